@@ -0,0 +1,120 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// DefaultAuditLogRetention is the default time entries are kept in the audit log.
+const DefaultAuditLogRetention = 90 * 24 * time.Hour
+
+// ops for audit log.
+const (
+	OpFindAuditLogEntries = "FindAuditLogEntries"
+)
+
+// AuditAction is the kind of change recorded by an audit log entry.
+type AuditAction string
+
+const (
+	// AuditCreate records the creation of a resource.
+	AuditCreate AuditAction = "create"
+	// AuditUpdate records the update of a resource.
+	AuditUpdate AuditAction = "update"
+	// AuditDelete records the deletion of a resource.
+	AuditDelete AuditAction = "delete"
+)
+
+// AuditLogEntry is a record of a change to a resource in the audit log.
+type AuditLogEntry struct {
+	ID           ID           `json:"id"`
+	Time         time.Time    `json:"time"`
+	OrgID        ID           `json:"orgID,omitempty"`
+	ResourceType ResourceType `json:"resourceType"`
+	ResourceID   ID           `json:"resourceID,omitempty"`
+	Action       AuditAction  `json:"action"`
+
+	// UserID, AuthorizerKind and AuthorizerID identify who made the change.
+	// They are empty for changes made by the system itself.
+	UserID         ID     `json:"userID,omitempty"`
+	AuthorizerKind string `json:"authorizerKind,omitempty"`
+	AuthorizerID   ID     `json:"authorizerID,omitempty"`
+	RemoteAddr     string `json:"remoteAddr,omitempty"`
+
+	// Before and After are the fields of the resource that changed, before
+	// and after the change. A created resource has no Before, and a deleted
+	// resource has no After. Secrets are never recorded.
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditLogFilter represents a set of filters that restrict the returned audit log entries.
+type AuditLogFilter struct {
+	OrgID        *ID
+	ResourceType *ResourceType
+	ResourceID   *ID
+	UserID       *ID
+	Since        *time.Time
+	Until        *time.Time
+}
+
+// QueryParams converts AuditLogFilter fields to url query params.
+func (f AuditLogFilter) QueryParams() map[string][]string {
+	qp := map[string][]string{}
+	if f.OrgID != nil {
+		qp["orgID"] = []string{f.OrgID.String()}
+	}
+	if f.ResourceType != nil {
+		qp["resourceType"] = []string{string(*f.ResourceType)}
+	}
+	if f.ResourceID != nil {
+		qp["resourceID"] = []string{f.ResourceID.String()}
+	}
+	if f.UserID != nil {
+		qp["userID"] = []string{f.UserID.String()}
+	}
+	if f.Since != nil {
+		qp["since"] = []string{f.Since.Format(time.RFC3339Nano)}
+	}
+	if f.Until != nil {
+		qp["until"] = []string{f.Until.Format(time.RFC3339Nano)}
+	}
+	return qp
+}
+
+// Matches returns true if e passes the filter.
+func (f AuditLogFilter) Matches(e *AuditLogEntry) bool {
+	if f.OrgID != nil && e.OrgID != *f.OrgID {
+		return false
+	}
+	if f.ResourceType != nil && e.ResourceType != *f.ResourceType {
+		return false
+	}
+	if f.ResourceID != nil && e.ResourceID != *f.ResourceID {
+		return false
+	}
+	if f.UserID != nil && e.UserID != *f.UserID {
+		return false
+	}
+	if f.Since != nil && e.Time.Before(*f.Since) {
+		return false
+	}
+	if f.Until != nil && !e.Time.Before(*f.Until) {
+		return false
+	}
+	return true
+}
+
+// AuditLogService is an interface for retrieving the audit log of changes to resources.
+type AuditLogService interface {
+	// FindAuditLogEntries returns the entries of the audit log that match filter,
+	// the newest first unless opts is ascending.
+	FindAuditLogEntries(ctx context.Context, filter AuditLogFilter, opt ...FindOptions) ([]*AuditLogEntry, int, error)
+}
+
+// DefaultAuditLogFindOptions are the default options for the audit log.
+var DefaultAuditLogFindOptions = FindOptions{
+	Descending: true,
+	Limit:      100,
+}
@@ -3,6 +3,7 @@ package influxdb
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // AuthorizationKind is returned by (*Authorization).Kind().
@@ -14,8 +15,18 @@ var (
 		Msg:  "unable to create token",
 		Code: EInvalid,
 	}
+
+	// ErrAuthorizationExpired is returned when an authorization is used after it expired.
+	ErrAuthorizationExpired = &Error{
+		Msg:  "authorization has expired",
+		Code: EUnauthorized,
+	}
 )
 
+// LastUsedAtPrecision is how often the time an authorization was last used is recorded.
+// Requests made within this duration of the recorded time do not update it.
+var LastUsedAtPrecision = time.Minute
+
 // Authorization is an authorization. 🎉
 type Authorization struct {
 	ID          ID           `json:"id"`
@@ -25,12 +36,41 @@ type Authorization struct {
 	OrgID       ID           `json:"orgID"`
 	UserID      ID           `json:"userID,omitempty"`
 	Permissions []Permission `json:"permissions"`
+
+	// ExpiresAt is when the authorization stops being usable; it never expires if nil.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// LastUsedAt is when the authorization was last used to authenticate a request, to LastUsedAtPrecision.
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	// ParentID is the authorization this authorization was derived from.
+	// A derived authorization is only usable while its parent is.
+	ParentID ID `json:"parentID,omitempty"`
+	// RoleIDs are the roles assigned to the authorization, whose permissions
+	// are granted in addition to Permissions.
+	RoleIDs []ID `json:"roleIDs,omitempty"`
 }
 
 // AuthorizationUpdate is the authorization update request.
 type AuthorizationUpdate struct {
-	Status      *Status `json:"status,omitempty"`
-	Description *string `json:"description,omitempty"`
+	Status      *Status    `json:"status,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	// ClearExpiresAt removes the expiry of the authorization, so that it never expires.
+	// It cannot be combined with ExpiresAt.
+	ClearExpiresAt bool `json:"clearExpiresAt,omitempty"`
+
+	// LastUsedAt is only set when authenticating requests.
+	LastUsedAt *time.Time `json:"-"`
+}
+
+// Valid returns an error if the update both sets and clears the expiry.
+func (u *AuthorizationUpdate) Valid() error {
+	if u.ExpiresAt != nil && u.ClearExpiresAt {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "cannot both set and clear the expiry of an authorization",
+		}
+	}
+	return nil
 }
 
 // Valid ensures that the authorization is valid.
@@ -47,16 +87,41 @@ func (a *Authorization) Valid() error {
 	return nil
 }
 
-// Allowed returns true if the authorization is active and request permission
+// Expired returns an error if the authorization is expired.
+func (a *Authorization) Expired() error {
+	if a.ExpiresAt != nil && !time.Now().Before(*a.ExpiresAt) {
+		return ErrAuthorizationExpired
+	}
+
+	return nil
+}
+
+// Allowed returns true if the authorization is active and unexpired and request permission
 // exists in the authorization's list of permissions.
 func (a *Authorization) Allowed(p Permission) bool {
 	if !a.IsActive() {
 		return false
 	}
+	if err := a.Expired(); err != nil {
+		return false
+	}
 
 	return PermissionAllowed(p, a.Permissions)
 }
 
+// Restrictions returns the restrictions on the data the authorization is allowed p on,
+// and whether it is allowed p at all.
+func (a *Authorization) Restrictions(p Permission) ([]*Restriction, bool) {
+	if !a.IsActive() {
+		return nil, false
+	}
+	if err := a.Expired(); err != nil {
+		return nil, false
+	}
+
+	return PermissionRestrictions(p, a.Permissions)
+}
+
 // IsActive is a stub for idpe.
 func IsActive(a *Authorization) bool {
 	return a.IsActive()
@@ -86,6 +151,7 @@ const (
 	OpCreateAuthorization      = "CreateAuthorization"
 	OpUpdateAuthorization      = "UpdateAuthorization"
 	OpDeleteAuthorization      = "DeleteAuthorization"
+	OpDeriveAuthorization      = "DeriveAuthorization"
 )
 
 // AuthorizationService represents a service for managing authorization data.
@@ -103,13 +169,30 @@ type AuthorizationService interface {
 	// Creates a new authorization and sets a.Token and a.UserID with the new identifier.
 	CreateAuthorization(ctx context.Context, a *Authorization) error
 
-	// UpdateAuthorization updates the status and description if available.
+	// UpdateAuthorization updates the status, description and expiry if available.
 	UpdateAuthorization(ctx context.Context, id ID, udp *AuthorizationUpdate) (*Authorization, error)
 
 	// Removes a authorization by token.
 	DeleteAuthorization(ctx context.Context, id ID) error
 }
 
+// AuthorizationDerive is the request to derive an authorization from the authorizer of the request.
+type AuthorizationDerive struct {
+	// OrgID defaults to the organization of the parent authorization.
+	OrgID       ID           `json:"orgID,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Permissions []Permission `json:"permissions"`
+	// ExpiresAt defaults to the expiry of the parent authorization, and cannot be after it.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// AuthorizationDeriveService derives authorizations from the authorizer on the context.
+type AuthorizationDeriveService interface {
+	// DeriveAuthorization creates an authorization for the user of the authorizer on the context,
+	// with a subset of the permissions of that authorizer.
+	DeriveAuthorization(ctx context.Context, d *AuthorizationDerive) (*Authorization, error)
+}
+
 // AuthorizationFilter represents a set of filter that restrict the returned results.
 type AuthorizationFilter struct {
 	Token *string
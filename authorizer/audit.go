@@ -0,0 +1,76 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.AuditLogService = (*AuditLogService)(nil)
+
+// AuditLogService wraps a influxdb.AuditLogService and authorizes actions
+// against it appropriately.
+//
+// The entries of an organization require read access to its audit log. Entries
+// of resources that belong to no organization, such as users, require read
+// access to the audit log of every organization.
+type AuditLogService struct {
+	s influxdb.AuditLogService
+}
+
+// NewAuditLogService constructs an instance of an authorizing audit log service.
+func NewAuditLogService(s influxdb.AuditLogService) *AuditLogService {
+	return &AuditLogService{
+		s: s,
+	}
+}
+
+func authorizeReadAuditLog(ctx context.Context, orgID influxdb.ID) error {
+	p := influxdb.Permission{
+		Action: influxdb.ReadAction,
+		Resource: influxdb.Resource{
+			Type: influxdb.AuditResourceType,
+		},
+	}
+	if orgID.Valid() {
+		p.Resource.OrgID = &orgID
+	}
+
+	if err := IsAllowed(ctx, p); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindAuditLogEntries retrieves all audit log entries that match the provided filter and then filters the list down to only the entries that are authorized.
+func (s *AuditLogService) FindAuditLogEntries(ctx context.Context, filter influxdb.AuditLogFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditLogEntry, int, error) {
+	if filter.OrgID != nil {
+		if err := authorizeReadAuditLog(ctx, *filter.OrgID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	es, _, err := s.s.FindAuditLogEntries(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	entries := es[:0]
+	for _, e := range es {
+		err := authorizeReadAuditLog(ctx, e.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, len(entries), nil
+}
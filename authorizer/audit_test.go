@@ -0,0 +1,89 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestAuditLogService_FindAuditLogEntries(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		filter     influxdb.AuditLogFilter
+		exp        []*influxdb.AuditLogEntry
+		err        error
+	}{
+		{
+			name: "authorized to read the audit log of an org",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type:  influxdb.AuditResourceType,
+					OrgID: influxdbtesting.IDPtr(10),
+				},
+			},
+			exp: []*influxdb.AuditLogEntry{
+				{ID: 1, OrgID: 10},
+			},
+		},
+		{
+			name: "authorized to read the audit log of all orgs",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.AuditResourceType,
+				},
+			},
+			exp: []*influxdb.AuditLogEntry{
+				{ID: 1, OrgID: 10},
+				{ID: 2, OrgID: 11},
+				{ID: 3},
+			},
+		},
+		{
+			name: "unauthorized to read the audit log of the org of the filter",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type:  influxdb.AuditResourceType,
+					OrgID: influxdbtesting.IDPtr(10),
+				},
+			},
+			filter: influxdb.AuditLogFilter{OrgID: influxdbtesting.IDPtr(11)},
+			err: &influxdb.Error{
+				Msg:  "read:orgs/000000000000000b/audit is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mock.NewAuditLogService()
+			svc.FindAuditLogEntriesFn = func(ctx context.Context, filter influxdb.AuditLogFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditLogEntry, int, error) {
+				es := []*influxdb.AuditLogEntry{
+					{ID: 1, OrgID: 10},
+					{ID: 2, OrgID: 11},
+					{ID: 3},
+				}
+				return es, len(es), nil
+			}
+			s := authorizer.NewAuditLogService(svc)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			es, _, err := s.FindAuditLogEntries(ctx, tt.filter)
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+			if diff := cmp.Diff(es, tt.exp); diff != "" {
+				t.Errorf("entries are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
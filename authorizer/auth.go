@@ -3,22 +3,30 @@ package authorizer
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/influxdata/influxdb"
+	influxdbcontext "github.com/influxdata/influxdb/context"
 )
 
-var _ influxdb.AuthorizationService = (*AuthorizationService)(nil)
+var (
+	_ influxdb.AuthorizationService       = (*AuthorizationService)(nil)
+	_ influxdb.AuthorizationDeriveService = (*AuthorizationService)(nil)
+)
 
 // AuthorizationService wraps a influxdb.AuthorizationService and authorizes actions
 // against it appropriately.
 type AuthorizationService struct {
-	s influxdb.AuthorizationService
+	s     influxdb.AuthorizationService
+	roles influxdb.RoleService
 }
 
 // NewAuthorizationService constructs an instance of an authorizing authorization serivce.
-func NewAuthorizationService(s influxdb.AuthorizationService) *AuthorizationService {
+// The roles assigned to authorizations are found in rs.
+func NewAuthorizationService(s influxdb.AuthorizationService, rs influxdb.RoleService) *AuthorizationService {
 	return &AuthorizationService{
-		s: s,
+		s:     s,
+		roles: rs,
 	}
 }
 
@@ -116,6 +124,8 @@ func (s *AuthorizationService) FindAuthorizations(ctx context.Context, filter in
 }
 
 // CreateAuthorization checks to see if the authorizer on context has write access to the global authorizations resource.
+// Assigning roles to the authorization requires write access to the roles, and every permission
+// they grant, so that a role cannot grant more than its assigner holds.
 func (s *AuthorizationService) CreateAuthorization(ctx context.Context, a *influxdb.Authorization) error {
 	if err := authorizeWriteAuthorization(ctx, a.UserID); err != nil {
 		return err
@@ -125,6 +135,12 @@ func (s *AuthorizationService) CreateAuthorization(ctx context.Context, a *influ
 		return err
 	}
 
+	for _, id := range a.RoleIDs {
+		if err := verifyRole(ctx, s.roles, id); err != nil {
+			return err
+		}
+	}
+
 	return s.s.CreateAuthorization(ctx, a)
 }
 
@@ -170,3 +186,82 @@ func (s *AuthorizationService) DeleteAuthorization(ctx context.Context, id influ
 
 	return s.s.DeleteAuthorization(ctx, id)
 }
+
+// DeriveAuthorization creates an authorization with a subset of the permissions of the authorizer on context,
+// for the user of that authorizer. It requires no permission other than the ones it derives.
+// An authorization derived from an authorization cannot outlive it.
+func (s *AuthorizationService) DeriveAuthorization(ctx context.Context, d *influxdb.AuthorizationDerive) (*influxdb.Authorization, error) {
+	auth, err := influxdbcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(d.Permissions) == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   influxdb.OpDeriveAuthorization,
+			Msg:  "authorization must include permissions",
+		}
+	}
+
+	a := &influxdb.Authorization{
+		Status:      influxdb.Active,
+		OrgID:       d.OrgID,
+		UserID:      auth.GetUserID(),
+		Description: d.Description,
+		Permissions: d.Permissions,
+		ExpiresAt:   d.ExpiresAt,
+	}
+
+	if parent, ok := auth.(*influxdb.Authorization); ok {
+		if !a.OrgID.Valid() {
+			a.OrgID = parent.OrgID
+		}
+		if a.OrgID != parent.OrgID {
+			return nil, &influxdb.Error{
+				Code: influxdb.EForbidden,
+				Op:   influxdb.OpDeriveAuthorization,
+				Msg:  "cannot derive an authorization for another organization",
+			}
+		}
+		if parent.ExpiresAt != nil {
+			if a.ExpiresAt == nil {
+				a.ExpiresAt = parent.ExpiresAt
+			} else if a.ExpiresAt.After(*parent.ExpiresAt) {
+				return nil, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Op:   influxdb.OpDeriveAuthorization,
+					Msg:  "derived authorization cannot expire after its parent",
+				}
+			}
+		}
+		a.ParentID = parent.ID
+	}
+
+	if a.ExpiresAt != nil && !a.ExpiresAt.After(time.Now()) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   influxdb.OpDeriveAuthorization,
+			Msg:  "expiration must be in the future",
+		}
+	}
+
+	for _, p := range a.Permissions {
+		if err := p.Valid(); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Op:   influxdb.OpDeriveAuthorization,
+				Err:  err,
+			}
+		}
+	}
+
+	if err := VerifyPermissions(ctx, a.Permissions); err != nil {
+		return nil, err
+	}
+
+	if err := s.s.CreateAuthorization(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
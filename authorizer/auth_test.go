@@ -133,7 +133,7 @@ func TestAuthorizationService_ReadAuthorization(t *testing.T) {
 					},
 				}, 1, nil
 			}
-			s := authorizer.NewAuthorizationService(m)
+			s := authorizer.NewAuthorizationService(m, mock.NewRoleService())
 
 			ctx := context.Background()
 			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{[]influxdb.Permission{tt.args.permission}})
@@ -247,7 +247,7 @@ func TestAuthorizationService_WriteAuthorization(t *testing.T) {
 			m.UpdateAuthorizationFn = func(ctx context.Context, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
 				return nil, nil
 			}
-			s := authorizer.NewAuthorizationService(m)
+			s := authorizer.NewAuthorizationService(m, mock.NewRoleService())
 
 			ctx := context.Background()
 			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{[]influxdb.Permission{tt.args.permission}})
@@ -25,3 +25,24 @@ func IsAllowed(ctx context.Context, p influxdb.Permission) error {
 
 	return nil
 }
+
+// IsAllowedRestricted checks to see if an action is authorized by retrieving
+// the authorizer off of context, allowing it when restricted to some of the
+// data of the resource. It returns the restrictions the action is subject to,
+// which are none if the authorizer is allowed the action on all of the data.
+func IsAllowedRestricted(ctx context.Context, p influxdb.Permission) ([]*influxdb.Restriction, error) {
+	a, err := influxdbcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rs, ok := influxdb.AuthorizerRestrictions(a, p)
+	if !ok {
+		return nil, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  fmt.Sprintf("%s is unauthorized", p),
+		}
+	}
+
+	return rs, nil
+}
@@ -0,0 +1,67 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.BucketSchemaService = (*BucketSchemaService)(nil)
+
+// BucketSchemaService wraps a influxdb.BucketSchemaService and authorizes actions
+// against it appropriately. Schemas are read and written with the permissions of their buckets.
+type BucketSchemaService struct {
+	s       influxdb.BucketSchemaService
+	buckets influxdb.BucketService
+}
+
+// NewBucketSchemaService constructs an instance of an authorizing bucket schema service.
+// The buckets of schemas are found with buckets.
+func NewBucketSchemaService(s influxdb.BucketSchemaService, buckets influxdb.BucketService) *BucketSchemaService {
+	return &BucketSchemaService{
+		s:       s,
+		buckets: buckets,
+	}
+}
+
+// FindBucketSchema checks to see if the authorizer on context has read access to the bucket.
+func (s *BucketSchemaService) FindBucketSchema(ctx context.Context, bucketID influxdb.ID) (*influxdb.BucketSchema, error) {
+	b, err := s.buckets.FindBucketByID(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadBucket(ctx, b.OrgID, bucketID); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindBucketSchema(ctx, bucketID)
+}
+
+// PutBucketSchema checks to see if the authorizer on context has write access to the bucket.
+func (s *BucketSchemaService) PutBucketSchema(ctx context.Context, bucketID influxdb.ID, sch *influxdb.BucketSchema) error {
+	b, err := s.buckets.FindBucketByID(ctx, bucketID)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteBucket(ctx, b.OrgID, bucketID); err != nil {
+		return err
+	}
+
+	return s.s.PutBucketSchema(ctx, bucketID, sch)
+}
+
+// DeleteBucketSchema checks to see if the authorizer on context has write access to the bucket.
+func (s *BucketSchemaService) DeleteBucketSchema(ctx context.Context, bucketID influxdb.ID) error {
+	b, err := s.buckets.FindBucketByID(ctx, bucketID)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteBucket(ctx, b.OrgID, bucketID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteBucketSchema(ctx, bucketID)
+}
@@ -0,0 +1,55 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.SigninLockoutService = (*SigninLockoutService)(nil)
+
+// SigninLockoutService wraps a influxdb.SigninLockoutService and authorizes
+// unlocking users. Signins are checked and recorded before anyone is
+// authorized, so those are not authorized.
+type SigninLockoutService struct {
+	s     influxdb.SigninLockoutService
+	users influxdb.UserService
+}
+
+// NewSigninLockoutService constructs an instance of an authorizing signin lockout service.
+// The users unlocked are found in users.
+func NewSigninLockoutService(s influxdb.SigninLockoutService, users influxdb.UserService) *SigninLockoutService {
+	return &SigninLockoutService{
+		s:     s,
+		users: users,
+	}
+}
+
+// CheckSignin returns an error if signins as the user, or from the address, are locked out.
+func (s *SigninLockoutService) CheckSignin(ctx context.Context, name, addr string) error {
+	return s.s.CheckSignin(ctx, name, addr)
+}
+
+// SigninFailed records a failed signin as the user from the address.
+func (s *SigninLockoutService) SigninFailed(ctx context.Context, name, addr string) {
+	s.s.SigninFailed(ctx, name, addr)
+}
+
+// SigninSucceeded clears the failed signins of the user.
+func (s *SigninLockoutService) SigninSucceeded(ctx context.Context, name string) {
+	s.s.SigninSucceeded(ctx, name)
+}
+
+// UnlockSignin checks to see if the authorizer on context has write access to the user.
+func (s *SigninLockoutService) UnlockSignin(ctx context.Context, name string) error {
+	u, err := s.users.FindUser(ctx, influxdb.UserFilter{Name: &name})
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteUser(ctx, u.ID); err != nil {
+		return err
+	}
+
+	return s.s.UnlockSignin(ctx, name)
+}
@@ -396,6 +396,55 @@ func TestOrgService_UpdateOrganization(t *testing.T) {
 	}
 }
 
+func TestOrgService_UpdateOrganization_QueryLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		err        error
+	}{
+		{
+			name: "authorized to update all orgs",
+			permission: influxdb.Permission{
+				Action: "write",
+				Resource: influxdb.Resource{
+					Type: influxdb.OrgsResourceType,
+				},
+			},
+		},
+		{
+			name: "only authorized to update the org",
+			permission: influxdb.Permission{
+				Action: "write",
+				Resource: influxdb.Resource{
+					Type: influxdb.OrgsResourceType,
+					ID:   influxdbtesting.IDPtr(1),
+				},
+			},
+			err: &influxdb.Error{
+				Msg:  "write:orgs is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewOrgService(&mock.OrganizationService{
+				UpdateOrganizationF: func(ctx context.Context, id influxdb.ID, upd influxdb.OrganizationUpdate) (*influxdb.Organization, error) {
+					return &influxdb.Organization{ID: id, QueryLimits: upd.QueryLimits}, nil
+				},
+			})
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			_, err := s.UpdateOrganization(ctx, 1, influxdb.OrganizationUpdate{
+				QueryLimits: &influxdb.QueryLimits{ConcurrencyQuota: 100},
+			})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
 func TestOrgService_DeleteOrganization(t *testing.T) {
 	type fields struct {
 		OrgService influxdb.OrganizationService
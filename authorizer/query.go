@@ -0,0 +1,95 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+var _ query.ActiveQueryService = (*ActiveQueryService)(nil)
+
+// ActiveQueryService wraps a query.ActiveQueryService and authorizes actions
+// against it appropriately.
+//
+// Query IDs are ephemeral, so permissions are checked on the queries of the
+// organization of a query, rather than on the query itself. Operators, whose
+// permissions are not restricted to an organization, can see and cancel the
+// queries of every organization.
+type ActiveQueryService struct {
+	s query.ActiveQueryService
+}
+
+// NewActiveQueryService constructs an instance of an authorizing active query service.
+func NewActiveQueryService(s query.ActiveQueryService) *ActiveQueryService {
+	return &ActiveQueryService{
+		s: s,
+	}
+}
+
+func authorizeQueries(ctx context.Context, a influxdb.Action, orgID influxdb.ID) error {
+	p, err := influxdb.NewPermission(a, influxdb.QueriesResourceType, orgID)
+	if err != nil {
+		return err
+	}
+
+	if err := IsAllowed(ctx, *p); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindActiveQueryByID checks to see if the authorizer on context has read access to the queries of the organization of the query.
+func (s *ActiveQueryService) FindActiveQueryByID(ctx context.Context, id influxdb.ID) (*query.ActiveQuery, error) {
+	q, err := s.s.FindActiveQueryByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeQueries(ctx, influxdb.ReadAction, q.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// FindActiveQueries retrieves all active queries that match the provided filter and then filters the list down to only the queries that are authorized.
+func (s *ActiveQueryService) FindActiveQueries(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+	qs, err := s.s.FindActiveQueries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	queries := qs[:0]
+	for _, q := range qs {
+		err := authorizeQueries(ctx, influxdb.ReadAction, q.OrganizationID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		queries = append(queries, q)
+	}
+
+	return queries, nil
+}
+
+// CancelActiveQuery checks to see if the authorizer on context has write access to the queries of the organization of the query.
+func (s *ActiveQueryService) CancelActiveQuery(ctx context.Context, id influxdb.ID) error {
+	q, err := s.s.FindActiveQueryByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeQueries(ctx, influxdb.WriteAction, q.OrganizationID); err != nil {
+		return err
+	}
+
+	return s.s.CancelActiveQuery(ctx, id)
+}
@@ -0,0 +1,126 @@
+package authorizer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/query"
+)
+
+var _ query.JobService = (*QueryJobService)(nil)
+
+// QueryJobService wraps a query.JobService and authorizes actions against it
+// appropriately.
+//
+// As for running queries, permissions are checked on the queries of the
+// organization of a job. The query of a job is still authorized by the
+// authorization of its request when it is executed, so its results may hold
+// data the other users of the organization cannot read; jobs can therefore
+// only be seen, read and deleted by the user that submitted them.
+type QueryJobService struct {
+	s query.JobService
+}
+
+// NewQueryJobService constructs an instance of an authorizing query job service.
+func NewQueryJobService(s query.JobService) *QueryJobService {
+	return &QueryJobService{
+		s: s,
+	}
+}
+
+// SubmitJob checks to see if the authorizer on context has write access to the queries of the organization of the request.
+func (s *QueryJobService) SubmitJob(ctx context.Context, req *query.Request) (*query.Job, error) {
+	if err := authorizeQueries(ctx, influxdb.WriteAction, req.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	return s.s.SubmitJob(ctx, req)
+}
+
+// authorizeJob checks to see if the authorizer on context has access to the
+// queries of the organization of the job and is of the user that submitted it.
+func authorizeJob(ctx context.Context, a influxdb.Action, j *query.Job) error {
+	if err := authorizeQueries(ctx, a, j.OrganizationID); err != nil {
+		return err
+	}
+
+	auth, err := influxdbcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !j.UserID.Valid() || auth.GetUserID() != j.UserID {
+		return &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  fmt.Sprintf("%s:orgs/%s/queries/jobs/%s is unauthorized", a, j.OrganizationID, j.ID),
+		}
+	}
+
+	return nil
+}
+
+// FindJobByID checks to see if the authorizer on context has read access to the queries of the organization of the job and submitted it.
+func (s *QueryJobService) FindJobByID(ctx context.Context, id influxdb.ID) (*query.Job, error) {
+	j, err := s.s.FindJobByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeJob(ctx, influxdb.ReadAction, j); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// FindJobs retrieves all jobs that match the provided filter and then filters the list down to only the jobs that are authorized and were submitted by the user.
+func (s *QueryJobService) FindJobs(ctx context.Context, filter query.JobFilter) ([]*query.Job, error) {
+	js, err := s.s.FindJobs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	jobs := js[:0]
+	for _, j := range js {
+		err := authorizeJob(ctx, influxdb.ReadAction, j)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+}
+
+// ReadJobPage checks to see if the authorizer on context has read access to the queries of the organization of the job and submitted it.
+func (s *QueryJobService) ReadJobPage(ctx context.Context, id influxdb.ID, page int) (flux.ResultIterator, error) {
+	if _, err := s.FindJobByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.s.ReadJobPage(ctx, id, page)
+}
+
+// DeleteJob checks to see if the authorizer on context has write access to the queries of the organization of the job and submitted it.
+func (s *QueryJobService) DeleteJob(ctx context.Context, id influxdb.ID) error {
+	j, err := s.s.FindJobByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeJob(ctx, influxdb.WriteAction, j); err != nil {
+		return err
+	}
+
+	return s.s.DeleteJob(ctx, id)
+}
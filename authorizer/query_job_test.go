@@ -0,0 +1,205 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestQueryJobService_SubmitJob(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		err        error
+	}{
+		{
+			name: "authorized to write the queries of the org",
+			permission: influxdb.Permission{
+				Action: "write",
+				Resource: influxdb.Resource{
+					Type:  influxdb.QueriesResourceType,
+					OrgID: influxdbtesting.IDPtr(10),
+				},
+			},
+		},
+		{
+			name: "authorized to write the queries of another org",
+			permission: influxdb.Permission{
+				Action: "write",
+				Resource: influxdb.Resource{
+					Type:  influxdb.QueriesResourceType,
+					OrgID: influxdbtesting.IDPtr(11),
+				},
+			},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/queries is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := querymock.NewJobService()
+			svc.SubmitJobFn = func(ctx context.Context, req *query.Request) (*query.Job, error) {
+				return &query.Job{ID: 1, OrganizationID: req.OrganizationID}, nil
+			}
+			s := authorizer.NewQueryJobService(svc)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			_, err := s.SubmitJob(ctx, &query.Request{OrganizationID: 10})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestQueryJobService_FindJobs(t *testing.T) {
+	svc := querymock.NewJobService()
+	svc.FindJobsFn = func(ctx context.Context, filter query.JobFilter) ([]*query.Job, error) {
+		return []*query.Job{
+			{ID: 1, OrganizationID: 10, UserID: 2},
+			{ID: 2, OrganizationID: 11, UserID: 2},
+			{ID: 3, OrganizationID: 10, UserID: 3},
+		}, nil
+	}
+	s := authorizer.NewQueryJobService(svc)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{{
+		Action: "read",
+		Resource: influxdb.Resource{
+			Type:  influxdb.QueriesResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}}})
+
+	js, err := s.FindJobs(ctx, query.JobFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(js, []*query.Job{{ID: 1, OrganizationID: 10, UserID: 2}}); diff != "" {
+		t.Errorf("jobs are different -got/+want\ndiff %s", diff)
+	}
+}
+
+func TestQueryJobService_ReadJobPage(t *testing.T) {
+	tests := []struct {
+		name string
+		job  query.Job
+		err  error
+	}{
+		{
+			name: "authorized to read the jobs of the user",
+			job:  query.Job{ID: 1, OrganizationID: 11, UserID: 2},
+		},
+		{
+			name: "authorized to read the queries of another org",
+			job:  query.Job{ID: 1, OrganizationID: 10, UserID: 2},
+			err: &influxdb.Error{
+				Msg:  "read:orgs/000000000000000a/queries is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+		{
+			name: "job of another user",
+			job:  query.Job{ID: 1, OrganizationID: 11, UserID: 3},
+			err: &influxdb.Error{
+				Msg:  "read:orgs/000000000000000b/queries/jobs/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+		{
+			name: "job without a user",
+			job:  query.Job{ID: 1, OrganizationID: 11},
+			err: &influxdb.Error{
+				Msg:  "read:orgs/000000000000000b/queries/jobs/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := querymock.NewJobService()
+			svc.FindJobByIDFn = func(ctx context.Context, id influxdb.ID) (*query.Job, error) {
+				j := tt.job
+				return &j, nil
+			}
+			var read bool
+			svc.ReadJobPageFn = func(ctx context.Context, id influxdb.ID, page int) (flux.ResultIterator, error) {
+				read = true
+				return flux.NewSliceResultIterator(nil), nil
+			}
+			s := authorizer.NewQueryJobService(svc)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type:  influxdb.QueriesResourceType,
+					OrgID: influxdbtesting.IDPtr(11),
+				},
+			}}})
+
+			_, err := s.ReadJobPage(ctx, 1, 0)
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+			if read != (tt.err == nil) {
+				t.Errorf("expected page to be read %v, was read %v", tt.err == nil, read)
+			}
+		})
+	}
+}
+
+func TestQueryJobService_DeleteJob(t *testing.T) {
+	var deleted bool
+	svc := querymock.NewJobService()
+	svc.FindJobByIDFn = func(ctx context.Context, id influxdb.ID) (*query.Job, error) {
+		return &query.Job{ID: id, OrganizationID: 10, UserID: 3}, nil
+	}
+	svc.DeleteJobFn = func(ctx context.Context, id influxdb.ID) error {
+		deleted = true
+		return nil
+	}
+	s := authorizer.NewQueryJobService(svc)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{{
+		Action: "read",
+		Resource: influxdb.Resource{
+			Type:  influxdb.QueriesResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}}})
+
+	err := s.DeleteJob(ctx, 1)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "write:orgs/000000000000000a/queries is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+	if deleted {
+		t.Error("expected job not to be deleted")
+	}
+
+	ctx = influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{{
+		Action: "write",
+		Resource: influxdb.Resource{
+			Type:  influxdb.QueriesResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}}})
+
+	err = s.DeleteJob(ctx, 1)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "write:orgs/000000000000000a/queries/jobs/0000000000000001 is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+	if deleted {
+		t.Error("expected job of another user not to be deleted")
+	}
+}
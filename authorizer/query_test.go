@@ -0,0 +1,131 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestActiveQueryService_FindActiveQueries(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		exp        []*query.ActiveQuery
+	}{
+		{
+			name: "authorized to read the queries of an org",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type:  influxdb.QueriesResourceType,
+					OrgID: influxdbtesting.IDPtr(10),
+				},
+			},
+			exp: []*query.ActiveQuery{
+				{ID: 1, OrganizationID: 10},
+				{ID: 2, OrganizationID: 10},
+			},
+		},
+		{
+			name: "authorized to read the queries of all orgs",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.QueriesResourceType,
+				},
+			},
+			exp: []*query.ActiveQuery{
+				{ID: 1, OrganizationID: 10},
+				{ID: 2, OrganizationID: 10},
+				{ID: 3, OrganizationID: 11},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := querymock.NewActiveQueryService()
+			svc.FindActiveQueriesFn = func(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+				return []*query.ActiveQuery{
+					{ID: 1, OrganizationID: 10},
+					{ID: 2, OrganizationID: 10},
+					{ID: 3, OrganizationID: 11},
+				}, nil
+			}
+			s := authorizer.NewActiveQueryService(svc)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			qs, err := s.FindActiveQueries(ctx, query.ActiveQueryFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(qs, tt.exp); diff != "" {
+				t.Errorf("queries are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func TestActiveQueryService_CancelActiveQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		err        error
+	}{
+		{
+			name: "authorized to cancel the queries of the org",
+			permission: influxdb.Permission{
+				Action: "write",
+				Resource: influxdb.Resource{
+					Type:  influxdb.QueriesResourceType,
+					OrgID: influxdbtesting.IDPtr(10),
+				},
+			},
+		},
+		{
+			name: "only authorized to read the queries of the org",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type:  influxdb.QueriesResourceType,
+					OrgID: influxdbtesting.IDPtr(10),
+				},
+			},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/queries is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var canceled bool
+			svc := querymock.NewActiveQueryService()
+			svc.FindActiveQueryByIDFn = func(ctx context.Context, id influxdb.ID) (*query.ActiveQuery, error) {
+				return &query.ActiveQuery{ID: id, OrganizationID: 10}, nil
+			}
+			svc.CancelActiveQueryFn = func(ctx context.Context, id influxdb.ID) error {
+				canceled = true
+				return nil
+			}
+			s := authorizer.NewActiveQueryService(svc)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			err := s.CancelActiveQuery(ctx, 1)
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+			if canceled != (tt.err == nil) {
+				t.Errorf("unexpected cancellation -got/+exp\n%v\n%v", canceled, tt.err == nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestReplicationService_FindReplicationByID(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		err        error
+	}{
+		{
+			name: "authorized to access id",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.ReplicationsResourceType,
+					ID:   influxdbtesting.IDPtr(1),
+				},
+			},
+		},
+		{
+			name: "unauthorized to access id",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.ReplicationsResourceType,
+					ID:   influxdbtesting.IDPtr(2),
+				},
+			},
+			err: &influxdb.Error{
+				Msg:  "read:orgs/000000000000000a/replications/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mock.NewReplicationService()
+			svc.FindReplicationByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Replication, error) {
+				return &influxdb.Replication{ID: id, OrgID: 10}, nil
+			}
+			s := authorizer.NewReplicationService(svc)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			_, err := s.FindReplicationByID(ctx, 1)
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestReplicationService_FindReplications(t *testing.T) {
+	svc := mock.NewReplicationService()
+	svc.FindReplicationsFn = func(ctx context.Context, filter influxdb.ReplicationFilter) ([]*influxdb.Replication, error) {
+		return []*influxdb.Replication{
+			{ID: 1, OrgID: 10},
+			{ID: 2, OrgID: 10},
+			{ID: 3, OrgID: 11},
+		}, nil
+	}
+	s := authorizer.NewReplicationService(svc)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type:  influxdb.ReplicationsResourceType,
+				OrgID: influxdbtesting.IDPtr(10),
+			},
+		},
+	}})
+
+	rs, err := s.FindReplications(ctx, influxdb.ReplicationFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []*influxdb.Replication{
+		{ID: 1, OrgID: 10},
+		{ID: 2, OrgID: 10},
+	}
+	if diff := cmp.Diff(rs, exp); diff != "" {
+		t.Errorf("replications are different -got/+want\ndiff %s", diff)
+	}
+}
+
+func TestReplicationService_CreateReplication(t *testing.T) {
+	writeReplications := influxdb.Permission{
+		Action: "write",
+		Resource: influxdb.Resource{
+			Type:  influxdb.ReplicationsResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+	readBucket := influxdb.Permission{
+		Action: "read",
+		Resource: influxdb.Resource{
+			Type: influxdb.BucketsResourceType,
+			ID:   influxdbtesting.IDPtr(1),
+		},
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		err         error
+	}{
+		{
+			name:        "authorized to create replication",
+			permissions: []influxdb.Permission{writeReplications, readBucket},
+		},
+		{
+			name:        "unauthorized to create replication",
+			permissions: []influxdb.Permission{readBucket},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/replications is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+		{
+			name:        "unauthorized to read local bucket",
+			permissions: []influxdb.Permission{writeReplications},
+			err: &influxdb.Error{
+				Msg:  "read:orgs/000000000000000a/buckets/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewReplicationService(mock.NewReplicationService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			err := s.CreateReplication(ctx, &influxdb.Replication{OrgID: 10, LocalBucketID: 1})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
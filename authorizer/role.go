@@ -0,0 +1,239 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.RoleService = (*RoleService)(nil)
+
+// RoleService wraps a influxdb.RoleService and authorizes actions
+// against it appropriately.
+//
+// The permissions of a role can only be set to permissions the authorizer
+// on context has itself, as for authorizations.
+type RoleService struct {
+	s influxdb.RoleService
+}
+
+// NewRoleService constructs an instance of an authorizing role service.
+func NewRoleService(s influxdb.RoleService) *RoleService {
+	return &RoleService{
+		s: s,
+	}
+}
+
+func newRolePermission(a influxdb.Action, orgID, id influxdb.ID) (*influxdb.Permission, error) {
+	return influxdb.NewPermissionAtID(id, a, influxdb.RolesResourceType, orgID)
+}
+
+func authorizeReadRole(ctx context.Context, orgID, id influxdb.ID) error {
+	p, err := newRolePermission(influxdb.ReadAction, orgID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := IsAllowed(ctx, *p); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func authorizeWriteRole(ctx context.Context, orgID, id influxdb.ID) error {
+	p, err := newRolePermission(influxdb.WriteAction, orgID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := IsAllowed(ctx, *p); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindRoleByID checks to see if the authorizer on context has read access to the id provided.
+func (s *RoleService) FindRoleByID(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	r, err := s.s.FindRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadRole(ctx, r.OrgID, id); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// FindRoles retrieves all roles that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *RoleService) FindRoles(ctx context.Context, filter influxdb.RoleFilter) ([]*influxdb.Role, error) {
+	rs, err := s.s.FindRoles(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	roles := rs[:0]
+	for _, r := range rs {
+		err := authorizeReadRole(ctx, r.OrgID, r.ID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		roles = append(roles, r)
+	}
+
+	return roles, nil
+}
+
+// CreateRole checks to see if the authorizer on context has write access to the roles of the organization,
+// and is allowed the permissions of the role.
+func (s *RoleService) CreateRole(ctx context.Context, r *influxdb.Role) error {
+	p, err := influxdb.NewPermission(influxdb.WriteAction, influxdb.RolesResourceType, r.OrgID)
+	if err != nil {
+		return err
+	}
+
+	if err := IsAllowed(ctx, *p); err != nil {
+		return err
+	}
+
+	if err := VerifyPermissions(ctx, r.Permissions); err != nil {
+		return err
+	}
+
+	return s.s.CreateRole(ctx, r)
+}
+
+// UpdateRole checks to see if the authorizer on context has write access to the role provided,
+// and is allowed the permissions it is updated to.
+func (s *RoleService) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	r, err := s.s.FindRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWriteRole(ctx, r.OrgID, id); err != nil {
+		return nil, err
+	}
+
+	if upd.Permissions != nil {
+		if err := VerifyPermissions(ctx, *upd.Permissions); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.s.UpdateRole(ctx, id, upd)
+}
+
+// DeleteRole checks to see if the authorizer on context has write access to the role provided.
+func (s *RoleService) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	r, err := s.s.FindRoleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteRole(ctx, r.OrgID, id); err != nil {
+		return err
+	}
+
+	return s.s.DeleteRole(ctx, id)
+}
+
+// verifyRole checks that the authorizer on context may assign the role with the
+// given id: it has write access to the role and holds every permission of it.
+func verifyRole(ctx context.Context, rs influxdb.RoleService, id influxdb.ID) error {
+	r, err := rs.FindRoleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteRole(ctx, r.OrgID, r.ID); err != nil {
+		return err
+	}
+
+	return VerifyPermissions(ctx, r.Permissions)
+}
+
+// RoleResolver resolves the permissions that roles grant to the authorizers
+// they are assigned to.
+type RoleResolver struct {
+	roles influxdb.RoleService
+	urms  influxdb.UserResourceMappingService
+}
+
+// NewRoleResolver constructs a RoleResolver reading roles from rs, and the
+// roles users are members of from urms.
+func NewRoleResolver(rs influxdb.RoleService, urms influxdb.UserResourceMappingService) *RoleResolver {
+	return &RoleResolver{
+		roles: rs,
+		urms:  urms,
+	}
+}
+
+// Resolve returns a copy of the authorizer whose permissions are its own and
+// those of its roles. Authorizations hold the roles of their RoleIDs, and
+// sessions the roles their user is a member of. Other authorizers are
+// returned as is. Roles that no longer exist are ignored.
+func (r *RoleResolver) Resolve(ctx context.Context, a influxdb.Authorizer) (influxdb.Authorizer, error) {
+	switch a := a.(type) {
+	case *influxdb.Authorization:
+		if len(a.RoleIDs) == 0 {
+			return a, nil
+		}
+		ps, err := r.rolePermissions(ctx, a.RoleIDs)
+		if err != nil {
+			return nil, err
+		}
+		resolved := *a
+		resolved.Permissions = append(append([]influxdb.Permission{}, a.Permissions...), ps...)
+		return &resolved, nil
+	case *influxdb.Session:
+		ms, _, err := r.urms.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+			UserID:       a.UserID,
+			ResourceType: influxdb.RolesResourceType,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(ms) == 0 {
+			return a, nil
+		}
+		ids := make([]influxdb.ID, 0, len(ms))
+		for _, m := range ms {
+			ids = append(ids, m.ResourceID)
+		}
+		ps, err := r.rolePermissions(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		resolved := *a
+		resolved.Permissions = append(append([]influxdb.Permission{}, a.Permissions...), ps...)
+		return &resolved, nil
+	default:
+		return a, nil
+	}
+}
+
+func (r *RoleResolver) rolePermissions(ctx context.Context, ids []influxdb.ID) ([]influxdb.Permission, error) {
+	var ps []influxdb.Permission
+	for _, id := range ids {
+		role, err := r.roles.FindRoleByID(ctx, id)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, role.Permissions...)
+	}
+	return ps, nil
+}
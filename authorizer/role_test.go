@@ -0,0 +1,337 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestRoleService_FindRoleByID(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		err        error
+	}{
+		{
+			name: "authorized to access id",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.RolesResourceType,
+					ID:   influxdbtesting.IDPtr(1),
+				},
+			},
+		},
+		{
+			name: "unauthorized to access id",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.RolesResourceType,
+					ID:   influxdbtesting.IDPtr(2),
+				},
+			},
+			err: &influxdb.Error{
+				Msg:  "read:orgs/000000000000000a/roles/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mock.NewRoleService()
+			svc.FindRoleByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+				return &influxdb.Role{ID: id, OrgID: 10}, nil
+			}
+			s := authorizer.NewRoleService(svc)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			_, err := s.FindRoleByID(ctx, 1)
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestRoleService_FindRoles(t *testing.T) {
+	svc := mock.NewRoleService()
+	svc.FindRolesFn = func(ctx context.Context, filter influxdb.RoleFilter) ([]*influxdb.Role, error) {
+		return []*influxdb.Role{
+			{ID: 1, OrgID: 10},
+			{ID: 2, OrgID: 10},
+			{ID: 3, OrgID: 11},
+		}, nil
+	}
+	s := authorizer.NewRoleService(svc)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type:  influxdb.RolesResourceType,
+				OrgID: influxdbtesting.IDPtr(10),
+			},
+		},
+	}})
+
+	rs, err := s.FindRoles(ctx, influxdb.RoleFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []*influxdb.Role{
+		{ID: 1, OrgID: 10},
+		{ID: 2, OrgID: 10},
+	}
+	if diff := cmp.Diff(rs, exp); diff != "" {
+		t.Errorf("roles are different -got/+want\ndiff %s", diff)
+	}
+}
+
+func TestRoleService_CreateRole(t *testing.T) {
+	writeRoles := influxdb.Permission{
+		Action: "write",
+		Resource: influxdb.Resource{
+			Type:  influxdb.RolesResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+	readBuckets := influxdb.Permission{
+		Action: "read",
+		Resource: influxdb.Resource{
+			Type:  influxdb.BucketsResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		err         error
+	}{
+		{
+			name:        "authorized to create role",
+			permissions: []influxdb.Permission{writeRoles, readBuckets},
+		},
+		{
+			name:        "unauthorized to create role",
+			permissions: []influxdb.Permission{readBuckets},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/roles is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+		{
+			name:        "not allowed the permissions of the role",
+			permissions: []influxdb.Permission{writeRoles},
+			err: &influxdb.Error{
+				Msg:  "permission read:orgs/000000000000000a/buckets is not allowed",
+				Code: influxdb.EForbidden,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewRoleService(mock.NewRoleService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			err := s.CreateRole(ctx, &influxdb.Role{OrgID: 10, Name: "readers", Permissions: []influxdb.Permission{readBuckets}})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestRoleAssignment(t *testing.T) {
+	writeRoles := influxdb.Permission{
+		Action: "write",
+		Resource: influxdb.Resource{
+			Type:  influxdb.RolesResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+	writeUsers := influxdb.Permission{
+		Action: "write",
+		Resource: influxdb.Resource{
+			Type: influxdb.UsersResourceType,
+		},
+	}
+	writeBuckets := influxdb.Permission{
+		Action: "write",
+		Resource: influxdb.Resource{
+			Type:  influxdb.BucketsResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+
+	roles := mock.NewRoleService()
+	roles.FindRoleByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+		if id != 1 {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: influxdb.ErrRoleNotFound}
+		}
+		return &influxdb.Role{ID: 1, OrgID: 10, Permissions: []influxdb.Permission{writeBuckets}}, nil
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		err         error
+	}{
+		{
+			name:        "authorized to assign role",
+			permissions: []influxdb.Permission{writeRoles, writeUsers, writeBuckets},
+		},
+		{
+			name:        "unauthorized to assign role",
+			permissions: []influxdb.Permission{writeUsers, writeBuckets},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/roles/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+		{
+			name:        "not allowed the permissions of the role",
+			permissions: []influxdb.Permission{writeRoles, writeUsers},
+			err: &influxdb.Error{
+				Msg:  "permission write:orgs/000000000000000a/buckets is not allowed",
+				Code: influxdb.EForbidden,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("authorization "+tt.name, func(t *testing.T) {
+			s := authorizer.NewAuthorizationService(mock.NewAuthorizationService(), roles)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			err := s.CreateAuthorization(ctx, &influxdb.Authorization{OrgID: 10, UserID: 5, RoleIDs: []influxdb.ID{1}})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+		t.Run("member "+tt.name, func(t *testing.T) {
+			s := authorizer.NewURMService(&OrgService{OrgID: 10}, mock.NewUserResourceMappingService(), roles)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			err := s.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+				UserID:       5,
+				UserType:     influxdb.Member,
+				ResourceType: influxdb.RolesResourceType,
+				ResourceID:   1,
+			})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestRoleResolver_Resolve(t *testing.T) {
+	readBuckets := influxdb.Permission{
+		Action: "read",
+		Resource: influxdb.Resource{
+			Type:  influxdb.BucketsResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+	writeBuckets := influxdb.Permission{
+		Action: "write",
+		Resource: influxdb.Resource{
+			Type:  influxdb.BucketsResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+	readDashboards := influxdb.Permission{
+		Action: "read",
+		Resource: influxdb.Resource{
+			Type:  influxdb.DashboardsResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+
+	roles := mock.NewRoleService()
+	roles.FindRoleByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+		switch id {
+		case 1:
+			return &influxdb.Role{ID: 1, OrgID: 10, Permissions: []influxdb.Permission{writeBuckets}}, nil
+		case 2:
+			return &influxdb.Role{ID: 2, OrgID: 10, Permissions: []influxdb.Permission{readDashboards}}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: influxdb.ErrRoleNotFound}
+	}
+	urms := mock.NewUserResourceMappingService()
+	urms.FindMappingsFn = func(ctx context.Context, filter influxdb.UserResourceMappingFilter) ([]*influxdb.UserResourceMapping, int, error) {
+		if filter.UserID != 5 || filter.ResourceType != influxdb.RolesResourceType {
+			return nil, 0, nil
+		}
+		return []*influxdb.UserResourceMapping{
+			{UserID: 5, UserType: influxdb.Member, ResourceType: influxdb.RolesResourceType, ResourceID: 2},
+		}, 1, nil
+	}
+	r := authorizer.NewRoleResolver(roles, urms)
+
+	tests := []struct {
+		name       string
+		authorizer influxdb.Authorizer
+		want       []influxdb.Permission
+	}{
+		{
+			name: "authorization with roles",
+			authorizer: &influxdb.Authorization{
+				Status:      influxdb.Active,
+				Permissions: []influxdb.Permission{readBuckets},
+				RoleIDs:     []influxdb.ID{1, 3},
+			},
+			want: []influxdb.Permission{readBuckets, writeBuckets},
+		},
+		{
+			name: "authorization without roles",
+			authorizer: &influxdb.Authorization{
+				Status:      influxdb.Active,
+				Permissions: []influxdb.Permission{readBuckets},
+			},
+			want: []influxdb.Permission{readBuckets},
+		},
+		{
+			name: "session of a member of a role",
+			authorizer: &influxdb.Session{
+				UserID:      5,
+				Permissions: []influxdb.Permission{readBuckets},
+			},
+			want: []influxdb.Permission{readBuckets, readDashboards},
+		},
+		{
+			name: "session of a user without roles",
+			authorizer: &influxdb.Session{
+				UserID:      6,
+				Permissions: []influxdb.Permission{readBuckets},
+			},
+			want: []influxdb.Permission{readBuckets},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := r.Resolve(context.Background(), tt.authorizer)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []influxdb.Permission
+			switch a := a.(type) {
+			case *influxdb.Authorization:
+				got = a.Permissions
+			case *influxdb.Session:
+				got = a.Permissions
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("permissions are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.StorageUsageService = (*StorageUsageService)(nil)
+
+// StorageUsageService wraps a influxdb.StorageUsageService and authorizes actions
+// against it appropriately.
+type StorageUsageService struct {
+	s influxdb.StorageUsageService
+}
+
+// NewStorageUsageService constructs an instance of an authorizing storage usage service.
+func NewStorageUsageService(s influxdb.StorageUsageService) *StorageUsageService {
+	return &StorageUsageService{
+		s: s,
+	}
+}
+
+// FindOrganizationStorageUsage checks to see if the authorizer on context has read access to the organization.
+func (s *StorageUsageService) FindOrganizationStorageUsage(ctx context.Context, orgID influxdb.ID) (*influxdb.StorageUsage, error) {
+	if err := authorizeReadOrg(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindOrganizationStorageUsage(ctx, orgID)
+}
+
+// FindBucketStorageUsage checks to see if the authorizer on context has read access to the bucket.
+func (s *StorageUsageService) FindBucketStorageUsage(ctx context.Context, orgID, bucketID influxdb.ID) (*influxdb.StorageUsage, error) {
+	if err := authorizeReadBucket(ctx, orgID, bucketID); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindBucketStorageUsage(ctx, orgID, bucketID)
+}
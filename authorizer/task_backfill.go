@@ -0,0 +1,111 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.BackfillService = (*BackfillService)(nil)
+
+// BackfillService wraps a influxdb.BackfillService and authorizes actions
+// against it appropriately.
+//
+// A backfill is authorized as the task it backfills: reading a backfill
+// requires read access to the task, and starting or canceling one requires
+// write access to it, as forcing a run of the task does.
+type BackfillService struct {
+	s  influxdb.BackfillService
+	ts influxdb.TaskService
+}
+
+// NewBackfillService constructs an instance of an authorizing backfill service.
+// The unauthorized task service ts is used to look up the organization of a task.
+func NewBackfillService(s influxdb.BackfillService, ts influxdb.TaskService) *BackfillService {
+	return &BackfillService{
+		s:  s,
+		ts: ts,
+	}
+}
+
+func authorizeTask(ctx context.Context, a influxdb.Action, orgID, taskID influxdb.ID) error {
+	p, err := influxdb.NewPermissionAtID(taskID, a, influxdb.TasksResourceType, orgID)
+	if err != nil {
+		return err
+	}
+
+	if err := IsAllowed(ctx, *p); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateBackfill checks to see if the authorizer on context has write access to the task.
+func (s *BackfillService) CreateBackfill(ctx context.Context, taskID influxdb.ID, c influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+	// Unauthenticated task lookup, to identify the task's organization.
+	t, err := s.ts.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeTask(ctx, influxdb.WriteAction, t.OrganizationID, taskID); err != nil {
+		return nil, err
+	}
+
+	return s.s.CreateBackfill(ctx, taskID, c)
+}
+
+// FindBackfillByID checks to see if the authorizer on context has read access to the task of the backfill.
+func (s *BackfillService) FindBackfillByID(ctx context.Context, taskID, id influxdb.ID) (*influxdb.Backfill, error) {
+	b, err := s.s.FindBackfillByID(ctx, taskID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeTask(ctx, influxdb.ReadAction, b.OrganizationID, b.TaskID); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// FindBackfills retrieves all backfills that match the provided filter and then filters the list down to only the backfills that are authorized.
+func (s *BackfillService) FindBackfills(ctx context.Context, filter influxdb.BackfillFilter) ([]*influxdb.Backfill, error) {
+	bs, err := s.s.FindBackfills(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	backfills := bs[:0]
+	for _, b := range bs {
+		err := authorizeTask(ctx, influxdb.ReadAction, b.OrganizationID, b.TaskID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		backfills = append(backfills, b)
+	}
+
+	return backfills, nil
+}
+
+// CancelBackfill checks to see if the authorizer on context has write access to the task of the backfill.
+func (s *BackfillService) CancelBackfill(ctx context.Context, taskID, id influxdb.ID) (*influxdb.Backfill, error) {
+	b, err := s.s.FindBackfillByID(ctx, taskID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeTask(ctx, influxdb.WriteAction, b.OrganizationID, b.TaskID); err != nil {
+		return nil, err
+	}
+
+	return s.s.CancelBackfill(ctx, taskID, id)
+}
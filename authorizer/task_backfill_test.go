@@ -0,0 +1,131 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func newBackfillTaskService() *mock.TaskService {
+	return &mock.TaskService{
+		FindTaskByIDFn: func(_ context.Context, id influxdb.ID) (*influxdb.Task, error) {
+			return &influxdb.Task{ID: id, OrganizationID: 10}, nil
+		},
+	}
+}
+
+func TestBackfillService_CreateBackfill(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		err        error
+	}{
+		{
+			name: "authorized to write task",
+			permission: influxdb.Permission{
+				Action: "write",
+				Resource: influxdb.Resource{
+					Type: influxdb.TasksResourceType,
+					ID:   influxdbtesting.IDPtr(1),
+				},
+			},
+		},
+		{
+			name: "only authorized to read task",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.TasksResourceType,
+					ID:   influxdbtesting.IDPtr(1),
+				},
+			},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/tasks/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewBackfillService(mock.NewBackfillService(), newBackfillTaskService())
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{tt.permission}})
+
+			_, err := s.CreateBackfill(ctx, 1, influxdb.BackfillCreate{})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestBackfillService_FindBackfills(t *testing.T) {
+	svc := mock.NewBackfillService()
+	svc.FindBackfillsFn = func(context.Context, influxdb.BackfillFilter) ([]*influxdb.Backfill, error) {
+		return []*influxdb.Backfill{
+			{ID: 1, TaskID: 1, OrganizationID: 10},
+			{ID: 2, TaskID: 2, OrganizationID: 10},
+			{ID: 3, TaskID: 1, OrganizationID: 10},
+		}, nil
+	}
+	s := authorizer.NewBackfillService(svc, newBackfillTaskService())
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type: influxdb.TasksResourceType,
+				ID:   influxdbtesting.IDPtr(1),
+			},
+		},
+	}})
+
+	bs, err := s.FindBackfills(ctx, influxdb.BackfillFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []*influxdb.Backfill{
+		{ID: 1, TaskID: 1, OrganizationID: 10},
+		{ID: 3, TaskID: 1, OrganizationID: 10},
+	}
+	if diff := cmp.Diff(bs, exp); diff != "" {
+		t.Errorf("backfills are different -got/+want\ndiff %s", diff)
+	}
+}
+
+func TestBackfillService_CancelBackfill(t *testing.T) {
+	svc := mock.NewBackfillService()
+	svc.FindBackfillByIDFn = func(_ context.Context, taskID, id influxdb.ID) (*influxdb.Backfill, error) {
+		return &influxdb.Backfill{ID: id, TaskID: taskID, OrganizationID: 10}, nil
+	}
+	canceled := false
+	svc.CancelBackfillFn = func(context.Context, influxdb.ID, influxdb.ID) (*influxdb.Backfill, error) {
+		canceled = true
+		return nil, nil
+	}
+	s := authorizer.NewBackfillService(svc, newBackfillTaskService())
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: "read",
+			Resource: influxdb.Resource{
+				Type:  influxdb.TasksResourceType,
+				OrgID: influxdbtesting.IDPtr(10),
+			},
+		},
+	}})
+
+	_, err := s.CancelBackfill(ctx, 1, 2)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "write:orgs/000000000000000a/tasks/0000000000000001 is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+	if canceled {
+		t.Fatal("expected the backfill not to be canceled")
+	}
+}
@@ -0,0 +1,128 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+// TokenAuthenticator finds the authorizations of the tokens of requests. It is
+// shared by the HTTP API and the gRPC storage read service, so that a token is
+// checked in the same way whichever way it is sent.
+type TokenAuthenticator struct {
+	s     influxdb.AuthorizationService
+	roles *RoleResolver
+}
+
+// NewTokenAuthenticator constructs a TokenAuthenticator finding authorizations in s.
+// If roles is not nil, authorizations are granted the permissions of their roles.
+func NewTokenAuthenticator(s influxdb.AuthorizationService, roles *RoleResolver) *TokenAuthenticator {
+	return &TokenAuthenticator{
+		s:     s,
+		roles: roles,
+	}
+}
+
+// Authorizer returns the authorizer of token, with the permissions of the roles
+// of its authorization. It fails like Authenticate.
+func (t *TokenAuthenticator) Authorizer(ctx context.Context, token string) (influxdb.Authorizer, error) {
+	a, err := t.Authenticate(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if t.roles == nil {
+		return a, nil
+	}
+	return t.roles.Resolve(ctx, a)
+}
+
+// FindAuthorizationByID returns the authorization with id, checked like the authorization
+// of a token, with the permissions of its roles. The runs of a task act with the
+// authorization it returns for the authorization of the task.
+func (t *TokenAuthenticator) FindAuthorizationByID(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+	a, err := t.s.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := CheckAuthorization(ctx, t.s, a); err != nil {
+		return nil, err
+	}
+
+	if t.roles == nil {
+		return a, nil
+	}
+	resolved, err := t.roles.Resolve(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	return resolved.(*influxdb.Authorization), nil
+}
+
+// Authenticate returns the authorization of token. It returns an unauthorized
+// error if no authorization has the token, or if the authorization or any
+// authorization it was derived from is expired or inactive.
+// An authorization that is inactive itself is still returned, and denied by its
+// permission checks.
+func (t *TokenAuthenticator) Authenticate(ctx context.Context, token string) (*influxdb.Authorization, error) {
+	a, err := t.s.FindAuthorizationByToken(ctx, token)
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil, &influxdb.Error{
+				Code: influxdb.EUnauthorized,
+				Msg:  "invalid token",
+				Err:  err,
+			}
+		}
+		return nil, err
+	}
+
+	if err := CheckAuthorization(ctx, t.s, a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// maxAuthorizationDepth bounds the chain of parents of a derived authorization that is checked.
+const maxAuthorizationDepth = 16
+
+// CheckAuthorization returns an unauthorized error if a, or any authorization it was derived
+// from, is expired, or if any authorization it was derived from is inactive. The parents of a
+// are found in s. It is shared by every use of an authorization found other than by its token,
+// such as the runs of a task, so that they are checked like the tokens of requests.
+func CheckAuthorization(ctx context.Context, s influxdb.AuthorizationService, a *influxdb.Authorization) error {
+	if err := a.Expired(); err != nil {
+		return err
+	}
+
+	for i := 0; a.ParentID.Valid(); i++ {
+		if i == maxAuthorizationDepth {
+			return &influxdb.Error{
+				Code: influxdb.EUnauthorized,
+				Msg:  "authorization is derived too many times",
+			}
+		}
+
+		parent, err := s.FindAuthorizationByID(ctx, a.ParentID)
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EUnauthorized,
+				Msg:  "parent authorization not found",
+				Err:  err,
+			}
+		}
+		if !parent.IsActive() {
+			return &influxdb.Error{
+				Code: influxdb.EUnauthorized,
+				Msg:  "parent authorization is inactive",
+			}
+		}
+		if err := parent.Expired(); err != nil {
+			return err
+		}
+		a = parent
+	}
+
+	return nil
+}
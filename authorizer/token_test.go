@@ -0,0 +1,114 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestTokenAuthenticator_Authenticate(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	auths := map[string]*influxdb.Authorization{
+		"active":          {ID: 1, Token: "active", Status: influxdb.Active},
+		"inactive":        {ID: 2, Token: "inactive", Status: influxdb.Inactive},
+		"expired":         {ID: 3, Token: "expired", Status: influxdb.Active, ExpiresAt: &past},
+		"derived":         {ID: 4, Token: "derived", Status: influxdb.Active, ParentID: 1},
+		"inactive parent": {ID: 5, Token: "inactive parent", Status: influxdb.Active, ParentID: 2},
+		"expired parent":  {ID: 6, Token: "expired parent", Status: influxdb.Active, ParentID: 3},
+		"missing parent":  {ID: 7, Token: "missing parent", Status: influxdb.Active, ParentID: 100},
+		"cycle":           {ID: 8, Token: "cycle", Status: influxdb.Active, ParentID: 8},
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		id    influxdb.ID
+		code  string
+	}{
+		{name: "valid token", token: "active", id: 1},
+		{name: "unknown token", token: "unknown", code: influxdb.EUnauthorized},
+		{name: "inactive authorization", token: "inactive", id: 2},
+		{name: "expired authorization", token: "expired", code: influxdb.EUnauthorized},
+		{name: "derived authorization", token: "derived", id: 4},
+		{name: "inactive parent", token: "inactive parent", code: influxdb.EUnauthorized},
+		{name: "expired parent", token: "expired parent", code: influxdb.EUnauthorized},
+		{name: "missing parent", token: "missing parent", code: influxdb.EUnauthorized},
+		{name: "parent cycle", token: "cycle", code: influxdb.EUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := mock.NewAuthorizationService()
+			s.FindAuthorizationByTokenFn = func(ctx context.Context, token string) (*influxdb.Authorization, error) {
+				if a, ok := auths[token]; ok {
+					return a, nil
+				}
+				return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "authorization not found"}
+			}
+			s.FindAuthorizationByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+				for _, a := range auths {
+					if a.ID == id {
+						return a, nil
+					}
+				}
+				return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "authorization not found"}
+			}
+
+			a, err := authorizer.NewTokenAuthenticator(s, nil).Authenticate(context.Background(), tt.token)
+			if code := influxdb.ErrorCode(err); code != tt.code {
+				t.Fatalf("unexpected error code -got/+exp\n%s\n%s", code, tt.code)
+			}
+			if err == nil && a.Identifier() != tt.id {
+				t.Errorf("unexpected authorization -got/+exp\n%s\n%s", a.Identifier(), tt.id)
+			}
+		})
+	}
+}
+
+func TestTokenAuthenticator_Authorizer(t *testing.T) {
+	writeBuckets := influxdb.Permission{
+		Action: influxdb.WriteAction,
+		Resource: influxdb.Resource{
+			Type:  influxdb.BucketsResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+
+	s := mock.NewAuthorizationService()
+	s.FindAuthorizationByTokenFn = func(ctx context.Context, token string) (*influxdb.Authorization, error) {
+		if token != "role" {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "authorization not found"}
+		}
+		return &influxdb.Authorization{ID: 1, Token: "role", Status: influxdb.Active, OrgID: 10, RoleIDs: []influxdb.ID{2}}, nil
+	}
+	roles := mock.NewRoleService()
+	roles.FindRoleByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+		return &influxdb.Role{ID: id, OrgID: 10, Permissions: []influxdb.Permission{writeBuckets}}, nil
+	}
+
+	// Without a role resolver, the authorizer only has the permissions of its authorization.
+	a, err := authorizer.NewTokenAuthenticator(s, nil).Authorizer(context.Background(), "role")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Allowed(writeBuckets) {
+		t.Error("expected authorizer without the permissions of its roles")
+	}
+
+	a, err = authorizer.NewTokenAuthenticator(s, authorizer.NewRoleResolver(roles, mock.NewUserResourceMappingService())).Authorizer(context.Background(), "role")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.Allowed(writeBuckets) {
+		t.Error("expected authorizer with the permissions of its roles")
+	}
+
+	if _, err := authorizer.NewTokenAuthenticator(s, nil).Authorizer(context.Background(), "unknown"); influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+		t.Errorf("unexpected error code -got/+exp\n%s\n%s", influxdb.ErrorCode(err), influxdb.EUnauthorized)
+	}
+}
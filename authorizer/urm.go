@@ -11,14 +11,16 @@ type OrganizationService interface {
 }
 
 type URMService struct {
-	s          influxdb.UserResourceMappingService
-	orgService OrganizationService
+	s           influxdb.UserResourceMappingService
+	orgService  OrganizationService
+	roleService influxdb.RoleService
 }
 
-func NewURMService(orgSvc OrganizationService, s influxdb.UserResourceMappingService) *URMService {
+func NewURMService(orgSvc OrganizationService, s influxdb.UserResourceMappingService, roleSvc influxdb.RoleService) *URMService {
 	return &URMService{
-		s:          s,
-		orgService: orgSvc,
+		s:           s,
+		orgService:  orgSvc,
+		roleService: roleSvc,
 	}
 }
 
@@ -85,6 +87,13 @@ func (s *URMService) CreateUserResourceMapping(ctx context.Context, m *influxdb.
 		return err
 	}
 
+	// Making a user a member of a role grants the user the permissions of the role.
+	if m.ResourceType == influxdb.RolesResourceType {
+		if err := verifyRole(ctx, s.roleService, m.ResourceID); err != nil {
+			return err
+		}
+	}
+
 	return s.s.CreateUserResourceMapping(ctx, m)
 }
 
@@ -128,7 +128,7 @@ func TestURMService_FindUserResourceMappings(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := authorizer.NewURMService(tt.fields.OrgService, tt.fields.UserResourceMappingService)
+			s := authorizer.NewURMService(tt.fields.OrgService, tt.fields.UserResourceMappingService, mock.NewRoleService())
 
 			ctx := context.Background()
 			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{[]influxdb.Permission{tt.args.permission}})
@@ -238,7 +238,7 @@ func TestURMService_WriteUserResourceMapping(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := authorizer.NewURMService(tt.fields.OrgService, tt.fields.UserResourceMappingService)
+			s := authorizer.NewURMService(tt.fields.OrgService, tt.fields.UserResourceMappingService, mock.NewRoleService())
 
 			ctx := context.Background()
 			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{[]influxdb.Permission{tt.args.permission}})
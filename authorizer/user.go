@@ -125,11 +125,24 @@ func (s *UserService) CreateUser(ctx context.Context, o *influxdb.User) error {
 }
 
 // UpdateUser checks to see if the authorizer on context has write access to the user provided.
+// Changing the origin of a user requires write access to the global users resource, so that
+// only administrators can hand a local user over to a LDAP server.
 func (s *UserService) UpdateUser(ctx context.Context, id influxdb.ID, upd influxdb.UserUpdate) (*influxdb.User, error) {
 	if err := authorizeWriteUser(ctx, id); err != nil {
 		return nil, err
 	}
 
+	if upd.Origin != nil {
+		p, err := influxdb.NewGlobalPermission(influxdb.WriteAction, influxdb.UsersResourceType)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := IsAllowed(ctx, *p); err != nil {
+			return nil, err
+		}
+	}
+
 	return s.s.UpdateUser(ctx, id, upd)
 }
 
@@ -317,11 +317,14 @@ func TestUserService_UpdateUser(t *testing.T) {
 	type args struct {
 		id         influxdb.ID
 		permission influxdb.Permission
+		upd        influxdb.UserUpdate
 	}
 	type wants struct {
 		err error
 	}
 
+	ldapOrigin := influxdb.UserOriginLDAP
+
 	tests := []struct {
 		name   string
 		fields fields
@@ -381,6 +384,60 @@ func TestUserService_UpdateUser(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "unauthorized to link user to LDAP",
+			fields: fields{
+				UserService: &mock.UserService{
+					UpdateUserFn: func(ctx context.Context, id influxdb.ID, upd influxdb.UserUpdate) (*influxdb.User, error) {
+						return &influxdb.User{
+							ID: 1,
+						}, nil
+					},
+				},
+			},
+			args: args{
+				id: 1,
+				permission: influxdb.Permission{
+					Action: "write",
+					Resource: influxdb.Resource{
+						Type: influxdb.UsersResourceType,
+						ID:   influxdbtesting.IDPtr(1),
+					},
+				},
+				upd: influxdb.UserUpdate{Origin: &ldapOrigin},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "write:users is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
+		{
+			name: "authorized to link user to LDAP",
+			fields: fields{
+				UserService: &mock.UserService{
+					UpdateUserFn: func(ctx context.Context, id influxdb.ID, upd influxdb.UserUpdate) (*influxdb.User, error) {
+						return &influxdb.User{
+							ID: 1,
+						}, nil
+					},
+				},
+			},
+			args: args{
+				id: 1,
+				permission: influxdb.Permission{
+					Action: "write",
+					Resource: influxdb.Resource{
+						Type: influxdb.UsersResourceType,
+					},
+				},
+				upd: influxdb.UserUpdate{Origin: &ldapOrigin},
+			},
+			wants: wants{
+				err: nil,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -390,7 +447,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 			ctx := context.Background()
 			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{[]influxdb.Permission{tt.args.permission}})
 
-			_, err := s.UpdateUser(ctx, tt.args.id, influxdb.UserUpdate{})
+			_, err := s.UpdateUser(ctx, tt.args.id, tt.args.upd)
 			influxdbtesting.ErrorsEqual(t, err, tt.wants.err)
 		})
 	}
@@ -40,6 +40,38 @@ func PermissionAllowed(perm Permission, ps []Permission) bool {
 	return false
 }
 
+// PermissionRestrictions determines if a permission is allowed when the data
+// it gives access to may be restricted. It returns the restrictions of the
+// permissions in ps that allow perm, or no restrictions if one of them allows
+// perm on all of the data.
+func PermissionRestrictions(perm Permission, ps []Permission) ([]*Restriction, bool) {
+	var rs []*Restriction
+	for _, p := range ps {
+		r := p.Restriction
+		p.Restriction = nil
+		if !p.Matches(perm) {
+			continue
+		}
+		if r == nil {
+			return nil, true
+		}
+		rs = append(rs, r)
+	}
+	return rs, len(rs) > 0
+}
+
+// AuthorizerRestrictions determines if a permission is allowed by the authorizer
+// when the data it gives access to may be restricted, as PermissionRestrictions.
+// Authorizers whose permissions are never restricted are allowed perm on all of the data.
+func AuthorizerRestrictions(a Authorizer, perm Permission) ([]*Restriction, bool) {
+	if r, ok := a.(interface {
+		Restrictions(Permission) ([]*Restriction, bool)
+	}); ok {
+		return r.Restrictions(perm)
+	}
+	return nil, a.Allowed(perm)
+}
+
 // Action is an enum defining all possible resource operations
 type Action string
 
@@ -122,6 +154,14 @@ const (
 	// ViewsResourceType gives permission to one or more views.
 	ViewsResourceType     = ResourceType("views")     // 12
 	DocumentsResourceType = ResourceType("documents") // 13
+	// ReplicationsResourceType gives permission to one or more replications.
+	ReplicationsResourceType = ResourceType("replications") // 14
+	// QueriesResourceType gives permission to one or more running queries.
+	QueriesResourceType = ResourceType("queries") // 15
+	// AuditResourceType gives permission to the audit log of changes to resources.
+	AuditResourceType = ResourceType("audit") // 16
+	// RolesResourceType gives permission to one or more roles.
+	RolesResourceType = ResourceType("roles") // 17
 )
 
 // AllResourceTypes is the list of all known resource types.
@@ -140,20 +180,28 @@ var AllResourceTypes = []ResourceType{
 	LabelsResourceType,         // 11
 	ViewsResourceType,          // 12
 	DocumentsResourceType,      // 13
+	ReplicationsResourceType,   // 14
+	QueriesResourceType,        // 15
+	AuditResourceType,          // 16
+	RolesResourceType,          // 17
 	// NOTE: when modifying this list, please update the swagger for components.schemas.Permission resource enum.
 }
 
 // OrgResourceTypes is the list of all known resource types that belong to an organization.
 var OrgResourceTypes = []ResourceType{
-	BucketsResourceType,    // 1
-	DashboardsResourceType, // 2
-	SourcesResourceType,    // 4
-	TasksResourceType,      // 5
-	TelegrafsResourceType,  // 6
-	UsersResourceType,      // 7
-	VariablesResourceType,  // 8
-	SecretsResourceType,    // 10
-	DocumentsResourceType,  //13
+	BucketsResourceType,      // 1
+	DashboardsResourceType,   // 2
+	SourcesResourceType,      // 4
+	TasksResourceType,        // 5
+	TelegrafsResourceType,    // 6
+	UsersResourceType,        // 7
+	VariablesResourceType,    // 8
+	SecretsResourceType,      // 10
+	DocumentsResourceType,    //13
+	ReplicationsResourceType, // 14
+	QueriesResourceType,      // 15
+	AuditResourceType,        // 16
+	RolesResourceType,        // 17
 }
 
 // Valid checks if the resource type is a member of the ResourceType enum.
@@ -178,6 +226,10 @@ func (t ResourceType) Valid() (err error) {
 	case LabelsResourceType: // 11
 	case ViewsResourceType: // 12
 	case DocumentsResourceType: // 13
+	case ReplicationsResourceType: // 14
+	case QueriesResourceType: // 15
+	case AuditResourceType: // 16
+	case RolesResourceType: // 17
 	default:
 		err = ErrInvalidResourceType
 	}
@@ -189,14 +241,22 @@ func (t ResourceType) Valid() (err error) {
 type Permission struct {
 	Action   Action   `json:"action"`
 	Resource Resource `json:"resource"`
+
+	// Restriction, if set, restricts a permission on buckets to some of their data.
+	Restriction *Restriction `json:"restriction,omitempty"`
 }
 
 // Matches returns whether or not one permission matches the other.
+// A restricted permission only matches permissions restricted at least as much.
 func (p Permission) Matches(perm Permission) bool {
 	if p.Action != perm.Action {
 		return false
 	}
 
+	if !p.Restriction.Covers(perm.Restriction) {
+		return false
+	}
+
 	if p.Resource.Type != perm.Resource.Type {
 		return false
 	}
@@ -229,6 +289,9 @@ func (p Permission) Matches(perm Permission) bool {
 }
 
 func (p Permission) String() string {
+	if p.Restriction != nil {
+		return fmt.Sprintf("%s:%s[%s]", p.Action, p.Resource, p.Restriction)
+	}
 	return fmt.Sprintf("%s:%s", p.Action, p.Resource)
 }
 
@@ -266,6 +329,18 @@ func (p *Permission) Valid() error {
 		}
 	}
 
+	if p.Restriction != nil {
+		if p.Resource.Type != BucketsResourceType {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "only permissions on buckets can be restricted",
+			}
+		}
+		if err := p.Restriction.Valid(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
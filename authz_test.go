@@ -229,6 +229,108 @@ func TestAuthorizer_PermissionAllowed(t *testing.T) {
 			},
 			allowed: false,
 		},
+		{
+			name: "restricted permission does not allow unrestricted permission",
+			permission: platform.Permission{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:  platform.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+					ID:    influxdbtesting.IDPtr(1),
+				},
+			},
+			permissions: []platform.Permission{
+				{
+					Action: platform.ReadAction,
+					Resource: platform.Resource{
+						Type:  platform.BucketsResourceType,
+						OrgID: influxdbtesting.IDPtr(1),
+					},
+					Restriction: &platform.Restriction{
+						Tags: []platform.RestrictionTag{{Key: "team", Value: "payments"}},
+					},
+				},
+			},
+			allowed: false,
+		},
+		{
+			name: "restricted permission allows narrower restriction",
+			permission: platform.Permission{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:  platform.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+					ID:    influxdbtesting.IDPtr(1),
+				},
+				Restriction: &platform.Restriction{
+					Measurements: []string{"cpu"},
+					Tags:         []platform.RestrictionTag{{Key: "team", Value: "payments"}},
+				},
+			},
+			permissions: []platform.Permission{
+				{
+					Action: platform.ReadAction,
+					Resource: platform.Resource{
+						Type:  platform.BucketsResourceType,
+						OrgID: influxdbtesting.IDPtr(1),
+					},
+					Restriction: &platform.Restriction{
+						Measurements: []string{"cpu", "mem"},
+					},
+				},
+			},
+			allowed: true,
+		},
+		{
+			name: "restricted permission does not allow wider restriction",
+			permission: platform.Permission{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:  platform.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+					ID:    influxdbtesting.IDPtr(1),
+				},
+				Restriction: &platform.Restriction{
+					Measurements: []string{"cpu", "disk"},
+				},
+			},
+			permissions: []platform.Permission{
+				{
+					Action: platform.ReadAction,
+					Resource: platform.Resource{
+						Type:  platform.BucketsResourceType,
+						OrgID: influxdbtesting.IDPtr(1),
+					},
+					Restriction: &platform.Restriction{
+						Measurements: []string{"cpu", "mem"},
+					},
+				},
+			},
+			allowed: false,
+		},
+		{
+			name: "unrestricted permission allows restricted permission",
+			permission: platform.Permission{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:  platform.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+					ID:    influxdbtesting.IDPtr(1),
+				},
+				Restriction: &platform.Restriction{
+					Measurements: []string{"cpu"},
+				},
+			},
+			permissions: []platform.Permission{
+				{
+					Action: platform.ReadAction,
+					Resource: platform.Resource{
+						Type: platform.BucketsResourceType,
+					},
+				},
+			},
+			allowed: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -243,8 +345,9 @@ func TestAuthorizer_PermissionAllowed(t *testing.T) {
 
 func TestPermission_Valid(t *testing.T) {
 	type fields struct {
-		Action   platform.Action
-		Resource platform.Resource
+		Action      platform.Action
+		Resource    platform.Resource
+		Restriction *platform.Restriction
 	}
 	tests := []struct {
 		name    string
@@ -302,12 +405,67 @@ func TestPermission_Valid(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid restricted bucket permission",
+			fields: fields{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:  platform.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+				},
+				Restriction: &platform.Restriction{
+					Measurements: []string{"cpu"},
+					Tags:         []platform.RestrictionTag{{Key: "team", Value: "payments"}},
+				},
+			},
+		},
+		{
+			name: "invalid empty restriction",
+			fields: fields{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:  platform.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+				},
+				Restriction: &platform.Restriction{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid restriction of a reserved tag key",
+			fields: fields{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:  platform.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+				},
+				Restriction: &platform.Restriction{
+					Tags: []platform.RestrictionTag{{Key: "_measurement", Value: "cpu"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid restricted permission on other resources than buckets",
+			fields: fields{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:  platform.TasksResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+				},
+				Restriction: &platform.Restriction{
+					Measurements: []string{"cpu"},
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			p := &platform.Permission{
-				Action:   tt.fields.Action,
-				Resource: tt.fields.Resource,
+				Action:      tt.fields.Action,
+				Resource:    tt.fields.Resource,
+				Restriction: tt.fields.Restriction,
 			}
 			if err := p.Valid(); (err != nil) != tt.wantErr {
 				t.Errorf("Permission.Valid() error = %v, wantErr %v", err, tt.wantErr)
@@ -316,6 +474,33 @@ func TestPermission_Valid(t *testing.T) {
 	}
 }
 
+func TestPermissionRestrictions(t *testing.T) {
+	payments := &platform.Restriction{Tags: []platform.RestrictionTag{{Key: "team", Value: "payments"}}}
+	cpu := &platform.Restriction{Measurements: []string{"cpu"}}
+	read := func(id platform.ID, r *platform.Restriction) platform.Permission {
+		return platform.Permission{
+			Action:      platform.ReadAction,
+			Resource:    platform.Resource{Type: platform.BucketsResourceType, OrgID: influxdbtesting.IDPtr(1), ID: &id},
+			Restriction: r,
+		}
+	}
+
+	perm := read(1, nil)
+	rs, ok := platform.PermissionRestrictions(perm, []platform.Permission{read(1, payments), read(2, cpu), read(1, cpu)})
+	if !ok || len(rs) != 2 || rs[0] != payments || rs[1] != cpu {
+		t.Errorf("expected the restrictions of bucket 1, got %v, %v", rs, ok)
+	}
+
+	rs, ok = platform.PermissionRestrictions(perm, []platform.Permission{read(1, payments), read(1, nil)})
+	if !ok || len(rs) != 0 {
+		t.Errorf("expected no restrictions, got %v, %v", rs, ok)
+	}
+
+	if _, ok := platform.PermissionRestrictions(perm, []platform.Permission{read(2, payments)}); ok {
+		t.Errorf("expected permission not to be allowed")
+	}
+}
+
 func TestPermissionAllResources_Valid(t *testing.T) {
 	var resources = []platform.ResourceType{
 		platform.UsersResourceType,
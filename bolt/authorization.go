@@ -402,7 +402,7 @@ func (c *Client) deleteAuthorization(ctx context.Context, tx *bolt.Tx, id platfo
 	return nil
 }
 
-// UpdateAuthorization updates the status and description if available.
+// UpdateAuthorization updates the status, description and expiry if available.
 func (c *Client) UpdateAuthorization(ctx context.Context, id platform.ID, upd *platform.AuthorizationUpdate) (*platform.Authorization, error) {
 	var a *platform.Authorization
 	err := c.db.Update(func(tx *bolt.Tx) error {
@@ -431,6 +431,15 @@ func (c *Client) updateAuthorization(ctx context.Context, tx *bolt.Tx, id platfo
 	if upd.Description != nil {
 		a.Description = *upd.Description
 	}
+	if upd.ExpiresAt != nil {
+		a.ExpiresAt = upd.ExpiresAt
+	}
+	if upd.ClearExpiresAt {
+		a.ExpiresAt = nil
+	}
+	if upd.LastUsedAt != nil {
+		a.LastUsedAt = upd.LastUsedAt
+	}
 
 	b, err := encodeAuthorization(a)
 	if err != nil {
@@ -523,6 +523,15 @@ func (c *Client) updateBucket(ctx context.Context, tx *bolt.Tx, id platform.ID,
 		b.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.StorageQuotaBytes != nil {
+		if err := platform.ValidStorageQuota(*upd.StorageQuotaBytes); err != nil {
+			return nil, &platform.Error{
+				Err: err,
+			}
+		}
+		b.StorageQuotaBytes = *upd.StorageQuotaBytes
+	}
+
 	if upd.Description != nil {
 		b.Description = *upd.Description
 	}
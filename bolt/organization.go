@@ -365,6 +365,27 @@ func (c *Client) updateOrganization(ctx context.Context, tx *bolt.Tx, id influxd
 		o.Description = *upd.Description
 	}
 
+	if upd.QueryLimits != nil {
+		if err := upd.QueryLimits.Valid(); err != nil {
+			return nil, &influxdb.Error{
+				Err: err,
+			}
+		}
+		o.QueryLimits = upd.QueryLimits
+		if o.QueryLimits.IsZero() {
+			o.QueryLimits = nil
+		}
+	}
+
+	if upd.StorageQuotaBytes != nil {
+		if err := influxdb.ValidStorageQuota(*upd.StorageQuotaBytes); err != nil {
+			return nil, &influxdb.Error{
+				Err: err,
+			}
+		}
+		o.StorageQuotaBytes = *upd.StorageQuotaBytes
+	}
+
 	o.UpdatedAt = c.Now()
 
 	if err := c.appendOrganizationEventToLog(ctx, tx, o.ID, organizationUpdatedEvent); err != nil {
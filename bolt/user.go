@@ -324,6 +324,10 @@ func (c *Client) updateUser(ctx context.Context, tx *bolt.Tx, id platform.ID, up
 		u.Name = *upd.Name
 	}
 
+	if upd.Origin != nil {
+		u.Origin = *upd.Origin
+	}
+
 	if err := c.appendUserEventToLog(ctx, tx, u.ID, userUpdatedEvent); err != nil {
 		return nil, &platform.Error{
 			Err: err,
@@ -26,6 +26,11 @@ type Bucket struct {
 	Description         string        `json:"description"`
 	RetentionPolicyName string        `json:"rp,omitempty"` // This to support v1 sources
 	RetentionPeriod     time.Duration `json:"retentionPeriod"`
+	// Schema, if set, is the schema points written to the bucket must satisfy.
+	Schema *BucketSchema `json:"schema,omitempty"`
+	// StorageQuotaBytes, if set, is the disk space the data of the bucket may
+	// use before writes to it are rejected.
+	StorageQuotaBytes int64 `json:"storageQuotaBytes,omitempty"`
 	CRUDLog
 }
 
@@ -68,6 +73,9 @@ type BucketUpdate struct {
 	Name            *string        `json:"name,omitempty"`
 	Description     *string        `json:"description,omitempty"`
 	RetentionPeriod *time.Duration `json:"retentionPeriod,omitempty"`
+	// StorageQuotaBytes replaces the storage quota of the bucket.
+	// Setting zero removes the quota.
+	StorageQuotaBytes *int64 `json:"storageQuotaBytes,omitempty"`
 }
 
 // BucketFilter represents a set of filter that restrict the returned results.
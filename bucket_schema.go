@@ -0,0 +1,203 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// ops for bucket schema errors.
+var (
+	OpFindBucketSchema   = "FindBucketSchema"
+	OpPutBucketSchema    = "PutBucketSchema"
+	OpDeleteBucketSchema = "DeleteBucketSchema"
+)
+
+// SchemaType is the schema enforcement mode of a bucket.
+type SchemaType string
+
+const (
+	// SchemaTypeImplicit buckets accept any points.
+	SchemaTypeImplicit SchemaType = "implicit"
+	// SchemaTypeExplicit buckets only accept points satisfying their schema.
+	SchemaTypeExplicit SchemaType = "explicit"
+)
+
+// FieldType is the type of the values of a field.
+type FieldType string
+
+// Field types of bucket schemas.
+const (
+	FieldTypeFloat    FieldType = "float"
+	FieldTypeInteger  FieldType = "integer"
+	FieldTypeUnsigned FieldType = "unsigned"
+	FieldTypeString   FieldType = "string"
+	FieldTypeBoolean  FieldType = "boolean"
+)
+
+// Valid returns an error if the field type is unknown.
+func (t FieldType) Valid() error {
+	switch t {
+	case FieldTypeFloat, FieldTypeInteger, FieldTypeUnsigned, FieldTypeString, FieldTypeBoolean:
+		return nil
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("unknown field type %q", t),
+	}
+}
+
+// BucketSchema is the explicit schema of a bucket. Points written to a bucket
+// with a schema must be of one of its measurements, have only tag keys of the
+// measurement, and have fields of the measurement of the declared types.
+type BucketSchema struct {
+	Measurements []MeasurementSchema `json:"measurements"`
+}
+
+// MeasurementSchema is the schema of a measurement of a bucket.
+type MeasurementSchema struct {
+	Name   string        `json:"name"`
+	Tags   []string      `json:"tags"`
+	Fields []FieldSchema `json:"fields"`
+}
+
+// FieldSchema is a field of a measurement and the type of its values.
+type FieldSchema struct {
+	Name string    `json:"name"`
+	Type FieldType `json:"type"`
+}
+
+// BucketSchemaService manages the explicit schemas of buckets.
+type BucketSchemaService interface {
+	// FindBucketSchema returns the schema of a bucket.
+	// Buckets without a schema return a not found error.
+	FindBucketSchema(ctx context.Context, bucketID ID) (*BucketSchema, error)
+
+	// PutBucketSchema replaces the schema of a bucket, making the bucket explicit.
+	PutBucketSchema(ctx context.Context, bucketID ID, s *BucketSchema) error
+
+	// DeleteBucketSchema removes the schema of a bucket, making the bucket implicit.
+	DeleteBucketSchema(ctx context.Context, bucketID ID) error
+}
+
+// SchemaType returns whether the bucket has an explicit schema.
+func (b *Bucket) SchemaType() SchemaType {
+	if b.Schema != nil {
+		return SchemaTypeExplicit
+	}
+	return SchemaTypeImplicit
+}
+
+// Valid returns an error if a measurement, tag key or field is unnamed,
+// reserved, or declared more than once.
+func (s *BucketSchema) Valid() error {
+	measurements := make(map[string]bool, len(s.Measurements))
+	for _, m := range s.Measurements {
+		if m.Name == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "schema measurement name cannot be empty",
+			}
+		}
+		if measurements[m.Name] {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("schema measurement %q is declared more than once", m.Name),
+			}
+		}
+		measurements[m.Name] = true
+
+		keys := make(map[string]bool, len(m.Tags)+len(m.Fields))
+		for _, t := range m.Tags {
+			if err := validSchemaKey(m.Name, t, keys); err != nil {
+				return err
+			}
+		}
+		for _, f := range m.Fields {
+			if err := validSchemaKey(m.Name, f.Name, keys); err != nil {
+				return err
+			}
+			if err := f.Type.Valid(); err != nil {
+				return &Error{
+					Code: EInvalid,
+					Msg:  fmt.Sprintf("field %q of schema measurement %q has unknown type %q", f.Name, m.Name, f.Type),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validSchemaKey(measurement, key string, keys map[string]bool) error {
+	switch {
+	case key == "":
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("schema measurement %q has an empty tag key or field", measurement),
+		}
+	case key == "_measurement" || key == "_field":
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("schema measurement %q uses reserved key %q", measurement, key),
+		}
+	case keys[key]:
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("schema measurement %q declares %q more than once", measurement, key),
+		}
+	}
+	keys[key] = true
+	return nil
+}
+
+// Check returns an error telling why a point of the measurement with the tag
+// keys and a field of type typ does not satisfy the schema.
+func (s *BucketSchema) Check(measurement string, tagKeys []string, field string, typ FieldType) error {
+	var m *MeasurementSchema
+	for i := range s.Measurements {
+		if s.Measurements[i].Name == measurement {
+			m = &s.Measurements[i]
+			break
+		}
+	}
+	if m == nil {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("measurement %q is not in the schema", measurement),
+		}
+	}
+
+	for _, k := range tagKeys {
+		if !m.hasTag(k) {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("tag key %q is not in the schema of measurement %q", k, measurement),
+			}
+		}
+	}
+
+	for _, f := range m.Fields {
+		if f.Name != field {
+			continue
+		}
+		if f.Type != typ {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("field %q of measurement %q is %s, not %s", field, measurement, f.Type, typ),
+			}
+		}
+		return nil
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("field %q is not in the schema of measurement %q", field, measurement),
+	}
+}
+
+func (m *MeasurementSchema) hasTag(key string) bool {
+	for _, t := range m.Tags {
+		if t == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBucketSchema_Valid(t *testing.T) {
+	tests := []struct {
+		name string
+		m    influxdb.MeasurementSchema
+		err  error
+	}{
+		{
+			name: "valid",
+			m: influxdb.MeasurementSchema{
+				Name:   "cpu",
+				Tags:   []string{"host"},
+				Fields: []influxdb.FieldSchema{{Name: "usage", Type: influxdb.FieldTypeFloat}},
+			},
+		},
+		{
+			name: "unnamed measurement",
+			m:    influxdb.MeasurementSchema{},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "schema measurement name cannot be empty",
+			},
+		},
+		{
+			name: "reserved key",
+			m: influxdb.MeasurementSchema{
+				Name: "cpu",
+				Tags: []string{"_field"},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `schema measurement "cpu" uses reserved key "_field"`,
+			},
+		},
+		{
+			name: "unknown field type",
+			m: influxdb.MeasurementSchema{
+				Name:   "cpu",
+				Fields: []influxdb.FieldSchema{{Name: "usage", Type: "double"}},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `field "usage" of schema measurement "cpu" has unknown type "double"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &influxdb.BucketSchema{Measurements: []influxdb.MeasurementSchema{tt.m}}
+			influxdbtesting.ErrorsEqual(t, s.Valid(), tt.err)
+		})
+	}
+
+	s := &influxdb.BucketSchema{Measurements: []influxdb.MeasurementSchema{{Name: "cpu"}, {Name: "cpu"}}}
+	influxdbtesting.ErrorsEqual(t, s.Valid(), &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  `schema measurement "cpu" is declared more than once`,
+	})
+}
+
+func TestBucketSchema_Check(t *testing.T) {
+	s := &influxdb.BucketSchema{
+		Measurements: []influxdb.MeasurementSchema{
+			{
+				Name:   "cpu",
+				Tags:   []string{"host", "region"},
+				Fields: []influxdb.FieldSchema{{Name: "usage", Type: influxdb.FieldTypeFloat}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		measurement string
+		tags        []string
+		field       string
+		typ         influxdb.FieldType
+		msg         string
+	}{
+		{
+			name:        "satisfied",
+			measurement: "cpu",
+			tags:        []string{"region"},
+			field:       "usage",
+			typ:         influxdb.FieldTypeFloat,
+		},
+		{
+			name:        "unknown measurement",
+			measurement: "cpus",
+			field:       "usage",
+			typ:         influxdb.FieldTypeFloat,
+			msg:         `measurement "cpus" is not in the schema`,
+		},
+		{
+			name:        "unknown tag key",
+			measurement: "cpu",
+			tags:        []string{"host", "rack"},
+			field:       "usage",
+			typ:         influxdb.FieldTypeFloat,
+			msg:         `tag key "rack" is not in the schema of measurement "cpu"`,
+		},
+		{
+			name:        "unknown field",
+			measurement: "cpu",
+			field:       "idle",
+			typ:         influxdb.FieldTypeFloat,
+			msg:         `field "idle" is not in the schema of measurement "cpu"`,
+		},
+		{
+			name:        "field type",
+			measurement: "cpu",
+			field:       "usage",
+			typ:         influxdb.FieldTypeInteger,
+			msg:         `field "usage" of measurement "cpu" is float, not integer`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want error
+			if tt.msg != "" {
+				want = &influxdb.Error{Code: influxdb.EInvalid, Msg: tt.msg}
+			}
+			influxdbtesting.ErrorsEqual(t, s.Check(tt.measurement, tt.tags, tt.field, tt.typ), want)
+		})
+	}
+}
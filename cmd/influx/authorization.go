@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/cmd/influx/internal"
@@ -33,6 +36,11 @@ type AuthorizationCreateFlags struct {
 	writeBucketPermissions []string
 	readBucketPermissions  []string
 
+	measurements []string
+	tags         []string
+
+	roles []string
+
 	writeTasksPermission bool
 	readTasksPermission  bool
 
@@ -44,6 +52,9 @@ type AuthorizationCreateFlags struct {
 
 	writeDashboardsPermission bool
 	readDashboardsPermission  bool
+
+	expiresIn time.Duration
+	derive    bool
 }
 
 var authorizationCreateFlags AuthorizationCreateFlags
@@ -68,6 +79,10 @@ func init() {
 
 	authorizationCreateCmd.Flags().StringArrayVarP(&authorizationCreateFlags.writeBucketPermissions, "write-bucket", "", []string{}, "The bucket id")
 	authorizationCreateCmd.Flags().StringArrayVarP(&authorizationCreateFlags.readBucketPermissions, "read-bucket", "", []string{}, "The bucket id")
+	authorizationCreateCmd.Flags().StringArrayVarP(&authorizationCreateFlags.measurements, "measurement", "", []string{}, "Restricts the bucket permissions to the series of the measurement")
+	authorizationCreateCmd.Flags().StringArrayVarP(&authorizationCreateFlags.tags, "tag", "", []string{}, "Restricts the bucket permissions to the series with the tag value, as key=value")
+
+	authorizationCreateCmd.Flags().StringArrayVarP(&authorizationCreateFlags.roles, "role", "", []string{}, "The ID of a role of the organization whose permissions are granted")
 
 	authorizationCreateCmd.Flags().BoolVarP(&authorizationCreateFlags.writeTasksPermission, "write-tasks", "", false, "Grants the permission to create tasks")
 	authorizationCreateCmd.Flags().BoolVarP(&authorizationCreateFlags.readTasksPermission, "read-tasks", "", false, "Grants the permission to read tasks")
@@ -81,10 +96,41 @@ func init() {
 	authorizationCreateCmd.Flags().BoolVarP(&authorizationCreateFlags.writeDashboardsPermission, "write-dashboards", "", false, "Grants the permission to create dashboards")
 	authorizationCreateCmd.Flags().BoolVarP(&authorizationCreateFlags.readDashboardsPermission, "read-dashboards", "", false, "Grants the permission to read dashboards")
 
+	authorizationCreateCmd.Flags().DurationVarP(&authorizationCreateFlags.expiresIn, "expires-in", "", 0, "Duration after which the authorization expires, e.g. 24h; it never expires if unset")
+	authorizationCreateCmd.Flags().BoolVarP(&authorizationCreateFlags.derive, "derive", "", false, "Derive the authorization from the token in use, with a subset of its permissions; it cannot outlive that token")
+
 	authorizationCmd.AddCommand(authorizationCreateCmd)
 }
 
+// authorizationRestriction returns the restriction of the bucket permissions
+// of the created authorization, or nil if they are not restricted.
+func authorizationRestriction() (*platform.Restriction, error) {
+	if len(authorizationCreateFlags.measurements) == 0 && len(authorizationCreateFlags.tags) == 0 {
+		return nil, nil
+	}
+
+	r := &platform.Restriction{Measurements: authorizationCreateFlags.measurements}
+	for _, t := range authorizationCreateFlags.tags {
+		kv := strings.SplitN(t, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("tag %q must be of the form key=value", t)
+		}
+		r.Tags = append(r.Tags, platform.RestrictionTag{Key: kv[0], Value: kv[1]})
+	}
+	return r, nil
+}
+
 func authorizationCreateF(cmd *cobra.Command, args []string) error {
+	if authorizationCreateFlags.expiresIn < 0 {
+		return fmt.Errorf("expires-in must be positive")
+	}
+	if authorizationCreateFlags.derive && authorizationCreateFlags.user != "" {
+		return fmt.Errorf("a derived authorization is for the user of the token in use; it cannot be combined with user")
+	}
+	if authorizationCreateFlags.derive && len(authorizationCreateFlags.roles) > 0 {
+		return fmt.Errorf("a derived authorization is granted permissions only; it cannot be combined with role")
+	}
+
 	var permissions []platform.Permission
 	orgSvc, err := newOrganizationService(flags)
 	if err != nil {
@@ -157,6 +203,23 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 		permissions = append(permissions, *p)
 	}
 
+	restriction, err := authorizationRestriction()
+	if err != nil {
+		return err
+	}
+	if restriction != nil {
+		var restricted bool
+		for i := range permissions {
+			if permissions[i].Resource.Type == platform.BucketsResourceType {
+				permissions[i].Restriction = restriction
+				restricted = true
+			}
+		}
+		if !restricted {
+			return fmt.Errorf("measurement and tag restrict bucket permissions; at least one must be granted")
+		}
+	}
+
 	if authorizationCreateFlags.writeTasksPermission {
 		p, err := platform.NewPermission(platform.WriteAction, platform.TasksResourceType, o.ID)
 		if err != nil {
@@ -226,6 +289,19 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 		OrgID:       o.ID,
 	}
 
+	for _, r := range authorizationCreateFlags.roles {
+		var id platform.ID
+		if err := id.DecodeFromString(r); err != nil {
+			return fmt.Errorf("failed to decode role id %q: %v", r, err)
+		}
+		authorization.RoleIDs = append(authorization.RoleIDs, id)
+	}
+
+	if authorizationCreateFlags.expiresIn > 0 {
+		expiresAt := time.Now().Add(authorizationCreateFlags.expiresIn).UTC()
+		authorization.ExpiresAt = &expiresAt
+	}
+
 	if userName := authorizationCreateFlags.user; userName != "" {
 		userSvc, err := newUserService(flags)
 		if err != nil {
@@ -245,7 +321,20 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if err := s.CreateAuthorization(context.Background(), authorization); err != nil {
+	if authorizationCreateFlags.derive {
+		ds, ok := s.(platform.AuthorizationDeriveService)
+		if !ok {
+			return fmt.Errorf("deriving an authorization requires a token and is not supported locally")
+		}
+		authorization, err = ds.DeriveAuthorization(ctx, &platform.AuthorizationDerive{
+			OrgID:       authorization.OrgID,
+			Permissions: authorization.Permissions,
+			ExpiresAt:   authorization.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+	} else if err := s.CreateAuthorization(ctx, authorization); err != nil {
 		return err
 	}
 
@@ -256,6 +345,7 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 		"Status",
 		"UserID",
 		"Permissions",
+		"ExpiresAt",
 	)
 
 	ps := []string{}
@@ -269,6 +359,7 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 		"Status":      authorization.Status,
 		"UserID":      authorization.UserID.String(),
 		"Permissions": ps,
+		"ExpiresAt":   formatExpiresAt(authorization.ExpiresAt),
 	})
 
 	w.Flush()
@@ -276,6 +367,13 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func formatExpiresAt(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
 // AuthorizationFindFlags are command line args used when finding a authorization
 type AuthorizationFindFlags struct {
 	user   string
@@ -3,10 +3,20 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/flux/repl"
 	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influx/internal"
+	"github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/query"
 	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/influxdata/influxdb/query/encoding"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -15,14 +25,19 @@ var queryCmd = &cobra.Command{
 	Use:   "query [query literal or @/path/to/query.flux]",
 	Short: "Execute a Flux query",
 	Long: `Execute a literal Flux query provided as a string,
-or execute a literal Flux query contained in a file by specifying the file prefixed with an @ sign.`,
+or execute a literal Flux query contained in a file by specifying the file prefixed with an @ sign.
+
+The results are printed as tables, unless a format is given. The formats are
+annotated CSV (csv), JSON (json), an Apache Arrow IPC stream for each table
+(arrow) and line protocol (lineprotocol), which can be written with influx write.`,
 	Args: cobra.ExactArgs(1),
 	RunE: wrapCheckSetup(fluxQueryF),
 }
 
 var queryFlags struct {
-	OrgID string
-	Org   string
+	OrgID  string
+	Org    string
+	Format string
 }
 
 func init() {
@@ -37,6 +52,8 @@ func init() {
 	if h := viper.GetString("ORG"); h != "" {
 		queryFlags.Org = h
 	}
+
+	queryCmd.Flags().StringVar(&queryFlags.Format, "format", "", "The format of the results: csv, json, arrow or lineprotocol")
 }
 
 func fluxQueryF(cmd *cobra.Command, args []string) error {
@@ -48,6 +65,21 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("must specify exactly one of org or org-id")
 	}
 
+	var dialect flux.Dialect
+	switch queryFlags.Format {
+	case "":
+	case http.QueryFormatCSV:
+		dialect = csv.DefaultDialect()
+	case http.QueryFormatJSON:
+		dialect = new(encoding.JSONDialect)
+	case http.QueryFormatArrow:
+		dialect = new(encoding.ArrowDialect)
+	case http.QueryFormatLineProtocol:
+		dialect = new(encoding.LineProtocolDialect)
+	default:
+		return fmt.Errorf("unknown format %q", queryFlags.Format)
+	}
+
 	q, err := repl.LoadQuery(args[0])
 	if err != nil {
 		return fmt.Errorf("failed to load query: %v", err)
@@ -76,6 +108,27 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 		orgID = o.ID
 	}
 
+	if dialect != nil {
+		s := &http.FluxService{
+			Addr:  flags.host,
+			Token: flags.token,
+		}
+		req := &query.ProxyRequest{
+			Request: query.Request{
+				OrganizationID: orgID,
+				Compiler: lang.FluxCompiler{
+					Query: q,
+					Now:   time.Now(),
+				},
+			},
+			Dialect: dialect,
+		}
+		if _, err := s.Query(context.Background(), os.Stdout, req); err != nil {
+			return fmt.Errorf("failed to execute query: %v", err)
+		}
+		return nil
+	}
+
 	r, err := getFluxREPL(flags.host, flags.token, orgID)
 	if err != nil {
 		return fmt.Errorf("failed to get the flux REPL: %v", err)
@@ -87,3 +140,415 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func newActiveQueryService() *http.ActiveQueryService {
+	return &http.ActiveQueryService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+}
+
+func writeActiveQueries(qs ...*query.ActiveQuery) {
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"OrgID",
+		"UserID",
+		"State",
+		"Elapsed",
+		"MemoryBytes",
+		"Query",
+	)
+	for _, q := range qs {
+		var userID string
+		if q.UserID.Valid() {
+			userID = q.UserID.String()
+		}
+		w.Write(map[string]interface{}{
+			"ID":          q.ID.String(),
+			"OrgID":       q.OrganizationID.String(),
+			"UserID":      userID,
+			"State":       q.State,
+			"Elapsed":     q.Elapsed.Round(time.Millisecond).String(),
+			"MemoryBytes": q.MemoryBytes,
+			"Query":       strings.Join(strings.Fields(q.Query), " "),
+		})
+	}
+	w.Flush()
+}
+
+func init() {
+	queryListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List running queries",
+		Long: `List the queries that are running. The queries of every organization the
+token can read the queries of are listed, unless org or org-id is given.`,
+		Args: cobra.NoArgs,
+		RunE: wrapCheckSetup(queryListF),
+	}
+
+	queryCmd.AddCommand(queryListCmd)
+}
+
+func queryListF(cmd *cobra.Command, args []string) error {
+	if flags.local {
+		return fmt.Errorf("local flag not supported for query list command")
+	}
+
+	if queryFlags.OrgID != "" && queryFlags.Org != "" {
+		return fmt.Errorf("must specify at most one of org or org-id")
+	}
+
+	filter := query.ActiveQueryFilter{}
+	if queryFlags.OrgID != "" {
+		id, err := platform.IDFromString(queryFlags.OrgID)
+		if err != nil {
+			return fmt.Errorf("failed to decode org-id: %v", err)
+		}
+		filter.OrganizationID = id
+	}
+
+	if queryFlags.Org != "" {
+		orgSvc, err := newOrganizationService(flags)
+		if err != nil {
+			return fmt.Errorf("failed to initialized organization service client: %v", err)
+		}
+
+		o, err := orgSvc.FindOrganization(context.Background(), platform.OrganizationFilter{Name: &queryFlags.Org})
+		if err != nil {
+			return fmt.Errorf("failed to retrieve organization %q: %v", queryFlags.Org, err)
+		}
+		filter.OrganizationID = &o.ID
+	}
+
+	qs, err := newActiveQueryService().FindActiveQueries(context.Background(), filter)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve queries: %v", err)
+	}
+
+	writeActiveQueries(qs...)
+	return nil
+}
+
+// QueryKillFlags define the Kill command
+type QueryKillFlags struct {
+	id string
+}
+
+var queryKillFlags QueryKillFlags
+
+func init() {
+	queryKillCmd := &cobra.Command{
+		Use:   "kill",
+		Short: "Cancel a running query",
+		Args:  cobra.NoArgs,
+		RunE:  wrapCheckSetup(queryKillF),
+	}
+
+	queryKillCmd.Flags().StringVarP(&queryKillFlags.id, "id", "i", "", "The query ID (required)")
+	queryKillCmd.MarkFlagRequired("id")
+
+	queryCmd.AddCommand(queryKillCmd)
+}
+
+func queryKillF(cmd *cobra.Command, args []string) error {
+	if flags.local {
+		return fmt.Errorf("local flag not supported for query kill command")
+	}
+
+	var id platform.ID
+	if err := id.DecodeFromString(queryKillFlags.id); err != nil {
+		return fmt.Errorf("failed to decode query id %q: %v", queryKillFlags.id, err)
+	}
+
+	s := newActiveQueryService()
+	ctx := context.Background()
+	q, err := s.FindActiveQueryByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to find query with id %q: %v", id, err)
+	}
+
+	if err := s.CancelActiveQuery(ctx, id); err != nil {
+		return fmt.Errorf("failed to cancel query with id %q: %v", id, err)
+	}
+
+	writeActiveQueries(q)
+	return nil
+}
+
+var queryJobCmd = &cobra.Command{
+	Use:   "job",
+	Short: "Query job management commands",
+	Long: `Submit queries to be executed in the background and read their results
+in pages once they are ready. The results of a job are kept for a while after
+it has finished, see the query-jobs-ttl flag of influxd.`,
+	Run: queryJobF,
+}
+
+func queryJobF(cmd *cobra.Command, args []string) {
+	cmd.Usage()
+}
+
+func init() {
+	queryCmd.AddCommand(queryJobCmd)
+}
+
+func newQueryJobService() *http.QueryJobService {
+	return &http.QueryJobService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+}
+
+func writeQueryJobs(js ...*query.Job) {
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"OrgID",
+		"UserID",
+		"Status",
+		"Pages",
+		"Rows",
+		"ExpiresAt",
+		"Error",
+		"Query",
+	)
+	for _, j := range js {
+		var userID, expiresAt string
+		if j.UserID.Valid() {
+			userID = j.UserID.String()
+		}
+		if !j.ExpiresAt.IsZero() {
+			expiresAt = j.ExpiresAt.Format(time.RFC3339)
+		}
+		w.Write(map[string]interface{}{
+			"ID":        j.ID.String(),
+			"OrgID":     j.OrganizationID.String(),
+			"UserID":    userID,
+			"Status":    string(j.Status),
+			"Pages":     j.Pages,
+			"Rows":      j.Rows,
+			"ExpiresAt": expiresAt,
+			"Error":     j.Error,
+			"Query":     strings.Join(strings.Fields(j.Query), " "),
+		})
+	}
+	w.Flush()
+}
+
+// queryJobOrgID returns the ID of the organization given by the org or
+// org-id flags, if any.
+func queryJobOrgID() (*platform.ID, error) {
+	if queryFlags.OrgID != "" && queryFlags.Org != "" {
+		return nil, fmt.Errorf("must specify at most one of org or org-id")
+	}
+
+	if queryFlags.OrgID != "" {
+		id, err := platform.IDFromString(queryFlags.OrgID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode org-id: %v", err)
+		}
+		return id, nil
+	}
+
+	if queryFlags.Org != "" {
+		orgSvc, err := newOrganizationService(flags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialized organization service client: %v", err)
+		}
+
+		o, err := orgSvc.FindOrganization(context.Background(), platform.OrganizationFilter{Name: &queryFlags.Org})
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve organization %q: %v", queryFlags.Org, err)
+		}
+		return &o.ID, nil
+	}
+	return nil, nil
+}
+
+func init() {
+	queryJobSubmitCmd := &cobra.Command{
+		Use:   "submit [query literal or @/path/to/query.flux]",
+		Short: "Submit a Flux query to be executed in the background",
+		Args:  cobra.ExactArgs(1),
+		RunE:  wrapCheckSetup(queryJobSubmitF),
+	}
+
+	queryJobCmd.AddCommand(queryJobSubmitCmd)
+}
+
+func queryJobSubmitF(cmd *cobra.Command, args []string) error {
+	if flags.local {
+		return fmt.Errorf("local flag not supported for query job submit command")
+	}
+
+	orgID, err := queryJobOrgID()
+	if err != nil {
+		return err
+	}
+	if orgID == nil {
+		return fmt.Errorf("must specify exactly one of org or org-id")
+	}
+
+	q, err := repl.LoadQuery(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to load query: %v", err)
+	}
+
+	j, err := newQueryJobService().SubmitJob(context.Background(), &query.Request{
+		OrganizationID: *orgID,
+		Compiler: lang.FluxCompiler{
+			Query: q,
+			Now:   time.Now(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to submit query job: %v", err)
+	}
+
+	writeQueryJobs(j)
+	return nil
+}
+
+func init() {
+	queryJobListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List query jobs",
+		Long: `List query jobs. The jobs of every organization the token can read the
+queries of are listed, unless org or org-id is given.`,
+		Args: cobra.NoArgs,
+		RunE: wrapCheckSetup(queryJobListF),
+	}
+
+	queryJobCmd.AddCommand(queryJobListCmd)
+}
+
+func queryJobListF(cmd *cobra.Command, args []string) error {
+	if flags.local {
+		return fmt.Errorf("local flag not supported for query job list command")
+	}
+
+	orgID, err := queryJobOrgID()
+	if err != nil {
+		return err
+	}
+
+	js, err := newQueryJobService().FindJobs(context.Background(), query.JobFilter{OrganizationID: orgID})
+	if err != nil {
+		return fmt.Errorf("failed to retrieve query jobs: %v", err)
+	}
+
+	writeQueryJobs(js...)
+	return nil
+}
+
+// QueryJobFlags define the flags of the query job commands of a single job.
+type QueryJobFlags struct {
+	id     string
+	page   int
+	format string
+}
+
+var queryJobFlags QueryJobFlags
+
+func init() {
+	queryJobFindCmd := &cobra.Command{
+		Use:   "find",
+		Short: "Find a query job",
+		Args:  cobra.NoArgs,
+		RunE:  wrapCheckSetup(queryJobFindF),
+	}
+
+	queryJobResultsCmd := &cobra.Command{
+		Use:   "results",
+		Short: "Print a page of the results of a query job",
+		Long: `Print a page of the results of a query job, numbered from 0. The number
+of pages that can be read is given by the Pages column of the job, it grows
+while the job is running.`,
+		Args: cobra.NoArgs,
+		RunE: wrapCheckSetup(queryJobResultsF),
+	}
+	queryJobResultsCmd.Flags().IntVarP(&queryJobFlags.page, "page", "p", 0, "The page of results")
+	queryJobResultsCmd.Flags().StringVar(&queryJobFlags.format, "format", http.QueryFormatCSV, "The format of the results: csv, json, arrow or lineprotocol")
+
+	queryJobDeleteCmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Cancel a query job and delete its results",
+		Args:  cobra.NoArgs,
+		RunE:  wrapCheckSetup(queryJobDeleteF),
+	}
+
+	for _, cmd := range []*cobra.Command{queryJobFindCmd, queryJobResultsCmd, queryJobDeleteCmd} {
+		cmd.Flags().StringVarP(&queryJobFlags.id, "id", "i", "", "The query job ID (required)")
+		cmd.MarkFlagRequired("id")
+		queryJobCmd.AddCommand(cmd)
+	}
+}
+
+func queryJobID() (platform.ID, error) {
+	var id platform.ID
+	if err := id.DecodeFromString(queryJobFlags.id); err != nil {
+		return 0, fmt.Errorf("failed to decode query job id %q: %v", queryJobFlags.id, err)
+	}
+	return id, nil
+}
+
+func queryJobFindF(cmd *cobra.Command, args []string) error {
+	if flags.local {
+		return fmt.Errorf("local flag not supported for query job find command")
+	}
+
+	id, err := queryJobID()
+	if err != nil {
+		return err
+	}
+
+	j, err := newQueryJobService().FindJobByID(context.Background(), id)
+	if err != nil {
+		return fmt.Errorf("failed to find query job with id %q: %v", id, err)
+	}
+
+	writeQueryJobs(j)
+	return nil
+}
+
+func queryJobResultsF(cmd *cobra.Command, args []string) error {
+	if flags.local {
+		return fmt.Errorf("local flag not supported for query job results command")
+	}
+
+	id, err := queryJobID()
+	if err != nil {
+		return err
+	}
+
+	if err := newQueryJobService().WriteJobPage(context.Background(), os.Stdout, id, queryJobFlags.page, queryJobFlags.format); err != nil {
+		return fmt.Errorf("failed to read page %d of query job with id %q: %v", queryJobFlags.page, id, err)
+	}
+	return nil
+}
+
+func queryJobDeleteF(cmd *cobra.Command, args []string) error {
+	if flags.local {
+		return fmt.Errorf("local flag not supported for query job delete command")
+	}
+
+	id, err := queryJobID()
+	if err != nil {
+		return err
+	}
+
+	s := newQueryJobService()
+	ctx := context.Background()
+	j, err := s.FindJobByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to find query job with id %q: %v", id, err)
+	}
+
+	if err := s.DeleteJob(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete query job with id %q: %v", id, err)
+	}
+
+	writeQueryJobs(j)
+	return nil
+}
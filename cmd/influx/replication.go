@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influx/internal"
+	"github.com/influxdata/influxdb/http"
+	"github.com/spf13/cobra"
+)
+
+// Replication Command
+var replicationCmd = &cobra.Command{
+	Use:   "replication",
+	Short: "Replication management commands",
+	Long:  "Manage the replication of writes to local buckets to buckets of a remote influxd.",
+	Run:   replicationF,
+}
+
+func replicationF(cmd *cobra.Command, args []string) {
+	cmd.Usage()
+}
+
+func newReplicationService(f Flags) (platform.ReplicationService, error) {
+	if flags.local {
+		return newLocalKVService()
+	}
+	return &http.ReplicationService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}, nil
+}
+
+func writeReplications(rs ...*platform.Replication) {
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"Name",
+		"OrgID",
+		"LocalBucketID",
+		"RemoteURL",
+		"RemoteOrgID",
+		"RemoteBucketID",
+		"MaxQueueSizeBytes",
+		"DropPolicy",
+	)
+	for _, r := range rs {
+		w.Write(map[string]interface{}{
+			"ID":                r.ID.String(),
+			"Name":              r.Name,
+			"OrgID":             r.OrgID.String(),
+			"LocalBucketID":     r.LocalBucketID.String(),
+			"RemoteURL":         r.RemoteURL,
+			"RemoteOrgID":       r.RemoteOrgID.String(),
+			"RemoteBucketID":    r.RemoteBucketID.String(),
+			"MaxQueueSizeBytes": r.MaxQueueSizeBytes,
+			"DropPolicy":        r.DropPolicy,
+		})
+	}
+	w.Flush()
+}
+
+// ReplicationCreateFlags define the Create Command
+type ReplicationCreateFlags struct {
+	name               string
+	description        string
+	orgID              string
+	localBucketID      string
+	remoteURL          string
+	remoteToken        string
+	remoteOrgID        string
+	remoteBucketID     string
+	insecureSkipVerify bool
+	maxQueueSizeBytes  int64
+	dropPolicy         string
+}
+
+var replicationCreateFlags ReplicationCreateFlags
+
+func init() {
+	replicationCreateCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create replication",
+		RunE:  wrapCheckSetup(replicationCreateF),
+	}
+
+	f := replicationCreateCmd.Flags()
+	f.StringVarP(&replicationCreateFlags.name, "name", "n", "", "Name of the replication (required)")
+	f.StringVarP(&replicationCreateFlags.description, "description", "d", "", "Description of the replication")
+	f.StringVarP(&replicationCreateFlags.orgID, "org-id", "", "", "The ID of the organization that owns the replication (required)")
+	f.StringVarP(&replicationCreateFlags.localBucketID, "local-bucket-id", "", "", "The ID of the local bucket whose writes are replicated (required)")
+	f.StringVarP(&replicationCreateFlags.remoteURL, "remote-url", "", "", "The URL of the remote influxd, e.g. https://cloud.example.com:9999 (required)")
+	f.StringVarP(&replicationCreateFlags.remoteToken, "remote-token", "", "", "The token used to write to the remote bucket")
+	f.StringVarP(&replicationCreateFlags.remoteOrgID, "remote-org-id", "", "", "The ID of the organization of the remote bucket (required)")
+	f.StringVarP(&replicationCreateFlags.remoteBucketID, "remote-bucket-id", "", "", "The ID of the remote bucket (required)")
+	f.BoolVarP(&replicationCreateFlags.insecureSkipVerify, "insecure-skip-verify", "", false, "Skip verification of the TLS certificate of the remote")
+	f.Int64VarP(&replicationCreateFlags.maxQueueSizeBytes, "max-queue-size", "", platform.DefaultReplicationMaxQueueSizeBytes, "Maximum number of bytes of writes waiting to be sent")
+	f.StringVarP(&replicationCreateFlags.dropPolicy, "drop-policy", "", string(platform.DropOldestReplicationPolicy), "Writes to drop when the queue is full: drop-oldest or drop-newest")
+	for _, name := range []string{"name", "org-id", "local-bucket-id", "remote-url", "remote-org-id", "remote-bucket-id"} {
+		replicationCreateCmd.MarkFlagRequired(name)
+	}
+
+	replicationCmd.AddCommand(replicationCreateCmd)
+}
+
+func replicationCreateF(cmd *cobra.Command, args []string) error {
+	s, err := newReplicationService(flags)
+	if err != nil {
+		return fmt.Errorf("failed to initialize replication service client: %v", err)
+	}
+
+	r := &platform.Replication{
+		Name:               replicationCreateFlags.name,
+		Description:        replicationCreateFlags.description,
+		RemoteURL:          replicationCreateFlags.remoteURL,
+		RemoteToken:        replicationCreateFlags.remoteToken,
+		InsecureSkipVerify: replicationCreateFlags.insecureSkipVerify,
+		MaxQueueSizeBytes:  replicationCreateFlags.maxQueueSizeBytes,
+		DropPolicy:         platform.ReplicationDropPolicy(replicationCreateFlags.dropPolicy),
+	}
+
+	for _, id := range []struct {
+		name string
+		s    string
+		dst  *platform.ID
+	}{
+		{"org", replicationCreateFlags.orgID, &r.OrgID},
+		{"local bucket", replicationCreateFlags.localBucketID, &r.LocalBucketID},
+		{"remote org", replicationCreateFlags.remoteOrgID, &r.RemoteOrgID},
+		{"remote bucket", replicationCreateFlags.remoteBucketID, &r.RemoteBucketID},
+	} {
+		if err := id.dst.DecodeFromString(id.s); err != nil {
+			return fmt.Errorf("failed to decode %s id %q: %v", id.name, id.s, err)
+		}
+	}
+
+	if err := s.CreateReplication(context.Background(), r); err != nil {
+		return fmt.Errorf("failed to create replication: %v", err)
+	}
+
+	writeReplications(r)
+	return nil
+}
+
+// ReplicationFindFlags define the Find Command
+type ReplicationFindFlags struct {
+	id            string
+	orgID         string
+	localBucketID string
+}
+
+var replicationFindFlags ReplicationFindFlags
+
+func init() {
+	replicationFindCmd := &cobra.Command{
+		Use:   "find",
+		Short: "Find replications",
+		RunE:  wrapCheckSetup(replicationFindF),
+	}
+
+	replicationFindCmd.Flags().StringVarP(&replicationFindFlags.id, "id", "i", "", "The replication ID")
+	replicationFindCmd.Flags().StringVarP(&replicationFindFlags.orgID, "org-id", "", "", "The replication organization ID")
+	replicationFindCmd.Flags().StringVarP(&replicationFindFlags.localBucketID, "local-bucket-id", "", "", "The ID of the replicated local bucket")
+
+	replicationCmd.AddCommand(replicationFindCmd)
+}
+
+func replicationFindF(cmd *cobra.Command, args []string) error {
+	s, err := newReplicationService(flags)
+	if err != nil {
+		return fmt.Errorf("failed to initialize replication service client: %v", err)
+	}
+
+	filter := platform.ReplicationFilter{}
+	if replicationFindFlags.id != "" {
+		id, err := platform.IDFromString(replicationFindFlags.id)
+		if err != nil {
+			return fmt.Errorf("failed to decode replication id %q: %v", replicationFindFlags.id, err)
+		}
+		filter.ID = id
+	}
+	if replicationFindFlags.orgID != "" {
+		id, err := platform.IDFromString(replicationFindFlags.orgID)
+		if err != nil {
+			return fmt.Errorf("failed to decode org id %q: %v", replicationFindFlags.orgID, err)
+		}
+		filter.OrgID = id
+	}
+	if replicationFindFlags.localBucketID != "" {
+		id, err := platform.IDFromString(replicationFindFlags.localBucketID)
+		if err != nil {
+			return fmt.Errorf("failed to decode local bucket id %q: %v", replicationFindFlags.localBucketID, err)
+		}
+		filter.LocalBucketID = id
+	}
+
+	rs, err := s.FindReplications(context.Background(), filter)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve replications: %v", err)
+	}
+
+	writeReplications(rs...)
+	return nil
+}
+
+// ReplicationUpdateFlags define the Update Command
+type ReplicationUpdateFlags struct {
+	id                 string
+	name               string
+	description        string
+	remoteURL          string
+	remoteToken        string
+	remoteOrgID        string
+	remoteBucketID     string
+	insecureSkipVerify bool
+	maxQueueSizeBytes  int64
+	dropPolicy         string
+}
+
+var replicationUpdateFlags ReplicationUpdateFlags
+
+func init() {
+	replicationUpdateCmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update replication",
+		RunE:  wrapCheckSetup(replicationUpdateF),
+	}
+
+	f := replicationUpdateCmd.Flags()
+	f.StringVarP(&replicationUpdateFlags.id, "id", "i", "", "The replication ID (required)")
+	f.StringVarP(&replicationUpdateFlags.name, "name", "n", "", "New name of the replication")
+	f.StringVarP(&replicationUpdateFlags.description, "description", "d", "", "New description of the replication")
+	f.StringVarP(&replicationUpdateFlags.remoteURL, "remote-url", "", "", "New URL of the remote influxd")
+	f.StringVarP(&replicationUpdateFlags.remoteToken, "remote-token", "", "", "New token used to write to the remote bucket")
+	f.StringVarP(&replicationUpdateFlags.remoteOrgID, "remote-org-id", "", "", "New ID of the organization of the remote bucket")
+	f.StringVarP(&replicationUpdateFlags.remoteBucketID, "remote-bucket-id", "", "", "New ID of the remote bucket")
+	f.BoolVarP(&replicationUpdateFlags.insecureSkipVerify, "insecure-skip-verify", "", false, "Skip verification of the TLS certificate of the remote")
+	f.Int64VarP(&replicationUpdateFlags.maxQueueSizeBytes, "max-queue-size", "", 0, "New maximum number of bytes of writes waiting to be sent")
+	f.StringVarP(&replicationUpdateFlags.dropPolicy, "drop-policy", "", "", "Writes to drop when the queue is full: drop-oldest or drop-newest")
+	replicationUpdateCmd.MarkFlagRequired("id")
+
+	replicationCmd.AddCommand(replicationUpdateCmd)
+}
+
+func replicationUpdateF(cmd *cobra.Command, args []string) error {
+	s, err := newReplicationService(flags)
+	if err != nil {
+		return fmt.Errorf("failed to initialize replication service client: %v", err)
+	}
+
+	var id platform.ID
+	if err := id.DecodeFromString(replicationUpdateFlags.id); err != nil {
+		return fmt.Errorf("failed to decode replication id %q: %v", replicationUpdateFlags.id, err)
+	}
+
+	update := platform.ReplicationUpdate{}
+	if replicationUpdateFlags.name != "" {
+		update.Name = &replicationUpdateFlags.name
+	}
+	if replicationUpdateFlags.description != "" {
+		update.Description = &replicationUpdateFlags.description
+	}
+	if replicationUpdateFlags.remoteURL != "" {
+		update.RemoteURL = &replicationUpdateFlags.remoteURL
+	}
+	if replicationUpdateFlags.remoteToken != "" {
+		update.RemoteToken = &replicationUpdateFlags.remoteToken
+	}
+	if replicationUpdateFlags.remoteOrgID != "" {
+		id, err := platform.IDFromString(replicationUpdateFlags.remoteOrgID)
+		if err != nil {
+			return fmt.Errorf("failed to decode remote org id %q: %v", replicationUpdateFlags.remoteOrgID, err)
+		}
+		update.RemoteOrgID = id
+	}
+	if replicationUpdateFlags.remoteBucketID != "" {
+		id, err := platform.IDFromString(replicationUpdateFlags.remoteBucketID)
+		if err != nil {
+			return fmt.Errorf("failed to decode remote bucket id %q: %v", replicationUpdateFlags.remoteBucketID, err)
+		}
+		update.RemoteBucketID = id
+	}
+	if cmd.Flags().Changed("insecure-skip-verify") {
+		update.InsecureSkipVerify = &replicationUpdateFlags.insecureSkipVerify
+	}
+	if replicationUpdateFlags.maxQueueSizeBytes != 0 {
+		update.MaxQueueSizeBytes = &replicationUpdateFlags.maxQueueSizeBytes
+	}
+	if replicationUpdateFlags.dropPolicy != "" {
+		policy := platform.ReplicationDropPolicy(replicationUpdateFlags.dropPolicy)
+		update.DropPolicy = &policy
+	}
+
+	r, err := s.UpdateReplication(context.Background(), id, update)
+	if err != nil {
+		return fmt.Errorf("failed to update replication: %v", err)
+	}
+
+	writeReplications(r)
+	return nil
+}
+
+// ReplicationDeleteFlags define the Delete command
+type ReplicationDeleteFlags struct {
+	id string
+}
+
+var replicationDeleteFlags ReplicationDeleteFlags
+
+func init() {
+	replicationDeleteCmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete replication and discard any writes not yet sent",
+		RunE:  wrapCheckSetup(replicationDeleteF),
+	}
+
+	replicationDeleteCmd.Flags().StringVarP(&replicationDeleteFlags.id, "id", "i", "", "The replication ID (required)")
+	replicationDeleteCmd.MarkFlagRequired("id")
+
+	replicationCmd.AddCommand(replicationDeleteCmd)
+}
+
+func replicationDeleteF(cmd *cobra.Command, args []string) error {
+	s, err := newReplicationService(flags)
+	if err != nil {
+		return fmt.Errorf("failed to initialize replication service client: %v", err)
+	}
+
+	var id platform.ID
+	if err := id.DecodeFromString(replicationDeleteFlags.id); err != nil {
+		return fmt.Errorf("failed to decode replication id %q: %v", replicationDeleteFlags.id, err)
+	}
+
+	ctx := context.Background()
+	r, err := s.FindReplicationByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to find replication with id %q: %v", id, err)
+	}
+
+	if err := s.DeleteReplication(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete replication with id %q: %v", id, err)
+	}
+
+	writeReplications(r)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/influxdata/flux/repl"
 	platform "github.com/influxdata/influxdb"
@@ -589,3 +590,195 @@ func runRetryF(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+type TaskBackfillFlags struct {
+	taskID      string
+	start       string
+	stop        string
+	concurrency int
+}
+
+var taskBackfillFlags TaskBackfillFlags
+
+var backfillCmd = &cobra.Command{
+	Use:   "backfill",
+	Short: "run a task for every time of its schedule between start and stop",
+	RunE:  wrapCheckSetup(taskBackfillF),
+}
+
+func init() {
+	cmd := backfillCmd
+	cmd.Flags().StringVarP(&taskBackfillFlags.taskID, "task-id", "i", "", "task id (required)")
+	cmd.Flags().StringVarP(&taskBackfillFlags.start, "start", "", "", "runs are queued for schedule times after start, RFC3339 (required)")
+	cmd.Flags().StringVarP(&taskBackfillFlags.stop, "stop", "", "", "runs are queued for schedule times up to and including stop, RFC3339 (required)")
+	cmd.Flags().IntVarP(&taskBackfillFlags.concurrency, "concurrency", "c", 0, "number of runs to queue at once (defaults to 1)")
+	cmd.MarkFlagRequired("task-id")
+	cmd.MarkFlagRequired("start")
+	cmd.MarkFlagRequired("stop")
+
+	taskCmd.AddCommand(cmd)
+}
+
+func taskBackfillF(cmd *cobra.Command, args []string) error {
+	s := &http.BackfillService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+
+	var taskID platform.ID
+	if err := taskID.DecodeFromString(taskBackfillFlags.taskID); err != nil {
+		return err
+	}
+
+	start, err := time.Parse(time.RFC3339, taskBackfillFlags.start)
+	if err != nil {
+		return fmt.Errorf("failed to parse start time: %v", err)
+	}
+	stop, err := time.Parse(time.RFC3339, taskBackfillFlags.stop)
+	if err != nil {
+		return fmt.Errorf("failed to parse stop time: %v", err)
+	}
+
+	b, err := s.CreateBackfill(context.Background(), taskID, platform.BackfillCreate{
+		Start:       start,
+		Stop:        stop,
+		Concurrency: taskBackfillFlags.concurrency,
+	})
+	if err != nil {
+		return err
+	}
+
+	writeBackfills(b)
+	return nil
+}
+
+type TaskBackfillFindFlags struct {
+	taskID string
+	id     string
+}
+
+var taskBackfillFindFlags TaskBackfillFindFlags
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "find",
+		Short: "find backfills of a task",
+		RunE:  wrapCheckSetup(taskBackfillFindF),
+	}
+
+	cmd.Flags().StringVarP(&taskBackfillFindFlags.taskID, "task-id", "i", "", "task id (required)")
+	cmd.Flags().StringVarP(&taskBackfillFindFlags.id, "id", "", "", "backfill id")
+	cmd.MarkFlagRequired("task-id")
+
+	backfillCmd.AddCommand(cmd)
+}
+
+func taskBackfillFindF(cmd *cobra.Command, args []string) error {
+	s := &http.BackfillService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+
+	var taskID platform.ID
+	if err := taskID.DecodeFromString(taskBackfillFindFlags.taskID); err != nil {
+		return err
+	}
+
+	var backfills []*platform.Backfill
+	if taskBackfillFindFlags.id != "" {
+		var id platform.ID
+		if err := id.DecodeFromString(taskBackfillFindFlags.id); err != nil {
+			return err
+		}
+		b, err := s.FindBackfillByID(context.Background(), taskID, id)
+		if err != nil {
+			return err
+		}
+		backfills = append(backfills, b)
+	} else {
+		var err error
+		backfills, err = s.FindBackfills(context.Background(), platform.BackfillFilter{Task: &taskID})
+		if err != nil {
+			return err
+		}
+	}
+
+	writeBackfills(backfills...)
+	return nil
+}
+
+type TaskBackfillCancelFlags struct {
+	taskID string
+	id     string
+}
+
+var taskBackfillCancelFlags TaskBackfillCancelFlags
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "cancel",
+		Short: "stop queueing the runs of a backfill",
+		RunE:  wrapCheckSetup(taskBackfillCancelF),
+	}
+
+	cmd.Flags().StringVarP(&taskBackfillCancelFlags.taskID, "task-id", "i", "", "task id (required)")
+	cmd.Flags().StringVarP(&taskBackfillCancelFlags.id, "id", "", "", "backfill id (required)")
+	cmd.MarkFlagRequired("task-id")
+	cmd.MarkFlagRequired("id")
+
+	backfillCmd.AddCommand(cmd)
+}
+
+func taskBackfillCancelF(cmd *cobra.Command, args []string) error {
+	s := &http.BackfillService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+
+	var taskID, id platform.ID
+	if err := taskID.DecodeFromString(taskBackfillCancelFlags.taskID); err != nil {
+		return err
+	}
+	if err := id.DecodeFromString(taskBackfillCancelFlags.id); err != nil {
+		return err
+	}
+
+	b, err := s.CancelBackfill(context.Background(), taskID, id)
+	if err != nil {
+		return err
+	}
+
+	writeBackfills(b)
+	return nil
+}
+
+func writeBackfills(backfills ...*platform.Backfill) {
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"TaskID",
+		"Status",
+		"Start",
+		"Stop",
+		"Concurrency",
+		"Total",
+		"Queued",
+		"Finished",
+		"Next",
+	)
+	for _, b := range backfills {
+		w.Write(map[string]interface{}{
+			"ID":          b.ID,
+			"TaskID":      b.TaskID,
+			"Status":      b.Status,
+			"Start":       b.Start,
+			"Stop":        b.Stop,
+			"Concurrency": b.Concurrency,
+			"Total":       b.Total,
+			"Queued":      b.Queued,
+			"Finished":    b.Finished,
+			"Next":        b.Next,
+		})
+	}
+	w.Flush()
+}
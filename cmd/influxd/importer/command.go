@@ -0,0 +1,176 @@
+package importer
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/bolt"
+	"github.com/influxdata/influxdb/internal/fs"
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/toml"
+	"github.com/spf13/cobra"
+)
+
+var importFlags = struct {
+	boltPath      string
+	enginePath    string
+	org, bucket   string
+	precision     string
+	maxBufferSize string
+	verbose       bool
+}{}
+
+// NewCommand creates the import command.
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import [flags] [<file>...]",
+		Short: "Import line protocol directly into TSM and TSI files",
+		Long: `
+This command imports line protocol into a bucket by writing TSM and TSI index
+files directly, rather than writing points through the WAL and cache of a
+running server. It is intended for backfilling large amounts of historical
+data.
+
+Line protocol is read from the named files, or from STDIN if no files are
+given or the file name is "-". Files ending in .gz are decompressed.
+
+Values are buffered in memory, sorted and written to new TSM files in a
+staging directory. Once all of the input has been read, the series are added
+to the index and the files are moved into the engine's data directory. If any
+line cannot be parsed or conflicts with the type of an existing field, no
+data is imported.
+
+NOTES:
+
+* The influxd server must not be running when using the import tool, as it
+  modifies the index and TSM data.
+* Imported values overwrite existing values with the same series and time,
+  except for values that have not yet been flushed from the WAL.
+`,
+		RunE: importFE,
+	}
+
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(err)
+	}
+	boltFile, err := fs.BoltFile()
+	if err != nil {
+		panic(err)
+	}
+	enginePath := filepath.Join(dir, "engine")
+
+	flags := cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVar(&importFlags.org, "org", "", "name of the organization owning the bucket")
+	flags.StringVar(&importFlags.bucket, "bucket", "", "name of the bucket to import into")
+	flags.StringVar(&importFlags.precision, "precision", "ns", "precision of the timestamps in the line protocol: ns, us, ms or s")
+	flags.StringVar(&importFlags.maxBufferSize, "max-buffer-size", "512m", "approximate amount of memory used to sort values before they are written to a TSM file")
+	flags.StringVar(&importFlags.boltPath, "bolt-path", boltFile, fmt.Sprintf("path to boltdb database (defaults to %s)", boltFile))
+	flags.StringVar(&importFlags.enginePath, "engine-path", enginePath, fmt.Sprintf("path to persistent engine files (defaults to %s)", enginePath))
+	flags.BoolVarP(&importFlags.verbose, "verbose", "v", false, "log the progress of the import")
+
+	return cmd
+}
+
+func importFE(_ *cobra.Command, args []string) error {
+	if importFlags.org == "" || importFlags.bucket == "" {
+		return fmt.Errorf("--org and --bucket are required")
+	}
+
+	var maxBufferSize toml.Size
+	if err := maxBufferSize.UnmarshalText([]byte(importFlags.maxBufferSize)); err != nil {
+		return fmt.Errorf("invalid max buffer size: %v", err)
+	}
+
+	ctx := context.Background()
+
+	// The bolt file stays open, and locked, for the duration of the import
+	// so that influxd cannot be started until the import has finished.
+	c := bolt.NewClient()
+	c.Path = importFlags.boltPath
+	if err := c.Open(ctx); err != nil {
+		return err
+	}
+	defer c.Close()
+
+	org, err := c.FindOrganizationByName(ctx, importFlags.org)
+	if err != nil {
+		return err
+	}
+	bucket, err := c.FindBucketByName(ctx, org.ID, importFlags.bucket)
+	if err != nil {
+		return err
+	}
+
+	imp := NewImporter(importFlags.enginePath, org.ID, bucket.ID)
+	imp.Precision = importFlags.precision
+	imp.MaxBufferSize = int(maxBufferSize)
+	if importFlags.verbose {
+		imp.Logger = logger.New(os.Stderr)
+	}
+
+	if err := imp.Open(ctx); err != nil {
+		return err
+	}
+	defer imp.Close()
+
+	start := time.Now()
+	if len(args) == 0 {
+		args = []string{"-"}
+	}
+	for _, path := range args {
+		if err := importFile(ctx, imp, path); err != nil {
+			return err
+		}
+	}
+
+	files, err := imp.Commit(ctx)
+	if err != nil {
+		return err
+	}
+
+	stats := imp.Stats()
+	fmt.Println("Imported:")
+	for _, f := range files {
+		fmt.Println(f)
+	}
+	fmt.Println()
+	fmt.Printf("Points: %d\n", stats.Points)
+	fmt.Printf("Series: %d\n", stats.Series)
+	fmt.Printf("Total time: %0.1f seconds\n", time.Since(start).Seconds())
+	return nil
+}
+
+// importFile writes the line protocol in the file at path to imp.
+func importFile(ctx context.Context, imp *Importer, path string) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	if strings.HasSuffix(path, ".gz") {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+		r = gr
+	}
+
+	if err := imp.Write(ctx, r); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,405 @@
+// Package importer writes line protocol directly to the TSM and TSI files of
+// a storage engine, bypassing the WAL, cache and compactions of a running
+// server.
+package importer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influx_inspect/buildtsi"
+	"github.com/influxdata/influxdb/cmd/influxd/internal/shard"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/file"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsi1"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMaxBufferSize is the default approximate number of bytes of values
+	// buffered in memory before they are sorted and written to a TSM file.
+	DefaultMaxBufferSize = 512 * 1024 * 1024
+
+	// DefaultBatchSize is the default number of lines parsed at a time.
+	DefaultBatchSize = 5000
+
+	// stagingDirName is the directory within the TSM data directory in which
+	// files are written until they are attached to the engine.
+	stagingDirName = ".import"
+
+	// valueOverhead approximates the per-key memory cost of buffered values.
+	valueOverhead = 64
+)
+
+// Stats describes the data written by an Importer.
+type Stats struct {
+	Lines  int // number of lines read, including comments and blank lines
+	Points int // number of points written
+	Series int // number of distinct series written
+}
+
+// An Importer writes line protocol for a single bucket to sorted TSM files
+// and adds the series to the TSI index and series file of a storage engine.
+//
+// Files are written to a staging directory and are only moved into the
+// engine's data directory once all of the input has been written and indexed,
+// so that a failed import leaves no data behind. The storage engine must not
+// be open while an import is in progress.
+type Importer struct {
+	// MaxBufferSize is the approximate number of bytes of values buffered
+	// before they are written to a TSM file.
+	MaxBufferSize int
+
+	// BatchSize is the number of lines parsed at a time.
+	BatchSize int
+
+	// Precision is the precision of the timestamps in the line protocol.
+	Precision string
+
+	Logger *zap.Logger
+
+	path   string
+	config storage.Config
+	mm     []byte
+	now    time.Time
+
+	sfile   *tsdb.SeriesFile
+	staging string
+	gen     int
+	files   []string
+
+	types  map[string]models.FieldType // series key to field type
+	values map[string][]tsm1.Value
+	size   int
+	keyBuf []byte
+
+	stats Stats
+}
+
+// NewImporter returns an Importer for the bucket of the storage engine at path.
+func NewImporter(path string, orgID, bucketID influxdb.ID) *Importer {
+	name := tsdb.EncodeName(orgID, bucketID)
+	return &Importer{
+		MaxBufferSize: DefaultMaxBufferSize,
+		BatchSize:     DefaultBatchSize,
+		Precision:     "ns",
+		Logger:        zap.NewNop(),
+
+		path:   path,
+		config: storage.NewConfig(),
+		mm:     models.EscapeMeasurement(name[:]),
+		types:  make(map[string]models.FieldType),
+		values: make(map[string][]tsm1.Value),
+	}
+}
+
+// Open prepares the staging directory and opens the series file of the engine.
+func (imp *Importer) Open(ctx context.Context) error {
+	if !models.ValidPrecision(imp.Precision) {
+		return fmt.Errorf("invalid precision %q; valid precision units are ns, us, ms, and s", imp.Precision)
+	}
+	imp.now = time.Now().UTC()
+
+	dataPath := imp.config.GetEnginePath(imp.path)
+	if err := os.MkdirAll(dataPath, 0777); err != nil {
+		return err
+	}
+
+	gen, err := nextGeneration(dataPath)
+	if err != nil {
+		return err
+	}
+	imp.gen = gen
+
+	// Remove any files left behind by an earlier import that failed.
+	imp.staging = filepath.Join(dataPath, stagingDirName)
+	if err := os.RemoveAll(imp.staging); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(imp.staging, 0777); err != nil {
+		return err
+	}
+
+	imp.sfile = tsdb.NewSeriesFile(imp.config.GetSeriesFilePath(imp.path))
+	imp.sfile.WithLogger(imp.Logger)
+	if err := imp.sfile.Open(ctx); err != nil {
+		imp.sfile = nil
+		return err
+	}
+	imp.sfile.DisableCompactions()
+	return nil
+}
+
+// Close closes the series file and removes any files that were not attached
+// to the engine.
+func (imp *Importer) Close() error {
+	var err error
+	if imp.sfile != nil {
+		err = imp.sfile.Close()
+		imp.sfile = nil
+	}
+	if imp.staging != "" {
+		if e := os.RemoveAll(imp.staging); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Stats returns the statistics of the data written so far.
+func (imp *Importer) Stats() Stats { return imp.stats }
+
+// Write reads line protocol from r. The import fails if any line cannot be
+// parsed or stored, e.g. because of a field type conflict.
+func (imp *Importer) Write(ctx context.Context, r io.Reader) error {
+	br := bufio.NewReaderSize(r, 1024*1024)
+
+	var (
+		buf   []byte
+		lines int
+		first = imp.stats.Lines + 1
+	)
+	for {
+		line, err := br.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			// Long lines are read in several parts.
+			buf = append(buf, line...)
+			continue
+		} else if err != nil && err != io.EOF {
+			return err
+		}
+
+		buf = append(buf, line...)
+		if len(line) > 0 {
+			lines++
+		}
+
+		if lines >= imp.BatchSize || (err == io.EOF && lines > 0) {
+			if e := imp.writeBatch(buf, first, first+lines-1); e != nil {
+				return e
+			}
+			imp.stats.Lines += lines
+			first += lines
+			buf, lines = buf[:0], 0
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// writeBatch parses a batch of lines, numbered first to last, and buffers
+// the values.
+func (imp *Importer) writeBatch(buf []byte, first, last int) error {
+	points, err := models.ParsePointsWithPrecision(buf, imp.mm, imp.now, imp.Precision)
+	if err != nil {
+		return fmt.Errorf("error parsing lines %d-%d: %v", first, last, err)
+	}
+
+	collection := tsdb.NewSeriesCollection(points)
+	storage.ValidateSeriesCollection(collection)
+	if collection.Dropped > 0 {
+		return fmt.Errorf("error in lines %d-%d: %s", first, last, collection.Reason)
+	}
+
+	if err := imp.checkTypes(collection); err != nil {
+		return fmt.Errorf("error in lines %d-%d: %v", first, last, err)
+	}
+
+	values, err := tsm1.CollectionToValues(collection)
+	if err != nil {
+		return err
+	} else if collection.Dropped > 0 {
+		return fmt.Errorf("error in lines %d-%d: %s", first, last, collection.Reason)
+	}
+
+	for k, vs := range values {
+		existing, ok := imp.values[k]
+		if !ok {
+			imp.size += len(k) + valueOverhead
+		}
+		imp.values[k] = append(existing, vs...)
+		imp.size += tsm1.Values(vs).Size()
+	}
+	imp.stats.Points += len(points)
+
+	if imp.size >= imp.MaxBufferSize {
+		return imp.flush()
+	}
+	return nil
+}
+
+// checkTypes returns an error if the field type of any series in collection
+// differs from earlier writes, either in this import or in the engine.
+func (imp *Importer) checkTypes(collection *tsdb.SeriesCollection) error {
+	for iter := collection.Iterator(); iter.Next(); {
+		imp.keyBuf = tsdb.AppendSeriesKey(imp.keyBuf[:0], iter.Name(), iter.Tags())
+		typ := iter.Type()
+
+		if exp, ok := imp.types[string(imp.keyBuf)]; ok {
+			if typ != exp {
+				return conflictError(iter.Key(), typ, exp)
+			}
+			continue
+		}
+
+		if id := imp.sfile.SeriesIDTypedBySeriesKey(imp.keyBuf); !id.IsZero() && id.HasType() && id.Type() != typ {
+			return conflictError(iter.Key(), typ, id.Type())
+		}
+		imp.types[string(imp.keyBuf)] = typ
+		imp.stats.Series++
+	}
+	return nil
+}
+
+func conflictError(key []byte, typ, exp models.FieldType) error {
+	return fmt.Errorf("conflicting field type: %s has field type %s but expected %s", key, typ, exp)
+}
+
+// flush sorts the buffered values and writes them to a new generation of
+// TSM files in the staging directory.
+func (imp *Importer) flush() error {
+	if len(imp.values) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(imp.values))
+	for k := range imp.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	sw, err := shard.NewWriter(imp.staging, shard.Generation(imp.gen), shard.Temporary())
+	if err != nil {
+		return err
+	}
+	imp.gen++
+
+	for _, k := range keys {
+		// Later values for the same timestamp overwrite earlier ones, as
+		// they would when written to the engine.
+		vs := tsm1.Values(imp.values[k]).Deduplicate()
+		for len(vs) > 0 {
+			n := len(vs)
+			if n > tsm1.MaxPointsPerBlock {
+				n = tsm1.MaxPointsPerBlock
+			}
+			sw.Write([]byte(k), vs[:n])
+			vs = vs[n:]
+		}
+		if err := sw.Err(); err != nil {
+			sw.Close()
+			return err
+		}
+	}
+
+	sw.Close()
+	if err := sw.Err(); err != nil {
+		return err
+	}
+	imp.files = append(imp.files, sw.Files()...)
+
+	imp.Logger.Info("Wrote TSM files", zap.Strings("files", sw.Files()))
+
+	imp.values = make(map[string][]tsm1.Value)
+	imp.size = 0
+	return nil
+}
+
+// Commit writes any buffered values, adds all of the imported series to the
+// index and moves the TSM files into the data directory of the engine. It
+// returns the paths of the attached TSM files.
+//
+// If moving the files fails, any files already moved are removed again.
+// Series added to the index are not removed, but have no data.
+func (imp *Importer) Commit(ctx context.Context) ([]string, error) {
+	if err := imp.flush(); err != nil {
+		return nil, err
+	}
+	if len(imp.files) == 0 {
+		return nil, nil
+	}
+
+	if err := imp.buildIndex(ctx); err != nil {
+		return nil, fmt.Errorf("error building TSI index: %v", err)
+	}
+
+	dataPath := imp.config.GetEnginePath(imp.path)
+	attached := make([]string, 0, len(imp.files))
+	for _, src := range imp.files {
+		dst := filepath.Join(dataPath, filepath.Base(src))
+		dst = dst[:len(dst)-len(tsm1.TmpTSMFileExtension)-1]
+		if err := file.RenameFile(src, dst); err != nil {
+			for _, f := range attached {
+				os.Remove(f)
+			}
+			return nil, err
+		}
+		attached = append(attached, dst)
+	}
+
+	if err := file.SyncDir(dataPath); err != nil {
+		return nil, err
+	}
+	imp.files = nil
+	return attached, nil
+}
+
+// buildIndex adds the series of the staged TSM files to the index.
+func (imp *Importer) buildIndex(ctx context.Context) error {
+	idx := tsi1.NewIndex(imp.sfile, imp.config.Index,
+		tsi1.WithPath(imp.config.GetIndexPath(imp.path)),
+		tsi1.DisableMetrics(),
+	)
+	idx.WithLogger(imp.Logger)
+	if err := idx.Open(ctx); err != nil {
+		return err
+	}
+
+	for _, f := range imp.files {
+		if err := buildtsi.IndexTSMFile(idx, f, imp.BatchSize, imp.Logger, false); err != nil {
+			idx.Close()
+			return err
+		}
+	}
+
+	idx.Compact()
+	idx.Wait()
+	return idx.Close()
+}
+
+// nextGeneration returns a TSM generation greater than that of any file in
+// dataPath, so imported values overwrite existing values at the same time.
+func nextGeneration(dataPath string) (int, error) {
+	files, err := filepath.Glob(filepath.Join(dataPath, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return 0, err
+	}
+
+	gen := 1
+	for _, f := range files {
+		g, _, err := tsm1.DefaultParseFileName(f)
+		if err != nil {
+			return 0, err
+		}
+		if g >= gen {
+			gen = g + 1
+		}
+	}
+	return gen, nil
+}
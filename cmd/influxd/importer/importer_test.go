@@ -0,0 +1,198 @@
+package importer_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influxd/importer"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/export"
+	"github.com/influxdata/influxdb/storage/readservice"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+const (
+	orgID    = influxdb.ID(0x3131313131313131)
+	bucketID = influxdb.ID(0x3232323232323232)
+)
+
+func TestImporter(t *testing.T) {
+	path := mustTempDir(t)
+	defer os.RemoveAll(path)
+
+	// Write existing data to the engine.
+	writeEngine(t, path, `
+cpu,host=a usage=1 10
+cpu,host=a usage=2 20
+`)
+
+	imp := importer.NewImporter(path, orgID, bucketID)
+	imp.BatchSize = 2
+	imp.MaxBufferSize = 1 // write a TSM file for every batch
+	if err := imp.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer imp.Close()
+
+	for _, data := range []string{`
+# comment
+cpu,host=b usage=3 10
+cpu,host=a usage=5 30
+mem,host=a free=4i 10
+`, `mem,host=a free=6i 20
+mem,host=a free=7i 20
+`} {
+		if err := imp.Write(context.Background(), strings.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := imp.Commit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := len(files), 3; got != exp {
+		t.Errorf("unexpected number of files -got/+exp\n%d\n%d", got, exp)
+	}
+	if exp := (importer.Stats{Lines: 7, Points: 5, Series: 3}); imp.Stats() != exp {
+		t.Errorf("unexpected stats -got/+exp\n%+v\n%+v", imp.Stats(), exp)
+	}
+	if err := imp.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(path, "data", ".import")); !os.IsNotExist(err) {
+		t.Errorf("expected staging directory to be removed, got %v", err)
+	}
+
+	// Later values overwrite earlier values at the same time.
+	exp := `cpu,host=a usage=1 10
+cpu,host=a usage=2 20
+cpu,host=a usage=5 30
+cpu,host=b usage=3 10
+mem,host=a free=4i 10
+mem,host=a free=7i 20
+`
+	if got := readEngine(t, path); got != exp {
+		t.Errorf("unexpected data -got/+exp\n%s\n%s", got, exp)
+	}
+}
+
+func TestImporter_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		exp  string
+	}{
+		{
+			name: "parse error",
+			data: "cpu,host=b usage=3 10\ncpu,host=b usage= 20\n",
+			exp:  "error parsing lines 2-3",
+		},
+		{
+			name: "conflict with engine",
+			data: "cpu,host=a usage=3i 30\n",
+			exp:  "conflicting field type",
+		},
+		{
+			name: "conflict within import",
+			data: "cpu,host=b usage=3 30\ncpu,host=b usage=true 40\n",
+			exp:  "conflicting field type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := mustTempDir(t)
+			defer os.RemoveAll(path)
+
+			writeEngine(t, path, `cpu,host=a usage=1 10`)
+
+			imp := importer.NewImporter(path, orgID, bucketID)
+			if err := imp.Open(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			defer imp.Close()
+
+			// Write valid data first, which must not be imported.
+			if err := imp.Write(context.Background(), strings.NewReader("cpu,host=c usage=1 10\n")); err != nil {
+				t.Fatal(err)
+			}
+			err := imp.Write(context.Background(), strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.exp) {
+				t.Fatalf("expected error containing %q, got %v", tt.exp, err)
+			}
+			if err := imp.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, exp := readEngine(t, path), "cpu,host=a usage=1 10\n"; got != exp {
+				t.Errorf("unexpected data -got/+exp\n%s\n%s", got, exp)
+			}
+		})
+	}
+}
+
+func mustTempDir(t *testing.T) string {
+	t.Helper()
+	path, err := ioutil.TempDir("", "importer_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeEngine writes data to the test bucket of the engine at path and
+// closes it again, leaving the data in the WAL.
+func writeEngine(t *testing.T, path, data string) {
+	t.Helper()
+
+	e := storage.NewEngine(path, storage.NewConfig())
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	name := tsdb.EncodeName(orgID, bucketID)
+	points, err := models.ParsePointsWithPrecision([]byte(strings.TrimSpace(data)), models.EscapeMeasurement(name[:]), time.Now(), "ns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.WritePoints(context.Background(), points); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readEngine returns the data of the test bucket as sorted line protocol.
+func readEngine(t *testing.T, path string) string {
+	t.Helper()
+
+	e := storage.NewEngine(path, storage.NewConfig())
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	var buf bytes.Buffer
+	if _, err := export.NewExporter(readservice.NewStore(e)).Export(context.Background(), &buf, export.LineProtocol, export.Filter{
+		OrgID:    orgID,
+		BucketID: bucketID,
+		Start:    models.MinNanoTime,
+		End:      models.MaxNanoTime,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.SplitAfter(buf.String(), "\n")
+	sort.Strings(lines)
+	return strings.Join(lines, "")
+}
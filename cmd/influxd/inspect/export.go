@@ -0,0 +1,162 @@
+package inspect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/internal/fs"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/export"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/readservice"
+	"github.com/spf13/cobra"
+)
+
+// exportFlags defines the `export` Command.
+var exportFlags = struct {
+	enginePath      string
+	orgID, bucketID string
+	start, end      string
+	measurement     string
+	predicate       string
+	format          string
+	output          string
+}{}
+
+func NewExportCommand() *cobra.Command {
+	exportCommand := &cobra.Command{
+		Use:   "export",
+		Short: "Export bucket data as line protocol, CSV or Parquet",
+		Long: `
+This command exports the data of a single bucket from a storage engine
+directory. The engine must not be in use by a running influxd process.
+
+TSM and index files are not compacted, and the WAL is read without being
+replayed or truncated, so data that influxd has not yet snapshotted to TSM
+files is exported too. As when influxd starts, opening the engine removes
+temporary files left behind by interrupted compactions and creates empty
+index log files where missing.
+
+Data may be limited to a time range, a single measurement or a Flux predicate
+function, e.g.
+
+	--predicate '(r) => r.host == "server01" and r._field == "usage_user"'
+
+The following formats are supported:
+
+	* lp: line protocol (default);
+	* csv: annotated CSV, with one table per series; and
+	* parquet: a single Parquet file with one column per tag key.`,
+		Args: cobra.NoArgs,
+		RunE: inspectExport,
+	}
+
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(err)
+	}
+	dir = filepath.Join(dir, "engine")
+	exportCommand.Flags().StringVarP(&exportFlags.enginePath, "engine-path", "", dir, fmt.Sprintf("use provided engine directory (defaults to %s).", dir))
+
+	exportCommand.Flags().StringVarP(&exportFlags.orgID, "org-id", "", "", "organization ID of the bucket to export.")
+	exportCommand.Flags().StringVarP(&exportFlags.bucketID, "bucket-id", "", "", "bucket ID to export.")
+	exportCommand.Flags().StringVarP(&exportFlags.start, "start", "", "", "only export data at or after this RFC3339 time.")
+	exportCommand.Flags().StringVarP(&exportFlags.end, "end", "", "", "only export data before this RFC3339 time.")
+	exportCommand.Flags().StringVarP(&exportFlags.measurement, "measurement", "", "", "only export data for this measurement.")
+	exportCommand.Flags().StringVarP(&exportFlags.predicate, "predicate", "", "", "only export series matching this Flux predicate function.")
+	exportCommand.Flags().StringVarP(&exportFlags.format, "format", "", string(export.LineProtocol), "output format: lp, csv or parquet.")
+	exportCommand.Flags().StringVarP(&exportFlags.output, "output", "o", "", "write output to this file (defaults to stdout).")
+
+	return exportCommand
+}
+
+// inspectExport runs the export tool.
+func inspectExport(cmd *cobra.Command, args []string) error {
+	if exportFlags.orgID == "" || exportFlags.bucketID == "" {
+		return errors.New("--org-id and --bucket-id are required")
+	}
+
+	orgID, err := influxdb.IDFromString(exportFlags.orgID)
+	if err != nil {
+		return fmt.Errorf("invalid org ID: %v", err)
+	}
+	bucketID, err := influxdb.IDFromString(exportFlags.bucketID)
+	if err != nil {
+		return fmt.Errorf("invalid bucket ID: %v", err)
+	}
+
+	format, err := export.ParseFormat(exportFlags.format)
+	if err != nil {
+		return err
+	}
+
+	filter := export.Filter{
+		OrgID:       *orgID,
+		BucketID:    *bucketID,
+		Start:       models.MinNanoTime,
+		End:         models.MaxNanoTime,
+		Measurement: exportFlags.measurement,
+	}
+	if exportFlags.start != "" {
+		t, err := time.Parse(time.RFC3339Nano, exportFlags.start)
+		if err != nil {
+			return fmt.Errorf("invalid start time: %v", err)
+		}
+		filter.Start = t.UnixNano()
+	}
+	if exportFlags.end != "" {
+		t, err := time.Parse(time.RFC3339Nano, exportFlags.end)
+		if err != nil {
+			return fmt.Errorf("invalid end time: %v", err)
+		}
+		filter.End = t.UnixNano()
+	}
+	if filter.Start >= filter.End {
+		return errors.New("start time must be before end time")
+	}
+	if exportFlags.predicate != "" {
+		if filter.Predicate, err = reads.ParsePredicate(exportFlags.predicate); err != nil {
+			return fmt.Errorf("invalid predicate: %v", err)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if exportFlags.output != "" {
+		f, err := os.Create(exportFlags.output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	// Replaying the WAL would write its series into the index and series file,
+	// so it is loaded into the cache only once the engine is open.
+	config := storage.NewConfig()
+	config.WAL.Enabled = false
+
+	engine := storage.NewEngine(exportFlags.enginePath, config, storage.WithCompactionsDisabled())
+	if err := engine.Open(context.Background()); err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	if err := engine.LoadWAL(context.Background()); err != nil {
+		return err
+	}
+
+	stats, err := export.NewExporter(readservice.NewStore(engine)).Export(context.Background(), w, format, filter)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d values from %d series\n", stats.Values, stats.Series)
+	return nil
+}
@@ -86,13 +86,18 @@ func (w *Writer) Write(key []byte, values tsm1.Values) {
 		w.nextTSM()
 	}
 
-	if err := w.tw.Write(key, values); err != nil {
-		if err == tsm1.ErrMaxBlocksExceeded {
-			w.closeTSM()
-			w.nextTSM()
-		} else {
-			w.err = err
+	err := w.tw.Write(key, values)
+	if err == tsm1.ErrMaxBlocksExceeded {
+		// Roll over to a new file and write the values there instead.
+		w.closeTSM()
+		w.nextTSM()
+		if w.err != nil {
+			return
 		}
+		err = w.tw.Write(key, values)
+	}
+	if err != nil {
+		w.err = err
 	}
 }
 
@@ -113,13 +118,18 @@ func (w *Writer) WriteV(key []byte, values gen.Values) {
 		return
 	}
 
-	if err := w.tw.WriteBlock(key, minT, maxT, w.buf); err != nil {
-		if err == tsm1.ErrMaxBlocksExceeded {
-			w.closeTSM()
-			w.nextTSM()
-		} else {
-			w.err = err
+	err = w.tw.WriteBlock(key, minT, maxT, w.buf)
+	if err == tsm1.ErrMaxBlocksExceeded {
+		// Roll over to a new file and write the block there instead.
+		w.closeTSM()
+		w.nextTSM()
+		if w.err != nil {
+			return
 		}
+		err = w.tw.WriteBlock(key, minT, maxT, w.buf)
+	}
+	if err != nil {
+		w.err = err
 	}
 }
 
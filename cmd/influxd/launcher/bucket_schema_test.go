@@ -0,0 +1,109 @@
+package launcher_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	nethttp "net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influxd/launcher"
+)
+
+func TestLauncher_BucketSchema(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	do := func(req *nethttp.Request) (int, string) {
+		t.Helper()
+		resp, err := nethttp.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	schemaPath := fmt.Sprintf("/api/v2/buckets/%s/schema", l.Bucket.ID)
+	req := l.NewHTTPRequestOrFail(t, "PUT", schemaPath, l.Auth.Token, `measurement,key,type
+cpu,host,tag
+cpu,usage,float
+cpu,count,integer
+`)
+	req.Header.Set("Content-Type", "text/csv")
+	if code, body := do(req); code != nethttp.StatusOK {
+		t.Fatalf("unexpected status code %d putting schema: %s", code, body)
+	}
+
+	sch, err := l.BucketSchemaService().FindBucketSchema(ctx, l.Bucket.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &influxdb.BucketSchema{
+		Measurements: []influxdb.MeasurementSchema{
+			{
+				Name: "cpu",
+				Tags: []string{"host"},
+				Fields: []influxdb.FieldSchema{
+					{Name: "usage", Type: influxdb.FieldTypeFloat},
+					{Name: "count", Type: influxdb.FieldTypeInteger},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(sch, want) {
+		t.Fatalf("unexpected schema -got/+want\n%+v\n%+v", sch, want)
+	}
+
+	// Points violating the schema are dropped and the others are written.
+	req = l.NewHTTPRequestOrFail(t, "POST", fmt.Sprintf("/api/v2/write?org=%s&bucket=%s", l.Org.ID, l.Bucket.ID), l.Auth.Token, `cpu,host=a usage=1,count=2i
+cpu,host=b usage=3i
+cpu,region=west usage=4
+cpux,host=a usage=5`)
+	code, body := do(req)
+	if code != nethttp.StatusBadRequest {
+		t.Fatalf("unexpected status code %d writing points: %s", code, body)
+	}
+	if !strings.Contains(body, "partial write") || !strings.Contains(body, "dropped=3") {
+		t.Errorf("expected partial write error dropping 3 points, got %s", body)
+	}
+
+	for _, tt := range []struct {
+		call string
+		exp  []string
+	}{
+		{call: `measurements(bucket: "%s")`, exp: []string{"cpu"}},
+		{call: `measurementTagValues(bucket: "%s", measurement: "cpu", tag: "host")`, exp: []string{"a"}},
+		{call: `fieldKeys(bucket: "%s")`, exp: []string{"count", "usage"}},
+		{call: `bucketSchema(bucket: "%s") |> map(fn: (r) => ({_value: r.measurement + "." + r.key + ":" + r.type}))`, exp: []string{"cpu.host:tag", "cpu.usage:float", "cpu.count:integer"}},
+	} {
+		q := `import "influxdata/influxdb/schema"
+schema.` + fmt.Sprintf(tt.call, l.Bucket.Name)
+		var got []string
+		for _, line := range strings.Split(l.FluxQueryOrFail(t, l.Org, l.Auth.Token, q), "\r\n") {
+			if strings.HasPrefix(line, ",_result,") {
+				got = append(got, line[strings.LastIndex(line, ",")+1:])
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(tt.exp, ",") {
+			t.Errorf("%s: unexpected values -got/+exp\n%v\n%v", q, got, tt.exp)
+		}
+	}
+
+	// Anything can be written to buckets without a schema.
+	if err := l.BucketSchemaService().DeleteBucketSchema(ctx, l.Bucket.ID); err != nil {
+		t.Fatal(err)
+	}
+	l.WritePointsOrFail(t, `cpux,host=a usage=5`)
+
+	if _, err := l.BucketSchemaService().FindBucketSchema(ctx, l.Bucket.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected bucket without a schema, got %v", err)
+	}
+}
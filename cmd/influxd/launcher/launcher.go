@@ -27,14 +27,22 @@ import (
 	"github.com/influxdata/influxdb/kit/signals"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/ldap"
+	"github.com/influxdata/influxdb/lockout"
 	influxlogger "github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/nats"
 	infprom "github.com/influxdata/influxdb/prometheus"
 	"github.com/influxdata/influxdb/query"
+	querycache "github.com/influxdata/influxdb/query/cache"
 	"github.com/influxdata/influxdb/query/control"
+	"github.com/influxdata/influxdb/query/jobs"
+	"github.com/influxdata/influxdb/query/querylog"
+	"github.com/influxdata/influxdb/replication"
 	"github.com/influxdata/influxdb/snowflake"
 	"github.com/influxdata/influxdb/source"
 	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/export"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
 	"github.com/influxdata/influxdb/storage/readservice"
 	taskbackend "github.com/influxdata/influxdb/task/backend"
 	"github.com/influxdata/influxdb/task/backend/coordinator"
@@ -50,6 +58,8 @@ import (
 	jaegerconfig "github.com/uber/jaeger-client-go/config"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 const (
@@ -133,6 +143,22 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: ":9999",
 			Desc:    "bind address for the REST HTTP API",
 		},
+		{
+			DestP:   &l.grpcBindAddress,
+			Flag:    "grpc-bind-address",
+			Default: "",
+			Desc:    "bind address for the gRPC storage read service, which is disabled if empty",
+		},
+		{
+			DestP: &l.grpcTLSCert,
+			Flag:  "grpc-tls-cert",
+			Desc:  "TLS certificate file of the gRPC storage read service; both it and --grpc-tls-key are required for TLS",
+		},
+		{
+			DestP: &l.grpcTLSKey,
+			Flag:  "grpc-tls-key",
+			Desc:  "TLS private key file of the gRPC storage read service",
+		},
 		{
 			DestP:   &l.boltPath,
 			Flag:    "bolt-path",
@@ -162,6 +188,78 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: filepath.Join(dir, "engine"),
 			Desc:    "path to persistent engine files",
 		},
+		{
+			DestP:   &l.replicationsPath,
+			Flag:    "replications-path",
+			Default: filepath.Join(dir, "replicationq"),
+			Desc:    "path to the queues of writes waiting to be replicated to remote buckets",
+		},
+		{
+			DestP:   &l.queryJobsPath,
+			Flag:    "query-jobs-path",
+			Default: filepath.Join(dir, "queryjobs"),
+			Desc:    "path to the results of queries submitted as jobs",
+		},
+		{
+			DestP:   &l.queryJobsTTL,
+			Flag:    "query-jobs-ttl",
+			Default: jobs.DefaultTTL,
+			Desc:    "time the results of a query job are kept after it has finished",
+		},
+		{
+			DestP:   &l.queryCacheMaxMemoryBytes,
+			Flag:    "query-cache-max-memory-bytes",
+			Default: 0,
+			Desc:    "maximum number of bytes of memory used to cache query results; 0 disables the query cache",
+		},
+		{
+			DestP:   &l.queryCacheAlignment,
+			Flag:    "query-cache-alignment",
+			Default: querycache.DefaultAlignment,
+			Desc:    "interval the now of cached queries is aligned to; cached results are served until the end of the interval",
+		},
+		{
+			DestP:   &l.queryOrgConcurrencyQuota,
+			Flag:    "query-org-concurrency-quota",
+			Default: 0,
+			Desc:    "number of queries of a single organization allowed to execute concurrently; 0 is no limit per organization. Organizations may override it",
+		},
+		{
+			DestP:   &l.queryOrgQueueSize,
+			Flag:    "query-org-queue-size",
+			Default: 0,
+			Desc:    "number of queries of a single organization allowed to await execution; 0 is no limit per organization. Organizations may override it",
+		},
+		{
+			DestP:   &l.queryOrgMemoryBytesQuota,
+			Flag:    "query-org-memory-bytes-quota",
+			Default: 0,
+			Desc:    "maximum number of bytes of table memory the executing queries of a single organization may use together; 0 is no limit per organization. Organizations may override it",
+		},
+		{
+			DestP:   &l.queryTaskConcurrencyQuota,
+			Flag:    "query-task-concurrency-quota",
+			Default: 8,
+			Desc:    "number of task queries allowed to execute concurrently; 0 is no limit other than the one of all queries",
+		},
+		{
+			DestP:   &l.queryBackgroundConcurrencyQuota,
+			Flag:    "query-background-concurrency-quota",
+			Default: 4,
+			Desc:    "number of background queries allowed to execute concurrently; 0 is no limit other than the one of all queries",
+		},
+		{
+			DestP:   &l.queryLogSampleRate,
+			Flag:    "query-log-sample-rate",
+			Default: 0.0,
+			Desc:    "fraction of finished queries logged to the query log bucket of their organization; 0 disables the query log",
+		},
+		{
+			DestP:   &l.queryLogRetention,
+			Flag:    "query-log-retention",
+			Default: querylog.DefaultRetentionPeriod,
+			Desc:    "retention period of the query log buckets created for organizations",
+		},
 		{
 			DestP:   &l.secretStore,
 			Flag:    "secret-store",
@@ -180,12 +278,133 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: 60, // 60 minutes
 			Desc:    "ttl in minutes for newly created sessions",
 		},
+		{
+			DestP:   &l.auditLogRetention,
+			Flag:    "audit-log-retention",
+			Default: platform.DefaultAuditLogRetention,
+			Desc:    "time changes to resources are kept in the audit log; 0 keeps them forever",
+		},
 		{
 			DestP:   &l.sessionRenewDisabled,
 			Flag:    "session-renew-disabled",
 			Default: false,
 			Desc:    "disables automatically extending session ttl on request",
 		},
+		{
+			DestP:   &l.passwordPolicy.MinLength,
+			Flag:    "password-min-length",
+			Default: platform.DefaultPasswordPolicy.MinLength,
+			Desc:    "minimum length of passwords; passwords shorter than 8 characters are never allowed",
+		},
+		{
+			DestP:   &l.passwordPolicy.RequireUppercase,
+			Flag:    "password-require-uppercase",
+			Default: false,
+			Desc:    "require passwords to contain an uppercase letter",
+		},
+		{
+			DestP:   &l.passwordPolicy.RequireLowercase,
+			Flag:    "password-require-lowercase",
+			Default: false,
+			Desc:    "require passwords to contain a lowercase letter",
+		},
+		{
+			DestP:   &l.passwordPolicy.RequireDigit,
+			Flag:    "password-require-digit",
+			Default: false,
+			Desc:    "require passwords to contain a digit",
+		},
+		{
+			DestP:   &l.passwordPolicy.RequireSymbol,
+			Flag:    "password-require-symbol",
+			Default: false,
+			Desc:    "require passwords to contain a character that is not a letter or a digit",
+		},
+		{
+			DestP:   &l.signinLockout.UserAttempts,
+			Flag:    "signin-lockout-user-attempts",
+			Default: lockout.DefaultUserAttempts,
+			Desc:    "failed signins as a user after which its signins are locked out; 0 disables locking out users",
+		},
+		{
+			DestP:   &l.signinLockout.AddrAttempts,
+			Flag:    "signin-lockout-address-attempts",
+			Default: lockout.DefaultAddrAttempts,
+			Desc:    "failed signins from an address after which its signins are locked out; 0 disables locking out addresses",
+		},
+		{
+			DestP:   &l.signinLockout.Duration,
+			Flag:    "signin-lockout-duration",
+			Default: lockout.DefaultDuration,
+			Desc:    "duration of the first signin lockout; it doubles with every further failed signin",
+		},
+		{
+			DestP:   &l.signinLockout.MaxDuration,
+			Flag:    "signin-lockout-max-duration",
+			Default: lockout.DefaultMaxDuration,
+			Desc:    "longest signin lockout; failed signins are forgotten after this duration without failures",
+		},
+		{
+			DestP:   &l.passwordStore,
+			Flag:    "password-store",
+			Default: "bolt",
+			Desc:    "data store users sign in against (bolt or ldap); the passwords of users not found in ldap are kept in bolt",
+		},
+		{
+			DestP: &l.ldapConfig.URL,
+			Flag:  "ldap-url",
+			Desc:  "URL of the LDAP server, such as ldap://ldap.example.com:389 or ldaps://ldap.example.com:636",
+		},
+		{
+			DestP:   &l.ldapConfig.StartTLS,
+			Flag:    "ldap-start-tls",
+			Default: false,
+			Desc:    "upgrade ldap:// connections to the LDAP server to TLS",
+		},
+		{
+			DestP:   &l.ldapConfig.InsecureSkipVerify,
+			Flag:    "ldap-insecure-skip-verify",
+			Default: false,
+			Desc:    "skip verifying the certificate of the LDAP server",
+		},
+		{
+			DestP:   &l.ldapConfig.Timeout,
+			Flag:    "ldap-timeout",
+			Default: ldap.DefaultTimeout,
+			Desc:    "timeout of requests to the LDAP server",
+		},
+		{
+			DestP: &l.ldapConfig.BindDN,
+			Flag:  "ldap-bind-dn",
+			Desc:  "DN users are searched as; users are searched anonymously if empty",
+		},
+		{
+			DestP: &l.ldapConfig.BindPassword,
+			Flag:  "ldap-bind-password",
+			Desc:  "password of the DN users are searched as",
+		},
+		{
+			DestP: &l.ldapConfig.UserBaseDN,
+			Flag:  "ldap-user-base-dn",
+			Desc:  "DN users are searched under",
+		},
+		{
+			DestP:   &l.ldapConfig.UserFilter,
+			Flag:    "ldap-user-filter",
+			Default: ldap.DefaultUserFilter,
+			Desc:    "filter finding a user, with %s replaced by the user name",
+		},
+		{
+			DestP:   &l.ldapConfig.GroupAttribute,
+			Flag:    "ldap-group-attribute",
+			Default: ldap.DefaultGroupAttribute,
+			Desc:    "attribute of users listing the DNs of their groups",
+		},
+		{
+			DestP: &l.ldapGroupMappings,
+			Flag:  "ldap-group-mappings",
+			Desc:  "semicolon separated mappings of LDAP groups to organizations in the form <group DN>:<organization>:<member|owner>",
+		},
 	}
 
 	cli.BindOptions(cmd, opts)
@@ -202,30 +421,63 @@ type Launcher struct {
 	testing              bool
 	sessionLength        int // in minutes
 	sessionRenewDisabled bool
+	auditLogRetention    time.Duration
+	passwordPolicy       platform.PasswordPolicy
+	signinLockout        lockout.Config
+	passwordStore        string
+	ldapConfig           ldap.Config
+	ldapGroupMappings    string
 
 	logLevel          string
 	tracingType       string
 	reportingDisabled bool
 
-	httpBindAddress string
-	boltPath        string
-	enginePath      string
-	secretStore     string
+	httpBindAddress  string
+	grpcBindAddress  string
+	grpcTLSCert      string
+	grpcTLSKey       string
+	boltPath         string
+	enginePath       string
+	replicationsPath string
+	queryJobsPath    string
+	secretStore      string
+
+	queryJobsTTL time.Duration
+
+	queryCacheMaxMemoryBytes int
+	queryCacheAlignment      time.Duration
+
+	queryOrgConcurrencyQuota        int
+	queryOrgQueueSize               int
+	queryOrgMemoryBytesQuota        int
+	queryTaskConcurrencyQuota       int
+	queryBackgroundConcurrencyQuota int
+
+	queryLogSampleRate float64
+	queryLogRetention  time.Duration
 
 	boltClient    *bolt.Client
 	kvService     *kv.Service
 	engine        *storage.Engine
 	StorageConfig storage.Config
 
+	replicationManager *replication.Manager
+
 	queryController *control.Controller
+	queryCache      *querycache.Cache
+	queryJobs       *jobs.Manager
 
 	httpPort   int
 	httpServer *nethttp.Server
 
+	grpcPort   int
+	grpcServer *grpc.Server
+
 	natsServer *nats.Server
 
-	scheduler          *taskbackend.TickScheduler
+	scheduler          *taskbackend.HeapTaskScheduler
 	taskControlService taskbackend.TaskControlService
+	backfiller         *taskbackend.Backfiller
 
 	jaegerTracerCloser io.Closer
 	logger             *zap.Logger
@@ -272,6 +524,11 @@ func (m *Launcher) URL() string {
 	return fmt.Sprintf("http://127.0.0.1:%d", m.httpPort)
 }
 
+// GRPCAddr returns the address of the gRPC storage read service.
+func (m *Launcher) GRPCAddr() string {
+	return fmt.Sprintf("127.0.0.1:%d", m.grpcPort)
+}
+
 // Engine returns a reference to the storage engine. It should only be called
 // for end-to-end testing purposes.
 func (m *Launcher) Engine() *storage.Engine {
@@ -282,7 +539,13 @@ func (m *Launcher) Engine() *storage.Engine {
 func (m *Launcher) Shutdown(ctx context.Context) {
 	m.httpServer.Shutdown(ctx)
 
+	m.logger.Info("Stopping", zap.String("service", "grpc"))
+	m.stopGRPCServer(ctx)
+
 	m.logger.Info("Stopping", zap.String("service", "task"))
+	if err := m.backfiller.Close(); err != nil {
+		m.logger.Error("failed to close task backfiller", zap.Error(err))
+	}
 	m.scheduler.Stop()
 
 	m.logger.Info("Stopping", zap.String("service", "nats"))
@@ -293,11 +556,21 @@ func (m *Launcher) Shutdown(ctx context.Context) {
 		m.logger.Info("failed closing bolt", zap.Error(err))
 	}
 
+	m.logger.Info("Stopping", zap.String("service", "query-jobs"))
+	if err := m.queryJobs.Close(); err != nil {
+		m.logger.Error("failed to close query jobs", zap.Error(err))
+	}
+
 	m.logger.Info("Stopping", zap.String("service", "query"))
 	if err := m.queryController.Shutdown(ctx); err != nil && err != context.Canceled {
 		m.logger.Info("Failed closing query service", zap.Error(err))
 	}
 
+	m.logger.Info("Stopping", zap.String("service", "replication"))
+	if err := m.replicationManager.Close(); err != nil {
+		m.logger.Error("failed to close replication streams", zap.Error(err))
+	}
+
 	m.logger.Info("Stopping", zap.String("service", "storage-engine"))
 	if err := m.engine.Close(); err != nil {
 		m.logger.Error("failed to close engine", zap.Error(err))
@@ -396,7 +669,9 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	}
 
 	serviceConfig := kv.ServiceConfig{
-		SessionLength: time.Duration(m.sessionLength) * time.Minute,
+		SessionLength:     time.Duration(m.sessionLength) * time.Minute,
+		AuditLogRetention: m.auditLogRetention,
+		PasswordPolicy:    m.passwordPolicy,
 	}
 
 	var flusher http.Flusher
@@ -475,6 +750,30 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	switch m.passwordStore {
+	case "bolt":
+		// If it is bolt, then we already set it above.
+	case "ldap":
+		mappings, err := ldap.ParseGroupMappings(m.ldapGroupMappings)
+		if err != nil {
+			m.logger.Error("failed parsing ldap group mappings", zap.Error(err))
+			return err
+		}
+		m.ldapConfig.GroupMappings = mappings
+
+		svc, err := ldap.NewPasswordsService(m.ldapConfig, passwdsSvc, userSvc, orgSvc, userResourceSvc)
+		if err != nil {
+			m.logger.Error("failed initializing ldap passwords service", zap.Error(err))
+			return err
+		}
+		svc.Logger = m.logger.With(zap.String("service", "ldap"))
+		passwdsSvc = svc
+	default:
+		err := fmt.Errorf("unknown password store %q, expected \"bolt\" or \"ldap\"", m.passwordStore)
+		m.logger.Error("failed setting passwords service", zap.Error(err))
+		return err
+	}
+
 	chronografSvc, err := server.NewServiceV2(ctx, m.boltClient.DB())
 	if err != nil {
 		m.logger.Error("failed creating chronograf service", zap.Error(err))
@@ -493,7 +792,40 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		// The Engine's metrics must be registered after it opens.
 		m.reg.MustRegister(m.engine.PrometheusCollectors()...)
 
-		pointsWriter = m.engine
+		m.replicationManager = replication.NewManager(m.replicationsPath, m.kvService)
+		m.replicationManager.Logger = m.logger.With(zap.String("service", "replication"))
+		if err := m.replicationManager.Open(ctx); err != nil {
+			m.logger.Error("failed to open replication streams", zap.Error(err))
+			return err
+		}
+		m.reg.MustRegister(m.replicationManager.PrometheusCollectors()...)
+
+		// Writes are replicated only once the engine has accepted them.
+		pointsWriter = &replication.PointsWriter{
+			Underlying: m.engine,
+			Manager:    m.replicationManager,
+		}
+
+		if m.queryCacheMaxMemoryBytes > 0 {
+			qc, err := querycache.New(querycache.Config{
+				MaxMemoryBytes: int64(m.queryCacheMaxMemoryBytes),
+				Alignment:      m.queryCacheAlignment,
+				Logger:         m.logger.With(zap.String("service", "query-cache")),
+			})
+			if err != nil {
+				m.logger.Error("Failed to create query cache", zap.Error(err))
+				return err
+			}
+			m.queryCache = qc
+			m.reg.MustRegister(m.queryCache.PrometheusCollectors()...)
+
+			// Every write, including those of the to function, invalidates
+			// the cached results it changes.
+			pointsWriter = &querycache.PointsWriter{
+				Underlying: pointsWriter,
+				Cache:      m.queryCache,
+			}
+		}
 
 		// TODO(cwolff): Figure out a good default per-query memory limit:
 		//   https://github.com/influxdata/influxdb/issues/13642
@@ -508,13 +840,25 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			ConcurrencyQuota:         concurrencyQuota,
 			MemoryBytesQuotaPerQuery: int64(memoryBytesQuotaPerQuery),
 			QueueSize:                QueueSize,
-			Logger:                   m.logger.With(zap.String("service", "storage-reads")),
+			OrgConcurrencyQuota:      m.queryOrgConcurrencyQuota,
+			OrgQueueSize:             m.queryOrgQueueSize,
+			OrgMemoryBytesQuota:      int64(m.queryOrgMemoryBytesQuota),
+			PriorityConcurrencyQuotas: map[query.Priority]int{
+				query.PriorityTask:       m.queryTaskConcurrencyQuota,
+				query.PriorityBackground: m.queryBackgroundConcurrencyQuota,
+			},
+			OrganizationService: orgSvc,
+			Logger:              m.logger.With(zap.String("service", "storage-reads")),
+		}
+
+		if m.queryLogSampleRate > 0 {
+			cc.QueryLogger = querylog.NewWriter(pointsWriter, bucketSvc, m.queryLogRetention, m.queryLogSampleRate)
 		}
 
 		authBucketSvc := authorizer.NewBucketService(bucketSvc)
 		authOrgSvc := authorizer.NewOrgService(orgSvc)
 		if err := readservice.AddControllerConfigDependencies(
-			&cc, m.engine, authBucketSvc, authOrgSvc,
+			&cc, m.engine, pointsWriter, authBucketSvc, authOrgSvc,
 		); err != nil {
 			m.logger.Error("Failed to configure query controller dependencies", zap.Error(err))
 			return err
@@ -529,8 +873,33 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		m.reg.MustRegister(m.queryController.PrometheusCollectors()...)
 	}
 
-	var storageQueryService = readservice.NewProxyQueryService(m.queryController)
-	var taskSvc platform.TaskService
+	var queryService query.AsyncQueryService = m.queryController
+	if m.queryCache != nil {
+		queryService = &querycache.QueryService{
+			Underlying:    m.queryController,
+			BucketService: bucketSvc,
+			Cache:         m.queryCache,
+		}
+	}
+	var storageQueryService = readservice.NewProxyQueryService(queryService)
+
+	// Query jobs bypass the cache, since their results are spooled to disk.
+	m.queryJobs = jobs.NewManager(m.queryJobsPath, query.QueryServiceBridge{AsyncQueryService: m.queryController})
+	m.queryJobs.TTL = m.queryJobsTTL
+	m.queryJobs.Logger = m.logger.With(zap.String("service", "query-jobs"))
+	if err := m.queryJobs.Open(ctx); err != nil {
+		m.logger.Error("failed to open query jobs", zap.Error(err))
+		return err
+	}
+
+	// Tokens are checked, and granted the permissions of their roles, in the same way
+	// whether they are used by task runs or sent to the gRPC storage read service.
+	tokenAuth := authorizer.NewTokenAuthenticator(authSvc, authorizer.NewRoleResolver(m.kvService, userResourceSvc))
+
+	var (
+		taskSvc     platform.TaskService
+		backfillSvc platform.BackfillService
+	)
 	{
 
 		// create the task stack:
@@ -538,16 +907,23 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 
 		// define the executor and build analytical storage middleware
 		combinedTaskService := taskbackend.NewAnalyticalStorage(m.logger.With(zap.String("service", "task-analytical-store")), m.kvService, m.kvService, pointsWriter, query.QueryServiceBridge{AsyncQueryService: m.queryController})
-		executor := taskexecutor.NewAsyncQueryServiceExecutor(m.logger.With(zap.String("service", "task-executor")), m.queryController, authSvc, combinedTaskService)
+		executor := taskexecutor.NewAsyncQueryServiceExecutor(m.logger.With(zap.String("service", "task-executor")), m.queryController, tokenAuth, combinedTaskService)
 
 		// create the scheduler
-		m.scheduler = taskbackend.NewScheduler(combinedTaskService, executor, time.Now().UTC().Unix(), taskbackend.WithTicker(ctx, 100*time.Millisecond), taskbackend.WithLogger(m.logger))
+		m.scheduler = taskbackend.NewHeapTaskScheduler(m.logger, combinedTaskService, executor, taskbackend.NewTaskServiceCheckpointer(combinedTaskService))
 		m.scheduler.Start(ctx)
 		m.reg.MustRegister(m.scheduler.PrometheusCollectors()...)
 
 		taskSvc = coordinator.New(m.logger.With(zap.String("service", "task-coordinator")), m.scheduler, combinedTaskService)
 		taskSvc = authorizer.NewTaskService(m.logger.With(zap.String("service", "task-authz-validator")), taskSvc, bucketSvc)
 		m.taskControlService = combinedTaskService
+
+		m.backfiller = taskbackend.NewBackfiller(m.logger.With(zap.String("service", "task-backfill")), m.kvService, combinedTaskService, combinedTaskService, m.scheduler)
+		if err := m.backfiller.Open(ctx); err != nil {
+			m.logger.Error("failed to open task backfiller", zap.Error(err))
+			return err
+		}
+		backfillSvc = authorizer.NewBackfillService(m.backfiller, combinedTaskService)
 	}
 
 	// NATS streaming server
@@ -592,6 +968,13 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		logger.Info("Stopping")
 	}(m.logger)
 
+	// gRPC storage read service
+	if m.grpcBindAddress != "" {
+		if err := m.runGRPC(tokenAuth); err != nil {
+			return err
+		}
+	}
+
 	m.httpServer = &nethttp.Server{
 		Addr: m.httpBindAddress,
 	}
@@ -604,9 +987,12 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		NewBucketService:     source.NewBucketService,
 		NewQueryService:      source.NewQueryService,
 		PointsWriter:         pointsWriter,
+		Exporter:             export.NewExporter(readservice.NewStore(m.engine)),
 		AuthorizationService: authSvc,
 		// Wrap the BucketService in a storage backed one that will ensure deleted buckets are removed from the storage engine.
 		BucketService:                   storage.NewBucketService(bucketSvc, m.engine),
+		BucketSchemaService:             m.kvService,
+		StorageUsageService:             m.engine,
 		SessionService:                  sessionSvc,
 		UserService:                     userSvc,
 		OrganizationService:             orgSvc,
@@ -620,10 +1006,16 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		SourceService:                   sourceSvc,
 		VariableService:                 variableSvc,
 		PasswordsService:                passwdsSvc,
+		SigninLockoutService:            lockout.NewService(m.signinLockout),
 		OnboardingService:               onboardingSvc,
 		InfluxQLService:                 nil, // No InfluxQL support
 		FluxService:                     storageQueryService,
+		ActiveQueryService:              m.queryController,
+		AuditLogService:                 m.kvService,
+		RoleService:                     m.kvService,
+		QueryJobService:                 m.queryJobs,
 		TaskService:                     taskSvc,
+		BackfillService:                 backfillSvc,
 		TelegrafService:                 telegrafSvc,
 		ScraperTargetStoreService:       scraperTargetSvc,
 		ChronografService:               chronografSvc,
@@ -631,6 +1023,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		LookupService:                   lookupSvc,
 		DocumentService:                 m.kvService,
 		OrgLookupService:                m.kvService,
+		ReplicationService:              m.replicationManager,
 		WriteEventRecorder:              infprom.NewEventRecorder("write"),
 		QueryEventRecorder:              infprom.NewEventRecorder("query"),
 	}
@@ -677,6 +1070,71 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	return nil
 }
 
+// runGRPC starts the gRPC storage read service, over TLS if a certificate
+// and key are configured.
+func (m *Launcher) runGRPC(auth *authorizer.TokenAuthenticator) error {
+	grpcLogger := m.logger.With(zap.String("service", "grpc"))
+
+	var opts []grpc.ServerOption
+	if m.grpcTLSCert != "" || m.grpcTLSKey != "" {
+		creds, err := credentials.NewServerTLSFromFile(m.grpcTLSCert, m.grpcTLSKey)
+		if err != nil {
+			grpcLogger.Error("failed to load grpc TLS certificate", zap.Error(err))
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	} else {
+		grpcLogger.Warn("gRPC storage read service is not using TLS; tokens are sent in clear text")
+	}
+
+	m.grpcServer = grpc.NewServer(opts...)
+	datatypes.RegisterStorageServer(m.grpcServer, readservice.NewStorageServer(readservice.NewStore(m.engine), auth))
+
+	grpcLn, err := net.Listen("tcp", m.grpcBindAddress)
+	if err != nil {
+		grpcLogger.Error("failed grpc listener", zap.Error(err))
+		grpcLogger.Info("Stopping")
+		return err
+	}
+
+	if addr, ok := grpcLn.Addr().(*net.TCPAddr); ok {
+		m.grpcPort = addr.Port
+	}
+
+	m.wg.Add(1)
+	go func(logger *zap.Logger) {
+		defer m.wg.Done()
+		logger.Info("Listening", zap.String("transport", "grpc"), zap.String("addr", m.grpcBindAddress), zap.Int("port", m.grpcPort), zap.Bool("tls", len(opts) > 0))
+
+		if err := m.grpcServer.Serve(grpcLn); err != nil {
+			logger.Error("failed grpc service", zap.Error(err))
+		}
+		logger.Info("Stopping")
+	}(grpcLogger)
+
+	return nil
+}
+
+// stopGRPCServer waits for the pending reads of the gRPC server to finish
+// and cancels them when ctx is done.
+func (m *Launcher) stopGRPCServer(ctx context.Context) {
+	if m.grpcServer == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		m.grpcServer.Stop()
+	}
+}
+
 // OrganizationService returns the internal organization service.
 func (m *Launcher) OrganizationService() platform.OrganizationService {
 	return m.apibackend.OrganizationService
@@ -76,7 +76,9 @@ func RunTestLauncherOrFail(tb testing.TB, ctx context.Context, args ...string) *
 func (tl *TestLauncher) Run(ctx context.Context, args ...string) error {
 	args = append(args, "--bolt-path", filepath.Join(tl.Path, "influxd.bolt"))
 	args = append(args, "--engine-path", filepath.Join(tl.Path, "engine"))
+	args = append(args, "--replications-path", filepath.Join(tl.Path, "replicationq"))
 	args = append(args, "--http-bind-address", "127.0.0.1:0")
+	args = append(args, "--grpc-bind-address", "127.0.0.1:0")
 	args = append(args, "--log-level", "debug")
 	return tl.Launcher.Run(ctx, args...)
 }
@@ -292,18 +294,46 @@ func (tl *TestLauncher) FluxQueryService() *http.FluxQueryService {
 	return &http.FluxQueryService{Addr: tl.URL(), Token: tl.Auth.Token}
 }
 
+func (tl *TestLauncher) QueryJobService() *http.QueryJobService {
+	return &http.QueryJobService{Addr: tl.URL(), Token: tl.Auth.Token}
+}
+
+func (tl *TestLauncher) VariableService() *http.VariableService {
+	return &http.VariableService{Addr: tl.URL(), Token: tl.Auth.Token}
+}
+
 func (tl *TestLauncher) BucketService() *http.BucketService {
 	return &http.BucketService{Addr: tl.URL(), Token: tl.Auth.Token, OpPrefix: bolt.OpPrefix}
 }
 
+func (tl *TestLauncher) BucketSchemaService() *http.BucketSchemaService {
+	return &http.BucketSchemaService{Addr: tl.URL(), Token: tl.Auth.Token}
+}
+
 func (tl *TestLauncher) AuthorizationService() *http.AuthorizationService {
 	return &http.AuthorizationService{Addr: tl.URL(), Token: tl.Auth.Token}
 }
 
+func (tl *TestLauncher) AuditLogService() *http.AuditLogService {
+	return &http.AuditLogService{Addr: tl.URL(), Token: tl.Auth.Token}
+}
+
+func (tl *TestLauncher) ReplicationService() *http.ReplicationService {
+	return &http.ReplicationService{Addr: tl.URL(), Token: tl.Auth.Token}
+}
+
+func (tl *TestLauncher) RoleService() *http.RoleService {
+	return &http.RoleService{Addr: tl.URL(), Token: tl.Auth.Token}
+}
+
 func (tl *TestLauncher) TaskService() *http.TaskService {
 	return &http.TaskService{Addr: tl.URL(), Token: tl.Auth.Token}
 }
 
+func (tl *TestLauncher) BackfillService() *http.BackfillService {
+	return &http.BackfillService{Addr: tl.URL(), Token: tl.Auth.Token}
+}
+
 // QueryResult wraps a single flux.Result with some helper methods.
 type QueryResult struct {
 	t *testing.T
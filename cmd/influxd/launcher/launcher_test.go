@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	nethttp "net/http"
 	"testing"
+	"time"
 
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/cmd/influxd/launcher"
@@ -139,3 +140,146 @@ func TestLauncher_SetupWithUsers(t *testing.T) {
 		t.Fatalf("unexpected 2 users: %#+v", exp)
 	}
 }
+
+func TestLauncher_ExpiringAndDerivedTokens(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	readBucket, err := platform.NewPermissionAtID(l.Bucket.ID, platform.ReadAction, platform.BucketsResourceType, l.Org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeBucket, err := platform.NewPermissionAtID(l.Bucket.ID, platform.WriteAction, platform.BucketsResourceType, l.Org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := l.AuthorizationService()
+
+	past := time.Now().Add(-time.Minute)
+	if err := svc.CreateAuthorization(ctx, &platform.Authorization{
+		OrgID:       l.Org.ID,
+		UserID:      l.User.ID,
+		Permissions: []platform.Permission{*readBucket},
+		ExpiresAt:   &past,
+	}); platform.ErrorCode(err) != platform.EInvalid {
+		t.Fatalf("expected invalid error creating an expired token, got %v", err)
+	}
+
+	parentExpiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	parent := &platform.Authorization{
+		OrgID:       l.Org.ID,
+		UserID:      l.User.ID,
+		Permissions: []platform.Permission{*readBucket},
+		ExpiresAt:   &parentExpiresAt,
+	}
+	if err := svc.CreateAuthorization(ctx, parent); err != nil {
+		t.Fatal(err)
+	}
+
+	// Derive a child token with the parent token, which has no permission on authorizations.
+	parentSvc := &http.AuthorizationService{Addr: l.URL(), Token: parent.Token}
+	if _, err := parentSvc.DeriveAuthorization(ctx, &platform.AuthorizationDerive{
+		Permissions: []platform.Permission{*writeBucket},
+	}); platform.ErrorCode(err) != platform.EForbidden {
+		t.Fatalf("expected forbidden error deriving a permission the parent does not have, got %v", err)
+	}
+	later := parentExpiresAt.Add(time.Hour)
+	if _, err := parentSvc.DeriveAuthorization(ctx, &platform.AuthorizationDerive{
+		Permissions: []platform.Permission{*readBucket},
+		ExpiresAt:   &later,
+	}); platform.ErrorCode(err) != platform.EInvalid {
+		t.Fatalf("expected invalid error deriving a token outliving its parent, got %v", err)
+	}
+
+	child, err := parentSvc.DeriveAuthorization(ctx, &platform.AuthorizationDerive{
+		Permissions: []platform.Permission{*readBucket},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child.ParentID != parent.ID || child.UserID != l.User.ID || child.OrgID != l.Org.ID {
+		t.Fatalf("unexpected derived token %+v", child)
+	}
+	if child.ExpiresAt == nil || !child.ExpiresAt.Equal(parentExpiresAt) {
+		t.Fatalf("expected derived token to expire with its parent at %s, got %v", parentExpiresAt, child.ExpiresAt)
+	}
+
+	childBuckets := &http.BucketService{Addr: l.URL(), Token: child.Token}
+	if _, err := childBuckets.FindBucketByID(ctx, l.Bucket.ID); err != nil {
+		t.Fatalf("unexpected error using derived token: %v", err)
+	}
+
+	// Using a token records when it was last used.
+	found, err := svc.FindAuthorizationByID(ctx, child.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.LastUsedAt == nil {
+		t.Fatal("expected derived token to record its last use")
+	}
+
+	// Deactivating the parent token disables the child token.
+	inactive := platform.Inactive
+	if _, err := svc.UpdateAuthorization(ctx, parent.ID, &platform.AuthorizationUpdate{Status: &inactive}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := childBuckets.FindBucketByID(ctx, l.Bucket.ID); platform.ErrorCode(err) != platform.EUnauthorized {
+		t.Fatalf("expected unauthorized error using a token derived from an inactive token, got %v", err)
+	}
+
+	// Expiring a token disables it.
+	active := platform.Active
+	if _, err := svc.UpdateAuthorization(ctx, parent.ID, &platform.AuthorizationUpdate{Status: &active, ExpiresAt: &past}); err != nil {
+		t.Fatal(err)
+	}
+	parentBuckets := &http.BucketService{Addr: l.URL(), Token: parent.Token}
+	if _, err := parentBuckets.FindBucketByID(ctx, l.Bucket.ID); platform.ErrorCode(err) != platform.EUnauthorized {
+		t.Fatalf("expected unauthorized error using an expired token, got %v", err)
+	}
+}
+
+func TestLauncher_AuditLog(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	b := &platform.Bucket{OrgID: l.Org.ID, Name: "audited"}
+	if err := l.BucketService().CreateBucket(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	name := "renamed"
+	if _, err := l.BucketService().UpdateBucket(ctx, b.ID, platform.BucketUpdate{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+
+	es, _, err := l.AuditLogService().FindAuditLogEntries(ctx, platform.AuditLogFilter{
+		OrgID:      &l.Org.ID,
+		ResourceID: &b.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != 2 {
+		t.Fatalf("expected 2 entries for the bucket, got %d", len(es))
+	}
+
+	update, create := es[0], es[1]
+	if create.Action != platform.AuditCreate || update.Action != platform.AuditUpdate {
+		t.Fatalf("unexpected actions %s and %s", create.Action, update.Action)
+	}
+	if update.UserID != l.User.ID || update.AuthorizerID != l.Auth.ID || update.AuthorizerKind != platform.AuthorizationKind {
+		t.Errorf("unexpected caller of update %+v", update)
+	}
+	if update.RemoteAddr == "" {
+		t.Error("expected the remote address of the update to be recorded")
+	}
+	var after map[string]interface{}
+	if err := json.Unmarshal(update.After, &after); err != nil {
+		t.Fatal(err)
+	}
+	if after["name"] != "renamed" {
+		t.Errorf("unexpected change %s", update.After)
+	}
+}
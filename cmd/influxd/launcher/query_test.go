@@ -3,18 +3,24 @@ package launcher_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	nethttp "net/http"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/cmd/influxd/launcher"
 	phttp "github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/kit/prom/promtest"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/querylog"
 )
 
 func TestPipeline_Write_Query_FieldKey(t *testing.T) {
@@ -147,3 +153,382 @@ func TestPipeline_QueryMemoryLimits(t *testing.T) {
 		t.Fatal("expected error, got successful query execution")
 	}
 }
+
+func TestLauncher_QueryCache(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx,
+		"--query-cache-max-memory-bytes", "1048576",
+		"--query-cache-alignment", "1h",
+	)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	// The points are written before the aligned now the queries run at.
+	now := time.Now()
+	l.WritePointsOrFail(t, fmt.Sprintf("m f=1 %d", now.Add(-2*time.Hour).UnixNano()))
+
+	qs := fmt.Sprintf(`from(bucket: "%s") |> range(start: -1d) |> sum()`, l.Bucket.Name)
+	requests := func(result string) float64 {
+		t.Helper()
+		mfs := promtest.MustGather(t, l.Registry())
+		m := promtest.MustFindMetric(t, mfs, "query_cache_requests_total", map[string]string{"result": result})
+		return m.GetCounter().GetValue()
+	}
+
+	for i := 0; i < 2; i++ {
+		if got := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, qs); !strings.Contains(got, ",f,m,1\r\n") {
+			t.Fatalf("unexpected results of query %d:\n%s", i, got)
+		}
+	}
+	if got, exp := requests("miss"), 1.0; got != exp {
+		t.Errorf("unexpected cache misses -got/+exp\n%v\n%v", got, exp)
+	}
+	if got, exp := requests("hit"), 1.0; got != exp {
+		t.Errorf("unexpected cache hits -got/+exp\n%v\n%v", got, exp)
+	}
+
+	// A write into the range read by the query invalidates its results.
+	l.WritePointsOrFail(t, fmt.Sprintf("m f=2 %d", now.Add(-3*time.Hour).UnixNano()))
+	if got := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, qs); !strings.Contains(got, ",f,m,3\r\n") {
+		t.Fatalf("unexpected results after write:\n%s", got)
+	}
+	if got, exp := requests("miss"), 2.0; got != exp {
+		t.Errorf("unexpected cache misses -got/+exp\n%v\n%v", got, exp)
+	}
+}
+
+func TestLauncher_QueryLog(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx, "--query-log-sample-rate", "1")
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, fmt.Sprintf("m f=1 %d", time.Now().Add(-time.Minute).UnixNano()))
+	l.FluxQueryOrFail(t, l.Org, l.Auth.Token, fmt.Sprintf(`from(bucket: "%s") |> range(start: -1h)`, l.Bucket.Name))
+
+	qs := fmt.Sprintf(`from(bucket: "%s")
+	|> range(start: -1h)
+	|> filter(fn: (r) => r._measurement == "queries" and r._field == "rowCount")
+	|> keep(columns: ["_value", "status", "userID"])`, querylog.BucketName)
+	got := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, qs)
+	if exp := fmt.Sprintf(",1,success,%s\r\n", l.User.ID); !strings.Contains(got, exp) {
+		t.Fatalf("expected the log of the query in the query log bucket to contain %q, got:\n%s", exp, got)
+	}
+
+	// The query log bucket is a bucket of the organization with a retention period.
+	name := querylog.BucketName
+	b, err := l.BucketService().FindBucket(ctx, platform.BucketFilter{OrganizationID: &l.Org.ID, Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.RetentionPeriod != querylog.DefaultRetentionPeriod {
+		t.Errorf("unexpected retention period of query log bucket -got/+exp\n%v\n%v", b.RetentionPeriod, querylog.DefaultRetentionPeriod)
+	}
+}
+
+func TestLauncher_QueryProfile(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	now := time.Now()
+	l.WritePointsOrFail(t, fmt.Sprintf("m f=1 %d\nm f=2 %d", now.Add(-2*time.Minute).UnixNano(), now.Add(-time.Minute).UnixNano()))
+
+	body, err := json.Marshal(map[string]interface{}{
+		"query":   fmt.Sprintf(`from(bucket: "%s") |> range(start: -1h) |> filter(fn: (r) => r._measurement == "m")`, l.Bucket.Name),
+		"profile": true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := l.NewHTTPRequestOrFail(t, "POST", "/api/v2/query?orgID="+l.Org.ID.String(), l.Auth.Token, string(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != nethttp.StatusOK {
+		t.Fatalf("unexpected status code %d: %s", resp.StatusCode, b)
+	}
+
+	// The filter is pushed down to storage, so the read from storage is the
+	// only operation of the physical plan.
+	got := string(b)
+	if exp := ",ReadRangePhysKind,1,2,"; !strings.Contains(got, exp) {
+		t.Fatalf("expected the profile of the storage read %q, got:\n%s", exp, got)
+	}
+}
+
+func TestLauncher_QueryFormats(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	ts := time.Now().Add(-time.Minute).UnixNano()
+	l.WritePointsOrFail(t, fmt.Sprintf("m,host=a f=1 %d", ts))
+
+	q := fmt.Sprintf(`from(bucket: "%s") |> range(start: -1h) |> drop(columns: ["_start", "_stop"])`, l.Bucket.Name)
+	for _, tt := range []struct {
+		name        string
+		accept      string
+		format      string
+		contentType string
+		check       func(t *testing.T, body []byte)
+	}{
+		{
+			name:        "json dialect",
+			format:      "json",
+			contentType: "application/json",
+			check: func(t *testing.T, body []byte) {
+				if !json.Valid(body) {
+					t.Fatalf("expected valid JSON, got:\n%s", body)
+				}
+			},
+		},
+		{
+			name:        "line protocol dialect",
+			format:      "lineprotocol",
+			contentType: "text/plain; charset=utf-8",
+			check: func(t *testing.T, body []byte) {
+				if exp := fmt.Sprintf("m,host=a f=1 %d\n", ts); string(body) != exp {
+					t.Fatalf("unexpected line protocol -got/+exp\n%s\n%s", body, exp)
+				}
+			},
+		},
+		{
+			name:        "arrow accept",
+			accept:      "application/vnd.apache.arrow.stream",
+			contentType: "application/vnd.apache.arrow.stream",
+			check: func(t *testing.T, body []byte) {
+				if len(body) == 0 || len(body)%8 != 4 {
+					t.Fatalf("unexpected length of arrow stream: %d", len(body))
+				}
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			body, contentType := q, "application/vnd.flux"
+			if tt.format != "" {
+				b, err := json.Marshal(map[string]interface{}{
+					"query":   q,
+					"dialect": map[string]string{"format": tt.format},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				body, contentType = string(b), "application/json"
+			}
+			req := l.NewHTTPRequestOrFail(t, "POST", "/api/v2/query?orgID="+l.Org.ID.String(), l.Auth.Token, body)
+			req.Header.Set("Content-Type", contentType)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp, err := nethttp.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != nethttp.StatusOK {
+				t.Fatalf("unexpected status code %d: %s", resp.StatusCode, b)
+			}
+			if got := resp.Header.Get("Content-Type"); got != tt.contentType {
+				t.Fatalf("unexpected content type -got/+exp\n%s\n%s", got, tt.contentType)
+			}
+			tt.check(t, b)
+		})
+	}
+}
+
+func TestLauncher_QueryJobs(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	ts := time.Now().Add(-time.Minute).UnixNano()
+	l.WritePointsOrFail(t, fmt.Sprintf("m,host=a f=1 %d\nm,host=b f=2 %d", ts, ts))
+
+	svc := l.QueryJobService()
+	j, err := svc.SubmitJob(ctx, &query.Request{
+		OrganizationID: l.Org.ID,
+		Compiler: lang.FluxCompiler{
+			Query: fmt.Sprintf(`from(bucket: "%s") |> range(start: -1h)`, l.Bucket.Name),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !j.Status.Finished() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for query job to finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+		if j, err = svc.FindJobByID(ctx, j.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if j.Status != query.JobSucceeded || j.Pages != 1 || j.Rows != 2 {
+		t.Fatalf("unexpected job %+v", j)
+	}
+
+	var buf bytes.Buffer
+	if err := svc.WriteJobPage(ctx, &buf, j.ID, 0, phttp.QueryFormatLineProtocol); err != nil {
+		t.Fatal(err)
+	}
+	// The order of the tables is not guaranteed.
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	sort.Strings(lines)
+	if got, exp := strings.Join(lines, "\n"), fmt.Sprintf("m,host=a f=1 %d\nm,host=b f=2 %d", ts, ts); got != exp {
+		t.Errorf("unexpected line protocol -got/+exp\n%s\n%s", got, exp)
+	}
+
+	if err := svc.DeleteJob(ctx, j.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ReadJobPage(ctx, j.ID, 0); err == nil {
+		t.Fatal("expected deleted job not to be found")
+	}
+}
+
+func TestLauncher_QueryParams(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	now := time.Now()
+	l.WritePointsOrFail(t, fmt.Sprintf("m,host=a f=1 %d\nm,host=b f=2 %d", now.Add(-2*time.Minute).UnixNano(), now.Add(-time.Minute).UnixNano()))
+
+	v := &platform.Variable{
+		OrganizationID: l.Org.ID,
+		Name:           "host",
+		Arguments: &platform.VariableArguments{
+			Type:   "constant",
+			Values: platform.VariableConstantValues{"a", "b"},
+		},
+		Selected: []string{"a"},
+	}
+	if err := l.VariableService().CreateVariable(ctx, v); err != nil {
+		t.Fatal(err)
+	}
+
+	q := `from(bucket: params.bucket)
+	|> range(start: params.start)
+	|> filter(fn: (r) => r._measurement == "m" and r.host == params.host)
+	|> keep(columns: ["host", "_value"])`
+	for _, tt := range []struct {
+		name   string
+		params string
+		exp    string
+		unexp  string
+	}{
+		{
+			name:   "variable",
+			params: fmt.Sprintf(`{"bucket": %q, "start": {"type": "duration", "value": "-1h"}, "host": {"type": "variable", "value": "host"}}`, l.Bucket.Name),
+			exp:    ",1,a\r\n",
+			unexp:  ",2,b\r\n",
+		},
+		{
+			name:   "selected",
+			params: fmt.Sprintf(`{"bucket": %q, "start": {"type": "duration", "value": "-1h"}, "host": {"type": "variable", "value": "host", "selected": "b"}}`, l.Bucket.Name),
+			exp:    ",2,b\r\n",
+			unexp:  ",1,a\r\n",
+		},
+		{
+			name:   "string",
+			params: fmt.Sprintf(`{"bucket": %q, "start": {"type": "time", "value": %q}, "host": "b"}`, l.Bucket.Name, now.Add(-time.Hour).Format(time.RFC3339)),
+			exp:    ",2,b\r\n",
+			unexp:  ",1,a\r\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"query": %q, "params": %s}`, q, tt.params)
+			req := l.NewHTTPRequestOrFail(t, "POST", "/api/v2/query?orgID="+l.Org.ID.String(), l.Auth.Token, body)
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := nethttp.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != nethttp.StatusOK {
+				t.Fatalf("unexpected status code %d: %s", resp.StatusCode, b)
+			}
+			if got := string(b); !strings.Contains(got, tt.exp) || strings.Contains(got, tt.unexp) {
+				t.Fatalf("expected row %q and not %q, got:\n%s", tt.exp, tt.unexp, got)
+			}
+		})
+	}
+
+	// A value that is not one of the values of the variable is rejected.
+	body := fmt.Sprintf(`{"query": %q, "params": {"bucket": %q, "start": {"type": "duration", "value": "-1h"}, "host": {"type": "variable", "value": "host", "selected": "a\" or true or \""}}}`, q, l.Bucket.Name)
+	req := l.NewHTTPRequestOrFail(t, "POST", "/api/v2/query?orgID="+l.Org.ID.String(), l.Auth.Token, body)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != nethttp.StatusBadRequest {
+		t.Fatalf("unexpected status code %d", resp.StatusCode)
+	}
+}
+
+func TestLauncher_QuerySchema(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	now := time.Now()
+	l.WritePointsOrFail(t, fmt.Sprintf(`cpu,host=a,region=west usage=1,idle=2 %d
+cpu,host=b,region=east usage=3 %d
+mem,host=a free=4 %d
+disk,host=c used=5 %d`,
+		now.Add(-2*time.Hour).UnixNano(),
+		now.Add(-time.Minute).UnixNano(),
+		now.Add(-time.Minute).UnixNano(),
+		now.Add(-60*24*time.Hour).UnixNano()))
+
+	for _, tt := range []struct {
+		call string
+		exp  []string
+	}{
+		{call: `measurements(bucket: "%s")`, exp: []string{"cpu", "mem"}},
+		{call: `measurements(bucket: "%s", start: -90d)`, exp: []string{"cpu", "disk", "mem"}},
+		{call: `measurements(bucket: "%s", start: -1h)`, exp: []string{"cpu", "mem"}},
+		{call: `measurements(bucket: "%s", predicate: (r) => r.region == "west")`, exp: []string{"cpu"}},
+		{call: `measurementTagKeys(bucket: "%s", measurement: "cpu")`, exp: []string{"host", "region"}},
+		{call: `measurementTagKeys(bucket: "%s", measurement: "mem")`, exp: []string{"host"}},
+		{call: `measurementTagValues(bucket: "%s", measurement: "cpu", tag: "host")`, exp: []string{"a", "b"}},
+		{call: `measurementTagValues(bucket: "%s", measurement: "cpu", tag: "host", predicate: (r) => r.region == "east")`, exp: []string{"b"}},
+		{call: `fieldKeys(bucket: "%s")`, exp: []string{"free", "idle", "usage"}},
+		{call: `fieldKeys(bucket: "%s", measurement: "cpu", start: -1h)`, exp: []string{"usage"}},
+		{call: `cardinality(bucket: "%s")`, exp: []string{"4"}},
+		{call: `cardinality(bucket: "%s", start: -1h)`, exp: []string{"2"}},
+		{call: `cardinality(bucket: "%s", predicate: (r) => r._measurement == "cpu")`, exp: []string{"3"}},
+	} {
+		q := `import "influxdata/influxdb/schema"
+schema.` + fmt.Sprintf(tt.call, l.Bucket.Name)
+		t.Run(q, func(t *testing.T) {
+			var got []string
+			for _, line := range strings.Split(l.FluxQueryOrFail(t, l.Org, l.Auth.Token, q), "\r\n") {
+				if strings.HasPrefix(line, ",_result,") {
+					got = append(got, line[strings.LastIndex(line, ",")+1:])
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.exp, ",") {
+				t.Fatalf("unexpected values -got/+exp\n%v\n%v", got, tt.exp)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package launcher_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influxd/launcher"
+)
+
+func TestLauncher_Replication(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	// Replicate to a bucket of another organization through the HTTP API,
+	// the same way writes are sent to a remote influxd.
+	remote := l.OnBoardOrFail(t, &influxdb.OnboardingRequest{
+		User:     "REMOTE-USER",
+		Password: "PASSWORD",
+		Org:      "REMOTE-ORG",
+		Bucket:   "REMOTE-BUCKET",
+	})
+
+	r := &influxdb.Replication{
+		OrgID:          l.Org.ID,
+		Name:           "remote",
+		LocalBucketID:  l.Bucket.ID,
+		RemoteURL:      l.URL(),
+		RemoteToken:    remote.Auth.Token,
+		RemoteOrgID:    remote.Org.ID,
+		RemoteBucketID: remote.Bucket.ID,
+	}
+	if err := l.ReplicationService().CreateReplication(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+
+	l.WritePointsOrFail(t, `m,k=v f=100i 946684800000000000`)
+
+	qs := `from(bucket:"REMOTE-BUCKET") |> range(start:2000-01-01T00:00:00Z,stop:2000-01-02T00:00:00Z)`
+	exp := `,result,table,_start,_stop,_time,_value,_field,_measurement,k` + "\r\n" +
+		`,_result,0,2000-01-01T00:00:00Z,2000-01-02T00:00:00Z,2000-01-01T00:00:00Z,100,f,m,v` + "\r\n\r\n"
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		got := l.FluxQueryOrFail(t, remote.Org, remote.Auth.Token, qs)
+		if got == exp {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("write was not replicated -got/+exp\n%s\n%s", got, exp)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,140 @@
+package launcher_test
+
+import (
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"testing"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influxd/launcher"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/storage/readservice"
+	"google.golang.org/grpc"
+)
+
+func TestLauncher_Role(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	readBucket, err := influxdb.NewPermissionAtID(l.Bucket.ID, influxdb.ReadAction, influxdb.BucketsResourceType, l.Org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	role := &influxdb.Role{
+		OrgID:       l.Org.ID,
+		Name:        "readers",
+		Permissions: []influxdb.Permission{*readBucket},
+	}
+	if err := l.RoleService().CreateRole(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+
+	// A token holding only the role.
+	auth := &influxdb.Authorization{
+		OrgID:   l.Org.ID,
+		UserID:  l.User.ID,
+		RoleIDs: []influxdb.ID{role.ID},
+	}
+	if err := l.AuthorizationService().CreateAuthorization(ctx, auth); err != nil {
+		t.Fatal(err)
+	}
+
+	// A user that is a member of the role, signed in with a session.
+	svc := l.KeyValueService()
+	user := &influxdb.User{Name: "analyst"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SetPassword(ctx, user.Name, "analyst-password"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+		UserID:       user.ID,
+		UserType:     influxdb.Member,
+		ResourceType: influxdb.RolesResourceType,
+		ResourceID:   role.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	signin := l.MustNewHTTPRequest("POST", "/api/v2/signin", "")
+	signin.SetBasicAuth(user.Name, "analyst-password")
+	resp, err := nethttp.DefaultClient.Do(signin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != nethttp.StatusNoContent {
+		t.Fatalf("unexpected status signing in %d", resp.StatusCode)
+	}
+	cookies := resp.Cookies()
+
+	getBucket := func(withToken bool) int {
+		t.Helper()
+		req := l.MustNewHTTPRequest("GET", fmt.Sprintf("/api/v2/buckets/%s", l.Bucket.ID), "")
+		if withToken {
+			req.Header.Set("Authorization", "Token "+auth.Token)
+		} else {
+			req.Header.Del("Authorization")
+			for _, c := range cookies {
+				req.AddCookie(c)
+			}
+		}
+		resp, err := nethttp.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	for _, withToken := range []bool{true, false} {
+		if got := getBucket(withToken); got != nethttp.StatusOK {
+			t.Errorf("holder of role (token %t): got status %d, want %d", withToken, got, nethttp.StatusOK)
+		}
+	}
+
+	// The token is granted the permissions of its role by the gRPC read service too.
+	l.WritePointsOrFail(t, "m,k=v f=1i 946684800000000000")
+	conn, err := grpc.Dial(l.GRPCAddr(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	store := readservice.NewRemoteStore(conn, auth.Token)
+	source, err := types.MarshalAny(store.GetSource(uint64(l.Org.ID), uint64(l.Bucket.ID)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter, err := store.TagValues(ctx, &datatypes.TagValuesRequest{
+		TagsSource: source,
+		Range:      datatypes.TimestampRange{Start: 946684800000000000, End: 946684800000000001},
+		TagKey:     "k",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for iter.Next() {
+		got = append(got, iter.Value())
+	}
+	if err := iter.(interface{ Err() error }).Err(); err != nil && err != io.EOF {
+		t.Fatalf("unexpected error reading with the role over gRPC: %v", err)
+	}
+	if len(got) != 1 || got[0] != "v" {
+		t.Errorf("unexpected tag values %v", got)
+	}
+
+	// Removing the permission from the role revokes it from every holder at once.
+	none := []influxdb.Permission{}
+	if _, err := l.RoleService().UpdateRole(ctx, role.ID, influxdb.RoleUpdate{Permissions: &none}); err != nil {
+		t.Fatal(err)
+	}
+	for _, withToken := range []bool{true, false} {
+		if got := getBucket(withToken); got == nethttp.StatusOK {
+			t.Errorf("holder of updated role (token %t): got status %d", withToken, got)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package launcher_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influxd/launcher"
+	"github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/storage/readservice"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func TestStorage_GRPC(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, "m,k=v1 f=1i 946684800000000000\nm,k=v2 f=2i 946684800000000000")
+
+	conn, err := grpc.Dial(l.GRPCAddr(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	store := readservice.NewRemoteStore(conn, l.Auth.Token)
+	source, err := types.MarshalAny(store.GetSource(uint64(l.Org.ID), uint64(l.Bucket.ID)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &datatypes.TagValuesRequest{
+		TagsSource: source,
+		Range:      datatypes.TimestampRange{Start: 946684800000000000, End: 946684800000000001},
+		TagKey:     "k",
+	}
+
+	iter, err := store.TagValues(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for iter.Next() {
+		got = append(got, iter.Value())
+	}
+	if len(got) != 2 || got[0] != "v1" || got[1] != "v2" {
+		t.Errorf("unexpected tag values %v", got)
+	}
+
+	rs, err := store.ReadFilter(ctx, &datatypes.ReadFilterRequest{ReadSource: source, Range: req.Range})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var series, sum int64
+	for rs.Next() {
+		series++
+		cur := rs.Cursor().(cursors.IntegerArrayCursor)
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for _, v := range a.Values {
+				sum += v
+			}
+		}
+		cur.Close()
+	}
+	rs.Close()
+	if err := rs.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if series != 2 || sum != 3 {
+		t.Errorf("unexpected series %d with sum %d", series, sum)
+	}
+
+	// Reads require a token that may read the bucket.
+	iter, err = readservice.NewRemoteStore(conn, "invalid").TagValues(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for iter.Next() {
+		t.Error("unexpected tag value for invalid token")
+	}
+	if code := influxdb.ErrorCode(iter.(interface{ Err() error }).Err()); code != influxdb.EUnauthorized {
+		t.Errorf("unexpected error code -got/+exp\n%s\n%s", code, influxdb.EUnauthorized)
+	}
+
+	// A token derived from an inactive token may not read, as over HTTP.
+	readBucket, err := influxdb.NewPermissionAtID(l.Bucket.ID, influxdb.ReadAction, influxdb.BucketsResourceType, l.Org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := l.AuthorizationService()
+	parent := &influxdb.Authorization{
+		OrgID:       l.Org.ID,
+		UserID:      l.User.ID,
+		Permissions: []influxdb.Permission{*readBucket},
+	}
+	if err := svc.CreateAuthorization(ctx, parent); err != nil {
+		t.Fatal(err)
+	}
+	parentSvc := &http.AuthorizationService{Addr: l.URL(), Token: parent.Token}
+	child, err := parentSvc.DeriveAuthorization(ctx, &influxdb.AuthorizationDerive{
+		Permissions: []influxdb.Permission{*readBucket},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readservice.NewRemoteStore(conn, child.Token).TagValues(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+
+	inactive := influxdb.Inactive
+	if _, err := svc.UpdateAuthorization(ctx, parent.ID, &influxdb.AuthorizationUpdate{Status: &inactive}); err != nil {
+		t.Fatal(err)
+	}
+	iter, err = readservice.NewRemoteStore(conn, child.Token).TagValues(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for iter.Next() {
+		t.Error("unexpected tag value for a token derived from an inactive token")
+	}
+	if code := influxdb.ErrorCode(iter.(interface{ Err() error }).Err()); code != influxdb.EUnauthorized {
+		t.Errorf("unexpected error code -got/+exp\n%s\n%s", code, influxdb.EUnauthorized)
+	}
+}
+
+func TestStorage_GRPC_TLS(t *testing.T) {
+	l := launcher.NewTestLauncher()
+	certFile, keyFile, pool := mustWriteCertificate(t, l.Path)
+	if err := l.Run(ctx, "--grpc-tls-cert", certFile, "--grpc-tls-key", keyFile); err != nil {
+		t.Fatal(err)
+	}
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, "m,k=v1 f=1i 946684800000000000")
+
+	conn, err := grpc.Dial(l.GRPCAddr(), grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(pool, "")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	store := readservice.NewRemoteStore(conn, l.Auth.Token)
+	source, err := types.MarshalAny(store.GetSource(uint64(l.Org.ID), uint64(l.Bucket.ID)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter, err := store.TagValues(ctx, &datatypes.TagValuesRequest{
+		TagsSource: source,
+		Range:      datatypes.TimestampRange{Start: 946684800000000000, End: 946684800000000001},
+		TagKey:     "k",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for iter.Next() {
+		got = append(got, iter.Value())
+	}
+	if len(got) != 1 || got[0] != "v1" {
+		t.Errorf("unexpected tag values %v", got)
+	}
+}
+
+// mustWriteCertificate writes a self-signed certificate for 127.0.0.1 and its
+// key to dir and returns their paths and a pool trusting the certificate.
+func mustWriteCertificate(t *testing.T, dir string) (string, string, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "influxd"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile := filepath.Join(dir, "grpc.crt"), filepath.Join(dir, "grpc.key")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
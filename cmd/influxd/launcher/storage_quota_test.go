@@ -0,0 +1,115 @@
+package launcher_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	nethttp "net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/cmd/influxd/launcher"
+)
+
+func TestLauncher_StorageQuota(t *testing.T) {
+	l := launcher.NewTestLauncher()
+	l.StorageConfig.Engine.Cache.SnapshotMemorySize = 10
+	defer l.ShutdownOrFail(t, ctx)
+
+	if err := l.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	l.SetupOrFail(t)
+
+	do := func(req *nethttp.Request) (int, string) {
+		t.Helper()
+		resp, err := nethttp.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	type usage struct {
+		TSMBytes   int64 `json:"tsmBytes"`
+		IndexBytes int64 `json:"indexBytes"`
+		Bytes      int64 `json:"bytes"`
+		QuotaBytes int64 `json:"quotaBytes"`
+	}
+	getUsage := func(p string) usage {
+		t.Helper()
+		code, body := do(l.NewHTTPRequestOrFail(t, "GET", p, l.Auth.Token, ""))
+		if code != nethttp.StatusOK {
+			t.Fatalf("unexpected status code %d getting %s: %s", code, p, body)
+		}
+		var u usage
+		if err := json.Unmarshal([]byte(body), &u); err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+
+	bucketUsagePath := fmt.Sprintf("/api/v2/buckets/%s/usage", l.Bucket.ID)
+	orgUsagePath := fmt.Sprintf("/api/v2/orgs/%s/usage", l.Org.ID)
+
+	if u := getUsage(bucketUsagePath); u.Bytes != 0 {
+		t.Fatalf("expected no usage of an empty bucket, got %+v", u)
+	}
+
+	l.WritePointsOrFail(t, `cpu,host=a f=1i 946684800000000000
+cpu,host=b f=2i 946684800000000000
+mem,host=a f=3i 946684800000000000`)
+
+	// Wait for the cache to snapshot the points to TSM files.
+	var u usage
+	for i := 0; i < 50; i++ {
+		if u = getUsage(bucketUsagePath); u.TSMBytes > 0 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if u.TSMBytes == 0 || u.IndexBytes == 0 || u.Bytes != u.TSMBytes+u.IndexBytes {
+		t.Fatalf("unexpected bucket usage %+v", u)
+	}
+	if ou := getUsage(orgUsagePath); ou.Bytes < u.Bytes {
+		t.Fatalf("expected org usage %+v to include bucket usage %+v", ou, u)
+	}
+
+	// Writes are rejected once the bucket exceeds its quota.
+	code, body := do(l.NewHTTPRequestOrFail(t, "PATCH", fmt.Sprintf("/api/v2/buckets/%s", l.Bucket.ID), l.Auth.Token, `{"storageQuotaBytes": 1}`))
+	if code != nethttp.StatusOK {
+		t.Fatalf("unexpected status code %d setting bucket quota: %s", code, body)
+	}
+	if u := getUsage(bucketUsagePath); u.QuotaBytes != 1 {
+		t.Fatalf("expected bucket quota of 1 byte, got %+v", u)
+	}
+
+	writePath := fmt.Sprintf("/api/v2/write?org=%s&bucket=%s", l.Org.ID, l.Bucket.ID)
+	code, body = do(l.NewHTTPRequestOrFail(t, "POST", writePath, l.Auth.Token, `cpu,host=c f=4i 946684800000000000`))
+	if code != nethttp.StatusForbidden || !strings.Contains(body, "exceeded its storage quota") {
+		t.Fatalf("expected write to be rejected by bucket quota, got %d: %s", code, body)
+	}
+
+	// Removing the quota allows writes again.
+	code, body = do(l.NewHTTPRequestOrFail(t, "PATCH", fmt.Sprintf("/api/v2/buckets/%s", l.Bucket.ID), l.Auth.Token, `{"storageQuotaBytes": 0}`))
+	if code != nethttp.StatusOK {
+		t.Fatalf("unexpected status code %d removing bucket quota: %s", code, body)
+	}
+	l.WritePointsOrFail(t, `cpu,host=c f=4i 946684800000000000`)
+
+	// The same applies to organization quotas.
+	code, body = do(l.NewHTTPRequestOrFail(t, "PATCH", fmt.Sprintf("/api/v2/orgs/%s", l.Org.ID), l.Auth.Token, `{"storageQuotaBytes": 1}`))
+	if code != nethttp.StatusOK {
+		t.Fatalf("unexpected status code %d setting org quota: %s", code, body)
+	}
+	code, body = do(l.NewHTTPRequestOrFail(t, "POST", writePath, l.Auth.Token, `cpu,host=d f=5i 946684800000000000`))
+	if code != nethttp.StatusForbidden || !strings.Contains(body, "organization") {
+		t.Fatalf("expected write to be rejected by org quota, got %d: %s", code, body)
+	}
+}
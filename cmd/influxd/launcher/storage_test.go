@@ -247,3 +247,57 @@ func TestStorage_CacheSnapshot_Age(t *testing.T) {
 		t.Fatalf("got %d series in TSM files, expected %d", got, exp)
 	}
 }
+
+func TestLauncher_RestrictedToken(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, `cpu,team=payments f=1i 946684800000000000
+cpu,team=billing f=2i 946684800000000000
+mem,team=payments f=3i 946684800000000000`)
+
+	restriction := &influxdb.Restriction{
+		Measurements: []string{"cpu"},
+		Tags:         []influxdb.RestrictionTag{{Key: "team", Value: "payments"}},
+	}
+	var ps []influxdb.Permission
+	for _, a := range []influxdb.Action{influxdb.ReadAction, influxdb.WriteAction} {
+		p, err := influxdb.NewPermissionAtID(l.Bucket.ID, a, influxdb.BucketsResourceType, l.Org.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Restriction = restriction
+		ps = append(ps, *p)
+	}
+	auth := &influxdb.Authorization{
+		OrgID:       l.Org.ID,
+		UserID:      l.User.ID,
+		Permissions: ps,
+	}
+	if err := l.AuthorizationService().CreateAuthorization(ctx, auth); err != nil {
+		t.Fatal(err)
+	}
+
+	qs := fmt.Sprintf(`from(bucket:"%s") |> range(start:2000-01-01T00:00:00Z,stop:2000-01-02T00:00:00Z) |> keep(columns: ["_value"])`, l.Bucket.Name)
+	exp := `,result,table,_value` + "\r\n" +
+		`,_result,0,1` + "\r\n\r\n"
+	if got := l.FluxQueryOrFail(t, l.Org, auth.Token, qs); !cmp.Equal(got, exp) {
+		t.Errorf("unexpected query results -got/+exp\n%s", cmp.Diff(got, exp))
+	}
+
+	for data, status := range map[string]int{
+		`cpu,team=payments f=4i 946684800000000001`: nethttp.StatusNoContent,
+		`cpu,team=billing f=5i 946684800000000001`:  nethttp.StatusForbidden,
+		`mem,team=payments f=6i 946684800000000001`: nethttp.StatusForbidden,
+	} {
+		resp, err := nethttp.DefaultClient.Do(l.NewHTTPRequestOrFail(t, "POST", fmt.Sprintf("/api/v2/write?org=%s&bucket=%s", l.Org.ID, l.Bucket.ID), auth.Token, data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Errorf("write of %q: got status %d, want %d", data, resp.StatusCode, status)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	nethttp "net/http"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,7 +16,6 @@ import (
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/cmd/influxd/launcher"
 	pctx "github.com/influxdata/influxdb/context"
-	"github.com/influxdata/influxdb/task/backend"
 )
 
 func TestLauncher_Task(t *testing.T) {
@@ -103,12 +103,11 @@ from(bucket:"my_bucket_in") |> range(start:-5m) |> to(bucket:"%s", org:"%s")`, b
 		t.Fatal(err)
 	}
 
-	// Find the next due run of the task we just created, so that we can accurately tick the scheduler to it.
+	// Find the next due run of the task we just created, so that we can look for the run the scheduler creates for it.
 	ndr, err := be.TaskControlService().NextDueRun(ctx, created.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
-	be.TaskScheduler().(*backend.TickScheduler).Tick(ndr + 1)
 
 	// Poll for the task to have started and finished.
 	deadline := time.Now().Add(10 * time.Second) // Arbitrary deadline; 10s seems safe for -race on a resource-constrained system.
@@ -249,3 +248,79 @@ from(bucket:"my_bucket_in") |> range(start:-5m) |> to(bucket:"%s", org:"%s")`, b
 		t.Fatalf("unmarshalled query statistics are zero; they should be non-zero. JSON: %s", statJSON)
 	}
 }
+
+func TestLauncher_TaskBackfill(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	bOut := &influxdb.Bucket{OrgID: l.Org.ID, Name: "backfill_out"}
+	if err := l.BucketService().CreateBucket(context.Background(), bOut); err != nil {
+		t.Fatal(err)
+	}
+
+	// One point in each hour of the backfill.
+	start := time.Now().UTC().Truncate(time.Hour).Add(-24 * time.Hour)
+	l.WritePointsOrFail(t, fmt.Sprintf(`m v=1 %d
+m v=2 %d
+m v=3 %d`,
+		start.Add(30*time.Minute).UnixNano(),
+		start.Add(90*time.Minute).UnixNano(),
+		start.Add(150*time.Minute).UnixNano(),
+	))
+
+	// The task copies the hour before each of its runs.
+	task, err := l.TaskService().CreateTask(context.Background(), influxdb.TaskCreate{
+		OrganizationID: l.Org.ID,
+		Flux: fmt.Sprintf(`option task = {name: "downsample", every: 1h}
+
+from(bucket: "%s") |> range(start: -1h) |> to(bucket: "%s", org: "%s")`, l.Bucket.Name, bOut.Name, l.Org.Name),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := l.BackfillService().CreateBackfill(context.Background(), task.ID, influxdb.BackfillCreate{
+		Start:       start,
+		Stop:        start.Add(3 * time.Hour),
+		Concurrency: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Total != 3 || b.Queued != 2 {
+		t.Fatalf("unexpected backfill after create: %+v", b)
+	}
+
+	deadline := time.Now().Add(30 * time.Second)
+	for b.Status == influxdb.BackfillStatusRunning {
+		if time.Now().After(deadline) {
+			t.Fatalf("backfill did not finish within deadline: %+v", b)
+		}
+		time.Sleep(100 * time.Millisecond)
+
+		b, err = l.BackfillService().FindBackfillByID(context.Background(), task.ID, b.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b.Status != influxdb.BackfillStatusSuccess || b.Finished != 3 {
+		t.Fatalf("unexpected backfill after finishing: %+v", b)
+	}
+
+	res := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, fmt.Sprintf(`from(bucket: "%s")
+	|> range(start: -2d)
+	|> keep(columns: ["_value"])
+	|> sum()`, bOut.Name))
+	if !strings.Contains(res, ",_result,0,6") {
+		t.Fatalf("expected the three backfilled points to sum to 6, got:\n%s", res)
+	}
+
+	bs, err := l.BackfillService().FindBackfills(context.Background(), influxdb.BackfillFilter{Task: &task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bs) != 1 || bs[0].ID != b.ID {
+		t.Fatalf("unexpected backfills %+v", bs)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/cmd/influxd/generate"
+	"github.com/influxdata/influxdb/cmd/influxd/importer"
 	"github.com/influxdata/influxdb/cmd/influxd/inspect"
 	"github.com/influxdata/influxdb/cmd/influxd/launcher"
 	_ "github.com/influxdata/influxdb/query/builtin"
@@ -36,6 +37,7 @@ func init() {
 
 	rootCmd.AddCommand(launcher.NewCommand())
 	rootCmd.AddCommand(generate.Command)
+	rootCmd.AddCommand(importer.NewCommand())
 	rootCmd.AddCommand(inspect.NewCommand())
 }
 
@@ -0,0 +1,21 @@
+package context
+
+import (
+	"context"
+)
+
+const (
+	remoteAddrCtxKey = contextKey("influx/remoteaddr/v1")
+)
+
+// SetRemoteAddr sets the network address of the client of a request on context.
+func SetRemoteAddr(ctx context.Context, addr string) context.Context {
+	return context.WithValue(ctx, remoteAddrCtxKey, addr)
+}
+
+// GetRemoteAddr retrieves the network address of the client of a request from context.
+// It is empty if the context is not one of a request.
+func GetRemoteAddr(ctx context.Context) string {
+	addr, _ := ctx.Value(remoteAddrCtxKey).(string)
+	return addr
+}
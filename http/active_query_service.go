@@ -0,0 +1,362 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	queriesPath = "/api/v2/queries"
+)
+
+// ActiveQueryBackend is all services and associated parameters required to construct
+// the ActiveQueryHandler.
+type ActiveQueryBackend struct {
+	influxdb.HTTPErrorHandler
+	Logger              *zap.Logger
+	ActiveQueryService  query.ActiveQueryService
+	OrganizationService influxdb.OrganizationService
+}
+
+// NewActiveQueryBackend creates a backend used by the active query handler.
+func NewActiveQueryBackend(b *APIBackend) *ActiveQueryBackend {
+	return &ActiveQueryBackend{
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		Logger:              b.Logger.With(zap.String("handler", "active_query")),
+		ActiveQueryService:  b.ActiveQueryService,
+		OrganizationService: b.OrganizationService,
+	}
+}
+
+// ActiveQueryHandler is the handler for listing and canceling running queries.
+type ActiveQueryHandler struct {
+	*httprouter.Router
+
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	ActiveQueryService  query.ActiveQueryService
+	OrganizationService influxdb.OrganizationService
+}
+
+// NewActiveQueryHandler creates a new ActiveQueryHandler.
+func NewActiveQueryHandler(b *ActiveQueryBackend) *ActiveQueryHandler {
+	h := &ActiveQueryHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Logger:           b.Logger,
+
+		ActiveQueryService:  b.ActiveQueryService,
+		OrganizationService: b.OrganizationService,
+	}
+
+	entityPath := fmt.Sprintf("%s/:id", queriesPath)
+
+	h.HandlerFunc("GET", queriesPath, h.handleGetQueries)
+	h.HandlerFunc("GET", entityPath, h.handleGetQuery)
+	h.HandlerFunc("DELETE", entityPath, h.handleDeleteQuery)
+
+	return h
+}
+
+type activeQueryLinks struct {
+	Self string `json:"self"`
+	Org  string `json:"org"`
+}
+
+// activeQueryResponse encodes the IDs of an active query as strings, since
+// queries submitted without an authorization have no user.
+type activeQueryResponse struct {
+	ID           string           `json:"id"`
+	OrgID        string           `json:"orgID"`
+	UserID       string           `json:"userID,omitempty"`
+	Query        string           `json:"query"`
+	CompilerType string           `json:"compilerType"`
+	State        string           `json:"state"`
+	StartedAt    time.Time        `json:"startedAt"`
+	Elapsed      string           `json:"elapsed"`
+	MemoryBytes  int64            `json:"memoryBytes"`
+	Links        activeQueryLinks `json:"links"`
+}
+
+func newActiveQueryResponse(q *query.ActiveQuery) *activeQueryResponse {
+	resp := &activeQueryResponse{
+		ID:           q.ID.String(),
+		OrgID:        q.OrganizationID.String(),
+		Query:        q.Query,
+		CompilerType: q.CompilerType,
+		State:        q.State,
+		StartedAt:    q.StartedAt,
+		Elapsed:      q.Elapsed.String(),
+		MemoryBytes:  q.MemoryBytes,
+		Links: activeQueryLinks{
+			Self: fmt.Sprintf("/api/v2/queries/%s", q.ID),
+			Org:  fmt.Sprintf("/api/v2/orgs/%s", q.OrganizationID),
+		},
+	}
+	if q.UserID.Valid() {
+		resp.UserID = q.UserID.String()
+	}
+	return resp
+}
+
+func (r *activeQueryResponse) toActiveQuery() (*query.ActiveQuery, error) {
+	q := &query.ActiveQuery{
+		Query:        r.Query,
+		CompilerType: r.CompilerType,
+		State:        r.State,
+		StartedAt:    r.StartedAt,
+		MemoryBytes:  r.MemoryBytes,
+	}
+	if err := q.ID.DecodeFromString(r.ID); err != nil {
+		return nil, err
+	}
+	if err := q.OrganizationID.DecodeFromString(r.OrgID); err != nil {
+		return nil, err
+	}
+	if r.UserID != "" {
+		if err := q.UserID.DecodeFromString(r.UserID); err != nil {
+			return nil, err
+		}
+	}
+	elapsed, err := time.ParseDuration(r.Elapsed)
+	if err != nil {
+		return nil, err
+	}
+	q.Elapsed = elapsed
+	return q, nil
+}
+
+type activeQueriesResponse struct {
+	Queries []*activeQueryResponse `json:"queries"`
+	Links   map[string]string      `json:"links"`
+}
+
+func newActiveQueriesResponse(qs []*query.ActiveQuery) *activeQueriesResponse {
+	resp := &activeQueriesResponse{
+		Queries: make([]*activeQueryResponse, 0, len(qs)),
+		Links: map[string]string{
+			"self": queriesPath,
+		},
+	}
+	for _, q := range qs {
+		resp.Queries = append(resp.Queries, newActiveQueryResponse(q))
+	}
+	return resp
+}
+
+func (h *ActiveQueryHandler) decodeActiveQueryFilter(ctx context.Context, r *http.Request) (*query.ActiveQueryFilter, error) {
+	qp := r.URL.Query()
+	f := &query.ActiveQueryFilter{}
+
+	if orgID := qp.Get("orgID"); orgID != "" {
+		id, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			return nil, err
+		}
+		f.OrganizationID = id
+	} else if org := qp.Get("org"); org != "" {
+		o, err := h.OrganizationService.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &org})
+		if err != nil {
+			return nil, err
+		}
+		f.OrganizationID = &o.ID
+	}
+	return f, nil
+}
+
+func (h *ActiveQueryHandler) handleGetQueries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := h.decodeActiveQueryFilter(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	qs, err := h.ActiveQueryService.FindActiveQueries(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newActiveQueriesResponse(qs)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func requestActiveQueryID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	urlID := params.ByName("id")
+	if urlID == "" {
+		return influxdb.InvalidID(), &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	id, err := influxdb.IDFromString(urlID)
+	if err != nil {
+		return influxdb.InvalidID(), err
+	}
+	return *id, nil
+}
+
+func (h *ActiveQueryHandler) handleGetQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestActiveQueryID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	q, err := h.ActiveQueryService.FindActiveQueryByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newActiveQueryResponse(q)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *ActiveQueryHandler) handleDeleteQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestActiveQueryID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.ActiveQueryService.CancelActiveQuery(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("query canceled", zap.String("queryID", fmt.Sprint(id)))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ActiveQueryService connects to Influx via HTTP using tokens to list and cancel running queries.
+type ActiveQueryService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ query.ActiveQueryService = (*ActiveQueryService)(nil)
+
+// FindActiveQueryByID returns a single running query by ID.
+func (s *ActiveQueryService) FindActiveQueryByID(ctx context.Context, id influxdb.ID) (*query.ActiveQuery, error) {
+	u, err := NewURL(s.Addr, activeQueryIDPath(id))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var qr activeQueryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&qr); err != nil {
+		return nil, err
+	}
+	return qr.toActiveQuery()
+}
+
+// FindActiveQueries returns the running queries that match filter.
+func (s *ActiveQueryService) FindActiveQueries(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+	u, err := NewURL(s.Addr, queriesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	qp := url.Values{}
+	if filter.OrganizationID != nil {
+		qp.Set("orgID", filter.OrganizationID.String())
+	}
+	req.URL.RawQuery = qp.Encode()
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var qr activeQueriesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&qr); err != nil {
+		return nil, err
+	}
+
+	qs := make([]*query.ActiveQuery, 0, len(qr.Queries))
+	for _, r := range qr.Queries {
+		q, err := r.toActiveQuery()
+		if err != nil {
+			return nil, err
+		}
+		qs = append(qs, q)
+	}
+	return qs, nil
+}
+
+// CancelActiveQuery cancels a running query by ID.
+func (s *ActiveQueryService) CancelActiveQuery(ctx context.Context, id influxdb.ID) error {
+	u, err := NewURL(s.Addr, activeQueryIDPath(id))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckErrorStatus(http.StatusNoContent, resp)
+}
+
+func activeQueryIDPath(id influxdb.ID) string {
+	return path.Join(queriesPath, id.String())
+}
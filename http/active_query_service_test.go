@@ -0,0 +1,151 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"go.uber.org/zap"
+)
+
+func newActiveQueryHandler(svc query.ActiveQueryService) *ActiveQueryHandler {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, f influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		if f.Name == nil || *f.Name != "edge" {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "organization not found"}
+		}
+		return &influxdb.Organization{ID: 0x1, Name: "edge"}, nil
+	}
+	return NewActiveQueryHandler(&ActiveQueryBackend{
+		HTTPErrorHandler:    ErrorHandler(0),
+		Logger:              zap.NewNop(),
+		ActiveQueryService:  svc,
+		OrganizationService: orgs,
+	})
+}
+
+func TestActiveQueryHandler_handleGetQueries(t *testing.T) {
+	svc := querymock.NewActiveQueryService()
+	svc.FindActiveQueriesFn = func(ctx context.Context, f query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+		if f.OrganizationID == nil || *f.OrganizationID != 0x1 {
+			t.Errorf("unexpected filter %+v", f)
+		}
+		return []*query.ActiveQuery{
+			{
+				ID:             0x10,
+				OrganizationID: 0x1,
+				UserID:         0x2,
+				Query:          `from(bucket: "telegraf") |> range(start: -1h)`,
+				CompilerType:   "flux",
+				State:          "executing",
+				StartedAt:      time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC),
+				Elapsed:        90 * time.Second,
+				MemoryBytes:    1024,
+			},
+		}, nil
+	}
+
+	r := httptest.NewRequest("GET", "http://any.url/api/v2/queries?org=edge", nil)
+	w := httptest.NewRecorder()
+	newActiveQueryHandler(svc).ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", res.StatusCode, body)
+	}
+
+	exp := `
+{
+  "links": {
+    "self": "/api/v2/queries"
+  },
+  "queries": [
+    {
+      "id": "0000000000000010",
+      "orgID": "0000000000000001",
+      "userID": "0000000000000002",
+      "query": "from(bucket: \"telegraf\") |> range(start: -1h)",
+      "compilerType": "flux",
+      "state": "executing",
+      "startedAt": "2019-05-01T12:00:00Z",
+      "elapsed": "1m30s",
+      "memoryBytes": 1024,
+      "links": {
+        "self": "/api/v2/queries/0000000000000010",
+        "org": "/api/v2/orgs/0000000000000001"
+      }
+    }
+  ]
+}`
+	if eq, diff, err := jsonEqual(string(body), exp); err != nil {
+		t.Fatalf("error unmarshaling json %v", err)
+	} else if !eq {
+		t.Errorf("unexpected response ***%s***", diff)
+	}
+}
+
+func TestActiveQueryService(t *testing.T) {
+	ctx := context.Background()
+
+	q := &query.ActiveQuery{
+		ID:             0x10,
+		OrganizationID: 0x1,
+		Query:          `from(bucket: "telegraf") |> range(start: -1h)`,
+		CompilerType:   "flux",
+		State:          "queueing",
+		StartedAt:      time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC),
+		Elapsed:        1500 * time.Millisecond,
+	}
+	var canceled bool
+	svc := querymock.NewActiveQueryService()
+	svc.FindActiveQueryByIDFn = func(ctx context.Context, id influxdb.ID) (*query.ActiveQuery, error) {
+		if id != q.ID || canceled {
+			return nil, query.ErrActiveQueryNotFound
+		}
+		return q, nil
+	}
+	svc.FindActiveQueriesFn = func(ctx context.Context, f query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+		if f.OrganizationID == nil || *f.OrganizationID != q.OrganizationID || canceled {
+			return nil, nil
+		}
+		return []*query.ActiveQuery{q}, nil
+	}
+	svc.CancelActiveQueryFn = func(ctx context.Context, id influxdb.ID) error {
+		if _, err := svc.FindActiveQueryByIDFn(ctx, id); err != nil {
+			return err
+		}
+		canceled = true
+		return nil
+	}
+
+	server := httptest.NewServer(newActiveQueryHandler(svc))
+	defer server.Close()
+	client := &ActiveQueryService{Addr: server.URL}
+
+	qs, err := client.FindActiveQueries(ctx, query.ActiveQueryFilter{OrganizationID: &q.OrganizationID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(qs, []*query.ActiveQuery{q}); diff != "" {
+		t.Errorf("queries are different -got/+want\ndiff %s", diff)
+	}
+
+	if err := client.CancelActiveQuery(ctx, q.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.FindActiveQueryByID(ctx, q.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	if err := client.CancelActiveQuery(ctx, q.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/influxdata/influxdb/kit/prom"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/export"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
@@ -33,6 +34,12 @@ type APIHandler struct {
 	TelegrafHandler      *TelegrafHandler
 	QueryHandler         *FluxHandler
 	WriteHandler         *WriteHandler
+	ExportHandler        *ExportHandler
+	ReplicationHandler   *ReplicationHandler
+	RoleHandler          *RoleHandler
+	ActiveQueryHandler   *ActiveQueryHandler
+	AuditLogHandler      *AuditLogHandler
+	QueryJobHandler      *QueryJobHandler
 	DocumentHandler      *DocumentHandler
 	SetupHandler         *SetupHandler
 	SessionHandler       *SessionHandler
@@ -54,8 +61,11 @@ type APIBackend struct {
 	QueryEventRecorder metric.EventRecorder
 
 	PointsWriter                    storage.PointsWriter
+	Exporter                        *export.Exporter
 	AuthorizationService            influxdb.AuthorizationService
 	BucketService                   influxdb.BucketService
+	BucketSchemaService             influxdb.BucketSchemaService
+	StorageUsageService             influxdb.StorageUsageService
 	SessionService                  influxdb.SessionService
 	UserService                     influxdb.UserService
 	OrganizationService             influxdb.OrganizationService
@@ -68,11 +78,18 @@ type APIBackend struct {
 	OrganizationOperationLogService influxdb.OrganizationOperationLogService
 	SourceService                   influxdb.SourceService
 	VariableService                 influxdb.VariableService
+	ReplicationService              influxdb.ReplicationService
+	RoleService                     influxdb.RoleService
 	PasswordsService                influxdb.PasswordsService
+	SigninLockoutService            influxdb.SigninLockoutService
 	OnboardingService               influxdb.OnboardingService
 	InfluxQLService                 query.ProxyQueryService
 	FluxService                     query.ProxyQueryService
+	ActiveQueryService              query.ActiveQueryService
+	AuditLogService                 influxdb.AuditLogService
+	QueryJobService                 query.JobService
 	TaskService                     influxdb.TaskService
+	BackfillService                 influxdb.BackfillService
 	TelegrafService                 influxdb.TelegrafConfigStore
 	ScraperTargetStoreService       influxdb.ScraperTargetStoreService
 	SecretService                   influxdb.SecretService
@@ -94,6 +111,10 @@ func (b *APIBackend) PrometheusCollectors() []prometheus.Collector {
 		cs = append(cs, pc.PrometheusCollectors()...)
 	}
 
+	if pc, ok := b.SigninLockoutService.(prom.PrometheusCollector); ok {
+		cs = append(cs, pc.PrometheusCollectors()...)
+	}
+
 	return cs
 }
 
@@ -104,7 +125,7 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 	}
 
 	internalURM := b.UserResourceMappingService
-	b.UserResourceMappingService = authorizer.NewURMService(b.OrgLookupService, b.UserResourceMappingService)
+	b.UserResourceMappingService = authorizer.NewURMService(b.OrgLookupService, b.UserResourceMappingService, b.RoleService)
 
 	documentBackend := NewDocumentBackend(b)
 	h.DocumentHandler = NewDocumentHandler(documentBackend)
@@ -114,14 +135,26 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 
 	bucketBackend := NewBucketBackend(b)
 	bucketBackend.BucketService = authorizer.NewBucketService(b.BucketService)
+	if b.BucketSchemaService != nil {
+		bucketBackend.BucketSchemaService = authorizer.NewBucketSchemaService(b.BucketSchemaService, b.BucketService)
+	}
+	if b.StorageUsageService != nil {
+		bucketBackend.StorageUsageService = authorizer.NewStorageUsageService(b.StorageUsageService)
+	}
 	h.BucketHandler = NewBucketHandler(bucketBackend)
 
 	orgBackend := NewOrgBackend(b)
 	orgBackend.OrganizationService = authorizer.NewOrgService(b.OrganizationService)
+	if b.StorageUsageService != nil {
+		orgBackend.StorageUsageService = authorizer.NewStorageUsageService(b.StorageUsageService)
+	}
 	h.OrgHandler = NewOrgHandler(orgBackend)
 
 	userBackend := NewUserBackend(b)
 	userBackend.UserService = authorizer.NewUserService(b.UserService)
+	if b.SigninLockoutService != nil {
+		userBackend.SigninLockoutService = authorizer.NewSigninLockoutService(b.SigninLockoutService, b.UserService)
+	}
 	h.UserHandler = NewUserHandler(userBackend)
 
 	dashboardBackend := NewDashboardBackend(b)
@@ -133,7 +166,9 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 	h.VariableHandler = NewVariableHandler(variableBackend)
 
 	authorizationBackend := NewAuthorizationBackend(b)
-	authorizationBackend.AuthorizationService = authorizer.NewAuthorizationService(b.AuthorizationService)
+	authorizationService := authorizer.NewAuthorizationService(b.AuthorizationService, b.RoleService)
+	authorizationBackend.AuthorizationService = authorizationService
+	authorizationBackend.AuthorizationDeriveService = authorizationService
 	h.AuthorizationHandler = NewAuthorizationHandler(authorizationBackend)
 
 	scraperBackend := NewScraperBackend(b)
@@ -161,7 +196,32 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 	writeBackend := NewWriteBackend(b)
 	h.WriteHandler = NewWriteHandler(writeBackend)
 
+	exportBackend := NewExportBackend(b)
+	h.ExportHandler = NewExportHandler(exportBackend)
+
+	replicationBackend := NewReplicationBackend(b)
+	replicationBackend.ReplicationService = authorizer.NewReplicationService(b.ReplicationService)
+	h.ReplicationHandler = NewReplicationHandler(replicationBackend)
+
+	roleBackend := NewRoleBackend(b)
+	roleBackend.RoleService = authorizer.NewRoleService(b.RoleService)
+	h.RoleHandler = NewRoleHandler(roleBackend)
+
+	activeQueryBackend := NewActiveQueryBackend(b)
+	activeQueryBackend.ActiveQueryService = authorizer.NewActiveQueryService(b.ActiveQueryService)
+	h.ActiveQueryHandler = NewActiveQueryHandler(activeQueryBackend)
+
+	queryJobBackend := NewQueryJobBackend(b)
+	queryJobBackend.QueryJobService = authorizer.NewQueryJobService(b.QueryJobService)
+	queryJobBackend.VariableService = authorizer.NewVariableService(b.VariableService)
+	h.QueryJobHandler = NewQueryJobHandler(queryJobBackend)
+
+	auditLogBackend := NewAuditLogBackend(b)
+	auditLogBackend.AuditLogService = authorizer.NewAuditLogService(b.AuditLogService)
+	h.AuditLogHandler = NewAuditLogHandler(auditLogBackend)
+
 	fluxBackend := NewFluxBackend(b)
+	fluxBackend.VariableService = authorizer.NewVariableService(b.VariableService)
 	h.QueryHandler = NewFluxHandler(fluxBackend)
 
 	h.ChronografHandler = NewChronografHandler(b.ChronografService, b.HTTPErrorHandler)
@@ -174,9 +234,11 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 var apiLinks = map[string]interface{}{
 	// when adding new links, please take care to keep this list alphabetical
 	// as this makes it easier to verify values against the swagger document.
+	"audit":          "/api/v2/audit",
 	"authorizations": "/api/v2/authorizations",
 	"buckets":        "/api/v2/buckets",
 	"dashboards":     "/api/v2/dashboards",
+	"export":         "/api/v2/export",
 	"external": map[string]string{
 		"statusFeed": "https://www.influxdata.com/feed/json",
 	},
@@ -190,12 +252,15 @@ var apiLinks = map[string]interface{}{
 		"analyze":     "/api/v2/query/analyze",
 		"suggestions": "/api/v2/query/suggestions",
 	},
-	"setup":    "/api/v2/setup",
-	"signin":   "/api/v2/signin",
-	"signout":  "/api/v2/signout",
-	"sources":  "/api/v2/sources",
-	"scrapers": "/api/v2/scrapers",
-	"swagger":  "/api/v2/swagger.json",
+	"queries":      "/api/v2/queries",
+	"replications": "/api/v2/replications",
+	"roles":        "/api/v2/roles",
+	"setup":        "/api/v2/setup",
+	"signin":       "/api/v2/signin",
+	"signout":      "/api/v2/signout",
+	"sources":      "/api/v2/sources",
+	"scrapers":     "/api/v2/scrapers",
+	"swagger":      "/api/v2/swagger.json",
 	"system": map[string]string{
 		"metrics": "/metrics",
 		"debug":   "/debug/pprof",
@@ -242,6 +307,11 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if strings.HasPrefix(r.URL.Path, "/api/v2/export") {
+		h.ExportHandler.ServeHTTP(w, r)
+		return
+	}
+
 	if strings.HasPrefix(r.URL.Path, "/api/v2/query") {
 		h.QueryHandler.ServeHTTP(w, r)
 		return
@@ -307,6 +377,33 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Query jobs are routed before running queries, whose IDs would
+	// otherwise conflict with the jobs path.
+	if strings.HasPrefix(r.URL.Path, "/api/v2/queries/jobs") {
+		h.QueryJobHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if r.URL.Path == "/api/v2/audit" {
+		h.AuditLogHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/queries") {
+		h.ActiveQueryHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/replications") {
+		h.ReplicationHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/roles") {
+		h.RoleHandler.ServeHTTP(w, r)
+		return
+	}
+
 	if strings.HasPrefix(r.URL.Path, "/api/v2/documents") {
 		h.DocumentHandler.ServeHTTP(w, r)
 		return
@@ -0,0 +1,261 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	auditPath = "/api/v2/audit"
+)
+
+// AuditLogBackend is all services and associated parameters required to construct
+// the AuditLogHandler.
+type AuditLogBackend struct {
+	influxdb.HTTPErrorHandler
+	Logger              *zap.Logger
+	AuditLogService     influxdb.AuditLogService
+	OrganizationService influxdb.OrganizationService
+}
+
+// NewAuditLogBackend creates a backend used by the audit log handler.
+func NewAuditLogBackend(b *APIBackend) *AuditLogBackend {
+	return &AuditLogBackend{
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		Logger:              b.Logger.With(zap.String("handler", "audit")),
+		AuditLogService:     b.AuditLogService,
+		OrganizationService: b.OrganizationService,
+	}
+}
+
+// AuditLogHandler is the handler for the audit log of changes to resources.
+type AuditLogHandler struct {
+	*httprouter.Router
+
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	AuditLogService     influxdb.AuditLogService
+	OrganizationService influxdb.OrganizationService
+}
+
+// NewAuditLogHandler creates a new AuditLogHandler.
+func NewAuditLogHandler(b *AuditLogBackend) *AuditLogHandler {
+	h := &AuditLogHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Logger:           b.Logger,
+
+		AuditLogService:     b.AuditLogService,
+		OrganizationService: b.OrganizationService,
+	}
+
+	h.HandlerFunc("GET", auditPath, h.handleGetAuditLog)
+
+	return h
+}
+
+type auditLogEntryResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.AuditLogEntry
+}
+
+func newAuditLogEntryResponse(e *influxdb.AuditLogEntry) *auditLogEntryResponse {
+	links := map[string]string{}
+	if e.OrgID.Valid() {
+		links["org"] = fmt.Sprintf("/api/v2/orgs/%s", e.OrgID)
+	}
+	if e.UserID.Valid() {
+		links["user"] = fmt.Sprintf("/api/v2/users/%s", e.UserID)
+	}
+	return &auditLogEntryResponse{
+		Links:         links,
+		AuditLogEntry: e,
+	}
+}
+
+type auditLogResponse struct {
+	Links   *influxdb.PagingLinks    `json:"links"`
+	Entries []*auditLogEntryResponse `json:"entries"`
+}
+
+func newAuditLogResponse(opts influxdb.FindOptions, f influxdb.AuditLogFilter, es []*influxdb.AuditLogEntry) *auditLogResponse {
+	resp := &auditLogResponse{
+		Links:   newPagingLinks(auditPath, opts, f, len(es)),
+		Entries: make([]*auditLogEntryResponse, 0, len(es)),
+	}
+	for _, e := range es {
+		resp.Entries = append(resp.Entries, newAuditLogEntryResponse(e))
+	}
+	return resp
+}
+
+type getAuditLogRequest struct {
+	filter influxdb.AuditLogFilter
+	opts   influxdb.FindOptions
+}
+
+func (h *AuditLogHandler) decodeGetAuditLogRequest(ctx context.Context, r *http.Request) (*getAuditLogRequest, error) {
+	qp := r.URL.Query()
+	req := &getAuditLogRequest{}
+
+	opts, err := decodeFindOptions(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	req.opts = *opts
+	// The newest entries come first, unless asked otherwise.
+	if qp.Get("descending") == "" {
+		req.opts.Descending = true
+	}
+
+	if orgID := qp.Get("orgID"); orgID != "" {
+		id, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			return nil, err
+		}
+		req.filter.OrgID = id
+	} else if org := qp.Get("org"); org != "" {
+		o, err := h.OrganizationService.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &org})
+		if err != nil {
+			return nil, err
+		}
+		req.filter.OrgID = &o.ID
+	}
+
+	if rt := qp.Get("resourceType"); rt != "" {
+		t := influxdb.ResourceType(rt)
+		if err := t.Valid(); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("unknown resource type %q", rt),
+			}
+		}
+		req.filter.ResourceType = &t
+	}
+
+	if resourceID := qp.Get("resourceID"); resourceID != "" {
+		id, err := influxdb.IDFromString(resourceID)
+		if err != nil {
+			return nil, err
+		}
+		req.filter.ResourceID = id
+	}
+
+	if userID := qp.Get("userID"); userID != "" {
+		id, err := influxdb.IDFromString(userID)
+		if err != nil {
+			return nil, err
+		}
+		req.filter.UserID = id
+	}
+
+	for k, dst := range map[string]**time.Time{"since": &req.filter.Since, "until": &req.filter.Until} {
+		v := qp.Get(k)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("%s must be an RFC3339 time", k),
+				Err:  err,
+			}
+		}
+		*dst = &t
+	}
+
+	return req, nil
+}
+
+// handleGetAuditLog is the HTTP handler for the GET /api/v2/audit route.
+func (h *AuditLogHandler) handleGetAuditLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := h.decodeGetAuditLogRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	es, _, err := h.AuditLogService.FindAuditLogEntries(ctx, req.filter, req.opts)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newAuditLogResponse(req.opts, req.filter, es)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// AuditLogService connects to Influx via HTTP using tokens to retrieve the audit log.
+type AuditLogService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ influxdb.AuditLogService = (*AuditLogService)(nil)
+
+// FindAuditLogEntries returns the entries of the audit log that match filter.
+func (s *AuditLogService) FindAuditLogEntries(ctx context.Context, filter influxdb.AuditLogFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditLogEntry, int, error) {
+	u, err := NewURL(s.Addr, auditPath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	qp := u.Query()
+	for k, vs := range filter.QueryParams() {
+		for _, v := range vs {
+			qp.Add(k, v)
+		}
+	}
+	opts := influxdb.DefaultAuditLogFindOptions
+	if len(opt) > 0 {
+		opts = opt[0]
+	}
+	for k, vs := range opts.QueryParams() {
+		for _, v := range vs {
+			qp.Add(k, v)
+		}
+	}
+	u.RawQuery = qp.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, 0, err
+	}
+
+	var res auditLogResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, 0, err
+	}
+
+	es := make([]*influxdb.AuditLogEntry, 0, len(res.Entries))
+	for _, e := range res.Entries {
+		es = append(es, e.AuditLogEntry)
+	}
+	return es, len(es), nil
+}
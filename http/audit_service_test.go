@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap"
+)
+
+func TestAuditLogHandler_handleGetAuditLog(t *testing.T) {
+	svc := mock.NewAuditLogService()
+	svc.FindAuditLogEntriesFn = func(ctx context.Context, f influxdb.AuditLogFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditLogEntry, int, error) {
+		if f.OrgID == nil || *f.OrgID != 0x1 {
+			t.Errorf("unexpected org filter %+v", f.OrgID)
+		}
+		if f.ResourceType == nil || *f.ResourceType != influxdb.BucketsResourceType {
+			t.Errorf("unexpected resource type filter %+v", f.ResourceType)
+		}
+		if f.Since == nil || !f.Since.Equal(time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("unexpected since filter %+v", f.Since)
+		}
+		if len(opt) != 1 || !opt[0].Descending || opt[0].Limit != 2 {
+			t.Errorf("unexpected find options %+v", opt)
+		}
+		es := []*influxdb.AuditLogEntry{
+			{
+				ID:             0x10,
+				Time:           time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC),
+				OrgID:          0x1,
+				ResourceType:   influxdb.BucketsResourceType,
+				ResourceID:     0x3,
+				Action:         influxdb.AuditUpdate,
+				UserID:         0x2,
+				AuthorizerKind: influxdb.AuthorizationKind,
+				AuthorizerID:   0x4,
+				RemoteAddr:     "10.0.0.1:5000",
+				Before:         json.RawMessage(`{"name":"b1"}`),
+				After:          json.RawMessage(`{"name":"b2"}`),
+			},
+		}
+		return es, len(es), nil
+	}
+
+	h := NewAuditLogHandler(&AuditLogBackend{
+		HTTPErrorHandler:    ErrorHandler(0),
+		Logger:              zap.NewNop(),
+		AuditLogService:     svc,
+		OrganizationService: mock.NewOrganizationService(),
+	})
+
+	r := httptest.NewRequest("GET", "http://any.url/api/v2/audit?orgID=0000000000000001&resourceType=buckets&since=2019-05-01T00:00:00Z&limit=2", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, res.StatusCode, body)
+	}
+
+	want := `
+{
+  "links": {
+    "self": "/api/v2/audit?descending=true&limit=2&offset=0&orgID=0000000000000001&resourceType=buckets&since=2019-05-01T00%3A00%3A00Z"
+  },
+  "entries": [
+    {
+      "links": {
+        "org": "/api/v2/orgs/0000000000000001",
+        "user": "/api/v2/users/0000000000000002"
+      },
+      "id": "0000000000000010",
+      "time": "2019-05-01T12:00:00Z",
+      "orgID": "0000000000000001",
+      "resourceType": "buckets",
+      "resourceID": "0000000000000003",
+      "action": "update",
+      "userID": "0000000000000002",
+      "authorizerKind": "authorization",
+      "authorizerID": "0000000000000004",
+      "remoteAddr": "10.0.0.1:5000",
+      "before": {"name": "b1"},
+      "after": {"name": "b2"}
+    }
+  ]
+}
+`
+	if eq, diff, err := jsonEqual(string(body), want); err != nil || !eq {
+		t.Errorf("unexpected audit log: %v\n%s", err, diff)
+	}
+
+	r = httptest.NewRequest("GET", "http://any.url/api/v2/audit?resourceType=nope", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for unknown resource type, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	platform "github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+var _ platform.AuthorizationDeriveService = (*AuthorizationService)(nil)
+
+// handlePostDeriveAuthorization is the HTTP handler for the POST /api/v2/authorizations/derive route.
+func (h *AuthorizationHandler) handlePostDeriveAuthorization(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	h.Logger.Debug("derive auth request", zap.String("r", fmt.Sprint(r)))
+
+	req, err := decodePostDeriveAuthorizationRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	auth, err := h.AuthorizationDeriveService.DeriveAuthorization(ctx, req)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	org, err := h.OrganizationService.FindOrganizationByID(ctx, auth.OrgID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	user, err := h.UserService.FindUserByID(ctx, auth.UserID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	perms, err := newPermissionsResponse(ctx, auth.Permissions, h.LookupService)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	h.Logger.Debug("auth derived", zap.String("auth", fmt.Sprint(auth)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newAuthResponse(auth, org, user, perms)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodePostDeriveAuthorizationRequest(ctx context.Context, r *http.Request) (*platform.AuthorizationDerive, error) {
+	d := &platform.AuthorizationDerive{}
+	if err := json.NewDecoder(r.Body).Decode(d); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid json structure",
+			Err:  err,
+		}
+	}
+
+	return d, nil
+}
+
+// DeriveAuthorization creates an authorization with a subset of the permissions of the token of s.
+func (s *AuthorizationService) DeriveAuthorization(ctx context.Context, d *platform.AuthorizationDerive) (*platform.Authorization, error) {
+	u, err := NewURL(s.Addr, authorizationDerivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	octets, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var res authResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+
+	return res.toPlatform(), nil
+}
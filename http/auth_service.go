@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"path"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -22,10 +23,11 @@ type AuthorizationBackend struct {
 	platform.HTTPErrorHandler
 	Logger *zap.Logger
 
-	AuthorizationService platform.AuthorizationService
-	OrganizationService  platform.OrganizationService
-	UserService          platform.UserService
-	LookupService        platform.LookupService
+	AuthorizationService       platform.AuthorizationService
+	AuthorizationDeriveService platform.AuthorizationDeriveService
+	OrganizationService        platform.OrganizationService
+	UserService                platform.UserService
+	LookupService              platform.LookupService
 }
 
 // NewAuthorizationBackend returns a new instance of AuthorizationBackend.
@@ -47,10 +49,11 @@ type AuthorizationHandler struct {
 	platform.HTTPErrorHandler
 	Logger *zap.Logger
 
-	OrganizationService  platform.OrganizationService
-	UserService          platform.UserService
-	AuthorizationService platform.AuthorizationService
-	LookupService        platform.LookupService
+	OrganizationService        platform.OrganizationService
+	UserService                platform.UserService
+	AuthorizationService       platform.AuthorizationService
+	AuthorizationDeriveService platform.AuthorizationDeriveService
+	LookupService              platform.LookupService
 }
 
 // NewAuthorizationHandler returns a new instance of AuthorizationHandler.
@@ -60,13 +63,15 @@ func NewAuthorizationHandler(b *AuthorizationBackend) *AuthorizationHandler {
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		Logger:           b.Logger,
 
-		AuthorizationService: b.AuthorizationService,
-		OrganizationService:  b.OrganizationService,
-		UserService:          b.UserService,
-		LookupService:        b.LookupService,
+		AuthorizationService:       b.AuthorizationService,
+		AuthorizationDeriveService: b.AuthorizationDeriveService,
+		OrganizationService:        b.OrganizationService,
+		UserService:                b.UserService,
+		LookupService:              b.LookupService,
 	}
 
 	h.HandlerFunc("POST", "/api/v2/authorizations", h.handlePostAuthorization)
+	h.HandlerFunc("POST", authorizationDerivePath, h.handlePostDeriveAuthorization)
 	h.HandlerFunc("GET", "/api/v2/authorizations", h.handleGetAuthorizations)
 	h.HandlerFunc("GET", "/api/v2/authorizations/:id", h.handleGetAuthorization)
 	h.HandlerFunc("PATCH", "/api/v2/authorizations/:id", h.handleUpdateAuthorization)
@@ -84,6 +89,10 @@ type authResponse struct {
 	UserID      platform.ID          `json:"userID"`
 	User        string               `json:"user"`
 	Permissions []permissionResponse `json:"permissions"`
+	RoleIDs     []platform.ID        `json:"roleIDs,omitempty"`
+	ExpiresAt   *time.Time           `json:"expiresAt,omitempty"`
+	LastUsedAt  *time.Time           `json:"lastUsedAt,omitempty"`
+	ParentID    *platform.ID         `json:"parentID,omitempty"`
 	Links       map[string]string    `json:"links"`
 }
 
@@ -98,11 +107,19 @@ func newAuthResponse(a *platform.Authorization, org *platform.Organization, user
 		User:        user.Name,
 		Org:         org.Name,
 		Permissions: ps,
+		RoleIDs:     a.RoleIDs,
+		ExpiresAt:   a.ExpiresAt,
+		LastUsedAt:  a.LastUsedAt,
 		Links: map[string]string{
 			"self": fmt.Sprintf("/api/v2/authorizations/%s", a.ID),
 			"user": fmt.Sprintf("/api/v2/users/%s", a.UserID),
 		},
 	}
+	if a.ParentID.Valid() {
+		parentID := a.ParentID
+		res.ParentID = &parentID
+		res.Links["parent"] = fmt.Sprintf("/api/v2/authorizations/%s", parentID)
+	}
 	return res
 }
 
@@ -114,16 +131,23 @@ func (a *authResponse) toPlatform() *platform.Authorization {
 		Description: a.Description,
 		OrgID:       a.OrgID,
 		UserID:      a.UserID,
+		RoleIDs:     a.RoleIDs,
+		ExpiresAt:   a.ExpiresAt,
+		LastUsedAt:  a.LastUsedAt,
+	}
+	if a.ParentID != nil {
+		res.ParentID = *a.ParentID
 	}
 	for _, p := range a.Permissions {
-		res.Permissions = append(res.Permissions, platform.Permission{Action: p.Action, Resource: p.Resource.Resource})
+		res.Permissions = append(res.Permissions, platform.Permission{Action: p.Action, Resource: p.Resource.Resource, Restriction: p.Restriction})
 	}
 	return res
 }
 
 type permissionResponse struct {
-	Action   platform.Action  `json:"action"`
-	Resource resourceResponse `json:"resource"`
+	Action      platform.Action       `json:"action"`
+	Resource    resourceResponse      `json:"resource"`
+	Restriction *platform.Restriction `json:"restriction,omitempty"`
 }
 
 type resourceResponse struct {
@@ -140,6 +164,7 @@ func newPermissionsResponse(ctx context.Context, ps []platform.Permission, svc p
 			Resource: resourceResponse{
 				Resource: p.Resource,
 			},
+			Restriction: p.Restriction,
 		}
 
 		if p.Resource.ID != nil {
@@ -238,6 +263,8 @@ type postAuthorizationRequest struct {
 	UserID      *platform.ID          `json:"userID,omitempty"`
 	Description string                `json:"description"`
 	Permissions []platform.Permission `json:"permissions"`
+	RoleIDs     []platform.ID         `json:"roleIDs,omitempty"`
+	ExpiresAt   *time.Time            `json:"expiresAt,omitempty"`
 }
 
 func (p *postAuthorizationRequest) toPlatform(userID platform.ID) *platform.Authorization {
@@ -246,7 +273,9 @@ func (p *postAuthorizationRequest) toPlatform(userID platform.ID) *platform.Auth
 		Status:      p.Status,
 		Description: p.Description,
 		Permissions: p.Permissions,
+		RoleIDs:     p.RoleIDs,
 		UserID:      userID,
+		ExpiresAt:   p.ExpiresAt,
 	}
 }
 
@@ -255,7 +284,9 @@ func newPostAuthorizationRequest(a *platform.Authorization) (*postAuthorizationR
 		OrgID:       a.OrgID,
 		Description: a.Description,
 		Permissions: a.Permissions,
+		RoleIDs:     a.RoleIDs,
 		Status:      a.Status,
+		ExpiresAt:   a.ExpiresAt,
 	}
 
 	if a.UserID.Valid() {
@@ -274,10 +305,10 @@ func (p *postAuthorizationRequest) SetDefaults() {
 }
 
 func (p *postAuthorizationRequest) Validate() error {
-	if len(p.Permissions) == 0 {
+	if len(p.Permissions) == 0 && len(p.RoleIDs) == 0 {
 		return &platform.Error{
 			Code: platform.EInvalid,
-			Msg:  "authorization must include permissions",
+			Msg:  "authorization must include permissions or roles",
 		}
 	}
 
@@ -297,6 +328,13 @@ func (p *postAuthorizationRequest) Validate() error {
 		}
 	}
 
+	if p.ExpiresAt != nil && !p.ExpiresAt.After(time.Now()) {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "expiration must be in the future",
+		}
+	}
+
 	if p.Status == "" {
 		p.Status = platform.Active
 	}
@@ -565,6 +603,9 @@ func decodeUpdateAuthorizationRequest(ctx context.Context, r *http.Request) (*up
 	if err := json.NewDecoder(r.Body).Decode(upd); err != nil {
 		return nil, err
 	}
+	if err := upd.Valid(); err != nil {
+		return nil, err
+	}
 
 	return &updateAuthorizationRequest{
 		ID:                  i,
@@ -740,7 +781,8 @@ func (s *AuthorizationService) FindAuthorizations(ctx context.Context, filter pl
 }
 
 const (
-	authorizationPath = "/api/v2/authorizations"
+	authorizationPath       = "/api/v2/authorizations"
+	authorizationDerivePath = "/api/v2/authorizations/derive"
 )
 
 // CreateAuthorization creates a new authorization and sets b.ID with the new identifier.
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
 	platcontext "github.com/influxdata/influxdb/context"
 	"github.com/julienschmidt/httprouter"
 	"go.uber.org/zap"
@@ -21,6 +22,12 @@ type AuthenticationHandler struct {
 	SessionService       platform.SessionService
 	SessionRenewDisabled bool
 
+	// LastUsedDisabled disables recording when authorizations were last used.
+	LastUsedDisabled bool
+
+	// RoleResolver, if set, grants authorizations and sessions the permissions of their roles.
+	RoleResolver *authorizer.RoleResolver
+
 	// This is only really used for it's lookup method the specific http
 	// handler used to register routes does not matter.
 	noAuthRouter *httprouter.Router
@@ -67,6 +74,9 @@ func ProbeAuthScheme(r *http.Request) (string, error) {
 
 // ServeHTTP extracts the session or token from the http request and places the resulting authorizer on the request context.
 func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The client address is recorded in the audit log of any change made by the request.
+	r = r.WithContext(platcontext.SetRemoteAddr(r.Context(), r.RemoteAddr))
+
 	if handler, _, _ := h.noAuthRouter.Lookup(r.Method, r.URL.Path); handler != nil {
 		h.Handler.ServeHTTP(w, r)
 		return
@@ -107,14 +117,45 @@ func (h *AuthenticationHandler) extractAuthorization(ctx context.Context, r *htt
 		return ctx, err
 	}
 
-	a, err := h.AuthorizationService.FindAuthorizationByToken(ctx, t)
+	// The roles of the authorization are resolved by setAuthorizer, as for sessions.
+	a, err := authorizer.NewTokenAuthenticator(h.AuthorizationService, nil).Authenticate(ctx, t)
 	if err != nil {
 		return ctx, err
 	}
 
+	if !h.LastUsedDisabled {
+		h.recordLastUsed(ctx, a)
+	}
+
+	return h.setAuthorizer(ctx, a)
+}
+
+// setAuthorizer places a on the context, with the permissions of its roles.
+func (h *AuthenticationHandler) setAuthorizer(ctx context.Context, a platform.Authorizer) (context.Context, error) {
+	if h.RoleResolver != nil {
+		resolved, err := h.RoleResolver.Resolve(ctx, a)
+		if err != nil {
+			return ctx, err
+		}
+		a = resolved
+	}
 	return platcontext.SetAuthorizer(ctx, a), nil
 }
 
+// recordLastUsed updates the time a was last used, if it is older than platform.LastUsedAtPrecision.
+// Failing to record it does not fail the request.
+func (h *AuthenticationHandler) recordLastUsed(ctx context.Context, a *platform.Authorization) {
+	now := time.Now().UTC()
+	if a.LastUsedAt != nil && now.Sub(*a.LastUsedAt) < platform.LastUsedAtPrecision {
+		return
+	}
+
+	a.LastUsedAt = &now
+	if _, err := h.AuthorizationService.UpdateAuthorization(ctx, a.ID, &platform.AuthorizationUpdate{LastUsedAt: &now}); err != nil {
+		h.Logger.Info("Failed to record authorization use", zap.String("authorization_id", a.ID.String()), zap.Error(err))
+	}
+}
+
 func (h *AuthenticationHandler) extractSession(ctx context.Context, r *http.Request) (context.Context, error) {
 	k, err := decodeCookieSession(ctx, r)
 	if err != nil {
@@ -134,5 +175,5 @@ func (h *AuthenticationHandler) extractSession(ctx context.Context, r *http.Requ
 		}
 	}
 
-	return platcontext.SetAuthorizer(ctx, s), nil
+	return h.setAuthorizer(ctx, s)
 }
@@ -76,6 +76,9 @@ func TestAuthenticationHandler(t *testing.T) {
 					FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
 						return &platform.Authorization{}, nil
 					},
+					UpdateAuthorizationFn: func(ctx context.Context, id platform.ID, upd *platform.AuthorizationUpdate) (*platform.Authorization, error) {
+						return &platform.Authorization{}, nil
+					},
 				},
 				SessionService: mock.NewSessionService(),
 			},
@@ -103,6 +106,68 @@ func TestAuthenticationHandler(t *testing.T) {
 				code: http.StatusUnauthorized,
 			},
 		},
+		{
+			name: "token expired",
+			fields: fields{
+				AuthorizationService: &mock.AuthorizationService{
+					FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+						expiresAt := time.Now().Add(-time.Minute)
+						return &platform.Authorization{Status: platform.Active, ExpiresAt: &expiresAt}, nil
+					},
+				},
+				SessionService: mock.NewSessionService(),
+			},
+			args: args{
+				token: "abc123",
+			},
+			wants: wants{
+				code: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "token derived from inactive token",
+			fields: fields{
+				AuthorizationService: &mock.AuthorizationService{
+					FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+						return &platform.Authorization{ID: 2, Status: platform.Active, ParentID: 1}, nil
+					},
+					FindAuthorizationByIDFn: func(ctx context.Context, id platform.ID) (*platform.Authorization, error) {
+						return &platform.Authorization{ID: 1, Status: platform.Inactive}, nil
+					},
+				},
+				SessionService: mock.NewSessionService(),
+			},
+			args: args{
+				token: "abc123",
+			},
+			wants: wants{
+				code: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "token derived from active token",
+			fields: fields{
+				AuthorizationService: &mock.AuthorizationService{
+					FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+						return &platform.Authorization{ID: 2, Status: platform.Active, ParentID: 1}, nil
+					},
+					FindAuthorizationByIDFn: func(ctx context.Context, id platform.ID) (*platform.Authorization, error) {
+						expiresAt := time.Now().Add(time.Hour)
+						return &platform.Authorization{ID: 1, Status: platform.Active, ExpiresAt: &expiresAt}, nil
+					},
+					UpdateAuthorizationFn: func(ctx context.Context, id platform.ID, upd *platform.AuthorizationUpdate) (*platform.Authorization, error) {
+						return &platform.Authorization{}, nil
+					},
+				},
+				SessionService: mock.NewSessionService(),
+			},
+			args: args{
+				token: "abc123",
+			},
+			wants: wants{
+				code: http.StatusOK,
+			},
+		},
 		{
 			name: "no auth provided",
 			fields: fields{
@@ -147,6 +212,45 @@ func TestAuthenticationHandler(t *testing.T) {
 	}
 }
 
+func TestAuthenticationHandler_LastUsed(t *testing.T) {
+	var updates []*platform.AuthorizationUpdate
+	lastUsedAt := time.Now().Add(-time.Hour)
+	auth := &platform.Authorization{ID: 1, Status: platform.Active, LastUsedAt: &lastUsedAt}
+
+	h := platformhttp.NewAuthenticationHandler(platformhttp.ErrorHandler(0))
+	h.AuthorizationService = &mock.AuthorizationService{
+		FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+			return auth, nil
+		},
+		UpdateAuthorizationFn: func(ctx context.Context, id platform.ID, upd *platform.AuthorizationUpdate) (*platform.Authorization, error) {
+			updates = append(updates, upd)
+			return auth, nil
+		},
+	}
+	h.SessionService = mock.NewSessionService()
+	h.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// The second request is within the precision of the last use recorded by the first.
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "http://any.url", nil)
+		platformhttp.SetToken("abc123", r)
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status code to be %d got %d", http.StatusOK, w.Code)
+		}
+	}
+
+	if len(updates) != 1 {
+		t.Fatalf("expected last use to be recorded once, got %d updates", len(updates))
+	}
+	if upd := updates[0]; upd.LastUsedAt == nil || !upd.LastUsedAt.After(lastUsedAt) {
+		t.Fatalf("expected last use to be recorded, got %+v", upd)
+	}
+}
+
 func TestProbeAuthScheme(t *testing.T) {
 	type args struct {
 		token   string
@@ -0,0 +1,332 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	bucketsIDSchemaPath = "/api/v2/buckets/:id/schema"
+
+	// schemaTagType is the type of tag keys in the CSV form of bucket schemas.
+	schemaTagType = "tag"
+)
+
+// schemaCSVHeader is the header of the CSV form of bucket schemas.
+// Each row is a tag key or a field of a measurement.
+var schemaCSVHeader = []string{"measurement", "key", "type"}
+
+// handleGetBucketSchema is the HTTP handler for the GET /api/v2/buckets/:id/schema route.
+// The schema is encoded as CSV if requested by the Accept header, and as JSON otherwise.
+func (h *BucketHandler) handleGetBucketSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetBucketRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	sch, err := h.BucketSchemaService.FindBucketSchema(ctx, req.BucketID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Accept")); mt == "text/csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if err := encodeBucketSchemaCSV(w, sch); err != nil {
+			logEncodingError(h.Logger, r, err)
+		}
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, sch); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePutBucketSchema is the HTTP handler for the PUT /api/v2/buckets/:id/schema route.
+// The schema is decoded from CSV if the Content-Type is text/csv, and from JSON otherwise.
+func (h *BucketHandler) handlePutBucketSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodePutBucketSchemaRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.BucketSchemaService.PutBucketSchema(ctx, req.BucketID, req.Schema); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("bucket schema updated", zap.String("bucketID", req.BucketID.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, req.Schema); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+type putBucketSchemaRequest struct {
+	BucketID influxdb.ID
+	Schema   *influxdb.BucketSchema
+}
+
+func decodePutBucketSchemaRequest(ctx context.Context, r *http.Request) (*putBucketSchemaRequest, error) {
+	req, err := decodeGetBucketRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var sch *influxdb.BucketSchema
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "text/csv" {
+		sch, err = decodeBucketSchemaCSV(r.Body)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		sch = &influxdb.BucketSchema{}
+		if err := json.NewDecoder(r.Body).Decode(sch); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid json schema",
+				Err:  err,
+			}
+		}
+	}
+	if sch.Measurements == nil {
+		sch.Measurements = []influxdb.MeasurementSchema{}
+	}
+
+	return &putBucketSchemaRequest{
+		BucketID: req.BucketID,
+		Schema:   sch,
+	}, nil
+}
+
+// handleDeleteBucketSchema is the HTTP handler for the DELETE /api/v2/buckets/:id/schema route.
+func (h *BucketHandler) handleDeleteBucketSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetBucketRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.BucketSchemaService.DeleteBucketSchema(ctx, req.BucketID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("bucket schema deleted", zap.String("bucketID", req.BucketID.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeBucketSchemaCSV decodes a schema from CSV with the header
+// measurement,key,type, where type is "tag" for tag keys and the type of
+// the values of fields otherwise.
+func decodeBucketSchemaCSV(r io.Reader) (*influxdb.BucketSchema, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(schemaCSVHeader)
+	cr.TrimLeadingSpace = true
+
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid csv schema",
+			Err:  err,
+		}
+	}
+	if len(rows) == 0 || !equalStrings(rows[0], schemaCSVHeader) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("csv schema must start with the header %q", schemaCSVHeader),
+		}
+	}
+
+	sch := &influxdb.BucketSchema{Measurements: []influxdb.MeasurementSchema{}}
+	index := make(map[string]int)
+	for i, row := range rows[1:] {
+		name, key, typ := row[0], row[1], row[2]
+		j, ok := index[name]
+		if !ok {
+			j = len(sch.Measurements)
+			index[name] = j
+			sch.Measurements = append(sch.Measurements, influxdb.MeasurementSchema{
+				Name:   name,
+				Tags:   []string{},
+				Fields: []influxdb.FieldSchema{},
+			})
+		}
+		m := &sch.Measurements[j]
+
+		if typ == schemaTagType {
+			m.Tags = append(m.Tags, key)
+			continue
+		}
+		if err := influxdb.FieldType(typ).Valid(); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("csv schema row %d has type %q; expected %q or a field type", i+2, typ, schemaTagType),
+			}
+		}
+		m.Fields = append(m.Fields, influxdb.FieldSchema{Name: key, Type: influxdb.FieldType(typ)})
+	}
+	return sch, nil
+}
+
+// encodeBucketSchemaCSV encodes a schema in the form read by decodeBucketSchemaCSV.
+func encodeBucketSchemaCSV(w io.Writer, sch *influxdb.BucketSchema) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(schemaCSVHeader); err != nil {
+		return err
+	}
+	for _, m := range sch.Measurements {
+		for _, t := range m.Tags {
+			if err := cw.Write([]string{m.Name, t, schemaTagType}); err != nil {
+				return err
+			}
+		}
+		for _, f := range m.Fields {
+			if err := cw.Write([]string{m.Name, f.Name, string(f.Type)}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// BucketSchemaService connects to Influx via HTTP using tokens to manage bucket schemas.
+type BucketSchemaService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ influxdb.BucketSchemaService = (*BucketSchemaService)(nil)
+
+// FindBucketSchema returns the schema of a bucket.
+func (s *BucketSchemaService) FindBucketSchema(ctx context.Context, bucketID influxdb.ID) (*influxdb.BucketSchema, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := NewURL(s.Addr, bucketSchemaPath(bucketID))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var sch influxdb.BucketSchema
+	if err := json.NewDecoder(resp.Body).Decode(&sch); err != nil {
+		return nil, err
+	}
+	return &sch, nil
+}
+
+// PutBucketSchema replaces the schema of a bucket.
+func (s *BucketSchemaService) PutBucketSchema(ctx context.Context, bucketID influxdb.ID, sch *influxdb.BucketSchema) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := NewURL(s.Addr, bucketSchemaPath(bucketID))
+	if err != nil {
+		return err
+	}
+
+	octets, err := json.Marshal(sch)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PUT", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+// DeleteBucketSchema removes the schema of a bucket.
+func (s *BucketSchemaService) DeleteBucketSchema(ctx context.Context, bucketID influxdb.ID) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := NewURL(s.Addr, bucketSchemaPath(bucketID))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+func bucketSchemaPath(id influxdb.ID) string {
+	return path.Join(bucketsPath, id.String(), "schema")
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestService_handlePutBucketSchema(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		statusCode  int
+		schema      *platform.BucketSchema
+	}{
+		{
+			name:        "csv schema",
+			contentType: "text/csv",
+			body:        "measurement,key,type\ncpu,host,tag\ncpu,usage,float\nmem,free,integer\ncpu,region,tag\n",
+			statusCode:  http.StatusOK,
+			schema: &platform.BucketSchema{
+				Measurements: []platform.MeasurementSchema{
+					{
+						Name:   "cpu",
+						Tags:   []string{"host", "region"},
+						Fields: []platform.FieldSchema{{Name: "usage", Type: platform.FieldTypeFloat}},
+					},
+					{
+						Name:   "mem",
+						Tags:   []string{},
+						Fields: []platform.FieldSchema{{Name: "free", Type: platform.FieldTypeInteger}},
+					},
+				},
+			},
+		},
+		{
+			name:        "json schema",
+			contentType: "application/json",
+			body:        `{"measurements":[{"name":"cpu","tags":["host"],"fields":[{"name":"usage","type":"float"}]}]}`,
+			statusCode:  http.StatusOK,
+			schema: &platform.BucketSchema{
+				Measurements: []platform.MeasurementSchema{
+					{
+						Name:   "cpu",
+						Tags:   []string{"host"},
+						Fields: []platform.FieldSchema{{Name: "usage", Type: platform.FieldTypeFloat}},
+					},
+				},
+			},
+		},
+		{
+			name:        "csv schema without header",
+			contentType: "text/csv",
+			body:        "cpu,host,tag\n",
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "csv schema with unknown type",
+			contentType: "text/csv",
+			body:        "measurement,key,type\ncpu,usage,double\n",
+			statusCode:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *platform.BucketSchema
+			schemas := mock.NewBucketSchemaService()
+			schemas.PutBucketSchemaFn = func(ctx context.Context, id platform.ID, s *platform.BucketSchema) error {
+				got = s
+				return nil
+			}
+
+			bucketBackend := NewMockBucketBackend()
+			bucketBackend.HTTPErrorHandler = ErrorHandler(0)
+			bucketBackend.BucketSchemaService = schemas
+			h := NewBucketHandler(bucketBackend)
+
+			r := httptest.NewRequest("PUT", "http://any.url", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			r = r.WithContext(context.WithValue(
+				context.Background(),
+				httprouter.ParamsKey,
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "020f755c3c082000",
+					},
+				}))
+
+			w := httptest.NewRecorder()
+
+			h.handlePutBucketSchema(w, r)
+
+			res := w.Result()
+			if res.StatusCode != tt.statusCode {
+				body, _ := ioutil.ReadAll(res.Body)
+				t.Fatalf("handlePutBucketSchema() = %v, want %v: %s", res.StatusCode, tt.statusCode, body)
+			}
+			if !reflect.DeepEqual(got, tt.schema) {
+				t.Errorf("handlePutBucketSchema() put %+v, want %+v", got, tt.schema)
+			}
+		})
+	}
+}
+
+func TestService_handleGetBucketSchema_CSV(t *testing.T) {
+	schemas := mock.NewBucketSchemaService()
+	schemas.FindBucketSchemaFn = func(ctx context.Context, id platform.ID) (*platform.BucketSchema, error) {
+		return &platform.BucketSchema{
+			Measurements: []platform.MeasurementSchema{
+				{
+					Name:   "cpu",
+					Tags:   []string{"host"},
+					Fields: []platform.FieldSchema{{Name: "usage", Type: platform.FieldTypeFloat}},
+				},
+			},
+		}, nil
+	}
+
+	bucketBackend := NewMockBucketBackend()
+	bucketBackend.HTTPErrorHandler = ErrorHandler(0)
+	bucketBackend.BucketSchemaService = schemas
+	h := NewBucketHandler(bucketBackend)
+
+	r := httptest.NewRequest("GET", "http://any.url", nil)
+	r.Header.Set("Accept", "text/csv")
+	r = r.WithContext(context.WithValue(
+		context.Background(),
+		httprouter.ParamsKey,
+		httprouter.Params{
+			{
+				Key:   "id",
+				Value: "020f755c3c082000",
+			},
+		}))
+
+	w := httptest.NewRecorder()
+
+	h.handleGetBucketSchema(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("handleGetBucketSchema() = %v, want %v: %s", res.StatusCode, http.StatusOK, body)
+	}
+	if got, want := res.Header.Get("Content-Type"), "text/csv; charset=utf-8"; got != want {
+		t.Errorf("handleGetBucketSchema() content type = %v, want %v", got, want)
+	}
+	if got, want := string(body), "measurement,key,type\ncpu,host,tag\ncpu,usage,float\n"; got != want {
+		t.Errorf("handleGetBucketSchema() = %q, want %q", got, want)
+	}
+}
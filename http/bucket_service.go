@@ -24,6 +24,8 @@ type BucketBackend struct {
 
 	BucketService              influxdb.BucketService
 	BucketOperationLogService  influxdb.BucketOperationLogService
+	BucketSchemaService        influxdb.BucketSchemaService
+	StorageUsageService        influxdb.StorageUsageService
 	UserResourceMappingService influxdb.UserResourceMappingService
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
@@ -38,6 +40,8 @@ func NewBucketBackend(b *APIBackend) *BucketBackend {
 
 		BucketService:              b.BucketService,
 		BucketOperationLogService:  b.BucketOperationLogService,
+		BucketSchemaService:        b.BucketSchemaService,
+		StorageUsageService:        b.StorageUsageService,
 		UserResourceMappingService: b.UserResourceMappingService,
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
@@ -53,6 +57,8 @@ type BucketHandler struct {
 
 	BucketService              influxdb.BucketService
 	BucketOperationLogService  influxdb.BucketOperationLogService
+	BucketSchemaService        influxdb.BucketSchemaService
+	StorageUsageService        influxdb.StorageUsageService
 	UserResourceMappingService influxdb.UserResourceMappingService
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
@@ -80,6 +86,8 @@ func NewBucketHandler(b *BucketBackend) *BucketHandler {
 
 		BucketService:              b.BucketService,
 		BucketOperationLogService:  b.BucketOperationLogService,
+		BucketSchemaService:        b.BucketSchemaService,
+		StorageUsageService:        b.StorageUsageService,
 		UserResourceMappingService: b.UserResourceMappingService,
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
@@ -93,6 +101,16 @@ func NewBucketHandler(b *BucketBackend) *BucketHandler {
 	h.HandlerFunc("PATCH", bucketsIDPath, h.handlePatchBucket)
 	h.HandlerFunc("DELETE", bucketsIDPath, h.handleDeleteBucket)
 
+	if b.BucketSchemaService != nil {
+		h.HandlerFunc("GET", bucketsIDSchemaPath, h.handleGetBucketSchema)
+		h.HandlerFunc("PUT", bucketsIDSchemaPath, h.handlePutBucketSchema)
+		h.HandlerFunc("DELETE", bucketsIDSchemaPath, h.handleDeleteBucketSchema)
+	}
+
+	if b.StorageUsageService != nil {
+		h.HandlerFunc("GET", bucketsIDUsagePath, h.handleGetBucketUsage)
+	}
+
 	memberBackend := MemberBackend{
 		HTTPErrorHandler:           b.HTTPErrorHandler,
 		Logger:                     b.Logger.With(zap.String("handler", "member")),
@@ -132,12 +150,15 @@ func NewBucketHandler(b *BucketBackend) *BucketHandler {
 
 // bucket is used for serialization/deserialization with duration string syntax.
 type bucket struct {
-	ID                  influxdb.ID     `json:"id,omitempty"`
-	OrgID               influxdb.ID     `json:"orgID,omitempty"`
-	Description         string          `json:"description,omitempty"`
-	Name                string          `json:"name"`
-	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
-	RetentionRules      []retentionRule `json:"retentionRules"`
+	ID                  influxdb.ID            `json:"id,omitempty"`
+	OrgID               influxdb.ID            `json:"orgID,omitempty"`
+	Description         string                 `json:"description,omitempty"`
+	Name                string                 `json:"name"`
+	RetentionPolicyName string                 `json:"rp,omitempty"` // This to support v1 sources
+	RetentionRules      []retentionRule        `json:"retentionRules"`
+	SchemaType          string                 `json:"schemaType,omitempty"`
+	Schema              *influxdb.BucketSchema `json:"schema,omitempty"`
+	StorageQuotaBytes   int64                  `json:"storageQuotaBytes,omitempty"`
 	influxdb.CRUDLog
 }
 
@@ -172,6 +193,8 @@ func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 		Name:                b.Name,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     d,
+		Schema:              b.Schema,
+		StorageQuotaBytes:   b.StorageQuotaBytes,
 		CRUDLog:             b.CRUDLog,
 	}, nil
 }
@@ -197,15 +220,19 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 		Description:         pb.Description,
 		RetentionPolicyName: pb.RetentionPolicyName,
 		RetentionRules:      rules,
+		SchemaType:          string(pb.SchemaType()),
+		Schema:              pb.Schema,
+		StorageQuotaBytes:   pb.StorageQuotaBytes,
 		CRUDLog:             pb.CRUDLog,
 	}
 }
 
 // bucketUpdate is used for serialization/deserialization with retention rules.
 type bucketUpdate struct {
-	Name           *string         `json:"name,omitempty"`
-	Description    *string         `json:"description,omitempty"`
-	RetentionRules []retentionRule `json:"retentionRules,omitempty"`
+	Name              *string         `json:"name,omitempty"`
+	Description       *string         `json:"description,omitempty"`
+	RetentionRules    []retentionRule `json:"retentionRules,omitempty"`
+	StorageQuotaBytes *int64          `json:"storageQuotaBytes,omitempty"`
 }
 
 func (b *bucketUpdate) toInfluxDB() (*influxdb.BucketUpdate, error) {
@@ -226,9 +253,10 @@ func (b *bucketUpdate) toInfluxDB() (*influxdb.BucketUpdate, error) {
 	}
 
 	return &influxdb.BucketUpdate{
-		Name:            b.Name,
-		Description:     b.Description,
-		RetentionPeriod: &d,
+		Name:              b.Name,
+		Description:       b.Description,
+		RetentionPeriod:   &d,
+		StorageQuotaBytes: b.StorageQuotaBytes,
 	}, nil
 }
 
@@ -238,9 +266,10 @@ func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
 	}
 
 	up := &bucketUpdate{
-		Name:           pb.Name,
-		Description:    pb.Description,
-		RetentionRules: []retentionRule{},
+		Name:              pb.Name,
+		Description:       pb.Description,
+		RetentionRules:    []retentionRule{},
+		StorageQuotaBytes: pb.StorageQuotaBytes,
 	}
 
 	if pb.RetentionPeriod != nil {
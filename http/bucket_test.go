@@ -120,6 +120,7 @@ func TestService_handleGetBuckets(t *testing.T) {
       "orgID": "50f7ba1150f7ba11",
       "name": "hello",
       "retentionRules": [{"type": "expire", "everySeconds": 2}],
+      "schemaType": "implicit",
 			"labels": [
         {
           "id": "fc3dc670a4be9b9a",
@@ -146,6 +147,7 @@ func TestService_handleGetBuckets(t *testing.T) {
       "orgID": "7e55e118dbabb1ed",
       "name": "example",
       "retentionRules": [{"type": "expire", "everySeconds": 86400}],
+      "schemaType": "implicit",
       "labels": [
         {
           "id": "fc3dc670a4be9b9a",
@@ -288,6 +290,7 @@ func TestService_handleGetBucket(t *testing.T) {
 		  "orgID": "020f755c3c082000",
 		  "name": "hello",
 		  "retentionRules": [{"type": "expire", "everySeconds": 30}],
+		  "schemaType": "implicit",
       "labels": []
 		}
 		`,
@@ -420,6 +423,7 @@ func TestService_handlePostBucket(t *testing.T) {
   "orgID": "6f626f7274697320",
   "name": "hello",
   "retentionRules": [],
+  "schemaType": "implicit",
   "labels": []
 }
 `,
@@ -642,6 +646,7 @@ func TestService_handlePatchBucket(t *testing.T) {
   "orgID": "020f755c3c082000",
   "name": "example",
   "retentionRules": [{"type": "expire", "everySeconds": 2}],
+  "schemaType": "implicit",
   "labels": []
 }
 `,
@@ -720,6 +725,7 @@ func TestService_handlePatchBucket(t *testing.T) {
   "orgID": "020f755c3c082000",
   "name": "bucket with no retention",
   "retentionRules": [],
+  "schemaType": "implicit",
   "labels": []
 }
 `,
@@ -779,6 +785,7 @@ func TestService_handlePatchBucket(t *testing.T) {
   "orgID": "020f755c3c082000",
   "name": "b1",
   "retentionRules": [],
+  "schemaType": "implicit",
   "labels": []
 }
 `,
@@ -0,0 +1,256 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/flux/iocounter"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage/export"
+	"github.com/influxdata/influxdb/storage/reads"
+)
+
+// ExportBackend is all services and associated parameters required to construct
+// the ExportHandler.
+type ExportBackend struct {
+	platform.HTTPErrorHandler
+	Logger *zap.Logger
+
+	Exporter            *export.Exporter
+	BucketService       platform.BucketService
+	OrganizationService platform.OrganizationService
+}
+
+// NewExportBackend returns a new instance of ExportBackend.
+func NewExportBackend(b *APIBackend) *ExportBackend {
+	return &ExportBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Logger:           b.Logger.With(zap.String("handler", "export")),
+
+		Exporter:            b.Exporter,
+		BucketService:       b.BucketService,
+		OrganizationService: b.OrganizationService,
+	}
+}
+
+// ExportHandler streams the contents of a bucket as line protocol, annotated
+// CSV or Parquet.
+type ExportHandler struct {
+	*httprouter.Router
+	platform.HTTPErrorHandler
+	Logger *zap.Logger
+
+	Exporter            *export.Exporter
+	BucketService       platform.BucketService
+	OrganizationService platform.OrganizationService
+}
+
+const (
+	exportPath = "/api/v2/export"
+)
+
+// NewExportHandler creates a new handler at /api/v2/export to export bucket data.
+func NewExportHandler(b *ExportBackend) *ExportHandler {
+	h := &ExportHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Logger:           b.Logger,
+
+		Exporter:            b.Exporter,
+		BucketService:       b.BucketService,
+		OrganizationService: b.OrganizationService,
+	}
+
+	h.HandlerFunc("POST", exportPath, h.handlePostExport)
+	return h
+}
+
+// exportRequest is the body of an export request.
+type exportRequest struct {
+	Org         string     `json:"org"`
+	Bucket      string     `json:"bucket"`
+	Start       *time.Time `json:"start,omitempty"`
+	Stop        *time.Time `json:"stop,omitempty"`
+	Measurement string     `json:"measurement,omitempty"`
+	Predicate   string     `json:"predicate,omitempty"`
+	Format      string     `json:"format,omitempty"`
+}
+
+func (h *ExportHandler) handlePostExport(w http.ResponseWriter, r *http.Request) {
+	const op = "http/handlePostExport"
+	span, r := tracing.ExtractFromHTTPRequest(r, "ExportHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	req, err := decodeExportRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	format, err := export.ParseFormat(req.Format)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	org, bucket, err := h.findBucket(ctx, req.Org, req.Bucket)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	p, err := platform.NewPermissionAtID(bucket.ID, platform.ReadAction, platform.BucketsResourceType, org.ID)
+	if err != nil {
+		h.HandleHTTPError(ctx, &platform.Error{
+			Code: platform.EInternal,
+			Op:   op,
+			Msg:  fmt.Sprintf("unable to create permission for bucket: %v", err),
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if !a.Allowed(*p) {
+		h.HandleHTTPError(ctx, &platform.Error{
+			Code: platform.EForbidden,
+			Op:   op,
+			Msg:  "insufficient permissions for export",
+		}, w)
+		return
+	}
+
+	filter := export.Filter{
+		OrgID:       org.ID,
+		BucketID:    bucket.ID,
+		Start:       models.MinNanoTime,
+		End:         models.MaxNanoTime,
+		Measurement: req.Measurement,
+	}
+	if req.Start != nil {
+		filter.Start = req.Start.UnixNano()
+	}
+	if req.Stop != nil {
+		filter.End = req.Stop.UnixNano()
+	}
+	if req.Predicate != "" {
+		filter.Predicate, err = reads.ParsePredicate(req.Predicate)
+		if err != nil {
+			h.HandleHTTPError(ctx, &platform.Error{
+				Code: platform.EInvalid,
+				Op:   op,
+				Msg:  "invalid predicate",
+				Err:  err,
+			}, w)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+
+	cw := iocounter.Writer{Writer: w}
+	stats, err := h.Exporter.Export(ctx, &cw, format, filter)
+	if err != nil {
+		if cw.Count() == 0 {
+			// Only record the error headers IFF nothing has been written to w.
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		h.Logger.Info("Error writing export to client", zap.Error(err))
+		return
+	}
+
+	h.Logger.Debug("Export complete",
+		zap.String("org_id", org.ID.String()),
+		zap.String("bucket_id", bucket.ID.String()),
+		zap.Int("series", stats.Series),
+		zap.Int("values", stats.Values))
+}
+
+func decodeExportRequest(ctx context.Context, r *http.Request) (*exportRequest, error) {
+	req := &exportRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Op:   "http/decodeExportRequest",
+			Msg:  "invalid json",
+			Err:  err,
+		}
+	}
+
+	if req.Org == "" || req.Bucket == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Op:   "http/decodeExportRequest",
+			Msg:  "org and bucket are required",
+		}
+	}
+
+	if req.Start != nil && req.Stop != nil && !req.Start.Before(*req.Stop) {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Op:   "http/decodeExportRequest",
+			Msg:  "start must be before stop",
+		}
+	}
+
+	return req, nil
+}
+
+// findBucket finds the organization and bucket referenced by the request.
+// Both may be referenced by ID or by name.
+func (h *ExportHandler) findBucket(ctx context.Context, orgRef, bucketRef string) (*platform.Organization, *platform.Bucket, error) {
+	var org *platform.Organization
+	if id, err := platform.IDFromString(orgRef); err == nil {
+		o, err := h.OrganizationService.FindOrganizationByID(ctx, *id)
+		if err == nil {
+			org = o
+		} else if platform.ErrorCode(err) != platform.ENotFound {
+			return nil, nil, err
+		}
+	}
+	if org == nil {
+		o, err := h.OrganizationService.FindOrganization(ctx, platform.OrganizationFilter{Name: &orgRef})
+		if err != nil {
+			return nil, nil, err
+		}
+		org = o
+	}
+
+	if id, err := platform.IDFromString(bucketRef); err == nil {
+		b, err := h.BucketService.FindBucket(ctx, platform.BucketFilter{
+			OrganizationID: &org.ID,
+			ID:             id,
+		})
+		if err == nil {
+			return org, b, nil
+		} else if platform.ErrorCode(err) != platform.ENotFound {
+			return nil, nil, err
+		}
+	}
+
+	b, err := h.BucketService.FindBucket(ctx, platform.BucketFilter{
+		OrganizationID: &org.ID,
+		Name:           &bucketRef,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return org, b, nil
+}
@@ -0,0 +1,160 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/export"
+	"github.com/influxdata/influxdb/storage/readservice"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+func TestExportHandler_handlePostExport(t *testing.T) {
+	const (
+		orgID    = platform.ID(0x3131313131313131)
+		bucketID = platform.ID(0x3232323232323232)
+	)
+
+	path, err := ioutil.TempDir("", "export_handler_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	engine := storage.NewEngine(path, storage.NewConfig())
+	if err := engine.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Close()
+
+	name := tsdb.EncodeName(orgID, bucketID)
+	points, err := models.ParsePointsWithPrecision([]byte("cpu,host=a usage=1.5 10\nmem,host=a free=4i 10"), models.EscapeMeasurement(name[:]), time.Now(), "ns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.WritePoints(context.Background(), points); err != nil {
+		t.Fatal(err)
+	}
+
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationByIDF = func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+		if id != orgID {
+			return nil, &platform.Error{Code: platform.ENotFound, Msg: "organization not found"}
+		}
+		return &platform.Organization{ID: orgID, Name: "o1"}, nil
+	}
+	orgs.FindOrganizationF = func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+		if filter.Name == nil || *filter.Name != "o1" {
+			return nil, &platform.Error{Code: platform.ENotFound, Msg: "organization not found"}
+		}
+		return &platform.Organization{ID: orgID, Name: "o1"}, nil
+	}
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+		if (filter.ID != nil && *filter.ID == bucketID) || (filter.Name != nil && *filter.Name == "b1") {
+			return &platform.Bucket{ID: bucketID, OrgID: orgID, Name: "b1"}, nil
+		}
+		return nil, &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"}
+	}
+
+	readBucket, err := platform.NewPermissionAtID(bucketID, platform.ReadAction, platform.BucketsResourceType, orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		body        string
+		authorizer  platform.Authorizer
+		status      int
+		contentType string
+		want        string
+	}{
+		{
+			name:        "line protocol by name",
+			body:        `{"org":"o1","bucket":"b1"}`,
+			authorizer:  &platform.Authorization{Status: platform.Active, Permissions: []platform.Permission{*readBucket}},
+			status:      http.StatusOK,
+			contentType: export.LineProtocol.ContentType(),
+			want:        "cpu,host=a usage=1.5 10\nmem,host=a free=4i 10\n",
+		},
+		{
+			name:        "measurement by ID",
+			body:        `{"org":"3131313131313131","bucket":"3232323232323232","measurement":"mem"}`,
+			authorizer:  &platform.Authorization{Status: platform.Active, Permissions: []platform.Permission{*readBucket}},
+			status:      http.StatusOK,
+			contentType: export.LineProtocol.ContentType(),
+			want:        "mem,host=a free=4i 10\n",
+		},
+		{
+			name:       "without read permission",
+			body:       `{"org":"o1","bucket":"b1"}`,
+			authorizer: &platform.Authorization{Status: platform.Active},
+			status:     http.StatusForbidden,
+		},
+		{
+			name:       "missing bucket",
+			body:       `{"org":"o1"}`,
+			authorizer: &platform.Authorization{Status: platform.Active, Permissions: []platform.Permission{*readBucket}},
+			status:     http.StatusBadRequest,
+		},
+		{
+			name:       "unknown bucket",
+			body:       `{"org":"o1","bucket":"b2"}`,
+			authorizer: &platform.Authorization{Status: platform.Active, Permissions: []platform.Permission{*readBucket}},
+			status:     http.StatusNotFound,
+		},
+		{
+			name:       "unknown format",
+			body:       `{"org":"o1","bucket":"b1","format":"xml"}`,
+			authorizer: &platform.Authorization{Status: platform.Active, Permissions: []platform.Permission{*readBucket}},
+			status:     http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewExportHandler(&ExportBackend{
+				HTTPErrorHandler:    ErrorHandler(0),
+				Logger:              zap.NewNop(),
+				Exporter:            export.NewExporter(readservice.NewStore(engine)),
+				BucketService:       buckets,
+				OrganizationService: orgs,
+			})
+
+			r := httptest.NewRequest("POST", "http://any.url/api/v2/export", bytes.NewBufferString(tt.body))
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), tt.authorizer))
+			w := httptest.NewRecorder()
+
+			h.handlePostExport(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.status {
+				t.Fatalf("handlePostExport() = %v, want %v: %s", res.StatusCode, tt.status, body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if got := res.Header.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("handlePostExport() content type = %v, want %v", got, tt.contentType)
+			}
+			if got := string(body); got != tt.want {
+				t.Errorf("handlePostExport() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
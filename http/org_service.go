@@ -27,6 +27,7 @@ type OrgBackend struct {
 	SecretService                   influxdb.SecretService
 	LabelService                    influxdb.LabelService
 	UserService                     influxdb.UserService
+	StorageUsageService             influxdb.StorageUsageService
 }
 
 // NewOrgBackend is a datasource used by the org handler.
@@ -41,6 +42,7 @@ func NewOrgBackend(b *APIBackend) *OrgBackend {
 		SecretService:                   b.SecretService,
 		LabelService:                    b.LabelService,
 		UserService:                     b.UserService,
+		StorageUsageService:             b.StorageUsageService,
 	}
 }
 
@@ -56,6 +58,7 @@ type OrgHandler struct {
 	SecretService                   influxdb.SecretService
 	LabelService                    influxdb.LabelService
 	UserService                     influxdb.UserService
+	StorageUsageService             influxdb.StorageUsageService
 }
 
 const (
@@ -86,6 +89,7 @@ func NewOrgHandler(b *OrgBackend) *OrgHandler {
 		SecretService:                   b.SecretService,
 		LabelService:                    b.LabelService,
 		UserService:                     b.UserService,
+		StorageUsageService:             b.StorageUsageService,
 	}
 
 	h.HandlerFunc("POST", organizationsPath, h.handlePostOrg)
@@ -95,6 +99,10 @@ func NewOrgHandler(b *OrgBackend) *OrgHandler {
 	h.HandlerFunc("PATCH", organizationsIDPath, h.handlePatchOrg)
 	h.HandlerFunc("DELETE", organizationsIDPath, h.handleDeleteOrg)
 
+	if b.StorageUsageService != nil {
+		h.HandlerFunc("GET", organizationsIDUsagePath, h.handleGetOrgUsage)
+	}
+
 	memberBackend := MemberBackend{
 		HTTPErrorHandler:           b.HTTPErrorHandler,
 		Logger:                     b.Logger.With(zap.String("handler", "member")),
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/influxdata/influxdb/authorizer"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -25,6 +26,11 @@ func setCORSResponseHeaders(w http.ResponseWriter, r *http.Request) {
 // NewPlatformHandler returns a platform handler that serves the API and associated assets.
 func NewPlatformHandler(b *APIBackend) *PlatformHandler {
 	h := NewAuthenticationHandler(b.HTTPErrorHandler)
+	if b.RoleService != nil {
+		// roles are resolved before the authorizer is on the context,
+		// so the services are used without authorization.
+		h.RoleResolver = authorizer.NewRoleResolver(b.RoleService, b.UserResourceMappingService)
+	}
 	h.Handler = NewAPIHandler(b)
 	h.AuthorizationService = b.AuthorizationService
 	h.SessionService = b.SessionService
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 	"unicode/utf8"
 
@@ -22,6 +23,8 @@ import (
 	"github.com/influxdata/flux/repl"
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/encoding"
+	"github.com/influxdata/influxdb/query/explain"
 	"github.com/influxdata/influxql"
 )
 
@@ -33,12 +36,24 @@ type QueryRequest struct {
 	Query   string       `json:"query"`
 	Type    string       `json:"type"`
 	Dialect QueryDialect `json:"dialect"`
+	// Priority is the priority class the query is executed with.
+	Priority query.Priority `json:"priority,omitempty"`
+	// Profile requests a profile of the execution of the query to be
+	// returned as an extra result.
+	Profile bool `json:"profile,omitempty"`
+	// Params are the parameters of the query, available to it as the
+	// params record.
+	Params QueryParams `json:"params,omitempty"`
 
 	Org *influxdb.Organization `json:"-"`
 }
 
 // QueryDialect is the formatting options for the query response.
 type QueryDialect struct {
+	// Format is the format of the response, one of the QueryFormat
+	// constants, and defaults to CSV. The other options only apply to the
+	// CSV format.
+	Format         string   `json:"format,omitempty"`
 	Header         *bool    `json:"header"`
 	Delimiter      string   `json:"delimiter"`
 	CommentPrefix  string   `json:"commentPrefix"`
@@ -46,6 +61,48 @@ type QueryDialect struct {
 	Annotations    []string `json:"annotations"`
 }
 
+// Formats of query responses.
+const (
+	QueryFormatCSV          = "csv"
+	QueryFormatJSON         = encoding.JSONDialectType
+	QueryFormatArrow        = encoding.ArrowDialectType
+	QueryFormatLineProtocol = encoding.LineProtocolDialectType
+)
+
+// queryFormats maps the formats of query responses to their content types.
+var queryFormats = map[string]string{
+	QueryFormatCSV:          "text/csv",
+	QueryFormatJSON:         encoding.JSONContentType,
+	QueryFormatArrow:        encoding.ArrowContentType,
+	QueryFormatLineProtocol: encoding.LineProtocolContentType,
+}
+
+// queryFormatFromAccept returns the format of the results requested by an
+// Accept header, or the empty string for the default of annotated CSV.
+// Clients such as browsers send generic Accept headers like
+// "application/json, text/plain, */*" with every request, so only media types
+// no client sends by default select a format; CSV is kept whenever it is
+// acceptable. Other formats are requested with the format of the dialect.
+func queryFormatFromAccept(accept string) string {
+	var format string
+	for _, v := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(v)
+		if err != nil {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q <= 0 {
+			continue
+		}
+		switch mt {
+		case "*/*", "text/*", "text/csv":
+			return ""
+		case encoding.ArrowContentType:
+			format = QueryFormatArrow
+		}
+	}
+	return format
+}
+
 // WithDefaults adds default values to the request.
 func (r QueryRequest) WithDefaults() QueryRequest {
 	if r.Type == "" {
@@ -79,10 +136,28 @@ func (r QueryRequest) Validate() error {
 		}
 	}
 
+	if r.Spec != nil && len(r.Params) > 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "request body cannot specify both a spec and parameters",
+		}
+	}
+
+	if err := r.Params.Validate(); err != nil {
+		return err
+	}
+
 	if r.Type != "flux" {
 		return fmt.Errorf(`unknown query type: %s`, r.Type)
 	}
 
+	if _, ok := queryFormats[r.Dialect.Format]; !ok && r.Dialect.Format != "" {
+		return fmt.Errorf(`unknown dialect format: %s`, r.Dialect.Format)
+	}
+	if f := r.Dialect.Format; f != "" && f != QueryFormatCSV {
+		return nil
+	}
+
 	if len(r.Dialect.CommentPrefix) > 1 {
 		return fmt.Errorf("invalid dialect comment prefix: must be length 0 or 1")
 	}
@@ -215,6 +290,27 @@ func columnFromCharacter(q string, char int) int {
 
 var influxqlParseErrorRE = regexp.MustCompile(`^(.+) at line (\d+), char (\d+)$`)
 
+// Explain plans the query of the request and returns its logical and
+// physical plans.
+func (r QueryRequest) Explain(ctx context.Context, now func() time.Time) (*explain.Explanation, error) {
+	if err := r.Validate(); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid query request",
+			Err:  err,
+		}
+	}
+	compiler, err := r.compiler(now)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid query request",
+			Err:  err,
+		}
+	}
+	return explain.Explain(ctx, compiler)
+}
+
 // ProxyRequest returns a request to proxy from the flux.
 func (r QueryRequest) ProxyRequest() (*query.ProxyRequest, error) {
 	return r.proxyRequest(time.Now)
@@ -224,12 +320,64 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 	if err := r.Validate(); err != nil {
 		return nil, err
 	}
+	compiler, err := r.compiler(now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &query.ProxyRequest{
+		Request: query.Request{
+			OrganizationID: r.Org.ID,
+			Compiler:       compiler,
+			Priority:       r.Priority,
+			Profile:        r.Profile,
+		},
+		Dialect: r.Dialect.fluxDialect(),
+	}, nil
+}
+
+// fluxDialect returns the dialect that encodes results in the format of d.
+func (d QueryDialect) fluxDialect() flux.Dialect {
+	switch d.Format {
+	case QueryFormatJSON:
+		return new(encoding.JSONDialect)
+	case QueryFormatArrow:
+		return new(encoding.ArrowDialect)
+	case QueryFormatLineProtocol:
+		return new(encoding.LineProtocolDialect)
+	default:
+		delimiter, _ := utf8.DecodeRuneInString(d.Delimiter)
+
+		noHeader := false
+		if d.Header != nil {
+			noHeader = !*d.Header
+		}
+
+		// TODO(nathanielc): Use commentPrefix and dateTimeFormat
+		// once they are supported.
+		return &csv.Dialect{
+			ResultEncoderConfig: csv.ResultEncoderConfig{
+				NoHeader:    noHeader,
+				Delimiter:   delimiter,
+				Annotations: d.Annotations,
+			},
+		}
+	}
+}
+
+// compiler returns the compiler of the query of the request.
+func (r QueryRequest) compiler(now func() time.Time) (flux.Compiler, error) {
+	extern, err := r.extern()
+	if err != nil {
+		return nil, err
+	}
+
 	// Query is preferred over AST
 	var compiler flux.Compiler
 	if r.Query != "" {
 		compiler = lang.FluxCompiler{
 			Now:    now(),
-			Extern: r.Extern,
+			Extern: extern,
 			Query:  r.Query,
 		}
 	} else if r.AST != nil {
@@ -237,8 +385,8 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 			AST: r.AST,
 			Now: now(),
 		}
-		if r.Extern != nil {
-			c.PrependFile(r.Extern)
+		if extern != nil {
+			c.PrependFile(extern)
 		}
 		compiler = c
 	} else if r.Spec != nil {
@@ -246,35 +394,34 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 			Spec: r.Spec,
 		}
 	}
+	return compiler, nil
+}
 
-	delimiter, _ := utf8.DecodeRuneInString(r.Dialect.Delimiter)
-
-	noHeader := false
-	if r.Dialect.Header != nil {
-		noHeader = !*r.Dialect.Header
+// extern returns the external declarations of the request, followed by the
+// assignment of its parameters, if any.
+func (r QueryRequest) extern() (*ast.File, error) {
+	if len(r.Params) == 0 {
+		return r.Extern, nil
 	}
 
-	// TODO(nathanielc): Use commentPrefix and dateTimeFormat
-	// once they are supported.
-	return &query.ProxyRequest{
-		Request: query.Request{
-			OrganizationID: r.Org.ID,
-			Compiler:       compiler,
-		},
-		Dialect: &csv.Dialect{
-			ResultEncoderConfig: csv.ResultEncoderConfig{
-				NoHeader:    noHeader,
-				Delimiter:   delimiter,
-				Annotations: r.Dialect.Annotations,
-			},
-		},
-	}, nil
+	params, err := r.Params.File()
+	if err != nil {
+		return nil, err
+	}
+	if r.Extern == nil {
+		return params, nil
+	}
+	extern := r.Extern.Copy().(*ast.File)
+	extern.Body = append(extern.Body, params.Body...)
+	return extern, nil
 }
 
 // QueryRequestFromProxyRequest converts a query.ProxyRequest into a QueryRequest.
 // The ProxyRequest must contain supported compilers and dialects otherwise an error occurs.
 func QueryRequestFromProxyRequest(req *query.ProxyRequest) (*QueryRequest, error) {
 	qr := new(QueryRequest)
+	qr.Priority = req.Request.Priority
+	qr.Profile = req.Request.Profile
 	switch c := req.Request.Compiler.(type) {
 	case lang.FluxCompiler:
 		qr.Type = "flux"
@@ -297,6 +444,12 @@ func QueryRequestFromProxyRequest(req *query.ProxyRequest) (*QueryRequest, error
 		qr.Dialect.CommentPrefix = "#"
 		qr.Dialect.DateTimeFormat = "RFC3339"
 		qr.Dialect.Annotations = d.ResultEncoderConfig.Annotations
+	case *encoding.JSONDialect:
+		qr.Dialect.Format = QueryFormatJSON
+	case *encoding.ArrowDialect:
+		qr.Dialect.Format = QueryFormatArrow
+	case *encoding.LineProtocolDialect:
+		qr.Dialect.Format = QueryFormatLineProtocol
 	default:
 		return nil, fmt.Errorf("unsupported dialect %T", d)
 	}
@@ -331,6 +484,11 @@ func decodeQueryRequest(ctx context.Context, r *http.Request, svc influxdb.Organ
 		}
 	}
 
+	// The format of the dialect takes precedence over the Accept header.
+	if req.Dialect.Format == "" {
+		req.Dialect.Format = queryFormatFromAccept(r.Header.Get("Accept"))
+	}
+
 	req = req.WithDefaults()
 	if err := req.Validate(); err != nil {
 		return nil, body.bytesRead, err
@@ -351,12 +509,18 @@ func (r *countReader) Read(p []byte) (n int, err error) {
 	return n, err
 }
 
-func decodeProxyQueryRequest(ctx context.Context, r *http.Request, auth influxdb.Authorizer, svc influxdb.OrganizationService) (*query.ProxyRequest, int, error) {
+// decodeProxyQueryRequest decodes a query request and expands its variable
+// parameters with vs, which may be nil if they are not supported.
+func decodeProxyQueryRequest(ctx context.Context, r *http.Request, auth influxdb.Authorizer, svc influxdb.OrganizationService, vs influxdb.VariableService) (*query.ProxyRequest, int, error) {
 	req, n, err := decodeQueryRequest(ctx, r, svc)
 	if err != nil {
 		return nil, n, err
 	}
 
+	if err := expandVariableParams(ctx, vs, req.Org.ID, req.Params); err != nil {
+		return nil, n, err
+	}
+
 	pr, err := req.ProxyRequest()
 	if err != nil {
 		return nil, n, err
@@ -42,6 +42,7 @@ type FluxBackend struct {
 	QueryEventRecorder metric.EventRecorder
 
 	OrganizationService platform.OrganizationService
+	VariableService     platform.VariableService
 	ProxyQueryService   query.ProxyQueryService
 }
 
@@ -54,6 +55,7 @@ func NewFluxBackend(b *APIBackend) *FluxBackend {
 
 		ProxyQueryService:   b.FluxService,
 		OrganizationService: b.OrganizationService,
+		VariableService:     b.VariableService,
 	}
 }
 
@@ -70,6 +72,7 @@ type FluxHandler struct {
 
 	Now                 func() time.Time
 	OrganizationService platform.OrganizationService
+	VariableService     platform.VariableService
 	ProxyQueryService   query.ProxyQueryService
 
 	EventRecorder metric.EventRecorder
@@ -85,12 +88,14 @@ func NewFluxHandler(b *FluxBackend) *FluxHandler {
 
 		ProxyQueryService:   b.ProxyQueryService,
 		OrganizationService: b.OrganizationService,
+		VariableService:     b.VariableService,
 		EventRecorder:       b.QueryEventRecorder,
 	}
 
 	h.HandlerFunc("POST", fluxPath, h.handleQuery)
 	h.HandlerFunc("POST", "/api/v2/query/ast", h.postFluxAST)
 	h.HandlerFunc("POST", "/api/v2/query/analyze", h.postQueryAnalyze)
+	h.HandlerFunc("POST", "/api/v2/query/explain", h.postQueryExplain)
 	h.HandlerFunc("GET", "/api/v2/query/suggestions", h.getFluxSuggestions)
 	h.HandlerFunc("GET", "/api/v2/query/suggestions/:name", h.getFluxSuggestion)
 	return h
@@ -131,7 +136,7 @@ func (h *FluxHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	req, n, err := decodeProxyQueryRequest(ctx, r, a, h.OrganizationService)
+	req, n, err := decodeProxyQueryRequest(ctx, r, a, h.OrganizationService, h.VariableService)
 	if err != nil && err != platform.ErrAuthorizerNotSupported {
 		err := &influxdb.Error{
 			Code: influxdb.EInvalid,
@@ -249,6 +254,34 @@ func (h *FluxHandler) postQueryAnalyze(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// postQueryExplain plans a query and returns its logical and physical plans.
+func (h *FluxHandler) postQueryExplain(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "FluxHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var req QueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid json",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	e, err := req.WithDefaults().Explain(ctx, h.Now)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, e); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
 // fluxParams contain flux funciton parameters as defined by the semantic graph
 type fluxParams map[string]string
 
@@ -368,7 +401,11 @@ func (s *FluxService) Query(ctx context.Context, w io.Writer, r *query.ProxyRequ
 	SetToken(s.Token, hreq)
 
 	hreq.Header.Set("Content-Type", "application/json")
-	hreq.Header.Set("Accept", "text/csv")
+	accept := "text/csv"
+	if ct, ok := queryFormats[qreq.Dialect.Format]; ok {
+		accept = ct
+	}
+	hreq.Header.Set("Accept", accept)
 	hreq = hreq.WithContext(ctx)
 
 	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
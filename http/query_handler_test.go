@@ -13,6 +13,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/flux"
@@ -25,6 +26,7 @@ import (
 	"github.com/influxdata/influxdb/inmem"
 	"github.com/influxdata/influxdb/kit/check"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/explain"
 	"github.com/influxdata/influxdb/query/mock"
 	"go.uber.org/zap/zaptest"
 )
@@ -269,6 +271,68 @@ func TestFluxHandler_postFluxAST(t *testing.T) {
 	}
 }
 
+func TestFluxHandler_postQueryExplain(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		status   int
+		logical  []string
+		physical []string
+	}{
+		{
+			name:     "pushes filter down to storage",
+			body:     `{"query": "from(bucket: \"b\") |> range(start: -1h) |> filter(fn: (r) => r._measurement == \"m\")"}`,
+			status:   http.StatusOK,
+			logical:  []string{"influxDBFrom", "range", "filter", "generatedYield"},
+			physical: []string{"ReadRangePhysKind", "generatedYield"},
+		},
+		{
+			name:   "error from query without streaming data",
+			body:   `{"query": "x = 1"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "error from bad json",
+			body:   `error!`,
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &FluxHandler{
+				HTTPErrorHandler: ErrorHandler(0),
+				Now:              time.Now,
+			}
+			w := httptest.NewRecorder()
+			h.postQueryExplain(w, httptest.NewRequest("POST", "/api/v2/query/explain", bytes.NewBufferString(tt.body)))
+			if got := w.Code; got != tt.status {
+				t.Fatalf("http.postQueryExplain = got %d\nwant %d: %s", got, tt.status, w.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var e explain.Explanation
+			if err := json.NewDecoder(w.Body).Decode(&e); err != nil {
+				t.Fatal(err)
+			}
+			kinds := func(p *explain.Plan) []string {
+				var kinds []string
+				for _, n := range p.Nodes {
+					kinds = append(kinds, n.Kind)
+				}
+				return kinds
+			}
+			if diff := cmp.Diff(kinds(e.Logical), tt.logical); diff != "" {
+				t.Errorf("unexpected logical plan -got/+want\n%s", diff)
+			}
+			if diff := cmp.Diff(kinds(e.Physical), tt.physical); diff != "" {
+				t.Errorf("unexpected physical plan -got/+want\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestFluxService_Check(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(HealthHandler))
 	defer ts.Close()
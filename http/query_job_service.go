@@ -0,0 +1,591 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/query"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	queryJobsPath = "/api/v2/queries/jobs"
+)
+
+// QueryJobBackend is all services and associated parameters required to construct
+// the QueryJobHandler.
+type QueryJobBackend struct {
+	influxdb.HTTPErrorHandler
+	Logger              *zap.Logger
+	QueryJobService     query.JobService
+	OrganizationService influxdb.OrganizationService
+	VariableService     influxdb.VariableService
+}
+
+// NewQueryJobBackend creates a backend used by the query job handler.
+func NewQueryJobBackend(b *APIBackend) *QueryJobBackend {
+	return &QueryJobBackend{
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		Logger:              b.Logger.With(zap.String("handler", "query_job")),
+		QueryJobService:     b.QueryJobService,
+		OrganizationService: b.OrganizationService,
+		VariableService:     b.VariableService,
+	}
+}
+
+// QueryJobHandler is the handler for submitting queries to be executed in the
+// background and reading their results.
+type QueryJobHandler struct {
+	*httprouter.Router
+
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	QueryJobService     query.JobService
+	OrganizationService influxdb.OrganizationService
+	VariableService     influxdb.VariableService
+}
+
+// NewQueryJobHandler creates a new QueryJobHandler.
+func NewQueryJobHandler(b *QueryJobBackend) *QueryJobHandler {
+	h := &QueryJobHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Logger:           b.Logger,
+
+		QueryJobService:     b.QueryJobService,
+		OrganizationService: b.OrganizationService,
+		VariableService:     b.VariableService,
+	}
+
+	entityPath := fmt.Sprintf("%s/:id", queryJobsPath)
+
+	h.HandlerFunc("POST", queryJobsPath, h.handlePostJob)
+	h.HandlerFunc("GET", queryJobsPath, h.handleGetJobs)
+	h.HandlerFunc("GET", entityPath, h.handleGetJob)
+	h.HandlerFunc("GET", entityPath+"/results", h.handleGetJobResults)
+	h.HandlerFunc("DELETE", entityPath, h.handleDeleteJob)
+
+	return h
+}
+
+type queryJobLinks struct {
+	Self    string `json:"self"`
+	Results string `json:"results"`
+	Org     string `json:"org"`
+}
+
+// queryJobResponse encodes the IDs of a query job as strings, since jobs
+// submitted without an authorization have no user. The times a job has not
+// reached yet are omitted.
+type queryJobResponse struct {
+	ID         string        `json:"id"`
+	OrgID      string        `json:"orgID"`
+	UserID     string        `json:"userID,omitempty"`
+	Query      string        `json:"query"`
+	Status     string        `json:"status"`
+	Error      string        `json:"error,omitempty"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	StartedAt  *time.Time    `json:"startedAt,omitempty"`
+	FinishedAt *time.Time    `json:"finishedAt,omitempty"`
+	ExpiresAt  *time.Time    `json:"expiresAt,omitempty"`
+	Pages      int           `json:"pages"`
+	Rows       int64         `json:"rows"`
+	Links      queryJobLinks `json:"links"`
+}
+
+func newQueryJobResponse(j *query.Job) *queryJobResponse {
+	resp := &queryJobResponse{
+		ID:        j.ID.String(),
+		OrgID:     j.OrganizationID.String(),
+		Query:     j.Query,
+		Status:    string(j.Status),
+		Error:     j.Error,
+		CreatedAt: j.CreatedAt,
+		Pages:     j.Pages,
+		Rows:      j.Rows,
+		Links: queryJobLinks{
+			Self:    queryJobIDPath(j.ID),
+			Results: path.Join(queryJobIDPath(j.ID), "results"),
+			Org:     fmt.Sprintf("/api/v2/orgs/%s", j.OrganizationID),
+		},
+	}
+	if j.UserID.Valid() {
+		resp.UserID = j.UserID.String()
+	}
+	optionalTime := func(t time.Time) *time.Time {
+		if t.IsZero() {
+			return nil
+		}
+		return &t
+	}
+	resp.StartedAt = optionalTime(j.StartedAt)
+	resp.FinishedAt = optionalTime(j.FinishedAt)
+	resp.ExpiresAt = optionalTime(j.ExpiresAt)
+	return resp
+}
+
+func (r *queryJobResponse) toJob() (*query.Job, error) {
+	j := &query.Job{
+		Query:     r.Query,
+		Status:    query.JobStatus(r.Status),
+		Error:     r.Error,
+		CreatedAt: r.CreatedAt,
+		Pages:     r.Pages,
+		Rows:      r.Rows,
+	}
+	if err := j.ID.DecodeFromString(r.ID); err != nil {
+		return nil, err
+	}
+	if err := j.OrganizationID.DecodeFromString(r.OrgID); err != nil {
+		return nil, err
+	}
+	if r.UserID != "" {
+		if err := j.UserID.DecodeFromString(r.UserID); err != nil {
+			return nil, err
+		}
+	}
+	if r.StartedAt != nil {
+		j.StartedAt = *r.StartedAt
+	}
+	if r.FinishedAt != nil {
+		j.FinishedAt = *r.FinishedAt
+	}
+	if r.ExpiresAt != nil {
+		j.ExpiresAt = *r.ExpiresAt
+	}
+	return j, nil
+}
+
+type queryJobsResponse struct {
+	Jobs  []*queryJobResponse `json:"jobs"`
+	Links map[string]string   `json:"links"`
+}
+
+func newQueryJobsResponse(js []*query.Job) *queryJobsResponse {
+	resp := &queryJobsResponse{
+		Jobs: make([]*queryJobResponse, 0, len(js)),
+		Links: map[string]string{
+			"self": queryJobsPath,
+		},
+	}
+	for _, j := range js {
+		resp.Jobs = append(resp.Jobs, newQueryJobResponse(j))
+	}
+	return resp
+}
+
+// handlePostJob submits the query of the body of the request, which is
+// decoded as for /api/v2/query. The dialect of the request is ignored, the
+// format of the results is chosen when they are read.
+func (h *QueryJobHandler) handlePostJob(w http.ResponseWriter, r *http.Request) {
+	const op = "http/handlePostQueryJob"
+	ctx := r.Context()
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "authorization is invalid or missing in the query request",
+			Op:   op,
+			Err:  err,
+		}, w)
+		return
+	}
+
+	req, _, err := decodeProxyQueryRequest(ctx, r, a, h.OrganizationService, h.VariableService)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode request body",
+			Op:   op,
+			Err:  err,
+		}, w)
+		return
+	}
+
+	j, err := h.QueryJobService.SubmitJob(ctx, &req.Request)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("query job submitted", zap.String("jobID", fmt.Sprint(j.ID)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newQueryJobResponse(j)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *QueryJobHandler) decodeJobFilter(ctx context.Context, r *http.Request) (*query.JobFilter, error) {
+	qp := r.URL.Query()
+	f := &query.JobFilter{}
+
+	if orgID := qp.Get("orgID"); orgID != "" {
+		id, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			return nil, err
+		}
+		f.OrganizationID = id
+	} else if org := qp.Get("org"); org != "" {
+		o, err := h.OrganizationService.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &org})
+		if err != nil {
+			return nil, err
+		}
+		f.OrganizationID = &o.ID
+	}
+	return f, nil
+}
+
+func (h *QueryJobHandler) handleGetJobs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := h.decodeJobFilter(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	js, err := h.QueryJobService.FindJobs(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newQueryJobsResponse(js)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *QueryJobHandler) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestActiveQueryID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	j, err := h.QueryJobService.FindJobByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newQueryJobResponse(j)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// decodeJobResultsDialect returns the dialect of the results of a job from
+// the format parameter of the request, or else its Accept header. Results
+// are encoded as annotated CSV by default, so that they can be decoded.
+func decodeJobResultsDialect(r *http.Request) (flux.Dialect, error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = queryFormatFromAccept(r.Header.Get("Accept"))
+	}
+	if _, ok := queryFormats[format]; !ok && format != "" {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unknown format: %s", format),
+		}
+	}
+	header := true
+	d := QueryDialect{
+		Format:      format,
+		Header:      &header,
+		Delimiter:   ",",
+		Annotations: []string{"datatype", "group", "default"},
+	}
+	return d.fluxDialect(), nil
+}
+
+func (h *QueryJobHandler) handleGetJobResults(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestActiveQueryID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	page := 0
+	if p := r.URL.Query().Get("page"); p != "" {
+		page, err = strconv.Atoi(p)
+		if err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "page must be an integer",
+				Err:  err,
+			}, w)
+			return
+		}
+	}
+
+	dialect, err := decodeJobResultsDialect(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	results, err := h.QueryJobService.ReadJobPage(ctx, id, page)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	defer results.Release()
+
+	dialect.(HTTPDialect).SetHeaders(w)
+	cw := iocounter.Writer{Writer: w}
+	if _, err := dialect.Encoder().Encode(&cw, results); err != nil {
+		if cw.Count() == 0 {
+			// Only record the error headers IFF nothing has been written to w.
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		h.Logger.Info("Error writing response to client",
+			zap.String("handler", "query_job"),
+			zap.Error(err),
+		)
+	}
+}
+
+func (h *QueryJobHandler) handleDeleteJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestActiveQueryID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.QueryJobService.DeleteJob(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("query job deleted", zap.String("jobID", fmt.Sprint(id)))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// QueryJobService connects to Influx via HTTP using tokens to submit queries
+// to be executed in the background and read their results.
+type QueryJobService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ query.JobService = (*QueryJobService)(nil)
+
+// SubmitJob submits the query of req to be executed in the background.
+func (s *QueryJobService) SubmitJob(ctx context.Context, req *query.Request) (*query.Job, error) {
+	u, err := NewURL(s.Addr, queryJobsPath)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set(OrgID, req.OrganizationID.String())
+	u.RawQuery = params.Encode()
+
+	qreq, err := QueryRequestFromProxyRequest(&query.ProxyRequest{
+		Request: *req,
+		Dialect: csv.DefaultDialect(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(qreq); err != nil {
+		return nil, err
+	}
+
+	hreq, err := http.NewRequest("POST", u.String(), &body)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, hreq)
+	hreq.Header.Set("Content-Type", "application/json")
+
+	var jr queryJobResponse
+	if err := s.do(ctx, hreq, http.StatusCreated, &jr); err != nil {
+		return nil, err
+	}
+	return jr.toJob()
+}
+
+// FindJobByID returns a single query job by ID.
+func (s *QueryJobService) FindJobByID(ctx context.Context, id influxdb.ID) (*query.Job, error) {
+	u, err := NewURL(s.Addr, queryJobIDPath(id))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	var jr queryJobResponse
+	if err := s.do(ctx, req, http.StatusOK, &jr); err != nil {
+		return nil, err
+	}
+	return jr.toJob()
+}
+
+// FindJobs returns the query jobs that match filter.
+func (s *QueryJobService) FindJobs(ctx context.Context, filter query.JobFilter) ([]*query.Job, error) {
+	u, err := NewURL(s.Addr, queryJobsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	qp := url.Values{}
+	if filter.OrganizationID != nil {
+		qp.Set("orgID", filter.OrganizationID.String())
+	}
+	req.URL.RawQuery = qp.Encode()
+	SetToken(s.Token, req)
+
+	var jr queryJobsResponse
+	if err := s.do(ctx, req, http.StatusOK, &jr); err != nil {
+		return nil, err
+	}
+
+	js := make([]*query.Job, 0, len(jr.Jobs))
+	for _, r := range jr.Jobs {
+		j, err := r.toJob()
+		if err != nil {
+			return nil, err
+		}
+		js = append(js, j)
+	}
+	return js, nil
+}
+
+// ReadJobPage returns the results of a page of a query job.
+func (s *QueryJobService) ReadJobPage(ctx context.Context, id influxdb.ID, page int) (flux.ResultIterator, error) {
+	resp, err := s.getJobPage(ctx, id, page, QueryFormatCSV)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{}).Decode(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return &responseResultIterator{ResultIterator: results, body: resp.Body}, nil
+}
+
+// WriteJobPage writes the results of a page of a query job to w, in the
+// given format.
+func (s *QueryJobService) WriteJobPage(ctx context.Context, w io.Writer, id influxdb.ID, page int, format string) error {
+	resp, err := s.getJobPage(ctx, id, page, format)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+func (s *QueryJobService) getJobPage(ctx context.Context, id influxdb.ID, page int, format string) (*http.Response, error) {
+	u, err := NewURL(s.Addr, path.Join(queryJobIDPath(id), "results"))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	qp := url.Values{}
+	qp.Set("page", strconv.Itoa(page))
+	if format != "" {
+		qp.Set("format", format)
+	}
+	req.URL.RawQuery = qp.Encode()
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckError(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// responseResultIterator closes the body of a response once its results are
+// released.
+type responseResultIterator struct {
+	flux.ResultIterator
+	body io.Closer
+}
+
+func (it *responseResultIterator) Release() {
+	it.ResultIterator.Release()
+	it.body.Close()
+}
+
+// DeleteJob cancels a query job by ID and removes its results.
+func (s *QueryJobService) DeleteJob(ctx context.Context, id influxdb.ID) error {
+	u, err := NewURL(s.Addr, queryJobIDPath(id))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	return s.do(ctx, req, http.StatusNoContent, nil)
+}
+
+// do sends req and decodes the body of the response into v, if it is not
+// nil, once its status is checked to be status.
+func (s *QueryJobService) do(ctx context.Context, req *http.Request, status int, v interface{}) error {
+	hc := NewClient(req.URL.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckErrorStatus(status, resp); err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func queryJobIDPath(id influxdb.ID) string {
+	return path.Join(queryJobsPath, id.String())
+}
@@ -0,0 +1,294 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"go.uber.org/zap"
+)
+
+// newQueryJobServer returns a server of a query job handler of svc that
+// authorizes requests with the authorization of the user 0x2.
+func newQueryJobServer(svc query.JobService) *httptest.Server {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, f influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		if (f.ID != nil && *f.ID == 0x1) || (f.Name != nil && *f.Name == "edge") {
+			return &influxdb.Organization{ID: 0x1, Name: "edge"}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "organization not found"}
+	}
+	h := NewQueryJobHandler(&QueryJobBackend{
+		HTTPErrorHandler:    ErrorHandler(0),
+		Logger:              zap.NewNop(),
+		QueryJobService:     svc,
+		OrganizationService: orgs,
+	})
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := pcontext.SetAuthorizer(r.Context(), &influxdb.Authorization{ID: 0x3, UserID: 0x2, OrgID: 0x1})
+		h.ServeHTTP(w, r.WithContext(ctx))
+	}))
+}
+
+func TestQueryJobHandler_handlePostJob(t *testing.T) {
+	svc := querymock.NewJobService()
+	svc.SubmitJobFn = func(ctx context.Context, req *query.Request) (*query.Job, error) {
+		if req.OrganizationID != 0x1 || req.Authorization == nil || req.Authorization.UserID != 0x2 {
+			t.Errorf("unexpected request %+v", req)
+		}
+		return &query.Job{
+			ID:             0x10,
+			OrganizationID: req.OrganizationID,
+			UserID:         req.Authorization.UserID,
+			Query:          req.Text(),
+			Status:         query.JobQueued,
+			CreatedAt:      time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC),
+		}, nil
+	}
+	server := newQueryJobServer(svc)
+	defer server.Close()
+
+	res, err := http.Post(server.URL+"/api/v2/queries/jobs?org=edge", "application/vnd.flux", strings.NewReader(`from(bucket: "telegraf") |> range(start: -1h)`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("unexpected status %d: %s", res.StatusCode, body)
+	}
+
+	exp := `
+{
+  "id": "0000000000000010",
+  "orgID": "0000000000000001",
+  "userID": "0000000000000002",
+  "query": "from(bucket: \"telegraf\") |> range(start: -1h)",
+  "status": "queued",
+  "createdAt": "2019-05-01T12:00:00Z",
+  "pages": 0,
+  "rows": 0,
+  "links": {
+    "self": "/api/v2/queries/jobs/0000000000000010",
+    "results": "/api/v2/queries/jobs/0000000000000010/results",
+    "org": "/api/v2/orgs/0000000000000001"
+  }
+}`
+	if eq, diff, err := jsonEqual(string(body), exp); err != nil {
+		t.Fatalf("error unmarshaling json %v", err)
+	} else if !eq {
+		t.Errorf("unexpected response ***%s***", diff)
+	}
+}
+
+func TestQueryJobHandler_handleGetJobResults(t *testing.T) {
+	svc := querymock.NewJobService()
+	svc.ReadJobPageFn = func(ctx context.Context, id influxdb.ID, page int) (flux.ResultIterator, error) {
+		if page != 1 {
+			return nil, query.ErrJobPageNotFound
+		}
+		return flux.NewSliceResultIterator([]flux.Result{&executetest.Result{
+			Nm: "_result",
+			Tbls: []*executetest.Table{{
+				KeyCols: []string{"host"},
+				ColMeta: []flux.ColMeta{
+					{Label: "host", Type: flux.TString},
+					{Label: "_value", Type: flux.TInt},
+				},
+				Data: [][]interface{}{{"a", int64(1)}},
+			}},
+		}}), nil
+	}
+	server := newQueryJobServer(svc)
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		url    string
+		accept string
+		status int
+		ct     string
+		body   string
+	}{
+		{
+			name:   "annotated CSV by default",
+			url:    "/api/v2/queries/jobs/0000000000000010/results?page=1",
+			status: http.StatusOK,
+			ct:     "text/csv; charset=utf-8",
+			body: "#datatype,string,long,string,long\r\n" +
+				"#group,false,false,true,false\r\n" +
+				"#default,_result,,,\r\n" +
+				",result,table,host,_value\r\n" +
+				",,0,a,1\r\n\r\n",
+		},
+		{
+			name:   "format parameter",
+			url:    "/api/v2/queries/jobs/0000000000000010/results?page=1&format=json",
+			accept: "text/csv",
+			status: http.StatusOK,
+			ct:     "application/json",
+		},
+		{
+			name:   "accept header",
+			url:    "/api/v2/queries/jobs/0000000000000010/results?page=1",
+			accept: "application/vnd.apache.arrow.stream",
+			status: http.StatusOK,
+			ct:     "application/vnd.apache.arrow.stream",
+		},
+		{
+			name:   "unknown format",
+			url:    "/api/v2/queries/jobs/0000000000000010/results?page=1&format=xml",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "page not found",
+			url:    "/api/v2/queries/jobs/0000000000000010/results?page=2",
+			status: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", server.URL+tt.url, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.status {
+				t.Fatalf("unexpected status %d: %s", res.StatusCode, body)
+			}
+			if tt.ct != "" {
+				if got := res.Header.Get("Content-Type"); got != tt.ct {
+					t.Errorf("unexpected content type -got/+exp\n%s\n%s", got, tt.ct)
+				}
+			}
+			if tt.body != "" && string(body) != tt.body {
+				t.Errorf("unexpected body -got/+exp\n%q\n%q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestQueryJobService(t *testing.T) {
+	ctx := context.Background()
+
+	j := &query.Job{
+		ID:             0x10,
+		OrganizationID: 0x1,
+		UserID:         0x2,
+		Query:          `from(bucket: "telegraf") |> range(start: -1h)`,
+		Status:         query.JobSucceeded,
+		CreatedAt:      time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC),
+		StartedAt:      time.Date(2019, 5, 1, 12, 0, 1, 0, time.UTC),
+		FinishedAt:     time.Date(2019, 5, 1, 12, 0, 2, 0, time.UTC),
+		ExpiresAt:      time.Date(2019, 5, 2, 12, 0, 2, 0, time.UTC),
+		Pages:          1,
+		Rows:           2,
+	}
+	var deleted bool
+	svc := querymock.NewJobService()
+	svc.SubmitJobFn = func(ctx context.Context, req *query.Request) (*query.Job, error) {
+		if req.Text() != j.Query {
+			t.Errorf("unexpected query -got/+exp\n%s\n%s", req.Text(), j.Query)
+		}
+		return j, nil
+	}
+	svc.FindJobByIDFn = func(ctx context.Context, id influxdb.ID) (*query.Job, error) {
+		if id != j.ID || deleted {
+			return nil, query.ErrJobNotFound
+		}
+		return j, nil
+	}
+	svc.FindJobsFn = func(ctx context.Context, f query.JobFilter) ([]*query.Job, error) {
+		if f.OrganizationID == nil || *f.OrganizationID != j.OrganizationID || deleted {
+			return nil, nil
+		}
+		return []*query.Job{j}, nil
+	}
+	svc.ReadJobPageFn = func(ctx context.Context, id influxdb.ID, page int) (flux.ResultIterator, error) {
+		return flux.NewSliceResultIterator([]flux.Result{&executetest.Result{
+			Nm: "_result",
+			Tbls: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{{Label: "_value", Type: flux.TFloat}},
+				Data:    [][]interface{}{{1.0}, {2.0}},
+			}},
+		}}), nil
+	}
+	svc.DeleteJobFn = func(ctx context.Context, id influxdb.ID) error {
+		if _, err := svc.FindJobByIDFn(ctx, id); err != nil {
+			return err
+		}
+		deleted = true
+		return nil
+	}
+
+	server := newQueryJobServer(svc)
+	defer server.Close()
+	client := &QueryJobService{Addr: server.URL}
+
+	got, err := client.SubmitJob(ctx, &query.Request{
+		OrganizationID: j.OrganizationID,
+		Compiler:       lang.FluxCompiler{Query: j.Query},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, j); diff != "" {
+		t.Errorf("jobs are different -got/+want\ndiff %s", diff)
+	}
+
+	js, err := client.FindJobs(ctx, query.JobFilter{OrganizationID: &j.OrganizationID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(js, []*query.Job{j}); diff != "" {
+		t.Errorf("jobs are different -got/+want\ndiff %s", diff)
+	}
+
+	results, err := client.ReadJobPage(ctx, j.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows int
+	for results.More() {
+		if err := results.Next().Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				rows += cr.Len()
+				return nil
+			})
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results.Release()
+	if err := results.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if rows != 2 {
+		t.Errorf("unexpected rows -got/+exp\n%d\n%d", rows, 2)
+	}
+
+	if err := client.DeleteJob(ctx, j.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.FindJobByID(ctx, j.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
@@ -0,0 +1,278 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+)
+
+// QueryParamsName is the name of the record of the parameters of a query.
+const QueryParamsName = "params"
+
+// Types of query parameters.
+const (
+	QueryParamString   = "string"
+	QueryParamInt      = "int"
+	QueryParamFloat    = "float"
+	QueryParamBool     = "bool"
+	QueryParamDuration = "duration"
+	QueryParamTime     = "time"
+	QueryParamArray    = "array"
+	// QueryParamVariable is the type of a parameter whose value is the
+	// selected value of a variable of the organization of the query. It is
+	// expanded into a string parameter before the query is compiled.
+	QueryParamVariable = "variable"
+)
+
+// QueryParams are the parameters of a Flux query. They are available to the
+// query as the properties of the params record, so that values never need to
+// be formatted into the text of the query.
+//
+// A parameter is either a JSON string, number, boolean or array, whose type
+// is inferred, or an object that declares its type and value, such as
+// {"type": "duration", "value": "5m"}. Numbers are integers unless they have
+// a fraction or an exponent, and the elements of an array must all have the
+// same type.
+type QueryParams map[string]QueryParam
+
+// QueryParam is a parameter of a query.
+type QueryParam struct {
+	// Type is the type of the parameter, one of the QueryParam constants.
+	Type string
+	// Value is a string for strings, durations and variables, an int64, a
+	// float64, a bool, a time.Time or a []QueryParam for arrays.
+	Value interface{}
+	// Selected is the value of a variable parameter. The selected value of
+	// the variable is used if it is empty.
+	Selected string
+}
+
+// queryParamJSON is the JSON object of a parameter with a declared type.
+type queryParamJSON struct {
+	Type     string          `json:"type"`
+	Value    json.RawMessage `json:"value"`
+	Selected string          `json:"selected,omitempty"`
+}
+
+var queryParamNameRE = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Validate returns an error if the name of a parameter is not an identifier.
+func (ps QueryParams) Validate() error {
+	for name := range ps {
+		if !queryParamNameRE.MatchString(name) {
+			return fmt.Errorf("invalid parameter name %q: must be an identifier", name)
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes the parameter of its inferred or declared type.
+func (p *QueryParam) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		var pj queryParamJSON
+		if err := json.Unmarshal(data, &pj); err != nil {
+			return err
+		}
+		return p.decodeDeclared(pj)
+	case nil:
+		return fmt.Errorf("parameter must not be null")
+	default:
+		return p.decodeInferred(v)
+	}
+}
+
+// decodeInferred decodes a JSON value decoded with numbers as json.Number.
+func (p *QueryParam) decodeInferred(v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		*p = QueryParam{Type: QueryParamString, Value: v}
+	case bool:
+		*p = QueryParam{Type: QueryParamBool, Value: v}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			*p = QueryParam{Type: QueryParamInt, Value: i}
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return fmt.Errorf("invalid number %s: %v", v, err)
+		}
+		*p = QueryParam{Type: QueryParamFloat, Value: f}
+	case []interface{}:
+		elems := make([]QueryParam, len(v))
+		for i, e := range v {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			if err := elems[i].UnmarshalJSON(data); err != nil {
+				return fmt.Errorf("element %d: %v", i, err)
+			}
+		}
+		*p = QueryParam{Type: QueryParamArray, Value: elems}
+		return p.validateArray()
+	default:
+		return fmt.Errorf("unsupported parameter value %v", v)
+	}
+	return nil
+}
+
+// decodeDeclared decodes the value of a parameter of a declared type.
+func (p *QueryParam) decodeDeclared(pj queryParamJSON) error {
+	if len(pj.Value) == 0 {
+		return fmt.Errorf("parameter of type %s has no value", pj.Type)
+	}
+
+	var err error
+	switch pj.Type {
+	case QueryParamString, QueryParamVariable:
+		var s string
+		err = json.Unmarshal(pj.Value, &s)
+		*p = QueryParam{Type: pj.Type, Value: s}
+	case QueryParamInt:
+		var i int64
+		err = json.Unmarshal(pj.Value, &i)
+		*p = QueryParam{Type: pj.Type, Value: i}
+	case QueryParamFloat:
+		var f float64
+		err = json.Unmarshal(pj.Value, &f)
+		*p = QueryParam{Type: pj.Type, Value: f}
+	case QueryParamBool:
+		var b bool
+		err = json.Unmarshal(pj.Value, &b)
+		*p = QueryParam{Type: pj.Type, Value: b}
+	case QueryParamDuration:
+		var s string
+		if err = json.Unmarshal(pj.Value, &s); err == nil {
+			_, err = parser.ParseSignedDuration(s)
+		}
+		*p = QueryParam{Type: pj.Type, Value: s}
+	case QueryParamTime:
+		var t time.Time
+		err = json.Unmarshal(pj.Value, &t)
+		*p = QueryParam{Type: pj.Type, Value: t}
+	case QueryParamArray:
+		var elems []QueryParam
+		err = json.Unmarshal(pj.Value, &elems)
+		*p = QueryParam{Type: pj.Type, Value: elems}
+		if err == nil {
+			err = p.validateArray()
+		}
+	default:
+		return fmt.Errorf("unknown parameter type %q", pj.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value of parameter of type %s: %v", pj.Type, err)
+	}
+	if pj.Selected != "" {
+		if pj.Type != QueryParamVariable {
+			return fmt.Errorf("only parameters of type %s have a selected value", QueryParamVariable)
+		}
+		p.Selected = pj.Selected
+	}
+	return nil
+}
+
+// validateArray returns an error if the elements of an array do not all have
+// the same type, or are variables.
+func (p *QueryParam) validateArray() error {
+	elems := p.Value.([]QueryParam)
+	for i, e := range elems {
+		if e.Type == QueryParamVariable {
+			return fmt.Errorf("element %d: arrays cannot have variables", i)
+		}
+		if e.Type != elems[0].Type {
+			return fmt.Errorf("element %d: expected %s, got %s", i, elems[0].Type, e.Type)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the parameter with its declared type.
+func (p QueryParam) MarshalJSON() ([]byte, error) {
+	value := p.Value
+	if t, ok := value.(time.Time); ok {
+		value = t.Format(time.RFC3339Nano)
+	}
+	v, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(queryParamJSON{Type: p.Type, Value: v, Selected: p.Selected})
+}
+
+// File returns a file assigning the parameters to the params record.
+func (ps QueryParams) File() (*ast.File, error) {
+	names := make([]string, 0, len(ps))
+	for name := range ps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	record := &ast.ObjectExpression{Properties: make([]*ast.Property, 0, len(ps))}
+	for _, name := range names {
+		v, err := ps[name].expression()
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %v", name, err)
+		}
+		record.Properties = append(record.Properties, &ast.Property{
+			Key:   &ast.Identifier{Name: name},
+			Value: v,
+		})
+	}
+
+	return &ast.File{
+		Body: []ast.Statement{
+			&ast.VariableAssignment{
+				ID:   &ast.Identifier{Name: QueryParamsName},
+				Init: record,
+			},
+		},
+	}, nil
+}
+
+// expression returns the literal of the value of the parameter.
+func (p QueryParam) expression() (ast.Expression, error) {
+	switch p.Type {
+	case QueryParamString:
+		return &ast.StringLiteral{Value: p.Value.(string)}, nil
+	case QueryParamInt:
+		return &ast.IntegerLiteral{Value: p.Value.(int64)}, nil
+	case QueryParamFloat:
+		return &ast.FloatLiteral{Value: p.Value.(float64)}, nil
+	case QueryParamBool:
+		return &ast.BooleanLiteral{Value: p.Value.(bool)}, nil
+	case QueryParamDuration:
+		return parser.ParseSignedDuration(p.Value.(string))
+	case QueryParamTime:
+		return &ast.DateTimeLiteral{Value: p.Value.(time.Time)}, nil
+	case QueryParamArray:
+		elems := p.Value.([]QueryParam)
+		array := &ast.ArrayExpression{Elements: make([]ast.Expression, len(elems))}
+		for i, e := range elems {
+			v, err := e.expression()
+			if err != nil {
+				return nil, err
+			}
+			array.Elements[i] = v
+		}
+		return array, nil
+	case QueryParamVariable:
+		return nil, fmt.Errorf("variable %q was not expanded", p.Value)
+	}
+	return nil, fmt.Errorf("unknown parameter type %q", p.Type)
+}
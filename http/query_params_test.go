@@ -0,0 +1,130 @@
+package http
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/flux/ast"
+)
+
+func TestQueryParams_File(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "inferred types",
+			params: `{"bucket": "telegraf", "limit": 10, "ratio": 0.5, "desc": true, "hosts": ["a", "b"]}`,
+			want: "params = {\n" +
+				"\tbucket: \"telegraf\",\n" +
+				"\tdesc: true,\n" +
+				"\thosts: [\"a\", \"b\"],\n" +
+				"\tlimit: 10,\n" +
+				"\tratio: 0.5,\n" +
+				"}",
+		},
+		{
+			name: "declared types",
+			params: `{
+				"every": {"type": "duration", "value": "1h5m"},
+				"offset": {"type": "duration", "value": "-5m"},
+				"start": {"type": "time", "value": "2019-05-01T12:00:00Z"},
+				"n": {"type": "float", "value": 2},
+				"windows": {"type": "array", "value": [{"type": "duration", "value": "1m"}, {"type": "duration", "value": "5m"}]}
+			}`,
+			want: "params = {\n" +
+				"\tevery: 1h5m,\n" +
+				"\tn: 2.0,\n" +
+				"\toffset: -5m,\n" +
+				"\tstart: 2019-05-01T12:00:00Z,\n" +
+				"\twindows: [1m, 5m],\n" +
+				"}",
+		},
+		{
+			name:   "strings are never interpreted",
+			params: `{"bucket": "telegraf\") |> drop(columns: [\"_value\"]) //"}`,
+			want:   `params = {bucket: "telegraf\") |> drop(columns: [\"_value\"]) //"}`,
+		},
+		{
+			name:    "invalid duration",
+			params:  `{"every": {"type": "duration", "value": "1h) |> yield("}}`,
+			wantErr: "invalid value of parameter of type duration",
+		},
+		{
+			name:    "wrong declared type",
+			params:  `{"limit": {"type": "int", "value": "10"}}`,
+			wantErr: "invalid value of parameter of type int",
+		},
+		{
+			name:    "unknown type",
+			params:  `{"limit": {"type": "regexp", "value": "a.*"}}`,
+			wantErr: `unknown parameter type "regexp"`,
+		},
+		{
+			name:    "mixed array",
+			params:  `{"hosts": ["a", 1]}`,
+			wantErr: "element 1: expected string, got int",
+		},
+		{
+			name:    "null",
+			params:  `{"bucket": null}`,
+			wantErr: "parameter must not be null",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ps QueryParams
+			err := json.Unmarshal([]byte(tt.params), &ps)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			f, err := ps.File()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ast.Format(f.Body[0]); got != tt.want {
+				t.Errorf("unexpected params -got/+want\n%s\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryParams_JSON(t *testing.T) {
+	var ps QueryParams
+	if err := json.Unmarshal([]byte(`{
+		"start": {"type": "time", "value": "2019-05-01T12:00:00Z"},
+		"host": {"type": "variable", "value": "host", "selected": "a"},
+		"limits": [1, 2]
+	}`), &ps); err != nil {
+		t.Fatal(err)
+	}
+
+	octets, err := json.Marshal(ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"host":{"type":"variable","value":"host","selected":"a"},"limits":{"type":"array","value":[{"type":"int","value":1},{"type":"int","value":2}]},"start":{"type":"time","value":"2019-05-01T12:00:00Z"}}`
+	if string(octets) != exp {
+		t.Errorf("unexpected JSON -got/+exp\n%s\n%s", octets, exp)
+	}
+
+	if _, err := ps.File(); err == nil || !strings.Contains(err.Error(), `variable "host" was not expanded`) {
+		t.Errorf("expected unexpanded variable error, got %v", err)
+	}
+}
+
+func TestQueryParams_Validate(t *testing.T) {
+	ps := QueryParams{"a-b": {Type: QueryParamInt, Value: int64(1)}}
+	if err := ps.Validate(); err == nil {
+		t.Error("expected invalid parameter name")
+	}
+}
@@ -0,0 +1,477 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	replicationsPath = "/api/v2/replications"
+)
+
+// ReplicationBackend is all services and associated parameters required to construct
+// the ReplicationHandler.
+type ReplicationBackend struct {
+	influxdb.HTTPErrorHandler
+	Logger             *zap.Logger
+	ReplicationService influxdb.ReplicationService
+}
+
+// NewReplicationBackend creates a backend used by the replication handler.
+func NewReplicationBackend(b *APIBackend) *ReplicationBackend {
+	return &ReplicationBackend{
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		Logger:             b.Logger.With(zap.String("handler", "replication")),
+		ReplicationService: b.ReplicationService,
+	}
+}
+
+// ReplicationHandler is the handler for the replication service.
+type ReplicationHandler struct {
+	*httprouter.Router
+
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	ReplicationService influxdb.ReplicationService
+}
+
+// NewReplicationHandler creates a new ReplicationHandler.
+func NewReplicationHandler(b *ReplicationBackend) *ReplicationHandler {
+	h := &ReplicationHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Logger:           b.Logger,
+
+		ReplicationService: b.ReplicationService,
+	}
+
+	entityPath := fmt.Sprintf("%s/:id", replicationsPath)
+
+	h.HandlerFunc("GET", replicationsPath, h.handleGetReplications)
+	h.HandlerFunc("POST", replicationsPath, h.handlePostReplication)
+	h.HandlerFunc("GET", entityPath, h.handleGetReplication)
+	h.HandlerFunc("PATCH", entityPath, h.handlePatchReplication)
+	h.HandlerFunc("DELETE", entityPath, h.handleDeleteReplication)
+
+	return h
+}
+
+type replicationLinks struct {
+	Self        string `json:"self"`
+	Org         string `json:"org"`
+	LocalBucket string `json:"localBucket"`
+}
+
+type replicationResponse struct {
+	*influxdb.Replication
+	Links replicationLinks `json:"links"`
+}
+
+// newReplicationResponse returns the response for a replication. The token
+// of the remote is never returned.
+func newReplicationResponse(r *influxdb.Replication) *replicationResponse {
+	redacted := *r
+	redacted.RemoteToken = ""
+	return &replicationResponse{
+		Replication: &redacted,
+		Links: replicationLinks{
+			Self:        fmt.Sprintf("/api/v2/replications/%s", r.ID),
+			Org:         fmt.Sprintf("/api/v2/orgs/%s", r.OrgID),
+			LocalBucket: fmt.Sprintf("/api/v2/buckets/%s", r.LocalBucketID),
+		},
+	}
+}
+
+type replicationsResponse struct {
+	Replications []*replicationResponse `json:"replications"`
+	Links        map[string]string      `json:"links"`
+}
+
+func newReplicationsResponse(rs []*influxdb.Replication) *replicationsResponse {
+	resp := &replicationsResponse{
+		Replications: make([]*replicationResponse, 0, len(rs)),
+		Links: map[string]string{
+			"self": replicationsPath,
+		},
+	}
+	for _, r := range rs {
+		resp.Replications = append(resp.Replications, newReplicationResponse(r))
+	}
+	return resp
+}
+
+func decodeReplicationFilter(ctx context.Context, r *http.Request) (*influxdb.ReplicationFilter, error) {
+	qp := r.URL.Query()
+	f := &influxdb.ReplicationFilter{}
+
+	if id := qp.Get("id"); id != "" {
+		i, err := influxdb.IDFromString(id)
+		if err != nil {
+			return nil, err
+		}
+		f.ID = i
+	}
+	if orgID := qp.Get("orgID"); orgID != "" {
+		i, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			return nil, err
+		}
+		f.OrgID = i
+	}
+	if bucketID := qp.Get("localBucketID"); bucketID != "" {
+		i, err := influxdb.IDFromString(bucketID)
+		if err != nil {
+			return nil, err
+		}
+		f.LocalBucketID = i
+	}
+	return f, nil
+}
+
+func (h *ReplicationHandler) handleGetReplications(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeReplicationFilter(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	rs, err := h.ReplicationService.FindReplications(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newReplicationsResponse(rs)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func requestReplicationID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	urlID := params.ByName("id")
+	if urlID == "" {
+		return influxdb.InvalidID(), &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	id, err := influxdb.IDFromString(urlID)
+	if err != nil {
+		return influxdb.InvalidID(), err
+	}
+	return *id, nil
+}
+
+func (h *ReplicationHandler) handleGetReplication(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestReplicationID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	rep, err := h.ReplicationService.FindReplicationByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newReplicationResponse(rep)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodePostReplicationRequest(ctx context.Context, r *http.Request) (*influxdb.Replication, error) {
+	rep := &influxdb.Replication{}
+	if err := json.NewDecoder(r.Body).Decode(rep); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode replication",
+			Err:  err,
+		}
+	}
+
+	if err := rep.Valid(); err != nil {
+		return nil, err
+	}
+	return rep, nil
+}
+
+func (h *ReplicationHandler) handlePostReplication(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rep, err := decodePostReplicationRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.ReplicationService.CreateReplication(ctx, rep); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("replication created", zap.String("replication", fmt.Sprint(rep.ID)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newReplicationResponse(rep)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+type patchReplicationRequest struct {
+	id  influxdb.ID
+	upd influxdb.ReplicationUpdate
+}
+
+func decodePatchReplicationRequest(ctx context.Context, r *http.Request) (*patchReplicationRequest, error) {
+	id, err := requestReplicationID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &patchReplicationRequest{id: id}
+	if err := json.NewDecoder(r.Body).Decode(&req.upd); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode replication update",
+			Err:  err,
+		}
+	}
+
+	if err := req.upd.Valid(); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (h *ReplicationHandler) handlePatchReplication(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodePatchReplicationRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	rep, err := h.ReplicationService.UpdateReplication(ctx, req.id, req.upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("replication updated", zap.String("replication", fmt.Sprint(rep.ID)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newReplicationResponse(rep)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *ReplicationHandler) handleDeleteReplication(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestReplicationID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.ReplicationService.DeleteReplication(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("replication deleted", zap.String("replicationID", fmt.Sprint(id)))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReplicationService connects to Influx via HTTP using tokens to manage replications.
+type ReplicationService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ influxdb.ReplicationService = (*ReplicationService)(nil)
+
+// FindReplicationByID returns a single replication by ID.
+func (s *ReplicationService) FindReplicationByID(ctx context.Context, id influxdb.ID) (*influxdb.Replication, error) {
+	u, err := NewURL(s.Addr, replicationIDPath(id))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var rr replicationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return nil, err
+	}
+	return rr.Replication, nil
+}
+
+// FindReplications returns a list of replications that match filter.
+func (s *ReplicationService) FindReplications(ctx context.Context, filter influxdb.ReplicationFilter) ([]*influxdb.Replication, error) {
+	u, err := NewURL(s.Addr, replicationsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = url.Values(filter.QueryParams()).Encode()
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var rr replicationsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return nil, err
+	}
+
+	rs := make([]*influxdb.Replication, 0, len(rr.Replications))
+	for _, r := range rr.Replications {
+		rs = append(rs, r.Replication)
+	}
+	return rs, nil
+}
+
+// CreateReplication creates a new replication and sets r.ID with the new identifier.
+func (s *ReplicationService) CreateReplication(ctx context.Context, r *influxdb.Replication) error {
+	u, err := NewURL(s.Addr, replicationsPath)
+	if err != nil {
+		return err
+	}
+
+	octets, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	var rr replicationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return err
+	}
+	token := r.RemoteToken
+	*r = *rr.Replication
+	r.RemoteToken = token
+	return nil
+}
+
+// UpdateReplication updates a single replication with changeset.
+func (s *ReplicationService) UpdateReplication(ctx context.Context, id influxdb.ID, upd influxdb.ReplicationUpdate) (*influxdb.Replication, error) {
+	u, err := NewURL(s.Addr, replicationIDPath(id))
+	if err != nil {
+		return nil, err
+	}
+
+	octets, err := json.Marshal(upd)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var rr replicationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return nil, err
+	}
+	return rr.Replication, nil
+}
+
+// DeleteReplication removes a replication by ID.
+func (s *ReplicationService) DeleteReplication(ctx context.Context, id influxdb.ID) error {
+	u, err := NewURL(s.Addr, replicationIDPath(id))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckErrorStatus(http.StatusNoContent, resp)
+}
+
+func replicationIDPath(id influxdb.ID) string {
+	return path.Join(replicationsPath, id.String())
+}
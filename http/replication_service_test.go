@@ -0,0 +1,174 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap"
+)
+
+func newReplicationHandler(svc influxdb.ReplicationService) *ReplicationHandler {
+	return NewReplicationHandler(&ReplicationBackend{
+		HTTPErrorHandler:   ErrorHandler(0),
+		Logger:             zap.NewNop(),
+		ReplicationService: svc,
+	})
+}
+
+func TestReplicationHandler_handleGetReplications(t *testing.T) {
+	svc := mock.NewReplicationService()
+	svc.FindReplicationsFn = func(ctx context.Context, f influxdb.ReplicationFilter) ([]*influxdb.Replication, error) {
+		if f.OrgID == nil || *f.OrgID != 0x1 {
+			t.Errorf("unexpected filter %+v", f)
+		}
+		return []*influxdb.Replication{
+			{
+				ID:                0x10,
+				OrgID:             0x1,
+				Name:              "cloud",
+				LocalBucketID:     0x2,
+				RemoteURL:         "https://cloud:9999",
+				RemoteToken:       "secret",
+				RemoteOrgID:       0x3,
+				RemoteBucketID:    0x4,
+				MaxQueueSizeBytes: 1024,
+				DropPolicy:        influxdb.DropOldestReplicationPolicy,
+			},
+		}, nil
+	}
+
+	r := httptest.NewRequest("GET", "http://any.url/api/v2/replications?orgID=0000000000000001", nil)
+	w := httptest.NewRecorder()
+	newReplicationHandler(svc).ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", res.StatusCode, body)
+	}
+
+	exp := `
+{
+  "links": {
+    "self": "/api/v2/replications"
+  },
+  "replications": [
+    {
+      "id": "0000000000000010",
+      "orgID": "0000000000000001",
+      "name": "cloud",
+      "localBucketID": "0000000000000002",
+      "remoteURL": "https://cloud:9999",
+      "remoteOrgID": "0000000000000003",
+      "remoteBucketID": "0000000000000004",
+      "maxQueueSizeBytes": 1024,
+      "dropPolicy": "drop-oldest",
+      "createdAt": "0001-01-01T00:00:00Z",
+      "updatedAt": "0001-01-01T00:00:00Z",
+      "links": {
+        "self": "/api/v2/replications/0000000000000010",
+        "org": "/api/v2/orgs/0000000000000001",
+        "localBucket": "/api/v2/buckets/0000000000000002"
+      }
+    }
+  ]
+}`
+	if eq, diff, err := jsonEqual(string(body), exp); err != nil {
+		t.Fatalf("error unmarshaling json %v", err)
+	} else if !eq {
+		t.Errorf("unexpected response ***%s***", diff)
+	}
+}
+
+func TestReplicationHandler_handlePostReplication_Invalid(t *testing.T) {
+	svc := mock.NewReplicationService()
+	svc.CreateReplicationFn = func(ctx context.Context, r *influxdb.Replication) error {
+		t.Error("unexpected call to CreateReplication")
+		return nil
+	}
+
+	body := `{"name": "cloud", "orgID": "0000000000000001", "localBucketID": "0000000000000002", "remoteURL": "ftp://cloud", "remoteOrgID": "0000000000000003", "remoteBucketID": "0000000000000004"}`
+	r := httptest.NewRequest("POST", "http://any.url/api/v2/replications", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	newReplicationHandler(svc).ServeHTTP(w, r)
+
+	if got, exp := w.Result().StatusCode, http.StatusBadRequest; got != exp {
+		t.Errorf("unexpected status -got/+exp\n%d\n%d", got, exp)
+	}
+}
+
+func TestReplicationService(t *testing.T) {
+	ctx := context.Background()
+
+	store := kv.NewService(inmem.NewKVStore())
+	if err := store.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "edge"}
+	if err := store.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	bucket := &influxdb.Bucket{OrgID: org.ID, Name: "sensors"}
+	if err := store.CreateBucket(ctx, bucket); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(newReplicationHandler(store))
+	defer server.Close()
+	client := &ReplicationService{Addr: server.URL}
+
+	r := &influxdb.Replication{
+		OrgID:          org.ID,
+		Name:           "cloud",
+		LocalBucketID:  bucket.ID,
+		RemoteURL:      "https://cloud:9999",
+		RemoteToken:    "secret",
+		RemoteOrgID:    0x3,
+		RemoteBucketID: 0x4,
+	}
+	if err := client.CreateReplication(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+	if !r.ID.Valid() || r.RemoteToken != "secret" || r.DropPolicy != influxdb.DropOldestReplicationPolicy {
+		t.Errorf("unexpected replication after create %+v", r)
+	}
+
+	// The token is stored, but not returned.
+	if stored, err := store.FindReplicationByID(ctx, r.ID); err != nil {
+		t.Fatal(err)
+	} else if stored.RemoteToken != "secret" {
+		t.Errorf("expected token to be stored, got %q", stored.RemoteToken)
+	}
+
+	name := "central"
+	updated, err := client.UpdateReplication(ctx, r.ID, influxdb.ReplicationUpdate{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != name || updated.RemoteToken != "" {
+		t.Errorf("unexpected replication after update %+v", updated)
+	}
+
+	rs, err := client.FindReplications(ctx, influxdb.ReplicationFilter{LocalBucketID: &bucket.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 1 || rs[0].ID != r.ID {
+		t.Errorf("unexpected replications %+v", rs)
+	}
+
+	if err := client.DeleteReplication(ctx, r.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.FindReplicationByID(ctx, r.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
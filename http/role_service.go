@@ -0,0 +1,487 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	rolesPath            = "/api/v2/roles"
+	rolesIDPath          = "/api/v2/roles/:id"
+	rolesIDMembersPath   = "/api/v2/roles/:id/members"
+	rolesIDMembersIDPath = "/api/v2/roles/:id/members/:userID"
+)
+
+// RoleBackend is all services and associated parameters required to construct
+// the RoleHandler.
+type RoleBackend struct {
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	RoleService                influxdb.RoleService
+	UserResourceMappingService influxdb.UserResourceMappingService
+	UserService                influxdb.UserService
+}
+
+// NewRoleBackend creates a backend used by the role handler.
+func NewRoleBackend(b *APIBackend) *RoleBackend {
+	return &RoleBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Logger:           b.Logger.With(zap.String("handler", "role")),
+
+		RoleService:                b.RoleService,
+		UserResourceMappingService: b.UserResourceMappingService,
+		UserService:                b.UserService,
+	}
+}
+
+// RoleHandler is the handler for the role service.
+type RoleHandler struct {
+	*httprouter.Router
+
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	RoleService                influxdb.RoleService
+	UserResourceMappingService influxdb.UserResourceMappingService
+	UserService                influxdb.UserService
+}
+
+// NewRoleHandler creates a new RoleHandler. Users are assigned a role by
+// adding them as members of it.
+func NewRoleHandler(b *RoleBackend) *RoleHandler {
+	h := &RoleHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Logger:           b.Logger,
+
+		RoleService:                b.RoleService,
+		UserResourceMappingService: b.UserResourceMappingService,
+		UserService:                b.UserService,
+	}
+
+	h.HandlerFunc("GET", rolesPath, h.handleGetRoles)
+	h.HandlerFunc("POST", rolesPath, h.handlePostRole)
+	h.HandlerFunc("GET", rolesIDPath, h.handleGetRole)
+	h.HandlerFunc("PATCH", rolesIDPath, h.handlePatchRole)
+	h.HandlerFunc("DELETE", rolesIDPath, h.handleDeleteRole)
+
+	memberBackend := MemberBackend{
+		HTTPErrorHandler:           b.HTTPErrorHandler,
+		Logger:                     b.Logger.With(zap.String("handler", "member")),
+		ResourceType:               influxdb.RolesResourceType,
+		UserType:                   influxdb.Member,
+		UserResourceMappingService: b.UserResourceMappingService,
+		UserService:                b.UserService,
+	}
+	h.HandlerFunc("POST", rolesIDMembersPath, newPostMemberHandler(memberBackend))
+	h.HandlerFunc("GET", rolesIDMembersPath, newGetMembersHandler(memberBackend))
+	h.HandlerFunc("DELETE", rolesIDMembersIDPath, newDeleteMemberHandler(memberBackend))
+
+	return h
+}
+
+type roleLinks struct {
+	Self    string `json:"self"`
+	Org     string `json:"org"`
+	Members string `json:"members"`
+}
+
+type roleResponse struct {
+	*influxdb.Role
+	Links roleLinks `json:"links"`
+}
+
+func newRoleResponse(r *influxdb.Role) *roleResponse {
+	return &roleResponse{
+		Role: r,
+		Links: roleLinks{
+			Self:    fmt.Sprintf("/api/v2/roles/%s", r.ID),
+			Org:     fmt.Sprintf("/api/v2/orgs/%s", r.OrgID),
+			Members: fmt.Sprintf("/api/v2/roles/%s/members", r.ID),
+		},
+	}
+}
+
+type rolesResponse struct {
+	Roles []*roleResponse   `json:"roles"`
+	Links map[string]string `json:"links"`
+}
+
+func newRolesResponse(rs []*influxdb.Role) *rolesResponse {
+	resp := &rolesResponse{
+		Roles: make([]*roleResponse, 0, len(rs)),
+		Links: map[string]string{
+			"self": rolesPath,
+		},
+	}
+	for _, r := range rs {
+		resp.Roles = append(resp.Roles, newRoleResponse(r))
+	}
+	return resp
+}
+
+func decodeRoleFilter(ctx context.Context, r *http.Request) (*influxdb.RoleFilter, error) {
+	qp := r.URL.Query()
+	f := &influxdb.RoleFilter{}
+
+	if id := qp.Get("id"); id != "" {
+		i, err := influxdb.IDFromString(id)
+		if err != nil {
+			return nil, err
+		}
+		f.ID = i
+	}
+	if orgID := qp.Get("orgID"); orgID != "" {
+		i, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			return nil, err
+		}
+		f.OrgID = i
+	}
+	if name := qp.Get("name"); name != "" {
+		f.Name = &name
+	}
+	return f, nil
+}
+
+func (h *RoleHandler) handleGetRoles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeRoleFilter(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	rs, err := h.RoleService.FindRoles(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newRolesResponse(rs)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func requestRoleID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	urlID := params.ByName("id")
+	if urlID == "" {
+		return influxdb.InvalidID(), &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	id, err := influxdb.IDFromString(urlID)
+	if err != nil {
+		return influxdb.InvalidID(), err
+	}
+	return *id, nil
+}
+
+func (h *RoleHandler) handleGetRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestRoleID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	role, err := h.RoleService.FindRoleByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newRoleResponse(role)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodePostRoleRequest(ctx context.Context, r *http.Request) (*influxdb.Role, error) {
+	role := &influxdb.Role{}
+	if err := json.NewDecoder(r.Body).Decode(role); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode role",
+			Err:  err,
+		}
+	}
+
+	if err := role.Valid(); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (h *RoleHandler) handlePostRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	role, err := decodePostRoleRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.RoleService.CreateRole(ctx, role); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("role created", zap.String("role", fmt.Sprint(role.ID)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newRoleResponse(role)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+type patchRoleRequest struct {
+	id  influxdb.ID
+	upd influxdb.RoleUpdate
+}
+
+func decodePatchRoleRequest(ctx context.Context, r *http.Request) (*patchRoleRequest, error) {
+	id, err := requestRoleID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &patchRoleRequest{id: id}
+	if err := json.NewDecoder(r.Body).Decode(&req.upd); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode role update",
+			Err:  err,
+		}
+	}
+	return req, nil
+}
+
+func (h *RoleHandler) handlePatchRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodePatchRoleRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	role, err := h.RoleService.UpdateRole(ctx, req.id, req.upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("role updated", zap.String("role", fmt.Sprint(role.ID)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newRoleResponse(role)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *RoleHandler) handleDeleteRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestRoleID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.RoleService.DeleteRole(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("role deleted", zap.String("roleID", fmt.Sprint(id)))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RoleService connects to Influx via HTTP using tokens to manage roles.
+type RoleService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ influxdb.RoleService = (*RoleService)(nil)
+
+// FindRoleByID returns a single role by ID.
+func (s *RoleService) FindRoleByID(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	u, err := NewURL(s.Addr, roleIDPath(id))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var rr roleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return nil, err
+	}
+	return rr.Role, nil
+}
+
+// FindRoles returns a list of roles that match filter.
+func (s *RoleService) FindRoles(ctx context.Context, filter influxdb.RoleFilter) ([]*influxdb.Role, error) {
+	u, err := NewURL(s.Addr, rolesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = url.Values(filter.QueryParams()).Encode()
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var rr rolesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return nil, err
+	}
+
+	rs := make([]*influxdb.Role, 0, len(rr.Roles))
+	for _, r := range rr.Roles {
+		rs = append(rs, r.Role)
+	}
+	return rs, nil
+}
+
+// CreateRole creates a new role and sets r.ID with the new identifier.
+func (s *RoleService) CreateRole(ctx context.Context, r *influxdb.Role) error {
+	u, err := NewURL(s.Addr, rolesPath)
+	if err != nil {
+		return err
+	}
+
+	octets, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	var rr roleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return err
+	}
+	*r = *rr.Role
+	return nil
+}
+
+// UpdateRole updates a single role with changeset.
+func (s *RoleService) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	u, err := NewURL(s.Addr, roleIDPath(id))
+	if err != nil {
+		return nil, err
+	}
+
+	octets, err := json.Marshal(upd)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var rr roleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return nil, err
+	}
+	return rr.Role, nil
+}
+
+// DeleteRole removes a role by ID.
+func (s *RoleService) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	u, err := NewURL(s.Addr, roleIDPath(id))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckErrorStatus(http.StatusNoContent, resp)
+}
+
+func roleIDPath(id influxdb.ID) string {
+	return path.Join(rolesPath, id.String())
+}
@@ -0,0 +1,205 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap"
+)
+
+func newRoleHandler(svc influxdb.RoleService, urms influxdb.UserResourceMappingService, users influxdb.UserService) *RoleHandler {
+	return NewRoleHandler(&RoleBackend{
+		HTTPErrorHandler:           ErrorHandler(0),
+		Logger:                     zap.NewNop(),
+		RoleService:                svc,
+		UserResourceMappingService: urms,
+		UserService:                users,
+	})
+}
+
+func TestRoleHandler_handleGetRoles(t *testing.T) {
+	svc := mock.NewRoleService()
+	svc.FindRolesFn = func(ctx context.Context, f influxdb.RoleFilter) ([]*influxdb.Role, error) {
+		if f.OrgID == nil || *f.OrgID != 0x1 {
+			t.Errorf("unexpected filter %+v", f)
+		}
+		orgID := influxdb.ID(0x1)
+		return []*influxdb.Role{
+			{
+				ID:    0x10,
+				OrgID: 0x1,
+				Name:  "readers",
+				Permissions: []influxdb.Permission{
+					{
+						Action:   influxdb.ReadAction,
+						Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &orgID},
+					},
+				},
+			},
+		}, nil
+	}
+
+	r := httptest.NewRequest("GET", "http://any.url/api/v2/roles?orgID=0000000000000001", nil)
+	w := httptest.NewRecorder()
+	newRoleHandler(svc, mock.NewUserResourceMappingService(), mock.NewUserService()).ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", res.StatusCode, body)
+	}
+
+	exp := `
+{
+  "links": {
+    "self": "/api/v2/roles"
+  },
+  "roles": [
+    {
+      "id": "0000000000000010",
+      "orgID": "0000000000000001",
+      "name": "readers",
+      "permissions": [
+        {
+          "action": "read",
+          "resource": {
+            "type": "buckets",
+            "orgID": "0000000000000001"
+          }
+        }
+      ],
+      "createdAt": "0001-01-01T00:00:00Z",
+      "updatedAt": "0001-01-01T00:00:00Z",
+      "links": {
+        "self": "/api/v2/roles/0000000000000010",
+        "org": "/api/v2/orgs/0000000000000001",
+        "members": "/api/v2/roles/0000000000000010/members"
+      }
+    }
+  ]
+}`
+	if eq, diff, err := jsonEqual(string(body), exp); err != nil {
+		t.Fatalf("error unmarshaling json %v", err)
+	} else if !eq {
+		t.Errorf("unexpected response ***%s***", diff)
+	}
+}
+
+func TestRoleHandler_handlePostRole_Invalid(t *testing.T) {
+	svc := mock.NewRoleService()
+	svc.CreateRoleFn = func(ctx context.Context, r *influxdb.Role) error {
+		t.Error("unexpected call to CreateRole")
+		return nil
+	}
+
+	body := `{"name": "readers", "orgID": "0000000000000001", "permissions": [{"action": "read", "resource": {"type": "buckets", "orgID": "0000000000000002"}}]}`
+	r := httptest.NewRequest("POST", "http://any.url/api/v2/roles", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	newRoleHandler(svc, mock.NewUserResourceMappingService(), mock.NewUserService()).ServeHTTP(w, r)
+
+	if got, exp := w.Result().StatusCode, http.StatusBadRequest; got != exp {
+		t.Errorf("unexpected status -got/+exp\n%d\n%d", got, exp)
+	}
+}
+
+func TestRoleService(t *testing.T) {
+	ctx := context.Background()
+
+	store := kv.NewService(inmem.NewKVStore())
+	if err := store.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "edge"}
+	if err := store.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "analyst"}
+	if err := store.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(newRoleHandler(store, store, store))
+	defer server.Close()
+	client := &RoleService{Addr: server.URL}
+
+	r := &influxdb.Role{
+		OrgID: org.ID,
+		Name:  "readers",
+		Permissions: []influxdb.Permission{
+			{
+				Action:   influxdb.ReadAction,
+				Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &org.ID},
+			},
+		},
+	}
+	if err := client.CreateRole(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+	if !r.ID.Valid() {
+		t.Errorf("unexpected role after create %+v", r)
+	}
+
+	name := "analysts"
+	updated, err := client.UpdateRole(ctx, r.ID, influxdb.RoleUpdate{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != name || len(updated.Permissions) != 1 {
+		t.Errorf("unexpected role after update %+v", updated)
+	}
+
+	rs, err := client.FindRoles(ctx, influxdb.RoleFilter{OrgID: &org.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 1 || rs[0].ID != r.ID {
+		t.Errorf("unexpected roles %+v", rs)
+	}
+
+	// Users are assigned the role as its members.
+	body := fmt.Sprintf(`{"id": %q}`, user.ID)
+	res, err := http.Post(server.URL+"/api/v2/roles/"+r.ID.String()+"/members", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("unexpected status adding member %d", res.StatusCode)
+	}
+	ms, _, err := store.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceType: influxdb.RolesResourceType,
+		ResourceID:   r.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) != 1 || ms[0].UserID != user.ID {
+		t.Errorf("unexpected members %+v", ms)
+	}
+
+	if err := client.DeleteRole(ctx, r.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.FindRoleByID(ctx, r.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	ms, _, err = store.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceType: influxdb.RolesResourceType,
+		ResourceID:   r.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) != 0 {
+		t.Errorf("expected members of deleted role to be removed, got %+v", ms)
+	}
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"net"
 	"net/http"
 
 	platform "github.com/influxdata/influxdb"
@@ -15,8 +16,9 @@ type SessionBackend struct {
 	Logger *zap.Logger
 	platform.HTTPErrorHandler
 
-	PasswordsService platform.PasswordsService
-	SessionService   platform.SessionService
+	PasswordsService     platform.PasswordsService
+	SessionService       platform.SessionService
+	SigninLockoutService platform.SigninLockoutService
 }
 
 // NewSessionBackend creates a new SessionBackend with associated logger.
@@ -25,8 +27,9 @@ func NewSessionBackend(b *APIBackend) *SessionBackend {
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		Logger:           b.Logger.With(zap.String("handler", "session")),
 
-		PasswordsService: b.PasswordsService,
-		SessionService:   b.SessionService,
+		PasswordsService:     b.PasswordsService,
+		SessionService:       b.SessionService,
+		SigninLockoutService: b.SigninLockoutService,
 	}
 }
 
@@ -38,6 +41,8 @@ type SessionHandler struct {
 
 	PasswordsService platform.PasswordsService
 	SessionService   platform.SessionService
+	// SigninLockoutService, if set, locks out signins after repeated failures.
+	SigninLockoutService platform.SigninLockoutService
 }
 
 // NewSessionHandler returns a new instance of SessionHandler.
@@ -47,8 +52,9 @@ func NewSessionHandler(b *SessionBackend) *SessionHandler {
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		Logger:           b.Logger,
 
-		PasswordsService: b.PasswordsService,
-		SessionService:   b.SessionService,
+		PasswordsService:     b.PasswordsService,
+		SessionService:       b.SessionService,
+		SigninLockoutService: b.SigninLockoutService,
 	}
 
 	h.HandlerFunc("POST", "/api/v2/signin", h.handleSignin)
@@ -66,11 +72,25 @@ func (h *SessionHandler) handleSignin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	addr := signinAddr(r)
+	if h.SigninLockoutService != nil {
+		if err := h.SigninLockoutService.CheckSignin(ctx, req.Username, addr); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+
 	if err := h.PasswordsService.ComparePassword(ctx, req.Username, req.Password); err != nil {
 		// Don't log here, it should already be handled by the service
+		if h.SigninLockoutService != nil && platform.ErrorCode(err) == platform.EForbidden {
+			h.SigninLockoutService.SigninFailed(ctx, req.Username, addr)
+		}
 		UnauthorizedError(ctx, h, w)
 		return
 	}
+	if h.SigninLockoutService != nil {
+		h.SigninLockoutService.SigninSucceeded(ctx, req.Username)
+	}
 
 	s, e := h.SessionService.CreateSession(ctx, req.Username)
 	if e != nil {
@@ -82,6 +102,16 @@ func (h *SessionHandler) handleSignin(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// signinAddr returns the address signins of the request are counted against.
+// It is the address of the peer, as headers set by proxies can be forged.
+func signinAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 type signinRequest struct {
 	Username string
 	Password string
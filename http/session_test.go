@@ -11,6 +11,7 @@ import (
 
 	platform "github.com/influxdata/influxdb"
 	platformhttp "github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/lockout"
 	"github.com/influxdata/influxdb/mock"
 )
 
@@ -99,3 +100,51 @@ func TestSessionHandler_handleSignin(t *testing.T) {
 		})
 	}
 }
+
+func TestSessionHandler_handleSignin_Lockout(t *testing.T) {
+	b := NewMockSessionBackend()
+	b.HTTPErrorHandler = platformhttp.ErrorHandler(0)
+	b.PasswordsService = &mock.PasswordsService{
+		ComparePasswordFn: func(ctx context.Context, name, password string) error {
+			if password != "supersecret" {
+				return &platform.Error{Code: platform.EForbidden, Msg: "your username or password is incorrect"}
+			}
+			return nil
+		},
+	}
+	b.SessionService = &mock.SessionService{
+		CreateSessionFn: func(context.Context, string) (*platform.Session, error) {
+			return &platform.Session{Key: "abc123xyz", ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+	}
+	b.SigninLockoutService = lockout.NewService(lockout.Config{
+		UserAttempts: 2,
+		AddrAttempts: 10,
+		Duration:     time.Minute,
+		MaxDuration:  time.Hour,
+	})
+	h := platformhttp.NewSessionHandler(b)
+
+	signin := func(user, password string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/signin", nil)
+		r.SetBasicAuth(user, password)
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	for i, want := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		if got := signin("user1", "guess"); got != want {
+			t.Errorf("signin %d: got status %d want %d", i, got, want)
+		}
+	}
+
+	// The correct password is rejected while the user is locked out.
+	if got, want := signin("user1", "supersecret"), http.StatusTooManyRequests; got != want {
+		t.Errorf("signin of locked out user: got status %d want %d", got, want)
+	}
+	// Other users are not locked out.
+	if got, want := signin("user2", "supersecret"), http.StatusNoContent; got != want {
+		t.Errorf("signin of other user: got status %d want %d", got, want)
+	}
+}
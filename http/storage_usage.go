@@ -0,0 +1,144 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+const (
+	bucketsIDUsagePath       = "/api/v2/buckets/:id/usage"
+	organizationsIDUsagePath = "/api/v2/orgs/:id/usage"
+)
+
+// storageUsageResponse is the disk space used by the data of an organization
+// or a bucket, and its storage quota, if any.
+type storageUsageResponse struct {
+	influxdb.StorageUsage
+	Bytes      int64 `json:"bytes"`
+	QuotaBytes int64 `json:"quotaBytes,omitempty"`
+}
+
+func newStorageUsageResponse(u *influxdb.StorageUsage, quotaBytes int64) *storageUsageResponse {
+	return &storageUsageResponse{
+		StorageUsage: *u,
+		Bytes:        u.Bytes(),
+		QuotaBytes:   quotaBytes,
+	}
+}
+
+// handleGetBucketUsage is the HTTP handler for the GET /api/v2/buckets/:id/usage route.
+func (h *BucketHandler) handleGetBucketUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetBucketRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	b, err := h.BucketService.FindBucketByID(ctx, req.BucketID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	u, err := h.StorageUsageService.FindBucketStorageUsage(ctx, b.OrgID, b.ID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newStorageUsageResponse(u, b.StorageQuotaBytes)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleGetOrgUsage is the HTTP handler for the GET /api/v2/orgs/:id/usage route.
+func (h *OrgHandler) handleGetOrgUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetOrgRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	o, err := h.OrganizationService.FindOrganizationByID(ctx, req.OrgID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	u, err := h.StorageUsageService.FindOrganizationStorageUsage(ctx, o.ID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newStorageUsageResponse(u, o.StorageQuotaBytes)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// StorageUsageService connects to Influx via HTTP using tokens to report the
+// disk space used by organizations and buckets.
+type StorageUsageService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ influxdb.StorageUsageService = (*StorageUsageService)(nil)
+
+// FindOrganizationStorageUsage returns the disk space used by an organization.
+func (s *StorageUsageService) FindOrganizationStorageUsage(ctx context.Context, orgID influxdb.ID) (*influxdb.StorageUsage, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.findStorageUsage(path.Join(organizationIDPath(orgID), "usage"))
+}
+
+// FindBucketStorageUsage returns the disk space used by a bucket.
+func (s *StorageUsageService) FindBucketStorageUsage(ctx context.Context, orgID, bucketID influxdb.ID) (*influxdb.StorageUsage, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.findStorageUsage(path.Join(bucketsPath, bucketID.String(), "usage"))
+}
+
+func (s *StorageUsageService) findStorageUsage(p string) (*influxdb.StorageUsage, error) {
+	u, err := NewURL(s.Addr, p)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var res storageUsageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	return &res.StorageUsage, nil
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestService_handleGetBucketUsage(t *testing.T) {
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		return &platform.Bucket{
+			ID:                id,
+			OrgID:             platform.ID(2),
+			Name:              "b1",
+			StorageQuotaBytes: 1024,
+		}, nil
+	}
+
+	usage := mock.NewStorageUsageService()
+	usage.FindBucketStorageUsageFn = func(ctx context.Context, orgID, bucketID platform.ID) (*platform.StorageUsage, error) {
+		if orgID != platform.ID(2) || bucketID.String() != "020f755c3c082000" {
+			t.Errorf("unexpected usage lookup of org %s bucket %s", orgID, bucketID)
+		}
+		return &platform.StorageUsage{TSMBytes: 100, IndexBytes: 20}, nil
+	}
+
+	bucketBackend := NewMockBucketBackend()
+	bucketBackend.HTTPErrorHandler = ErrorHandler(0)
+	bucketBackend.BucketService = buckets
+	bucketBackend.StorageUsageService = usage
+	h := NewBucketHandler(bucketBackend)
+
+	r := httptest.NewRequest("GET", "http://any.url", nil)
+	r = r.WithContext(context.WithValue(
+		context.Background(),
+		httprouter.ParamsKey,
+		httprouter.Params{
+			{
+				Key:   "id",
+				Value: "020f755c3c082000",
+			},
+		}))
+
+	w := httptest.NewRecorder()
+
+	h.handleGetBucketUsage(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("handleGetBucketUsage() = %v, want %v: %s", res.StatusCode, http.StatusOK, body)
+	}
+	want := `{"tsmBytes":100,"indexBytes":20,"bytes":120,"quotaBytes":1024}`
+	if eq, diff, _ := jsonEqual(string(body), want); !eq {
+		t.Errorf("handleGetBucketUsage() = ***%v***", diff)
+	}
+}
+
+func TestService_handleGetOrgUsage(t *testing.T) {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationByIDF = func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+		return &platform.Organization{ID: id, Name: "o1"}, nil
+	}
+
+	usage := mock.NewStorageUsageService()
+	usage.FindOrganizationStorageUsageFn = func(ctx context.Context, orgID platform.ID) (*platform.StorageUsage, error) {
+		return &platform.StorageUsage{TSMBytes: 300, IndexBytes: 40}, nil
+	}
+
+	orgBackend := NewMockOrgBackend()
+	orgBackend.HTTPErrorHandler = ErrorHandler(0)
+	orgBackend.OrganizationService = orgs
+	orgBackend.StorageUsageService = usage
+	h := NewOrgHandler(orgBackend)
+
+	r := httptest.NewRequest("GET", "http://any.url", nil)
+	r = r.WithContext(context.WithValue(
+		context.Background(),
+		httprouter.ParamsKey,
+		httprouter.Params{
+			{
+				Key:   "id",
+				Value: "020f755c3c082000",
+			},
+		}))
+
+	w := httptest.NewRecorder()
+
+	h.handleGetOrgUsage(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("handleGetOrgUsage() = %v, want %v: %s", res.StatusCode, http.StatusOK, body)
+	}
+	want := `{"tsmBytes":300,"indexBytes":40,"bytes":340}`
+	if eq, diff, _ := jsonEqual(string(body), want); !eq {
+		t.Errorf("handleGetOrgUsage() = ***%v***", diff)
+	}
+}
@@ -0,0 +1,387 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/julienschmidt/httprouter"
+)
+
+type backfillResponse struct {
+	Links map[string]string `json:"links,omitempty"`
+	platform.Backfill
+}
+
+func newBackfillResponse(b platform.Backfill) backfillResponse {
+	return backfillResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/tasks/%s/backfill/%s", b.TaskID, b.ID),
+			"task": fmt.Sprintf("/api/v2/tasks/%s", b.TaskID),
+			"runs": fmt.Sprintf("/api/v2/tasks/%s/runs", b.TaskID),
+		},
+		Backfill: b,
+	}
+}
+
+type backfillsResponse struct {
+	Links     map[string]string   `json:"links"`
+	Backfills []*backfillResponse `json:"backfills"`
+}
+
+func newBackfillsResponse(bs []*platform.Backfill, taskID platform.ID) backfillsResponse {
+	r := backfillsResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/tasks/%s/backfill", taskID),
+			"task": fmt.Sprintf("/api/v2/tasks/%s", taskID),
+		},
+		Backfills: make([]*backfillResponse, len(bs)),
+	}
+
+	for i := range bs {
+		b := newBackfillResponse(*bs[i])
+		r.Backfills[i] = &b
+	}
+	return r
+}
+
+func (h *TaskHandler) handlePostBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodePostBackfillRequest(ctx, r)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	b, err := h.BackfillService.CreateBackfill(ctx, req.TaskID, req.Create)
+	if err != nil {
+		err := &platform.Error{
+			Err: err,
+			Msg: "failed to create backfill",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newBackfillResponse(*b)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+type postBackfillRequest struct {
+	TaskID platform.ID
+	Create platform.BackfillCreate
+}
+
+func decodePostBackfillRequest(ctx context.Context, r *http.Request) (*postBackfillRequest, error) {
+	taskID, err := decodeBackfillTaskID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &postBackfillRequest{TaskID: taskID}
+	if err := json.NewDecoder(r.Body).Decode(&req.Create); err != nil {
+		return nil, err
+	}
+
+	if err := req.Create.Validate(); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (h *TaskHandler) handleGetBackfills(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := decodeBackfillTaskID(ctx)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	bs, err := h.BackfillService.FindBackfills(ctx, platform.BackfillFilter{Task: &taskID})
+	if err != nil {
+		err := &platform.Error{
+			Err: err,
+			Msg: "failed to find backfills",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBackfillsResponse(bs, taskID)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+func (h *TaskHandler) handleGetBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeBackfillIDRequest(ctx)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	b, err := h.BackfillService.FindBackfillByID(ctx, req.TaskID, req.BackfillID)
+	if err != nil {
+		err := &platform.Error{
+			Err: err,
+			Msg: "failed to find backfill",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBackfillResponse(*b)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+func (h *TaskHandler) handleCancelBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeBackfillIDRequest(ctx)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	b, err := h.BackfillService.CancelBackfill(ctx, req.TaskID, req.BackfillID)
+	if err != nil {
+		err := &platform.Error{
+			Err: err,
+			Msg: "failed to cancel backfill",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBackfillResponse(*b)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
+
+type backfillIDRequest struct {
+	TaskID     platform.ID
+	BackfillID platform.ID
+}
+
+func decodeBackfillIDRequest(ctx context.Context) (*backfillIDRequest, error) {
+	taskID, err := decodeBackfillTaskID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	params := httprouter.ParamsFromContext(ctx)
+	bid := params.ByName("bid")
+	if bid == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "you must provide a backfill ID",
+		}
+	}
+
+	var backfillID platform.ID
+	if err := backfillID.DecodeFromString(bid); err != nil {
+		return nil, err
+	}
+
+	return &backfillIDRequest{
+		TaskID:     taskID,
+		BackfillID: backfillID,
+	}, nil
+}
+
+func decodeBackfillTaskID(ctx context.Context) (platform.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	tid := params.ByName("id")
+	if tid == "" {
+		return 0, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "you must provide a task ID",
+		}
+	}
+
+	var taskID platform.ID
+	if err := taskID.DecodeFromString(tid); err != nil {
+		return 0, err
+	}
+	return taskID, nil
+}
+
+var _ platform.BackfillService = (*BackfillService)(nil)
+
+// BackfillService connects to Influx via HTTP using tokens to manage task backfills.
+type BackfillService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+func taskIDBackfillPath(taskID platform.ID) string {
+	return path.Join(tasksPath, taskID.String(), "backfill")
+}
+
+func taskIDBackfillIDPath(taskID, id platform.ID) string {
+	return path.Join(tasksPath, taskID.String(), "backfill", id.String())
+}
+
+// CreateBackfill starts queueing the runs of the task with taskID within the range of c.
+func (s *BackfillService) CreateBackfill(ctx context.Context, taskID platform.ID, c platform.BackfillCreate) (*platform.Backfill, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := NewURL(s.Addr, taskIDBackfillPath(taskID))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	return s.do(req, u.Scheme)
+}
+
+// FindBackfillByID returns a single backfill of a task.
+func (s *BackfillService) FindBackfillByID(ctx context.Context, taskID, id platform.ID) (*platform.Backfill, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := NewURL(s.Addr, taskIDBackfillIDPath(taskID, id))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	return s.do(req, u.Scheme)
+}
+
+// FindBackfills returns the backfills of the task of filter, newest first.
+func (s *BackfillService) FindBackfills(ctx context.Context, filter platform.BackfillFilter) ([]*platform.Backfill, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if filter.Task == nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "a task ID is required to list backfills",
+		}
+	}
+
+	u, err := NewURL(s.Addr, taskIDBackfillPath(*filter.Task))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var bsr backfillsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&bsr); err != nil {
+		return nil, err
+	}
+
+	bs := make([]*platform.Backfill, len(bsr.Backfills))
+	for i := range bsr.Backfills {
+		bs[i] = &bsr.Backfills[i].Backfill
+	}
+	return bs, nil
+}
+
+// CancelBackfill stops queueing runs for a backfill.
+func (s *BackfillService) CancelBackfill(ctx context.Context, taskID, id platform.ID) (*platform.Backfill, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := NewURL(s.Addr, taskIDBackfillIDPath(taskID, id))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+
+	return s.do(req, u.Scheme)
+}
+
+func (s *BackfillService) do(req *http.Request, scheme string) (*platform.Backfill, error) {
+	hc := NewClient(scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var br backfillResponse
+	if err := json.NewDecoder(resp.Body).Decode(&br); err != nil {
+		return nil, err
+	}
+	return &br.Backfill, nil
+}
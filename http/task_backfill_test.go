@@ -0,0 +1,133 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	platformtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBackfillService(t *testing.T) {
+	const taskID, backfillID = platform.ID(0xCCCCCC), platform.ID(0xAAAAAA)
+	start := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	stop := start.Add(24 * time.Hour)
+
+	backfill := func(status string) *platform.Backfill {
+		return &platform.Backfill{
+			ID:             backfillID,
+			TaskID:         taskID,
+			OrganizationID: 1,
+			Start:          start.Format(time.RFC3339),
+			Stop:           stop.Format(time.RFC3339),
+			Concurrency:    4,
+			Status:         status,
+			Total:          24,
+		}
+	}
+
+	svc := mock.NewBackfillService()
+	svc.CreateBackfillFn = func(_ context.Context, id platform.ID, c platform.BackfillCreate) (*platform.Backfill, error) {
+		if id != taskID {
+			return nil, platform.ErrTaskNotFound
+		}
+		if !c.Start.Equal(start) || !c.Stop.Equal(stop) || c.Concurrency != 4 {
+			t.Errorf("unexpected backfill create %+v", c)
+		}
+		return backfill(platform.BackfillStatusRunning), nil
+	}
+	svc.FindBackfillByIDFn = func(_ context.Context, tid, id platform.ID) (*platform.Backfill, error) {
+		if tid != taskID || id != backfillID {
+			return nil, platform.ErrBackfillNotFound
+		}
+		return backfill(platform.BackfillStatusRunning), nil
+	}
+	svc.FindBackfillsFn = func(_ context.Context, filter platform.BackfillFilter) ([]*platform.Backfill, error) {
+		if filter.Task == nil || *filter.Task != taskID {
+			return []*platform.Backfill{}, nil
+		}
+		return []*platform.Backfill{backfill(platform.BackfillStatusRunning)}, nil
+	}
+	svc.CancelBackfillFn = func(_ context.Context, tid, id platform.ID) (*platform.Backfill, error) {
+		if tid != taskID || id != backfillID {
+			return nil, platform.ErrBackfillNotFound
+		}
+		return backfill(platform.BackfillStatusCanceled), nil
+	}
+
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.HTTPErrorHandler = ErrorHandler(0)
+	taskBackend.BackfillService = svc
+	server := httptest.NewServer(NewTaskHandler(taskBackend))
+	defer server.Close()
+
+	client := &BackfillService{Addr: server.URL}
+	ctx := context.Background()
+
+	b, err := client.CreateBackfill(ctx, taskID, platform.BackfillCreate{Start: start, Stop: stop, Concurrency: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(b, backfill(platform.BackfillStatusRunning)); diff != "" {
+		t.Errorf("created backfill is different -got/+want\ndiff %s", diff)
+	}
+
+	if _, err := client.CreateBackfill(ctx, taskID, platform.BackfillCreate{Start: stop, Stop: start}); platform.ErrorCode(err) != platform.EInvalid {
+		t.Errorf("expected invalid error creating a backfill with stop before start, got %v", err)
+	}
+
+	b, err = client.FindBackfillByID(ctx, taskID, backfillID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(b, backfill(platform.BackfillStatusRunning)); diff != "" {
+		t.Errorf("found backfill is different -got/+want\ndiff %s", diff)
+	}
+
+	if _, err := client.FindBackfillByID(ctx, taskID, backfillID+1); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+
+	bs, err := client.FindBackfills(ctx, platform.BackfillFilter{Task: platformtesting.IDPtr(taskID)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(bs, []*platform.Backfill{backfill(platform.BackfillStatusRunning)}); diff != "" {
+		t.Errorf("found backfills are different -got/+want\ndiff %s", diff)
+	}
+
+	b, err = client.CancelBackfill(ctx, taskID, backfillID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Status != platform.BackfillStatusCanceled {
+		t.Errorf("expected canceled backfill, got %+v", b)
+	}
+}
+
+func TestTaskHandler_handlePostBackfill_InvalidBody(t *testing.T) {
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.HTTPErrorHandler = ErrorHandler(0)
+	taskBackend.BackfillService = mock.NewBackfillService()
+	h := NewTaskHandler(taskBackend)
+
+	for _, body := range []string{
+		`{"start": "yesterday"}`,
+		`{"stop": "2019-01-01T00:00:00Z"}`,
+		`{"start": "2019-01-01T00:00:00Z", "stop": "2019-01-02T00:00:00Z", "concurrency": -1}`,
+	} {
+		r := httptest.NewRequest("POST", "/api/v2/tasks/0000000000cccccc/backfill", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %s: expected status %d, got %d: %s", body, http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+// taskDependencyNode is a task in a dependency graph.
+type taskDependencyNode struct {
+	Links  map[string]string `json:"links"`
+	ID     platform.ID       `json:"id"`
+	Name   string            `json:"name"`
+	Status string            `json:"status"`
+}
+
+// taskDependencyEdge is a dependency of the task To on the task From.
+// A successful run of From triggers a run of To.
+type taskDependencyEdge struct {
+	From platform.ID `json:"from"`
+	To   platform.ID `json:"to"`
+}
+
+type taskDependenciesResponse struct {
+	Links map[string]string    `json:"links"`
+	Nodes []taskDependencyNode `json:"nodes"`
+	Edges []taskDependencyEdge `json:"edges"`
+}
+
+// newTaskDependenciesResponse returns the graph of the tasks connected to task by dependencies,
+// out of the tasks ts of its organization.
+func newTaskDependenciesResponse(task *platform.Task, ts []*platform.Task) taskDependenciesResponse {
+	taskID := task.ID
+	if !containsTask(ts, taskID) {
+		ts = append(ts, task)
+	}
+
+	tasks := make(map[platform.ID]*platform.Task, len(ts))
+	dependents := make(map[platform.ID][]platform.ID)
+	for _, t := range ts {
+		tasks[t.ID] = t
+		for _, dep := range t.DependsOn {
+			dependents[dep] = append(dependents[dep], t.ID)
+		}
+	}
+
+	// Walk the dependencies in both directions from the task.
+	connected := map[platform.ID]bool{taskID: true}
+	queue := []platform.ID{taskID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		var next []platform.ID
+		if t, ok := tasks[id]; ok {
+			next = append(next, t.DependsOn...)
+		}
+		next = append(next, dependents[id]...)
+		for _, n := range next {
+			if _, ok := tasks[n]; ok && !connected[n] {
+				connected[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+
+	res := taskDependenciesResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/tasks/%s/dependencies", taskID),
+			"task": fmt.Sprintf("/api/v2/tasks/%s", taskID),
+		},
+		Nodes: []taskDependencyNode{},
+		Edges: []taskDependencyEdge{},
+	}
+	for _, t := range ts {
+		if !connected[t.ID] {
+			continue
+		}
+		res.Nodes = append(res.Nodes, taskDependencyNode{
+			Links: map[string]string{
+				"self": fmt.Sprintf("/api/v2/tasks/%s", t.ID),
+			},
+			ID:     t.ID,
+			Name:   t.Name,
+			Status: t.Status,
+		})
+		for _, dep := range t.DependsOn {
+			if connected[dep] {
+				res.Edges = append(res.Edges, taskDependencyEdge{From: dep, To: t.ID})
+			}
+		}
+	}
+
+	sort.Slice(res.Nodes, func(i, j int) bool { return res.Nodes[i].ID < res.Nodes[j].ID })
+	sort.Slice(res.Edges, func(i, j int) bool {
+		if res.Edges[i].From == res.Edges[j].From {
+			return res.Edges[i].To < res.Edges[j].To
+		}
+		return res.Edges[i].From < res.Edges[j].From
+	})
+	return res
+}
+
+func containsTask(ts []*platform.Task, id platform.ID) bool {
+	for _, t := range ts {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// handleGetTaskDependencies returns the dependency graph of the tasks connected to a task.
+func (h *TaskHandler) handleGetTaskDependencies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetTaskRequest(ctx, r)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	task, err := h.TaskService.FindTaskByID(ctx, req.TaskID)
+	if err != nil {
+		err = &platform.Error{
+			Err:  err,
+			Code: platform.ENotFound,
+			Msg:  "failed to find task",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	// Dependencies never cross organizations, so the graph is within the tasks of the organization.
+	var tasks []*platform.Task
+	filter := platform.TaskFilter{
+		OrganizationID: &task.OrganizationID,
+		Limit:          platform.TaskMaxPageSize,
+	}
+	for {
+		ts, _, err := h.TaskService.FindTasks(ctx, filter)
+		if err != nil {
+			err = &platform.Error{
+				Err: err,
+				Msg: "failed to find tasks",
+			}
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		tasks = append(tasks, ts...)
+		if len(ts) < filter.Limit {
+			break
+		}
+		after := ts[len(ts)-1].ID
+		filter.After = &after
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskDependenciesResponse(task, tasks)); err != nil {
+		logEncodingError(h.logger, r, err)
+		return
+	}
+}
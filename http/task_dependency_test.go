@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestTaskHandler_handleGetTaskDependencies(t *testing.T) {
+	// raw -> 1m -> 1h, and an unrelated task.
+	tasks := []*platform.Task{
+		{ID: 1, OrganizationID: 1, Name: "raw", Status: "active"},
+		{ID: 2, OrganizationID: 1, Name: "1m", Status: "active", DependsOn: []platform.ID{1}},
+		{ID: 3, OrganizationID: 1, Name: "1h", Status: "inactive", DependsOn: []platform.ID{2}},
+		{ID: 4, OrganizationID: 1, Name: "other", Status: "active"},
+	}
+
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.HTTPErrorHandler = ErrorHandler(0)
+	taskBackend.TaskService = &mock.TaskService{
+		FindTaskByIDFn: func(_ context.Context, id platform.ID) (*platform.Task, error) {
+			for _, t := range tasks {
+				if t.ID == id {
+					return t, nil
+				}
+			}
+			return nil, platform.ErrTaskNotFound
+		},
+		FindTasksFn: func(_ context.Context, f platform.TaskFilter) ([]*platform.Task, int, error) {
+			if f.OrganizationID == nil || *f.OrganizationID != 1 {
+				return nil, 0, nil
+			}
+			return tasks, len(tasks), nil
+		},
+	}
+	h := NewTaskHandler(taskBackend)
+
+	r := httptest.NewRequest("GET", "/api/v2/tasks/0000000000000002/dependencies", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, res.StatusCode, body)
+	}
+
+	want := `
+{
+  "links": {
+    "self": "/api/v2/tasks/0000000000000002/dependencies",
+    "task": "/api/v2/tasks/0000000000000002"
+  },
+  "nodes": [
+    {
+      "links": {"self": "/api/v2/tasks/0000000000000001"},
+      "id": "0000000000000001",
+      "name": "raw",
+      "status": "active"
+    },
+    {
+      "links": {"self": "/api/v2/tasks/0000000000000002"},
+      "id": "0000000000000002",
+      "name": "1m",
+      "status": "active"
+    },
+    {
+      "links": {"self": "/api/v2/tasks/0000000000000003"},
+      "id": "0000000000000003",
+      "name": "1h",
+      "status": "inactive"
+    }
+  ],
+  "edges": [
+    {"from": "0000000000000001", "to": "0000000000000002"},
+    {"from": "0000000000000002", "to": "0000000000000003"}
+  ]
+}
+`
+	if eq, diff, err := jsonEqual(string(body), want); err != nil || !eq {
+		t.Errorf("unexpected dependency graph: %v\n%s", err, diff)
+	}
+
+	r = httptest.NewRequest("GET", "/api/v2/tasks/0000000000000009/dependencies", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for missing task, got %d", http.StatusNotFound, w.Code)
+	}
+}
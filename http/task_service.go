@@ -34,6 +34,7 @@ type TaskBackend struct {
 	Logger *zap.Logger
 
 	TaskService                platform.TaskService
+	BackfillService            platform.BackfillService
 	AuthorizationService       platform.AuthorizationService
 	OrganizationService        platform.OrganizationService
 	UserResourceMappingService platform.UserResourceMappingService
@@ -48,6 +49,7 @@ func NewTaskBackend(b *APIBackend) *TaskBackend {
 		HTTPErrorHandler:           b.HTTPErrorHandler,
 		Logger:                     b.Logger.With(zap.String("handler", "task")),
 		TaskService:                b.TaskService,
+		BackfillService:            b.BackfillService,
 		AuthorizationService:       b.AuthorizationService,
 		OrganizationService:        b.OrganizationService,
 		UserResourceMappingService: b.UserResourceMappingService,
@@ -64,6 +66,7 @@ type TaskHandler struct {
 	logger *zap.Logger
 
 	TaskService                platform.TaskService
+	BackfillService            platform.BackfillService
 	AuthorizationService       platform.AuthorizationService
 	OrganizationService        platform.OrganizationService
 	UserResourceMappingService platform.UserResourceMappingService
@@ -73,19 +76,22 @@ type TaskHandler struct {
 }
 
 const (
-	tasksPath              = "/api/v2/tasks"
-	tasksIDPath            = "/api/v2/tasks/:id"
-	tasksIDLogsPath        = "/api/v2/tasks/:id/logs"
-	tasksIDMembersPath     = "/api/v2/tasks/:id/members"
-	tasksIDMembersIDPath   = "/api/v2/tasks/:id/members/:userID"
-	tasksIDOwnersPath      = "/api/v2/tasks/:id/owners"
-	tasksIDOwnersIDPath    = "/api/v2/tasks/:id/owners/:userID"
-	tasksIDRunsPath        = "/api/v2/tasks/:id/runs"
-	tasksIDRunsIDPath      = "/api/v2/tasks/:id/runs/:rid"
-	tasksIDRunsIDLogsPath  = "/api/v2/tasks/:id/runs/:rid/logs"
-	tasksIDRunsIDRetryPath = "/api/v2/tasks/:id/runs/:rid/retry"
-	tasksIDLabelsPath      = "/api/v2/tasks/:id/labels"
-	tasksIDLabelsIDPath    = "/api/v2/tasks/:id/labels/:lid"
+	tasksPath               = "/api/v2/tasks"
+	tasksIDPath             = "/api/v2/tasks/:id"
+	tasksIDLogsPath         = "/api/v2/tasks/:id/logs"
+	tasksIDMembersPath      = "/api/v2/tasks/:id/members"
+	tasksIDMembersIDPath    = "/api/v2/tasks/:id/members/:userID"
+	tasksIDOwnersPath       = "/api/v2/tasks/:id/owners"
+	tasksIDOwnersIDPath     = "/api/v2/tasks/:id/owners/:userID"
+	tasksIDRunsPath         = "/api/v2/tasks/:id/runs"
+	tasksIDRunsIDPath       = "/api/v2/tasks/:id/runs/:rid"
+	tasksIDRunsIDLogsPath   = "/api/v2/tasks/:id/runs/:rid/logs"
+	tasksIDRunsIDRetryPath  = "/api/v2/tasks/:id/runs/:rid/retry"
+	tasksIDLabelsPath       = "/api/v2/tasks/:id/labels"
+	tasksIDLabelsIDPath     = "/api/v2/tasks/:id/labels/:lid"
+	tasksIDBackfillPath     = "/api/v2/tasks/:id/backfill"
+	tasksIDBackfillIDPath   = "/api/v2/tasks/:id/backfill/:bid"
+	tasksIDDependenciesPath = "/api/v2/tasks/:id/dependencies"
 )
 
 // NewTaskHandler returns a new instance of TaskHandler.
@@ -96,6 +102,7 @@ func NewTaskHandler(b *TaskBackend) *TaskHandler {
 		logger:           b.Logger,
 
 		TaskService:                b.TaskService,
+		BackfillService:            b.BackfillService,
 		AuthorizationService:       b.AuthorizationService,
 		OrganizationService:        b.OrganizationService,
 		UserResourceMappingService: b.UserResourceMappingService,
@@ -144,6 +151,13 @@ func NewTaskHandler(b *TaskBackend) *TaskHandler {
 	h.HandlerFunc("POST", tasksIDRunsIDRetryPath, h.handleRetryRun)
 	h.HandlerFunc("DELETE", tasksIDRunsIDPath, h.handleCancelRun)
 
+	h.HandlerFunc("POST", tasksIDBackfillPath, h.handlePostBackfill)
+	h.HandlerFunc("GET", tasksIDBackfillPath, h.handleGetBackfills)
+	h.HandlerFunc("GET", tasksIDBackfillIDPath, h.handleGetBackfill)
+	h.HandlerFunc("DELETE", tasksIDBackfillIDPath, h.handleCancelBackfill)
+
+	h.HandlerFunc("GET", tasksIDDependenciesPath, h.handleGetTaskDependencies)
+
 	labelBackend := &LabelBackend{
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		Logger:           b.Logger.With(zap.String("handler", "label")),
@@ -166,12 +180,13 @@ type taskResponse struct {
 func newTaskResponse(t platform.Task, labels []*platform.Label) taskResponse {
 	response := taskResponse{
 		Links: map[string]string{
-			"self":    fmt.Sprintf("/api/v2/tasks/%s", t.ID),
-			"members": fmt.Sprintf("/api/v2/tasks/%s/members", t.ID),
-			"owners":  fmt.Sprintf("/api/v2/tasks/%s/owners", t.ID),
-			"labels":  fmt.Sprintf("/api/v2/tasks/%s/labels", t.ID),
-			"runs":    fmt.Sprintf("/api/v2/tasks/%s/runs", t.ID),
-			"logs":    fmt.Sprintf("/api/v2/tasks/%s/logs", t.ID),
+			"self":         fmt.Sprintf("/api/v2/tasks/%s", t.ID),
+			"members":      fmt.Sprintf("/api/v2/tasks/%s/members", t.ID),
+			"owners":       fmt.Sprintf("/api/v2/tasks/%s/owners", t.ID),
+			"labels":       fmt.Sprintf("/api/v2/tasks/%s/labels", t.ID),
+			"runs":         fmt.Sprintf("/api/v2/tasks/%s/runs", t.ID),
+			"logs":         fmt.Sprintf("/api/v2/tasks/%s/logs", t.ID),
+			"dependencies": fmt.Sprintf("/api/v2/tasks/%s/dependencies", t.ID),
 		},
 		Task:   t,
 		Labels: []platform.Label{},
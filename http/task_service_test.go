@@ -134,7 +134,8 @@ func TestTaskHandler_handleGetTasks(t *testing.T) {
         "members": "/api/v2/tasks/0000000000000001/members",
         "labels": "/api/v2/tasks/0000000000000001/labels",
         "runs": "/api/v2/tasks/0000000000000001/runs",
-        "logs": "/api/v2/tasks/0000000000000001/logs"
+        "logs": "/api/v2/tasks/0000000000000001/logs",
+        "dependencies": "/api/v2/tasks/0000000000000001/dependencies"
       },
       "id": "0000000000000001",
       "name": "task1",
@@ -161,7 +162,8 @@ func TestTaskHandler_handleGetTasks(t *testing.T) {
         "members": "/api/v2/tasks/0000000000000002/members",
         "labels": "/api/v2/tasks/0000000000000002/labels",
         "runs": "/api/v2/tasks/0000000000000002/runs",
-        "logs": "/api/v2/tasks/0000000000000002/logs"
+        "logs": "/api/v2/tasks/0000000000000002/logs",
+        "dependencies": "/api/v2/tasks/0000000000000002/dependencies"
       },
       "id": "0000000000000002",
       "name": "task2",
@@ -234,7 +236,8 @@ func TestTaskHandler_handleGetTasks(t *testing.T) {
         "members": "/api/v2/tasks/0000000000000002/members",
         "labels": "/api/v2/tasks/0000000000000002/labels",
         "runs": "/api/v2/tasks/0000000000000002/runs",
-        "logs": "/api/v2/tasks/0000000000000002/logs"
+        "logs": "/api/v2/tasks/0000000000000002/logs",
+        "dependencies": "/api/v2/tasks/0000000000000002/dependencies"
       },
       "id": "0000000000000002",
       "name": "task2",
@@ -306,7 +309,8 @@ func TestTaskHandler_handleGetTasks(t *testing.T) {
         "members": "/api/v2/tasks/0000000000000002/members",
         "labels": "/api/v2/tasks/0000000000000002/labels",
         "runs": "/api/v2/tasks/0000000000000002/runs",
-        "logs": "/api/v2/tasks/0000000000000002/logs"
+        "logs": "/api/v2/tasks/0000000000000002/logs",
+        "dependencies": "/api/v2/tasks/0000000000000002/dependencies"
       },
       "id": "0000000000000002",
       "name": "task2",
@@ -472,7 +476,8 @@ func TestTaskHandler_handlePostTasks(t *testing.T) {
     "members": "/api/v2/tasks/0000000000000001/members",
     "labels": "/api/v2/tasks/0000000000000001/labels",
     "runs": "/api/v2/tasks/0000000000000001/runs",
-    "logs": "/api/v2/tasks/0000000000000001/logs"
+    "logs": "/api/v2/tasks/0000000000000001/logs",
+    "dependencies": "/api/v2/tasks/0000000000000001/dependencies"
   },
   "id": "0000000000000001",
   "name": "task1",
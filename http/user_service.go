@@ -22,6 +22,7 @@ type UserBackend struct {
 	UserService             influxdb.UserService
 	UserOperationLogService influxdb.UserOperationLogService
 	PasswordsService        influxdb.PasswordsService
+	SigninLockoutService    influxdb.SigninLockoutService
 }
 
 // NewUserBackend creates a UserBackend using information in the APIBackend.
@@ -32,6 +33,7 @@ func NewUserBackend(b *APIBackend) *UserBackend {
 		UserService:             b.UserService,
 		UserOperationLogService: b.UserOperationLogService,
 		PasswordsService:        b.PasswordsService,
+		SigninLockoutService:    b.SigninLockoutService,
 	}
 }
 
@@ -43,6 +45,7 @@ type UserHandler struct {
 	UserService             influxdb.UserService
 	UserOperationLogService influxdb.UserOperationLogService
 	PasswordsService        influxdb.PasswordsService
+	SigninLockoutService    influxdb.SigninLockoutService
 }
 
 const (
@@ -52,6 +55,7 @@ const (
 	usersIDPath       = "/api/v2/users/:id"
 	usersPasswordPath = "/api/v2/users/:id/password"
 	usersLogPath      = "/api/v2/users/:id/logs"
+	usersLockoutPath  = "/api/v2/users/:id/lockout"
 )
 
 // NewUserHandler returns a new instance of UserHandler.
@@ -64,6 +68,7 @@ func NewUserHandler(b *UserBackend) *UserHandler {
 		UserService:             b.UserService,
 		UserOperationLogService: b.UserOperationLogService,
 		PasswordsService:        b.PasswordsService,
+		SigninLockoutService:    b.SigninLockoutService,
 	}
 
 	h.HandlerFunc("POST", usersPath, h.handlePostUser)
@@ -73,6 +78,9 @@ func NewUserHandler(b *UserBackend) *UserHandler {
 	h.HandlerFunc("PATCH", usersIDPath, h.handlePatchUser)
 	h.HandlerFunc("DELETE", usersIDPath, h.handleDeleteUser)
 	h.HandlerFunc("PUT", usersPasswordPath, h.handlePutUserPassword)
+	if h.SigninLockoutService != nil {
+		h.HandlerFunc("DELETE", usersLockoutPath, h.handleDeleteUserLockout)
+	}
 
 	h.HandlerFunc("GET", mePath, h.handleGetMe)
 	h.HandlerFunc("PUT", mePasswordPath, h.handlePutUserPassword)
@@ -267,6 +275,31 @@ func (h *UserHandler) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handleDeleteUserLockout is the HTTP handler for the DELETE /api/v2/users/:id/lockout route.
+// It unlocks signins of a user locked out after failing to sign in.
+func (h *UserHandler) handleDeleteUserLockout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeGetUserRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	u, err := h.UserService.FindUserByID(ctx, req.UserID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.SigninLockoutService.UnlockSignin(ctx, u.Name); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("user unlocked", zap.String("userID", fmt.Sprint(u.ID)))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 type deleteUserRequest struct {
 	UserID influxdb.ID
 }
@@ -426,6 +459,9 @@ func decodePatchUserRequest(ctx context.Context, r *http.Request) (*patchUserReq
 	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
 		return nil, err
 	}
+	if err := upd.Valid(); err != nil {
+		return nil, err
+	}
 
 	return &patchUserRequest{
 		Update: upd,
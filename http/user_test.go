@@ -2,11 +2,13 @@ package http
 
 import (
 	"context"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/lockout"
 	"github.com/influxdata/influxdb/mock"
 	platformtesting "github.com/influxdata/influxdb/testing"
 	"go.uber.org/zap"
@@ -53,3 +55,44 @@ func TestUserService(t *testing.T) {
 	t.Parallel()
 	platformtesting.UserService(initUserService, t)
 }
+
+func TestUserHandler_handleDeleteUserLockout(t *testing.T) {
+	ctx := context.Background()
+	signins := lockout.NewService(lockout.DefaultConfig())
+	for i := 0; i < lockout.DefaultUserAttempts; i++ {
+		signins.SigninFailed(ctx, "user1", "10.0.0.1")
+	}
+
+	userBackend := NewMockUserBackend()
+	userBackend.HTTPErrorHandler = ErrorHandler(0)
+	userBackend.SigninLockoutService = signins
+	userBackend.UserService = &mock.UserService{
+		FindUserByIDFn: func(ctx context.Context, id platform.ID) (*platform.User, error) {
+			if id != platformtesting.MustIDBase16("020f755c3c082000") {
+				return nil, &platform.Error{Code: platform.ENotFound, Msg: "user not found"}
+			}
+			return &platform.User{ID: id, Name: "user1"}, nil
+		},
+	}
+	h := NewUserHandler(userBackend)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "http://any.url/api/v2/users/020f755c3c082001/lockout", nil)
+	h.ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNotFound; got != want {
+		t.Errorf("unlock of unknown user: got status %d want %d", got, want)
+	}
+	if err := signins.CheckSignin(ctx, "user1", "10.0.0.2"); err != platform.ErrSigninLocked {
+		t.Fatalf("expected user to be locked out, got %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("DELETE", "http://any.url/api/v2/users/020f755c3c082000/lockout", nil)
+	h.ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNoContent; got != want {
+		t.Errorf("got status %d want %d", got, want)
+	}
+	if err := signins.CheckSignin(ctx, "user1", "10.0.0.2"); err != nil {
+		t.Errorf("expected user to be unlocked, got %v", err)
+	}
+}
@@ -443,6 +443,87 @@ func (h *VariableHandler) handleDeleteVariable(w http.ResponseWriter, r *http.Re
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// expandVariableParams replaces the variable parameters of params with
+// string parameters of the values of the variables of the organization they
+// name. The value of a variable is the value selected by its parameter, or
+// else its own selected value. The values of constant variables must be one
+// of their constants, and map variables are expanded into the value of the
+// selected key. Any value of a query variable is accepted, since its values
+// are only known once its query is executed.
+func expandVariableParams(ctx context.Context, svc platform.VariableService, orgID platform.ID, params QueryParams) error {
+	var variables map[string]*platform.Variable
+	for name, p := range params {
+		if p.Type != QueryParamVariable {
+			continue
+		}
+
+		if variables == nil {
+			if svc == nil {
+				return &platform.Error{
+					Code: platform.EInvalid,
+					Msg:  "variable parameters are not supported",
+				}
+			}
+			vs, err := svc.FindVariables(ctx, platform.VariableFilter{OrganizationID: &orgID})
+			if err != nil {
+				return err
+			}
+			variables = make(map[string]*platform.Variable, len(vs))
+			for _, v := range vs {
+				variables[v.Name] = v
+			}
+		}
+
+		v, ok := variables[p.Value.(string)]
+		if !ok {
+			return &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("parameter %s: variable %q not found", name, p.Value),
+			}
+		}
+		value, err := variableValue(v, p.Selected)
+		if err != nil {
+			return &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("parameter %s: %v", name, err),
+			}
+		}
+		params[name] = QueryParam{Type: QueryParamString, Value: value}
+	}
+	return nil
+}
+
+// variableValue returns the value of v for the selected value, or its own
+// selected value if it is empty.
+func variableValue(v *platform.Variable, selected string) (string, error) {
+	if selected == "" {
+		if len(v.Selected) == 0 {
+			return "", fmt.Errorf("variable %q has no selected value", v.Name)
+		}
+		selected = v.Selected[0]
+	}
+	if v.Arguments == nil {
+		return selected, nil
+	}
+
+	switch values := v.Arguments.Values.(type) {
+	case platform.VariableConstantValues:
+		for _, c := range values {
+			if c == selected {
+				return selected, nil
+			}
+		}
+		return "", fmt.Errorf("%q is not a value of variable %q", selected, v.Name)
+	case platform.VariableMapValues:
+		value, ok := values[selected]
+		if !ok {
+			return "", fmt.Errorf("%q is not a key of variable %q", selected, v.Name)
+		}
+		return value, nil
+	}
+	return selected, nil
+}
+
 // VariableService is a variable service over HTTP to the influxdb server
 type VariableService struct {
 	Addr               string
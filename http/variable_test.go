@@ -13,6 +13,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/google/go-cmp/cmp"
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/inmem"
 	"github.com/influxdata/influxdb/mock"
@@ -921,3 +922,109 @@ func initVariableService(f platformtesting.VariableFields, t *testing.T) (platfo
 func TestVariableService(t *testing.T) {
 	platformtesting.VariableService(initVariableService, t)
 }
+
+func TestExpandVariableParams(t *testing.T) {
+	svc := mock.NewVariableService()
+	svc.FindVariablesF = func(ctx context.Context, f platform.VariableFilter, opts ...platform.FindOptions) ([]*platform.Variable, error) {
+		if f.OrganizationID == nil || *f.OrganizationID != 1 {
+			return nil, nil
+		}
+		return []*platform.Variable{
+			{
+				Name:     "host",
+				Selected: []string{"a"},
+				Arguments: &platform.VariableArguments{
+					Type:   "constant",
+					Values: platform.VariableConstantValues{"a", "b"},
+				},
+			},
+			{
+				Name:     "region",
+				Selected: []string{"west"},
+				Arguments: &platform.VariableArguments{
+					Type:   "map",
+					Values: platform.VariableMapValues{"west": "us-west-1", "east": "us-east-1"},
+				},
+			},
+			{
+				Name: "bucket",
+				Arguments: &platform.VariableArguments{
+					Type:   "query",
+					Values: platform.VariableQueryValues{Query: "buckets()", Language: "flux"},
+				},
+			},
+		}, nil
+	}
+
+	tests := []struct {
+		name    string
+		params  QueryParams
+		want    QueryParams
+		wantErr string
+	}{
+		{
+			name: "selected values of the variables",
+			params: QueryParams{
+				"host":   {Type: QueryParamVariable, Value: "host"},
+				"region": {Type: QueryParamVariable, Value: "region"},
+				"n":      {Type: QueryParamInt, Value: int64(1)},
+			},
+			want: QueryParams{
+				"host":   {Type: QueryParamString, Value: "a"},
+				"region": {Type: QueryParamString, Value: "us-west-1"},
+				"n":      {Type: QueryParamInt, Value: int64(1)},
+			},
+		},
+		{
+			name: "selected values of the parameters",
+			params: QueryParams{
+				"host":   {Type: QueryParamVariable, Value: "host", Selected: "b"},
+				"region": {Type: QueryParamVariable, Value: "region", Selected: "east"},
+				"bucket": {Type: QueryParamVariable, Value: "bucket", Selected: "telegraf"},
+			},
+			want: QueryParams{
+				"host":   {Type: QueryParamString, Value: "b"},
+				"region": {Type: QueryParamString, Value: "us-east-1"},
+				"bucket": {Type: QueryParamString, Value: "telegraf"},
+			},
+		},
+		{
+			name:    "value that is not a constant",
+			params:  QueryParams{"host": {Type: QueryParamVariable, Value: "host", Selected: `a" or true`}},
+			wantErr: `parameter host: "a\" or true" is not a value of variable "host"`,
+		},
+		{
+			name:    "key that is not in the map",
+			params:  QueryParams{"region": {Type: QueryParamVariable, Value: "region", Selected: "north"}},
+			wantErr: `parameter region: "north" is not a key of variable "region"`,
+		},
+		{
+			name:    "no selected value",
+			params:  QueryParams{"bucket": {Type: QueryParamVariable, Value: "bucket"}},
+			wantErr: `parameter bucket: variable "bucket" has no selected value`,
+		},
+		{
+			name:    "unknown variable",
+			params:  QueryParams{"cpu": {Type: QueryParamVariable, Value: "cpu"}},
+			wantErr: `parameter cpu: variable "cpu" not found`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := expandVariableParams(context.Background(), svc, 1, tt.params)
+			if tt.wantErr != "" {
+				platformtesting.ErrorsEqual(t, err, &platform.Error{Code: platform.EInvalid, Msg: tt.wantErr})
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.params, tt.want); diff != "" {
+				t.Errorf("unexpected params -got/+want\n%s", diff)
+			}
+		})
+	}
+
+	params := QueryParams{"host": {Type: QueryParamVariable, Value: "host"}}
+	err := expandVariableParams(context.Background(), nil, 1, params)
+	platformtesting.ErrorsEqual(t, err, &platform.Error{Code: platform.EInvalid, Msg: "variable parameters are not supported"})
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/influxdata/influxdb/http/metric"
@@ -31,6 +32,7 @@ type WriteBackend struct {
 	PointsWriter        storage.PointsWriter
 	BucketService       platform.BucketService
 	OrganizationService platform.OrganizationService
+	StorageUsageService platform.StorageUsageService
 }
 
 // NewWriteBackend returns a new instance of WriteBackend.
@@ -43,6 +45,7 @@ func NewWriteBackend(b *APIBackend) *WriteBackend {
 		PointsWriter:        b.PointsWriter,
 		BucketService:       b.BucketService,
 		OrganizationService: b.OrganizationService,
+		StorageUsageService: b.StorageUsageService,
 	}
 }
 
@@ -55,6 +58,13 @@ type WriteHandler struct {
 	BucketService       platform.BucketService
 	OrganizationService platform.OrganizationService
 
+	// StorageUsageService, if set, enforces the storage quotas of
+	// organizations and buckets.
+	StorageUsageService platform.StorageUsageService
+
+	usageOnce sync.Once
+	usage     *storageUsageCache
+
 	PointsWriter storage.PointsWriter
 
 	EventRecorder metric.EventRecorder
@@ -76,6 +86,7 @@ func NewWriteHandler(b *WriteBackend) *WriteHandler {
 		PointsWriter:        b.PointsWriter,
 		BucketService:       b.BucketService,
 		OrganizationService: b.OrganizationService,
+		StorageUsageService: b.StorageUsageService,
 		EventRecorder:       b.WriteEventRecorder,
 	}
 
@@ -201,7 +212,8 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !a.Allowed(*p) {
+	restrictions, ok := platform.AuthorizerRestrictions(a, *p)
+	if !ok {
 		h.HandleHTTPError(ctx, &platform.Error{
 			Code: platform.EForbidden,
 			Op:   "http/handleWrite",
@@ -210,6 +222,11 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.checkStorageQuotas(ctx, org, bucket); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
 	// TODO(jeff): we should be publishing with the org and bucket instead of
 	// parsing, rewriting, and publishing, but the interface isn't quite there yet.
 	// be sure to remove this when it is there!
@@ -240,13 +257,55 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.PointsWriter.WritePoints(ctx, points); err != nil {
-		logger.Error("Error writing points", zap.Error(err))
+	if len(restrictions) > 0 {
+		for _, pt := range points {
+			if !pointAllowed(pt, restrictions) {
+				h.HandleHTTPError(ctx, &platform.Error{
+					Code: platform.EForbidden,
+					Op:   "http/handleWrite",
+					Msg:  fmt.Sprintf("insufficient permissions for write to measurement %q", pt.Tags().GetString(models.MeasurementTagKey)),
+				}, w)
+				return
+			}
+		}
+	}
+
+	// Points violating the schema of an explicit bucket are dropped,
+	// and the others are written.
+	var rejected *tsdb.PartialWriteError
+	if bucket.Schema != nil {
+		allowed := make([]models.Point, 0, len(points))
+		for _, pt := range points {
+			if err := checkPointSchema(pt, bucket.Schema); err != nil {
+				if rejected == nil {
+					rejected = &tsdb.PartialWriteError{Reason: platform.ErrorMessage(err)}
+				}
+				rejected.Dropped++
+				continue
+			}
+			allowed = append(allowed, pt)
+		}
+		points = allowed
+	}
+
+	if len(points) > 0 {
+		if err := h.PointsWriter.WritePoints(ctx, points); err != nil {
+			logger.Error("Error writing points", zap.Error(err))
+			h.HandleHTTPError(ctx, &platform.Error{
+				Code: platform.EInternal,
+				Op:   "http/handleWrite",
+				Msg:  fmt.Sprintf("unable to write points to database: %v", err),
+				Err:  err,
+			}, w)
+			return
+		}
+	}
+
+	if rejected != nil {
 		h.HandleHTTPError(ctx, &platform.Error{
-			Code: platform.EInternal,
+			Code: platform.EInvalid,
 			Op:   "http/handleWrite",
-			Msg:  fmt.Sprintf("unable to write points to database: %v", err),
-			Err:  err,
+			Msg:  rejected.Error(),
 		}, w)
 		return
 	}
@@ -254,6 +313,175 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// checkStorageQuotas returns an error if the data of the bucket or of its
+// organization uses more disk space than their storage quotas. The usage is
+// cached for storageUsageTTL, so writes may exceed a quota by what is written
+// within it.
+func (h *WriteHandler) checkStorageQuotas(ctx context.Context, org *platform.Organization, bucket *platform.Bucket) error {
+	if h.StorageUsageService == nil {
+		return nil
+	}
+	h.usageOnce.Do(func() {
+		h.usage = newStorageUsageCache(h.StorageUsageService)
+	})
+
+	if bucket.StorageQuotaBytes > 0 {
+		n, err := h.usage.bucketBytes(ctx, org.ID, bucket.ID)
+		if err != nil {
+			return err
+		}
+		if n > bucket.StorageQuotaBytes {
+			return &platform.Error{
+				Code: platform.EForbidden,
+				Op:   "http/handleWrite",
+				Msg:  fmt.Sprintf("bucket %q has exceeded its storage quota of %d bytes", bucket.Name, bucket.StorageQuotaBytes),
+			}
+		}
+	}
+
+	if org.StorageQuotaBytes > 0 {
+		n, err := h.usage.orgBytes(ctx, org.ID)
+		if err != nil {
+			return err
+		}
+		if n > org.StorageQuotaBytes {
+			return &platform.Error{
+				Code: platform.EForbidden,
+				Op:   "http/handleWrite",
+				Msg:  fmt.Sprintf("organization %q has exceeded its storage quota of %d bytes", org.Name, org.StorageQuotaBytes),
+			}
+		}
+	}
+	return nil
+}
+
+// storageUsageTTL is how long the storage usage of an organization or bucket
+// is cached for checking storage quotas. Finding the usage walks the index of
+// the storage engine, which is too expensive to do on every write.
+const storageUsageTTL = 10 * time.Second
+
+// storageUsageKey identifies the usage of a bucket, or of an organization when
+// bucketID is not valid.
+type storageUsageKey struct {
+	orgID, bucketID platform.ID
+}
+
+type cachedStorageUsage struct {
+	bytes   int64
+	expires time.Time
+}
+
+// storageUsageCache caches the storage usage of organizations and buckets.
+type storageUsageCache struct {
+	s   platform.StorageUsageService
+	now func() time.Time
+
+	mu    sync.Mutex
+	usage map[storageUsageKey]cachedStorageUsage
+}
+
+func newStorageUsageCache(s platform.StorageUsageService) *storageUsageCache {
+	return &storageUsageCache{
+		s:     s,
+		now:   time.Now,
+		usage: make(map[storageUsageKey]cachedStorageUsage),
+	}
+}
+
+// orgBytes returns the disk space used by the organization.
+func (c *storageUsageCache) orgBytes(ctx context.Context, orgID platform.ID) (int64, error) {
+	return c.bytes(storageUsageKey{orgID: orgID}, func() (*platform.StorageUsage, error) {
+		return c.s.FindOrganizationStorageUsage(ctx, orgID)
+	})
+}
+
+// bucketBytes returns the disk space used by the bucket.
+func (c *storageUsageCache) bucketBytes(ctx context.Context, orgID, bucketID platform.ID) (int64, error) {
+	return c.bytes(storageUsageKey{orgID: orgID, bucketID: bucketID}, func() (*platform.StorageUsage, error) {
+		return c.s.FindBucketStorageUsage(ctx, orgID, bucketID)
+	})
+}
+
+// bytes returns the cached usage of key, finding it with find if it is not
+// cached or has expired. Concurrent writes may find the same usage at once,
+// which is cheaper than serializing them.
+func (c *storageUsageCache) bytes(key storageUsageKey, find func() (*platform.StorageUsage, error)) (int64, error) {
+	now := c.now()
+	c.mu.Lock()
+	cached, ok := c.usage[key]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.bytes, nil
+	}
+
+	u, err := find()
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Drop the expired usage of other organizations and buckets so the cache stays small.
+	for k, cached := range c.usage {
+		if !now.Before(cached.expires) {
+			delete(c.usage, k)
+		}
+	}
+	c.usage[key] = cachedStorageUsage{bytes: u.Bytes(), expires: now.Add(storageUsageTTL)}
+	return u.Bytes(), nil
+}
+
+// checkPointSchema returns an error if the point does not satisfy the schema s.
+// Points have a single field, as written points are split by field when parsed.
+func checkPointSchema(pt models.Point, s *platform.BucketSchema) error {
+	var measurement string
+	var tagKeys []string
+	for _, t := range pt.Tags() {
+		switch string(t.Key) {
+		case models.MeasurementTagKey:
+			measurement = string(t.Value)
+		case models.FieldKeyTagKey:
+		default:
+			tagKeys = append(tagKeys, string(t.Key))
+		}
+	}
+
+	fields := pt.FieldIterator()
+	if !fields.Next() {
+		return nil
+	}
+	var typ platform.FieldType
+	switch fields.Type() {
+	case models.Float:
+		typ = platform.FieldTypeFloat
+	case models.Integer:
+		typ = platform.FieldTypeInteger
+	case models.Unsigned:
+		typ = platform.FieldTypeUnsigned
+	case models.String:
+		typ = platform.FieldTypeString
+	case models.Boolean:
+		typ = platform.FieldTypeBoolean
+	}
+	return s.Check(measurement, tagKeys, string(fields.FieldKey()), typ)
+}
+
+// pointAllowed returns true if the series of the point satisfies any of the restrictions rs.
+func pointAllowed(pt models.Point, rs []*platform.Restriction) bool {
+	tags := pt.Tags()
+	tag := func(key string) (string, bool) {
+		v := tags.Get([]byte(key))
+		return string(v), v != nil
+	}
+	measurement := tags.GetString(models.MeasurementTagKey)
+	for _, r := range rs {
+		if r.Allows(measurement, tag) {
+			return true
+		}
+	}
+	return false
+}
+
 func decodeWriteRequest(ctx context.Context, r *http.Request) (*postWriteRequest, error) {
 	qp := r.URL.Query()
 	p := qp.Get("precision")
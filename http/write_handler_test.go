@@ -9,8 +9,10 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
 )
 
 func TestWriteService_Write(t *testing.T) {
@@ -69,3 +71,69 @@ func TestWriteService_Write(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteHandler_checkStorageQuotas(t *testing.T) {
+	var bucketCalls, orgCalls int
+	var bucketBytes int64 = 10
+	usage := mock.NewStorageUsageService()
+	usage.FindBucketStorageUsageFn = func(ctx context.Context, orgID, bucketID platform.ID) (*platform.StorageUsage, error) {
+		bucketCalls++
+		return &platform.StorageUsage{TSMBytes: bucketBytes}, nil
+	}
+	usage.FindOrganizationStorageUsageFn = func(ctx context.Context, orgID platform.ID) (*platform.StorageUsage, error) {
+		orgCalls++
+		return &platform.StorageUsage{TSMBytes: 100}, nil
+	}
+
+	now := time.Unix(0, 0)
+	h := &WriteHandler{StorageUsageService: usage}
+	h.usageOnce.Do(func() {
+		h.usage = newStorageUsageCache(usage)
+		h.usage.now = func() time.Time { return now }
+	})
+
+	org := &platform.Organization{ID: 1, Name: "o"}
+	bucket := &platform.Bucket{ID: 2, OrgID: 1, Name: "b", StorageQuotaBytes: 20}
+	ctx := context.Background()
+
+	// Buckets and organizations without a quota are not checked.
+	if err := h.checkStorageQuotas(ctx, org, &platform.Bucket{ID: 3, OrgID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if bucketCalls != 0 || orgCalls != 0 {
+		t.Fatalf("expected no usage to be found without quotas, got %d bucket and %d org calls", bucketCalls, orgCalls)
+	}
+
+	// The usage is found once, and cached until it expires.
+	for i := 0; i < 3; i++ {
+		if err := h.checkStorageQuotas(ctx, org, bucket); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if bucketCalls != 1 {
+		t.Fatalf("expected the bucket usage to be found once, got %d calls", bucketCalls)
+	}
+
+	bucketBytes = 30
+	if err := h.checkStorageQuotas(ctx, org, bucket); err != nil {
+		t.Fatalf("expected the cached usage to be checked, got %v", err)
+	}
+	now = now.Add(storageUsageTTL)
+	if err := h.checkStorageQuotas(ctx, org, bucket); platform.ErrorCode(err) != platform.EForbidden {
+		t.Fatalf("expected forbidden error once the cached usage expires, got %v", err)
+	}
+	if bucketCalls != 2 {
+		t.Fatalf("expected the bucket usage to be found again, got %d calls", bucketCalls)
+	}
+
+	org.StorageQuotaBytes = 50
+	if err := h.checkStorageQuotas(ctx, org, &platform.Bucket{ID: 3, OrgID: 1}); platform.ErrorCode(err) != platform.EForbidden {
+		t.Fatalf("expected forbidden error for the organization quota, got %v", err)
+	}
+	if err := h.checkStorageQuotas(ctx, org, &platform.Bucket{ID: 3, OrgID: 1}); platform.ErrorCode(err) != platform.EForbidden {
+		t.Fatalf("expected forbidden error for the organization quota, got %v", err)
+	}
+	if orgCalls != 1 {
+		t.Fatalf("expected the organization usage to be found once, got %d calls", orgCalls)
+	}
+}
@@ -217,7 +217,7 @@ func (s *Service) DeleteAuthorization(ctx context.Context, id platform.ID) error
 	return nil
 }
 
-// UpdateAuthorization updates the status and description if available.
+// UpdateAuthorization updates the status, description and expiry if available.
 func (s *Service) UpdateAuthorization(ctx context.Context, id platform.ID, upd *platform.AuthorizationUpdate) (*platform.Authorization, error) {
 	op := OpPrefix + platform.OpUpdateAuthorization
 	a, err := s.FindAuthorizationByID(ctx, id)
@@ -245,6 +245,15 @@ func (s *Service) UpdateAuthorization(ctx context.Context, id platform.ID, upd *
 	if upd.Description != nil {
 		a.Description = *upd.Description
 	}
+	if upd.ExpiresAt != nil {
+		a.ExpiresAt = upd.ExpiresAt
+	}
+	if upd.ClearExpiresAt {
+		a.ExpiresAt = nil
+	}
+	if upd.LastUsedAt != nil {
+		a.LastUsedAt = upd.LastUsedAt
+	}
 
 	return a, s.PutAuthorization(ctx, a)
 }
@@ -272,6 +272,13 @@ func (s *Service) UpdateBucket(ctx context.Context, id platform.ID, upd platform
 		b.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.StorageQuotaBytes != nil {
+		if err := platform.ValidStorageQuota(*upd.StorageQuotaBytes); err != nil {
+			return nil, err
+		}
+		b.StorageQuotaBytes = *upd.StorageQuotaBytes
+	}
+
 	if upd.Description != nil {
 		b.Description = *upd.Description
 	}
@@ -235,6 +235,23 @@ func (s *Service) UpdateOrganization(ctx context.Context, id platform.ID, upd pl
 		o.Description = *upd.Description
 	}
 
+	if upd.QueryLimits != nil {
+		if err := upd.QueryLimits.Valid(); err != nil {
+			return nil, err
+		}
+		o.QueryLimits = upd.QueryLimits
+		if o.QueryLimits.IsZero() {
+			o.QueryLimits = nil
+		}
+	}
+
+	if upd.StorageQuotaBytes != nil {
+		if err := platform.ValidStorageQuota(*upd.StorageQuotaBytes); err != nil {
+			return nil, err
+		}
+		o.StorageQuotaBytes = *upd.StorageQuotaBytes
+	}
+
 	o.UpdatedAt = s.Now()
 
 	s.organizationKV.Store(o.ID.String(), o)
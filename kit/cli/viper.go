@@ -99,6 +99,14 @@ func BindOptions(cmd *cobra.Command, opts []Opt) {
 			cmd.Flags().DurationVar(destP, o.Flag, d, o.Desc)
 			mustBindPFlag(o.Flag, cmd)
 			*destP = viper.GetDuration(o.Flag)
+		case *float64:
+			var d float64
+			if o.Default != nil {
+				d = o.Default.(float64)
+			}
+			cmd.Flags().Float64Var(destP, o.Flag, d, o.Desc)
+			mustBindPFlag(o.Flag, cmd)
+			*destP = viper.GetFloat64(o.Flag)
 		case *[]string:
 			var d []string
 			if o.Default != nil {
@@ -11,6 +11,7 @@ func ExampleNewCommand() {
 	var number int
 	var sleep bool
 	var duration time.Duration
+	var ratio float64
 	var stringSlice []string
 	cmd := NewCommand(&Program{
 		Run: func() error {
@@ -20,6 +21,7 @@ func ExampleNewCommand() {
 			}
 			fmt.Println(sleep)
 			fmt.Println(duration)
+			fmt.Println(ratio)
 			fmt.Println(stringSlice)
 			return nil
 		},
@@ -49,6 +51,12 @@ func ExampleNewCommand() {
 				Default: time.Minute,
 				Desc:    "how long to sleep",
 			},
+			{
+				DestP:   &ratio,
+				Flag:    "ratio",
+				Default: 0.5,
+				Desc:    "fraction of the time to sleep",
+			},
 			{
 				DestP:   &stringSlice,
 				Flag:    "string-slice",
@@ -67,5 +75,6 @@ func ExampleNewCommand() {
 	// 1
 	// true
 	// 1m0s
+	// 0.5
 	// [foo bar]
 }
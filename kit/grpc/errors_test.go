@@ -66,6 +66,17 @@ func TestToStatus(t *testing.T) {
 			wantCode:    codes.Unavailable,
 			wantMessage: `{"code":"unavailable","message":"howdy","op":"kit/grpc","error":"error"}`,
 		},
+		{
+			name: "encode unauthorized error",
+			err: &platform.Error{
+				Err:  fmt.Errorf("error"),
+				Op:   "kit/grpc",
+				Code: platform.EUnauthorized,
+				Msg:  "howdy",
+			},
+			wantCode:    codes.Unauthenticated,
+			wantMessage: `{"code":"unauthorized","message":"howdy","op":"kit/grpc","error":"error"}`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,688 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+)
+
+var (
+	auditLogBucket   = []byte("auditlogv1")
+	auditLogOrgIndex = []byte("auditlogorgindexv1")
+)
+
+var _ influxdb.AuditLogService = (*Service)(nil)
+
+// auditLogPruneBatch bounds the number of expired entries removed from the audit log by a single transaction.
+const auditLogPruneBatch = 100
+
+func (s *Service) initializeAuditLog(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(auditLogBucket); err != nil {
+		return err
+	}
+	if _, err := tx.Bucket(auditLogOrgIndex); err != nil {
+		return err
+	}
+	return nil
+}
+
+// auditedResource describes how the changes to the values of a bucket are recorded in the audit log.
+// The values are JSON objects, unless the resource has its own describe.
+type auditedResource struct {
+	resourceType influxdb.ResourceType
+	// idField is the field of the values identifying the resource; "id" if empty.
+	idField string
+	// redact are the fields of the values holding credentials, whose values are never recorded.
+	redact []string
+	// ignore are the fields of the values that the system updates by itself.
+	// Updates that change only these fields are not recorded.
+	ignore []string
+	// describe, if set, replaces the description of the resource and of the change from the values.
+	describe func(e *influxdb.AuditLogEntry, key, before, after []byte) error
+}
+
+// auditedResources are the buckets whose changes are recorded in the audit log, by name.
+var auditedResources = map[string]*auditedResource{
+	string(authBucket): {
+		resourceType: influxdb.AuthorizationsResourceType,
+		redact:       []string{"token"},
+		ignore:       []string{"lastUsedAt"},
+	},
+	string(bucketBucket): {
+		resourceType: influxdb.BucketsResourceType,
+	},
+	string(dashboardBucket): {
+		resourceType: influxdb.DashboardsResourceType,
+	},
+	string(labelBucket): {
+		resourceType: influxdb.LabelsResourceType,
+	},
+	string(labelMappingBucket): {
+		resourceType: influxdb.LabelsResourceType,
+		idField:      "labelID",
+	},
+	string(organizationBucket): {
+		resourceType: influxdb.OrgsResourceType,
+	},
+	string(replicationBucket): {
+		resourceType: influxdb.ReplicationsResourceType,
+		redact:       []string{"remoteToken"},
+	},
+	string(roleBucket): {
+		resourceType: influxdb.RolesResourceType,
+	},
+	string(scrapersBucket): {
+		resourceType: influxdb.ScraperResourceType,
+	},
+	string(secretBucket): {
+		resourceType: influxdb.SecretsResourceType,
+		describe:     describeSecretChange,
+	},
+	string(sourceBucket): {
+		resourceType: influxdb.SourcesResourceType,
+		redact:       []string{"password", "sharedSecret", "token"},
+	},
+	string(taskBucket): {
+		resourceType: influxdb.TasksResourceType,
+		ignore:       []string{"latestCompleted", "updatedAt"},
+	},
+	string(taskBackfillBucket): {
+		resourceType: influxdb.TasksResourceType,
+		idField:      "taskID",
+		ignore:       []string{"next", "queued", "finished", "runs", "updatedAt"},
+	},
+	string(telegrafBucket): {
+		resourceType: influxdb.TelegrafsResourceType,
+	},
+	string(urmBucket): {
+		resourceType: influxdb.UsersResourceType,
+		describe:     describeUserResourceMappingChange,
+	},
+	string(userBucket): {
+		resourceType: influxdb.UsersResourceType,
+	},
+	string(userpasswordBucket): {
+		resourceType: influxdb.UsersResourceType,
+		describe:     describePasswordChange,
+	},
+	string(variableBucket): {
+		resourceType: influxdb.VariablesResourceType,
+	},
+}
+
+// auditStore records the changes made by its update transactions to the audited
+// resources in the audit log, as part of the same transaction.
+type auditStore struct {
+	Store
+	s *Service
+	// ids generates the IDs of the entries, apart from the IDs of the resources.
+	ids influxdb.IDGenerator
+}
+
+// Update opens up a transaction that will mutate data, and records its changes in the audit log.
+func (st *auditStore) Update(ctx context.Context, fn func(Tx) error) error {
+	return st.Store.Update(ctx, func(tx Tx) error {
+		atx := &auditTx{Tx: tx, changes: map[string]*auditChange{}}
+		if err := fn(atx); err != nil {
+			return err
+		}
+		return st.s.appendAuditLog(ctx, tx, st.ids, atx.order)
+	})
+}
+
+// auditTx is a transaction collecting the changes made to the audited resources.
+type auditTx struct {
+	Tx
+	changes map[string]*auditChange
+	order   []*auditChange
+}
+
+// Bucket possibly creates and returns bucket, b.
+func (tx *auditTx) Bucket(b []byte) (Bucket, error) {
+	bkt, err := tx.Tx.Bucket(b)
+	if err != nil {
+		return nil, err
+	}
+
+	r, ok := auditedResources[string(b)]
+	if !ok {
+		return bkt, nil
+	}
+	return &auditBucket{Bucket: bkt, tx: tx, name: b, resource: r}, nil
+}
+
+// auditChange is the change of the value of a key in the transaction.
+// before is the value before the transaction, and after the last value put in it.
+type auditChange struct {
+	resource *auditedResource
+	key      []byte
+	before   []byte
+	after    []byte
+}
+
+func (tx *auditTx) record(name []byte, r *auditedResource, key, prev, next []byte) {
+	k := string(name) + "/" + string(key)
+	if c, ok := tx.changes[k]; ok {
+		c.after = next
+		return
+	}
+
+	c := &auditChange{
+		resource: r,
+		key:      append([]byte(nil), key...),
+		before:   prev,
+		after:    next,
+	}
+	tx.changes[k] = c
+	tx.order = append(tx.order, c)
+}
+
+// auditBucket is a bucket of an audited resource.
+type auditBucket struct {
+	Bucket
+	tx       *auditTx
+	name     []byte
+	resource *auditedResource
+}
+
+func (b *auditBucket) previous(key []byte) ([]byte, error) {
+	v, err := b.Bucket.Get(key)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), v...), nil
+}
+
+// Put sets the value of key, and records the change.
+func (b *auditBucket) Put(key, value []byte) error {
+	prev, err := b.previous(key)
+	if err != nil {
+		return err
+	}
+	if err := b.Bucket.Put(key, value); err != nil {
+		return err
+	}
+	b.tx.record(b.name, b.resource, key, prev, append([]byte(nil), value...))
+	return nil
+}
+
+// Delete removes key, and records the change.
+func (b *auditBucket) Delete(key []byte) error {
+	prev, err := b.previous(key)
+	if err != nil {
+		return err
+	}
+	if err := b.Bucket.Delete(key); err != nil {
+		return err
+	}
+	if prev != nil {
+		b.tx.record(b.name, b.resource, key, prev, nil)
+	}
+	return nil
+}
+
+// entry returns the audit log entry of the change, or nil if it is not recorded.
+func (c *auditChange) entry() (*influxdb.AuditLogEntry, error) {
+	e := &influxdb.AuditLogEntry{
+		ResourceType: c.resource.resourceType,
+	}
+	switch {
+	case c.before == nil && c.after == nil:
+		// Created and deleted by the same transaction.
+		return nil, nil
+	case c.before == nil:
+		e.Action = influxdb.AuditCreate
+	case c.after == nil:
+		e.Action = influxdb.AuditDelete
+	default:
+		if bytes.Equal(c.before, c.after) {
+			return nil, nil
+		}
+		e.Action = influxdb.AuditUpdate
+	}
+
+	if c.resource.describe != nil {
+		if err := c.resource.describe(e, c.key, c.before, c.after); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+
+	before, err := auditFields(c.before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := auditFields(c.after)
+	if err != nil {
+		return nil, err
+	}
+
+	current := after
+	if current == nil {
+		current = before
+	}
+	e.ResourceID = auditFieldID(current, c.resource.idField)
+	e.OrgID = auditFieldID(current, "orgID")
+	if !e.OrgID.Valid() {
+		e.OrgID = auditFieldID(current, "organizationID")
+	}
+	if e.ResourceType == influxdb.OrgsResourceType {
+		e.OrgID = e.ResourceID
+	}
+
+	if e.Action == influxdb.AuditUpdate {
+		before, after = c.resource.changed(before, after)
+		if before == nil && after == nil {
+			return nil, nil
+		}
+	}
+
+	if e.Before, err = c.resource.marshal(before); err != nil {
+		return nil, err
+	}
+	if e.After, err = c.resource.marshal(after); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// changed returns the fields that differ between before and after, with their values before and after.
+// Both are nil if only ignored fields differ.
+func (r *auditedResource) changed(before, after map[string]json.RawMessage) (map[string]json.RawMessage, map[string]json.RawMessage) {
+	b := map[string]json.RawMessage{}
+	a := map[string]json.RawMessage{}
+	significant := false
+	diff := func(k string) {
+		if bytes.Equal(before[k], after[k]) {
+			return
+		}
+		if v, ok := before[k]; ok {
+			b[k] = v
+		}
+		if v, ok := after[k]; ok {
+			a[k] = v
+		}
+		if !containsString(r.ignore, k) {
+			significant = true
+		}
+	}
+	for k := range before {
+		diff(k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			diff(k)
+		}
+	}
+
+	if !significant {
+		return nil, nil
+	}
+	return b, a
+}
+
+// marshal encodes the fields fs with the credentials redacted.
+func (r *auditedResource) marshal(fs map[string]json.RawMessage) (json.RawMessage, error) {
+	if fs == nil {
+		return nil, nil
+	}
+	for _, k := range r.redact {
+		if _, ok := fs[k]; ok {
+			fs[k] = json.RawMessage(`"[REDACTED]"`)
+		}
+	}
+	return json.Marshal(fs)
+}
+
+func auditFields(v []byte) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	fs := map[string]json.RawMessage{}
+	if err := json.Unmarshal(v, &fs); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// auditFieldID returns the ID in the field k of fs, or an invalid ID if there is none.
+func auditFieldID(fs map[string]json.RawMessage, k string) influxdb.ID {
+	if k == "" {
+		k = "id"
+	}
+	var id influxdb.ID
+	if v, ok := fs[k]; ok {
+		if err := json.Unmarshal(v, &id); err != nil {
+			return 0
+		}
+	}
+	return id
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// describeSecretChange records which secret of an organization changed, but never its value.
+func describeSecretChange(e *influxdb.AuditLogEntry, key, before, after []byte) error {
+	if len(key) < influxdb.IDLength {
+		return nil
+	}
+	var orgID influxdb.ID
+	if err := orgID.Decode(key[:influxdb.IDLength]); err != nil {
+		return err
+	}
+	e.OrgID = orgID
+	e.ResourceID = orgID
+
+	v, err := json.Marshal(map[string]string{"key": string(key[influxdb.IDLength:])})
+	if err != nil {
+		return err
+	}
+	if before != nil {
+		e.Before = v
+	}
+	if after != nil {
+		e.After = v
+	}
+	return nil
+}
+
+// describePasswordChange records that the password of a user changed, but never the password.
+func describePasswordChange(e *influxdb.AuditLogEntry, key, before, after []byte) error {
+	var userID influxdb.ID
+	if err := userID.Decode(key); err != nil {
+		return err
+	}
+	e.ResourceID = userID
+	if e.Action != influxdb.AuditDelete {
+		e.After = json.RawMessage(`{"password":"[REDACTED]"}`)
+	}
+	return nil
+}
+
+// describeUserResourceMappingChange records a change of the resources of a user.
+// Changes of the members of an organization belong to the organization.
+func describeUserResourceMappingChange(e *influxdb.AuditLogEntry, key, before, after []byte) error {
+	v := after
+	if v == nil {
+		v = before
+	}
+	m := &influxdb.UserResourceMapping{}
+	if err := json.Unmarshal(v, m); err != nil {
+		return err
+	}
+	e.ResourceID = m.UserID
+	if m.ResourceType == influxdb.OrgsResourceType {
+		e.OrgID = m.ResourceID
+	}
+	if before != nil {
+		e.Before = append(json.RawMessage(nil), before...)
+	}
+	if after != nil {
+		e.After = append(json.RawMessage(nil), after...)
+	}
+	return nil
+}
+
+// appendAuditLog records the changes in the audit log, and removes the entries that are past retention.
+func (s *Service) appendAuditLog(ctx context.Context, tx Tx, ids influxdb.IDGenerator, changes []*auditChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	now := s.Now()
+	for _, c := range changes {
+		e, err := c.entry()
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			continue
+		}
+
+		e.ID = ids.ID()
+		e.Time = now
+		if a, err := icontext.GetAuthorizer(ctx); err == nil {
+			e.UserID = a.GetUserID()
+			e.AuthorizerKind = a.Kind()
+			e.AuthorizerID = a.Identifier()
+		}
+		e.RemoteAddr = icontext.GetRemoteAddr(ctx)
+
+		if err := s.putAuditLogEntry(ctx, tx, e); err != nil {
+			return err
+		}
+	}
+
+	return s.pruneAuditLog(ctx, tx, now)
+}
+
+// encodeAuditLogKey returns the key of an entry, ordered by time.
+func encodeAuditLogKey(t time.Time, id influxdb.ID) ([]byte, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, err
+	}
+	k := make([]byte, 8, 8+len(encodedID))
+	binary.BigEndian.PutUint64(k, uint64(t.UnixNano()))
+	return append(k, encodedID...), nil
+}
+
+func decodeAuditLogKeyTime(k []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(k[:8]))).UTC()
+}
+
+func encodeAuditLogOrgIndexKey(orgID influxdb.ID, key []byte) ([]byte, error) {
+	encodedID, err := orgID.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return append(encodedID, key...), nil
+}
+
+func (s *Service) putAuditLogEntry(ctx context.Context, tx Tx, e *influxdb.AuditLogEntry) error {
+	k, err := encodeAuditLogKey(e.Time, e.ID)
+	if err != nil {
+		return err
+	}
+	v, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(auditLogBucket)
+	if err != nil {
+		return err
+	}
+	if err := b.Put(k, v); err != nil {
+		return err
+	}
+
+	if !e.OrgID.Valid() {
+		return nil
+	}
+	ik, err := encodeAuditLogOrgIndexKey(e.OrgID, k)
+	if err != nil {
+		return err
+	}
+	idx, err := tx.Bucket(auditLogOrgIndex)
+	if err != nil {
+		return err
+	}
+	return idx.Put(ik, k)
+}
+
+// pruneAuditLog removes the oldest entries of the audit log that are past retention.
+func (s *Service) pruneAuditLog(ctx context.Context, tx Tx, now time.Time) error {
+	if s.Config.AuditLogRetention <= 0 {
+		return nil
+	}
+	cutoff := now.Add(-s.Config.AuditLogRetention)
+
+	b, err := tx.Bucket(auditLogBucket)
+	if err != nil {
+		return err
+	}
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+
+	var expired []*influxdb.AuditLogEntry
+	for k, v := cur.First(); k != nil && len(expired) < auditLogPruneBatch; k, v = cur.Next() {
+		if !decodeAuditLogKeyTime(k).Before(cutoff) {
+			break
+		}
+		e := &influxdb.AuditLogEntry{}
+		if err := json.Unmarshal(v, e); err != nil {
+			return err
+		}
+		expired = append(expired, e)
+	}
+
+	idx, err := tx.Bucket(auditLogOrgIndex)
+	if err != nil {
+		return err
+	}
+	for _, e := range expired {
+		k, err := encodeAuditLogKey(e.Time, e.ID)
+		if err != nil {
+			return err
+		}
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+		if !e.OrgID.Valid() {
+			continue
+		}
+		ik, err := encodeAuditLogOrgIndexKey(e.OrgID, k)
+		if err != nil {
+			return err
+		}
+		if err := idx.Delete(ik); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindAuditLogEntries returns the entries of the audit log that match filter.
+func (s *Service) FindAuditLogEntries(ctx context.Context, filter influxdb.AuditLogFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditLogEntry, int, error) {
+	opts := influxdb.DefaultAuditLogFindOptions
+	if len(opt) > 0 {
+		opts = opt[0]
+	}
+
+	var es []*influxdb.AuditLogEntry
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		es, err = s.findAuditLogEntries(ctx, tx, filter)
+		return err
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindAuditLogEntries,
+			Err: err,
+		}
+	}
+
+	if opts.Descending {
+		for i, j := 0, len(es)-1; i < j; i, j = i+1, j-1 {
+			es[i], es[j] = es[j], es[i]
+		}
+	}
+
+	if opts.Offset > 0 {
+		if opts.Offset >= len(es) {
+			es = es[:0]
+		} else {
+			es = es[opts.Offset:]
+		}
+	}
+	if opts.Limit > 0 && len(es) > opts.Limit {
+		es = es[:opts.Limit]
+	}
+
+	return es, len(es), nil
+}
+
+// findAuditLogEntries returns the entries that match filter, the oldest first.
+// Entries past retention that were not removed yet are skipped.
+func (s *Service) findAuditLogEntries(ctx context.Context, tx Tx, filter influxdb.AuditLogFilter) ([]*influxdb.AuditLogEntry, error) {
+	if s.Config.AuditLogRetention > 0 {
+		cutoff := s.Now().Add(-s.Config.AuditLogRetention)
+		if filter.Since == nil || filter.Since.Before(cutoff) {
+			filter.Since = &cutoff
+		}
+	}
+
+	b, err := tx.Bucket(auditLogBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries are scanned in the order of time, through the index of the organization if there is one.
+	var prefix []byte
+	scan := b
+	if filter.OrgID != nil {
+		if prefix, err = filter.OrgID.Encode(); err != nil {
+			return nil, err
+		}
+		if scan, err = tx.Bucket(auditLogOrgIndex); err != nil {
+			return nil, err
+		}
+	}
+
+	cur, err := scan.Cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	var k, v []byte
+	if prefix != nil {
+		k, v = cur.Seek(prefix)
+	} else {
+		k, v = cur.First()
+	}
+
+	es := []*influxdb.AuditLogEntry{}
+	for ; k != nil && bytes.HasPrefix(k, prefix); k, v = cur.Next() {
+		t := decodeAuditLogKeyTime(k[len(prefix):])
+		if filter.Since != nil && t.Before(*filter.Since) {
+			continue
+		}
+		if filter.Until != nil && !t.Before(*filter.Until) {
+			break
+		}
+
+		if prefix != nil {
+			// The index refers to the key of the entry.
+			if v, err = b.Get(v); err != nil {
+				return nil, err
+			}
+		}
+
+		e := &influxdb.AuditLogEntry{}
+		if err := json.Unmarshal(v, e); err != nil {
+			return nil, err
+		}
+		if filter.Matches(e) {
+			es = append(es, e)
+		}
+	}
+	return es, nil
+}
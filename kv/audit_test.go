@@ -0,0 +1,162 @@
+package kv_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func newAuditTestService(t *testing.T) (*kv.Service, *mock.TimeGenerator, func()) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	now := &mock.TimeGenerator{FakeValue: time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)}
+	svc := kv.NewService(s)
+	svc.TimeGenerator = now
+	if err := svc.Initialize(context.Background()); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+	return svc, now, closeStore
+}
+
+func TestService_AuditLog(t *testing.T) {
+	svc, _, done := newAuditTestService(t)
+	defer done()
+
+	org := &influxdb.Organization{Name: "o1"}
+	if err := svc.CreateOrganization(context.Background(), org); err != nil {
+		t.Fatal(err)
+	}
+
+	user := &influxdb.User{Name: "u1"}
+	if err := svc.CreateUser(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	caller := &influxdb.Authorization{ID: 10, UserID: user.ID, OrgID: org.ID, Status: influxdb.Active}
+	ctx := icontext.SetAuthorizer(context.Background(), caller)
+	ctx = icontext.SetRemoteAddr(ctx, "10.0.0.1:5000")
+
+	b := &influxdb.Bucket{OrgID: org.ID, Name: "b1"}
+	if err := svc.CreateBucket(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	name := "b2"
+	if _, err := svc.UpdateBucket(ctx, b.ID, influxdb.BucketUpdate{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DeleteBucket(ctx, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	a := &influxdb.Authorization{OrgID: org.ID, UserID: user.ID, Permissions: influxdb.OperPermissions()}
+	if err := svc.CreateAuthorization(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.PutSecret(ctx, org.ID, "api", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	// Recording the use of a token is not a change worth auditing.
+	lastUsed := svc.Now()
+	if _, err := svc.UpdateAuthorization(ctx, a.ID, &influxdb.AuthorizationUpdate{LastUsedAt: &lastUsed}); err != nil {
+		t.Fatal(err)
+	}
+
+	bucketType := influxdb.BucketsResourceType
+	es, _, err := svc.FindAuditLogEntries(context.Background(), influxdb.AuditLogFilter{
+		OrgID:        &org.ID,
+		ResourceType: &bucketType,
+	}, influxdb.FindOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != 3 {
+		t.Fatalf("expected 3 bucket entries, got %d", len(es))
+	}
+	for i, action := range []influxdb.AuditAction{influxdb.AuditCreate, influxdb.AuditUpdate, influxdb.AuditDelete} {
+		e := es[i]
+		if e.Action != action || e.ResourceID != b.ID || e.OrgID != org.ID {
+			t.Errorf("entry %d: unexpected %s of %s in %s", i, e.Action, e.ResourceID, e.OrgID)
+		}
+		if e.UserID != user.ID || e.AuthorizerKind != influxdb.AuthorizationKind || e.AuthorizerID != 10 || e.RemoteAddr != "10.0.0.1:5000" {
+			t.Errorf("entry %d: unexpected caller %+v", i, e)
+		}
+	}
+	if got, want := string(es[1].Before), `{"name":"b1"}`; got != want {
+		t.Errorf("unexpected before of update: got %s, want %s", got, want)
+	}
+	if got, want := string(es[1].After), `{"name":"b2"}`; got != want {
+		t.Errorf("unexpected after of update: got %s, want %s", got, want)
+	}
+	if es[0].Before != nil || es[2].After != nil {
+		t.Errorf("expected no before on create and no after on delete")
+	}
+
+	all, _, err := svc.FindAuditLogEntries(context.Background(), influxdb.AuditLogFilter{OrgID: &org.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range all {
+		switch e.ResourceType {
+		case influxdb.AuthorizationsResourceType:
+			if e.Action != influxdb.AuditCreate {
+				t.Errorf("unexpected %s of authorization", e.Action)
+			}
+			var fields map[string]interface{}
+			if err := json.Unmarshal(e.After, &fields); err != nil {
+				t.Fatal(err)
+			}
+			if fields["token"] != "[REDACTED]" {
+				t.Errorf("expected token to be redacted, got %v", fields["token"])
+			}
+		case influxdb.SecretsResourceType:
+			if got, want := string(e.After), `{"key":"api"}`; got != want {
+				t.Errorf("unexpected secret change: got %s, want %s", got, want)
+			}
+		}
+	}
+	if all[len(all)-1].ResourceType != influxdb.OrgsResourceType || all[len(all)-1].Action != influxdb.AuditCreate {
+		t.Errorf("expected the oldest entry to be the creation of the org, got %+v", all[len(all)-1])
+	}
+}
+
+func TestService_AuditLogRetention(t *testing.T) {
+	svc, now, done := newAuditTestService(t)
+	defer done()
+	svc.Config.AuditLogRetention = time.Hour
+
+	ctx := context.Background()
+	o1 := &influxdb.Organization{Name: "o1"}
+	if err := svc.CreateOrganization(ctx, o1); err != nil {
+		t.Fatal(err)
+	}
+
+	now.FakeValue = now.FakeValue.Add(2 * time.Hour)
+	o2 := &influxdb.Organization{Name: "o2"}
+	if err := svc.CreateOrganization(ctx, o2); err != nil {
+		t.Fatal(err)
+	}
+
+	es, _, err := svc.FindAuditLogEntries(ctx, influxdb.AuditLogFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != 1 || es[0].ResourceID != o2.ID {
+		t.Fatalf("expected only the creation of o2 to be kept, got %d entries", len(es))
+	}
+
+	es, _, err = svc.FindAuditLogEntries(ctx, influxdb.AuditLogFilter{OrgID: &o1.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != 0 {
+		t.Fatalf("expected the entries of o1 to be removed, got %d entries", len(es))
+	}
+}
@@ -262,6 +262,10 @@ func (s *Service) createAuthorization(ctx context.Context, tx Tx, a *influxdb.Au
 		return influxdb.ErrUnableToCreateToken
 	}
 
+	if err := s.validAuthorizationRoles(ctx, tx, a); err != nil {
+		return err
+	}
+
 	if err := s.uniqueAuthToken(ctx, tx, a); err != nil {
 		return err
 	}
@@ -433,7 +437,7 @@ func (s *Service) deleteAuthorization(ctx context.Context, tx Tx, id influxdb.ID
 	return nil
 }
 
-// UpdateAuthorization updates the status and description if available.
+// UpdateAuthorization updates the status, description and expiry if available.
 func (s *Service) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
 	var a *influxdb.Authorization
 	var err error
@@ -456,6 +460,15 @@ func (s *Service) updateAuthorization(ctx context.Context, tx Tx, id influxdb.ID
 	if upd.Description != nil {
 		a.Description = *upd.Description
 	}
+	if upd.ExpiresAt != nil {
+		a.ExpiresAt = upd.ExpiresAt
+	}
+	if upd.ClearExpiresAt {
+		a.ExpiresAt = nil
+	}
+	if upd.LastUsedAt != nil {
+		a.LastUsedAt = upd.LastUsedAt
+	}
 
 	v, err := encodeAuthorization(a)
 	if err != nil {
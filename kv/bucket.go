@@ -382,6 +382,16 @@ func (s *Service) createBucket(ctx context.Context, tx Tx, b *influxdb.Bucket) e
 		return err
 	}
 
+	if b.Schema != nil {
+		if err := b.Schema.Valid(); err != nil {
+			return err
+		}
+	}
+
+	if err := influxdb.ValidStorageQuota(b.StorageQuotaBytes); err != nil {
+		return err
+	}
+
 	b.ID = s.IDGenerator.ID()
 	b.CreatedAt = s.Now()
 	b.UpdatedAt = s.Now()
@@ -591,6 +601,13 @@ func (s *Service) updateBucket(ctx context.Context, tx Tx, id influxdb.ID, upd i
 		b.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.StorageQuotaBytes != nil {
+		if err := influxdb.ValidStorageQuota(*upd.StorageQuotaBytes); err != nil {
+			return nil, err
+		}
+		b.StorageQuotaBytes = *upd.StorageQuotaBytes
+	}
+
 	if upd.Description != nil {
 		b.Description = *upd.Description
 	}
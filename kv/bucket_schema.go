@@ -0,0 +1,97 @@
+package kv
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.BucketSchemaService = (*Service)(nil)
+
+const bucketSchemaUpdatedEvent = "Bucket Schema Updated"
+
+// FindBucketSchema returns the schema of a bucket.
+func (s *Service) FindBucketSchema(ctx context.Context, bucketID influxdb.ID) (*influxdb.BucketSchema, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var sch *influxdb.BucketSchema
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := s.findBucketByID(ctx, tx, bucketID)
+		if err != nil {
+			return err
+		}
+		if b.Schema == nil {
+			return &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  "bucket has no schema",
+			}
+		}
+		sch = b.Schema
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindBucketSchema,
+			Err: err,
+		}
+	}
+	return sch, nil
+}
+
+// PutBucketSchema replaces the schema of a bucket.
+func (s *Service) PutBucketSchema(ctx context.Context, bucketID influxdb.ID, sch *influxdb.BucketSchema) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := sch.Valid(); err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpPutBucketSchema,
+			Err: err,
+		}
+	}
+
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.setBucketSchema(ctx, tx, bucketID, sch)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpPutBucketSchema,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// DeleteBucketSchema removes the schema of a bucket.
+func (s *Service) DeleteBucketSchema(ctx context.Context, bucketID influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.setBucketSchema(ctx, tx, bucketID, nil)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteBucketSchema,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) setBucketSchema(ctx context.Context, tx Tx, bucketID influxdb.ID, sch *influxdb.BucketSchema) error {
+	b, err := s.findBucketByID(ctx, tx, bucketID)
+	if err != nil {
+		return err
+	}
+
+	b.Schema = sch
+	b.UpdatedAt = s.Now()
+
+	if err := s.appendBucketEventToLog(ctx, tx, b.ID, bucketSchemaUpdatedEvent); err != nil {
+		return err
+	}
+	return s.putBucket(ctx, tx, b)
+}
@@ -0,0 +1,87 @@
+package kv_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestService_BucketSchema(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new inmem kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing service: %v", err)
+	}
+	o := &influxdb.Organization{Name: "org1"}
+	if err := svc.CreateOrganization(ctx, o); err != nil {
+		t.Fatalf("error creating organization: %v", err)
+	}
+	b := &influxdb.Bucket{Name: "bucket1", OrgID: o.ID}
+	if err := svc.CreateBucket(ctx, b); err != nil {
+		t.Fatalf("error creating bucket: %v", err)
+	}
+
+	_, err = svc.FindBucketSchema(ctx, b.ID)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "bucket has no schema",
+	})
+
+	err = svc.PutBucketSchema(ctx, b.ID, &influxdb.BucketSchema{
+		Measurements: []influxdb.MeasurementSchema{
+			{Name: "cpu", Tags: []string{"host"}, Fields: []influxdb.FieldSchema{{Name: "host", Type: influxdb.FieldTypeFloat}}},
+		},
+	})
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  `schema measurement "cpu" declares "host" more than once`,
+	})
+
+	sch := &influxdb.BucketSchema{
+		Measurements: []influxdb.MeasurementSchema{
+			{Name: "cpu", Tags: []string{"host"}, Fields: []influxdb.FieldSchema{{Name: "usage", Type: influxdb.FieldTypeFloat}}},
+		},
+	}
+	if err := svc.PutBucketSchema(ctx, b.ID, sch); err != nil {
+		t.Fatalf("unexpected error putting schema: %v", err)
+	}
+	got, err := svc.FindBucketSchema(ctx, b.ID)
+	if err != nil {
+		t.Fatalf("unexpected error finding schema: %v", err)
+	}
+	if !reflect.DeepEqual(got, sch) {
+		t.Errorf("got schema %+v, want %+v", got, sch)
+	}
+
+	fb, err := svc.FindBucketByID(ctx, b.ID)
+	if err != nil {
+		t.Fatalf("unexpected error finding bucket: %v", err)
+	}
+	if fb.SchemaType() != influxdb.SchemaTypeExplicit {
+		t.Errorf("got schema type %s, want %s", fb.SchemaType(), influxdb.SchemaTypeExplicit)
+	}
+
+	if err := svc.DeleteBucketSchema(ctx, b.ID); err != nil {
+		t.Fatalf("unexpected error deleting schema: %v", err)
+	}
+	_, err = svc.FindBucketSchema(ctx, b.ID)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "bucket has no schema",
+	})
+
+	err = svc.PutBucketSchema(ctx, influxdb.ID(1), sch)
+	if influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error putting schema of missing bucket, got %v", err)
+	}
+}
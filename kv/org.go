@@ -248,6 +248,10 @@ func (s *Service) createOrganization(ctx context.Context, tx Tx, o *influxdb.Org
 		return err
 	}
 
+	if err := influxdb.ValidStorageQuota(o.StorageQuotaBytes); err != nil {
+		return err
+	}
+
 	o.ID = s.IDGenerator.ID()
 	o.CreatedAt = s.Now()
 	o.UpdatedAt = s.Now()
@@ -404,6 +408,23 @@ func (s *Service) updateOrganization(ctx context.Context, tx Tx, id influxdb.ID,
 		o.Description = *upd.Description
 	}
 
+	if upd.QueryLimits != nil {
+		if err := upd.QueryLimits.Valid(); err != nil {
+			return nil, err
+		}
+		o.QueryLimits = upd.QueryLimits
+		if o.QueryLimits.IsZero() {
+			o.QueryLimits = nil
+		}
+	}
+
+	if upd.StorageQuotaBytes != nil {
+		if err := influxdb.ValidStorageQuota(*upd.StorageQuotaBytes); err != nil {
+			return nil, err
+		}
+		o.StorageQuotaBytes = *upd.StorageQuotaBytes
+	}
+
 	o.UpdatedAt = s.Now()
 
 	if err := s.appendOrganizationEventToLog(ctx, tx, o.ID, organizationUpdatedEvent); err != nil {
@@ -632,6 +653,12 @@ func (s *Service) FindResourceOrganizationID(ctx context.Context, rt influxdb.Re
 			return influxdb.InvalidID(), err
 		}
 		return r.OrgID, nil
+	case influxdb.RolesResourceType:
+		r, err := s.FindRoleByID(ctx, id)
+		if err != nil {
+			return influxdb.InvalidID(), err
+		}
+		return r.OrgID, nil
 	}
 
 	return influxdb.InvalidID(), &influxdb.Error{
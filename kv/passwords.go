@@ -100,6 +100,9 @@ func (s *Service) setPassword(ctx context.Context, tx Tx, name string, password
 	if len(password) < MinPasswordLength {
 		return EShortPassword
 	}
+	if err := s.Config.PasswordPolicy.Check(password); err != nil {
+		return err
+	}
 
 	u, err := s.findUserByName(ctx, tx, name)
 	if err != nil {
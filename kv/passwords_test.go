@@ -505,3 +505,44 @@ func TestService_ComparePassword(t *testing.T) {
 		})
 	}
 }
+
+func TestService_SetPassword_PasswordPolicy(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new inmem kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(s, kv.ServiceConfig{
+		PasswordPolicy: influxdb.PasswordPolicy{
+			MinLength:    10,
+			RequireDigit: true,
+		},
+	})
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing service: %v", err)
+	}
+	if err := svc.CreateUser(ctx, &influxdb.User{Name: "user1"}); err != nil {
+		t.Fatalf("error creating user: %v", err)
+	}
+
+	err = svc.SetPassword(ctx, "user1", "howdydoody")
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "passwords must contain a digit",
+	})
+
+	if err := svc.SetPassword(ctx, "user1", "howdydoody1"); err != nil {
+		t.Fatalf("unexpected error setting password satisfying the policy: %v", err)
+	}
+
+	err = svc.CompareAndSetPassword(ctx, "user1", "howdydoody1", "doody1")
+	influxdbtesting.ErrorsEqual(t, err, kv.EShortPassword)
+
+	err = svc.CompareAndSetPassword(ctx, "user1", "howdydoody1", "howdy1doo")
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "passwords must be at least 10 characters long",
+	})
+}
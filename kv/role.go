@@ -0,0 +1,331 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	roleBucket = []byte("rolesv1")
+)
+
+var _ influxdb.RoleService = (*Service)(nil)
+
+func (s *Service) initializeRoles(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(roleBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindRoleByID retrieves a role by id.
+func (s *Service) FindRoleByID(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	var r *influxdb.Role
+	err := s.kv.View(ctx, func(tx Tx) error {
+		role, err := s.findRoleByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		r = role
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindRoleByID,
+			Err: err,
+		}
+	}
+	return r, nil
+}
+
+func (s *Service) findRoleByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Role, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(roleBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrRoleNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalRole(v)
+}
+
+func unmarshalRole(v []byte) (*influxdb.Role, error) {
+	r := &influxdb.Role{}
+	if err := json.Unmarshal(v, r); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "unable to unmarshal role",
+			Err:  err,
+		}
+	}
+	return r, nil
+}
+
+// FindRoles returns all roles that match the filter.
+func (s *Service) FindRoles(ctx context.Context, filter influxdb.RoleFilter) ([]*influxdb.Role, error) {
+	var rs []*influxdb.Role
+	err := s.kv.View(ctx, func(tx Tx) error {
+		roles, err := s.findRoles(ctx, tx, filter)
+		if err != nil {
+			return err
+		}
+		rs = roles
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindRoles,
+			Err: err,
+		}
+	}
+	return rs, nil
+}
+
+func (s *Service) findRoles(ctx context.Context, tx Tx, filter influxdb.RoleFilter) ([]*influxdb.Role, error) {
+	matches := func(r *influxdb.Role) bool {
+		return (filter.OrgID == nil || r.OrgID == *filter.OrgID) &&
+			(filter.Name == nil || r.Name == *filter.Name)
+	}
+
+	if filter.ID != nil {
+		r, err := s.findRoleByID(ctx, tx, *filter.ID)
+		if err != nil {
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				return []*influxdb.Role{}, nil
+			}
+			return nil, err
+		}
+		if !matches(r) {
+			return []*influxdb.Role{}, nil
+		}
+		return []*influxdb.Role{r}, nil
+	}
+
+	rs := []*influxdb.Role{}
+	err := s.forEachRole(ctx, tx, func(r *influxdb.Role) bool {
+		if matches(r) {
+			rs = append(rs, r)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// forEachRole will iterate through all roles while fn returns true.
+func (s *Service) forEachRole(ctx context.Context, tx Tx, fn func(*influxdb.Role) bool) error {
+	b, err := tx.Bucket(roleBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		r, err := unmarshalRole(v)
+		if err != nil {
+			return err
+		}
+		if !fn(r) {
+			break
+		}
+	}
+	return nil
+}
+
+// uniqueRoleName returns an error if another role of the organization of r has its name.
+func (s *Service) uniqueRoleName(ctx context.Context, tx Tx, r *influxdb.Role) error {
+	rs, err := s.findRoles(ctx, tx, influxdb.RoleFilter{OrgID: &r.OrgID, Name: &r.Name})
+	if err != nil {
+		return err
+	}
+	for _, other := range rs {
+		if other.ID != r.ID {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  "role name is not unique",
+			}
+		}
+	}
+	return nil
+}
+
+// CreateRole creates a role and sets r.ID.
+func (s *Service) CreateRole(ctx context.Context, r *influxdb.Role) error {
+	if err := r.Valid(); err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateRole,
+			Err: err,
+		}
+	}
+
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findOrganizationByID(ctx, tx, r.OrgID); err != nil {
+			return err
+		}
+		if err := s.uniqueRoleName(ctx, tx, r); err != nil {
+			return err
+		}
+
+		r.ID = s.IDGenerator.ID()
+		now := s.Now()
+		r.CreatedAt = now
+		r.UpdatedAt = now
+		return s.putRole(ctx, tx, r)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateRole,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// PutRole will put a role without setting an ID.
+func (s *Service) PutRole(ctx context.Context, r *influxdb.Role) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.putRole(ctx, tx, r)
+	})
+}
+
+func (s *Service) putRole(ctx context.Context, tx Tx, r *influxdb.Role) error {
+	if r.Permissions == nil {
+		r.Permissions = []influxdb.Permission{}
+	}
+
+	v, err := json.Marshal(r)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	encodedID, err := r.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(roleBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
+
+// UpdateRole updates a role according the parameters set on upd.
+func (s *Service) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	var r *influxdb.Role
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		role, err := s.findRoleByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := upd.Valid(role.OrgID); err != nil {
+			return err
+		}
+
+		upd.Apply(role)
+		if upd.Name != nil {
+			if err := s.uniqueRoleName(ctx, tx, role); err != nil {
+				return err
+			}
+		}
+		role.UpdatedAt = s.Now()
+		if err := s.putRole(ctx, tx, role); err != nil {
+			return err
+		}
+		r = role
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpUpdateRole,
+			Err: err,
+		}
+	}
+	return r, nil
+}
+
+// DeleteRole deletes a role and the mappings of its members.
+func (s *Service) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findRoleByID(ctx, tx, id); err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Err:  err,
+			}
+		}
+
+		b, err := tx.Bucket(roleBucket)
+		if err != nil {
+			return err
+		}
+		if err := b.Delete(encodedID); err != nil {
+			return err
+		}
+
+		return s.deleteUserResourceMappings(ctx, tx, influxdb.UserResourceMappingFilter{
+			ResourceID:   id,
+			ResourceType: influxdb.RolesResourceType,
+		})
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteRole,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// validAuthorizationRoles returns an error if a role of the authorization
+// does not exist or is not a role of the organization of the authorization.
+func (s *Service) validAuthorizationRoles(ctx context.Context, tx Tx, a *influxdb.Authorization) error {
+	for _, id := range a.RoleIDs {
+		r, err := s.findRoleByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if r.OrgID != a.OrgID {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "role does not belong to the organization of the authorization",
+			}
+		}
+	}
+	return nil
+}
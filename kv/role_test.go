@@ -0,0 +1,82 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBoltRoleService(t *testing.T) {
+	influxdbtesting.RoleService(initBoltRoleService, t)
+}
+
+func TestInmemRoleService(t *testing.T) {
+	influxdbtesting.RoleService(initInmemRoleService, t)
+}
+
+func initBoltRoleService(f influxdbtesting.RoleFields, t *testing.T) (influxdb.RoleService, string, func()) {
+	s, closeBolt, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initRoleService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initInmemRoleService(f influxdbtesting.RoleFields, t *testing.T) (influxdb.RoleService, string, func()) {
+	s, closeBolt, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initRoleService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initRoleService(s kv.Store, f influxdbtesting.RoleFields, t *testing.T) (influxdb.RoleService, string, func()) {
+	svc := kv.NewService(s)
+	svc.IDGenerator = f.IDGenerator
+	svc.TimeGenerator = f.TimeGenerator
+	if svc.TimeGenerator == nil {
+		svc.TimeGenerator = influxdb.RealTimeGenerator{}
+	}
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing role service: %v", err)
+	}
+	for _, o := range f.Organizations {
+		if err := svc.PutOrganization(ctx, o); err != nil {
+			t.Fatalf("failed to populate organizations: %v", err)
+		}
+	}
+	for _, r := range f.Roles {
+		if err := svc.PutRole(ctx, r); err != nil {
+			t.Fatalf("failed to populate roles: %v", err)
+		}
+	}
+	for _, m := range f.UserResourceMappings {
+		if err := svc.CreateUserResourceMapping(ctx, m); err != nil {
+			t.Fatalf("failed to populate user resource mappings: %v", err)
+		}
+	}
+
+	done := func() {
+		for _, r := range f.Roles {
+			if err := svc.DeleteRole(ctx, r.ID); err != nil {
+				t.Logf("failed to remove role: %v", err)
+			}
+		}
+	}
+	return svc, kv.OpPrefix, done
+}
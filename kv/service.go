@@ -37,14 +37,16 @@ func NewService(kv Store, configs ...ServiceConfig) *Service {
 		IDGenerator:    snowflake.NewIDGenerator(),
 		TokenGenerator: rand.NewTokenGenerator(64),
 		Hash:           &Bcrypt{},
-		kv:             kv,
 		TimeGenerator:  influxdb.RealTimeGenerator{},
 	}
+	s.kv = &auditStore{Store: kv, s: s, ids: snowflake.NewIDGenerator()}
 
 	if len(configs) > 0 {
 		s.Config = configs[0]
 	} else {
 		s.Config.SessionLength = influxdb.DefaultSessionLength
+		s.Config.AuditLogRetention = influxdb.DefaultAuditLogRetention
+		s.Config.PasswordPolicy = influxdb.DefaultPasswordPolicy
 	}
 
 	return s
@@ -53,11 +55,20 @@ func NewService(kv Store, configs ...ServiceConfig) *Service {
 // ServiceConfig allows us to configure Services
 type ServiceConfig struct {
 	SessionLength time.Duration
+	// AuditLogRetention is the time entries are kept in the audit log; 0 keeps them forever.
+	AuditLogRetention time.Duration
+	// PasswordPolicy is checked when passwords are set. Passwords shorter
+	// than MinPasswordLength are never allowed.
+	PasswordPolicy influxdb.PasswordPolicy
 }
 
 // Initialize creates Buckets needed.
 func (s *Service) Initialize(ctx context.Context) error {
 	return s.kv.Update(ctx, func(tx Tx) error {
+		if err := s.initializeAuditLog(ctx, tx); err != nil {
+			return err
+		}
+
 		if err := s.initializeAuths(ctx, tx); err != nil {
 			return err
 		}
@@ -94,10 +105,22 @@ func (s *Service) Initialize(ctx context.Context) error {
 			return err
 		}
 
+		if err := s.initializeTaskBackfills(ctx, tx); err != nil {
+			return err
+		}
+
 		if err := s.initializePasswords(ctx, tx); err != nil {
 			return err
 		}
 
+		if err := s.initializeReplications(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeRoles(ctx, tx); err != nil {
+			return err
+		}
+
 		if err := s.initializeScraperTargets(ctx, tx); err != nil {
 			return err
 		}
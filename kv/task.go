@@ -44,6 +44,9 @@ func (s *Service) initializeTasks(ctx context.Context, tx Tx) error {
 	if _, err := tx.Bucket(taskIndexBucket); err != nil {
 		return err
 	}
+	if _, err := tx.Bucket(taskDependentsBucket); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -509,6 +512,16 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 		task.Offset = opt.Offset.String()
 	}
 
+	if len(tc.DependsOn) > 0 {
+		task.DependsOn = append([]influxdb.ID(nil), tc.DependsOn...)
+		if err := s.validateTaskDependencies(ctx, tx, task); err != nil {
+			return nil, err
+		}
+		if err := s.putTaskDependencies(ctx, tx, task.ID, nil, task.DependsOn); err != nil {
+			return nil, err
+		}
+	}
+
 	taskBucket, err := tx.Bucket(taskBucket)
 	if err != nil {
 		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
@@ -622,23 +635,39 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		task.LatestCompleted = *upd.LatestCompleted
 	}
 
+	if upd.DependsOn != nil {
+		old := task.DependsOn
+		task.DependsOn = append([]influxdb.ID(nil), (*upd.DependsOn)...)
+		if err := s.validateTaskDependencies(ctx, tx, task); err != nil {
+			return nil, err
+		}
+		if err := s.putTaskDependencies(ctx, tx, task.ID, old, task.DependsOn); err != nil {
+			return nil, err
+		}
+	}
+
 	task.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
 	// save the updated task
+	return task, s.putTask(ctx, tx, task)
+}
+
+// putTask saves task, without changing any of its indexes.
+func (s *Service) putTask(ctx context.Context, tx Tx, task *influxdb.Task) error {
 	bucket, err := tx.Bucket(taskBucket)
 	if err != nil {
-		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
 	}
-	key, err := taskKey(id)
+	key, err := taskKey(task.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	taskBytes, err := json.Marshal(task)
 	if err != nil {
-		return nil, influxdb.ErrInternalTaskServiceError(err)
+		return influxdb.ErrInternalTaskServiceError(err)
 	}
 
-	return task, bucket.Put(key, taskBytes)
+	return bucket.Put(key, taskBytes)
 }
 
 // DeleteTask removes a task by ID and purges all associated data and scheduled runs.
@@ -689,6 +718,11 @@ func (s *Service) deleteTask(ctx context.Context, tx Tx, id influxdb.ID) error {
 		return influxdb.ErrUnexpectedTaskBucketErr(err)
 	}
 
+	// remove the task from the dependency graph
+	if err := s.deleteTaskDependencies(ctx, tx, task); err != nil {
+		return err
+	}
+
 	// remove latest completed
 	lastCompletedKey, err := taskLatestCompletedKey(task.ID)
 	if err != nil {
@@ -1546,6 +1580,13 @@ func (s *Service) finishRun(ctx context.Context, tx Tx, taskID, runID influxdb.I
 		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
 	}
 
+	// a successful run triggers the tasks that depend on its task
+	if r.Status == backend.RunSuccess.String() {
+		if err := s.queueDependentRuns(ctx, tx, r); err != nil {
+			return nil, err
+		}
+	}
+
 	return r, nil
 }
 
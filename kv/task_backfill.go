@@ -0,0 +1,135 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+// Task Backfill Storage Schema
+// taskBackfillBucket:
+//   <backfillID>: backfill data storage
+
+var (
+	taskBackfillBucket = []byte("taskBackfillsv1")
+)
+
+func (s *Service) initializeTaskBackfills(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(taskBackfillBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindBackfillByID returns a single backfill.
+func (s *Service) FindBackfillByID(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error) {
+	var b *influxdb.Backfill
+	err := s.kv.View(ctx, func(tx Tx) error {
+		bf, err := s.findBackfillByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		b = bf
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *Service) findBackfillByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Backfill, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid backfill id",
+			Err:  err,
+		}
+	}
+
+	bucket, err := tx.Bucket(taskBackfillBucket)
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	v, err := bucket.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, influxdb.ErrBackfillNotFound
+	}
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	b := &influxdb.Backfill{}
+	if err := json.Unmarshal(v, b); err != nil {
+		return nil, influxdb.ErrInternalTaskServiceError(err)
+	}
+	return b, nil
+}
+
+// FindBackfills returns the backfills that match filter, newest first.
+func (s *Service) FindBackfills(ctx context.Context, filter influxdb.BackfillFilter) ([]*influxdb.Backfill, error) {
+	bs := []*influxdb.Backfill{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		bucket, err := tx.Bucket(taskBackfillBucket)
+		if err != nil {
+			return influxdb.ErrUnexpectedTaskBucketErr(err)
+		}
+
+		c, err := bucket.Cursor()
+		if err != nil {
+			return influxdb.ErrUnexpectedTaskBucketErr(err)
+		}
+
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			b := &influxdb.Backfill{}
+			if err := json.Unmarshal(v, b); err != nil {
+				return influxdb.ErrInternalTaskServiceError(err)
+			}
+			if filter.Task != nil && b.TaskID != *filter.Task {
+				continue
+			}
+			bs = append(bs, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bs, nil
+}
+
+// PutBackfill creates or replaces a backfill.
+func (s *Service) PutBackfill(ctx context.Context, b *influxdb.Backfill) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.putBackfill(ctx, tx, b)
+	})
+}
+
+func (s *Service) putBackfill(ctx context.Context, tx Tx, b *influxdb.Backfill) error {
+	encodedID, err := b.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid backfill id",
+			Err:  err,
+		}
+	}
+
+	v, err := json.Marshal(b)
+	if err != nil {
+		return influxdb.ErrInternalTaskServiceError(err)
+	}
+
+	bucket, err := tx.Bucket(taskBackfillBucket)
+	if err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	if err := bucket.Put(encodedID, v); err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend"
+)
+
+// Task Dependency Storage Schema
+// taskDependentsBucket:
+//   <upstreamTaskID>/<downstreamTaskID>: index of the tasks that depend on a task
+
+var taskDependentsBucket = []byte("taskDependentsv1")
+
+func taskDependentKey(upstreamID, downstreamID influxdb.ID) ([]byte, error) {
+	encodedUpstreamID, err := upstreamID.Encode()
+	if err != nil {
+		return nil, influxdb.ErrInvalidTaskID
+	}
+	encodedDownstreamID, err := downstreamID.Encode()
+	if err != nil {
+		return nil, influxdb.ErrInvalidTaskID
+	}
+
+	return []byte(string(encodedUpstreamID) + "/" + string(encodedDownstreamID)), nil
+}
+
+// validateTaskDependencies checks that every dependency of task exists in its organization,
+// and that following the dependencies never leads back to task.
+// Duplicate dependencies are removed from task.
+func (s *Service) validateTaskDependencies(ctx context.Context, tx Tx, task *influxdb.Task) error {
+	seen := make(map[influxdb.ID]bool, len(task.DependsOn))
+	deps := task.DependsOn[:0]
+	for _, id := range task.DependsOn {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if id == task.ID {
+			return influxdb.ErrTaskDependencyCycle
+		}
+
+		dep, err := s.findTaskByID(ctx, tx, id)
+		if err == influxdb.ErrTaskNotFound {
+			return influxdb.ErrTaskDependencyNotFound(id)
+		}
+		if err != nil {
+			return err
+		}
+		if dep.OrganizationID != task.OrganizationID {
+			return influxdb.ErrTaskDependencyNotFound(id)
+		}
+		deps = append(deps, id)
+	}
+	task.DependsOn = deps
+
+	// Walk upstream from the dependencies; reaching task means it would depend on itself.
+	visited := make(map[influxdb.ID]bool)
+	queue := append([]influxdb.ID(nil), task.DependsOn...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == task.ID {
+			return influxdb.ErrTaskDependencyCycle
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		t, err := s.findTaskByID(ctx, tx, id)
+		if err == influxdb.ErrTaskNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		queue = append(queue, t.DependsOn...)
+	}
+
+	return nil
+}
+
+// putTaskDependencies replaces the dependent index entries of the task with ID id, from old to new dependencies.
+func (s *Service) putTaskDependencies(ctx context.Context, tx Tx, id influxdb.ID, old, new []influxdb.ID) error {
+	b, err := tx.Bucket(taskDependentsBucket)
+	if err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	for _, upstreamID := range old {
+		key, err := taskDependentKey(upstreamID, id)
+		if err != nil {
+			return err
+		}
+		if err := b.Delete(key); err != nil {
+			return influxdb.ErrUnexpectedTaskBucketErr(err)
+		}
+	}
+
+	for _, upstreamID := range new {
+		key, err := taskDependentKey(upstreamID, id)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(key, nil); err != nil {
+			return influxdb.ErrUnexpectedTaskBucketErr(err)
+		}
+	}
+
+	return nil
+}
+
+// findTaskDependents returns the IDs of the tasks that depend on the task with ID id.
+func (s *Service) findTaskDependents(ctx context.Context, tx Tx, id influxdb.ID) ([]influxdb.ID, error) {
+	b, err := tx.Bucket(taskDependentsBucket)
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	c, err := b.Cursor()
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, influxdb.ErrInvalidTaskID
+	}
+	prefix := append(encodedID, '/')
+
+	var ids []influxdb.ID
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		var downstreamID influxdb.ID
+		if err := downstreamID.Decode(k[len(prefix):]); err != nil {
+			return nil, influxdb.ErrInvalidTaskID
+		}
+		ids = append(ids, downstreamID)
+	}
+	return ids, nil
+}
+
+// deleteTaskDependencies removes the task with ID id from the dependency graph:
+// its own dependencies, and the dependencies other tasks have on it.
+func (s *Service) deleteTaskDependencies(ctx context.Context, tx Tx, task *influxdb.Task) error {
+	if err := s.putTaskDependencies(ctx, tx, task.ID, task.DependsOn, nil); err != nil {
+		return err
+	}
+
+	dependents, err := s.findTaskDependents(ctx, tx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range dependents {
+		dependent, err := s.findTaskByID(ctx, tx, id)
+		if err != nil && err != influxdb.ErrTaskNotFound {
+			return err
+		}
+		if err := s.putTaskDependencies(ctx, tx, id, []influxdb.ID{task.ID}, nil); err != nil {
+			return err
+		}
+		if dependent == nil {
+			continue
+		}
+
+		deps := dependent.DependsOn[:0]
+		for _, dep := range dependent.DependsOn {
+			if dep != task.ID {
+				deps = append(deps, dep)
+			}
+		}
+		dependent.DependsOn = deps
+		if err := s.putTask(ctx, tx, dependent); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queueDependentRuns queues a run of every active task that depends on the task of run,
+// for the same schedule time as run.
+func (s *Service) queueDependentRuns(ctx context.Context, tx Tx, run *influxdb.Run) error {
+	dependents, err := s.findTaskDependents(ctx, tx, run.TaskID)
+	if err != nil {
+		return err
+	}
+
+	if len(dependents) == 0 {
+		return nil
+	}
+
+	scheduledFor, err := run.ScheduledForTime()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range dependents {
+		t, err := s.findTaskByID(ctx, tx, id)
+		if err == influxdb.ErrTaskNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if t.Status != string(backend.TaskActive) {
+			continue
+		}
+
+		if _, err := s.forceRun(ctx, tx, id, scheduledFor.Unix()); err != nil && err != influxdb.ErrTaskRunAlreadyQueued {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -355,6 +355,10 @@ func (s *Service) updateUser(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		u.Name = *upd.Name
 	}
 
+	if upd.Origin != nil {
+		u.Origin = *upd.Origin
+	}
+
 	if err := s.appendUserEventToLog(ctx, tx, u.ID, userUpdatedEvent); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,128 @@
+package ldap
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+const (
+	// DefaultUserFilter finds users by their uid.
+	DefaultUserFilter = "(uid=%s)"
+	// DefaultGroupAttribute is the attribute of users listing the groups they are members of.
+	DefaultGroupAttribute = "memberOf"
+	// DefaultTimeout is the default timeout of requests to the LDAP server.
+	DefaultTimeout = 10 * time.Second
+)
+
+// Config is the configuration of the LDAP server users sign in with.
+type Config struct {
+	// URL of the LDAP server, such as ldap://ldap.example.com:389 or ldaps://ldap.example.com:636.
+	URL string
+	// StartTLS upgrades ldap:// connections to TLS.
+	StartTLS bool
+	// InsecureSkipVerify skips verifying the certificate of the LDAP server.
+	InsecureSkipVerify bool
+	// Timeout of requests to the LDAP server.
+	Timeout time.Duration
+
+	// BindDN and BindPassword are the credentials users are searched with.
+	// Users are searched anonymously if BindDN is empty.
+	BindDN       string
+	BindPassword string
+
+	// UserBaseDN is the DN users are searched under.
+	UserBaseDN string
+	// UserFilter is the filter finding a user, with %s replaced by the escaped user name.
+	UserFilter string
+	// GroupAttribute is the attribute of users listing the DNs of their groups.
+	GroupAttribute string
+
+	// GroupMappings map the groups of users to the organizations they are members or owners of.
+	GroupMappings []GroupMapping
+}
+
+// DefaultConfig returns the configuration of a LDAP server at url
+// finding users by their uid under userBaseDN.
+func DefaultConfig(url, userBaseDN string) Config {
+	return Config{
+		URL:            url,
+		Timeout:        DefaultTimeout,
+		UserBaseDN:     userBaseDN,
+		UserFilter:     DefaultUserFilter,
+		GroupAttribute: DefaultGroupAttribute,
+	}
+}
+
+// Validate returns an error if the configuration is incomplete.
+func (c Config) Validate() error {
+	switch {
+	case c.URL == "":
+		return &influxdb.Error{Code: influxdb.EInvalid, Msg: "LDAP URL is required"}
+	case c.UserBaseDN == "":
+		return &influxdb.Error{Code: influxdb.EInvalid, Msg: "LDAP user base DN is required"}
+	case strings.Count(c.UserFilter, "%s") != 1:
+		return &influxdb.Error{Code: influxdb.EInvalid, Msg: "LDAP user filter must contain %s exactly once"}
+	case c.GroupAttribute == "":
+		return &influxdb.Error{Code: influxdb.EInvalid, Msg: "LDAP group attribute is required"}
+	}
+	return nil
+}
+
+// GroupMapping makes the members of a LDAP group members or owners of an organization.
+type GroupMapping struct {
+	GroupDN string
+	Org     string
+	Role    influxdb.UserType
+}
+
+// String returns the mapping in the form parsed by ParseGroupMappings.
+func (m GroupMapping) String() string {
+	return fmt.Sprintf("%s:%s:%s", m.GroupDN, m.Org, m.Role)
+}
+
+// ParseGroupMappings parses group mappings separated by semicolons,
+// each in the form <group DN>:<organization name>:<member|owner>.
+func ParseGroupMappings(s string) ([]GroupMapping, error) {
+	var ms []GroupMapping
+	for _, v := range strings.Split(s, ";") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		i := strings.LastIndex(v, ":")
+		j := -1
+		if i > 0 {
+			j = strings.LastIndex(v[:i], ":")
+		}
+		if j <= 0 {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("LDAP group mapping %q is not in the form <group DN>:<organization>:<member|owner>", v),
+			}
+		}
+
+		m := GroupMapping{
+			GroupDN: strings.TrimSpace(v[:j]),
+			Org:     strings.TrimSpace(v[j+1 : i]),
+			Role:    influxdb.UserType(strings.TrimSpace(v[i+1:])),
+		}
+		if m.GroupDN == "" || m.Org == "" {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("LDAP group mapping %q is not in the form <group DN>:<organization>:<member|owner>", v),
+			}
+		}
+		if m.Role != influxdb.Member && m.Role != influxdb.Owner {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("LDAP group mapping %q has role %q; expected %q or %q", v, m.Role, influxdb.Member, influxdb.Owner),
+			}
+		}
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
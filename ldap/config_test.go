@@ -0,0 +1,71 @@
+package ldap_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/ldap"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestParseGroupMappings(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want []ldap.GroupMapping
+		err  error
+	}{
+		{
+			name: "empty",
+			s:    "",
+		},
+		{
+			name: "mappings",
+			s:    "cn=admins,ou=groups,dc=example,dc=com:ops:owner; cn=devs,ou=groups,dc=example,dc=com:ops:member;",
+			want: []ldap.GroupMapping{
+				{GroupDN: "cn=admins,ou=groups,dc=example,dc=com", Org: "ops", Role: influxdb.Owner},
+				{GroupDN: "cn=devs,ou=groups,dc=example,dc=com", Org: "ops", Role: influxdb.Member},
+			},
+		},
+		{
+			name: "missing organization",
+			s:    "cn=admins,dc=example,dc=com:owner",
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `LDAP group mapping "cn=admins,dc=example,dc=com:owner" is not in the form <group DN>:<organization>:<member|owner>`,
+			},
+		},
+		{
+			name: "unknown role",
+			s:    "cn=admins,dc=example,dc=com:ops:admin",
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `LDAP group mapping "cn=admins,dc=example,dc=com:ops:admin" has role "admin"; expected "member" or "owner"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ldap.ParseGroupMappings(tt.s)
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := ldap.DefaultConfig("ldap://localhost:389", "dc=example,dc=com")
+	if err := c.Validate(); err != nil {
+		t.Errorf("unexpected error validating default config: %v", err)
+	}
+
+	c.UserFilter = "(uid=alice)"
+	influxdbtesting.ErrorsEqual(t, c.Validate(), &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "LDAP user filter must contain %s exactly once",
+	})
+}
@@ -0,0 +1,344 @@
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+	goldap "gopkg.in/ldap.v3"
+)
+
+var _ influxdb.PasswordsService = (*PasswordsService)(nil)
+
+var (
+	// EIncorrectPassword is returned when the credentials are rejected.
+	// It does not tell whether the user exists.
+	EIncorrectPassword = &influxdb.Error{
+		Code: influxdb.EForbidden,
+		Msg:  "your username or password is incorrect",
+	}
+
+	// EManagedPassword is returned when changing the password of a LDAP user.
+	EManagedPassword = &influxdb.Error{
+		Code: influxdb.EMethodNotAllowed,
+		Msg:  "passwords of LDAP users are managed by the LDAP server",
+	}
+)
+
+// PasswordsService checks the passwords of users against a LDAP server.
+// Users are created on their first signin, with the LDAP origin, and their memberships of the
+// organizations named by the group mappings are synchronized with their groups on every signin.
+// The passwords of users that are not found in LDAP are checked by Local, as are those of local
+// users whose name is also found in LDAP: a local user is only managed by the LDAP server once an
+// administrator sets its origin to LDAP.
+type PasswordsService struct {
+	Config Config
+	Logger *zap.Logger
+
+	Local                      influxdb.PasswordsService
+	UserService                influxdb.UserService
+	OrganizationService        influxdb.OrganizationService
+	UserResourceMappingService influxdb.UserResourceMappingService
+}
+
+// NewPasswordsService creates a PasswordsService for the LDAP server of c.
+func NewPasswordsService(c Config, local influxdb.PasswordsService, users influxdb.UserService, orgs influxdb.OrganizationService, urms influxdb.UserResourceMappingService) (*PasswordsService, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &PasswordsService{
+		Config:                     c,
+		Logger:                     zap.NewNop(),
+		Local:                      local,
+		UserService:                users,
+		OrganizationService:        orgs,
+		UserResourceMappingService: urms,
+	}, nil
+}
+
+// SetPassword sets the password of a user that is not a LDAP user.
+func (s *PasswordsService) SetPassword(ctx context.Context, name string, password string) error {
+	if err := s.checkLocal(ctx, name); err != nil {
+		return err
+	}
+	return s.Local.SetPassword(ctx, name, password)
+}
+
+// CompareAndSetPassword changes the password of a user that is not a LDAP user.
+func (s *PasswordsService) CompareAndSetPassword(ctx context.Context, name string, old string, new string) error {
+	if err := s.checkLocal(ctx, name); err != nil {
+		return err
+	}
+	return s.Local.CompareAndSetPassword(ctx, name, old, new)
+}
+
+// ComparePassword binds to the LDAP server as the user with the password.
+// Users are provisioned and their organization memberships are synchronized after binding.
+func (s *PasswordsService) ComparePassword(ctx context.Context, name string, password string) error {
+	u, err := s.findLocalUser(ctx, name)
+	if err != nil {
+		return err
+	}
+	if u != nil && u.Origin != influxdb.UserOriginLDAP {
+		return s.Local.ComparePassword(ctx, name, password)
+	}
+
+	conn, err := s.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	entry, err := s.findUser(conn, name)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return s.Local.ComparePassword(ctx, name, password)
+	}
+
+	// The LDAP server treats a bind without a password as an anonymous bind.
+	if password == "" {
+		return EIncorrectPassword
+	}
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return EIncorrectPassword
+		}
+		return unavailableError(err)
+	}
+
+	return s.provision(ctx, u, name, entry.GetAttributeValues(s.Config.GroupAttribute))
+}
+
+// checkLocal returns an error if name is a LDAP user.
+func (s *PasswordsService) checkLocal(ctx context.Context, name string) error {
+	u, err := s.findLocalUser(ctx, name)
+	if err != nil {
+		return err
+	}
+	if u != nil {
+		if u.Origin == influxdb.UserOriginLDAP {
+			return EManagedPassword
+		}
+		return nil
+	}
+
+	conn, err := s.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	entry, err := s.findUser(conn, name)
+	if err != nil {
+		return err
+	}
+	if entry != nil {
+		return EManagedPassword
+	}
+	return nil
+}
+
+// findLocalUser returns the user named name, or nil if there is none.
+func (s *PasswordsService) findLocalUser(ctx context.Context, name string) (*influxdb.User, error) {
+	u, err := s.UserService.FindUser(ctx, influxdb.UserFilter{Name: &name})
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// connect dials the LDAP server and binds with the search credentials.
+func (s *PasswordsService) connect() (*goldap.Conn, error) {
+	u, err := url.Parse(s.Config.URL)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid LDAP URL",
+			Err:  err,
+		}
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: s.Config.InsecureSkipVerify,
+	}
+
+	var conn *goldap.Conn
+	switch u.Scheme {
+	case "ldap":
+		conn, err = goldap.Dial("tcp", u.Host)
+	case "ldaps":
+		conn, err = goldap.DialTLS("tcp", u.Host, tlsConfig)
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("LDAP URL scheme %q is not ldap or ldaps", u.Scheme),
+		}
+	}
+	if err != nil {
+		return nil, unavailableError(err)
+	}
+	if s.Config.Timeout > 0 {
+		conn.SetTimeout(s.Config.Timeout)
+	}
+
+	if s.Config.StartTLS && u.Scheme == "ldap" {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, unavailableError(err)
+		}
+	}
+
+	if s.Config.BindDN != "" {
+		if err := conn.Bind(s.Config.BindDN, s.Config.BindPassword); err != nil {
+			conn.Close()
+			return nil, unavailableError(err)
+		}
+	}
+	return conn, nil
+}
+
+// findUser returns the entry of the user named name, or nil if there is none.
+func (s *PasswordsService) findUser(conn *goldap.Conn, name string) (*goldap.Entry, error) {
+	req := goldap.NewSearchRequest(
+		s.Config.UserBaseDN,
+		goldap.ScopeWholeSubtree,
+		goldap.NeverDerefAliases,
+		2,
+		0,
+		false,
+		fmt.Sprintf(s.Config.UserFilter, goldap.EscapeFilter(name)),
+		[]string{s.Config.GroupAttribute},
+		nil,
+	)
+
+	res, err := conn.Search(req)
+	if err != nil && !goldap.IsErrorWithCode(err, goldap.LDAPResultNoSuchObject) {
+		return nil, unavailableError(err)
+	}
+	if res == nil || len(res.Entries) == 0 {
+		return nil, nil
+	}
+	// Users that cannot be told apart cannot sign in.
+	if len(res.Entries) > 1 {
+		s.Logger.Warn("LDAP user filter matches more than one entry", zap.String("user", name))
+		return nil, EIncorrectPassword
+	}
+	return res.Entries[0], nil
+}
+
+// provision creates the user u named name with the LDAP origin if u is nil, and synchronizes
+// its memberships of the organizations of the group mappings with its groups. The memberships
+// of users that are not LDAP users are never changed.
+func (s *PasswordsService) provision(ctx context.Context, u *influxdb.User, name string, groups []string) error {
+	if u == nil {
+		u = &influxdb.User{Name: name, Origin: influxdb.UserOriginLDAP}
+		if err := s.UserService.CreateUser(ctx, u); err != nil {
+			return err
+		}
+	}
+	if u.Origin != influxdb.UserOriginLDAP {
+		s.Logger.Warn("refusing to manage local user with the name of a LDAP user", zap.String("user", name))
+		return EIncorrectPassword
+	}
+
+	member := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		member[normalizeDN(g)] = true
+	}
+
+	// Owners of an organization are also members of it,
+	// so owner mappings win over member mappings.
+	roles := make(map[string]influxdb.UserType)
+	var orgs []string
+	for _, m := range s.Config.GroupMappings {
+		if _, ok := roles[m.Org]; !ok {
+			roles[m.Org] = ""
+			orgs = append(orgs, m.Org)
+		}
+		if member[normalizeDN(m.GroupDN)] && roles[m.Org] != influxdb.Owner {
+			roles[m.Org] = m.Role
+		}
+	}
+
+	for _, name := range orgs {
+		if err := s.syncOrg(ctx, u.ID, name, roles[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncOrg makes the user a member or owner of the organization, or neither if role is empty.
+func (s *PasswordsService) syncOrg(ctx context.Context, userID influxdb.ID, name string, role influxdb.UserType) error {
+	o, err := s.OrganizationService.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &name})
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		s.Logger.Warn("organization of LDAP group mapping not found", zap.String("org", name))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ms, _, err := s.UserResourceMappingService.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   o.ID,
+		UserID:       userID,
+	})
+	if err != nil {
+		return err
+	}
+	for _, m := range ms {
+		if m.UserType == role {
+			return nil
+		}
+	}
+
+	if len(ms) > 0 {
+		if err := s.UserResourceMappingService.DeleteUserResourceMapping(ctx, o.ID, userID); err != nil {
+			return err
+		}
+	}
+	if role == "" {
+		return nil
+	}
+	return s.UserResourceMappingService.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   o.ID,
+		UserID:       userID,
+		UserType:     role,
+	})
+}
+
+// normalizeDN returns dn in a form that compares equal to equivalent DNs.
+func normalizeDN(dn string) string {
+	d, err := goldap.ParseDN(dn)
+	if err != nil {
+		return strings.ToLower(dn)
+	}
+	rdns := make([]string, len(d.RDNs))
+	for i, rdn := range d.RDNs {
+		attrs := make([]string, len(rdn.Attributes))
+		for j, a := range rdn.Attributes {
+			attrs[j] = strings.ToLower(a.Type) + "=" + strings.ToLower(a.Value)
+		}
+		rdns[i] = strings.Join(attrs, "+")
+	}
+	return strings.Join(rdns, ",")
+}
+
+func unavailableError(err error) error {
+	return &influxdb.Error{
+		Code: influxdb.EUnavailable,
+		Msg:  "unable to reach the LDAP server",
+		Err:  err,
+	}
+}
@@ -0,0 +1,336 @@
+package ldap_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/ldap"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+const (
+	adminsDN = "cn=admins,ou=groups,dc=example,dc=com"
+	devsDN   = "cn=devs,ou=groups,dc=example,dc=com"
+)
+
+var (
+	searcher = entry{
+		DN:       "cn=search,dc=example,dc=com",
+		Password: "searchpassword",
+	}
+	alice = entry{
+		DN:       "uid=alice,ou=people,dc=example,dc=com",
+		Password: "alicepassword",
+		Attrs: map[string][]string{
+			"uid":      {"alice"},
+			"memberOf": {"CN=Admins,OU=Groups,DC=example,DC=com", devsDN},
+		},
+	}
+	bob = entry{
+		DN:       "uid=bob,ou=people,dc=example,dc=com",
+		Password: "bobpassword",
+		Attrs: map[string][]string{
+			"uid":      {"bob"},
+			"memberOf": {devsDN},
+		},
+	}
+)
+
+func newPasswordsService(t *testing.T, srv *server) (*ldap.PasswordsService, *kv.Service) {
+	t.Helper()
+	ctx := context.Background()
+
+	svc := kv.NewService(inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+	if err := svc.CreateUser(ctx, &influxdb.User{Name: "local"}); err != nil {
+		t.Fatalf("error creating user: %v", err)
+	}
+	if err := svc.SetPassword(ctx, "local", "localpassword"); err != nil {
+		t.Fatalf("error setting password: %v", err)
+	}
+	if err := svc.CreateOrganization(ctx, &influxdb.Organization{Name: "ops"}); err != nil {
+		t.Fatalf("error creating organization: %v", err)
+	}
+
+	c := ldap.DefaultConfig(srv.URL(), "ou=people,dc=example,dc=com")
+	c.BindDN = searcher.DN
+	c.BindPassword = searcher.Password
+	c.GroupMappings = []ldap.GroupMapping{
+		{GroupDN: devsDN, Org: "ops", Role: influxdb.Member},
+		{GroupDN: adminsDN, Org: "ops", Role: influxdb.Owner},
+		{GroupDN: adminsDN, Org: "missing", Role: influxdb.Owner},
+	}
+
+	s, err := ldap.NewPasswordsService(c, svc, svc, svc, svc)
+	if err != nil {
+		t.Fatalf("error creating LDAP passwords service: %v", err)
+	}
+	return s, svc
+}
+
+func TestPasswordsService_ComparePassword(t *testing.T) {
+	srv := newServer(t, searcher, alice, bob)
+	defer srv.Close()
+	s, _ := newPasswordsService(t, srv)
+
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		err      error
+	}{
+		{
+			name:     "LDAP user with correct password",
+			user:     "alice",
+			password: "alicepassword",
+		},
+		{
+			name:     "LDAP user with incorrect password",
+			user:     "alice",
+			password: "bobpassword",
+			err:      ldap.EIncorrectPassword,
+		},
+		{
+			name:     "LDAP user without password",
+			user:     "alice",
+			password: "",
+			err:      ldap.EIncorrectPassword,
+		},
+		{
+			name:     "local user with correct password",
+			user:     "local",
+			password: "localpassword",
+		},
+		{
+			name:     "local user with incorrect password",
+			user:     "local",
+			password: "alicepassword",
+			err:      kv.EIncorrectPassword,
+		},
+		{
+			name:     "unknown user",
+			user:     "mallory",
+			password: "alicepassword",
+			err:      kv.EIncorrectPassword,
+		},
+		{
+			name:     "filter injection",
+			user:     "*",
+			password: "alicepassword",
+			err:      kv.EIncorrectPassword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ComparePassword(context.Background(), tt.user, tt.password)
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestPasswordsService_Provisioning(t *testing.T) {
+	ctx := context.Background()
+	srv := newServer(t, searcher, alice, bob)
+	defer srv.Close()
+	s, svc := newPasswordsService(t, srv)
+
+	org, err := svc.FindOrganization(ctx, influxdb.OrganizationFilter{Name: strPtr("ops")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orgRole := func(name string) influxdb.UserType {
+		t.Helper()
+		u, err := svc.FindUser(ctx, influxdb.UserFilter{Name: &name})
+		if err != nil {
+			t.Fatalf("expected user %s to be provisioned: %v", name, err)
+		}
+		ms, _, err := svc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.OrgsResourceType,
+			ResourceID:   org.ID,
+			UserID:       u.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ms) > 1 {
+			t.Fatalf("expected at most one mapping of user %s, got %d", name, len(ms))
+		}
+		if len(ms) == 0 {
+			return ""
+		}
+		return ms[0].UserType
+	}
+
+	if err := s.ComparePassword(ctx, "alice", "alicepassword"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ComparePassword(ctx, "bob", "bobpassword"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := orgRole("alice"), influxdb.Owner; got != want {
+		t.Errorf("alice: got role %q want %q", got, want)
+	}
+	if got, want := orgRole("bob"), influxdb.Member; got != want {
+		t.Errorf("bob: got role %q want %q", got, want)
+	}
+	if u, err := svc.FindUser(ctx, influxdb.UserFilter{Name: strPtr("alice")}); err != nil || u.Origin != influxdb.UserOriginLDAP {
+		t.Errorf("expected provisioned user to have the LDAP origin, got %v %v", u, err)
+	}
+
+	// Memberships follow changes of the groups of users on their next signin.
+	demoted, removed := alice, bob
+	demoted.Attrs = map[string][]string{"uid": {"alice"}, "memberOf": {devsDN}}
+	removed.Attrs = map[string][]string{"uid": {"bob"}}
+	srv.SetEntries(searcher, demoted, removed)
+
+	if err := s.ComparePassword(ctx, "alice", "alicepassword"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ComparePassword(ctx, "bob", "bobpassword"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := orgRole("alice"), influxdb.Member; got != want {
+		t.Errorf("demoted alice: got role %q want %q", got, want)
+	}
+	if got, want := orgRole("bob"), influxdb.UserType(""); got != want {
+		t.Errorf("removed bob: got role %q want %q", got, want)
+	}
+
+	// Failed signins do not provision users.
+	if err := s.ComparePassword(ctx, "carol", "carolpassword"); err == nil {
+		t.Fatal("expected signin of unknown user to fail")
+	}
+	if _, err := svc.FindUser(ctx, influxdb.UserFilter{Name: strPtr("carol")}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected unknown user not to be provisioned, got %v", err)
+	}
+}
+
+func TestPasswordsService_SetPassword(t *testing.T) {
+	ctx := context.Background()
+	srv := newServer(t, searcher, alice, bob)
+	defer srv.Close()
+	s, _ := newPasswordsService(t, srv)
+
+	err := s.SetPassword(ctx, "alice", "newalicepassword")
+	influxdbtesting.ErrorsEqual(t, err, ldap.EManagedPassword)
+
+	err = s.CompareAndSetPassword(ctx, "alice", "alicepassword", "newalicepassword")
+	influxdbtesting.ErrorsEqual(t, err, ldap.EManagedPassword)
+
+	if err := s.SetPassword(ctx, "local", "newlocalpassword"); err != nil {
+		t.Fatalf("unexpected error setting password of local user: %v", err)
+	}
+	if err := s.ComparePassword(ctx, "local", "newlocalpassword"); err != nil {
+		t.Errorf("unexpected error comparing new password of local user: %v", err)
+	}
+}
+
+func TestPasswordsService_Unavailable(t *testing.T) {
+	srv := newServer(t, searcher, alice)
+	s, _ := newPasswordsService(t, srv)
+	srv.Close()
+
+	err := s.ComparePassword(context.Background(), "alice", "alicepassword")
+	if got, want := influxdb.ErrorCode(err), influxdb.EUnavailable; got != want {
+		t.Errorf("got error code %q want %q: %v", got, want, err)
+	}
+
+	// Local users do not depend on the LDAP server.
+	if err := s.ComparePassword(context.Background(), "local", "localpassword"); err != nil {
+		t.Errorf("unexpected error comparing password of local user: %v", err)
+	}
+}
+
+func TestPasswordsService_LocalUserWithLDAPName(t *testing.T) {
+	ctx := context.Background()
+	srv := newServer(t, searcher, alice)
+	defer srv.Close()
+	s, svc := newPasswordsService(t, srv)
+
+	u := &influxdb.User{Name: "alice"}
+	if err := svc.CreateUser(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SetPassword(ctx, "alice", "localalicepassword"); err != nil {
+		t.Fatal(err)
+	}
+	org, err := svc.FindOrganization(ctx, influxdb.OrganizationFilter{Name: strPtr("ops")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   org.ID,
+		UserID:       u.ID,
+		UserType:     influxdb.Member,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	orgRole := func() influxdb.UserType {
+		t.Helper()
+		ms, _, err := svc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.OrgsResourceType,
+			ResourceID:   org.ID,
+			UserID:       u.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ms) != 1 {
+			t.Fatalf("expected one mapping of the user, got %d", len(ms))
+		}
+		return ms[0].UserType
+	}
+
+	// The local user is not bound to the LDAP user of the same name.
+	err = s.ComparePassword(ctx, "alice", "alicepassword")
+	influxdbtesting.ErrorsEqual(t, err, kv.EIncorrectPassword)
+	if err := s.ComparePassword(ctx, "alice", "localalicepassword"); err != nil {
+		t.Fatalf("unexpected error comparing password of local user: %v", err)
+	}
+	if got, want := orgRole(), influxdb.Member; got != want {
+		t.Errorf("local user: got role %q want %q", got, want)
+	}
+	if err := s.SetPassword(ctx, "alice", "newlocalalicepassword"); err != nil {
+		t.Fatalf("unexpected error setting password of local user: %v", err)
+	}
+
+	// Until an administrator links it to LDAP.
+	if _, err := svc.UpdateUser(ctx, u.ID, influxdb.UserUpdate{Origin: strPtr(influxdb.UserOriginLDAP)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ComparePassword(ctx, "alice", "alicepassword"); err != nil {
+		t.Fatalf("unexpected error comparing password of linked user: %v", err)
+	}
+	if got, want := orgRole(), influxdb.Owner; got != want {
+		t.Errorf("linked user: got role %q want %q", got, want)
+	}
+	err = s.SetPassword(ctx, "alice", "alicepassword")
+	influxdbtesting.ErrorsEqual(t, err, ldap.EManagedPassword)
+}
+
+func TestPasswordsService_SearchCredentials(t *testing.T) {
+	srv := newServer(t, alice)
+	defer srv.Close()
+	s, _ := newPasswordsService(t, srv)
+
+	err := s.ComparePassword(context.Background(), "alice", "alicepassword")
+	if got, want := influxdb.ErrorCode(err), influxdb.EUnavailable; got != want {
+		t.Errorf("got error code %q want %q: %v", got, want, err)
+	}
+	for _, dn := range srv.Binds() {
+		if dn == alice.DN {
+			t.Errorf("expected no bind as the user when the search bind fails")
+		}
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,182 @@
+package ldap_test
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	ber "gopkg.in/asn1-ber.v1"
+	goldap "gopkg.in/ldap.v3"
+)
+
+// entry is an entry of the directory of a stub LDAP server.
+type entry struct {
+	DN       string
+	Password string
+	Attrs    map[string][]string
+}
+
+// server is an in-process LDAP server answering simple binds and
+// searches with equality filters over a fixed directory.
+type server struct {
+	l net.Listener
+
+	mu      sync.Mutex
+	entries []entry
+	binds   []string
+}
+
+func newServer(t *testing.T, entries ...entry) *server {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &server{l: l, entries: entries}
+	go s.serve()
+	return s
+}
+
+// URL returns the ldap:// URL of the server.
+func (s *server) URL() string {
+	return "ldap://" + s.l.Addr().String()
+}
+
+// Close stops the server.
+func (s *server) Close() {
+	s.l.Close()
+}
+
+// SetEntries replaces the directory of the server.
+func (s *server) SetEntries(entries ...entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = entries
+}
+
+// Binds returns the DNs of the successful binds.
+func (s *server) Binds() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.binds...)
+}
+
+func (s *server) serve() {
+	for {
+		conn, err := s.l.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *server) handle(conn net.Conn) {
+	defer conn.Close()
+	for {
+		p, err := ber.ReadPacket(conn)
+		if err != nil || len(p.Children) < 2 {
+			return
+		}
+		id, _ := p.Children[0].Value.(int64)
+		req := p.Children[1]
+
+		var resps []*ber.Packet
+		switch req.Tag {
+		case goldap.ApplicationBindRequest:
+			resps = append(resps, s.bind(req))
+		case goldap.ApplicationSearchRequest:
+			resps = s.search(req)
+		case goldap.ApplicationUnbindRequest:
+			return
+		default:
+			return
+		}
+
+		for _, resp := range resps {
+			env := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			env.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "Message ID"))
+			env.AppendChild(resp)
+			if _, err := conn.Write(env.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *server) bind(req *ber.Packet) *ber.Packet {
+	dn := req.Children[1].Data.String()
+	password := req.Children[2].Data.String()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.entries {
+		if strings.EqualFold(e.DN, dn) && e.Password != "" && e.Password == password {
+			s.binds = append(s.binds, e.DN)
+			return result(goldap.ApplicationBindResponse, goldap.LDAPResultSuccess)
+		}
+	}
+	return result(goldap.ApplicationBindResponse, goldap.LDAPResultInvalidCredentials)
+}
+
+func (s *server) search(req *ber.Packet) []*ber.Packet {
+	base := strings.ToLower(req.Children[0].Data.String())
+	filter, err := goldap.DecompileFilter(req.Children[6])
+	if err != nil {
+		return []*ber.Packet{result(goldap.ApplicationSearchResultDone, goldap.LDAPResultFilterError)}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var resps []*ber.Packet
+	for _, e := range s.entries {
+		if strings.HasSuffix(strings.ToLower(e.DN), base) && e.matches(filter) {
+			resps = append(resps, e.encode())
+		}
+	}
+	return append(resps, result(goldap.ApplicationSearchResultDone, goldap.LDAPResultSuccess))
+}
+
+// matches reports whether the entry matches an equality filter such as (uid=name).
+func (e entry) matches(filter string) bool {
+	if !strings.HasPrefix(filter, "(") || !strings.HasSuffix(filter, ")") {
+		return false
+	}
+	kv := strings.SplitN(filter[1:len(filter)-1], "=", 2)
+	if len(kv) != 2 {
+		return false
+	}
+	for _, v := range e.Attrs[kv[0]] {
+		if strings.EqualFold(v, kv[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e entry) encode() *ber.Packet {
+	p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, goldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.DN, "DN"))
+	attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range e.Attrs {
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, v := range values {
+			vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "Value"))
+		}
+		attr.AppendChild(vals)
+		attrs.AppendChild(attr)
+	}
+	p.AppendChild(attrs)
+	return p
+}
+
+func result(tag ber.Tag, code uint16) *ber.Packet {
+	p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, uint64(code), "Result Code"))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return p
+}
@@ -0,0 +1,56 @@
+package lockout
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metrics is a collection of metrics relating to failed signins and the
+// lockouts they cause.
+type metrics struct {
+	failures prometheus.Counter
+	lockouts *prometheus.CounterVec
+	rejected *prometheus.CounterVec
+	unlocks  prometheus.Counter
+}
+
+func newMetrics() *metrics {
+	const namespace = "http"
+	const subsystem = "signin"
+
+	return &metrics{
+		failures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "failures_total",
+			Help:      "Number of failed signins.",
+		}),
+		lockouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "lockouts_total",
+			Help:      "Number of failed signins that locked out, or extended the lockout of, a user or an address.",
+		}, []string{"kind"}),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "rejected_total",
+			Help:      "Number of signins rejected because the user or the address was locked out.",
+		}, []string{"kind"}),
+		unlocks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "unlocks_total",
+			Help:      "Number of users unlocked by an administrator.",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *metrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.failures,
+		m.lockouts,
+		m.rejected,
+		m.unlocks,
+	}
+}
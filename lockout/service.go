@@ -0,0 +1,203 @@
+// Package lockout locks out signins after repeated failures.
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Defaults of the Config of a Service.
+const (
+	DefaultUserAttempts = 5
+	DefaultAddrAttempts = 20
+	DefaultDuration     = time.Minute
+	DefaultMaxDuration  = time.Hour
+)
+
+// Config configures when signins are locked out.
+type Config struct {
+	// UserAttempts is the number of consecutive failed signins as a user
+	// after which signins as the user are locked out; 0 never locks out users.
+	UserAttempts int
+	// AddrAttempts is the number of failed signins from an address after
+	// which signins from the address are locked out; 0 never locks out addresses.
+	AddrAttempts int
+	// Duration is the time of the first lockout. Every further failed signin
+	// doubles it, up to MaxDuration.
+	Duration time.Duration
+	// MaxDuration is the longest lockout. Failed signins are forgotten once
+	// there has been none for MaxDuration.
+	MaxDuration time.Duration
+}
+
+// DefaultConfig returns the default Config of a Service.
+func DefaultConfig() Config {
+	return Config{
+		UserAttempts: DefaultUserAttempts,
+		AddrAttempts: DefaultAddrAttempts,
+		Duration:     DefaultDuration,
+		MaxDuration:  DefaultMaxDuration,
+	}
+}
+
+// pruneEvery is the number of failed signins after which failures that are
+// forgotten are removed.
+const pruneEvery = 1024
+
+const (
+	kindUser = "user"
+	kindAddr = "address"
+)
+
+var _ influxdb.SigninLockoutService = (*Service)(nil)
+
+// Service tracks failed signins in memory, by user and by address, and locks
+// them out with exponentially growing durations.
+type Service struct {
+	config Config
+	now    func() time.Time
+
+	mu       sync.Mutex
+	users    map[string]*failures
+	addrs    map[string]*failures
+	recorded int
+
+	metrics *metrics
+}
+
+// failures are the failed signins of a user or an address.
+type failures struct {
+	count int
+	last  time.Time
+}
+
+// NewService returns a Service locking out signins according to config.
+func NewService(config Config) *Service {
+	return &Service{
+		config:  config,
+		now:     time.Now,
+		users:   make(map[string]*failures),
+		addrs:   make(map[string]*failures),
+		metrics: newMetrics(),
+	}
+}
+
+// WithNow sets the clock of the service. It is used by tests.
+func (s *Service) WithNow(now func() time.Time) {
+	s.now = now
+}
+
+// CheckSignin returns influxdb.ErrSigninLocked if signins as the user, or from the address, are locked out.
+func (s *Service) CheckSignin(ctx context.Context, name, addr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.locked(s.users[name], s.config.UserAttempts, now) {
+		s.metrics.rejected.WithLabelValues(kindUser).Inc()
+		return influxdb.ErrSigninLocked
+	}
+	if s.locked(s.addrs[addr], s.config.AddrAttempts, now) {
+		s.metrics.rejected.WithLabelValues(kindAddr).Inc()
+		return influxdb.ErrSigninLocked
+	}
+	return nil
+}
+
+// SigninFailed records a failed signin as the user from the address.
+func (s *Service) SigninFailed(ctx context.Context, name, addr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.metrics.failures.Inc()
+	s.fail(s.users, name, s.config.UserAttempts, kindUser, now)
+	s.fail(s.addrs, addr, s.config.AddrAttempts, kindAddr, now)
+
+	s.recorded++
+	if s.recorded%pruneEvery == 0 {
+		s.prune(now)
+	}
+}
+
+// SigninSucceeded clears the failed signins of the user.
+func (s *Service) SigninSucceeded(ctx context.Context, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.users, name)
+}
+
+// UnlockSignin clears the failed signins of the user, so that they may sign in again.
+func (s *Service) UnlockSignin(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[name]; ok {
+		delete(s.users, name)
+		s.metrics.unlocks.Inc()
+	}
+	return nil
+}
+
+func (s *Service) fail(m map[string]*failures, key string, attempts int, kind string, now time.Time) {
+	if attempts <= 0 {
+		return
+	}
+
+	f, ok := m[key]
+	if !ok || s.forgotten(f, now) {
+		f = &failures{}
+		m[key] = f
+	}
+	f.count++
+	f.last = now
+
+	if f.count >= attempts {
+		s.metrics.lockouts.WithLabelValues(kind).Inc()
+	}
+}
+
+// locked returns true if the failures lock out signins at now.
+func (s *Service) locked(f *failures, attempts int, now time.Time) bool {
+	if f == nil || attempts <= 0 || f.count < attempts {
+		return false
+	}
+	return now.Before(f.last.Add(s.lockoutDuration(f.count - attempts)))
+}
+
+// lockoutDuration returns the duration of the lockout after the failed
+// signins that exceeded the number of attempts.
+func (s *Service) lockoutDuration(exceeded int) time.Duration {
+	d := s.config.Duration
+	for i := 0; i < exceeded && d < s.config.MaxDuration; i++ {
+		d *= 2
+	}
+	if d > s.config.MaxDuration {
+		d = s.config.MaxDuration
+	}
+	return d
+}
+
+func (s *Service) forgotten(f *failures, now time.Time) bool {
+	return !now.Before(f.last.Add(s.config.MaxDuration))
+}
+
+func (s *Service) prune(now time.Time) {
+	for _, m := range []map[string]*failures{s.users, s.addrs} {
+		for k, f := range m {
+			if s.forgotten(f, now) {
+				delete(m, k)
+			}
+		}
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (s *Service) PrometheusCollectors() []prometheus.Collector {
+	return s.metrics.PrometheusCollectors()
+}
@@ -0,0 +1,144 @@
+package lockout_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/lockout"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time { return c.now }
+
+func newService(c *clock) *lockout.Service {
+	s := lockout.NewService(lockout.Config{
+		UserAttempts: 3,
+		AddrAttempts: 5,
+		Duration:     time.Minute,
+		MaxDuration:  10 * time.Minute,
+	})
+	s.WithNow(c.Now)
+	return s
+}
+
+func TestService_UserLockout(t *testing.T) {
+	ctx := context.Background()
+	c := &clock{now: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := newService(c)
+
+	for i := 0; i < 2; i++ {
+		s.SigninFailed(ctx, "user", "10.0.0.1")
+	}
+	if err := s.CheckSignin(ctx, "user", "10.0.0.1"); err != nil {
+		t.Fatalf("unexpected lockout before the attempts are exceeded: %v", err)
+	}
+
+	// The lockout doubles with every failed signin, up to the maximum.
+	for i, d := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute} {
+		s.SigninFailed(ctx, "user", "10.0.0.2")
+		if err := s.CheckSignin(ctx, "user", "10.0.0.3"); err != influxdb.ErrSigninLocked {
+			t.Fatalf("failure %d: expected user to be locked out, got %v", i, err)
+		}
+
+		c.now = c.now.Add(d - time.Second)
+		if err := s.CheckSignin(ctx, "user", "10.0.0.3"); err != influxdb.ErrSigninLocked {
+			t.Fatalf("failure %d: expected user to be locked out for %s, got %v", i, d, err)
+		}
+		c.now = c.now.Add(time.Second)
+		if err := s.CheckSignin(ctx, "user", "10.0.0.3"); err != nil {
+			t.Fatalf("failure %d: expected lockout to end after %s, got %v", i, d, err)
+		}
+	}
+
+	if err := s.CheckSignin(ctx, "other", "10.0.0.3"); err != nil {
+		t.Errorf("unexpected lockout of another user: %v", err)
+	}
+}
+
+func TestService_AddrLockout(t *testing.T) {
+	ctx := context.Background()
+	c := &clock{now: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := newService(c)
+
+	// Failures of different users from the same address.
+	for _, user := range []string{"a", "b", "c", "d", "e"} {
+		s.SigninFailed(ctx, user, "10.0.0.1")
+	}
+	if err := s.CheckSignin(ctx, "f", "10.0.0.1"); err != influxdb.ErrSigninLocked {
+		t.Errorf("expected address to be locked out, got %v", err)
+	}
+	if err := s.CheckSignin(ctx, "f", "10.0.0.2"); err != nil {
+		t.Errorf("unexpected lockout of another address: %v", err)
+	}
+
+	// Signing in does not clear the failures of the address.
+	s.SigninSucceeded(ctx, "a")
+	if err := s.CheckSignin(ctx, "f", "10.0.0.1"); err != influxdb.ErrSigninLocked {
+		t.Errorf("expected address to stay locked out, got %v", err)
+	}
+}
+
+func TestService_SigninSucceeded(t *testing.T) {
+	ctx := context.Background()
+	c := &clock{now: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := newService(c)
+
+	s.SigninFailed(ctx, "user", "10.0.0.1")
+	s.SigninFailed(ctx, "user", "10.0.0.1")
+	s.SigninSucceeded(ctx, "user")
+	s.SigninFailed(ctx, "user", "10.0.0.1")
+	if err := s.CheckSignin(ctx, "user", "10.0.0.1"); err != nil {
+		t.Errorf("expected failures before a successful signin to be cleared, got %v", err)
+	}
+}
+
+func TestService_UnlockSignin(t *testing.T) {
+	ctx := context.Background()
+	c := &clock{now: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := newService(c)
+
+	for i := 0; i < 3; i++ {
+		s.SigninFailed(ctx, "user", "10.0.0.1")
+	}
+	if err := s.CheckSignin(ctx, "user", "10.0.0.2"); err != influxdb.ErrSigninLocked {
+		t.Fatalf("expected user to be locked out, got %v", err)
+	}
+
+	if err := s.UnlockSignin(ctx, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CheckSignin(ctx, "user", "10.0.0.2"); err != nil {
+		t.Errorf("expected unlocked user to sign in, got %v", err)
+	}
+}
+
+func TestService_FailuresForgotten(t *testing.T) {
+	ctx := context.Background()
+	c := &clock{now: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := newService(c)
+
+	s.SigninFailed(ctx, "user", "10.0.0.1")
+	s.SigninFailed(ctx, "user", "10.0.0.1")
+	c.now = c.now.Add(10 * time.Minute)
+	s.SigninFailed(ctx, "user", "10.0.0.1")
+	if err := s.CheckSignin(ctx, "user", "10.0.0.1"); err != nil {
+		t.Errorf("expected old failures to be forgotten, got %v", err)
+	}
+}
+
+func TestService_Disabled(t *testing.T) {
+	ctx := context.Background()
+	s := lockout.NewService(lockout.Config{Duration: time.Minute, MaxDuration: time.Hour})
+
+	for i := 0; i < 100; i++ {
+		s.SigninFailed(ctx, "user", "10.0.0.1")
+	}
+	if err := s.CheckSignin(ctx, "user", "10.0.0.1"); err != nil {
+		t.Errorf("unexpected lockout with lockouts disabled: %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.AuditLogService = (*AuditLogService)(nil)
+
+// AuditLogService is a mock implementation of influxdb.AuditLogService.
+type AuditLogService struct {
+	FindAuditLogEntriesFn func(context.Context, influxdb.AuditLogFilter, ...influxdb.FindOptions) ([]*influxdb.AuditLogEntry, int, error)
+}
+
+// NewAuditLogService returns a mock of AuditLogService where its methods will return zero values.
+func NewAuditLogService() *AuditLogService {
+	return &AuditLogService{
+		FindAuditLogEntriesFn: func(context.Context, influxdb.AuditLogFilter, ...influxdb.FindOptions) ([]*influxdb.AuditLogEntry, int, error) {
+			return nil, 0, nil
+		},
+	}
+}
+
+// FindAuditLogEntries returns the entries of the audit log that match filter.
+func (s *AuditLogService) FindAuditLogEntries(ctx context.Context, filter influxdb.AuditLogFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditLogEntry, int, error) {
+	return s.FindAuditLogEntriesFn(ctx, filter, opt...)
+}
@@ -0,0 +1,51 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.BackfillService = (*BackfillService)(nil)
+
+// BackfillService is a mock implementation of influxdb.BackfillService.
+type BackfillService struct {
+	CreateBackfillFn   func(context.Context, influxdb.ID, influxdb.BackfillCreate) (*influxdb.Backfill, error)
+	FindBackfillByIDFn func(context.Context, influxdb.ID, influxdb.ID) (*influxdb.Backfill, error)
+	FindBackfillsFn    func(context.Context, influxdb.BackfillFilter) ([]*influxdb.Backfill, error)
+	CancelBackfillFn   func(context.Context, influxdb.ID, influxdb.ID) (*influxdb.Backfill, error)
+}
+
+// NewBackfillService returns a mock of BackfillService where its methods will return zero values.
+func NewBackfillService() *BackfillService {
+	return &BackfillService{
+		CreateBackfillFn: func(context.Context, influxdb.ID, influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+			return nil, nil
+		},
+		FindBackfillByIDFn: func(context.Context, influxdb.ID, influxdb.ID) (*influxdb.Backfill, error) { return nil, nil },
+		FindBackfillsFn: func(context.Context, influxdb.BackfillFilter) ([]*influxdb.Backfill, error) {
+			return nil, nil
+		},
+		CancelBackfillFn: func(context.Context, influxdb.ID, influxdb.ID) (*influxdb.Backfill, error) { return nil, nil },
+	}
+}
+
+// CreateBackfill starts a backfill of a task.
+func (s *BackfillService) CreateBackfill(ctx context.Context, taskID influxdb.ID, c influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+	return s.CreateBackfillFn(ctx, taskID, c)
+}
+
+// FindBackfillByID returns a single backfill by ID.
+func (s *BackfillService) FindBackfillByID(ctx context.Context, taskID, id influxdb.ID) (*influxdb.Backfill, error) {
+	return s.FindBackfillByIDFn(ctx, taskID, id)
+}
+
+// FindBackfills returns a list of backfills that match filter.
+func (s *BackfillService) FindBackfills(ctx context.Context, filter influxdb.BackfillFilter) ([]*influxdb.Backfill, error) {
+	return s.FindBackfillsFn(ctx, filter)
+}
+
+// CancelBackfill cancels a backfill.
+func (s *BackfillService) CancelBackfill(ctx context.Context, taskID, id influxdb.ID) (*influxdb.Backfill, error) {
+	return s.CancelBackfillFn(ctx, taskID, id)
+}
@@ -0,0 +1,41 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.BucketSchemaService = (*BucketSchemaService)(nil)
+
+// BucketSchemaService is a mock implementation of platform.BucketSchemaService.
+type BucketSchemaService struct {
+	FindBucketSchemaFn   func(context.Context, platform.ID) (*platform.BucketSchema, error)
+	PutBucketSchemaFn    func(context.Context, platform.ID, *platform.BucketSchema) error
+	DeleteBucketSchemaFn func(context.Context, platform.ID) error
+}
+
+// NewBucketSchemaService returns a mock BucketSchemaService where its methods will return
+// zero values.
+func NewBucketSchemaService() *BucketSchemaService {
+	return &BucketSchemaService{
+		FindBucketSchemaFn:   func(context.Context, platform.ID) (*platform.BucketSchema, error) { return nil, nil },
+		PutBucketSchemaFn:    func(context.Context, platform.ID, *platform.BucketSchema) error { return nil },
+		DeleteBucketSchemaFn: func(context.Context, platform.ID) error { return nil },
+	}
+}
+
+// FindBucketSchema returns the schema of a bucket.
+func (s *BucketSchemaService) FindBucketSchema(ctx context.Context, bucketID platform.ID) (*platform.BucketSchema, error) {
+	return s.FindBucketSchemaFn(ctx, bucketID)
+}
+
+// PutBucketSchema replaces the schema of a bucket.
+func (s *BucketSchemaService) PutBucketSchema(ctx context.Context, bucketID platform.ID, sch *platform.BucketSchema) error {
+	return s.PutBucketSchemaFn(ctx, bucketID, sch)
+}
+
+// DeleteBucketSchema removes the schema of a bucket.
+func (s *BucketSchemaService) DeleteBucketSchema(ctx context.Context, bucketID platform.ID) error {
+	return s.DeleteBucketSchemaFn(ctx, bucketID)
+}
@@ -0,0 +1,58 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.ReplicationService = (*ReplicationService)(nil)
+
+// ReplicationService is a mock implementation of influxdb.ReplicationService.
+type ReplicationService struct {
+	FindReplicationByIDFn func(context.Context, influxdb.ID) (*influxdb.Replication, error)
+	FindReplicationsFn    func(context.Context, influxdb.ReplicationFilter) ([]*influxdb.Replication, error)
+	CreateReplicationFn   func(context.Context, *influxdb.Replication) error
+	UpdateReplicationFn   func(context.Context, influxdb.ID, influxdb.ReplicationUpdate) (*influxdb.Replication, error)
+	DeleteReplicationFn   func(context.Context, influxdb.ID) error
+}
+
+// NewReplicationService returns a mock of ReplicationService where its methods will return zero values.
+func NewReplicationService() *ReplicationService {
+	return &ReplicationService{
+		FindReplicationByIDFn: func(context.Context, influxdb.ID) (*influxdb.Replication, error) { return nil, nil },
+		FindReplicationsFn: func(context.Context, influxdb.ReplicationFilter) ([]*influxdb.Replication, error) {
+			return nil, nil
+		},
+		CreateReplicationFn: func(context.Context, *influxdb.Replication) error { return nil },
+		UpdateReplicationFn: func(context.Context, influxdb.ID, influxdb.ReplicationUpdate) (*influxdb.Replication, error) {
+			return nil, nil
+		},
+		DeleteReplicationFn: func(context.Context, influxdb.ID) error { return nil },
+	}
+}
+
+// FindReplicationByID returns a single replication by ID.
+func (s *ReplicationService) FindReplicationByID(ctx context.Context, id influxdb.ID) (*influxdb.Replication, error) {
+	return s.FindReplicationByIDFn(ctx, id)
+}
+
+// FindReplications returns a list of replications that match filter.
+func (s *ReplicationService) FindReplications(ctx context.Context, filter influxdb.ReplicationFilter) ([]*influxdb.Replication, error) {
+	return s.FindReplicationsFn(ctx, filter)
+}
+
+// CreateReplication creates a new replication.
+func (s *ReplicationService) CreateReplication(ctx context.Context, r *influxdb.Replication) error {
+	return s.CreateReplicationFn(ctx, r)
+}
+
+// UpdateReplication updates a single replication with changeset.
+func (s *ReplicationService) UpdateReplication(ctx context.Context, id influxdb.ID, upd influxdb.ReplicationUpdate) (*influxdb.Replication, error) {
+	return s.UpdateReplicationFn(ctx, id, upd)
+}
+
+// DeleteReplication removes a replication by ID.
+func (s *ReplicationService) DeleteReplication(ctx context.Context, id influxdb.ID) error {
+	return s.DeleteReplicationFn(ctx, id)
+}
@@ -0,0 +1,58 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.RoleService = (*RoleService)(nil)
+
+// RoleService is a mock implementation of influxdb.RoleService.
+type RoleService struct {
+	FindRoleByIDFn func(context.Context, influxdb.ID) (*influxdb.Role, error)
+	FindRolesFn    func(context.Context, influxdb.RoleFilter) ([]*influxdb.Role, error)
+	CreateRoleFn   func(context.Context, *influxdb.Role) error
+	UpdateRoleFn   func(context.Context, influxdb.ID, influxdb.RoleUpdate) (*influxdb.Role, error)
+	DeleteRoleFn   func(context.Context, influxdb.ID) error
+}
+
+// NewRoleService returns a mock of RoleService where its methods will return zero values.
+func NewRoleService() *RoleService {
+	return &RoleService{
+		FindRoleByIDFn: func(context.Context, influxdb.ID) (*influxdb.Role, error) { return nil, nil },
+		FindRolesFn: func(context.Context, influxdb.RoleFilter) ([]*influxdb.Role, error) {
+			return nil, nil
+		},
+		CreateRoleFn: func(context.Context, *influxdb.Role) error { return nil },
+		UpdateRoleFn: func(context.Context, influxdb.ID, influxdb.RoleUpdate) (*influxdb.Role, error) {
+			return nil, nil
+		},
+		DeleteRoleFn: func(context.Context, influxdb.ID) error { return nil },
+	}
+}
+
+// FindRoleByID returns a single role by ID.
+func (s *RoleService) FindRoleByID(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	return s.FindRoleByIDFn(ctx, id)
+}
+
+// FindRoles returns a list of roles that match filter.
+func (s *RoleService) FindRoles(ctx context.Context, filter influxdb.RoleFilter) ([]*influxdb.Role, error) {
+	return s.FindRolesFn(ctx, filter)
+}
+
+// CreateRole creates a new role.
+func (s *RoleService) CreateRole(ctx context.Context, r *influxdb.Role) error {
+	return s.CreateRoleFn(ctx, r)
+}
+
+// UpdateRole updates a single role with changeset.
+func (s *RoleService) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	return s.UpdateRoleFn(ctx, id, upd)
+}
+
+// DeleteRole removes a role by ID.
+func (s *RoleService) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	return s.DeleteRoleFn(ctx, id)
+}
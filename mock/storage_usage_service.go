@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.StorageUsageService = (*StorageUsageService)(nil)
+
+// StorageUsageService is a mock implementation of platform.StorageUsageService.
+type StorageUsageService struct {
+	FindOrganizationStorageUsageFn func(context.Context, platform.ID) (*platform.StorageUsage, error)
+	FindBucketStorageUsageFn       func(context.Context, platform.ID, platform.ID) (*platform.StorageUsage, error)
+}
+
+// NewStorageUsageService returns a mock StorageUsageService where its methods will return
+// no usage.
+func NewStorageUsageService() *StorageUsageService {
+	return &StorageUsageService{
+		FindOrganizationStorageUsageFn: func(context.Context, platform.ID) (*platform.StorageUsage, error) {
+			return &platform.StorageUsage{}, nil
+		},
+		FindBucketStorageUsageFn: func(context.Context, platform.ID, platform.ID) (*platform.StorageUsage, error) {
+			return &platform.StorageUsage{}, nil
+		},
+	}
+}
+
+// FindOrganizationStorageUsage returns the disk space used by an organization.
+func (s *StorageUsageService) FindOrganizationStorageUsage(ctx context.Context, orgID platform.ID) (*platform.StorageUsage, error) {
+	return s.FindOrganizationStorageUsageFn(ctx, orgID)
+}
+
+// FindBucketStorageUsage returns the disk space used by a bucket.
+func (s *StorageUsageService) FindBucketStorageUsage(ctx context.Context, orgID, bucketID platform.ID) (*platform.StorageUsage, error) {
+	return s.FindBucketStorageUsageFn(ctx, orgID, bucketID)
+}
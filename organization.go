@@ -7,9 +7,49 @@ type Organization struct {
 	ID          ID     `json:"id,omitempty"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	// QueryLimits overrides the default limits of the queries of the
+	// organization, if set.
+	QueryLimits *QueryLimits `json:"queryLimits,omitempty"`
+	// StorageQuotaBytes, if set, is the disk space the data of all buckets of
+	// the organization may use before writes to them are rejected.
+	StorageQuotaBytes int64 `json:"storageQuotaBytes,omitempty"`
 	CRUDLog
 }
 
+// QueryLimits are the limits of the queries of an organization. Zero limits
+// are those the query controller is configured with.
+type QueryLimits struct {
+	// ConcurrencyQuota is the number of queries of the organization that are
+	// allowed to execute concurrently.
+	ConcurrencyQuota int `json:"concurrencyQuota,omitempty"`
+	// MemoryBytesQuotaPerQuery is the maximum number of bytes (in table
+	// memory) a query of the organization is allowed to use at any given time.
+	MemoryBytesQuotaPerQuery int64 `json:"memoryBytesQuotaPerQuery,omitempty"`
+	// MemoryBytesQuota is the maximum number of bytes (in table memory) all
+	// executing queries of the organization are allowed to use together.
+	// Each executing query reserves its MemoryBytesQuotaPerQuery of it.
+	MemoryBytesQuota int64 `json:"memoryBytesQuota,omitempty"`
+	// QueueSize is the number of queries of the organization that are allowed
+	// to be awaiting execution before new ones are rejected.
+	QueueSize int `json:"queueSize,omitempty"`
+}
+
+// Valid returns an error if any of the limits is negative.
+func (l *QueryLimits) Valid() error {
+	if l.ConcurrencyQuota < 0 || l.MemoryBytesQuotaPerQuery < 0 || l.MemoryBytesQuota < 0 || l.QueueSize < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "query limits must not be negative",
+		}
+	}
+	return nil
+}
+
+// IsZero reports whether none of the limits are set.
+func (l *QueryLimits) IsZero() bool {
+	return l == nil || *l == QueryLimits{}
+}
+
 // errors of org
 var (
 	// ErrOrgNameisEmpty is error when org name is empty
@@ -57,6 +97,12 @@ type OrganizationService interface {
 type OrganizationUpdate struct {
 	Name        *string
 	Description *string `json:"description,omitempty"`
+	// QueryLimits replaces the query limits of the organization. Setting no
+	// limits removes the overrides.
+	QueryLimits *QueryLimits `json:"queryLimits,omitempty"`
+	// StorageQuotaBytes replaces the storage quota of the organization.
+	// Setting zero removes the quota.
+	StorageQuotaBytes *int64 `json:"storageQuotaBytes,omitempty"`
 }
 
 // ErrInvalidOrgFilter is the error indicate org filter is empty
@@ -1,6 +1,11 @@
 package influxdb
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
 
 // PasswordsService is the service for managing basic auth passwords.
 type PasswordsService interface {
@@ -13,3 +18,83 @@ type PasswordsService interface {
 	// updates to the new password.
 	CompareAndSetPassword(ctx context.Context, name string, old string, new string) error
 }
+
+// PasswordPolicy is the rules passwords must satisfy when they are set.
+type PasswordPolicy struct {
+	// MinLength is the least number of characters of a password.
+	MinLength int
+	// RequireUppercase requires an uppercase letter.
+	RequireUppercase bool
+	// RequireLowercase requires a lowercase letter.
+	RequireLowercase bool
+	// RequireDigit requires a digit.
+	RequireDigit bool
+	// RequireSymbol requires a character that is not a letter or a digit.
+	RequireSymbol bool
+}
+
+// DefaultPasswordPolicy only requires passwords to be 8 characters long.
+var DefaultPasswordPolicy = PasswordPolicy{MinLength: 8}
+
+// Check returns an error listing the rules of the policy the password does not satisfy.
+func (p PasswordPolicy) Check(password string) error {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsLetter(r):
+			symbol = true
+		}
+	}
+
+	var missing []string
+	if n := len([]rune(password)); n < p.MinLength {
+		missing = append(missing, fmt.Sprintf("be at least %d characters long", p.MinLength))
+	}
+	if p.RequireUppercase && !upper {
+		missing = append(missing, "contain an uppercase letter")
+	}
+	if p.RequireLowercase && !lower {
+		missing = append(missing, "contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		missing = append(missing, "contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		missing = append(missing, "contain a symbol")
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return &Error{
+		Code: EInvalid,
+		Msg:  "passwords must " + strings.Join(missing, ", "),
+	}
+}
+
+// ErrSigninLocked is returned when signins are locked out after too many failures.
+var ErrSigninLocked = &Error{
+	Code: ETooManyRequests,
+	Msg:  "too many failed signins; try again later",
+}
+
+// SigninLockoutService tracks failed signins, and locks out the users and
+// the addresses that fail to sign in too often.
+type SigninLockoutService interface {
+	// CheckSignin returns ErrSigninLocked if signins as the user, or from the address, are locked out.
+	CheckSignin(ctx context.Context, name, addr string) error
+	// SigninFailed records a failed signin as the user from the address.
+	SigninFailed(ctx context.Context, name, addr string)
+	// SigninSucceeded clears the failed signins of the user.
+	// The failed signins of the address are kept, so that signing in to one account
+	// does not allow guessing the passwords of others.
+	SigninSucceeded(ctx context.Context, name string)
+	// UnlockSignin clears the failed signins of the user, so that they may sign in again.
+	UnlockSignin(ctx context.Context, name string) error
+}
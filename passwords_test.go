@@ -0,0 +1,69 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	strict := influxdb.PasswordPolicy{
+		MinLength:        10,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+	}
+
+	tests := []struct {
+		name     string
+		policy   influxdb.PasswordPolicy
+		password string
+		err      error
+	}{
+		{
+			name:     "default policy",
+			policy:   influxdb.DefaultPasswordPolicy,
+			password: "password",
+		},
+		{
+			name:     "too short for default policy",
+			policy:   influxdb.DefaultPasswordPolicy,
+			password: "passwor",
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "passwords must be at least 8 characters long",
+			},
+		},
+		{
+			name:     "satisfies strict policy",
+			policy:   strict,
+			password: "Correct-Horse-9",
+		},
+		{
+			name:     "length is counted in characters",
+			policy:   influxdb.PasswordPolicy{MinLength: 4},
+			password: "ééé",
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "passwords must be at least 4 characters long",
+			},
+		},
+		{
+			name:     "lists every rule not satisfied",
+			policy:   strict,
+			password: "password",
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "passwords must be at least 10 characters long, contain an uppercase letter, contain a digit, contain a symbol",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			influxdbtesting.ErrorsEqual(t, tt.policy.Check(tt.password), tt.err)
+		})
+	}
+}
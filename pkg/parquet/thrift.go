@@ -0,0 +1,144 @@
+package parquet
+
+import "encoding/binary"
+
+// Thrift compact protocol type identifiers used when encoding the Parquet
+// page headers and file metadata.
+const (
+	thriftBoolTrue  byte = 1
+	thriftBoolFalse byte = 2
+	thriftByte      byte = 3
+	thriftI32       byte = 5
+	thriftI64       byte = 6
+	thriftBinary    byte = 8
+	thriftList      byte = 9
+	thriftStruct    byte = 12
+)
+
+// thriftWriter is a minimal encoder for the Thrift compact protocol. It only
+// supports the subset of the protocol needed to describe Parquet metadata.
+type thriftWriter struct {
+	buf  []byte
+	last []int16 // stack of the last field id written for each open struct.
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{last: []int16{0}}
+}
+
+// Bytes returns the encoded bytes.
+func (w *thriftWriter) Bytes() []byte { return w.buf }
+
+func (w *thriftWriter) uvarint(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	w.buf = append(w.buf, tmp[:n]...)
+}
+
+func (w *thriftWriter) varint(v int64) {
+	w.uvarint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (w *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &w.last[len(w.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.varint(int64(id))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) listHeader(id int16, elem byte, n int) {
+	w.fieldHeader(id, thriftList)
+	w.listElemHeader(elem, n)
+}
+
+func (w *thriftWriter) listElemHeader(elem byte, n int) {
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|elem)
+		return
+	}
+	w.buf = append(w.buf, 0xf0|elem)
+	w.uvarint(uint64(n))
+}
+
+// Bool writes a boolean field.
+func (w *thriftWriter) Bool(id int16, v bool) {
+	if v {
+		w.fieldHeader(id, thriftBoolTrue)
+	} else {
+		w.fieldHeader(id, thriftBoolFalse)
+	}
+}
+
+// I8 writes an 8-bit integer field.
+func (w *thriftWriter) I8(id int16, v int8) {
+	w.fieldHeader(id, thriftByte)
+	w.buf = append(w.buf, byte(v))
+}
+
+// I32 writes a 32-bit integer field.
+func (w *thriftWriter) I32(id int16, v int32) {
+	w.fieldHeader(id, thriftI32)
+	w.varint(int64(v))
+}
+
+// I64 writes a 64-bit integer field.
+func (w *thriftWriter) I64(id int16, v int64) {
+	w.fieldHeader(id, thriftI64)
+	w.varint(v)
+}
+
+// String writes a binary field.
+func (w *thriftWriter) String(id int16, v string) {
+	w.fieldHeader(id, thriftBinary)
+	w.uvarint(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// I32List writes a list of 32-bit integers.
+func (w *thriftWriter) I32List(id int16, vs []int32) {
+	w.listHeader(id, thriftI32, len(vs))
+	for _, v := range vs {
+		w.varint(int64(v))
+	}
+}
+
+// StringList writes a list of binary values.
+func (w *thriftWriter) StringList(id int16, vs []string) {
+	w.listHeader(id, thriftBinary, len(vs))
+	for _, v := range vs {
+		w.uvarint(uint64(len(v)))
+		w.buf = append(w.buf, v...)
+	}
+}
+
+// StructBegin starts a nested struct field.
+func (w *thriftWriter) StructBegin(id int16) {
+	w.fieldHeader(id, thriftStruct)
+	w.last = append(w.last, 0)
+}
+
+// StructEnd terminates the innermost open struct.
+func (w *thriftWriter) StructEnd() {
+	w.buf = append(w.buf, 0)
+	w.last = w.last[:len(w.last)-1]
+}
+
+// StructListBegin starts a list of n structs. Each element must be written
+// with ListStructBegin and StructEnd.
+func (w *thriftWriter) StructListBegin(id int16, n int) {
+	w.listHeader(id, thriftStruct, n)
+}
+
+// ListStructBegin starts a struct that is an element of a list.
+func (w *thriftWriter) ListStructBegin() {
+	w.last = append(w.last, 0)
+}
+
+// Stop terminates the top level struct.
+func (w *thriftWriter) Stop() {
+	w.buf = append(w.buf, 0)
+}
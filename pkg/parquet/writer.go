@@ -0,0 +1,478 @@
+// Package parquet implements a minimal, streaming writer for the Apache
+// Parquet file format.
+//
+// The writer supports flat schemas of required or optional primitive
+// columns. Each row group is buffered in memory and written as a single
+// Snappy compressed, PLAIN encoded data page per column. Readers need no
+// special support beyond the core Parquet specification.
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/golang/snappy"
+)
+
+// DefaultRowGroupSize is the default number of rows buffered before a row
+// group is written.
+const DefaultRowGroupSize = 64 * 1024
+
+var magic = []byte("PAR1")
+
+// ErrWriterClosed is returned when writing to a closed Writer.
+var ErrWriterClosed = errors.New("parquet writer closed")
+
+// Type is the logical type of a column.
+type Type int
+
+// Supported column types.
+const (
+	Boolean Type = iota
+	Int64
+	Uint64
+	Double
+	String
+	TimestampNanos
+)
+
+func (t Type) String() string {
+	switch t {
+	case Boolean:
+		return "boolean"
+	case Int64:
+		return "int64"
+	case Uint64:
+		return "uint64"
+	case Double:
+		return "double"
+	case String:
+		return "string"
+	case TimestampNanos:
+		return "timestamp"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// Parquet physical types.
+const (
+	physicalBoolean   = 0
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+)
+
+// Parquet enumerations used in the file metadata.
+const (
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	convertedUTF8   = 0
+	convertedUint64 = 14
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecSnappy = 1
+
+	pageTypeData = 0
+)
+
+func (t Type) physical() int32 {
+	switch t {
+	case Boolean:
+		return physicalBoolean
+	case Double:
+		return physicalDouble
+	case String:
+		return physicalByteArray
+	default:
+		return physicalInt64
+	}
+}
+
+// Column describes a single column of a flat Parquet schema.
+type Column struct {
+	Name     string
+	Type     Type
+	Optional bool
+}
+
+// Writer encodes rows into a Parquet file written to an underlying io.Writer.
+// The underlying writer does not need to support seeking.
+type Writer struct {
+	// RowGroupSize is the number of rows buffered before a row group is
+	// flushed. It may be changed before the first call to Write.
+	RowGroupSize int
+
+	w         io.Writer
+	offset    int64
+	columns   []Column
+	bufs      []*columnBuffer
+	rows      int
+	numRows   int64
+	rowGroups []rowGroup
+	closed    bool
+}
+
+// NewWriter returns a Writer that encodes rows with the given columns to w.
+func NewWriter(w io.Writer, columns []Column) *Writer {
+	pw := &Writer{
+		RowGroupSize: DefaultRowGroupSize,
+		w:            w,
+		columns:      columns,
+		bufs:         make([]*columnBuffer, len(columns)),
+	}
+	for i, c := range columns {
+		pw.bufs[i] = &columnBuffer{col: c}
+	}
+	return pw
+}
+
+// Write appends a row. The row must have one value for each column, in
+// schema order. A nil value represents null and is only valid for optional
+// columns.
+func (w *Writer) Write(row []interface{}) error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	if len(row) != len(w.columns) {
+		return fmt.Errorf("row has %d values, schema has %d columns", len(row), len(w.columns))
+	}
+	for i, v := range row {
+		if err := w.bufs[i].append(v); err != nil {
+			return err
+		}
+	}
+	w.rows++
+
+	if w.rows >= w.RowGroupSize {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush writes all buffered rows as a new row group.
+func (w *Writer) Flush() error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+	if w.rows == 0 {
+		return nil
+	}
+
+	rg := rowGroup{numRows: int64(w.rows)}
+	for _, b := range w.bufs {
+		cc, err := w.writeColumnChunk(b)
+		if err != nil {
+			return err
+		}
+		rg.columns = append(rg.columns, cc)
+		rg.totalBytes += cc.uncompressedSize
+		b.reset()
+	}
+	w.rowGroups = append(w.rowGroups, rg)
+	w.numRows += int64(w.rows)
+	w.rows = 0
+	return nil
+}
+
+// Close flushes any buffered rows and writes the file footer. It does not
+// close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	w.closed = true
+
+	meta := w.fileMetadata()
+	if err := w.write(meta); err != nil {
+		return err
+	}
+	var tmp [4]byte
+	binary.LittleEndian.PutUint32(tmp[:], uint32(len(meta)))
+	if err := w.write(tmp[:]); err != nil {
+		return err
+	}
+	return w.write(magic)
+}
+
+func (w *Writer) write(p []byte) error {
+	n, err := w.w.Write(p)
+	w.offset += int64(n)
+	return err
+}
+
+func (w *Writer) writeMagic() error {
+	if w.offset > 0 {
+		return nil
+	}
+	return w.write(magic)
+}
+
+func (w *Writer) writeColumnChunk(b *columnBuffer) (columnChunk, error) {
+	raw := b.encode()
+	compressed := snappy.Encode(nil, raw)
+
+	th := newThriftWriter()
+	th.I32(1, pageTypeData)
+	th.I32(2, int32(len(raw)))
+	th.I32(3, int32(len(compressed)))
+	th.StructBegin(5) // DataPageHeader
+	th.I32(1, int32(b.n))
+	th.I32(2, encodingPlain)
+	th.I32(3, encodingRLE)
+	th.I32(4, encodingRLE)
+	th.StructEnd()
+	th.Stop()
+	header := th.Bytes()
+
+	cc := columnChunk{
+		offset:           w.offset,
+		numValues:        int64(b.n),
+		uncompressedSize: int64(len(header) + len(raw)),
+		compressedSize:   int64(len(header) + len(compressed)),
+	}
+	if err := w.write(header); err != nil {
+		return cc, err
+	}
+	return cc, w.write(compressed)
+}
+
+func (w *Writer) fileMetadata() []byte {
+	th := newThriftWriter()
+	th.I32(1, 1) // version
+
+	th.StructListBegin(2, len(w.columns)+1)
+	th.ListStructBegin()
+	th.String(4, "schema")
+	th.I32(5, int32(len(w.columns)))
+	th.StructEnd()
+	for _, c := range w.columns {
+		th.ListStructBegin()
+		writeSchemaElement(th, c)
+		th.StructEnd()
+	}
+
+	th.I64(3, w.numRows)
+
+	th.StructListBegin(4, len(w.rowGroups))
+	for _, rg := range w.rowGroups {
+		th.ListStructBegin()
+		th.StructListBegin(1, len(rg.columns))
+		for i, cc := range rg.columns {
+			c := w.columns[i]
+			th.ListStructBegin()
+			th.I64(2, cc.offset)
+			th.StructBegin(3) // ColumnMetaData
+			th.I32(1, c.Type.physical())
+			th.I32List(2, []int32{encodingPlain, encodingRLE})
+			th.StringList(3, []string{c.Name})
+			th.I32(4, codecSnappy)
+			th.I64(5, cc.numValues)
+			th.I64(6, cc.uncompressedSize)
+			th.I64(7, cc.compressedSize)
+			th.I64(9, cc.offset)
+			th.StructEnd()
+			th.StructEnd()
+		}
+		th.I64(2, rg.totalBytes)
+		th.I64(3, rg.numRows)
+		th.StructEnd()
+	}
+
+	th.String(6, "influxdb")
+	th.Stop()
+	return th.Bytes()
+}
+
+func writeSchemaElement(th *thriftWriter, c Column) {
+	th.I32(1, c.Type.physical())
+	if c.Optional {
+		th.I32(3, repetitionOptional)
+	} else {
+		th.I32(3, repetitionRequired)
+	}
+	th.String(4, c.Name)
+
+	switch c.Type {
+	case String:
+		th.I32(6, convertedUTF8)
+		th.StructBegin(10) // LogicalType
+		th.StructBegin(1)  // StringType
+		th.StructEnd()
+		th.StructEnd()
+	case Uint64:
+		th.I32(6, convertedUint64)
+		th.StructBegin(10) // LogicalType
+		th.StructBegin(10) // IntType
+		th.I8(1, 64)
+		th.Bool(2, false)
+		th.StructEnd()
+		th.StructEnd()
+	case TimestampNanos:
+		th.StructBegin(10) // LogicalType
+		th.StructBegin(8)  // TimestampType
+		th.Bool(1, true)
+		th.StructBegin(2) // TimeUnit
+		th.StructBegin(3) // NANOS
+		th.StructEnd()
+		th.StructEnd()
+		th.StructEnd()
+		th.StructEnd()
+	}
+}
+
+type rowGroup struct {
+	columns    []columnChunk
+	totalBytes int64
+	numRows    int64
+}
+
+type columnChunk struct {
+	offset           int64
+	numValues        int64
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+// columnBuffer accumulates the definition levels and PLAIN encoded values
+// of a single column for the current row group.
+type columnBuffer struct {
+	col    Column
+	n      int
+	defs   []bool
+	values []byte
+	bools  []bool
+}
+
+func (b *columnBuffer) reset() {
+	b.n = 0
+	b.defs = b.defs[:0]
+	b.values = b.values[:0]
+	b.bools = b.bools[:0]
+}
+
+func (b *columnBuffer) append(v interface{}) error {
+	if v == nil {
+		if !b.col.Optional {
+			return fmt.Errorf("column %q: null value in required column", b.col.Name)
+		}
+		b.defs = append(b.defs, false)
+		b.n++
+		return nil
+	}
+
+	switch b.col.Type {
+	case Boolean:
+		x, ok := v.(bool)
+		if !ok {
+			return b.typeError(v)
+		}
+		b.bools = append(b.bools, x)
+	case Int64, TimestampNanos:
+		x, ok := v.(int64)
+		if !ok {
+			return b.typeError(v)
+		}
+		b.values = appendUint64(b.values, uint64(x))
+	case Uint64:
+		x, ok := v.(uint64)
+		if !ok {
+			return b.typeError(v)
+		}
+		b.values = appendUint64(b.values, x)
+	case Double:
+		x, ok := v.(float64)
+		if !ok {
+			return b.typeError(v)
+		}
+		b.values = appendUint64(b.values, math.Float64bits(x))
+	case String:
+		switch x := v.(type) {
+		case string:
+			b.values = appendUint32(b.values, uint32(len(x)))
+			b.values = append(b.values, x...)
+		case []byte:
+			b.values = appendUint32(b.values, uint32(len(x)))
+			b.values = append(b.values, x...)
+		default:
+			return b.typeError(v)
+		}
+	}
+
+	if b.col.Optional {
+		b.defs = append(b.defs, true)
+	}
+	b.n++
+	return nil
+}
+
+func (b *columnBuffer) typeError(v interface{}) error {
+	return fmt.Errorf("column %q: cannot write %T to %s column", b.col.Name, v, b.col.Type)
+}
+
+// encode returns the uncompressed data page contents for the buffer.
+func (b *columnBuffer) encode() []byte {
+	var out []byte
+	if b.col.Optional {
+		levels := encodeLevels(b.defs)
+		out = appendUint32(out, uint32(len(levels)))
+		out = append(out, levels...)
+	}
+
+	if b.col.Type == Boolean {
+		packed := make([]byte, (len(b.bools)+7)/8)
+		for i, v := range b.bools {
+			if v {
+				packed[i/8] |= 1 << uint(i%8)
+			}
+		}
+		return append(out, packed...)
+	}
+	return append(out, b.values...)
+}
+
+// encodeLevels encodes definition levels with a bit width of one using the
+// RLE runs of the RLE/bit-packing hybrid encoding.
+func encodeLevels(defs []bool) []byte {
+	var out []byte
+	var tmp [binary.MaxVarintLen64]byte
+	for i := 0; i < len(defs); {
+		j := i + 1
+		for j < len(defs) && defs[j] == defs[i] {
+			j++
+		}
+		n := binary.PutUvarint(tmp[:], uint64(j-i)<<1)
+		out = append(out, tmp[:n]...)
+		if defs[i] {
+			out = append(out, 1)
+		} else {
+			out = append(out, 0)
+		}
+		i = j
+	}
+	return out
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	var tmp [4]byte
+	binary.LittleEndian.PutUint32(tmp[:], v)
+	return append(b, tmp[:]...)
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var tmp [8]byte
+	binary.LittleEndian.PutUint64(tmp[:], v)
+	return append(b, tmp[:]...)
+}
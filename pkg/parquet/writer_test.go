@@ -0,0 +1,123 @@
+package parquet_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/pkg/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := parquet.NewWriter(&buf, []parquet.Column{
+		{Name: "_measurement", Type: parquet.String},
+		{Name: "_time", Type: parquet.TimestampNanos},
+		{Name: "_value", Type: parquet.Double, Optional: true},
+		{Name: "count", Type: parquet.Int64, Optional: true},
+		{Name: "total", Type: parquet.Uint64},
+		{Name: "ok", Type: parquet.Boolean, Optional: true},
+	})
+	w.RowGroupSize = 2
+
+	rows := [][]interface{}{
+		{"cpu", int64(0), 1.5, int64(-1), uint64(1), true},
+		{"cpu", int64(10), nil, nil, uint64(2), false},
+		{"mem", int64(20), 3.0, int64(3), uint64(math.MaxUint64), nil},
+	}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatalf("unexpected error writing row: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing writer: %v", err)
+	}
+
+	if err := w.Write(rows[0]); err != parquet.ErrWriterClosed {
+		t.Errorf("expected ErrWriterClosed, got %v", err)
+	}
+
+	// Read the file back with an independent implementation of the format.
+	r, err := reader.NewParquetColumnReader(&bufferFile{Reader: bytes.NewReader(buf.Bytes())}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error reading footer: %v", err)
+	}
+	if got, exp := r.GetNumRows(), int64(len(rows)); got != exp {
+		t.Fatalf("got %d rows, exp %d", got, exp)
+	}
+	if got, exp := len(r.Footer.RowGroups), 2; got != exp {
+		t.Fatalf("got %d row groups, exp %d", got, exp)
+	}
+	if got, exp := r.Footer.GetCreatedBy(), "influxdb"; got != exp {
+		t.Errorf("got created by %q, exp %q", got, exp)
+	}
+
+	for i := range rows[0] {
+		values, _, _, err := r.ReadColumnByIndex(int64(i), int64(len(rows)))
+		if err != nil {
+			t.Fatalf("unexpected error reading column %d: %v", i, err)
+		}
+
+		var exp []interface{}
+		for _, row := range rows {
+			v := row[i]
+			// The reader returns UINT_64 columns as their physical type.
+			if x, ok := v.(uint64); ok {
+				v = int64(x)
+			}
+			exp = append(exp, v)
+		}
+		if !cmp.Equal(values, exp) {
+			t.Errorf("unexpected values in column %d -got/+exp\n%s", i, cmp.Diff(values, exp))
+		}
+	}
+}
+
+// bufferFile implements the file interface of the Parquet reader on top of
+// an in-memory buffer.
+type bufferFile struct {
+	*bytes.Reader
+}
+
+func (f *bufferFile) Open(string) (source.ParquetFile, error) {
+	r := *f.Reader
+	return &bufferFile{Reader: &r}, nil
+}
+
+func (f *bufferFile) Create(string) (source.ParquetFile, error) {
+	return nil, errors.New("read-only file")
+}
+
+func (f *bufferFile) Write([]byte) (int, error) {
+	return 0, errors.New("read-only file")
+}
+
+func (f *bufferFile) Close() error { return nil }
+
+func TestWriter_InvalidValues(t *testing.T) {
+	cols := []parquet.Column{
+		{Name: "required", Type: parquet.Int64},
+	}
+
+	tests := []struct {
+		name string
+		row  []interface{}
+	}{
+		{name: "null in required column", row: []interface{}{nil}},
+		{name: "wrong type", row: []interface{}{"1"}},
+		{name: "wrong arity", row: []interface{}{int64(1), int64(2)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := parquet.NewWriter(&bytes.Buffer{}, cols)
+			if err := w.Write(tt.row); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
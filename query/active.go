@@ -0,0 +1,48 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+// ActiveQuery is a query that has been submitted and not yet finished.
+type ActiveQuery struct {
+	ID             platform.ID
+	OrganizationID platform.ID
+	// UserID is the user of the authorization that submitted the query,
+	// if any.
+	UserID       platform.ID
+	Query        string
+	CompilerType string
+	State        string
+	StartedAt    time.Time
+	Elapsed      time.Duration
+	// MemoryBytes is the number of bytes of table memory the query is using.
+	MemoryBytes int64
+}
+
+// ActiveQueryFilter selects active queries.
+type ActiveQueryFilter struct {
+	OrganizationID *platform.ID
+}
+
+// ActiveQueryService lists and cancels the queries that are running.
+type ActiveQueryService interface {
+	// FindActiveQueryByID returns a single active query by ID.
+	FindActiveQueryByID(ctx context.Context, id platform.ID) (*ActiveQuery, error)
+
+	// FindActiveQueries returns the active queries that match filter.
+	FindActiveQueries(ctx context.Context, filter ActiveQueryFilter) ([]*ActiveQuery, error)
+
+	// CancelActiveQuery cancels the execution of an active query.
+	CancelActiveQuery(ctx context.Context, id platform.ID) error
+}
+
+// ErrActiveQueryNotFound is returned when an active query cannot be found,
+// including when it has already finished.
+var ErrActiveQueryNotFound = &platform.Error{
+	Code: platform.ENotFound,
+	Msg:  "query not found",
+}
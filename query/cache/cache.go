@@ -0,0 +1,334 @@
+// Package cache caches the results of Flux queries.
+//
+// The QueryService sits in front of a query.AsyncQueryService, usually the
+// control.Controller, and serves identical queries of an organization from
+// the memory of a Cache. Queries are identified by their normalised AST and
+// are evaluated with now aligned to the alignment of the cache, so that every
+// viewer of a dashboard refreshing within the same interval shares the same
+// results.
+//
+// Only queries that read buckets named by string literals and that have no
+// side effects are cached. Cached results are removed when a write lands in
+// the time range they read, when they expire at the end of their alignment
+// interval, or when the cache needs room for newer results.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/query"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// StatisticsKey is the key of the flux.Statistics metadata reporting whether
+// the results of a query were served from the cache: "hit" or "miss".
+const StatisticsKey = "influxdb/cache"
+
+// DefaultAlignment is the default interval now is aligned to.
+const DefaultAlignment = 10 * time.Second
+
+// Config configures a Cache.
+type Config struct {
+	// MaxMemoryBytes is the maximum number of bytes of table memory held by
+	// cached results. The results of a query using more than a quarter of it
+	// are not cached.
+	MaxMemoryBytes int64
+	// Alignment is the interval now is truncated to when evaluating cached
+	// queries. Cached results are served until the end of the interval.
+	Alignment time.Duration
+	Logger    *zap.Logger
+}
+
+// Validate returns an error if the configuration is invalid.
+func (c *Config) Validate() error {
+	if c.MaxMemoryBytes <= 0 {
+		return errors.New("MaxMemoryBytes must be positive")
+	}
+	if c.Alignment <= 0 {
+		return errors.New("Alignment must be positive")
+	}
+	return nil
+}
+
+// Cache holds the results of queries. Queries are served from it by a
+// QueryService, and cached results are invalidated by a PointsWriter.
+type Cache struct {
+	maxMemoryBytes int64
+	maxEntryBytes  int64
+	alignment      time.Duration
+
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+
+	logger  *zap.Logger
+	metrics *cacheMetrics
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	// lru orders the entries from the most to the least recently used.
+	lru      *list.List
+	byBucket map[influxdb.ID]map[*entry]struct{}
+	// generations counts the writes to each bucket, so that results read
+	// while a write was in flight are not cached.
+	generations map[influxdb.ID]uint64
+	size        int64
+}
+
+// entry is the cached results of a query.
+type entry struct {
+	key     string
+	buckets []influxdb.ID
+	// start and stop are the bounds of the time range read by the query.
+	start, stop int64
+	results     []*cachedResult
+	size        int64
+	expires     time.Time
+	elem        *list.Element
+}
+
+type cachedResult struct {
+	name   string
+	tables []flux.BufferedTable
+}
+
+// New returns an empty Cache.
+func New(c Config) (*Cache, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	logger := c.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	logger.Info("Starting query cache",
+		zap.Int64("max_memory_bytes", c.MaxMemoryBytes),
+		zap.Duration("alignment", c.Alignment))
+
+	return &Cache{
+		maxMemoryBytes: c.MaxMemoryBytes,
+		maxEntryBytes:  c.MaxMemoryBytes / 4,
+		alignment:      c.Alignment,
+		Now:            time.Now,
+		logger:         logger,
+		metrics:        newCacheMetrics(),
+		entries:        make(map[string]*entry),
+		lru:            list.New(),
+		byBucket:       make(map[influxdb.ID]map[*entry]struct{}),
+		generations:    make(map[influxdb.ID]uint64),
+	}, nil
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (c *Cache) PrometheusCollectors() []prometheus.Collector {
+	return c.metrics.PrometheusCollectors()
+}
+
+// QueryService is a query.AsyncQueryService that serves the results of the
+// queries of the underlying query service from a Cache.
+type QueryService struct {
+	Underlying query.AsyncQueryService
+	// BucketService finds the IDs of the buckets queries read by name.
+	BucketService influxdb.BucketService
+	Cache         *Cache
+}
+
+// Query serves the results of req from the cache if they are cached, or
+// runs req against the underlying query service and caches its results.
+func (s *QueryService) Query(ctx context.Context, req *query.Request) (flux.Query, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	c := s.Cache
+	a, ok := analyse(req, c.Now(), c.alignment)
+	if !ok || req.Authorization == nil {
+		c.metrics.requests.WithLabelValues(string(labelUncacheable)).Inc()
+		return s.Underlying.Query(ctx, req)
+	}
+
+	buckets, err := resolve(a.buckets, func(name string) (influxdb.ID, error) {
+		b, err := s.BucketService.FindBucket(ctx, influxdb.BucketFilter{
+			OrganizationID: &req.OrganizationID,
+			Name:           &name,
+		})
+		if err != nil {
+			return 0, err
+		}
+		return b.ID, nil
+	})
+	if err != nil || !canRead(req.Authorization, req.OrganizationID, buckets) {
+		// Let the query service report the error.
+		c.metrics.requests.WithLabelValues(string(labelUncacheable)).Inc()
+		return s.Underlying.Query(ctx, req)
+	}
+
+	if q := c.get(a.key); q != nil {
+		c.metrics.requests.WithLabelValues(string(labelHit)).Inc()
+		return q, nil
+	}
+	c.metrics.requests.WithLabelValues(string(labelMiss)).Inc()
+
+	generations := c.generationsOf(buckets)
+	q, err := s.Underlying.Query(ctx, a.req)
+	if err != nil {
+		return nil, err
+	}
+	return newMissQuery(q, c, a.key, buckets, generations), nil
+}
+
+// canRead reports whether auth may read all of buckets.
+func canRead(auth *influxdb.Authorization, orgID influxdb.ID, buckets []influxdb.ID) bool {
+	for _, id := range buckets {
+		p, err := influxdb.NewPermissionAtID(id, influxdb.ReadAction, influxdb.BucketsResourceType, orgID)
+		if err != nil || !auth.Allowed(*p) {
+			return false
+		}
+	}
+	return true
+}
+
+// get returns a query replaying the cached results of key, or nil if they
+// are not cached.
+func (c *Cache) get(key string) flux.Query {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if !c.Now().Before(e.expires) {
+		c.remove(e, labelExpired)
+		return nil
+	}
+	c.lru.MoveToFront(e.elem)
+
+	// The tables are copied while holding the lock, so that they cannot be
+	// released by an eviction in the meantime.
+	results := make([]flux.Result, len(e.results))
+	for i, r := range e.results {
+		tables := make([]flux.Table, len(r.tables))
+		for j, t := range r.tables {
+			tables[j] = t.Copy()
+		}
+		results[i] = &result{name: r.name, tables: tables}
+	}
+	return newHitQuery(results)
+}
+
+// generationsOf returns the current generations of buckets.
+func (c *Cache) generationsOf(buckets []influxdb.ID) []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	generations := make([]uint64, len(buckets))
+	for i, id := range buckets {
+		generations[i] = c.generations[id]
+	}
+	return generations
+}
+
+// put caches e, unless one of its buckets was written since generations
+// were read.
+func (c *Cache) put(e *entry, generations []uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, id := range e.buckets {
+		if c.generations[id] != generations[i] {
+			releaseResults(e.results)
+			return
+		}
+	}
+
+	if old, ok := c.entries[e.key]; ok {
+		c.remove(old, labelExpired)
+	}
+
+	// Remove the expired entries first, then the least recently used ones
+	// until there is room for e.
+	now := c.Now()
+	for elem := c.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if old := elem.Value.(*entry); !now.Before(old.expires) {
+			c.remove(old, labelExpired)
+		}
+		elem = prev
+	}
+	for c.size+e.size > c.maxMemoryBytes && c.lru.Len() > 0 {
+		c.remove(c.lru.Back().Value.(*entry), labelMemory)
+	}
+
+	e.expires = now.Truncate(c.alignment).Add(c.alignment)
+	e.elem = c.lru.PushFront(e)
+	c.entries[e.key] = e
+	for _, id := range e.buckets {
+		m, ok := c.byBucket[id]
+		if !ok {
+			m = make(map[*entry]struct{})
+			c.byBucket[id] = m
+		}
+		m[e] = struct{}{}
+	}
+	c.size += e.size
+	c.updateGauges()
+}
+
+// remove removes e from the cache and releases its tables.
+func (c *Cache) remove(e *entry, reason evictionsLabel) {
+	delete(c.entries, e.key)
+	c.lru.Remove(e.elem)
+	for _, id := range e.buckets {
+		if m, ok := c.byBucket[id]; ok {
+			delete(m, e)
+			if len(m) == 0 {
+				delete(c.byBucket, id)
+			}
+		}
+	}
+	c.size -= e.size
+	releaseResults(e.results)
+
+	c.metrics.evictions.WithLabelValues(string(reason)).Inc()
+	c.updateGauges()
+}
+
+func (c *Cache) updateGauges() {
+	c.metrics.entries.Set(float64(len(c.entries)))
+	c.metrics.memoryBytes.Set(float64(c.size))
+}
+
+// Invalidate removes the cached results that read data of bucketID between
+// min and max, inclusive, and prevents the results of queries of the bucket
+// that are running from being cached.
+func (c *Cache) Invalidate(bucketID influxdb.ID, min, max int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generations[bucketID]++
+	for e := range c.byBucket[bucketID] {
+		if min < e.stop && max >= e.start {
+			c.remove(e, labelWrite)
+		}
+	}
+}
+
+func releaseResults(results []*cachedResult) {
+	for _, r := range results {
+		for _, t := range r.tables {
+			t.Done()
+		}
+	}
+}
+
+// unbounded is the time range of results that do not report the range they
+// read.
+var unbounded = [2]int64{math.MinInt64, math.MaxInt64}
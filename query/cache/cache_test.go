@@ -0,0 +1,229 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/lang"
+	fluxmock "github.com/influxdata/flux/mock"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/cache"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+const (
+	orgID    = influxdb.ID(0x1)
+	bucketID = influxdb.ID(0x2)
+)
+
+// service counts the queries it runs and the now they were run at.
+type service struct {
+	querymock.AsyncQueryService
+	n   int
+	now []time.Time
+}
+
+func newService() *service {
+	s := &service{}
+	s.QueryF = func(ctx context.Context, req *query.Request) (flux.Query, error) {
+		s.n++
+		if c, ok := req.Compiler.(lang.FluxCompiler); ok {
+			s.now = append(s.now, c.Now)
+		}
+		q := &fluxmock.Query{}
+		q.ProduceResults(func(results chan<- flux.Result, canceled <-chan struct{}) {
+			select {
+			case results <- executetest.NewResult([]*executetest.Table{{
+				KeyCols: []string{"_start", "_stop"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_start", Type: flux.TTime},
+					{Label: "_stop", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(100), execute.Time(200), float64(s.n)},
+				},
+			}}):
+			case <-canceled:
+			}
+		})
+		return q, nil
+	}
+	return s
+}
+
+func newCache(t *testing.T, s *service, now *time.Time) (*cache.Cache, *cache.QueryService) {
+	t.Helper()
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, f influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		if f.Name == nil || *f.Name != "telegraf" || f.OrganizationID == nil || *f.OrganizationID != orgID {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+		}
+		return &influxdb.Bucket{ID: bucketID, OrgID: orgID, Name: "telegraf"}, nil
+	}
+	c, err := cache.New(cache.Config{MaxMemoryBytes: 1 << 20, Alignment: 10 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Now = func() time.Time { return *now }
+	return c, &cache.QueryService{Underlying: s, BucketService: buckets, Cache: c}
+}
+
+func newRequest(q string, perms ...influxdb.Permission) *query.Request {
+	if perms == nil {
+		perms = []influxdb.Permission{{
+			Action:   influxdb.ReadAction,
+			Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: idPtr(orgID)},
+		}}
+	}
+	return &query.Request{
+		Authorization:  &influxdb.Authorization{Status: influxdb.Active, Permissions: perms},
+		OrganizationID: orgID,
+		Compiler:       lang.FluxCompiler{Query: q},
+	}
+}
+
+func idPtr(id influxdb.ID) *influxdb.ID { return &id }
+
+// run runs req and returns the value of its only row and whether it was
+// served from the cache.
+func run(t *testing.T, c *cache.QueryService, req *query.Request) (float64, string) {
+	t.Helper()
+	q, err := c.Query(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v float64
+	for r := range q.Results() {
+		if err := r.Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				v = cr.Floats(2).Value(0)
+				return nil
+			})
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q.Done()
+	if err := q.Err(); err != nil {
+		t.Fatal(err)
+	}
+	var result string
+	if md := q.Statistics().Metadata[cache.StatisticsKey]; len(md) == 1 {
+		result = md[0].(string)
+	}
+	return v, result
+}
+
+func TestCache(t *testing.T) {
+	now := time.Unix(1000, int64(3*time.Second))
+	s := newService()
+	c, qs := newCache(t, s, &now)
+
+	if v, result := run(t, qs, newRequest(`from(bucket: "telegraf") |> range(start: -1m)`)); v != 1 || result != "miss" {
+		t.Fatalf("unexpected first query %v (%s)", v, result)
+	}
+	if got, exp := s.now[0], time.Unix(1000, 0); !got.Equal(exp) {
+		t.Errorf("expected query to run at aligned now -got/+exp\n%v\n%v", got, exp)
+	}
+
+	// The same query, formatted differently, later in the same interval.
+	now = now.Add(5 * time.Second)
+	if v, result := run(t, qs, newRequest("// cpu\nfrom(bucket:\"telegraf\")\n\t|> range(start:-1m)")); v != 1 || result != "hit" {
+		t.Fatalf("unexpected cached query %v (%s)", v, result)
+	}
+
+	// A write outside of the range read by the query keeps the results.
+	w := &cache.PointsWriter{Underlying: &mock.PointsWriter{}, Cache: c}
+	write := func(ts int64) {
+		t.Helper()
+		name := tsdb.EncodeName(orgID, bucketID)
+		p, err := models.NewPoint(string(name[:]), nil, models.Fields{"v": 1.0}, time.Unix(0, ts))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WritePoints(context.Background(), []models.Point{p}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(300)
+	if _, result := run(t, qs, newRequest(`from(bucket: "telegraf") |> range(start: -1m)`)); result != "hit" {
+		t.Errorf("expected results to be cached after unrelated write, got %s", result)
+	}
+
+	// A write into the range removes them.
+	write(150)
+	if v, result := run(t, qs, newRequest(`from(bucket: "telegraf") |> range(start: -1m)`)); v != 2 || result != "miss" {
+		t.Errorf("unexpected query after write %v (%s)", v, result)
+	}
+
+	// The results expire at the end of the interval.
+	now = now.Add(5 * time.Second)
+	if v, result := run(t, qs, newRequest(`from(bucket: "telegraf") |> range(start: -1m)`)); v != 3 || result != "miss" {
+		t.Errorf("unexpected query in next interval %v (%s)", v, result)
+	}
+}
+
+func TestCache_Uncacheable(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *query.Request
+	}{
+		{
+			name: "writes",
+			req:  newRequest(`from(bucket: "telegraf") |> range(start: -1m) |> to(bucket: "other")`),
+		},
+		{
+			name: "variable bucket",
+			req:  newRequest(`b = "telegraf" from(bucket: b) |> range(start: -1m)`),
+		},
+		{
+			name: "unsafe import",
+			req:  newRequest(`import "csv" csv.from(csv: "") |> range(start: -1m)`),
+		},
+		{
+			name: "profile",
+			req: func() *query.Request {
+				req := newRequest(`from(bucket: "telegraf") |> range(start: -1m)`)
+				req.Profile = true
+				return req
+			}(),
+		},
+		{
+			name: "unknown bucket",
+			req:  newRequest(`from(bucket: "other") |> range(start: -1m)`),
+		},
+		{
+			name: "unauthorized",
+			req: newRequest(`from(bucket: "telegraf") |> range(start: -1m)`, influxdb.Permission{
+				Action:   influxdb.ReadAction,
+				Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: idPtr(orgID), ID: idPtr(0x3)},
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(1000, 0)
+			s := newService()
+			_, qs := newCache(t, s, &now)
+
+			for i := 0; i < 2; i++ {
+				if _, result := run(t, qs, tt.req); result != "" {
+					t.Errorf("expected query not to be cached, got %s", result)
+				}
+			}
+			if s.n != 2 {
+				t.Errorf("unexpected number of queries -got/+exp\n%d\n%d", s.n, 2)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+// safeImports are the packages a cached query may import. They neither read
+// from storage nor have side effects.
+var safeImports = map[string]bool{
+	"date":    true,
+	"math":    true,
+	"regexp":  true,
+	"strings": true,
+}
+
+// unsafeFunctions are the builtin functions that make a query uncacheable,
+// because they either write data or read data that writes do not invalidate.
+var unsafeFunctions = map[string]bool{
+	"buckets": true,
+	"to":      true,
+}
+
+// bucketRef is a bucket read by a query, identified by either name or ID.
+type bucketRef struct {
+	name string
+	id   string
+}
+
+// analysis is the result of analysing the Flux of a request.
+type analysis struct {
+	key     string
+	now     time.Time
+	buckets []bucketRef
+	req     *query.Request
+}
+
+// analyse computes the cache key of req and the buckets it reads. It returns
+// false if the request is not cacheable.
+//
+// The key is made of the organization, the formatted AST of the query, which
+// normalises whitespace and comments away, and now aligned to alignment. The
+// returned request is evaluated at that aligned now, so that every request
+// with the same key produces the same results.
+//
+// Profiled requests are not cacheable, since their profile describes a single
+// execution.
+func analyse(req *query.Request, now time.Time, alignment time.Duration) (*analysis, bool) {
+	if req.Profile {
+		return nil, false
+	}
+
+	var (
+		pkg    *ast.Package
+		extern *ast.File
+		at     time.Time
+	)
+	switch c := req.Compiler.(type) {
+	case lang.FluxCompiler:
+		pkg, extern, at = parser.ParseSource(c.Query), c.Extern, c.Now
+	case *lang.FluxCompiler:
+		pkg, extern, at = parser.ParseSource(c.Query), c.Extern, c.Now
+	case lang.ASTCompiler:
+		pkg, at = c.AST, c.Now
+	case *lang.ASTCompiler:
+		pkg, at = c.AST, c.Now
+	default:
+		return nil, false
+	}
+	if pkg == nil || ast.Check(pkg) > 0 {
+		return nil, false
+	}
+
+	// Requests from the HTTP API set now to the time they were received.
+	if at.IsZero() {
+		at = now
+	}
+	at = at.Truncate(alignment)
+
+	nodes := []ast.Node{pkg}
+	if extern != nil {
+		nodes = append(nodes, extern)
+	}
+	var buckets []bucketRef
+	for _, n := range nodes {
+		refs, ok := analyseNode(n)
+		if !ok {
+			return nil, false
+		}
+		buckets = append(buckets, refs...)
+	}
+	if len(buckets) == 0 {
+		return nil, false
+	}
+
+	h := sha256.New()
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(req.OrganizationID))
+	h.Write(buf[:])
+	binary.BigEndian.PutUint64(buf[:], uint64(at.UnixNano()))
+	h.Write(buf[:])
+	if extern != nil {
+		h.Write([]byte(ast.Format(extern)))
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(ast.Format(pkg)))
+
+	r := *req
+	switch c := req.Compiler.(type) {
+	case lang.FluxCompiler:
+		c.Now = at
+		r.Compiler = c
+	case *lang.FluxCompiler:
+		cc := *c
+		cc.Now = at
+		r.Compiler = cc
+	case lang.ASTCompiler:
+		c.Now = at
+		r.Compiler = c
+	case *lang.ASTCompiler:
+		cc := *c
+		cc.Now = at
+		r.Compiler = cc
+	}
+
+	return &analysis{
+		key:     hex.EncodeToString(h.Sum(nil)),
+		now:     at,
+		buckets: buckets,
+		req:     &r,
+	}, true
+}
+
+// analyseNode returns the buckets read by the from calls of n. It returns
+// false if n imports a package that is not safe, calls an unsafe function,
+// or reads from a bucket that is not given as a string literal.
+func analyseNode(n ast.Node) ([]bucketRef, bool) {
+	var (
+		refs []bucketRef
+		ok   = true
+	)
+	ast.Visit(n, func(n ast.Node) {
+		if !ok {
+			return
+		}
+		switch n := n.(type) {
+		case *ast.ImportDeclaration:
+			if n.Path == nil || !safeImports[n.Path.Value] {
+				ok = false
+			}
+		case *ast.CallExpression:
+			id, isIdent := n.Callee.(*ast.Identifier)
+			if !isIdent {
+				return
+			}
+			if unsafeFunctions[id.Name] {
+				ok = false
+			} else if id.Name == "from" {
+				var ref bucketRef
+				ref, ok = fromBucket(n)
+				refs = append(refs, ref)
+			}
+		}
+	})
+	return refs, ok
+}
+
+// fromBucket returns the bucket of a call to from.
+func fromBucket(call *ast.CallExpression) (bucketRef, bool) {
+	if len(call.Arguments) != 1 {
+		return bucketRef{}, false
+	}
+	obj, ok := call.Arguments[0].(*ast.ObjectExpression)
+	if !ok || obj.With != nil {
+		return bucketRef{}, false
+	}
+
+	var ref bucketRef
+	for _, p := range obj.Properties {
+		lit, ok := p.Value.(*ast.StringLiteral)
+		if !ok {
+			return bucketRef{}, false
+		}
+		switch p.Key.Key() {
+		case "bucket":
+			ref.name = lit.Value
+		case "bucketID":
+			ref.id = lit.Value
+		default:
+			// Reads from another host, for instance.
+			return bucketRef{}, false
+		}
+	}
+	return ref, (ref.name == "") != (ref.id == "")
+}
+
+// resolve returns the distinct IDs of refs, looking up the IDs of buckets
+// referenced by name with find.
+func resolve(refs []bucketRef, find func(name string) (influxdb.ID, error)) ([]influxdb.ID, error) {
+	ids := make([]influxdb.ID, 0, len(refs))
+	seen := make(map[influxdb.ID]bool, len(refs))
+	for _, ref := range refs {
+		var id influxdb.ID
+		if ref.id != "" {
+			if err := id.DecodeFromString(ref.id); err != nil {
+				return nil, err
+			}
+		} else {
+			var err error
+			if id, err = find(ref.name); err != nil {
+				return nil, err
+			}
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
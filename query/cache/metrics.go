@@ -0,0 +1,75 @@
+package cache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// cacheMetrics holds metrics related to the query result cache.
+type cacheMetrics struct {
+	requests  *prometheus.CounterVec
+	evictions *prometheus.CounterVec
+
+	entries     prometheus.Gauge
+	memoryBytes prometheus.Gauge
+}
+
+type requestsLabel string
+
+const (
+	labelHit         = requestsLabel("hit")
+	labelMiss        = requestsLabel("miss")
+	labelUncacheable = requestsLabel("uncacheable")
+)
+
+type evictionsLabel string
+
+const (
+	labelMemory  = evictionsLabel("memory")
+	labelExpired = evictionsLabel("expired")
+	labelWrite   = evictionsLabel("write")
+)
+
+func newCacheMetrics() *cacheMetrics {
+	const (
+		namespace = "query"
+		subsystem = "cache"
+	)
+
+	return &cacheMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "requests_total",
+			Help:      "Count of the query requests, split out by whether the results were cached",
+		}, []string{"result"}),
+
+		evictions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "evictions_total",
+			Help:      "Count of the cached results removed, split out by reason: memory bound, expiry or a write into the cached range",
+		}, []string{"reason"}),
+
+		entries: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "entries",
+			Help:      "Number of cached query results",
+		}),
+
+		memoryBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "memory_bytes",
+			Help:      "Number of bytes of table memory held by cached query results",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *cacheMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.requests,
+		m.evictions,
+		m.entries,
+		m.memoryBytes,
+	}
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// PointsWriter invalidates the cached results that read the time range of
+// the points written to the underlying PointsWriter.
+type PointsWriter struct {
+	Underlying storage.PointsWriter
+	Cache      *Cache
+}
+
+// WritePoints writes points to the underlying PointsWriter and then removes
+// the cached results the points change. The results are removed even if the
+// write fails, as part of the points may have been written.
+func (w *PointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	err := w.Underlying.WritePoints(ctx, points)
+
+	type bounds struct{ min, max int64 }
+	buckets := make(map[influxdb.ID]bounds)
+	for _, p := range points {
+		// The name of a point is its encoded organization and bucket ID.
+		name := p.Name()
+		if len(name) != 16 {
+			continue
+		}
+		_, bucketID := tsdb.DecodeNameSlice(name)
+
+		t := p.UnixNano()
+		b, ok := buckets[bucketID]
+		if !ok {
+			b = bounds{min: t, max: t}
+		} else if t < b.min {
+			b.min = t
+		} else if t > b.max {
+			b.max = t
+		}
+		buckets[bucketID] = b
+	}
+
+	for bucketID, b := range buckets {
+		w.Cache.Invalidate(bucketID, b.min, b.max)
+	}
+	return err
+}
@@ -0,0 +1,284 @@
+package cache
+
+import (
+	"sync"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/influxdb"
+)
+
+// result is a flux.Result of tables that are already materialised.
+type result struct {
+	name   string
+	tables []flux.Table
+}
+
+func (r *result) Name() string { return r.name }
+
+func (r *result) Tables() flux.TableIterator { return r }
+
+func (r *result) Do(f func(flux.Table) error) error {
+	for i, t := range r.tables {
+		if err := f(t); err != nil {
+			for _, t := range r.tables[i+1:] {
+				t.Done()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// hitQuery replays cached results.
+type hitQuery struct {
+	results chan flux.Result
+	once    sync.Once
+}
+
+func newHitQuery(results []flux.Result) *hitQuery {
+	q := &hitQuery{results: make(chan flux.Result, len(results))}
+	for _, r := range results {
+		q.results <- r
+	}
+	close(q.results)
+	return q
+}
+
+func (q *hitQuery) Results() <-chan flux.Result { return q.results }
+
+func (q *hitQuery) Done() {
+	q.once.Do(func() {
+		// Release the tables of the results that were not read.
+		for r := range q.results {
+			for _, t := range r.(*result).tables {
+				t.Done()
+			}
+		}
+	})
+}
+
+func (q *hitQuery) Cancel() {}
+
+func (q *hitQuery) Err() error { return nil }
+
+func (q *hitQuery) Statistics() flux.Statistics {
+	return flux.Statistics{
+		Metadata: flux.Metadata{StatisticsKey: []interface{}{string(labelHit)}},
+	}
+}
+
+// missQuery passes on the results of a query and caches them once the query
+// completes without error.
+type missQuery struct {
+	flux.Query
+	c *Cache
+
+	results chan flux.Result
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+
+	// The fields below are protected by mu.
+	mu          sync.Mutex
+	entry       *entry
+	generations []uint64
+	pending     int  // results not yet fully read
+	cacheable   bool // false once the results cannot be cached
+	complete    bool // true once all results have been received
+}
+
+func newMissQuery(q flux.Query, c *Cache, key string, buckets []influxdb.ID, generations []uint64) *missQuery {
+	mq := &missQuery{
+		Query:       q,
+		c:           c,
+		results:     make(chan flux.Result),
+		done:        make(chan struct{}),
+		entry:       &entry{key: key, buckets: buckets, start: unbounded[1], stop: unbounded[0]},
+		generations: generations,
+		cacheable:   true,
+	}
+	mq.wg.Add(1)
+	go mq.pump()
+	return mq
+}
+
+func (q *missQuery) pump() {
+	defer q.wg.Done()
+	defer close(q.results)
+
+	for r := range q.Query.Results() {
+		cr := &cachedResult{name: r.Name()}
+		q.mu.Lock()
+		q.entry.results = append(q.entry.results, cr)
+		q.pending++
+		q.mu.Unlock()
+
+		select {
+		case q.results <- &recordingResult{Result: r, q: q, cr: cr}:
+		case <-q.done:
+			q.setUncacheable()
+			return
+		}
+	}
+
+	q.mu.Lock()
+	q.complete = true
+	q.mu.Unlock()
+}
+
+func (q *missQuery) Results() <-chan flux.Result { return q.results }
+
+func (q *missQuery) Done() {
+	q.once.Do(func() {
+		close(q.done)
+		q.Query.Done()
+		q.wg.Wait()
+
+		q.mu.Lock()
+		cacheable := q.cacheable && q.complete && q.pending == 0 &&
+			q.Query.Err() == nil && len(q.Query.Statistics().RuntimeErrors) == 0
+		e := q.entry
+		q.mu.Unlock()
+
+		if !cacheable {
+			releaseResults(e.results)
+			return
+		}
+		if e.start > e.stop {
+			// No tables reported the time range they read.
+			e.start, e.stop = unbounded[0], unbounded[1]
+		}
+		q.c.put(e, q.generations)
+	})
+}
+
+func (q *missQuery) Statistics() flux.Statistics {
+	stats := q.Query.Statistics()
+	md := make(flux.Metadata, len(stats.Metadata)+1)
+	for k, v := range stats.Metadata {
+		md[k] = v
+	}
+	md[StatisticsKey] = []interface{}{string(labelMiss)}
+	stats.Metadata = md
+	return stats
+}
+
+func (q *missQuery) setUncacheable() {
+	q.mu.Lock()
+	q.cacheable = false
+	q.mu.Unlock()
+}
+
+// record keeps a copy of t for the cache, unless the results have become
+// too large to cache. It returns the table to pass on in place of t.
+func (q *missQuery) record(cr *cachedResult, t flux.Table) (flux.Table, error) {
+	q.mu.Lock()
+	cacheable := q.cacheable
+	q.mu.Unlock()
+	if !cacheable {
+		return t, nil
+	}
+
+	bt, err := execute.CopyTable(t)
+	if err != nil {
+		return nil, err
+	}
+	size := tableSize(bt)
+	start, stop, bounded := tableRange(bt.Key())
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.cacheable {
+		return bt, nil
+	}
+	if q.entry.size+size > q.c.maxEntryBytes {
+		q.cacheable = false
+		return bt, nil
+	}
+	q.entry.size += size
+	if !bounded {
+		start, stop = unbounded[0], unbounded[1]
+	}
+	if start < q.entry.start {
+		q.entry.start = start
+	}
+	if stop > q.entry.stop {
+		q.entry.stop = stop
+	}
+	cr.tables = append(cr.tables, bt.Copy())
+	return bt, nil
+}
+
+// recordingResult records the tables of a result as they are read.
+type recordingResult struct {
+	flux.Result
+	q  *missQuery
+	cr *cachedResult
+}
+
+func (r *recordingResult) Tables() flux.TableIterator { return r }
+
+func (r *recordingResult) Do(f func(flux.Table) error) error {
+	err := r.Result.Tables().Do(func(t flux.Table) error {
+		t, err := r.q.record(r.cr, t)
+		if err != nil {
+			return err
+		}
+		return f(t)
+	})
+
+	r.q.mu.Lock()
+	r.q.pending--
+	if err != nil {
+		r.q.cacheable = false
+	}
+	r.q.mu.Unlock()
+	return err
+}
+
+// tableRange returns the bounds of the time range a table was read from,
+// from the _start and _stop columns of its group key.
+func tableRange(key flux.GroupKey) (start, stop int64, ok bool) {
+	i, j := execute.ColIdx(execute.DefaultStartColLabel, key.Cols()), execute.ColIdx(execute.DefaultStopColLabel, key.Cols())
+	if i < 0 || j < 0 || key.Cols()[i].Type != flux.TTime || key.Cols()[j].Type != flux.TTime {
+		return 0, 0, false
+	}
+	return int64(key.ValueTime(i)), int64(key.ValueTime(j)), true
+}
+
+// tableSize returns the number of bytes of memory used by the columns of t.
+func tableSize(t flux.BufferedTable) int64 {
+	var size int64
+	cp := t.Copy()
+	_ = cp.Do(func(cr flux.ColReader) error {
+		for j, c := range cr.Cols() {
+			var arr array.Interface
+			switch c.Type {
+			case flux.TBool:
+				arr = cr.Bools(j)
+			case flux.TInt:
+				arr = cr.Ints(j)
+			case flux.TUInt:
+				arr = cr.UInts(j)
+			case flux.TFloat:
+				arr = cr.Floats(j)
+			case flux.TString:
+				arr = cr.Strings(j)
+			case flux.TTime:
+				arr = cr.Times(j)
+			}
+			if arr == nil {
+				continue
+			}
+			for _, buf := range arr.Data().Buffers() {
+				if buf != nil {
+					size += int64(buf.Len())
+				}
+			}
+		}
+		return nil
+	})
+	return size
+}
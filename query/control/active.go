@@ -0,0 +1,79 @@
+package control
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+	"go.uber.org/zap"
+)
+
+var _ query.ActiveQueryService = (*Controller)(nil)
+
+// FindActiveQueryByID returns the query with the given ID if it has not
+// finished yet.
+func (c *Controller) FindActiveQueryByID(ctx context.Context, id influxdb.ID) (*query.ActiveQuery, error) {
+	c.queriesMu.RLock()
+	q, ok := c.queries[QueryID(id)]
+	c.queriesMu.RUnlock()
+	if !ok {
+		return nil, query.ErrActiveQueryNotFound
+	}
+	return q.describe(time.Now()), nil
+}
+
+// FindActiveQueries returns the queries that have not finished yet, ordered
+// by ID.
+func (c *Controller) FindActiveQueries(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+	now := time.Now()
+	queries := c.Queries()
+	aqs := make([]*query.ActiveQuery, 0, len(queries))
+	for _, q := range queries {
+		if filter.OrganizationID != nil && q.orgID != *filter.OrganizationID {
+			continue
+		}
+		aqs = append(aqs, q.describe(now))
+	}
+	sort.Slice(aqs, func(i, j int) bool {
+		return aqs[i].ID < aqs[j].ID
+	})
+	return aqs, nil
+}
+
+// CancelActiveQuery cancels the query with the given ID. The query is
+// finished once its submitter calls Done.
+func (c *Controller) CancelActiveQuery(ctx context.Context, id influxdb.ID) error {
+	c.queriesMu.RLock()
+	q, ok := c.queries[QueryID(id)]
+	c.queriesMu.RUnlock()
+	if !ok {
+		return query.ErrActiveQueryNotFound
+	}
+	c.logger.Info("Canceling query on request", zap.Stringer("query_id", id), zap.Stringer("org_id", q.orgID))
+	q.Cancel()
+	return nil
+}
+
+// describe returns the description of q at now.
+func (q *Query) describe(now time.Time) *query.ActiveQuery {
+	q.stateMu.RLock()
+	alloc := q.alloc
+	q.stateMu.RUnlock()
+
+	aq := &query.ActiveQuery{
+		ID:             influxdb.ID(q.id),
+		OrganizationID: q.orgID,
+		UserID:         q.userID,
+		Query:          q.text,
+		CompilerType:   string(q.compilerType),
+		State:          q.State().String(),
+		StartedAt:      q.createdAt,
+		Elapsed:        now.Sub(q.createdAt),
+	}
+	if alloc != nil {
+		aq.MemoryBytes = alloc.Allocated()
+	}
+	return aq
+}
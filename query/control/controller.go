@@ -34,29 +34,44 @@ import (
 	"github.com/influxdata/influxdb/kit/errors"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/explain"
 	"github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-// orgLabel is the metric label to use in the controller
-const orgLabel = "org"
+const (
+	// orgLabel is the metric label to use in the controller
+	orgLabel = "org"
+	// priorityLabel is the metric label of the priority class of a query.
+	priorityLabel = "priority"
+)
 
 // Controller provides a central location to manage all incoming queries.
 // The controller is responsible for compiling, queueing, and executing queries.
 type Controller struct {
-	lastID     uint64
-	queriesMu  sync.RWMutex
-	queries    map[QueryID]*Query
-	queryQueue chan *Query
-	wg         sync.WaitGroup
-	shutdown   bool
-	done       chan struct{}
-	abortOnce  sync.Once
-	abort      chan struct{}
-
-	memoryBytesQuotaPerQuery int64
+	lastID    uint64
+	queriesMu sync.RWMutex
+	queries   map[QueryID]*Query
+	scheduler *scheduler
+	wg        sync.WaitGroup
+	shutdown  bool
+	done      chan struct{}
+	abortOnce sync.Once
+	abort     chan struct{}
+
+	// orgLimits are the limits of the queries of organizations that do
+	// not override them.
+	orgLimits influxdb.QueryLimits
+	orgSvc    influxdb.OrganizationService
+
+	// limitsCache holds the query limits of organizations, which are looked
+	// up again once they are older than orgLimitsTTL.
+	limitsMu    sync.Mutex
+	limitsCache map[influxdb.ID]cachedQueryLimits
+
+	queryLogger query.Logger
 
 	metrics   *controllerMetrics
 	labelKeys []string
@@ -78,7 +93,29 @@ type Config struct {
 	// QueueSize is the number of queries that are allowed to be awaiting execution before new queries are
 	// rejected.
 	QueueSize int
-	Logger    *zap.Logger
+
+	// OrgConcurrencyQuota is the number of queries of a single organization that are allowed to execute
+	// concurrently. Zero is no limit other than ConcurrencyQuota.
+	OrgConcurrencyQuota int
+	// OrgQueueSize is the number of queries of a single organization that are allowed to be awaiting
+	// execution before new queries of the organization are rejected. Zero is no limit other than QueueSize.
+	OrgQueueSize int
+	// OrgMemoryBytesQuota is the maximum number of bytes (in table memory) the executing queries of a single
+	// organization are allowed to use together. Each executing query reserves MemoryBytesQuotaPerQuery of
+	// it, and queries wait in the queue until their reservation fits. Zero is no limit per organization.
+	OrgMemoryBytesQuota int64
+	// PriorityConcurrencyQuotas is the number of queries of each priority class that are allowed to execute
+	// concurrently. A priority class without a quota is only limited by ConcurrencyQuota.
+	// Quotas for the lower priority classes ensure executors are left for interactive queries.
+	PriorityConcurrencyQuotas map[query.Priority]int
+	// OrganizationService, if set, is used to look up the query limits organizations override the
+	// ones above with.
+	OrganizationService influxdb.OrganizationService
+
+	// QueryLogger, if set, is given a log of every finished query.
+	QueryLogger query.Logger
+
+	Logger *zap.Logger
 	// MetricLabelKeys is a list of labels to add to the metrics produced by the controller.
 	// The value for a given key will be read off the context.
 	// The context value must be a string or an implementation of the Stringer interface.
@@ -97,6 +134,23 @@ func (c *Config) Validate() error {
 	if c.QueueSize <= 0 {
 		return errors.New("QueueSize must be positive")
 	}
+	if c.OrgConcurrencyQuota < 0 {
+		return errors.New("OrgConcurrencyQuota must not be negative")
+	}
+	if c.OrgQueueSize < 0 {
+		return errors.New("OrgQueueSize must not be negative")
+	}
+	if c.OrgMemoryBytesQuota < 0 {
+		return errors.New("OrgMemoryBytesQuota must not be negative")
+	}
+	for p, quota := range c.PriorityConcurrencyQuotas {
+		if !p.Valid() {
+			return fmt.Errorf("unknown priority %v in PriorityConcurrencyQuotas", p)
+		}
+		if quota < 0 {
+			return fmt.Errorf("concurrency quota of %v priority must not be negative", p)
+		}
+	}
 	return nil
 }
 
@@ -106,7 +160,7 @@ func New(c Config) (*Controller, error) {
 	if err := c.Validate(); err != nil {
 		return nil, errors.Wrap(err, "invalid controller config")
 	}
-	c.MetricLabelKeys = append(c.MetricLabelKeys, orgLabel)
+	c.MetricLabelKeys = append(c.MetricLabelKeys, orgLabel, priorityLabel)
 	logger := c.Logger
 	if logger == nil {
 		logger = zap.NewNop()
@@ -114,17 +168,28 @@ func New(c Config) (*Controller, error) {
 	logger.Info("Starting query controller",
 		zap.Int("concurrency_quota", c.ConcurrencyQuota),
 		zap.Int64("memory_bytes_quota_per_query", c.MemoryBytesQuotaPerQuery),
-		zap.Int("queue_size", c.QueueSize))
+		zap.Int("queue_size", c.QueueSize),
+		zap.Int("org_concurrency_quota", c.OrgConcurrencyQuota),
+		zap.Int("org_queue_size", c.OrgQueueSize),
+		zap.Int64("org_memory_bytes_quota", c.OrgMemoryBytesQuota))
 	ctrl := &Controller{
-		queries:                  make(map[QueryID]*Query),
-		queryQueue:               make(chan *Query, c.QueueSize),
-		done:                     make(chan struct{}),
-		abort:                    make(chan struct{}),
-		memoryBytesQuotaPerQuery: c.MemoryBytesQuotaPerQuery,
-		logger:                   logger,
-		metrics:                  newControllerMetrics(c.MetricLabelKeys),
-		labelKeys:                c.MetricLabelKeys,
-		dependencies:             c.ExecutorDependencies,
+		queries:   make(map[QueryID]*Query),
+		scheduler: newScheduler(c.QueueSize, c.PriorityConcurrencyQuotas),
+		done:      make(chan struct{}),
+		abort:     make(chan struct{}),
+		orgLimits: influxdb.QueryLimits{
+			ConcurrencyQuota:         c.OrgConcurrencyQuota,
+			MemoryBytesQuotaPerQuery: c.MemoryBytesQuotaPerQuery,
+			MemoryBytesQuota:         c.OrgMemoryBytesQuota,
+			QueueSize:                c.OrgQueueSize,
+		},
+		orgSvc:       c.OrganizationService,
+		limitsCache:  make(map[influxdb.ID]cachedQueryLimits),
+		queryLogger:  c.QueryLogger,
+		logger:       logger,
+		metrics:      newControllerMetrics(c.MetricLabelKeys),
+		labelKeys:    c.MetricLabelKeys,
+		dependencies: c.ExecutorDependencies,
 	}
 	ctrl.wg.Add(c.ConcurrencyQuota)
 	for i := 0; i < c.ConcurrencyQuota; i++ {
@@ -141,10 +206,18 @@ func (c *Controller) Query(ctx context.Context, req *query.Request) (flux.Query,
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	if !req.Priority.Valid() {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unknown query priority %v", req.Priority),
+		}
+	}
+
 	// Set the request on the context so platform specific Flux operations can retrieve it later.
 	ctx = query.ContextWithRequest(ctx, req)
-	// Set the org label value for controller metrics
+	// Set the org and priority label values for controller metrics
 	ctx = context.WithValue(ctx, orgLabel, req.OrganizationID.String())
+	ctx = context.WithValue(ctx, priorityLabel, req.Priority.String())
 	q, err := c.query(ctx, req.Compiler)
 	if err != nil {
 		return q, err
@@ -210,6 +283,8 @@ func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Qu
 	)
 	q := &Query{
 		id:                 id,
+		compilerType:       ct,
+		createdAt:          time.Now(),
 		labelValues:        labelValues,
 		compileLabelValues: compileLabelValues,
 		state:              Created,
@@ -219,6 +294,17 @@ func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Qu
 		parentSpan:         parentSpan,
 		cancel:             cancel,
 		doneCh:             make(chan struct{}),
+		limits:             clampLimits(c.orgLimits),
+	}
+	if req := query.RequestFromContext(ctx); req != nil {
+		q.request = req
+		q.orgID = req.OrganizationID
+		if req.Authorization != nil {
+			q.userID = req.Authorization.UserID
+		}
+		q.text = req.Text()
+		q.priority = req.Priority
+		q.limits = c.limitsOf(ctx, req.OrganizationID)
 	}
 
 	// Lock the queries mutex for the rest of this method.
@@ -239,6 +325,74 @@ func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Qu
 	return q, nil
 }
 
+// orgLimitsTTL is how long the query limits of an organization are cached
+// before they are looked up again, so changes take effect within it.
+const orgLimitsTTL = 10 * time.Second
+
+type cachedQueryLimits struct {
+	limits  influxdb.QueryLimits
+	expires time.Time
+}
+
+// limitsOf returns the query limits of the organization with the given ID.
+// The limits the organization does not override are those of the controller.
+func (c *Controller) limitsOf(ctx context.Context, orgID influxdb.ID) influxdb.QueryLimits {
+	if c.orgSvc == nil || !orgID.Valid() {
+		return clampLimits(c.orgLimits)
+	}
+
+	now := time.Now()
+	c.limitsMu.Lock()
+	cached, ok := c.limitsCache[orgID]
+	c.limitsMu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.limits
+	}
+
+	limits := c.orgLimits
+	org, err := c.orgSvc.FindOrganizationByID(ctx, orgID)
+	if err != nil {
+		c.logger.Info("Failed to look up query limits of organization; using the default limits",
+			zap.Stringer("org_id", orgID), zap.Error(err))
+		return clampLimits(limits)
+	}
+	if o := org.QueryLimits; o != nil {
+		if o.ConcurrencyQuota > 0 {
+			limits.ConcurrencyQuota = o.ConcurrencyQuota
+		}
+		if o.MemoryBytesQuotaPerQuery > 0 {
+			limits.MemoryBytesQuotaPerQuery = o.MemoryBytesQuotaPerQuery
+		}
+		if o.MemoryBytesQuota > 0 {
+			limits.MemoryBytesQuota = o.MemoryBytesQuota
+		}
+		if o.QueueSize > 0 {
+			limits.QueueSize = o.QueueSize
+		}
+	}
+	limits = clampLimits(limits)
+
+	c.limitsMu.Lock()
+	// Drop the expired limits of other organizations so the cache stays small.
+	for id, l := range c.limitsCache {
+		if !now.Before(l.expires) {
+			delete(c.limitsCache, id)
+		}
+	}
+	c.limitsCache[orgID] = cachedQueryLimits{limits: limits, expires: now.Add(orgLimitsTTL)}
+	c.limitsMu.Unlock()
+	return limits
+}
+
+// clampLimits lowers the memory quota per query to the memory quota of the
+// organization, so a single query can always execute within the latter.
+func clampLimits(limits influxdb.QueryLimits) influxdb.QueryLimits {
+	if limits.MemoryBytesQuota > 0 && limits.MemoryBytesQuotaPerQuery > limits.MemoryBytesQuota {
+		limits.MemoryBytesQuotaPerQuery = limits.MemoryBytesQuota
+	}
+	return limits
+}
+
 func (c *Controller) nextID() QueryID {
 	nextID := atomic.AddUint64(&c.lastID, 1)
 	return QueryID(nextID)
@@ -252,6 +406,30 @@ func (c *Controller) countQueryRequest(q *Query, result requestsLabel) {
 	c.metrics.requests.WithLabelValues(lvs...).Inc()
 }
 
+// logQuery gives the log of the finished query q to the query logger.
+func (c *Controller) logQuery(q *Query) {
+	if c.queryLogger == nil || q.request == nil {
+		return
+	}
+
+	err := q.err
+	if err == nil && len(q.runtimeErrs) > 0 {
+		err = q.runtimeErrs[0]
+	}
+	log := query.Log{
+		Time:           time.Now(),
+		OrganizationID: q.orgID,
+		Error:          handleFluxError(err),
+		ProxyRequest:   &query.ProxyRequest{Request: *q.request},
+		RowCount:       atomic.LoadInt64(&q.rows),
+		Statistics:     q.Statistics(),
+	}
+	log.Redact()
+	if err := c.queryLogger.Log(log); err != nil {
+		c.logger.Info("Failed to log query", zap.Stringer("org_id", q.orgID), zap.Error(err))
+	}
+}
+
 func (c *Controller) compileQuery(q *Query, compiler flux.Compiler) (err error) {
 	defer func() {
 		if e := recover(); e != nil {
@@ -275,7 +453,12 @@ func (c *Controller) compileQuery(q *Query, compiler flux.Compiler) (err error)
 		}
 	}
 
-	prog, err := compiler.Compile(ctx)
+	var prog flux.Program
+	if q.request != nil && q.request.Profile {
+		prog, err = explain.CompileProfile(ctx, compiler)
+	} else {
+		prog, err = compiler.Compile(ctx)
+	}
 	if err != nil {
 		return &flux.Error{
 			Msg: "compilation failed",
@@ -300,26 +483,17 @@ func (c *Controller) enqueueQuery(q *Query) error {
 		}
 	}
 
-	select {
-	case c.queryQueue <- q:
-	default:
-		return &flux.Error{
-			Code: codes.ResourceExhausted,
-			Msg:  "queue length exceeded",
-		}
-	}
-
-	return nil
+	return c.scheduler.enqueue(q)
 }
 
 func (c *Controller) processQueryQueue() {
 	for {
-		select {
-		case <-c.done:
+		q, ok := c.scheduler.next()
+		if !ok {
 			return
-		case q := <-c.queryQueue:
-			c.executeQuery(q)
 		}
+		c.executeQuery(q)
+		c.scheduler.release(q)
 	}
 }
 
@@ -352,9 +526,13 @@ func (c *Controller) executeQuery(q *Query) {
 		return
 	}
 
-	q.alloc = new(memory.Allocator)
-	q.alloc.Limit = func(v int64) *int64 { return &v }(c.memoryBytesQuotaPerQuery)
-	exec, err := q.program.Start(ctx, q.alloc)
+	alloc := new(memory.Allocator)
+	alloc.Limit = func(v int64) *int64 { return &v }(q.limits.MemoryBytesQuotaPerQuery)
+	// The allocator is read by the listing of active queries.
+	q.stateMu.Lock()
+	q.alloc = alloc
+	q.stateMu.Unlock()
+	exec, err := q.program.Start(ctx, alloc)
 	if err != nil {
 		q.setErr(err)
 		return
@@ -368,6 +546,7 @@ func (c *Controller) finish(q *Query) {
 	delete(c.queries, q.id)
 	if len(c.queries) == 0 && c.shutdown {
 		close(c.done)
+		c.scheduler.close()
 	}
 	c.queriesMu.Unlock()
 }
@@ -394,6 +573,7 @@ func (c *Controller) Shutdown(ctx context.Context) error {
 	c.shutdown = true
 	if len(c.queries) == 0 {
 		c.queriesMu.Unlock()
+		c.scheduler.close()
 		return nil
 	}
 	c.queriesMu.Unlock()
@@ -430,6 +610,21 @@ func (c *Controller) PrometheusCollectors() []prometheus.Collector {
 type Query struct {
 	id QueryID
 
+	// The fields below describe the query to operators.
+	orgID        influxdb.ID
+	userID       influxdb.ID
+	text         string
+	compilerType flux.CompilerType
+	createdAt    time.Time
+
+	priority query.Priority
+	limits   influxdb.QueryLimits
+	request  *query.Request
+
+	// rows is the number of rows read from the results. It is
+	// accessed atomically.
+	rows int64
+
 	labelValues        []string
 	compileLabelValues []string
 
@@ -465,6 +660,9 @@ func (q *Query) Cancel() {
 	// Call the cancel function to signal that execution should
 	// be interrupted.
 	q.cancel()
+	// A queued query is canceled by the executor that removes it from
+	// the queue, so have the executors look at the queue again.
+	q.c.scheduler.wake()
 }
 
 // Results returns a channel that will deliver the query results.
@@ -496,6 +694,12 @@ func (q *Query) Done() {
 		q.cancel()
 		q.stateMu.Unlock()
 
+		// A query that is still queued will never execute, so nothing
+		// else is going to close its results.
+		if q.c.scheduler.remove(q) {
+			close(q.results)
+		}
+
 		// Ensure that all of the results have been drained.
 		// It is ok to read this as the user has already indicated they don't
 		// care about the results. When this is closed, it tells us an error has
@@ -535,6 +739,7 @@ func (q *Query) Done() {
 		} else {
 			q.c.countQueryRequest(q, labelSuccess)
 		}
+		q.c.logQuery(q)
 	})
 	<-q.doneCh
 }
@@ -800,13 +1005,28 @@ type errorCollectingTableIterator struct {
 }
 
 func (ti *errorCollectingTableIterator) Do(f func(t flux.Table) error) error {
-	err := ti.TableIterator.Do(f)
+	err := ti.TableIterator.Do(func(t flux.Table) error {
+		return f(&rowCountingTable{Table: t, q: ti.q})
+	})
 	if err != nil {
 		ti.q.addRuntimeError(err)
 	}
 	return err
 }
 
+// rowCountingTable counts the rows read from a table of the results.
+type rowCountingTable struct {
+	flux.Table
+	q *Query
+}
+
+func (t *rowCountingTable) Do(f func(flux.ColReader) error) error {
+	return t.Table.Do(func(cr flux.ColReader) error {
+		atomic.AddInt64(&t.q.rows, int64(cr.Len()))
+		return f(cr)
+	})
+}
+
 // State is the query state.
 type State int
 
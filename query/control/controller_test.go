@@ -19,6 +19,7 @@ import (
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/plan/plantest"
 	"github.com/influxdata/flux/stdlib/universe"
+	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/query/control"
 	"github.com/prometheus/client_golang/prometheus"
@@ -69,8 +70,9 @@ func validateRequestTotals(t testing.TB, reg *prometheus.Registry, success, comp
 			metrics,
 			"query_control_requests_total",
 			map[string]string{
-				"result": name,
-				"org":    "",
+				"result":   name,
+				"org":      "",
+				"priority": "interactive",
 			},
 		)
 		var got int
@@ -833,6 +835,545 @@ func TestController_CancelDone(t *testing.T) {
 	wg.Wait()
 }
 
+func TestController_ActiveQueries(t *testing.T) {
+	config := config
+	config.ConcurrencyQuota = 2
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	executing := make(chan struct{}, 2)
+	compiler := &mock.Compiler{
+		CompileFn: func(ctx context.Context) (flux.Program, error) {
+			return &mock.Program{
+				ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+					executing <- struct{}{}
+					<-ctx.Done()
+				},
+			}, nil
+		},
+	}
+
+	orgA, orgB := influxdb.ID(0xa), influxdb.ID(0xb)
+	var queries []flux.Query
+	for _, orgID := range []influxdb.ID{orgA, orgB} {
+		req := makeRequest(compiler)
+		req.OrganizationID = orgID
+		req.Authorization = &influxdb.Authorization{UserID: 0x1}
+		q, err := ctrl.Query(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		queries = append(queries, q)
+		<-executing
+	}
+
+	aqs, err := ctrl.FindActiveQueries(context.Background(), query.ActiveQueryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := len(aqs), 2; got != exp {
+		t.Fatalf("unexpected number of active queries -got/+exp\n%d\n%d", got, exp)
+	}
+	for i, aq := range aqs {
+		if got, exp := aq.ID, influxdb.ID(queries[i].(*control.Query).ID()); got != exp {
+			t.Errorf("unexpected query id -got/+exp\n%v\n%v", got, exp)
+		}
+		if got, exp := aq.State, "executing"; got != exp {
+			t.Errorf("unexpected query state -got/+exp\n%v\n%v", got, exp)
+		}
+		if got, exp := aq.UserID, influxdb.ID(0x1); got != exp {
+			t.Errorf("unexpected user id -got/+exp\n%v\n%v", got, exp)
+		}
+	}
+
+	aqs, err = ctrl.FindActiveQueries(context.Background(), query.ActiveQueryFilter{OrganizationID: &orgB})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(aqs) != 1 || aqs[0].OrganizationID != orgB {
+		t.Fatalf("expected only the query of org b, got %v", aqs)
+	}
+
+	// Cancel the query of org b, which frees its executor.
+	if err := ctrl.CancelActiveQuery(context.Background(), aqs[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	for range queries[1].Results() {
+	}
+	queries[1].Done()
+	if _, err := ctrl.FindActiveQueryByID(context.Background(), aqs[0].ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected canceled query not to be found, got %v", err)
+	}
+	if err := ctrl.CancelActiveQuery(context.Background(), aqs[0].ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected canceling a finished query to fail with not found, got %v", err)
+	}
+
+	queries[0].Cancel()
+	queries[0].Done()
+}
+
+// namedCompiler returns a compiler of programs that send name on started
+// once executing and then block until release is closed or they are canceled.
+func namedCompiler(name string, started chan<- string, release <-chan struct{}) flux.Compiler {
+	return &mock.Compiler{
+		CompileFn: func(ctx context.Context) (flux.Program, error) {
+			return &mock.Program{
+				ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+					started <- name
+					select {
+					case <-release:
+					case <-ctx.Done():
+					}
+				},
+			}, nil
+		},
+	}
+}
+
+// submit submits req to ctrl and releases the query once its results are read.
+func submit(t *testing.T, ctrl *control.Controller, req *query.Request) flux.Query {
+	t.Helper()
+	q, err := ctrl.Query(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for range q.Results() {
+			// discard the results
+		}
+		q.Done()
+	}()
+	return q
+}
+
+func queryState(t *testing.T, ctrl *control.Controller, q flux.Query) string {
+	t.Helper()
+	aq, err := ctrl.FindActiveQueryByID(context.Background(), influxdb.ID(q.(*control.Query).ID()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aq.State
+}
+
+func TestController_Priority(t *testing.T) {
+	config := config
+	config.QueueSize = 10
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	started := make(chan string, 3)
+	release := make(chan struct{})
+	done := make(chan struct{})
+	close(done)
+
+	// Occupy the only executor so the other queries are queued.
+	submit(t, ctrl, makeRequest(namedCompiler("blocking", started, release)))
+	if got := <-started; got != "blocking" {
+		t.Fatalf("unexpected query started: %s", got)
+	}
+
+	req := makeRequest(namedCompiler("task", started, done))
+	req.Priority = query.PriorityTask
+	submit(t, ctrl, req)
+	submit(t, ctrl, makeRequest(namedCompiler("interactive", started, done)))
+
+	close(release)
+	for _, exp := range []string{"interactive", "task"} {
+		if got := <-started; got != exp {
+			t.Errorf("unexpected query started -got/+exp\n%s\n%s", got, exp)
+		}
+	}
+}
+
+func TestController_PriorityConcurrencyQuota(t *testing.T) {
+	config := config
+	config.ConcurrencyQuota = 2
+	config.QueueSize = 10
+	config.PriorityConcurrencyQuotas = map[query.Priority]int{query.PriorityTask: 1}
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	started := make(chan string, 3)
+	release := make(chan struct{})
+	defer close(release)
+
+	var tasks []flux.Query
+	for _, name := range []string{"task 1", "task 2"} {
+		req := makeRequest(namedCompiler(name, started, release))
+		req.Priority = query.PriorityTask
+		tasks = append(tasks, submit(t, ctrl, req))
+	}
+	if got, exp := <-started, "task 1"; got != exp {
+		t.Fatalf("unexpected query started -got/+exp\n%s\n%s", got, exp)
+	}
+
+	// The executor left by the task quota runs interactive queries.
+	submit(t, ctrl, makeRequest(namedCompiler("interactive", started, release)))
+	if got, exp := <-started, "interactive"; got != exp {
+		t.Fatalf("unexpected query started -got/+exp\n%s\n%s", got, exp)
+	}
+	if got, exp := queryState(t, ctrl, tasks[1]), "queueing"; got != exp {
+		t.Errorf("unexpected state of second task query -got/+exp\n%s\n%s", got, exp)
+	}
+}
+
+func TestController_OrgConcurrencyQuota(t *testing.T) {
+	config := config
+	config.ConcurrencyQuota = 2
+	config.QueueSize = 10
+	config.OrgConcurrencyQuota = 1
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	started := make(chan string, 3)
+	release := make(chan struct{})
+	defer close(release)
+
+	orgA, orgB := influxdb.ID(0xa), influxdb.ID(0xb)
+	var queries []flux.Query
+	for _, r := range []struct {
+		name  string
+		orgID influxdb.ID
+	}{
+		{name: "a 1", orgID: orgA},
+		{name: "a 2", orgID: orgA},
+		{name: "b 1", orgID: orgB},
+	} {
+		req := makeRequest(namedCompiler(r.name, started, release))
+		req.OrganizationID = r.orgID
+		queries = append(queries, submit(t, ctrl, req))
+	}
+
+	// The second query of org a waits for the first one while the one of
+	// org b takes the other executor.
+	got := []string{<-started, <-started}
+	if got[0] > got[1] {
+		got[0], got[1] = got[1], got[0]
+	}
+	if exp := []string{"a 1", "b 1"}; got[0] != exp[0] || got[1] != exp[1] {
+		t.Fatalf("unexpected queries started -got/+exp\n%v\n%v", got, exp)
+	}
+	if got, exp := queryState(t, ctrl, queries[1]), "queueing"; got != exp {
+		t.Errorf("unexpected state of second query of org a -got/+exp\n%s\n%s", got, exp)
+	}
+}
+
+func TestController_OrgQueueSize(t *testing.T) {
+	config := config
+	config.QueueSize = 10
+	config.OrgQueueSize = 1
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	reg := setupPromRegistry(ctrl)
+
+	started := make(chan string, 4)
+	release := make(chan struct{})
+	defer close(release)
+
+	orgA, orgB := influxdb.ID(0xa), influxdb.ID(0xb)
+	newRequest := func(orgID influxdb.ID) *query.Request {
+		req := makeRequest(namedCompiler(orgID.String(), started, release))
+		req.OrganizationID = orgID
+		return req
+	}
+
+	// One query of org a executes and the other one fills its queue.
+	submit(t, ctrl, newRequest(orgA))
+	<-started
+	submit(t, ctrl, newRequest(orgA))
+
+	if _, err := ctrl.Query(context.Background(), newRequest(orgA)); err == nil {
+		t.Fatal("expected an error about the queue length of the organization")
+	} else if got, exp := influxdb.ErrorMessage(err), "organization queue length exceeded"; got != exp {
+		t.Errorf("unexpected error -got/+exp\n%s\n%s", got, exp)
+	}
+
+	// Other organizations can still queue queries.
+	submit(t, ctrl, newRequest(orgB))
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := FindMetric(mfs, "query_control_requests_total", map[string]string{
+		"org":      orgA.String(),
+		"priority": "interactive",
+		"result":   "queue_error",
+	})
+	if m == nil || m.Counter.GetValue() != 1 {
+		t.Errorf("expected one queue error of org a, got %v", m)
+	}
+}
+
+// limitsOrganizationService finds organizations with the same query limits.
+type limitsOrganizationService struct {
+	influxdb.OrganizationService
+	limits influxdb.QueryLimits
+}
+
+func (s *limitsOrganizationService) FindOrganizationByID(ctx context.Context, id influxdb.ID) (*influxdb.Organization, error) {
+	limits := s.limits
+	return &influxdb.Organization{ID: id, QueryLimits: &limits}, nil
+}
+
+func TestController_OrgQueryLimits(t *testing.T) {
+	config := config
+	config.OrganizationService = &limitsOrganizationService{
+		limits: influxdb.QueryLimits{MemoryBytesQuotaPerQuery: 10},
+	}
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	compiler := &mock.Compiler{
+		CompileFn: func(ctx context.Context) (flux.Program, error) {
+			return &mock.Program{
+				ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+					// This is within the limit of the controller, but not of the organization.
+					if err := alloc.Allocate(100); err != nil {
+						q.SetErr(err)
+					}
+				},
+			}, nil
+		},
+	}
+
+	req := makeRequest(compiler)
+	req.OrganizationID = influxdb.ID(0xa)
+	q, err := ctrl.Query(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range q.Results() {
+		// discard the results
+	}
+	q.Done()
+
+	if q.Err() == nil {
+		t.Fatal("expected error about memory limit exceeded")
+	}
+}
+
+func TestController_OrgMemoryBytesQuota(t *testing.T) {
+	config := config
+	config.ConcurrencyQuota = 3
+	config.QueueSize = 10
+	config.OrgMemoryBytesQuota = 2 * config.MemoryBytesQuotaPerQuery
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	started := make(chan string, 4)
+	release := make(chan struct{})
+	defer close(release)
+
+	orgA, orgB := influxdb.ID(0xa), influxdb.ID(0xb)
+	var queries []flux.Query
+	for _, r := range []struct {
+		name  string
+		orgID influxdb.ID
+	}{
+		{name: "a 1", orgID: orgA},
+		{name: "a 2", orgID: orgA},
+		{name: "a 3", orgID: orgA},
+		{name: "b 1", orgID: orgB},
+	} {
+		req := makeRequest(namedCompiler(r.name, started, release))
+		req.OrganizationID = r.orgID
+		queries = append(queries, submit(t, ctrl, req))
+	}
+
+	// The memory quota of org a fits two of its queries, so the third one
+	// waits while the one of org b takes the last executor.
+	got := map[string]bool{<-started: true, <-started: true, <-started: true}
+	if !got["a 1"] || !got["a 2"] || !got["b 1"] {
+		t.Fatalf("unexpected queries started %v", got)
+	}
+	if got, exp := queryState(t, ctrl, queries[2]), "queueing"; got != exp {
+		t.Errorf("unexpected state of third query of org a -got/+exp\n%s\n%s", got, exp)
+	}
+}
+
+// countingOrganizationService counts the organizations it finds.
+type countingOrganizationService struct {
+	influxdb.OrganizationService
+	mu    sync.Mutex
+	count int
+}
+
+func (s *countingOrganizationService) FindOrganizationByID(ctx context.Context, id influxdb.ID) (*influxdb.Organization, error) {
+	s.mu.Lock()
+	s.count++
+	s.mu.Unlock()
+	return &influxdb.Organization{ID: id}, nil
+}
+
+func TestController_OrgQueryLimitsCached(t *testing.T) {
+	orgs := &countingOrganizationService{}
+	config := config
+	config.OrganizationService = orgs
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	for i := 0; i < 3; i++ {
+		req := makeRequest(mockCompiler)
+		req.OrganizationID = influxdb.ID(0xa)
+		q, err := ctrl.Query(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for range q.Results() {
+			// discard the results
+		}
+		q.Done()
+	}
+
+	orgs.mu.Lock()
+	defer orgs.mu.Unlock()
+	if orgs.count != 1 {
+		t.Errorf("expected the limits of the organization to be looked up once, got %d lookups", orgs.count)
+	}
+}
+
+// queryLoggerFunc is a query.Logger that calls itself.
+type queryLoggerFunc func(query.Log) error
+
+func (fn queryLoggerFunc) Log(l query.Log) error {
+	return fn(l)
+}
+
+func TestController_QueryLogger(t *testing.T) {
+	var logs []query.Log
+	config := config
+	config.QueryLogger = queryLoggerFunc(func(l query.Log) error {
+		logs = append(logs, l)
+		return nil
+	})
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	compiler := &mock.Compiler{
+		CompileFn: func(ctx context.Context) (flux.Program, error) {
+			return &mock.Program{
+				ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+					q.ResultsCh <- &executetest.Result{
+						Nm: "_result",
+						Tbls: []*executetest.Table{{
+							ColMeta: []flux.ColMeta{{Label: "_value", Type: flux.TInt}},
+							Data:    [][]interface{}{{int64(1)}, {int64(2)}},
+						}},
+					}
+				},
+			}, nil
+		},
+	}
+
+	req := makeRequest(compiler)
+	req.OrganizationID = influxdb.ID(0xa)
+	req.Authorization = &influxdb.Authorization{UserID: 0x1, Token: "secret"}
+	q, err := ctrl.Query(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for res := range q.Results() {
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(flux.ColReader) error { return nil })
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q.Done()
+
+	if len(logs) != 1 {
+		t.Fatalf("expected one query log, got %d", len(logs))
+	}
+	l := logs[0]
+	if l.OrganizationID != req.OrganizationID {
+		t.Errorf("unexpected org of log -got/+exp\n%v\n%v", l.OrganizationID, req.OrganizationID)
+	}
+	if l.Error != nil {
+		t.Errorf("unexpected error in log: %v", l.Error)
+	}
+	if got, exp := l.RowCount, int64(2); got != exp {
+		t.Errorf("unexpected row count -got/+exp\n%d\n%d", got, exp)
+	}
+	if l.Statistics.TotalDuration == 0 {
+		t.Error("expected total duration to be above zero")
+	}
+	if l.ProxyRequest == nil || l.ProxyRequest.Request.Authorization.Token != "" {
+		t.Errorf("expected request of log with redacted token, got %+v", l.ProxyRequest)
+	}
+}
+
+func TestController_Profile(t *testing.T) {
+	config := config
+	config.MemoryBytesQuotaPerQuery = 1 << 20
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	req := makeRequest(lang.FluxCompiler{
+		Query: `import "csv"
+csv.from(csv: "#datatype,string,long,long
+#group,false,false,false
+#default,_result,,
+,result,table,_value
+,,0,1
+")`,
+	})
+	req.Profile = true
+	q, err := ctrl.Query(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for res := range q.Results() {
+		names = append(names, res.Name())
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(flux.ColReader) error { return nil })
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q.Done()
+	if err := q.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := []string{"_result", "_profile"}; len(names) != len(exp) || names[0] != exp[0] || names[1] != exp[1] {
+		t.Errorf("unexpected results -got/+exp\n%v\n%v", names, exp)
+	}
+}
+
 // Test that rapidly starts and calls done on queries without reading the result.
 func TestController_DoneWithoutRead(t *testing.T) {
 	config := config
@@ -0,0 +1,194 @@
+package control
+
+import (
+	"sync"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+// scheduler queues the queries that are awaiting execution and hands them to
+// the executors of the controller.
+//
+// Queries of a higher priority class are always handed out before those of a
+// lower one. A query is only handed out while the number of executing queries
+// of its organization and of its priority class are below their quotas, so a
+// busy organization or priority class cannot take up every executor. If its
+// organization has a memory quota, a query is also only handed out while its
+// memory quota per query fits in what the executing queries of the
+// organization have not reserved yet.
+type scheduler struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	closed bool
+
+	// queues holds the queued queries of each priority class in FIFO order.
+	queues    [][]*Query
+	queued    int
+	queueSize int
+	orgQueued map[influxdb.ID]int
+
+	// priorityQuotas is the concurrency quota of each priority class; zero
+	// is no quota other than the one of the controller.
+	priorityQuotas    []int
+	priorityExecuting []int
+	orgExecuting      map[influxdb.ID]int
+	// orgMemory is the memory reserved by the executing queries of each
+	// organization with a memory quota.
+	orgMemory map[influxdb.ID]int64
+}
+
+func newScheduler(queueSize int, priorityQuotas map[query.Priority]int) *scheduler {
+	s := &scheduler{
+		queues:            make([][]*Query, len(query.Priorities)),
+		queueSize:         queueSize,
+		orgQueued:         make(map[influxdb.ID]int),
+		priorityQuotas:    make([]int, len(query.Priorities)),
+		priorityExecuting: make([]int, len(query.Priorities)),
+		orgExecuting:      make(map[influxdb.ID]int),
+		orgMemory:         make(map[influxdb.ID]int64),
+	}
+	for p, quota := range priorityQuotas {
+		s.priorityQuotas[p] = quota
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// enqueue adds q to the queue of its priority class. It fails if either the
+// queue of the controller or the one of the organization of q is full.
+func (s *scheduler) enqueue(q *Query) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return &flux.Error{
+			Code: codes.Unavailable,
+			Msg:  "query controller shutdown",
+		}
+	}
+	if s.queued >= s.queueSize {
+		return &flux.Error{
+			Code: codes.ResourceExhausted,
+			Msg:  "queue length exceeded",
+		}
+	}
+	if q.limits.QueueSize > 0 && s.orgQueued[q.orgID] >= q.limits.QueueSize {
+		return &flux.Error{
+			Code: codes.ResourceExhausted,
+			Msg:  "organization queue length exceeded",
+		}
+	}
+
+	s.queues[q.priority] = append(s.queues[q.priority], q)
+	s.queued++
+	s.orgQueued[q.orgID]++
+	s.cond.Broadcast()
+	return nil
+}
+
+// next blocks until a queued query can be executed and removes it from its
+// queue. It returns false once the scheduler is closed.
+//
+// The query must be released once it has finished executing.
+func (s *scheduler) next() (*Query, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		if s.closed {
+			return nil, false
+		}
+		if q := s.pick(); q != nil {
+			s.priorityExecuting[q.priority]++
+			s.orgExecuting[q.orgID]++
+			if q.limits.MemoryBytesQuota > 0 {
+				s.orgMemory[q.orgID] += q.limits.MemoryBytesQuotaPerQuery
+			}
+			return q, true
+		}
+		s.cond.Wait()
+	}
+}
+
+// pick removes and returns the first query that can be executed, if any.
+// Canceled queries can always be picked, since they never execute.
+func (s *scheduler) pick() *Query {
+	for p, queue := range s.queues {
+		priorityFull := s.priorityQuotas[p] > 0 && s.priorityExecuting[p] >= s.priorityQuotas[p]
+		for i, q := range queue {
+			canceled := q.parentCtx.Err() != nil
+			if !canceled {
+				if priorityFull {
+					break
+				}
+				if q.limits.ConcurrencyQuota > 0 && s.orgExecuting[q.orgID] >= q.limits.ConcurrencyQuota {
+					continue
+				}
+				if q.limits.MemoryBytesQuota > 0 && s.orgMemory[q.orgID]+q.limits.MemoryBytesQuotaPerQuery > q.limits.MemoryBytesQuota {
+					continue
+				}
+			}
+			s.removeAt(p, i)
+			return q
+		}
+	}
+	return nil
+}
+
+// remove removes q from its queue and reports whether it was queued.
+func (s *scheduler) remove(q *Query) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, qq := range s.queues[q.priority] {
+		if qq == q {
+			s.removeAt(int(q.priority), i)
+			return true
+		}
+	}
+	return false
+}
+
+func (s *scheduler) removeAt(p, i int) {
+	q := s.queues[p][i]
+	s.queues[p] = append(s.queues[p][:i], s.queues[p][i+1:]...)
+	s.queued--
+	if s.orgQueued[q.orgID]--; s.orgQueued[q.orgID] == 0 {
+		delete(s.orgQueued, q.orgID)
+	}
+}
+
+// release frees the concurrency taken up by q, which was returned by next.
+func (s *scheduler) release(q *Query) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.priorityExecuting[q.priority]--
+	if s.orgExecuting[q.orgID]--; s.orgExecuting[q.orgID] == 0 {
+		delete(s.orgExecuting, q.orgID)
+	}
+	if q.limits.MemoryBytesQuota > 0 {
+		if s.orgMemory[q.orgID] -= q.limits.MemoryBytesQuotaPerQuery; s.orgMemory[q.orgID] <= 0 {
+			delete(s.orgMemory, q.orgID)
+		}
+	}
+	s.cond.Broadcast()
+}
+
+// wake makes the executors waiting for a query look at the queues again.
+func (s *scheduler) wake() {
+	s.mu.Lock()
+	s.cond.Broadcast()
+	s.mu.Unlock()
+}
+
+// close stops handing out queries.
+func (s *scheduler) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+}
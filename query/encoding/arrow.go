@@ -0,0 +1,354 @@
+package encoding
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+
+	"github.com/google/flatbuffers/go"
+	"github.com/influxdata/flux"
+)
+
+// Keys of the custom metadata of the schemas of the Arrow dialect.
+const (
+	// ArrowResultKey is the key of the name of the result of a table.
+	ArrowResultKey = "flux.result"
+	// ArrowGroupKeyKey is the key of the labels of the columns of the group
+	// key of a table, encoded as a JSON array.
+	ArrowGroupKeyKey = "flux.groupKey"
+	// ArrowErrorKey is the key of the error of a query.
+	ArrowErrorKey = "flux.error"
+)
+
+// ArrowDialect encodes each table of the results of a query as an Apache
+// Arrow IPC stream: a schema message, a record batch for each buffer of the
+// table and an end of stream marker. The streams of the tables follow each
+// other in the response.
+//
+// The schema of a stream names the result and the group key of the table in
+// its custom metadata. Strings are encoded as UTF-8, integers and unsigned
+// integers as 64 bit integers, floats as doubles, booleans as booleans and
+// times as timestamps with nanosecond precision in UTC. If the query fails
+// after its results started to be written, a stream with an empty schema with
+// the error in its custom metadata is written.
+type ArrowDialect struct{}
+
+func (d *ArrowDialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ArrowContentType)
+	w.Header().Set("Transfer-Encoding", "chunked")
+}
+
+func (d *ArrowDialect) Encoder() flux.MultiResultEncoder {
+	return &flux.DelimitedMultiResultEncoder{
+		Encoder: new(arrowResultEncoder),
+	}
+}
+
+func (d *ArrowDialect) DialectType() flux.DialectType {
+	return ArrowDialectType
+}
+
+// Values of the flatbuffers schema of Arrow messages.
+const (
+	arrowMetadataV4 = 3
+
+	arrowHeaderSchema      = 1
+	arrowHeaderRecordBatch = 3
+
+	arrowTypeInt           = 2
+	arrowTypeFloatingPoint = 3
+	arrowTypeUtf8          = 5
+	arrowTypeBool          = 6
+	arrowTypeTimestamp     = 10
+
+	arrowPrecisionDouble = 2
+	arrowUnitNanosecond  = 3
+)
+
+type arrowResultEncoder struct{}
+
+func (e *arrowResultEncoder) Encode(w io.Writer, result flux.Result) (int64, error) {
+	aw := &arrowWriter{w: &writer{w: w}}
+	name := result.Name()
+	err := result.Tables().Do(func(tbl flux.Table) error {
+		key := tbl.Key()
+		labels := make([]string, len(key.Cols()))
+		for j, c := range key.Cols() {
+			labels[j] = c.Label
+		}
+		groupKey, err := json.Marshal(labels)
+		if err != nil {
+			return err
+		}
+
+		cols := tbl.Cols()
+		aw.writeSchema(cols, [][2]string{
+			{ArrowResultKey, name},
+			{ArrowGroupKeyKey, string(groupKey)},
+		})
+		// The stream is ended even if reading the table fails, so that the
+		// stream of the error can follow it.
+		err = tbl.Do(func(cr flux.ColReader) error {
+			aw.writeRecordBatch(cr)
+			return aw.w.err
+		})
+		aw.writeEOS()
+		if err != nil {
+			return err
+		}
+		return aw.w.err
+	})
+	return aw.w.n, err
+}
+
+func (e *arrowResultEncoder) EncodeError(w io.Writer, err error) error {
+	aw := &arrowWriter{w: &writer{w: w}}
+	aw.writeSchema(nil, [][2]string{{ArrowErrorKey, err.Error()}})
+	aw.writeEOS()
+	return aw.w.err
+}
+
+// arrowWriter writes the messages of an Arrow IPC stream.
+type arrowWriter struct {
+	w *writer
+	b *flatbuffers.Builder
+}
+
+func (aw *arrowWriter) builder() *flatbuffers.Builder {
+	if aw.b == nil {
+		aw.b = flatbuffers.NewBuilder(1024)
+	}
+	aw.b.Reset()
+	return aw.b
+}
+
+func (aw *arrowWriter) writeSchema(cols []flux.ColMeta, metadata [][2]string) {
+	b := aw.builder()
+
+	fields := make([]flatbuffers.UOffsetT, len(cols))
+	for j, c := range cols {
+		fields[j] = arrowField(b, c)
+	}
+	fieldsVec := arrowOffsets(b, fields)
+
+	kvs := make([]flatbuffers.UOffsetT, len(metadata))
+	for i, kv := range metadata {
+		key, value := b.CreateString(kv[0]), b.CreateString(kv[1])
+		b.StartObject(2)
+		b.PrependUOffsetTSlot(0, key, 0)
+		b.PrependUOffsetTSlot(1, value, 0)
+		kvs[i] = b.EndObject()
+	}
+	kvsVec := arrowOffsets(b, kvs)
+
+	b.StartObject(3)
+	b.PrependUOffsetTSlot(1, fieldsVec, 0)
+	b.PrependUOffsetTSlot(2, kvsVec, 0)
+	schema := b.EndObject()
+
+	aw.writeMessage(arrowHeaderSchema, schema, nil)
+}
+
+// arrowField builds the field of the schema of column c.
+func arrowField(b *flatbuffers.Builder, c flux.ColMeta) flatbuffers.UOffsetT {
+	name := b.CreateString(c.Label)
+
+	var typeType byte
+	var typ flatbuffers.UOffsetT
+	switch c.Type {
+	case flux.TInt, flux.TUInt:
+		typeType = arrowTypeInt
+		b.StartObject(2)
+		b.PrependInt32Slot(0, 64, 0)
+		b.PrependBoolSlot(1, c.Type == flux.TInt, false)
+		typ = b.EndObject()
+	case flux.TFloat:
+		typeType = arrowTypeFloatingPoint
+		b.StartObject(1)
+		b.PrependInt16Slot(0, arrowPrecisionDouble, 0)
+		typ = b.EndObject()
+	case flux.TBool:
+		typeType = arrowTypeBool
+		b.StartObject(0)
+		typ = b.EndObject()
+	case flux.TTime:
+		typeType = arrowTypeTimestamp
+		tz := b.CreateString("UTC")
+		b.StartObject(2)
+		b.PrependInt16Slot(0, arrowUnitNanosecond, 0)
+		b.PrependUOffsetTSlot(1, tz, 0)
+		typ = b.EndObject()
+	default:
+		typeType = arrowTypeUtf8
+		b.StartObject(0)
+		typ = b.EndObject()
+	}
+	children := arrowOffsets(b, nil)
+
+	b.StartObject(7)
+	b.PrependUOffsetTSlot(0, name, 0)
+	b.PrependBoolSlot(1, true, false)
+	b.PrependByteSlot(2, typeType, 0)
+	b.PrependUOffsetTSlot(3, typ, 0)
+	b.PrependUOffsetTSlot(5, children, 0)
+	return b.EndObject()
+}
+
+// arrowOffsets builds a vector of the offsets of tables.
+func arrowOffsets(b *flatbuffers.Builder, offsets []flatbuffers.UOffsetT) flatbuffers.UOffsetT {
+	b.StartVector(4, len(offsets), 4)
+	for i := len(offsets) - 1; i >= 0; i-- {
+		b.PrependUOffsetT(offsets[i])
+	}
+	return b.EndVector(len(offsets))
+}
+
+// arrowBuffer is the location of a buffer in the body of a record batch.
+type arrowBuffer struct {
+	offset, length int64
+}
+
+func (aw *arrowWriter) writeRecordBatch(cr flux.ColReader) {
+	n := cr.Len()
+	var (
+		body    []byte
+		buffers []arrowBuffer
+		nulls   = make([]int64, len(cr.Cols()))
+	)
+	addBuffer := func(buf []byte) {
+		buffers = append(buffers, arrowBuffer{offset: int64(len(body)), length: int64(len(buf))})
+		body = append(body, buf...)
+		body = append(body, make([]byte, pad8(len(buf)))...)
+	}
+
+	for j, c := range cr.Cols() {
+		validity := make([]byte, (n+7)/8)
+		var values, data []byte
+		switch c.Type {
+		case flux.TBool:
+			vs := cr.Bools(j)
+			values = make([]byte, (n+7)/8)
+			for i := 0; i < n; i++ {
+				if vs.IsValid(i) && vs.Value(i) {
+					values[i/8] |= 1 << uint(i%8)
+				}
+			}
+		case flux.TString:
+			vs := cr.Strings(j)
+			values = make([]byte, 4*(n+1))
+			for i := 0; i < n; i++ {
+				if vs.IsValid(i) {
+					data = append(data, vs.Value(i)...)
+				}
+				binary.LittleEndian.PutUint32(values[4*(i+1):], uint32(len(data)))
+			}
+		default:
+			values = make([]byte, 8*n)
+			for i := 0; i < n; i++ {
+				var v uint64
+				switch c.Type {
+				case flux.TInt:
+					v = uint64(cr.Ints(j).Value(i))
+				case flux.TUInt:
+					v = cr.UInts(j).Value(i)
+				case flux.TFloat:
+					v = math.Float64bits(cr.Floats(j).Value(i))
+				case flux.TTime:
+					v = uint64(cr.Times(j).Value(i))
+				}
+				binary.LittleEndian.PutUint64(values[8*i:], v)
+			}
+		}
+		for i := 0; i < n; i++ {
+			if isValid(cr, i, j) {
+				validity[i/8] |= 1 << uint(i%8)
+			} else {
+				nulls[j]++
+			}
+		}
+
+		addBuffer(validity)
+		addBuffer(values)
+		if c.Type == flux.TString {
+			addBuffer(data)
+		}
+	}
+
+	b := aw.builder()
+	b.StartVector(16, len(nulls), 8)
+	for j := len(nulls) - 1; j >= 0; j-- {
+		b.Prep(8, 16)
+		b.PrependInt64(nulls[j])
+		b.PrependInt64(int64(n))
+	}
+	nodes := b.EndVector(len(nulls))
+
+	b.StartVector(16, len(buffers), 8)
+	for i := len(buffers) - 1; i >= 0; i-- {
+		b.Prep(8, 16)
+		b.PrependInt64(buffers[i].length)
+		b.PrependInt64(buffers[i].offset)
+	}
+	bufs := b.EndVector(len(buffers))
+
+	b.StartObject(3)
+	b.PrependInt64Slot(0, int64(n), 0)
+	b.PrependUOffsetTSlot(1, nodes, 0)
+	b.PrependUOffsetTSlot(2, bufs, 0)
+	batch := b.EndObject()
+
+	aw.writeMessage(arrowHeaderRecordBatch, batch, body)
+}
+
+// writeMessage finishes the message with the header built last and writes
+// it, followed by its body.
+func (aw *arrowWriter) writeMessage(headerType byte, header flatbuffers.UOffsetT, body []byte) {
+	b := aw.b
+	b.StartObject(5)
+	b.PrependInt16Slot(0, arrowMetadataV4, 0)
+	b.PrependByteSlot(1, headerType, 0)
+	b.PrependUOffsetTSlot(2, header, 0)
+	b.PrependInt64Slot(3, int64(len(body)), 0)
+	b.Finish(b.EndObject())
+
+	meta := b.FinishedBytes()
+	// The metadata is padded so that the body starts at a multiple of 8.
+	padding := pad8(4 + len(meta))
+	var prefix [4]byte
+	binary.LittleEndian.PutUint32(prefix[:], uint32(len(meta)+padding))
+	aw.w.Write(prefix[:])
+	aw.w.Write(meta)
+	aw.w.Write(make([]byte, padding))
+	aw.w.Write(body)
+}
+
+func (aw *arrowWriter) writeEOS() {
+	aw.w.Write([]byte{0, 0, 0, 0})
+}
+
+// pad8 returns the number of bytes n must be padded with to be a multiple
+// of 8.
+func pad8(n int) int {
+	return (8 - n%8) % 8
+}
+
+// isValid reports whether row i of column j of cr is not null.
+func isValid(cr flux.ColReader, i, j int) bool {
+	switch cr.Cols()[j].Type {
+	case flux.TBool:
+		return cr.Bools(j).IsValid(i)
+	case flux.TInt:
+		return cr.Ints(j).IsValid(i)
+	case flux.TUInt:
+		return cr.UInts(j).IsValid(i)
+	case flux.TFloat:
+		return cr.Floats(j).IsValid(i)
+	case flux.TString:
+		return cr.Strings(j).IsValid(i)
+	case flux.TTime:
+		return cr.Times(j).IsValid(i)
+	}
+	return false
+}
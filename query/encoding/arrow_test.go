@@ -0,0 +1,132 @@
+package encoding_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb/query/encoding"
+)
+
+func TestArrowDialect(t *testing.T) {
+	res := &executetest.Result{
+		Nm: "_result",
+		Tbls: []*executetest.Table{{
+			KeyCols: []string{"n"},
+			ColMeta: []flux.ColMeta{
+				{Label: "n", Type: flux.TInt},
+				{Label: "u", Type: flux.TUInt},
+				{Label: "f", Type: flux.TFloat},
+				{Label: "ok", Type: flux.TBool},
+			},
+			Data: [][]interface{}{
+				{int64(-1), uint64(1), 1.5, true},
+				{int64(-1), nil, 2.5, false},
+				{int64(-1), uint64(3), nil, true},
+			},
+		}},
+	}
+
+	r, err := ipc.NewReader(bytes.NewReader(encode(t, new(encoding.ArrowDialect), res)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+
+	schema := r.Schema()
+	for _, exp := range []struct {
+		name string
+		typ  arrow.DataType
+	}{
+		{name: "n", typ: arrow.PrimitiveTypes.Int64},
+		{name: "u", typ: arrow.PrimitiveTypes.Uint64},
+		{name: "f", typ: arrow.PrimitiveTypes.Float64},
+		{name: "ok", typ: arrow.FixedWidthTypes.Boolean},
+	} {
+		f, ok := schema.FieldByName(exp.name)
+		if !ok {
+			t.Fatalf("expected field %s", exp.name)
+		}
+		if !arrow.TypeEquals(f.Type, exp.typ) {
+			t.Errorf("unexpected type of %s -got/+exp\n%v\n%v", exp.name, f.Type, exp.typ)
+		}
+	}
+	md := schema.Metadata()
+	if i := md.FindKey(encoding.ArrowResultKey); i < 0 || md.Values()[i] != "_result" {
+		t.Errorf("unexpected result in metadata: %v", md)
+	}
+	if i := md.FindKey(encoding.ArrowGroupKeyKey); i < 0 || md.Values()[i] != `["n"]` {
+		t.Errorf("unexpected group key in metadata: %v", md)
+	}
+
+	if !r.Next() {
+		t.Fatal("expected a record")
+	}
+	rec := r.Record()
+	if rec.NumRows() != 3 {
+		t.Fatalf("unexpected number of rows -got/+exp\n%d\n%d", rec.NumRows(), 3)
+	}
+	if n := rec.Column(0).(*array.Int64); n.Value(2) != -1 {
+		t.Errorf("unexpected value of n -got/+exp\n%d\n%d", n.Value(2), -1)
+	}
+	if u := rec.Column(1).(*array.Uint64); !u.IsNull(1) || u.Value(2) != 3 {
+		t.Errorf("unexpected values of u: %v", u)
+	}
+	if f := rec.Column(2).(*array.Float64); f.Value(1) != 2.5 || !f.IsNull(2) {
+		t.Errorf("unexpected values of f: %v", f)
+	}
+	if ok := rec.Column(3).(*array.Boolean); !ok.Value(0) || ok.Value(1) || !ok.Value(2) {
+		t.Errorf("unexpected values of ok: %v", ok)
+	}
+	if r.Next() {
+		t.Fatal("expected a single record")
+	}
+}
+
+// TestArrowDialect_Messages checks the messages of the streams of tables with
+// strings and times, which the Arrow reader cannot read yet.
+func TestArrowDialect_Messages(t *testing.T) {
+	res := cpu()
+	res.Tbls[1].Err = errors.New("expected error")
+
+	buf := bytes.NewReader(encode(t, new(encoding.ArrowDialect), res))
+	var got []ipc.MessageType
+	for buf.Len() > 0 {
+		r := ipc.NewMessageReader(buf)
+		for {
+			msg, err := r.Message()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if msg.Type() == ipc.MessageRecordBatch && msg.BodyLen()%8 != 0 {
+				t.Errorf("expected body padded to 8 bytes, got %d bytes", msg.BodyLen())
+			}
+			got = append(got, msg.Type())
+		}
+		got = append(got, ipc.MessageNone)
+	}
+
+	// The stream of the table that fails is ended and followed by a stream
+	// of the error.
+	exp := []ipc.MessageType{
+		ipc.MessageSchema, ipc.MessageRecordBatch, ipc.MessageNone,
+		ipc.MessageSchema, ipc.MessageNone,
+		ipc.MessageSchema, ipc.MessageNone,
+	}
+	if len(got) != len(exp) {
+		t.Fatalf("unexpected messages -got/+exp\n%v\n%v", got, exp)
+	}
+	for i := range exp {
+		if got[i] != exp[i] {
+			t.Fatalf("unexpected messages -got/+exp\n%v\n%v", got, exp)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Package encoding provides the dialects Flux query results can be encoded
+// in besides annotated CSV.
+//
+// The JSON dialect encodes the results as a single JSON document with the
+// rows of each table grouped under the table. The Arrow dialect encodes each
+// table as an Apache Arrow IPC stream. The line protocol dialect encodes each
+// row as a point, so the results can be written back into a bucket.
+package encoding
+
+import (
+	"io"
+
+	"github.com/influxdata/flux"
+)
+
+// Dialect types of the dialects of this package.
+const (
+	JSONDialectType         = "json"
+	ArrowDialectType        = "arrow"
+	LineProtocolDialectType = "lineprotocol"
+)
+
+// Content types of the responses encoded with the dialects of this package.
+const (
+	JSONContentType         = "application/json"
+	ArrowContentType        = "application/vnd.apache.arrow.stream"
+	LineProtocolContentType = "text/plain; charset=utf-8"
+)
+
+// AddDialectMappings adds the mappings of the dialects of this package.
+func AddDialectMappings(mappings flux.DialectMappings) error {
+	if err := mappings.Add(JSONDialectType, func() flux.Dialect {
+		return new(JSONDialect)
+	}); err != nil {
+		return err
+	}
+	if err := mappings.Add(ArrowDialectType, func() flux.Dialect {
+		return new(ArrowDialect)
+	}); err != nil {
+		return err
+	}
+	return mappings.Add(LineProtocolDialectType, func() flux.Dialect {
+		return new(LineProtocolDialect)
+	})
+}
+
+// encoderError is an error writing an encoded result. Unlike the errors of
+// the results, it cannot be encoded into the response.
+type encoderError struct {
+	err error
+}
+
+func (e *encoderError) Error() string {
+	return e.err.Error()
+}
+
+func (e *encoderError) IsEncoderError() bool {
+	return true
+}
+
+// writer is an io.Writer that keeps the first error of its writes, so
+// encoders can write without checking every write.
+type writer struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	if err != nil {
+		w.err = &encoderError{err: err}
+	}
+	return n, w.err
+}
+
+func (w *writer) WriteString(s string) {
+	w.Write([]byte(s))
+}
@@ -0,0 +1,99 @@
+package encoding_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/query/encoding"
+)
+
+// cpu returns a result of two tables of a cpu measurement in the layout
+// storage returns data in.
+func cpu() *executetest.Result {
+	cols := []flux.ColMeta{
+		{Label: "_start", Type: flux.TTime},
+		{Label: "_stop", Type: flux.TTime},
+		{Label: "_time", Type: flux.TTime},
+		{Label: "_value", Type: flux.TFloat},
+		{Label: "_field", Type: flux.TString},
+		{Label: "_measurement", Type: flux.TString},
+		{Label: "host", Type: flux.TString},
+	}
+	keyCols := []string{"_start", "_stop", "_field", "_measurement", "host"}
+	return &executetest.Result{
+		Nm: "_result",
+		Tbls: []*executetest.Table{
+			{
+				KeyCols: keyCols,
+				ColMeta: cols,
+				Data: [][]interface{}{
+					{values.Time(0), values.Time(100), values.Time(10), 1.5, "usage", "cpu", "a"},
+					{values.Time(0), values.Time(100), values.Time(20), nil, "usage", "cpu", "a"},
+				},
+			},
+			{
+				KeyCols: keyCols,
+				ColMeta: cols,
+				Data: [][]interface{}{
+					{values.Time(0), values.Time(100), values.Time(10), 2.0, "usage", "cpu", "b"},
+				},
+			},
+		},
+	}
+}
+
+func encode(t *testing.T, d flux.Dialect, results ...flux.Result) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := d.Encoder().Encode(&buf, flux.NewSliceResultIterator(results)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestAddDialectMappings(t *testing.T) {
+	mappings := make(flux.DialectMappings)
+	if err := encoding.AddDialectMappings(mappings); err != nil {
+		t.Fatal(err)
+	}
+	for _, typ := range []flux.DialectType{
+		encoding.JSONDialectType,
+		encoding.ArrowDialectType,
+		encoding.LineProtocolDialectType,
+	} {
+		create, ok := mappings[typ]
+		if !ok {
+			t.Errorf("expected a mapping of %s", typ)
+			continue
+		}
+		if got := create().DialectType(); got != typ {
+			t.Errorf("unexpected dialect type -got/+exp\n%s\n%s", got, typ)
+		}
+	}
+}
+
+func TestDialects_ErrorBeforeWrite(t *testing.T) {
+	exp := errors.New("expected error")
+	for _, d := range []flux.Dialect{
+		new(encoding.JSONDialect),
+		new(encoding.ArrowDialect),
+		new(encoding.LineProtocolDialect),
+	} {
+		t.Run(string(d.DialectType()), func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := d.Encoder().Encode(&buf, flux.NewSliceResultIterator([]flux.Result{
+				&executetest.Result{Nm: "_result", Err: exp},
+			}))
+			if err != exp {
+				t.Fatalf("unexpected error -got/+exp\n%v\n%v", err, exp)
+			}
+			if n != 0 || buf.Len() != 0 {
+				t.Fatalf("expected nothing to be written, got %q", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package encoding
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+// JSONDialect encodes the results of a query as a JSON document of the form
+//
+//	{
+//	  "results": [{
+//	    "name": "_result",
+//	    "tables": [{
+//	      "groupKey": {"_measurement": "cpu"},
+//	      "columns": [{"label": "_time", "type": "time", "group": false}, ...],
+//	      "rows": [{"_time": "2019-01-01T00:00:00Z", "_measurement": "cpu", ...}, ...]
+//	    }]
+//	  }],
+//	  "error": "..."
+//	}
+//
+// Times are RFC3339 strings with nanosecond precision. Null values, and
+// floats that are not numbers or are infinite, are null. The error is only
+// set if the query failed after its results started to be written.
+type JSONDialect struct{}
+
+func (d *JSONDialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", JSONContentType)
+	w.Header().Set("Transfer-Encoding", "chunked")
+}
+
+func (d *JSONDialect) Encoder() flux.MultiResultEncoder {
+	return new(jsonMultiResultEncoder)
+}
+
+func (d *JSONDialect) DialectType() flux.DialectType {
+	return JSONDialectType
+}
+
+type jsonMultiResultEncoder struct{}
+
+// Encode writes the results as a single JSON document. An error of the
+// results is returned as is if nothing has been written yet, and encoded into
+// the document otherwise.
+func (e *jsonMultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	wc := &iocounter.Writer{Writer: w}
+	enc := &jsonEncoder{w: &writer{w: wc}}
+
+	for results.More() {
+		if err := enc.encodeResult(results.Next()); err != nil {
+			return enc.fail(wc, err)
+		}
+		if f, ok := w.(interface{ Flush() }); ok {
+			f.Flush()
+		}
+	}
+	if err := results.Err(); err != nil {
+		return enc.fail(wc, err)
+	}
+
+	enc.open()
+	enc.w.WriteString("]}\n")
+	return wc.Count(), enc.w.err
+}
+
+// jsonEncoder writes a JSON document of results. The document is only
+// opened once there is something to write, so that errors occurring before
+// any result is ready can be reported by the caller.
+type jsonEncoder struct {
+	w *writer
+
+	opened  bool
+	results int
+
+	// inResult and inTable are set while the result or table is open.
+	inResult bool
+	tables   int
+	inTable  bool
+	rows     int
+}
+
+func (e *jsonEncoder) open() {
+	if !e.opened {
+		e.opened = true
+		e.w.WriteString(`{"results":[`)
+	}
+}
+
+func (e *jsonEncoder) openResult(name string) {
+	if e.inResult {
+		return
+	}
+	e.open()
+	if e.results > 0 {
+		e.w.WriteString(",")
+	}
+	e.results++
+	e.inResult = true
+	e.tables = 0
+	e.w.WriteString(`{"name":`)
+	e.writeValue(name)
+	e.w.WriteString(`,"tables":[`)
+}
+
+func (e *jsonEncoder) closeResult() {
+	e.w.WriteString("]}")
+	e.inResult = false
+}
+
+func (e *jsonEncoder) encodeResult(res flux.Result) error {
+	name := res.Name()
+	if err := res.Tables().Do(func(tbl flux.Table) error {
+		e.openResult(name)
+		return e.encodeTable(tbl)
+	}); err != nil {
+		return err
+	}
+	e.openResult(name)
+	e.closeResult()
+	return e.w.err
+}
+
+func (e *jsonEncoder) encodeTable(tbl flux.Table) error {
+	if e.tables > 0 {
+		e.w.WriteString(",")
+	}
+	e.tables++
+	e.inTable = true
+	e.rows = 0
+
+	key := tbl.Key()
+	e.w.WriteString(`{"groupKey":{`)
+	for j, c := range key.Cols() {
+		if j > 0 {
+			e.w.WriteString(",")
+		}
+		e.writeValue(c.Label)
+		e.w.WriteString(":")
+		e.writeValue(jsonValue(key.Value(j)))
+	}
+	e.w.WriteString(`},"columns":[`)
+	cols := tbl.Cols()
+	labels := make([][]byte, len(cols))
+	for j, c := range cols {
+		if j > 0 {
+			e.w.WriteString(",")
+		}
+		e.writeValue(struct {
+			Label string `json:"label"`
+			Type  string `json:"type"`
+			Group bool   `json:"group"`
+		}{
+			Label: c.Label,
+			Type:  c.Type.String(),
+			Group: key.HasCol(c.Label),
+		})
+		labels[j], _ = json.Marshal(c.Label)
+	}
+	e.w.WriteString(`],"rows":[`)
+
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		for i := 0; i < cr.Len(); i++ {
+			if e.rows > 0 {
+				e.w.WriteString(",")
+			}
+			e.rows++
+			e.w.WriteString("{")
+			for j := range cr.Cols() {
+				if j > 0 {
+					e.w.WriteString(",")
+				}
+				e.w.Write(labels[j])
+				e.w.WriteString(":")
+				e.writeValue(valueAt(cr, i, j))
+			}
+			e.w.WriteString("}")
+		}
+		return e.w.err
+	}); err != nil {
+		return err
+	}
+
+	e.w.WriteString("]}")
+	e.inTable = false
+	return e.w.err
+}
+
+func (e *jsonEncoder) writeValue(v interface{}) {
+	octets, err := json.Marshal(v)
+	if err != nil {
+		e.w.err = &encoderError{err: err}
+		return
+	}
+	e.w.Write(octets)
+}
+
+// fail encodes err into the document, unless nothing has been written yet
+// or err is an error of the encoder, in which case err is returned.
+func (e *jsonEncoder) fail(wc *iocounter.Writer, err error) (int64, error) {
+	if flux.IsEncoderError(err) || wc.Count() == 0 {
+		return wc.Count(), err
+	}
+	if e.inTable {
+		e.w.WriteString("]}")
+	}
+	if e.inResult {
+		e.closeResult()
+	}
+	e.w.WriteString(`],"error":`)
+	e.writeValue(err.Error())
+	e.w.WriteString("}\n")
+	return wc.Count(), e.w.err
+}
+
+// valueAt returns the value of row i of column j of cr as a value that can
+// be encoded as JSON.
+func valueAt(cr flux.ColReader, i, j int) interface{} {
+	switch cr.Cols()[j].Type {
+	case flux.TBool:
+		if vs := cr.Bools(j); vs.IsValid(i) {
+			return vs.Value(i)
+		}
+	case flux.TInt:
+		if vs := cr.Ints(j); vs.IsValid(i) {
+			return vs.Value(i)
+		}
+	case flux.TUInt:
+		if vs := cr.UInts(j); vs.IsValid(i) {
+			return vs.Value(i)
+		}
+	case flux.TFloat:
+		if vs := cr.Floats(j); vs.IsValid(i) {
+			return jsonFloat(vs.Value(i))
+		}
+	case flux.TString:
+		if vs := cr.Strings(j); vs.IsValid(i) {
+			return vs.ValueString(i)
+		}
+	case flux.TTime:
+		if vs := cr.Times(j); vs.IsValid(i) {
+			return jsonTime(vs.Value(i))
+		}
+	}
+	return nil
+}
+
+// jsonValue returns v as a value that can be encoded as JSON.
+func jsonValue(v values.Value) interface{} {
+	if v.IsNull() {
+		return nil
+	}
+	switch v.Type().Nature() {
+	case semantic.Bool:
+		return v.Bool()
+	case semantic.Int:
+		return v.Int()
+	case semantic.UInt:
+		return v.UInt()
+	case semantic.Float:
+		return jsonFloat(v.Float())
+	case semantic.String:
+		return v.Str()
+	case semantic.Time:
+		return jsonTime(int64(v.Time()))
+	}
+	return nil
+}
+
+func jsonFloat(f float64) interface{} {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	return f
+}
+
+func jsonTime(ns int64) string {
+	return time.Unix(0, ns).UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,87 @@
+package encoding_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb/query/encoding"
+)
+
+func TestJSONDialect(t *testing.T) {
+	other := &executetest.Result{
+		Nm: "other",
+		Tbls: []*executetest.Table{{
+			ColMeta: []flux.ColMeta{
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "n", Type: flux.TInt},
+				{Label: "u", Type: flux.TUInt},
+				{Label: "ok", Type: flux.TBool},
+				{Label: "f", Type: flux.TFloat},
+			},
+			Data: [][]interface{}{
+				{"m", int64(-1), uint64(1), true, math.NaN()},
+			},
+		}},
+	}
+
+	got := encode(t, new(encoding.JSONDialect), cpu(), other)
+	exp := `{"results":[` +
+		`{"name":"_result","tables":[` +
+		`{"groupKey":{"_start":"1970-01-01T00:00:00Z","_stop":"1970-01-01T00:00:00.0000001Z","_field":"usage","_measurement":"cpu","host":"a"},` +
+		`"columns":[{"label":"_start","type":"time","group":true},{"label":"_stop","type":"time","group":true},{"label":"_time","type":"time","group":false},{"label":"_value","type":"float","group":false},{"label":"_field","type":"string","group":true},{"label":"_measurement","type":"string","group":true},{"label":"host","type":"string","group":true}],` +
+		`"rows":[` +
+		`{"_start":"1970-01-01T00:00:00Z","_stop":"1970-01-01T00:00:00.0000001Z","_time":"1970-01-01T00:00:00.00000001Z","_value":1.5,"_field":"usage","_measurement":"cpu","host":"a"},` +
+		`{"_start":"1970-01-01T00:00:00Z","_stop":"1970-01-01T00:00:00.0000001Z","_time":"1970-01-01T00:00:00.00000002Z","_value":null,"_field":"usage","_measurement":"cpu","host":"a"}]},` +
+		`{"groupKey":{"_start":"1970-01-01T00:00:00Z","_stop":"1970-01-01T00:00:00.0000001Z","_field":"usage","_measurement":"cpu","host":"b"},` +
+		`"columns":[{"label":"_start","type":"time","group":true},{"label":"_stop","type":"time","group":true},{"label":"_time","type":"time","group":false},{"label":"_value","type":"float","group":false},{"label":"_field","type":"string","group":true},{"label":"_measurement","type":"string","group":true},{"label":"host","type":"string","group":true}],` +
+		`"rows":[` +
+		`{"_start":"1970-01-01T00:00:00Z","_stop":"1970-01-01T00:00:00.0000001Z","_time":"1970-01-01T00:00:00.00000001Z","_value":2,"_field":"usage","_measurement":"cpu","host":"b"}]}]},` +
+		`{"name":"other","tables":[` +
+		`{"groupKey":{},` +
+		`"columns":[{"label":"_measurement","type":"string","group":false},{"label":"n","type":"int","group":false},{"label":"u","type":"uint","group":false},{"label":"ok","type":"bool","group":false},{"label":"f","type":"float","group":false}],` +
+		`"rows":[{"_measurement":"m","n":-1,"u":1,"ok":true,"f":null}]}]}]}
+`
+	if diff := cmp.Diff(string(got), exp); diff != "" {
+		t.Fatalf("unexpected output -got/+exp\n%s", diff)
+	}
+	if !json.Valid(got) {
+		t.Fatal("expected valid JSON")
+	}
+}
+
+func TestJSONDialect_Empty(t *testing.T) {
+	got := encode(t, new(encoding.JSONDialect), &executetest.Result{Nm: "_result"})
+	if exp := `{"results":[{"name":"_result","tables":[]}]}` + "\n"; string(got) != exp {
+		t.Fatalf("unexpected output -got/+exp\n%s\n%s", got, exp)
+	}
+}
+
+func TestJSONDialect_Error(t *testing.T) {
+	res := cpu()
+	res.Tbls[1].Err = errors.New("expected error")
+
+	got := encode(t, new(encoding.JSONDialect), res)
+	if !json.Valid(got) {
+		t.Fatalf("expected valid JSON, got %s", got)
+	}
+	var doc struct {
+		Results []struct {
+			Tables []json.RawMessage `json:"tables"`
+		} `json:"results"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(got, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Error != "expected error" {
+		t.Errorf("unexpected error -got/+exp\n%s\n%s", doc.Error, "expected error")
+	}
+	if len(doc.Results) != 1 || len(doc.Results[0].Tables) != 2 {
+		t.Errorf("unexpected results: %s", got)
+	}
+}
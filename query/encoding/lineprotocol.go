@@ -0,0 +1,164 @@
+package encoding
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/influxdb/models"
+)
+
+// LineProtocolDialect encodes each row of the results of a query as a point
+// of line protocol, so that the results can be written into a bucket as is.
+//
+// The measurement of a point is the _measurement column, which every table
+// must have. Its tags are the string columns of the group key, other than
+// _measurement, _field, _start and _stop, and its timestamp is the _time
+// column if the table has one. If the table has the _field and _value
+// columns, the point has the single field they name; otherwise every column
+// that is not a tag, _measurement, _time, _start or _stop is a field. Null
+// fields are left out, and rows without any field are skipped.
+type LineProtocolDialect struct{}
+
+func (d *LineProtocolDialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", LineProtocolContentType)
+	w.Header().Set("Transfer-Encoding", "chunked")
+}
+
+func (d *LineProtocolDialect) Encoder() flux.MultiResultEncoder {
+	return &flux.DelimitedMultiResultEncoder{
+		Encoder: new(lineProtocolResultEncoder),
+	}
+}
+
+func (d *LineProtocolDialect) DialectType() flux.DialectType {
+	return LineProtocolDialectType
+}
+
+type lineProtocolResultEncoder struct{}
+
+func (e *lineProtocolResultEncoder) Encode(w io.Writer, result flux.Result) (int64, error) {
+	lw := &writer{w: w}
+	var buf []byte
+	err := result.Tables().Do(func(tbl flux.Table) error {
+		cols, err := newLineProtocolColumns(tbl)
+		if err != nil {
+			return err
+		}
+		return tbl.Do(func(cr flux.ColReader) error {
+			for i := 0; i < cr.Len(); i++ {
+				p, err := cols.point(cr, i)
+				if err != nil {
+					return err
+				}
+				if p == nil {
+					continue
+				}
+				buf = append(p.AppendString(buf[:0]), '\n')
+				if _, err := lw.Write(buf); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	return lw.n, err
+}
+
+// EncodeError writes err as a comment, which is ignored by writes.
+func (e *lineProtocolResultEncoder) EncodeError(w io.Writer, err error) error {
+	_, werr := fmt.Fprintf(w, "# error: %v\n", err)
+	return werr
+}
+
+// lineProtocolColumns holds the indexes of the columns of a table that make
+// up its points.
+type lineProtocolColumns struct {
+	measurement int
+	time        int
+	field       int
+	value       int
+	tags        []int
+	fields      []int
+}
+
+func newLineProtocolColumns(tbl flux.Table) (*lineProtocolColumns, error) {
+	cols := tbl.Cols()
+	lc := &lineProtocolColumns{
+		measurement: execute.ColIdx("_measurement", cols),
+		time:        execute.ColIdx(execute.DefaultTimeColLabel, cols),
+		field:       execute.ColIdx("_field", cols),
+		value:       execute.ColIdx(execute.DefaultValueColLabel, cols),
+	}
+	if lc.measurement < 0 || cols[lc.measurement].Type != flux.TString {
+		return nil, fmt.Errorf("table must have a string _measurement column to be encoded as line protocol")
+	}
+	if lc.time >= 0 && cols[lc.time].Type != flux.TTime {
+		lc.time = -1
+	}
+	if lc.field >= 0 && (lc.value < 0 || cols[lc.field].Type != flux.TString) {
+		lc.field = -1
+	}
+
+	key := tbl.Key()
+	for j, c := range cols {
+		switch c.Label {
+		case "_measurement", execute.DefaultTimeColLabel, execute.DefaultStartColLabel, execute.DefaultStopColLabel:
+			continue
+		case "_field", execute.DefaultValueColLabel:
+			if lc.field >= 0 {
+				continue
+			}
+		}
+		if c.Type == flux.TString && key.HasCol(c.Label) {
+			lc.tags = append(lc.tags, j)
+		} else if lc.field < 0 && c.Type != flux.TTime {
+			lc.fields = append(lc.fields, j)
+		}
+	}
+	return lc, nil
+}
+
+// point returns the point of row i of cr, or nil if the row has no fields.
+func (lc *lineProtocolColumns) point(cr flux.ColReader, i int) (models.Point, error) {
+	ms := cr.Strings(lc.measurement)
+	if !ms.IsValid(i) {
+		return nil, nil
+	}
+	cols := cr.Cols()
+
+	tags := make(map[string]string, len(lc.tags))
+	for _, j := range lc.tags {
+		if vs := cr.Strings(j); vs.IsValid(i) && vs.ValueLen(i) > 0 {
+			tags[cols[j].Label] = vs.ValueString(i)
+		}
+	}
+
+	fields := make(models.Fields, len(lc.fields))
+	if lc.field >= 0 {
+		if fs := cr.Strings(lc.field); fs.IsValid(i) {
+			if v := valueAt(cr, i, lc.value); v != nil {
+				fields[fs.ValueString(i)] = v
+			}
+		}
+	}
+	for _, j := range lc.fields {
+		if v := valueAt(cr, i, j); v != nil {
+			fields[cols[j].Label] = v
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	var t time.Time
+	if lc.time >= 0 {
+		if ts := cr.Times(lc.time); ts.IsValid(i) {
+			t = time.Unix(0, ts.Value(i)).UTC()
+		}
+	}
+	return models.NewPoint(ms.ValueString(i), models.NewTags(tags), fields, t)
+}
@@ -0,0 +1,114 @@
+package encoding_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query/encoding"
+)
+
+func init() {
+	// Force uint support to be enabled to parse the encoded points.
+	models.EnableUintSupport()
+}
+
+func TestLineProtocolDialect(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		result *executetest.Result
+		exp    string
+	}{
+		{
+			name:   "field and value",
+			result: cpu(),
+			exp: "cpu,host=a usage=1.5 10\n" +
+				"cpu,host=b usage=2 10\n",
+		},
+		{
+			name: "pivoted",
+			result: &executetest.Result{
+				Nm: "_result",
+				Tbls: []*executetest.Table{{
+					KeyCols: []string{"_measurement", "region"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_measurement", Type: flux.TString},
+						{Label: "region", Type: flux.TString},
+						{Label: "host", Type: flux.TString},
+						{Label: "n", Type: flux.TInt},
+						{Label: "u", Type: flux.TUInt},
+						{Label: "ok", Type: flux.TBool},
+					},
+					Data: [][]interface{}{
+						{values.Time(5), "m", "west", "a b", int64(-1), uint64(2), true},
+						{values.Time(6), "m", "west", nil, nil, nil, nil},
+						{values.Time(7), "m", "west", nil, int64(3), nil, nil},
+					},
+				}},
+			},
+			exp: "m,region=west host=\"a b\",n=-1i,ok=true,u=2u 5\n" +
+				"m,region=west n=3i 7\n",
+		},
+		{
+			name: "without time",
+			result: &executetest.Result{
+				Nm: "_result",
+				Tbls: []*executetest.Table{{
+					KeyCols: []string{"_measurement"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_measurement", Type: flux.TString},
+						{Label: "count", Type: flux.TInt},
+					},
+					Data: [][]interface{}{
+						{"m", int64(4)},
+					},
+				}},
+			},
+			exp: "m count=4i\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := encode(t, new(encoding.LineProtocolDialect), tt.result)
+			if diff := cmp.Diff(string(got), tt.exp); diff != "" {
+				t.Fatalf("unexpected output -got/+exp\n%s", diff)
+			}
+			if _, err := models.ParsePointsWithPrecisionV1(got, nil, time.Now(), "n"); err != nil {
+				t.Fatalf("failed to parse output: %v", err)
+			}
+		})
+	}
+}
+
+func TestLineProtocolDialect_NoMeasurement(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := new(encoding.LineProtocolDialect).Encoder().Encode(&buf, flux.NewSliceResultIterator([]flux.Result{&executetest.Result{
+		Nm: "_result",
+		Tbls: []*executetest.Table{{
+			ColMeta: []flux.ColMeta{{Label: "n", Type: flux.TInt}},
+			Data:    [][]interface{}{{int64(1)}},
+		}},
+	}}))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected nothing to be written, got %q", buf.String())
+	}
+}
+
+func TestLineProtocolDialect_Error(t *testing.T) {
+	res := cpu()
+	res.Tbls[1].Err = errors.New("expected error")
+
+	got := encode(t, new(encoding.LineProtocolDialect), res)
+	if exp := "cpu,host=a usage=1.5 10\n# error: expected error\n"; string(got) != exp {
+		t.Fatalf("unexpected output -got/+exp\n%s\n%s", got, exp)
+	}
+}
@@ -0,0 +1,174 @@
+// Package explain describes how Flux queries are planned and profiles how
+// they execute.
+//
+// Explain returns the logical plan of a query, after the logical rules have
+// rewritten it, and the physical plan it is executed with, after the physical
+// rules, such as the ones pushing operations down to storage, have rewritten
+// it.
+//
+// CompileProfile compiles a query into a program that measures the output of
+// each operation of its physical plan, from the storage reads up through the
+// transformations, and returns the measurements as an extra result named
+// ProfileResultName.
+package explain
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/influxdb"
+)
+
+// Explanation describes how a query is planned.
+type Explanation struct {
+	// Logical is the plan of the query after the logical rules are applied.
+	Logical *Plan `json:"logical"`
+	// Physical is the plan the query is executed with.
+	Physical *Plan `json:"physical"`
+}
+
+// Plan is a query plan. Its nodes are ordered so that the predecessors of a
+// node come before it.
+type Plan struct {
+	Nodes []*Node `json:"nodes"`
+}
+
+// Node is an operation of a query plan.
+type Node struct {
+	ID           string          `json:"id"`
+	Kind         string          `json:"kind"`
+	Predecessors []string        `json:"predecessors"`
+	Bounds       *Bounds         `json:"bounds,omitempty"`
+	Spec         json.RawMessage `json:"spec,omitempty"`
+}
+
+// Bounds is the time range of the data an operation produces.
+type Bounds struct {
+	Start time.Time `json:"start"`
+	Stop  time.Time `json:"stop"`
+}
+
+// Explain plans the query compiled by compiler and describes its logical and
+// physical plans. The query is not executed.
+func Explain(ctx context.Context, compiler flux.Compiler) (*Explanation, error) {
+	src, err := sourceOf(compiler)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := src.spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lp, err := logicalPlan(spec)
+	if err != nil {
+		return nil, err
+	}
+	// Describe the logical plan before it is handed to the physical planner,
+	// which replaces its nodes.
+	e := &Explanation{Logical: describe(lp)}
+	pp, err := plan.NewPhysicalPlanner().Plan(lp)
+	if err != nil {
+		return nil, planError(err)
+	}
+	e.Physical = describe(pp)
+	return e, nil
+}
+
+func logicalPlan(spec *flux.Spec) (*plan.Spec, error) {
+	lp := plan.NewLogicalPlanner()
+	initial, err := lp.CreateInitialPlan(spec)
+	if err != nil {
+		return nil, planError(err)
+	}
+	p, err := lp.Plan(initial)
+	if err != nil {
+		return nil, planError(err)
+	}
+	return p, nil
+}
+
+func physicalPlan(spec *flux.Spec) (*plan.Spec, error) {
+	lp, err := logicalPlan(spec)
+	if err != nil {
+		return nil, err
+	}
+	pp, err := plan.NewPhysicalPlanner().Plan(lp)
+	if err != nil {
+		return nil, planError(err)
+	}
+	return pp, nil
+}
+
+func planError(err error) error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "error in planning query",
+		Err:  err,
+	}
+}
+
+// describe returns the description of the nodes of p.
+func describe(p *plan.Spec) *Plan {
+	nodes := walk(p)
+	desc := &Plan{Nodes: make([]*Node, 0, len(nodes))}
+	for _, n := range nodes {
+		node := &Node{
+			ID:           string(n.ID()),
+			Kind:         string(n.Kind()),
+			Predecessors: make([]string, 0, len(n.Predecessors())),
+		}
+		for _, pred := range n.Predecessors() {
+			node.Predecessors = append(node.Predecessors, string(pred.ID()))
+		}
+		if b := n.Bounds(); b != nil {
+			node.Bounds = &Bounds{
+				Start: b.Start.Time().UTC(),
+				Stop:  b.Stop.Time().UTC(),
+			}
+		}
+		// Not every procedure spec can be encoded, so its parameters are
+		// described on a best effort basis.
+		if octets, err := json.Marshal(n.ProcedureSpec()); err == nil {
+			node.Spec = octets
+		}
+		desc.Nodes = append(desc.Nodes, node)
+	}
+	return desc
+}
+
+// walk returns the nodes of p with the predecessors of each node before it.
+// Unlike the walks of the plan, the order does not depend on map iteration.
+func walk(p *plan.Spec) []plan.Node {
+	roots := make([]plan.Node, 0, len(p.Roots))
+	for root := range p.Roots {
+		roots = append(roots, root)
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].ID() < roots[j].ID()
+	})
+
+	var (
+		nodes   []plan.Node
+		visited = make(map[plan.Node]bool)
+		visit   func(n plan.Node)
+	)
+	visit = func(n plan.Node) {
+		if visited[n] {
+			return
+		}
+		visited[n] = true
+		for _, pred := range n.Predecessors() {
+			visit(pred)
+		}
+		nodes = append(nodes, n)
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+	return nodes
+}
@@ -0,0 +1,106 @@
+package explain_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/flux/repl"
+	"github.com/influxdata/influxdb"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/influxdata/influxdb/query/explain"
+)
+
+const data = `
+#datatype,string,long,dateTime:RFC3339,double,string,string
+#group,false,false,false,false,true,true
+#default,_result,,,,,
+,result,table,_time,_value,_field,_measurement
+,,0,2019-01-01T00:00:00Z,1,f,m0
+,,0,2019-01-01T00:00:01Z,2,f,m0
+,,1,2019-01-01T00:00:00Z,3,f,m1
+`
+
+// extern declares the data the test queries read.
+func extern() *ast.File {
+	return parser.ParseSource("data = \"" + data + "\"").Files[0]
+}
+
+func TestExplain(t *testing.T) {
+	q := `import "csv"
+csv.from(csv: data)
+  |> range(start: 2019-01-01T00:00:00Z, stop: 2019-01-02T00:00:00Z)
+  |> filter(fn: (r) => r._measurement == "m0")
+  |> yield(name: "out")`
+
+	e, err := explain.Explain(context.Background(), lang.FluxCompiler{
+		Query:  q,
+		Extern: extern(),
+		Now:    time.Unix(0, 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		plan  *explain.Plan
+		kinds []string
+	}{
+		{name: "logical", plan: e.Logical, kinds: []string{"fromCSV", "range", "filter", "yield"}},
+		{name: "physical", plan: e.Physical, kinds: []string{"fromCSV", "range", "filter", "yield"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.plan.Nodes) != len(tt.kinds) {
+				t.Fatalf("unexpected number of nodes -got/+exp\n%d\n%d", len(tt.plan.Nodes), len(tt.kinds))
+			}
+			for i, n := range tt.plan.Nodes {
+				if n.Kind != tt.kinds[i] {
+					t.Errorf("unexpected kind of node %d -got/+exp\n%s\n%s", i, n.Kind, tt.kinds[i])
+				}
+				if i > 0 && (len(n.Predecessors) != 1 || n.Predecessors[0] != tt.plan.Nodes[i-1].ID) {
+					t.Errorf("unexpected predecessors of node %s: %v", n.ID, n.Predecessors)
+				}
+			}
+		})
+	}
+
+	// Only the physical plan has bounds.
+	if b := e.Logical.Nodes[2].Bounds; b != nil {
+		t.Errorf("expected no bounds in logical plan, got %v", b)
+	}
+	b := e.Physical.Nodes[2].Bounds
+	if b == nil {
+		t.Fatal("expected bounds in physical plan")
+	}
+	if exp := time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC); !b.Stop.Equal(exp) {
+		t.Errorf("unexpected stop of bounds -got/+exp\n%v\n%v", b.Stop, exp)
+	}
+
+	if _, err := json.Marshal(e); err != nil {
+		t.Fatalf("failed to encode explanation: %v", err)
+	}
+}
+
+func TestExplain_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		compiler flux.Compiler
+	}{
+		{name: "syntax error", compiler: lang.FluxCompiler{Query: `from(`}},
+		{name: "no streaming data", compiler: lang.FluxCompiler{Query: `x = 1`}},
+		{name: "unsupported compiler", compiler: repl.Compiler{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := explain.Explain(context.Background(), tt.compiler)
+			if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+				t.Fatalf("unexpected error code -got/+exp\n%s\n%s (%v)", code, influxdb.EInvalid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,472 @@
+package explain
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/values"
+)
+
+const (
+	// ProfileResultName is the name of the result holding the profile of a
+	// query compiled with CompileProfile.
+	ProfileResultName = "_profile"
+
+	// ProfileKind is the kind of the operations that measure the output of
+	// the operation they follow.
+	ProfileKind = "influxDBProfile"
+)
+
+// Columns of the profile result.
+const (
+	operatorColumn           = "operator"
+	kindColumn               = "kind"
+	tablesColumn             = "tables"
+	rowsColumn               = "rows"
+	bytesColumn              = "bytes"
+	firstTableDurationColumn = "firstTableDuration"
+	totalDurationColumn      = "totalDuration"
+)
+
+var profileColumns = []flux.ColMeta{
+	{Label: operatorColumn, Type: flux.TString},
+	{Label: kindColumn, Type: flux.TString},
+	{Label: tablesColumn, Type: flux.TInt},
+	{Label: rowsColumn, Type: flux.TInt},
+	{Label: bytesColumn, Type: flux.TInt},
+	{Label: firstTableDurationColumn, Type: flux.TInt},
+	{Label: totalDurationColumn, Type: flux.TInt},
+}
+
+func init() {
+	execute.RegisterTransformation(ProfileKind, createProfileTransformation)
+}
+
+// CompileProfile compiles the query of compiler into a program that profiles
+// its execution. The program returns the results of the query followed by a
+// result named ProfileResultName with a row for each operation of the
+// physical plan of the query and the columns
+//
+//	operator            the ID of the operation in the physical plan
+//	kind                the kind of the operation
+//	tables              the number of tables the operation produced
+//	rows                the number of rows the operation produced
+//	bytes               the approximate size of the data the operation produced
+//	firstTableDuration  the nanoseconds until the operation produced its first table
+//	totalDuration       the nanoseconds until the operation finished
+//
+// The durations are relative to the start of the execution of the query.
+func CompileProfile(ctx context.Context, compiler flux.Compiler) (flux.Program, error) {
+	src, err := sourceOf(compiler)
+	if err != nil {
+		return nil, err
+	}
+	return &profileProgram{
+		Program: &lang.Program{},
+		src:     src,
+	}, nil
+}
+
+// profileProgram is a program that inserts a profile operation after each
+// operation of the physical plan of its query. Like the programs of Flux,
+// the query is evaluated and planned once the program is started.
+type profileProgram struct {
+	*lang.Program
+
+	src *source
+}
+
+func (p *profileProgram) Start(ctx context.Context, alloc *memory.Allocator) (flux.Query, error) {
+	spec, err := p.src.spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ps, err := physicalPlan(spec)
+	if err != nil {
+		return nil, err
+	}
+	prof := instrument(ps)
+	p.PlanSpec = ps
+
+	ctx, cancel := context.WithCancel(ctx)
+	prof.start = time.Now()
+	exec, err := p.Program.Start(ctx, alloc)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	q := &profileQuery{
+		Query:   exec,
+		results: make(chan flux.Result),
+		cancel:  cancel,
+	}
+	q.wg.Add(1)
+	go q.forward(ctx, &profileResult{ctx: ctx, prof: prof, alloc: alloc})
+	return q, nil
+}
+
+// instrument inserts a profile operation between each operation of p that
+// has successors and its successors.
+func instrument(p *plan.Spec) *profile {
+	prof := new(profile)
+	for _, node := range walk(p) {
+		if _, ok := node.ProcedureSpec().(plan.YieldProcedureSpec); ok {
+			continue
+		}
+		succs := node.Successors()
+		if len(succs) == 0 {
+			continue
+		}
+
+		op := &operator{
+			id:   string(node.ID()),
+			kind: string(node.Kind()),
+			done: make(chan struct{}),
+		}
+		prof.operators = append(prof.operators, op)
+		pn := plan.CreatePhysicalNode(plan.NodeID("profile_"+op.id), &profileProcedureSpec{op: op})
+		pn.AddPredecessors(node)
+		pn.AddSuccessors(succs...)
+
+		// The order of the predecessors of the successors has to be kept,
+		// since some operations, such as join, depend on it.
+		for _, succ := range succs {
+			preds := succ.Predecessors()
+			succ.ClearPredecessors()
+			for _, pred := range preds {
+				if pred == node {
+					pred = pn
+				}
+				succ.AddPredecessors(pred)
+			}
+		}
+		node.ClearSuccessors()
+		node.AddSuccessors(pn)
+	}
+	return prof
+}
+
+// profile holds the measurements of the operations of a query.
+type profile struct {
+	start     time.Time
+	operators []*operator
+}
+
+// operator holds the measurements of the output of an operation.
+type operator struct {
+	id, kind string
+
+	mu         sync.Mutex
+	tables     int64
+	rows       int64
+	bytes      int64
+	firstTable time.Time
+	finished   time.Time
+
+	// done is closed once the operation has finished.
+	done chan struct{}
+}
+
+func (o *operator) addTable() {
+	o.mu.Lock()
+	o.tables++
+	if o.firstTable.IsZero() {
+		o.firstTable = time.Now()
+	}
+	o.mu.Unlock()
+}
+
+func (o *operator) addRows(cr flux.ColReader) {
+	rows, size := int64(cr.Len()), int64(0)
+	for j, col := range cr.Cols() {
+		switch col.Type {
+		case flux.TBool:
+			size += rows
+		case flux.TInt:
+			size += 8 * rows
+		case flux.TUInt:
+			size += 8 * rows
+		case flux.TFloat:
+			size += 8 * rows
+		case flux.TTime:
+			size += 8 * rows
+		case flux.TString:
+			vs := cr.Strings(j)
+			size += int64(len(vs.ValueBytes()))
+		}
+	}
+	o.mu.Lock()
+	o.rows += rows
+	o.bytes += size
+	o.mu.Unlock()
+}
+
+func (o *operator) finish() {
+	o.mu.Lock()
+	if o.finished.IsZero() {
+		o.finished = time.Now()
+		close(o.done)
+	}
+	o.mu.Unlock()
+}
+
+type profileProcedureSpec struct {
+	plan.DefaultCost
+
+	op *operator
+}
+
+func (s *profileProcedureSpec) Kind() plan.ProcedureKind {
+	return ProfileKind
+}
+
+func (s *profileProcedureSpec) Copy() plan.ProcedureSpec {
+	return &profileProcedureSpec{op: s.op}
+}
+
+func createProfileTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s := spec.(*profileProcedureSpec)
+	t := &profileTransformation{id: id, op: s.op}
+	return t, &profileDataset{id: id, t: t}, nil
+}
+
+// profileTransformation measures the tables of its parent and passes them on
+// to the transformations of its dataset unchanged.
+type profileTransformation struct {
+	id execute.DatasetID
+	op *operator
+	ts []execute.Transformation
+}
+
+func (t *profileTransformation) RetractTable(_ execute.DatasetID, key flux.GroupKey) error {
+	for _, tr := range t.ts {
+		if err := tr.RetractTable(t.id, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *profileTransformation) Process(_ execute.DatasetID, tbl flux.Table) error {
+	t.op.addTable()
+	tbl = &countingTable{Table: tbl, op: t.op}
+	if len(t.ts) == 1 {
+		return t.ts[0].Process(t.id, tbl)
+	}
+
+	// Like the datasets of Flux, the table is buffered so every
+	// transformation can read it.
+	buf, err := execute.CopyTable(tbl)
+	if err != nil {
+		return err
+	}
+	defer buf.Done()
+	for _, tr := range t.ts {
+		if err := tr.Process(t.id, buf.Copy()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *profileTransformation) UpdateWatermark(_ execute.DatasetID, mark execute.Time) error {
+	for _, tr := range t.ts {
+		if err := tr.UpdateWatermark(t.id, mark); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *profileTransformation) UpdateProcessingTime(_ execute.DatasetID, pt execute.Time) error {
+	for _, tr := range t.ts {
+		if err := tr.UpdateProcessingTime(t.id, pt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *profileTransformation) Finish(_ execute.DatasetID, err error) {
+	t.op.finish()
+	for _, tr := range t.ts {
+		tr.Finish(t.id, err)
+	}
+}
+
+// profileDataset is the dataset of a profile transformation. It hands the
+// tables of the transformation to its successors as they arrive, so it
+// ignores triggers.
+type profileDataset struct {
+	id execute.DatasetID
+	t  *profileTransformation
+}
+
+func (d *profileDataset) AddTransformation(t execute.Transformation) {
+	d.t.ts = append(d.t.ts, t)
+}
+
+func (d *profileDataset) RetractTable(key flux.GroupKey) error {
+	return d.t.RetractTable(d.id, key)
+}
+
+func (d *profileDataset) UpdateProcessingTime(t execute.Time) error {
+	return d.t.UpdateProcessingTime(d.id, t)
+}
+
+func (d *profileDataset) UpdateWatermark(mark execute.Time) error {
+	return d.t.UpdateWatermark(d.id, mark)
+}
+
+func (d *profileDataset) Finish(err error) {
+	d.t.Finish(d.id, err)
+}
+
+func (d *profileDataset) SetTriggerSpec(plan.TriggerSpec) {}
+
+// countingTable counts the rows and bytes of a table as it is read. A table
+// is only counted the first time it is read.
+type countingTable struct {
+	flux.Table
+	op   *operator
+	read bool
+}
+
+func (t *countingTable) Do(f func(flux.ColReader) error) error {
+	if t.read {
+		return t.Table.Do(f)
+	}
+	t.read = true
+	return t.Table.Do(func(cr flux.ColReader) error {
+		t.op.addRows(cr)
+		return f(cr)
+	})
+}
+
+// profileQuery is the query of a profile program. It forwards the results
+// of the query followed by the profile result.
+type profileQuery struct {
+	flux.Query
+
+	results chan flux.Result
+	cancel  func()
+	err     error
+	wg      sync.WaitGroup
+}
+
+func (q *profileQuery) forward(ctx context.Context, prof flux.Result) {
+	defer q.wg.Done()
+	defer close(q.results)
+
+	for res := range q.Query.Results() {
+		select {
+		case q.results <- res:
+		case <-ctx.Done():
+			q.err = ctx.Err()
+			return
+		}
+	}
+	select {
+	case q.results <- prof:
+	case <-ctx.Done():
+		q.err = ctx.Err()
+	}
+}
+
+func (q *profileQuery) Results() <-chan flux.Result {
+	return q.results
+}
+
+func (q *profileQuery) Done() {
+	q.cancel()
+	q.Query.Done()
+	q.wg.Wait()
+}
+
+func (q *profileQuery) Cancel() {
+	q.cancel()
+	q.Query.Cancel()
+}
+
+func (q *profileQuery) Err() error {
+	if err := q.Query.Err(); err != nil {
+		return err
+	}
+	return q.err
+}
+
+// profileResult is the result holding the measurements of a profile. Its
+// table is built once every operation has finished.
+type profileResult struct {
+	ctx   context.Context
+	prof  *profile
+	alloc *memory.Allocator
+}
+
+func (r *profileResult) Name() string {
+	return ProfileResultName
+}
+
+func (r *profileResult) Tables() flux.TableIterator {
+	return r
+}
+
+func (r *profileResult) Do(f func(flux.Table) error) error {
+	for _, op := range r.prof.operators {
+		select {
+		case <-op.done:
+		case <-r.ctx.Done():
+			return r.ctx.Err()
+		}
+	}
+
+	tbl, err := r.table()
+	if err != nil {
+		return err
+	}
+	return f(tbl)
+}
+
+func (r *profileResult) table() (flux.Table, error) {
+	b := execute.NewColListTableBuilder(execute.NewGroupKey(nil, nil), r.alloc)
+	for _, col := range profileColumns {
+		if _, err := b.AddCol(col); err != nil {
+			return nil, err
+		}
+	}
+	for _, op := range r.prof.operators {
+		op.mu.Lock()
+		row := []values.Value{
+			values.NewString(op.id),
+			values.NewString(op.kind),
+			values.NewInt(op.tables),
+			values.NewInt(op.rows),
+			values.NewInt(op.bytes),
+			values.NewInt(r.prof.since(op.firstTable)),
+			values.NewInt(r.prof.since(op.finished)),
+		}
+		op.mu.Unlock()
+		for j, v := range row {
+			if err := b.AppendValue(j, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b.Table()
+}
+
+// since returns the nanoseconds between the start of the profile and t, or
+// zero if t is not set.
+func (p *profile) since(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return int64(t.Sub(p.start))
+}
@@ -0,0 +1,106 @@
+package explain_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/influxdb/query/explain"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCompileProfile(t *testing.T) {
+	q := `import "csv"
+csv.from(csv: data)
+  |> range(start: 2019-01-01T00:00:00Z, stop: 2019-01-02T00:00:00Z)
+  |> filter(fn: (r) => r._measurement == "m0")
+  |> yield(name: "out")`
+
+	ctx := context.Background()
+	prog, err := explain.CompileProfile(ctx, lang.FluxCompiler{
+		Query:  q,
+		Extern: extern(),
+		Now:    time.Unix(0, 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, ok := prog.(lang.DependenciesAwareProgram)
+	if !ok {
+		t.Fatalf("expected a program aware of dependencies, got %T", prog)
+	}
+	p.SetLogger(zaptest.NewLogger(t))
+
+	exec, err := prog.Start(ctx, &memory.Allocator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exec.Done()
+
+	var (
+		names   []string
+		outRows int
+		profile = make(map[string][]int64)
+	)
+	for res := range exec.Results() {
+		names = append(names, res.Name())
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				if res.Name() != explain.ProfileResultName {
+					outRows += cr.Len()
+					return nil
+				}
+				for i := 0; i < cr.Len(); i++ {
+					kind := cr.Strings(1).ValueString(i)
+					profile[kind] = []int64{
+						cr.Ints(2).Value(i),
+						cr.Ints(3).Value(i),
+						cr.Ints(4).Value(i),
+						cr.Ints(5).Value(i),
+						cr.Ints(6).Value(i),
+					}
+				}
+				return nil
+			})
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := exec.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(names) != 2 || names[0] != "out" || names[1] != explain.ProfileResultName {
+		t.Fatalf("unexpected results: %v", names)
+	}
+	if outRows != 2 {
+		t.Errorf("unexpected number of rows of result -got/+exp\n%d\n%d", outRows, 2)
+	}
+
+	for kind, exp := range map[string]struct{ tables, rows int64 }{
+		"fromCSV": {tables: 2, rows: 3},
+		"range":   {tables: 2, rows: 3},
+		"filter":  {tables: 2, rows: 2},
+	} {
+		got, ok := profile[kind]
+		if !ok {
+			t.Errorf("expected a profile of %s", kind)
+			continue
+		}
+		if got[0] != exp.tables || got[1] != exp.rows {
+			t.Errorf("unexpected tables and rows of %s -got/+exp\n%d %d\n%d %d", kind, got[0], got[1], exp.tables, exp.rows)
+		}
+		if got[2] <= 0 {
+			t.Errorf("expected bytes of %s, got %d", kind, got[2])
+		}
+		if got[4] <= 0 || got[3] > got[4] {
+			t.Errorf("unexpected durations of %s: first table %d, total %d", kind, got[3], got[4])
+		}
+	}
+	if len(profile) != 3 {
+		t.Errorf("unexpected operators of profile: %v", profile)
+	}
+}
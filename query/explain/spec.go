@@ -0,0 +1,187 @@
+package explain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb"
+	"github.com/opentracing/opentracing-go"
+)
+
+const nowOption = "now"
+
+// source is the AST of a Flux query and the time it is evaluated at.
+type source struct {
+	pkg *ast.Package
+	now time.Time
+}
+
+// sourceOf returns the source of the query compiled by compiler. Only the
+// Flux and AST compilers are supported.
+func sourceOf(compiler flux.Compiler) (*source, error) {
+	var (
+		pkg    *ast.Package
+		extern *ast.File
+		now    time.Time
+	)
+	switch c := compiler.(type) {
+	case lang.FluxCompiler:
+		pkg, extern, now = parser.ParseSource(c.Query), c.Extern, c.Now
+	case *lang.FluxCompiler:
+		pkg, extern, now = parser.ParseSource(c.Query), c.Extern, c.Now
+	case lang.ASTCompiler:
+		pkg, now = c.AST, c.Now
+	case *lang.ASTCompiler:
+		pkg, now = c.AST, c.Now
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("compiler type %q is not supported", compilerType(compiler)),
+		}
+	}
+	if pkg == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "query is empty",
+		}
+	}
+	if err := ast.GetError(pkg); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid query",
+			Err:  err,
+		}
+	}
+	if extern != nil {
+		// Copy the package so the files of the compiler are left untouched.
+		pkg = pkg.Copy().(*ast.Package)
+		pkg.Files = append([]*ast.File{extern}, pkg.Files...)
+	}
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return &source{pkg: pkg, now: now}, nil
+}
+
+func compilerType(compiler flux.Compiler) flux.CompilerType {
+	if compiler == nil {
+		return ""
+	}
+	return compiler.CompilerType()
+}
+
+// spec evaluates the source and returns the specification of the operations
+// of the query, in the same way the Flux programs do when they are started.
+func (s *source) spec(ctx context.Context) (*flux.Spec, error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "eval")
+	sideEffects, scope, err := flux.EvalAST(s.pkg, flux.SetOption(nowOption, nowFunc(s.now)))
+	span.Finish()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "error in evaluating query",
+			Err:  err,
+		}
+	}
+
+	now := s.now
+	if opt, ok := scope.Lookup(nowOption); ok {
+		v, err := opt.Function().Call(nil)
+		if err != nil {
+			return nil, err
+		}
+		now = v.Time().Time()
+	}
+
+	spec := toSpec(sideEffects, now)
+	if len(spec.Operations) == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "query returns no streaming data",
+		}
+	}
+	return spec, nil
+}
+
+func nowFunc(now time.Time) values.Function {
+	v := values.NewTime(values.ConvertTime(now))
+	typ := semantic.NewFunctionPolyType(semantic.FunctionPolySignature{
+		Return: semantic.Time,
+	})
+	return values.NewFunction(nowOption, typ, func(values.Object) (values.Value, error) {
+		return v, nil
+	}, false)
+}
+
+// toSpec builds the specification of the table objects among the side
+// effects of a query, skipping duplicate ones.
+func toSpec(sideEffects []interpreter.SideEffect, now time.Time) *flux.Spec {
+	ider := &ider{lookup: make(map[*flux.TableObject]flux.OperationID)}
+	spec := &flux.Spec{Now: now}
+	visited := make(map[*flux.TableObject]bool)
+	var objs []*flux.TableObject
+	for _, se := range sideEffects {
+		to, ok := se.Value.(*flux.TableObject)
+		if !ok {
+			continue
+		}
+		dup := false
+		for _, obj := range objs {
+			if to.Equal(obj) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			buildSpec(to, ider, spec, visited)
+			objs = append(objs, to)
+		}
+	}
+	return spec
+}
+
+func buildSpec(t *flux.TableObject, ider flux.IDer, spec *flux.Spec, visited map[*flux.TableObject]bool) {
+	// Parents are sorted by parameter name, so the operations are always
+	// added in the same order.
+	t.Parents.Range(func(i int, v values.Value) {
+		if p := v.(*flux.TableObject); !visited[p] {
+			buildSpec(p, ider, spec, visited)
+		}
+	})
+
+	id := ider.ID(t)
+	t.Parents.Range(func(i int, v values.Value) {
+		spec.Edges = append(spec.Edges, flux.Edge{
+			Parent: ider.ID(v.(*flux.TableObject)),
+			Child:  id,
+		})
+	})
+
+	visited[t] = true
+	spec.Operations = append(spec.Operations, t.Operation(ider))
+}
+
+// ider names the operations of table objects after their kind and the order
+// they are visited in, like the Flux programs do.
+type ider struct {
+	next   int
+	lookup map[*flux.TableObject]flux.OperationID
+}
+
+func (i *ider) ID(t *flux.TableObject) flux.OperationID {
+	id, ok := i.lookup[t]
+	if !ok {
+		id = flux.OperationID(fmt.Sprintf("%s%d", t.Kind, i.next))
+		i.next++
+		i.lookup[t] = id
+	}
+	return id
+}
@@ -0,0 +1,95 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/flux"
+	platform "github.com/influxdata/influxdb"
+)
+
+// JobStatus is the status of a query job.
+type JobStatus string
+
+const (
+	// JobQueued is the status of a job waiting to be executed.
+	JobQueued JobStatus = "queued"
+	// JobRunning is the status of a job whose query is executing.
+	JobRunning JobStatus = "running"
+	// JobSucceeded is the status of a job whose results have all been
+	// spooled.
+	JobSucceeded JobStatus = "success"
+	// JobFailed is the status of a job whose query failed, including when
+	// it was interrupted.
+	JobFailed JobStatus = "failed"
+)
+
+// Finished reports whether a job of the status has finished.
+func (s JobStatus) Finished() bool {
+	return s == JobSucceeded || s == JobFailed
+}
+
+// Job is a query submitted to be executed in the background. The results of
+// the query are kept in pages of rows until the job expires.
+type Job struct {
+	ID             platform.ID
+	OrganizationID platform.ID
+	// UserID is the user of the authorization that submitted the job, if
+	// any.
+	UserID platform.ID
+	Query  string
+	Status JobStatus
+	// Error is the error of the query of a failed job.
+	Error      string
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// ExpiresAt is when the job and its results are removed. It is set
+	// once the job has finished.
+	ExpiresAt time.Time
+	// Pages is the number of pages of results that can be read. It grows
+	// while the job runs.
+	Pages int
+	// Rows is the number of rows of the pages.
+	Rows int64
+}
+
+// JobFilter selects query jobs.
+type JobFilter struct {
+	OrganizationID *platform.ID
+}
+
+// JobService executes queries in the background and keeps their results.
+type JobService interface {
+	// SubmitJob submits the query of req to be executed and returns
+	// immediately.
+	SubmitJob(ctx context.Context, req *Request) (*Job, error)
+
+	// FindJobByID returns a single job by ID.
+	FindJobByID(ctx context.Context, id platform.ID) (*Job, error)
+
+	// FindJobs returns the jobs that match filter.
+	FindJobs(ctx context.Context, filter JobFilter) ([]*Job, error)
+
+	// ReadJobPage returns the results of a page of a job, numbered from 0.
+	// Release must be called on the returned results.
+	ReadJobPage(ctx context.Context, id platform.ID, page int) (flux.ResultIterator, error)
+
+	// DeleteJob cancels a job if it has not finished and removes it and its
+	// results.
+	DeleteJob(ctx context.Context, id platform.ID) error
+}
+
+// ErrJobNotFound is returned when a query job cannot be found, including
+// when it has expired.
+var ErrJobNotFound = &platform.Error{
+	Code: platform.ENotFound,
+	Msg:  "query job not found",
+}
+
+// ErrJobPageNotFound is returned when a page of results of a query job does
+// not exist, or does not exist yet.
+var ErrJobPageNotFound = &platform.Error{
+	Code: platform.ENotFound,
+	Msg:  "page of query job not found",
+}
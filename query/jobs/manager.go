@@ -0,0 +1,523 @@
+// Package jobs executes queries in the background and spools their results
+// to disk, so that they can be read in pages long after the request that
+// submitted the query has ended.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/snowflake"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultTTL is the default time the results of a job are kept after
+	// it has finished.
+	DefaultTTL = 24 * time.Hour
+
+	// DefaultPageSize is the default minimum number of rows of a page of
+	// results, other than the last one.
+	DefaultPageSize = 10000
+
+	// DefaultConcurrency is the default number of jobs executed
+	// concurrently.
+	DefaultConcurrency = 2
+
+	// DefaultCleanupInterval is the default interval at which expired jobs
+	// are removed.
+	DefaultCleanupInterval = time.Minute
+)
+
+// errInterrupted is the error of the jobs that had not finished when the
+// manager was closed.
+const errInterrupted = "query job was interrupted by a shutdown"
+
+var _ query.JobService = (*Manager)(nil)
+
+// Manager executes query jobs through a query service, which is usually the
+// query controller, so that jobs are subject to the same limits as any
+// other query. Since no one is waiting for their results, the queries of
+// jobs are executed with the background priority. Jobs wait for one of a
+// fixed number of slots before their query is submitted.
+//
+// Each job is kept in a directory within the path of the manager, holding
+// the description of the job and its pages of results, until the job
+// expires.
+type Manager struct {
+	// TTL is the time the results of a job are kept after it has finished.
+	TTL time.Duration
+
+	// PageSize is the minimum number of rows of a page of results, other
+	// than the last one.
+	PageSize int
+
+	// Concurrency is the number of jobs executed concurrently. It must be
+	// set before the manager is opened.
+	Concurrency int
+
+	// CleanupInterval is the interval at which expired jobs are removed.
+	CleanupInterval time.Duration
+
+	Logger *zap.Logger
+
+	path  string
+	qs    query.QueryService
+	now   func() time.Time
+	idGen influxdb.IDGenerator
+
+	ctx    context.Context
+	cancel func()
+	slots  chan struct{}
+	wg     sync.WaitGroup
+
+	mu   sync.RWMutex
+	jobs map[influxdb.ID]*job
+}
+
+// job is a job of the manager.
+type job struct {
+	query.Job
+	dir    string
+	cancel func()
+}
+
+// NewManager returns a Manager that executes jobs through qs and keeps them
+// in directories within path.
+func NewManager(path string, qs query.QueryService) *Manager {
+	return &Manager{
+		TTL:             DefaultTTL,
+		PageSize:        DefaultPageSize,
+		Concurrency:     DefaultConcurrency,
+		CleanupInterval: DefaultCleanupInterval,
+		Logger:          zap.NewNop(),
+		path:            path,
+		qs:              qs,
+		now:             time.Now,
+		idGen:           snowflake.NewIDGenerator(),
+		jobs:            make(map[influxdb.ID]*job),
+	}
+}
+
+// Open loads the jobs kept within the path of the manager and starts to
+// remove expired jobs. Jobs that had not finished are marked as failed,
+// their query cannot be resumed.
+func (m *Manager) Open(ctx context.Context) error {
+	if err := os.MkdirAll(m.path, 0700); err != nil {
+		return err
+	}
+	fis, err := ioutil.ReadDir(m.path)
+	if err != nil {
+		return err
+	}
+
+	now := m.now()
+	for _, fi := range fis {
+		if !fi.IsDir() {
+			continue
+		}
+		dir := filepath.Join(m.path, fi.Name())
+		j, err := loadJob(dir)
+		if err != nil {
+			// The job was not completely submitted.
+			m.Logger.Info("Removing unreadable query job", zap.String("path", dir), zap.Error(err))
+			os.RemoveAll(dir)
+			continue
+		}
+		if !j.Status.Finished() {
+			m.finished(j, now, errInterrupted)
+			m.save(j)
+		}
+		if !j.ExpiresAt.After(now) {
+			os.RemoveAll(dir)
+			continue
+		}
+		m.jobs[j.ID] = j
+	}
+
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.slots = make(chan struct{}, m.Concurrency)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.cleanup()
+	}()
+	return nil
+}
+
+// Close cancels the jobs that have not finished and waits for them to stop.
+// They are failed when the manager is opened again.
+func (m *Manager) Close() error {
+	if m.cancel == nil {
+		return nil
+	}
+	m.cancel()
+	m.wg.Wait()
+	return nil
+}
+
+// SubmitJob creates a job for the query of req and starts it once a slot is
+// available.
+func (m *Manager) SubmitJob(ctx context.Context, req *query.Request) (*query.Job, error) {
+	if !req.OrganizationID.Valid() {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "query job requires an organization",
+		}
+	}
+
+	j := &job{
+		Job: query.Job{
+			ID:             m.idGen.ID(),
+			OrganizationID: req.OrganizationID,
+			Query:          req.Text(),
+			Status:         query.JobQueued,
+			CreatedAt:      m.now(),
+		},
+	}
+	if req.Authorization != nil {
+		j.UserID = req.Authorization.UserID
+	}
+	j.dir = filepath.Join(m.path, j.ID.String())
+
+	if err := os.Mkdir(j.dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := m.save(j); err != nil {
+		os.RemoveAll(j.dir)
+		return nil, err
+	}
+
+	jreq := *req
+	jreq.Priority = query.PriorityBackground
+
+	jctx, cancel := context.WithCancel(m.ctx)
+	j.cancel = cancel
+	if req.Authorization != nil {
+		// The query is authorized as if it was executed by the request.
+		jctx = icontext.SetAuthorizer(jctx, req.Authorization)
+	}
+
+	m.mu.Lock()
+	m.jobs[j.ID] = j
+	desc := j.Job
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel()
+		m.run(jctx, j, &jreq)
+	}()
+	return &desc, nil
+}
+
+// run executes the query of j and spools its results.
+func (m *Manager) run(ctx context.Context, j *job, req *query.Request) {
+	select {
+	case m.slots <- struct{}{}:
+		defer func() { <-m.slots }()
+	case <-ctx.Done():
+		m.finish(j, ctx.Err())
+		return
+	}
+
+	m.update(j, func(j *job) {
+		j.Status = query.JobRunning
+		j.StartedAt = m.now()
+	})
+
+	results, err := m.qs.Query(ctx, req)
+	if err != nil {
+		m.finish(j, err)
+		return
+	}
+	s := &spooler{
+		dir:      j.dir,
+		pageSize: m.PageSize,
+		committed: func(rows int) {
+			m.update(j, func(j *job) {
+				j.Pages++
+				j.Rows += int64(rows)
+			})
+		},
+	}
+	err = s.spool(results)
+	results.Release()
+	m.finish(j, err)
+}
+
+// finish marks j as finished with err.
+func (m *Manager) finish(j *job, err error) {
+	if err != nil {
+		log := m.Logger.Info
+		if m.ctx.Err() != nil {
+			// The manager is closing; the job is failed when it is opened
+			// again.
+			log = m.Logger.Debug
+		}
+		log("Query job failed", zap.Stringer("job_id", j.ID), zap.Error(err))
+	}
+	if m.ctx.Err() != nil {
+		return
+	}
+	m.update(j, func(j *job) {
+		var msg string
+		if err != nil {
+			msg = err.Error()
+		}
+		m.finished(j, m.now(), msg)
+	})
+}
+
+// finished sets the status of j to finished at now with the error msg, if
+// any.
+func (m *Manager) finished(j *job, now time.Time, msg string) {
+	j.Status = query.JobSucceeded
+	if msg != "" {
+		j.Status = query.JobFailed
+		j.Error = msg
+	}
+	j.FinishedAt = now
+	j.ExpiresAt = now.Add(m.TTL)
+}
+
+// update changes j with fn and saves it, unless it has been deleted.
+func (m *Manager) update(j *job, fn func(j *job)) {
+	m.mu.Lock()
+	fn(j)
+	_, ok := m.jobs[j.ID]
+	saved := *j
+	m.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	if err := m.save(&saved); err != nil {
+		m.Logger.Info("Failed to save query job", zap.Stringer("job_id", j.ID), zap.Error(err))
+	}
+}
+
+// FindJobByID returns the job with the given ID.
+func (m *Manager) FindJobByID(ctx context.Context, id influxdb.ID) (*query.Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, query.ErrJobNotFound
+	}
+	desc := j.Job
+	return &desc, nil
+}
+
+// FindJobs returns the jobs that match filter, ordered by ID.
+func (m *Manager) FindJobs(ctx context.Context, filter query.JobFilter) ([]*query.Job, error) {
+	m.mu.RLock()
+	js := make([]*query.Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		if filter.OrganizationID != nil && j.OrganizationID != *filter.OrganizationID {
+			continue
+		}
+		desc := j.Job
+		js = append(js, &desc)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(js, func(i, k int) bool {
+		return js[i].ID < js[k].ID
+	})
+	return js, nil
+}
+
+// ReadJobPage returns the results of page n of the job with the given ID.
+func (m *Manager) ReadJobPage(ctx context.Context, id influxdb.ID, n int) (flux.ResultIterator, error) {
+	m.mu.RLock()
+	j, ok := m.jobs[id]
+	var pages int
+	if ok {
+		pages = j.Pages
+	}
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, query.ErrJobNotFound
+	}
+	if n < 0 || n >= pages {
+		return nil, query.ErrJobPageNotFound
+	}
+
+	f, err := os.Open(pagePath(j.dir, n))
+	if os.IsNotExist(err) {
+		// The job was removed since.
+		return nil, query.ErrJobNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	results, err := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{}).Decode(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &pageResultIterator{ResultIterator: results, f: f}, nil
+}
+
+// pageResultIterator closes the file of a page once its results are
+// released.
+type pageResultIterator struct {
+	flux.ResultIterator
+	f *os.File
+}
+
+func (it *pageResultIterator) Release() {
+	it.ResultIterator.Release()
+	it.f.Close()
+}
+
+// DeleteJob cancels the job with the given ID and removes it.
+func (m *Manager) DeleteJob(ctx context.Context, id influxdb.ID) error {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	delete(m.jobs, id)
+	m.mu.Unlock()
+
+	if !ok {
+		return query.ErrJobNotFound
+	}
+	if j.cancel != nil {
+		j.cancel()
+	}
+	return os.RemoveAll(j.dir)
+}
+
+// cleanup removes expired jobs until the manager is closed.
+func (m *Manager) cleanup() {
+	ticker := time.NewTicker(m.CleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.removeExpired()
+		}
+	}
+}
+
+func (m *Manager) removeExpired() {
+	now := m.now()
+	var expired []*job
+	m.mu.Lock()
+	for id, j := range m.jobs {
+		if j.Status.Finished() && !j.ExpiresAt.After(now) {
+			expired = append(expired, j)
+			delete(m.jobs, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, j := range expired {
+		if err := os.RemoveAll(j.dir); err != nil {
+			m.Logger.Info("Failed to remove expired query job", zap.Stringer("job_id", j.ID), zap.Error(err))
+		}
+	}
+}
+
+// jobFile is the description of a job kept in its directory. IDs are kept
+// as strings, since jobs submitted without an authorization have no user.
+type jobFile struct {
+	ID         string          `json:"id"`
+	OrgID      string          `json:"orgID"`
+	UserID     string          `json:"userID,omitempty"`
+	Query      string          `json:"query"`
+	Status     query.JobStatus `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt time.Time       `json:"finishedAt"`
+	ExpiresAt  time.Time       `json:"expiresAt"`
+	Pages      int             `json:"pages"`
+	Rows       int64           `json:"rows"`
+}
+
+const jobFileName = "job.json"
+
+// save writes the description of j into its directory.
+func (m *Manager) save(j *job) error {
+	jf := jobFile{
+		ID:         j.ID.String(),
+		OrgID:      j.OrganizationID.String(),
+		Query:      j.Query,
+		Status:     j.Status,
+		Error:      j.Error,
+		CreatedAt:  j.CreatedAt,
+		StartedAt:  j.StartedAt,
+		FinishedAt: j.FinishedAt,
+		ExpiresAt:  j.ExpiresAt,
+		Pages:      j.Pages,
+		Rows:       j.Rows,
+	}
+	if j.UserID.Valid() {
+		jf.UserID = j.UserID.String()
+	}
+	octets, err := json.Marshal(jf)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(j.dir, jobFileName)
+	if err := ioutil.WriteFile(path+".tmp", octets, 0600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// loadJob reads the description of the job kept in dir.
+func loadJob(dir string) (*job, error) {
+	octets, err := ioutil.ReadFile(filepath.Join(dir, jobFileName))
+	if err != nil {
+		return nil, err
+	}
+	var jf jobFile
+	if err := json.Unmarshal(octets, &jf); err != nil {
+		return nil, err
+	}
+
+	j := &job{
+		Job: query.Job{
+			Query:      jf.Query,
+			Status:     jf.Status,
+			Error:      jf.Error,
+			CreatedAt:  jf.CreatedAt,
+			StartedAt:  jf.StartedAt,
+			FinishedAt: jf.FinishedAt,
+			ExpiresAt:  jf.ExpiresAt,
+			Pages:      jf.Pages,
+			Rows:       jf.Rows,
+		},
+		dir: dir,
+	}
+	if err := j.ID.DecodeFromString(jf.ID); err != nil {
+		return nil, err
+	}
+	if err := j.OrganizationID.DecodeFromString(jf.OrgID); err != nil {
+		return nil, err
+	}
+	if jf.UserID != "" {
+		if err := j.UserID.DecodeFromString(jf.UserID); err != nil {
+			return nil, err
+		}
+	}
+	return j, nil
+}
@@ -0,0 +1,341 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/check"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/jobs"
+)
+
+// queryService is a fake query service that returns results, or blocks
+// until it is unblocked or its context is canceled.
+type queryService struct {
+	results func() []flux.Result
+	err     error
+	block   chan struct{}
+}
+
+func (s *queryService) Check(ctx context.Context) check.Response {
+	return check.Response{Name: "query", Status: check.StatusPass}
+}
+
+func (s *queryService) Query(ctx context.Context, req *query.Request) (flux.ResultIterator, error) {
+	if s.block != nil {
+		select {
+		case <-s.block:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return flux.NewSliceResultIterator(s.results()), nil
+}
+
+// cpu returns a result of three tables of two rows.
+func cpu() []flux.Result {
+	res := &executetest.Result{Nm: "_result"}
+	for _, host := range []string{"a", "b", "c"} {
+		res.Tbls = append(res.Tbls, &executetest.Table{
+			KeyCols: []string{"host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "host", Type: flux.TString},
+				{Label: "_value", Type: flux.TFloat},
+			},
+			Data: [][]interface{}{
+				{host, 1.0},
+				{host, 2.0},
+			},
+		})
+	}
+	return []flux.Result{res}
+}
+
+func newRequest() *query.Request {
+	return &query.Request{
+		OrganizationID: 0x1,
+		Authorization:  &influxdb.Authorization{UserID: 0x2},
+	}
+}
+
+func mustTempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "query-jobs-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func mustOpen(t *testing.T, m *jobs.Manager) {
+	t.Helper()
+	if err := m.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// wait waits for the job with the given ID to finish.
+func wait(t *testing.T, m *jobs.Manager, id influxdb.ID) *query.Job {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		j, err := m.FindJobByID(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if j.Status.Finished() {
+			return j
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for query job to finish")
+	return nil
+}
+
+// readPage returns the number of tables and rows of a page.
+func readPage(t *testing.T, m *jobs.Manager, id influxdb.ID, n int) (tables, rows int) {
+	t.Helper()
+	results, err := m.ReadJobPage(context.Background(), id, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer results.Release()
+	for results.More() {
+		if err := results.Next().Tables().Do(func(tbl flux.Table) error {
+			tables++
+			return tbl.Do(func(cr flux.ColReader) error {
+				rows += cr.Len()
+				return nil
+			})
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := results.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return tables, rows
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	m := jobs.NewManager(dir, &queryService{results: cpu})
+	m.PageSize = 3
+	mustOpen(t, m)
+	defer m.Close()
+
+	j, err := m.SubmitJob(ctx, newRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Status != query.JobQueued || j.UserID != 0x2 {
+		t.Fatalf("unexpected submitted job %+v", j)
+	}
+
+	j = wait(t, m, j.ID)
+	if j.Status != query.JobSucceeded || j.Error != "" {
+		t.Fatalf("unexpected status %q: %s", j.Status, j.Error)
+	}
+	if j.Pages != 2 || j.Rows != 6 {
+		t.Fatalf("unexpected pages and rows -got/+exp\n%d %d\n%d %d", j.Pages, j.Rows, 2, 6)
+	}
+	if got := j.ExpiresAt.Sub(j.FinishedAt); got != jobs.DefaultTTL {
+		t.Errorf("unexpected TTL -got/+exp\n%v\n%v", got, jobs.DefaultTTL)
+	}
+
+	// The page ends after the buffer that fills it.
+	if tables, rows := readPage(t, m, j.ID, 0); tables != 2 || rows != 4 {
+		t.Errorf("unexpected page 0 -got/+exp\n%d %d\n%d %d", tables, rows, 2, 4)
+	}
+	if tables, rows := readPage(t, m, j.ID, 1); tables != 1 || rows != 2 {
+		t.Errorf("unexpected page 1 -got/+exp\n%d %d\n%d %d", tables, rows, 1, 2)
+	}
+	if _, err := m.ReadJobPage(ctx, j.ID, 2); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected page not found, got %v", err)
+	}
+
+	orgID := influxdb.ID(0x3)
+	if js, err := m.FindJobs(ctx, query.JobFilter{OrganizationID: &orgID}); err != nil || len(js) != 0 {
+		t.Errorf("expected no jobs of other organization, got %v %v", js, err)
+	}
+	if js, err := m.FindJobs(ctx, query.JobFilter{}); err != nil || len(js) != 1 {
+		t.Errorf("expected a job, got %v %v", js, err)
+	}
+
+	if err := m.DeleteJob(ctx, j.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.FindJobByID(ctx, j.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected job not found, got %v", err)
+	}
+	if _, err := os.Stat(dir + "/" + j.ID.String()); !os.IsNotExist(err) {
+		t.Errorf("expected directory of job to be removed, got %v", err)
+	}
+}
+
+func TestManager_Permissions(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	// The spool holds query results, which only the server may read.
+	path := filepath.Join(dir, "jobs")
+	m := jobs.NewManager(path, &queryService{results: cpu})
+	m.PageSize = 3
+	mustOpen(t, m)
+	defer m.Close()
+
+	j, err := m.SubmitJob(context.Background(), newRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wait(t, m, j.ID)
+
+	if err := filepath.Walk(path, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		exp := os.FileMode(0600)
+		if fi.IsDir() {
+			exp = 0700
+		}
+		if got := fi.Mode().Perm(); got != exp {
+			t.Errorf("unexpected permissions of %s -got/+exp\n%v\n%v", p, got, exp)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManager_Failed(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	m := jobs.NewManager(dir, &queryService{err: errors.New("expected error")})
+	mustOpen(t, m)
+	defer m.Close()
+
+	j, err := m.SubmitJob(context.Background(), newRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	j = wait(t, m, j.ID)
+	if j.Status != query.JobFailed || j.Error != "expected error" {
+		t.Fatalf("unexpected status %q: %s", j.Status, j.Error)
+	}
+}
+
+func TestManager_DeleteRunning(t *testing.T) {
+	ctx := context.Background()
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	m := jobs.NewManager(dir, &queryService{results: cpu, block: make(chan struct{})})
+	mustOpen(t, m)
+	defer m.Close()
+
+	j, err := m.SubmitJob(ctx, newRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.DeleteJob(ctx, j.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.DeleteJob(ctx, j.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected job not found, got %v", err)
+	}
+}
+
+func TestManager_Reopen(t *testing.T) {
+	ctx := context.Background()
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	qs := &queryService{results: cpu}
+	m := jobs.NewManager(dir, qs)
+	mustOpen(t, m)
+	done, err := m.SubmitJob(ctx, newRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wait(t, m, done.ID)
+
+	// A job that is running when the manager is closed is failed when it is
+	// opened again.
+	qs.block = make(chan struct{})
+	running, err := m.SubmitJob(ctx, newRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	m = jobs.NewManager(dir, qs)
+	mustOpen(t, m)
+	defer m.Close()
+
+	j, err := m.FindJobByID(ctx, done.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Status != query.JobSucceeded || j.Pages != 1 || j.UserID != 0x2 {
+		t.Errorf("unexpected reopened job %+v", j)
+	}
+	if tables, rows := readPage(t, m, j.ID, 0); tables != 3 || rows != 6 {
+		t.Errorf("unexpected page 0 -got/+exp\n%d %d\n%d %d", tables, rows, 3, 6)
+	}
+
+	j, err = m.FindJobByID(ctx, running.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Status != query.JobFailed || j.Error == "" {
+		t.Errorf("expected interrupted job to fail, got %+v", j)
+	}
+}
+
+func TestManager_Expired(t *testing.T) {
+	ctx := context.Background()
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	m := jobs.NewManager(dir, &queryService{results: cpu})
+	m.TTL = time.Millisecond
+	m.CleanupInterval = 10 * time.Millisecond
+	mustOpen(t, m)
+	defer m.Close()
+
+	j, err := m.SubmitJob(ctx, newRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The job is removed from the manager before its directory.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, err := os.Stat(dir + "/" + j.ID.String()); os.IsNotExist(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for query job to expire")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := m.FindJobByID(ctx, j.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected job not found, got %v", err)
+	}
+}
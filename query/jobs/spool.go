@@ -0,0 +1,190 @@
+package jobs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+)
+
+// pagePath returns the path of the file of page n of the results of the job
+// in dir.
+func pagePath(dir string, n int) string {
+	return filepath.Join(dir, fmt.Sprintf("%08d.csv", n))
+}
+
+// spooler writes the results of a query into files of pages of at least
+// pageSize rows, unless it is the last page. A page ends after the buffer of
+// rows that fills it, so tables may be split across pages.
+//
+// Pages are annotated CSV, so they can be decoded into results and encoded
+// in any dialect when they are read.
+type spooler struct {
+	dir      string
+	pageSize int
+	// committed is called once a page of rows has been written.
+	committed func(rows int)
+
+	pages   int
+	rows    int
+	results []*pageResult
+}
+
+func (s *spooler) spool(results flux.ResultIterator) error {
+	defer s.release()
+	for results.More() {
+		res := results.Next()
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			pt := s.table(res.Name(), tbl)
+			return tbl.Do(func(cr flux.ColReader) error {
+				if pt == nil {
+					pt = s.table(res.Name(), tbl)
+				}
+				cr.Retain()
+				pt.readers = append(pt.readers, cr)
+				s.rows += cr.Len()
+				if s.rows < s.pageSize {
+					return nil
+				}
+				pt = nil
+				return s.flush()
+			})
+		}); err != nil {
+			return err
+		}
+	}
+	if err := results.Err(); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+// table returns a table of the current page to hold the rows of tbl.
+func (s *spooler) table(name string, tbl flux.Table) *pageTable {
+	if n := len(s.results); n == 0 || s.results[n-1].name != name {
+		s.results = append(s.results, &pageResult{name: name})
+	}
+	res := s.results[len(s.results)-1]
+	pt := &pageTable{key: tbl.Key(), cols: tbl.Cols()}
+	res.tables = append(res.tables, pt)
+	return pt
+}
+
+// flush writes the current page, if it has any tables.
+func (s *spooler) flush() error {
+	if len(s.results) == 0 {
+		return nil
+	}
+
+	// The page is written to a temporary file first, so that only complete
+	// pages can be read.
+	path := pagePath(s.dir, s.pages)
+	if err := s.write(path + ".tmp"); err != nil {
+		os.Remove(path + ".tmp")
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+
+	rows := s.rows
+	s.release()
+	s.pages++
+	s.committed(rows)
+	return nil
+}
+
+func (s *spooler) write(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	rs := make([]flux.Result, len(s.results))
+	for i, r := range s.results {
+		rs[i] = r
+	}
+	enc := csv.NewMultiResultEncoder(csv.DefaultEncoderConfig())
+	if _, err := enc.Encode(f, flux.NewSliceResultIterator(rs)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// release releases the buffers of the current page and empties it.
+func (s *spooler) release() {
+	for _, r := range s.results {
+		for _, t := range r.tables {
+			t.Done()
+		}
+	}
+	s.results = nil
+	s.rows = 0
+}
+
+// pageResult is a result of a page of results.
+type pageResult struct {
+	name   string
+	tables []*pageTable
+}
+
+func (r *pageResult) Name() string {
+	return r.name
+}
+
+func (r *pageResult) Tables() flux.TableIterator {
+	return r
+}
+
+func (r *pageResult) Do(f func(flux.Table) error) error {
+	for _, t := range r.tables {
+		if err := f(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pageTable is a table of the buffers of a table of the results that were
+// spooled into a page.
+type pageTable struct {
+	key     flux.GroupKey
+	cols    []flux.ColMeta
+	readers []flux.ColReader
+}
+
+func (t *pageTable) Key() flux.GroupKey {
+	return t.key
+}
+
+func (t *pageTable) Cols() []flux.ColMeta {
+	return t.cols
+}
+
+func (t *pageTable) Do(f func(flux.ColReader) error) error {
+	defer t.Done()
+	for _, cr := range t.readers {
+		if err := f(cr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *pageTable) Done() {
+	for _, cr := range t.readers {
+		cr.Release()
+	}
+	t.readers = nil
+}
+
+func (t *pageTable) Empty() bool {
+	for _, cr := range t.readers {
+		if cr.Len() > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -25,6 +25,8 @@ type Log struct {
 	ProxyRequest *ProxyRequest
 	// ResponseSize is the size in bytes of the query response
 	ResponseSize int64
+	// RowCount is the number of rows read from the results of the query
+	RowCount int64
 	// Statistics is a set of statistics about the query execution
 	Statistics flux.Statistics
 }
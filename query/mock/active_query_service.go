@@ -0,0 +1,44 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+var _ query.ActiveQueryService = (*ActiveQueryService)(nil)
+
+// ActiveQueryService is a mock implementation of a query.ActiveQueryService.
+type ActiveQueryService struct {
+	FindActiveQueryByIDFn func(ctx context.Context, id influxdb.ID) (*query.ActiveQuery, error)
+	FindActiveQueriesFn   func(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error)
+	CancelActiveQueryFn   func(ctx context.Context, id influxdb.ID) error
+}
+
+// NewActiveQueryService returns a mock ActiveQueryService where its methods
+// return zero values.
+func NewActiveQueryService() *ActiveQueryService {
+	return &ActiveQueryService{
+		FindActiveQueryByIDFn: func(ctx context.Context, id influxdb.ID) (*query.ActiveQuery, error) { return nil, nil },
+		FindActiveQueriesFn: func(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+			return nil, nil
+		},
+		CancelActiveQueryFn: func(ctx context.Context, id influxdb.ID) error { return nil },
+	}
+}
+
+// FindActiveQueryByID returns a single active query by ID.
+func (s *ActiveQueryService) FindActiveQueryByID(ctx context.Context, id influxdb.ID) (*query.ActiveQuery, error) {
+	return s.FindActiveQueryByIDFn(ctx, id)
+}
+
+// FindActiveQueries returns the active queries that match filter.
+func (s *ActiveQueryService) FindActiveQueries(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+	return s.FindActiveQueriesFn(ctx, filter)
+}
+
+// CancelActiveQuery cancels an active query.
+func (s *ActiveQueryService) CancelActiveQuery(ctx context.Context, id influxdb.ID) error {
+	return s.CancelActiveQueryFn(ctx, id)
+}
@@ -0,0 +1,59 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+var _ query.JobService = (*JobService)(nil)
+
+// JobService is a mock implementation of a query.JobService.
+type JobService struct {
+	SubmitJobFn   func(ctx context.Context, req *query.Request) (*query.Job, error)
+	FindJobByIDFn func(ctx context.Context, id influxdb.ID) (*query.Job, error)
+	FindJobsFn    func(ctx context.Context, filter query.JobFilter) ([]*query.Job, error)
+	ReadJobPageFn func(ctx context.Context, id influxdb.ID, page int) (flux.ResultIterator, error)
+	DeleteJobFn   func(ctx context.Context, id influxdb.ID) error
+}
+
+// NewJobService returns a mock JobService where its methods return zero
+// values.
+func NewJobService() *JobService {
+	return &JobService{
+		SubmitJobFn:   func(ctx context.Context, req *query.Request) (*query.Job, error) { return nil, nil },
+		FindJobByIDFn: func(ctx context.Context, id influxdb.ID) (*query.Job, error) { return nil, nil },
+		FindJobsFn:    func(ctx context.Context, filter query.JobFilter) ([]*query.Job, error) { return nil, nil },
+		ReadJobPageFn: func(ctx context.Context, id influxdb.ID, page int) (flux.ResultIterator, error) {
+			return nil, nil
+		},
+		DeleteJobFn: func(ctx context.Context, id influxdb.ID) error { return nil },
+	}
+}
+
+// SubmitJob submits a query job.
+func (s *JobService) SubmitJob(ctx context.Context, req *query.Request) (*query.Job, error) {
+	return s.SubmitJobFn(ctx, req)
+}
+
+// FindJobByID returns a single query job by ID.
+func (s *JobService) FindJobByID(ctx context.Context, id influxdb.ID) (*query.Job, error) {
+	return s.FindJobByIDFn(ctx, id)
+}
+
+// FindJobs returns the query jobs that match filter.
+func (s *JobService) FindJobs(ctx context.Context, filter query.JobFilter) ([]*query.Job, error) {
+	return s.FindJobsFn(ctx, filter)
+}
+
+// ReadJobPage returns the results of a page of a query job.
+func (s *JobService) ReadJobPage(ctx context.Context, id influxdb.ID, page int) (flux.ResultIterator, error) {
+	return s.ReadJobPageFn(ctx, id, page)
+}
+
+// DeleteJob deletes a query job.
+func (s *JobService) DeleteJob(ctx context.Context, id influxdb.ID) error {
+	return s.DeleteJobFn(ctx, id)
+}
@@ -49,7 +49,8 @@ func (a *preAuthorizer) PreAuthorize(ctx context.Context, ast *ast.Package, auth
 			return errors.Wrapf(err, "could not create read bucket permission")
 		}
 
-		if !auth.Allowed(*reqPerm) {
+		// Reads restricted to some of the data of the bucket are restricted by storage.
+		if _, ok := platform.AuthorizerRestrictions(auth, *reqPerm); !ok {
 			return errors.New("no read permission for bucket: \"" + bucket.Name + "\"")
 		}
 	}
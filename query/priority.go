@@ -0,0 +1,72 @@
+package query
+
+import (
+	"fmt"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+// Priority is the priority class of a query. Queries of a higher priority
+// class are executed before the queued queries of lower ones.
+type Priority int
+
+const (
+	// PriorityInteractive is the priority of queries that a user is waiting
+	// for, such as those of dashboards. It is the default.
+	PriorityInteractive Priority = iota
+	// PriorityTask is the priority of the queries of task runs.
+	PriorityTask
+	// PriorityBackground is the priority of queries that no one is waiting
+	// for.
+	PriorityBackground
+)
+
+// Priorities lists the priority classes from highest to lowest.
+var Priorities = []Priority{PriorityInteractive, PriorityTask, PriorityBackground}
+
+// String returns the name of the priority class.
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityTask:
+		return "task"
+	case PriorityBackground:
+		return "background"
+	default:
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+}
+
+// Valid reports whether p is one of the priority classes.
+func (p Priority) Valid() bool {
+	return p >= PriorityInteractive && p <= PriorityBackground
+}
+
+// ParsePriority returns the priority class of the given name.
+func ParsePriority(s string) (Priority, error) {
+	for _, p := range Priorities {
+		if p.String() == s {
+			return p, nil
+		}
+	}
+	return 0, &platform.Error{
+		Code: platform.EInvalid,
+		Msg:  fmt.Sprintf("unknown query priority %q; expected interactive, task or background", s),
+	}
+}
+
+// MarshalText encodes the priority class as its name.
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText decodes the priority class from its name.
+func (p *Priority) UnmarshalText(b []byte) error {
+	v, err := ParsePriority(string(b))
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
@@ -0,0 +1,252 @@
+// Package querylog writes the logs of finished queries to the query log
+// system bucket of their organizations, so slow or failing queries can be
+// found with Flux.
+//
+// Each log is a point of the "queries" measurement in the "_queries" bucket
+// of the organization of the query, which is created with a retention period
+// when the first query of the organization is logged, for example:
+//
+//	from(bucket: "_queries")
+//	  |> range(start: -1d)
+//	  |> filter(fn: (r) => r._measurement == "queries")
+//	  |> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+//
+// The point is written at the time the query finished and has the tags
+//
+//	orgID         the ID of the organization of the query
+//	userID        the ID of the user of the authorization of the query, if any
+//	compilerType  the type of the compiler of the query, such as "flux"
+//	status        "success" or "error"
+//
+// and the fields
+//
+//	queryHash        the hex encoded SHA-256 hash of the query text
+//	compileDuration  the nanoseconds spent compiling the query
+//	queueDuration    the nanoseconds spent waiting for execution
+//	executeDuration  the nanoseconds spent executing the query
+//	totalDuration    the nanoseconds between submitting and finishing the query
+//	rowCount         the number of rows read from the results
+//	responseSize     the number of bytes of the response, if known
+//	scannedBytes     the number of bytes read from storage
+//	scannedValues    the number of values read from storage
+//	maxAllocated     the maximum number of bytes of memory the query allocated
+//	error            the error of the query, if it failed
+package querylog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+const (
+	// BucketName is the name of the system bucket of each organization the
+	// logs of its queries are written to.
+	BucketName = "_queries"
+
+	// DefaultRetentionPeriod is the retention period of the query log buckets
+	// created by a Writer.
+	DefaultRetentionPeriod = 7 * 24 * time.Hour
+
+	// Measurement is the measurement of the logs.
+	Measurement = "queries"
+)
+
+// bucketTTL is how long the ID of the query log bucket of an organization is
+// cached before it is looked up again, in case the bucket was deleted.
+const bucketTTL = time.Minute
+
+const (
+	orgIDTag        = "orgID"
+	userIDTag       = "userID"
+	compilerTypeTag = "compilerType"
+	statusTag       = "status"
+
+	queryHashField       = "queryHash"
+	compileDurationField = "compileDuration"
+	queueDurationField   = "queueDuration"
+	executeDurationField = "executeDuration"
+	totalDurationField   = "totalDuration"
+	rowCountField        = "rowCount"
+	responseSizeField    = "responseSize"
+	scannedBytesField    = "scannedBytes"
+	scannedValuesField   = "scannedValues"
+	maxAllocatedField    = "maxAllocated"
+	errorField           = "error"
+)
+
+// Metadata keys of the statistics reported by the storage sources of a query.
+const (
+	scannedBytesKey  = "influxdb/scanned-bytes"
+	scannedValuesKey = "influxdb/scanned-values"
+)
+
+var _ query.Logger = (*Writer)(nil)
+
+// Writer is a query.Logger that writes a sample of the logs of queries to
+// the query log bucket of their organizations.
+type Writer struct {
+	pw         storage.PointsWriter
+	buckets    influxdb.BucketService
+	retention  time.Duration
+	sampleRate float64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+
+	bucketsMu sync.Mutex
+	bucketIDs map[influxdb.ID]cachedBucketID
+}
+
+type cachedBucketID struct {
+	id      influxdb.ID
+	expires time.Time
+}
+
+// NewWriter returns a Writer that writes the given fraction of the logs it is
+// given with pw. A sample rate of 1 writes every log. The query log buckets
+// are found, or created with the given retention period, in buckets.
+func NewWriter(pw storage.PointsWriter, buckets influxdb.BucketService, retention time.Duration, sampleRate float64) *Writer {
+	return &Writer{
+		pw:         pw,
+		buckets:    buckets,
+		retention:  retention,
+		sampleRate: sampleRate,
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
+		bucketIDs:  make(map[influxdb.ID]cachedBucketID),
+	}
+}
+
+// Log writes l to the query log bucket of its organization, unless it is not
+// part of the sample.
+func (w *Writer) Log(l query.Log) error {
+	if !w.sample() || !l.OrganizationID.Valid() {
+		return nil
+	}
+
+	ctx := context.Background()
+	bucketID, err := w.bucketID(ctx, l.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	pt, err := newPoint(l)
+	if err != nil {
+		return err
+	}
+	points, err := tsdb.ExplodePoints(l.OrganizationID, bucketID, models.Points{pt})
+	if err != nil {
+		return err
+	}
+	return w.pw.WritePoints(ctx, points)
+}
+
+// bucketID returns the ID of the query log bucket of the organization,
+// creating the bucket if it does not exist.
+func (w *Writer) bucketID(ctx context.Context, orgID influxdb.ID) (influxdb.ID, error) {
+	now := time.Now()
+	w.bucketsMu.Lock()
+	defer w.bucketsMu.Unlock()
+
+	if c, ok := w.bucketIDs[orgID]; ok && now.Before(c.expires) {
+		return c.id, nil
+	}
+
+	name := BucketName
+	b, err := w.buckets.FindBucket(ctx, influxdb.BucketFilter{
+		OrganizationID: &orgID,
+		Name:           &name,
+	})
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		b = &influxdb.Bucket{
+			OrgID:           orgID,
+			Name:            BucketName,
+			Description:     "Logs of the queries of the organization",
+			RetentionPeriod: w.retention,
+		}
+		err = w.buckets.CreateBucket(ctx, b)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	w.bucketIDs[orgID] = cachedBucketID{id: b.ID, expires: now.Add(bucketTTL)}
+	return b.ID, nil
+}
+
+func (w *Writer) sample() bool {
+	if w.sampleRate >= 1 {
+		return true
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.rand.Float64() < w.sampleRate
+}
+
+func newPoint(l query.Log) (models.Point, error) {
+	status := "success"
+	if l.Error != nil {
+		status = "error"
+	}
+	tags := map[string]string{
+		orgIDTag:  l.OrganizationID.String(),
+		statusTag: status,
+	}
+
+	stats := l.Statistics
+	fields := map[string]interface{}{
+		compileDurationField: int64(stats.CompileDuration),
+		queueDurationField:   int64(stats.QueueDuration),
+		executeDurationField: int64(stats.ExecuteDuration),
+		totalDurationField:   int64(stats.TotalDuration),
+		rowCountField:        l.RowCount,
+		responseSizeField:    l.ResponseSize,
+		scannedBytesField:    sumMetadata(stats.Metadata, scannedBytesKey),
+		scannedValuesField:   sumMetadata(stats.Metadata, scannedValuesKey),
+		maxAllocatedField:    stats.MaxAllocated,
+	}
+	if l.Error != nil {
+		fields[errorField] = l.Error.Error()
+	}
+
+	if req := l.ProxyRequest; req != nil {
+		if auth := req.Request.Authorization; auth != nil && auth.UserID.Valid() {
+			tags[userIDTag] = auth.UserID.String()
+		}
+		if req.Request.Compiler != nil {
+			tags[compilerTypeTag] = string(req.Request.Compiler.CompilerType())
+		}
+		sum := sha256.Sum256([]byte(req.Request.Text()))
+		fields[queryHashField] = hex.EncodeToString(sum[:])
+	}
+
+	t := l.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return models.NewPoint(Measurement, models.NewTags(tags), fields, t)
+}
+
+// sumMetadata returns the sum of the integer values of key in metadata.
+func sumMetadata(metadata flux.Metadata, key string) int64 {
+	var sum int64
+	for _, v := range metadata[key] {
+		switch v := v.(type) {
+		case int:
+			sum += int64(v)
+		case int64:
+			sum += v
+		}
+	}
+	return sum
+}
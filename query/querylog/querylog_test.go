@@ -0,0 +1,152 @@
+package querylog_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/querylog"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func newLog(orgID influxdb.ID) query.Log {
+	return query.Log{
+		Time:           time.Unix(100, 0),
+		OrganizationID: orgID,
+		Error:          errors.New("expected"),
+		ProxyRequest: &query.ProxyRequest{
+			Request: query.Request{
+				Authorization:  &influxdb.Authorization{UserID: 0x2},
+				OrganizationID: orgID,
+				Compiler:       lang.FluxCompiler{Query: `from(bucket: "b")`},
+			},
+		},
+		RowCount: 3,
+		Statistics: flux.Statistics{
+			CompileDuration: time.Second,
+			QueueDuration:   2 * time.Second,
+			ExecuteDuration: 3 * time.Second,
+			TotalDuration:   6 * time.Second,
+			Metadata: flux.Metadata{
+				"influxdb/scanned-bytes": []interface{}{10, 20},
+			},
+		},
+	}
+}
+
+// newBucketService returns a bucket service holding the query log bucket of
+// the organizations it is asked to create it for.
+func newBucketService() (*mock.BucketService, *[]*influxdb.Bucket) {
+	var created []*influxdb.Bucket
+	s := mock.NewBucketService()
+	s.FindBucketFn = func(ctx context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		for _, b := range created {
+			if b.OrgID == *filter.OrganizationID && b.Name == *filter.Name {
+				return b, nil
+			}
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+	}
+	s.CreateBucketFn = func(ctx context.Context, b *influxdb.Bucket) error {
+		b.ID = influxdb.ID(0x100 + len(created))
+		created = append(created, b)
+		return nil
+	}
+	return s, &created
+}
+
+func TestWriter_Log(t *testing.T) {
+	orgID := influxdb.ID(0x1)
+	pw := &mock.PointsWriter{}
+	buckets, created := newBucketService()
+	w := querylog.NewWriter(pw, buckets, time.Hour, 1)
+	if err := w.Log(newLog(orgID)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Log(newLog(orgID)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*created) != 1 {
+		t.Fatalf("expected one query log bucket to be created, got %d", len(*created))
+	}
+	b := (*created)[0]
+	if b.OrgID != orgID || b.Name != querylog.BucketName || b.RetentionPeriod != time.Hour {
+		t.Errorf("unexpected query log bucket %+v", b)
+	}
+
+	fields := make(map[string]interface{})
+	for _, pt := range pw.Points {
+		gotOrg, gotBucket := tsdb.DecodeNameSlice(pt.Name())
+		if gotOrg != orgID || gotBucket != b.ID {
+			t.Fatalf("unexpected org and bucket of point -got/+exp\n%v %v\n%v %v", gotOrg, gotBucket, orgID, b.ID)
+		}
+		tags := pt.Tags()
+		for k, exp := range map[string]string{
+			models.MeasurementTagKey: querylog.Measurement,
+			"orgID":                  orgID.String(),
+			"userID":                 influxdb.ID(0x2).String(),
+			"compilerType":           "flux",
+			"status":                 "error",
+		} {
+			if got := string(tags.Get([]byte(k))); got != exp {
+				t.Errorf("unexpected %s tag -got/+exp\n%s\n%s", k, got, exp)
+			}
+		}
+		if !pt.Time().Equal(time.Unix(100, 0)) {
+			t.Errorf("unexpected time of point: %v", pt.Time())
+		}
+
+		iter := pt.FieldIterator()
+		for iter.Next() {
+			switch iter.Type() {
+			case models.Integer:
+				v, err := iter.IntegerValue()
+				if err != nil {
+					t.Fatal(err)
+				}
+				fields[string(iter.FieldKey())] = v
+			case models.String:
+				fields[string(iter.FieldKey())] = iter.StringValue()
+			}
+		}
+	}
+
+	for k, exp := range map[string]interface{}{
+		"compileDuration": int64(time.Second),
+		"queueDuration":   int64(2 * time.Second),
+		"executeDuration": int64(3 * time.Second),
+		"totalDuration":   int64(6 * time.Second),
+		"rowCount":        int64(3),
+		"scannedBytes":    int64(30),
+		"error":           "expected",
+	} {
+		if got := fields[k]; got != exp {
+			t.Errorf("unexpected %s field -got/+exp\n%v\n%v", k, got, exp)
+		}
+	}
+	if hash, _ := fields["queryHash"].(string); len(hash) != 64 {
+		t.Errorf("expected a query hash, got %q", hash)
+	}
+}
+
+func TestWriter_Log_Sample(t *testing.T) {
+	pw := &mock.PointsWriter{}
+	buckets, _ := newBucketService()
+	w := querylog.NewWriter(pw, buckets, time.Hour, 0)
+	for i := 0; i < 10; i++ {
+		if err := w.Log(newLog(influxdb.ID(0x1))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(pw.Points) != 0 {
+		t.Errorf("expected no logs to be sampled, got %d points", len(pw.Points))
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
 	platform "github.com/influxdata/influxdb"
 )
 
@@ -20,10 +22,39 @@ type Request struct {
 	// Compiler converts the query to a specification to run against the data.
 	Compiler flux.Compiler `json:"compiler"`
 
+	// Priority is the priority class the query is scheduled with.
+	Priority Priority `json:"priority,omitempty"`
+
+	// Profile requests a profile of the execution of the query to be
+	// returned as an extra result.
+	Profile bool `json:"profile,omitempty"`
+
 	// compilerMappings maps compiler types to creation methods
 	compilerMappings flux.CompilerMappings
 }
 
+// Text returns the text of the Flux query the request compiles, if any.
+func (r *Request) Text() string {
+	switch c := r.Compiler.(type) {
+	case lang.FluxCompiler:
+		return c.Query
+	case *lang.FluxCompiler:
+		return c.Query
+	case lang.ASTCompiler:
+		return formatAST(c.AST)
+	case *lang.ASTCompiler:
+		return formatAST(c.AST)
+	}
+	return ""
+}
+
+func formatAST(pkg *ast.Package) string {
+	if pkg == nil {
+		return ""
+	}
+	return ast.Format(pkg)
+}
+
 // WithCompilerMappings sets the query type mappings on the request.
 func (r *Request) WithCompilerMappings(mappings flux.CompilerMappings) {
 	r.compilerMappings = mappings
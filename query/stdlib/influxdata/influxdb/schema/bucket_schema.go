@@ -0,0 +1,221 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/semantic"
+	fluxinfluxdb "github.com/influxdata/flux/stdlib/influxdata/influxdb"
+	"github.com/influxdata/flux/values"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
+)
+
+// BucketSchemaKind is the kind of the bucketSchema function, which reads the
+// explicit schema of a bucket instead of the index of the storage engine.
+const BucketSchemaKind = "schemaBucketSchema"
+
+// bucketSchemaTagType is the type of the rows of tag keys.
+const bucketSchemaTagType = "tag"
+
+func registerBucketSchema() {
+	signature := semantic.FunctionPolySignature{
+		Parameters: map[string]semantic.PolyType{
+			"bucket":   semantic.String,
+			"bucketID": semantic.String,
+		},
+		Return: flux.TableObjectType,
+	}
+	fn := flux.FunctionValue(BucketSchemaKind, createBucketSchemaOpSpec, signature)
+	flux.RegisterPackageValue(PackagePath, "bucketSchema", function{fn.(values.Function)})
+	flux.RegisterOpSpec(BucketSchemaKind, func() flux.OperationSpec {
+		return &BucketSchemaOpSpec{}
+	})
+	plan.RegisterProcedureSpec(BucketSchemaKind, newBucketSchemaProcedure, BucketSchemaKind)
+	execute.RegisterSource(BucketSchemaKind, createBucketSchemaSource)
+}
+
+// BucketSchemaOpSpec is the operation of the bucketSchema function.
+type BucketSchemaOpSpec struct {
+	Bucket   string `json:"bucket,omitempty"`
+	BucketID string `json:"bucketID,omitempty"`
+}
+
+func createBucketSchemaOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	spec := &BucketSchemaOpSpec{}
+
+	if bucket, ok, err := args.GetString("bucket"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Bucket = bucket
+	}
+
+	if bucketID, ok, err := args.GetString("bucketID"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.BucketID = bucketID
+	}
+
+	if spec.Bucket == "" && spec.BucketID == "" {
+		return nil, &flux.Error{
+			Code: codes.Invalid,
+			Msg:  "must specify one of bucket or bucketID",
+		}
+	}
+	if spec.Bucket != "" && spec.BucketID != "" {
+		return nil, &flux.Error{
+			Code: codes.Invalid,
+			Msg:  "must specify only one of bucket or bucketID",
+		}
+	}
+	return spec, nil
+}
+
+func (s *BucketSchemaOpSpec) Kind() flux.OperationKind {
+	return BucketSchemaKind
+}
+
+// BucketsAccessed makes BucketSchemaOpSpec a query.BucketAwareOperationSpec
+func (s *BucketSchemaOpSpec) BucketsAccessed(orgID *platform.ID) (readBuckets, writeBuckets []platform.BucketFilter) {
+	from := influxdb.FromOpSpec{Bucket: s.Bucket, BucketID: s.BucketID}
+	return from.BucketsAccessed(orgID)
+}
+
+// BucketSchemaProcedureSpec is the procedure of the bucketSchema function.
+type BucketSchemaProcedureSpec struct {
+	plan.DefaultCost
+
+	Bucket   string
+	BucketID string
+}
+
+func newBucketSchemaProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*BucketSchemaOpSpec)
+	if !ok {
+		return nil, &flux.Error{
+			Code: codes.Internal,
+			Msg:  fmt.Sprintf("invalid spec type %T", qs),
+		}
+	}
+	return &BucketSchemaProcedureSpec{
+		Bucket:   spec.Bucket,
+		BucketID: spec.BucketID,
+	}, nil
+}
+
+func (s *BucketSchemaProcedureSpec) Kind() plan.ProcedureKind {
+	return BucketSchemaKind
+}
+
+func (s *BucketSchemaProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+func createBucketSchemaSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := prSpec.(*BucketSchemaProcedureSpec)
+	if !ok {
+		return nil, &flux.Error{
+			Code: codes.Internal,
+			Msg:  fmt.Sprintf("invalid spec type %T", prSpec),
+		}
+	}
+
+	deps := a.Dependencies()[fluxinfluxdb.BucketsKind].(influxdb.BucketDependencies)
+	req := query.RequestFromContext(a.Context())
+	if req == nil {
+		return nil, &flux.Error{
+			Code: codes.Internal,
+			Msg:  "missing request on context",
+		}
+	}
+
+	d := &bucketSchemaDecoder{
+		orgID: req.OrganizationID,
+		deps:  deps,
+		spec:  spec,
+		alloc: a.Allocator(),
+	}
+	return execute.CreateSourceFromDecoder(d, dsid, a)
+}
+
+// bucketSchemaDecoder decodes the schema of a bucket into a single table
+// with a row for each tag key and field of each measurement.
+// Buckets without a schema have no rows.
+type bucketSchemaDecoder struct {
+	orgID  platform.ID
+	deps   influxdb.BucketDependencies
+	spec   *BucketSchemaProcedureSpec
+	alloc  *memory.Allocator
+	schema *platform.BucketSchema
+}
+
+func (d *bucketSchemaDecoder) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (d *bucketSchemaDecoder) Fetch(ctx context.Context) (bool, error) {
+	buckets, _ := d.deps.FindAllBuckets(ctx, d.orgID)
+	for _, b := range buckets {
+		if (d.spec.Bucket != "" && b.Name == d.spec.Bucket) || (d.spec.BucketID != "" && b.ID.String() == d.spec.BucketID) {
+			d.schema = b.Schema
+			return false, nil
+		}
+	}
+
+	name := d.spec.Bucket
+	if name == "" {
+		name = d.spec.BucketID
+	}
+	return false, &flux.Error{
+		Code: codes.NotFound,
+		Msg:  fmt.Sprintf("bucket %q not found", name),
+	}
+}
+
+func (d *bucketSchemaDecoder) Decode(ctx context.Context) (flux.Table, error) {
+	b := execute.NewColListTableBuilder(execute.NewGroupKey(nil, nil), d.alloc)
+	for _, label := range []string{"measurement", "key", "type"} {
+		if _, err := b.AddCol(flux.ColMeta{
+			Label: label,
+			Type:  flux.TString,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.schema != nil {
+		for _, m := range d.schema.Measurements {
+			for _, t := range m.Tags {
+				if err := appendStrings(b, m.Name, t, bucketSchemaTagType); err != nil {
+					return nil, err
+				}
+			}
+			for _, f := range m.Fields {
+				if err := appendStrings(b, m.Name, f.Name, string(f.Type)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return b.Table()
+}
+
+func (d *bucketSchemaDecoder) Close() error {
+	return nil
+}
+
+func appendStrings(b *execute.ColListTableBuilder, vs ...string) error {
+	for j, v := range vs {
+		if err := b.AppendString(j, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
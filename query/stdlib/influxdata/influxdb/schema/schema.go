@@ -0,0 +1,500 @@
+// Package schema implements the influxdata/influxdb/schema Flux package. Its
+// functions explore the schema of a bucket by reading the tag keys, tag values
+// and series of the bucket directly from the index of the storage engine,
+// instead of reading and grouping its data. Its bucketSchema function reads
+// the explicit schema of a bucket instead.
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
+)
+
+// PackagePath is the import path of the package.
+const PackagePath = "influxdata/influxdb/schema"
+
+const (
+	MeasurementsKind         = "schemaMeasurements"
+	MeasurementTagKeysKind   = "schemaMeasurementTagKeys"
+	MeasurementTagValuesKind = "schemaMeasurementTagValues"
+	FieldKeysKind            = "schemaFieldKeys"
+	CardinalityKind          = "schemaCardinality"
+)
+
+// DefaultStart is the start of the time range of a function when it is not
+// given.
+const DefaultStart = -30 * 24 * time.Hour
+
+const source = `package schema
+
+// measurements returns the measurements of a bucket.
+// The return value is always a single table with a single column "_value".
+builtin measurements
+
+// measurementTagKeys returns the tag keys of a measurement.
+// The return value is always a single table with a single column "_value".
+builtin measurementTagKeys
+
+// measurementTagValues returns the values of a tag of a measurement.
+// The return value is always a single table with a single column "_value".
+builtin measurementTagValues
+
+// fieldKeys returns the field keys of a bucket, or of one of its measurements.
+// The return value is always a single table with a single column "_value".
+builtin fieldKeys
+
+// cardinality returns the number of series of a bucket, where each field of
+// a series is counted separately.
+// The return value is always a single table with a single integer column "_value".
+builtin cardinality
+
+// bucketSchema returns the explicit schema of a bucket.
+// The return value is always a single table with the string columns
+// "measurement", "key" and "type", where type is "tag" for tag keys and the
+// type of the values of fields otherwise. It is empty for buckets without a schema.
+builtin bucketSchema
+`
+
+// functions are the names of the functions of the package by kind.
+var functions = map[flux.OperationKind]string{
+	MeasurementsKind:         "measurements",
+	MeasurementTagKeysKind:   "measurementTagKeys",
+	MeasurementTagValuesKind: "measurementTagValues",
+	FieldKeysKind:            "fieldKeys",
+	CardinalityKind:          "cardinality",
+}
+
+func init() {
+	pkg := parser.ParseSource(source)
+	if ast.Check(pkg) > 0 {
+		panic(ast.GetError(pkg))
+	}
+	pkg.Path = PackagePath
+	flux.RegisterPackage(pkg)
+
+	for kind, name := range functions {
+		kind := kind
+		params := map[string]semantic.PolyType{
+			"bucket":   semantic.String,
+			"bucketID": semantic.String,
+			"start":    semantic.Tvar(1),
+			"stop":     semantic.Tvar(2),
+			"predicate": semantic.NewFunctionPolyType(semantic.FunctionPolySignature{
+				Parameters: map[string]semantic.PolyType{
+					"r": semantic.Tvar(3),
+				},
+				Required: semantic.LabelSet{"r"},
+				Return:   semantic.Bool,
+			}),
+		}
+		var required semantic.LabelSet
+		switch kind {
+		case MeasurementTagKeysKind:
+			params["measurement"] = semantic.String
+			required = semantic.LabelSet{"measurement"}
+		case MeasurementTagValuesKind:
+			params["measurement"] = semantic.String
+			params["tag"] = semantic.String
+			required = semantic.LabelSet{"measurement", "tag"}
+		case FieldKeysKind:
+			params["measurement"] = semantic.String
+		}
+		signature := semantic.FunctionPolySignature{
+			Parameters: params,
+			Required:   required,
+			Return:     flux.TableObjectType,
+		}
+
+		fn := flux.FunctionValue(string(kind), func(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+			return createOpSpec(kind, args)
+		}, signature)
+		flux.RegisterPackageValue(PackagePath, name, function{fn.(values.Function)})
+		flux.RegisterOpSpec(kind, func() flux.OperationSpec {
+			return &OpSpec{kind: kind}
+		})
+		plan.RegisterProcedureSpec(plan.ProcedureKind(kind), newProcedure, kind)
+		execute.RegisterSource(plan.ProcedureKind(kind), createSource)
+	}
+
+	registerBucketSchema()
+}
+
+// function is a builtin function with a polymorphic signature. Its type is
+// invalid, like the type of a polymorphic function written in Flux, instead
+// of nil, which makes importing the package panic.
+type function struct {
+	builtin
+}
+
+// builtin is values.Function under a name that does not conflict with its
+// Function method when it is embedded.
+type builtin = values.Function
+
+func (f function) Type() semantic.Type {
+	if t := f.builtin.Type(); t != nil {
+		return t
+	}
+	return semantic.Invalid
+}
+
+// OpSpec is the operation of each of the functions of the package.
+type OpSpec struct {
+	kind flux.OperationKind
+
+	Bucket      string                       `json:"bucket,omitempty"`
+	BucketID    string                       `json:"bucketID,omitempty"`
+	Measurement string                       `json:"measurement,omitempty"`
+	Tag         string                       `json:"tag,omitempty"`
+	Start       flux.Time                    `json:"start"`
+	Stop        flux.Time                    `json:"stop"`
+	Predicate   *semantic.FunctionExpression `json:"predicate,omitempty"`
+}
+
+// NewOpSpec returns an empty operation of the function of kind.
+func NewOpSpec(kind flux.OperationKind) *OpSpec {
+	return &OpSpec{kind: kind}
+}
+
+func createOpSpec(kind flux.OperationKind, args flux.Arguments) (flux.OperationSpec, error) {
+	spec := NewOpSpec(kind)
+
+	if bucket, ok, err := args.GetString("bucket"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Bucket = bucket
+	}
+
+	if bucketID, ok, err := args.GetString("bucketID"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.BucketID = bucketID
+	}
+
+	if spec.Bucket == "" && spec.BucketID == "" {
+		return nil, &flux.Error{
+			Code: codes.Invalid,
+			Msg:  "must specify one of bucket or bucketID",
+		}
+	}
+	if spec.Bucket != "" && spec.BucketID != "" {
+		return nil, &flux.Error{
+			Code: codes.Invalid,
+			Msg:  "must specify only one of bucket or bucketID",
+		}
+	}
+
+	if measurement, ok, err := args.GetString("measurement"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Measurement = measurement
+	}
+
+	if tag, ok, err := args.GetString("tag"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Tag = tag
+	}
+
+	if start, ok, err := args.GetTime("start"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Start = start
+	} else {
+		spec.Start = flux.Time{IsRelative: true, Relative: DefaultStart}
+	}
+
+	if stop, ok, err := args.GetTime("stop"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Stop = stop
+	} else {
+		spec.Stop = flux.Now
+	}
+
+	if f, ok, err := args.GetFunction("predicate"); err != nil {
+		return nil, err
+	} else if ok {
+		fn, err := interpreter.ResolveFunction(f)
+		if err != nil {
+			return nil, err
+		}
+		if fn.Block.Parameters == nil || len(fn.Block.Parameters.List) != 1 {
+			return nil, &flux.Error{
+				Code: codes.Invalid,
+				Msg:  "predicate must have exactly one parameter",
+			}
+		}
+		if _, ok := fn.Block.Body.(semantic.Expression); !ok {
+			return nil, &flux.Error{
+				Code: codes.Invalid,
+				Msg:  "predicate must be a single expression",
+			}
+		}
+		spec.Predicate = fn
+	}
+
+	return spec, nil
+}
+
+func (s *OpSpec) Kind() flux.OperationKind {
+	return s.kind
+}
+
+// BucketsAccessed makes OpSpec a query.BucketAwareOperationSpec
+func (s *OpSpec) BucketsAccessed(orgID *platform.ID) (readBuckets, writeBuckets []platform.BucketFilter) {
+	from := influxdb.FromOpSpec{Bucket: s.Bucket, BucketID: s.BucketID}
+	return from.BucketsAccessed(orgID)
+}
+
+// ProcedureSpec is the procedure of each of the functions of the package.
+type ProcedureSpec struct {
+	plan.DefaultCost
+
+	kind plan.ProcedureKind
+
+	Bucket   string
+	BucketID string
+
+	// Predicate is the predicate that series must match, including the
+	// measurement of the function, if any. It is nil if all series match.
+	Predicate *semantic.FunctionExpression
+
+	Start flux.Time
+	Stop  flux.Time
+
+	// TagKey is the tag key whose values are read.
+	TagKey string
+}
+
+func newProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*OpSpec)
+	if !ok {
+		return nil, &flux.Error{
+			Code: codes.Internal,
+			Msg:  fmt.Sprintf("invalid spec type %T", qs),
+		}
+	}
+
+	ps := &ProcedureSpec{
+		kind:      plan.ProcedureKind(spec.kind),
+		Bucket:    spec.Bucket,
+		BucketID:  spec.BucketID,
+		Predicate: spec.Predicate,
+		Start:     spec.Start,
+		Stop:      spec.Stop,
+	}
+	if spec.Measurement != "" {
+		ps.Predicate = withMeasurement(spec.Measurement, spec.Predicate)
+	}
+	switch spec.kind {
+	case MeasurementsKind:
+		ps.TagKey = "_measurement"
+	case MeasurementTagValuesKind:
+		ps.TagKey = spec.Tag
+	case FieldKeysKind:
+		ps.TagKey = "_field"
+	}
+	return ps, nil
+}
+
+func (s *ProcedureSpec) Kind() plan.ProcedureKind {
+	return s.kind
+}
+
+func (s *ProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	if s.Predicate != nil {
+		ns.Predicate = s.Predicate.Copy().(*semantic.FunctionExpression)
+	}
+	return &ns
+}
+
+// withMeasurement returns a predicate matching the series of measurement
+// that also match fn, if it is not nil.
+func withMeasurement(measurement string, fn *semantic.FunctionExpression) *semantic.FunctionExpression {
+	param := "r"
+	if fn != nil {
+		param = fn.Block.Parameters.List[0].Key.Name
+	}
+
+	var body semantic.Expression = &semantic.BinaryExpression{
+		Operator: ast.EqualOperator,
+		Left: &semantic.MemberExpression{
+			Object:   &semantic.IdentifierExpression{Name: param},
+			Property: "_measurement",
+		},
+		Right: &semantic.StringLiteral{Value: measurement},
+	}
+	if fn != nil {
+		body = &semantic.LogicalExpression{
+			Operator: ast.AndOperator,
+			Left:     body,
+			Right:    fn.Block.Body.(semantic.Expression).Copy().(semantic.Expression),
+		}
+	}
+
+	return &semantic.FunctionExpression{
+		Block: &semantic.FunctionBlock{
+			Parameters: &semantic.FunctionParameters{
+				List: []*semantic.FunctionParameter{
+					{Key: &semantic.Identifier{Name: param}},
+				},
+			},
+			Body: body,
+		},
+	}
+}
+
+func createSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := prSpec.(*ProcedureSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", prSpec)
+	}
+
+	deps := a.Dependencies()[influxdb.FromKind].(influxdb.Dependencies)
+	req := query.RequestFromContext(a.Context())
+	if req == nil {
+		return nil, errors.New("missing request on context")
+	}
+	orgID := req.OrganizationID
+
+	rs := influxdb.ReadRangePhysSpec{Bucket: spec.Bucket, BucketID: spec.BucketID}
+	bucketID, err := rs.LookupBucketID(a.Context(), orgID, deps.BucketLookup)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := influxdb.ReadFilterSpec{
+		OrganizationID: orgID,
+		BucketID:       bucketID,
+		Bounds: execute.Bounds{
+			Start: a.ResolveTime(spec.Start),
+			Stop:  a.ResolveTime(spec.Stop),
+		},
+		Predicate: spec.Predicate,
+	}
+
+	reader := deps.Reader
+	d := &decoder{alloc: a.Allocator()}
+	switch spec.kind {
+	case plan.ProcedureKind(MeasurementTagKeysKind):
+		d.read = func(ctx context.Context) (influxdb.TableIterator, error) {
+			return reader.ReadTagKeys(ctx, influxdb.ReadTagKeysSpec{ReadFilterSpec: filter}, d.alloc)
+		}
+		d.keep = isTagKey
+	case plan.ProcedureKind(CardinalityKind):
+		d.typ = flux.TInt
+		d.read = func(ctx context.Context) (influxdb.TableIterator, error) {
+			return reader.ReadSeriesCardinality(ctx, influxdb.ReadSeriesCardinalitySpec{ReadFilterSpec: filter}, d.alloc)
+		}
+	default:
+		d.read = func(ctx context.Context) (influxdb.TableIterator, error) {
+			return reader.ReadTagValues(ctx, influxdb.ReadTagValuesSpec{ReadFilterSpec: filter, TagKey: spec.TagKey}, d.alloc)
+		}
+	}
+	if d.typ == flux.TInvalid {
+		d.typ = flux.TString
+	}
+
+	return execute.CreateSourceFromDecoder(d, dsid, a)
+}
+
+// isTagKey returns false for the keys of the columns that storage reads
+// besides the tag keys of series.
+func isTagKey(key string) bool {
+	switch key {
+	case execute.DefaultStartColLabel, execute.DefaultStopColLabel, "_measurement", "_field":
+		return false
+	}
+	return true
+}
+
+// decoder decodes the _value column of the tables read from storage into a
+// single table.
+type decoder struct {
+	read  func(ctx context.Context) (influxdb.TableIterator, error)
+	keep  func(v string) bool
+	typ   flux.ColType
+	alloc *memory.Allocator
+
+	strings []string
+	ints    []int64
+}
+
+func (d *decoder) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (d *decoder) Fetch(ctx context.Context) (bool, error) {
+	ti, err := d.read(ctx)
+	if err != nil {
+		return false, err
+	}
+	err = ti.Do(func(tbl flux.Table) error {
+		j := execute.ColIdx(execute.DefaultValueColLabel, tbl.Cols())
+		if j < 0 {
+			tbl.Done()
+			return nil
+		}
+		return tbl.Do(func(cr flux.ColReader) error {
+			switch d.typ {
+			case flux.TInt:
+				vs := cr.Ints(j)
+				for i := 0; i < vs.Len(); i++ {
+					d.ints = append(d.ints, vs.Value(i))
+				}
+			default:
+				vs := cr.Strings(j)
+				for i := 0; i < vs.Len(); i++ {
+					if v := vs.ValueString(i); d.keep == nil || d.keep(v) {
+						d.strings = append(d.strings, v)
+					}
+				}
+			}
+			return nil
+		})
+	})
+	return false, err
+}
+
+func (d *decoder) Decode(ctx context.Context) (flux.Table, error) {
+	b := execute.NewColListTableBuilder(execute.NewGroupKey(nil, nil), d.alloc)
+	if _, err := b.AddCol(flux.ColMeta{
+		Label: execute.DefaultValueColLabel,
+		Type:  d.typ,
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, v := range d.strings {
+		if err := b.AppendString(0, v); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range d.ints {
+		if err := b.AppendInt(0, v); err != nil {
+			return nil, err
+		}
+	}
+	return b.Table()
+}
+
+func (d *decoder) Close() error {
+	return nil
+}
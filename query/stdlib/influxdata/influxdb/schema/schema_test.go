@@ -0,0 +1,56 @@
+package schema_test
+
+import (
+	"fmt"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	pquerytest "github.com/influxdata/influxdb/query/querytest"
+)
+
+func TestOpSpec_BucketsAccessed(t *testing.T) {
+	bucketName := "my_bucket"
+	bucketIDString := "aaaabbbbccccdddd"
+	bucketID, err := platform.IDFromString(bucketIDString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []pquerytest.BucketsAccessedTestCase{
+		{
+			Name: "measurements with bucket",
+			Raw: fmt.Sprintf(`import "influxdata/influxdb/schema"
+schema.measurements(bucket: "%s")`, bucketName),
+			WantReadBuckets:  &[]platform.BucketFilter{{Name: &bucketName}},
+			WantWriteBuckets: &[]platform.BucketFilter{},
+		},
+		{
+			Name: "measurementTagValues with bucketID",
+			Raw: fmt.Sprintf(`import "influxdata/influxdb/schema"
+schema.measurementTagValues(bucketID: "%s", measurement: "cpu", tag: "host", predicate: (r) => r.region == "west")`, bucketID),
+			WantReadBuckets:  &[]platform.BucketFilter{{ID: bucketID}},
+			WantWriteBuckets: &[]platform.BucketFilter{},
+		},
+		{
+			Name: "cardinality with time range",
+			Raw: fmt.Sprintf(`import "influxdata/influxdb/schema"
+schema.cardinality(bucket: "%s", start: -1h, stop: 2019-01-01T00:00:00Z)`, bucketName),
+			WantReadBuckets:  &[]platform.BucketFilter{{Name: &bucketName}},
+			WantWriteBuckets: &[]platform.BucketFilter{},
+		},
+		{
+			Name: "bucketSchema with bucketID",
+			Raw: fmt.Sprintf(`import "influxdata/influxdb/schema"
+schema.bucketSchema(bucketID: "%s")`, bucketID),
+			WantReadBuckets:  &[]platform.BucketFilter{{ID: bucketID}},
+			WantWriteBuckets: &[]platform.BucketFilter{},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			pquerytest.BucketsAccessedTestHelper(t, tc)
+		})
+	}
+}
@@ -111,6 +111,10 @@ type ReadTagValuesSpec struct {
 	TagKey string
 }
 
+type ReadSeriesCardinalitySpec struct {
+	ReadFilterSpec
+}
+
 type Reader interface {
 	ReadFilter(ctx context.Context, spec ReadFilterSpec, alloc *memory.Allocator) (TableIterator, error)
 	ReadGroup(ctx context.Context, spec ReadGroupSpec, alloc *memory.Allocator) (TableIterator, error)
@@ -118,6 +122,10 @@ type Reader interface {
 	ReadTagKeys(ctx context.Context, spec ReadTagKeysSpec, alloc *memory.Allocator) (TableIterator, error)
 	ReadTagValues(ctx context.Context, spec ReadTagValuesSpec, alloc *memory.Allocator) (TableIterator, error)
 
+	// ReadSeriesCardinality reads a table with the number of series
+	// matching the predicate within the bounds in its _value column.
+	ReadSeriesCardinality(ctx context.Context, spec ReadSeriesCardinalitySpec, alloc *memory.Allocator) (TableIterator, error)
+
 	Close()
 }
 
@@ -0,0 +1,231 @@
+package influxdb
+
+import (
+	"context"
+	"net/url"
+)
+
+// ErrReplicationNotFound is the error msg for a missing replication.
+const ErrReplicationNotFound = "replication not found"
+
+// ops for replications.
+const (
+	OpFindReplicationByID = "FindReplicationByID"
+	OpFindReplications    = "FindReplications"
+	OpCreateReplication   = "CreateReplication"
+	OpUpdateReplication   = "UpdateReplication"
+	OpDeleteReplication   = "DeleteReplication"
+)
+
+// DefaultReplicationMaxQueueSizeBytes is the default maximum size of the
+// queue of writes waiting to be sent to the remote bucket.
+const DefaultReplicationMaxQueueSizeBytes = 64 * 1024 * 1024
+
+// ReplicationDropPolicy describes which writes are dropped when the queue
+// of a replication is full.
+type ReplicationDropPolicy string
+
+const (
+	// DropOldestReplicationPolicy drops the oldest queued writes to make
+	// room for new writes.
+	DropOldestReplicationPolicy ReplicationDropPolicy = "drop-oldest"
+	// DropNewestReplicationPolicy drops new writes until there is room in
+	// the queue.
+	DropNewestReplicationPolicy ReplicationDropPolicy = "drop-newest"
+)
+
+// Valid returns an error if the drop policy is unknown.
+func (p ReplicationDropPolicy) Valid() error {
+	switch p {
+	case DropOldestReplicationPolicy, DropNewestReplicationPolicy:
+		return nil
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  "drop policy must be drop-oldest or drop-newest",
+		}
+	}
+}
+
+// Replication mirrors the writes to a local bucket to a bucket of a remote
+// influxd, by way of a durable on-disk queue.
+type Replication struct {
+	ID                 ID                    `json:"id,omitempty"`
+	OrgID              ID                    `json:"orgID,omitempty"`
+	Name               string                `json:"name"`
+	Description        string                `json:"description,omitempty"`
+	LocalBucketID      ID                    `json:"localBucketID"`
+	RemoteURL          string                `json:"remoteURL"`
+	RemoteToken        string                `json:"remoteToken,omitempty"`
+	RemoteOrgID        ID                    `json:"remoteOrgID"`
+	RemoteBucketID     ID                    `json:"remoteBucketID"`
+	InsecureSkipVerify bool                  `json:"insecureSkipVerify,omitempty"`
+	MaxQueueSizeBytes  int64                 `json:"maxQueueSizeBytes"`
+	DropPolicy         ReplicationDropPolicy `json:"dropPolicy"`
+	CRUDLog
+}
+
+// Valid returns an error if the replication is missing required fields.
+func (r *Replication) Valid() error {
+	if r.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "replication name is required",
+		}
+	}
+	if !r.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "replication orgID is required",
+		}
+	}
+	if !r.LocalBucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "replication localBucketID is required",
+		}
+	}
+	if err := validReplicationURL(r.RemoteURL); err != nil {
+		return err
+	}
+	if !r.RemoteOrgID.Valid() || !r.RemoteBucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "replication remoteOrgID and remoteBucketID are required",
+		}
+	}
+	if r.MaxQueueSizeBytes < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "replication maxQueueSizeBytes must not be negative",
+		}
+	}
+	if r.DropPolicy != "" {
+		if err := r.DropPolicy.Valid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validReplicationURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "replication remoteURL must be an http or https URL",
+		}
+	}
+	return nil
+}
+
+// ReplicationFilter represents a set of filters that restrict the returned replications.
+type ReplicationFilter struct {
+	ID            *ID
+	OrgID         *ID
+	LocalBucketID *ID
+}
+
+// QueryParams converts ReplicationFilter fields to url query params.
+func (f ReplicationFilter) QueryParams() map[string][]string {
+	qp := url.Values{}
+	if f.ID != nil {
+		qp.Add("id", f.ID.String())
+	}
+	if f.OrgID != nil {
+		qp.Add("orgID", f.OrgID.String())
+	}
+	if f.LocalBucketID != nil {
+		qp.Add("localBucketID", f.LocalBucketID.String())
+	}
+	return qp
+}
+
+// ReplicationUpdate represents updates to a replication.
+// Only fields which are set are updated.
+type ReplicationUpdate struct {
+	Name               *string                `json:"name,omitempty"`
+	Description        *string                `json:"description,omitempty"`
+	RemoteURL          *string                `json:"remoteURL,omitempty"`
+	RemoteToken        *string                `json:"remoteToken,omitempty"`
+	RemoteOrgID        *ID                    `json:"remoteOrgID,omitempty"`
+	RemoteBucketID     *ID                    `json:"remoteBucketID,omitempty"`
+	InsecureSkipVerify *bool                  `json:"insecureSkipVerify,omitempty"`
+	MaxQueueSizeBytes  *int64                 `json:"maxQueueSizeBytes,omitempty"`
+	DropPolicy         *ReplicationDropPolicy `json:"dropPolicy,omitempty"`
+}
+
+// Valid returns an error if the update contains invalid values.
+func (u *ReplicationUpdate) Valid() error {
+	if u.Name != nil && *u.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "replication name cannot be empty",
+		}
+	}
+	if u.RemoteURL != nil {
+		if err := validReplicationURL(*u.RemoteURL); err != nil {
+			return err
+		}
+	}
+	if u.MaxQueueSizeBytes != nil && *u.MaxQueueSizeBytes < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "replication maxQueueSizeBytes must not be negative",
+		}
+	}
+	if u.DropPolicy != nil {
+		return u.DropPolicy.Valid()
+	}
+	return nil
+}
+
+// Apply applies the update to a replication.
+func (u *ReplicationUpdate) Apply(r *Replication) {
+	if u.Name != nil {
+		r.Name = *u.Name
+	}
+	if u.Description != nil {
+		r.Description = *u.Description
+	}
+	if u.RemoteURL != nil {
+		r.RemoteURL = *u.RemoteURL
+	}
+	if u.RemoteToken != nil {
+		r.RemoteToken = *u.RemoteToken
+	}
+	if u.RemoteOrgID != nil {
+		r.RemoteOrgID = *u.RemoteOrgID
+	}
+	if u.RemoteBucketID != nil {
+		r.RemoteBucketID = *u.RemoteBucketID
+	}
+	if u.InsecureSkipVerify != nil {
+		r.InsecureSkipVerify = *u.InsecureSkipVerify
+	}
+	if u.MaxQueueSizeBytes != nil {
+		r.MaxQueueSizeBytes = *u.MaxQueueSizeBytes
+	}
+	if u.DropPolicy != nil {
+		r.DropPolicy = *u.DropPolicy
+	}
+}
+
+// ReplicationService is a service for managing replications.
+type ReplicationService interface {
+	// FindReplicationByID returns a single replication by ID.
+	FindReplicationByID(ctx context.Context, id ID) (*Replication, error)
+
+	// FindReplications returns a list of replications that match filter.
+	FindReplications(ctx context.Context, filter ReplicationFilter) ([]*Replication, error)
+
+	// CreateReplication creates a new replication and sets r.ID with the new identifier.
+	CreateReplication(ctx context.Context, r *Replication) error
+
+	// UpdateReplication updates a single replication with changeset.
+	// Returns the new replication state after update.
+	UpdateReplication(ctx context.Context, id ID, upd ReplicationUpdate) (*Replication, error)
+
+	// DeleteReplication removes a replication by ID.
+	DeleteReplication(ctx context.Context, id ID) error
+}
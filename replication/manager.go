@@ -0,0 +1,250 @@
+// Package replication mirrors writes to local buckets to buckets of a
+// remote influxd. Writes are appended to a durable queue per replication,
+// from which they are sent to the remote write endpoint.
+package replication
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultBatchSize is the default number of bytes of line protocol sent
+	// to the remote in a single request.
+	DefaultBatchSize = 1024 * 1024
+
+	// DefaultMinBackoff is the default time waited before retrying a failed
+	// write to the remote. It doubles after each failure.
+	DefaultMinBackoff = time.Second
+
+	// DefaultMaxBackoff is the default maximum time waited before retrying a
+	// failed write to the remote.
+	DefaultMaxBackoff = 5 * time.Minute
+
+	// DefaultTimeout is the default timeout of requests to the remote.
+	DefaultTimeout = time.Minute
+)
+
+var _ influxdb.ReplicationService = (*Manager)(nil)
+
+// Manager runs a replication stream for each replication. It wraps the
+// ReplicationService that stores the replications, so that streams are
+// started, restarted and stopped as replications are created, updated and
+// deleted.
+type Manager struct {
+	influxdb.ReplicationService
+
+	// BatchSize is the number of bytes of line protocol sent to the remote
+	// in a single request.
+	BatchSize int
+
+	// MinBackoff and MaxBackoff bound the time waited before retrying a
+	// failed write to the remote.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Timeout is the timeout of requests to the remote.
+	Timeout time.Duration
+
+	Logger *zap.Logger
+
+	path    string
+	now     func() time.Time
+	metrics *replicationMetrics
+
+	mu      sync.RWMutex
+	streams map[influxdb.ID]*stream
+	buckets map[influxdb.ID][]*stream // local bucket ID to streams
+}
+
+// NewManager returns a Manager that stores the queues of the replications
+// of s in directories within path.
+func NewManager(path string, s influxdb.ReplicationService) *Manager {
+	return &Manager{
+		ReplicationService: s,
+		BatchSize:          DefaultBatchSize,
+		MinBackoff:         DefaultMinBackoff,
+		MaxBackoff:         DefaultMaxBackoff,
+		Timeout:            DefaultTimeout,
+		Logger:             zap.NewNop(),
+		path:               path,
+		now:                time.Now,
+		metrics:            newReplicationMetrics(),
+		streams:            make(map[influxdb.ID]*stream),
+		buckets:            make(map[influxdb.ID][]*stream),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *Manager) PrometheusCollectors() []prometheus.Collector {
+	return m.metrics.PrometheusCollectors()
+}
+
+// Open starts a stream for every replication.
+func (m *Manager) Open(ctx context.Context) error {
+	if err := os.MkdirAll(m.path, 0777); err != nil {
+		return err
+	}
+
+	rs, err := m.ReplicationService.FindReplications(ctx, influxdb.ReplicationFilter{})
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range rs {
+		if err := m.startStream(r); err != nil {
+			m.closeStreams()
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops all streams. Writes that have not been sent remain queued.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closeStreams()
+}
+
+func (m *Manager) closeStreams() error {
+	var err error
+	for id := range m.streams {
+		if e := m.stopStream(id); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// CreateReplication creates a replication and starts its stream.
+func (m *Manager) CreateReplication(ctx context.Context, r *influxdb.Replication) error {
+	if err := m.ReplicationService.CreateReplication(ctx, r); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.startStream(r); err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateReplication,
+			Msg: "replication was created but its stream could not be started",
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// UpdateReplication updates a replication and restarts its stream. Queued
+// writes are kept and are sent to the updated remote.
+func (m *Manager) UpdateReplication(ctx context.Context, id influxdb.ID, upd influxdb.ReplicationUpdate) (*influxdb.Replication, error) {
+	r, err := m.ReplicationService.UpdateReplication(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.stopStream(id); err != nil {
+		return nil, err
+	}
+	if err := m.startStream(r); err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpUpdateReplication,
+			Msg: "replication was updated but its stream could not be restarted",
+			Err: err,
+		}
+	}
+	return r, nil
+}
+
+// DeleteReplication deletes a replication, stops its stream and removes
+// any writes that have not been sent.
+func (m *Manager) DeleteReplication(ctx context.Context, id influxdb.ID) error {
+	if err := m.ReplicationService.DeleteReplication(ctx, id); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.stopStream(id); err != nil {
+		return err
+	}
+	m.metrics.delete(id.String())
+	return os.RemoveAll(m.queuePath(id))
+}
+
+// Enqueue queues line protocol written to a local bucket for every
+// replication of the bucket.
+func (m *Manager) Enqueue(bucketID influxdb.ID, data []byte) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, s := range m.buckets[bucketID] {
+		s.enqueue(data)
+	}
+}
+
+// HasReplications returns true if any replication has the bucket as its
+// local bucket.
+func (m *Manager) HasReplications(bucketID influxdb.ID) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.buckets[bucketID]) > 0
+}
+
+// startStream opens the queue of r and starts sending it. m.mu must be held.
+func (m *Manager) startStream(r *influxdb.Replication) error {
+	q := NewQueue(m.queuePath(r.ID), r.MaxQueueSizeBytes, r.DropPolicy)
+	if err := q.Open(); err != nil {
+		return err
+	}
+
+	s, err := newStream(r, q, m)
+	if err != nil {
+		q.Close()
+		return err
+	}
+	s.open()
+
+	m.streams[r.ID] = s
+	m.buckets[r.LocalBucketID] = append(m.buckets[r.LocalBucketID], s)
+	return nil
+}
+
+// stopStream stops the stream of a replication, if it is running. m.mu
+// must be held.
+func (m *Manager) stopStream(id influxdb.ID) error {
+	s, ok := m.streams[id]
+	if !ok {
+		return nil
+	}
+	delete(m.streams, id)
+
+	for bucketID, ss := range m.buckets {
+		for i := range ss {
+			if ss[i] == s {
+				ss = append(ss[:i:i], ss[i+1:]...)
+				break
+			}
+		}
+		if len(ss) == 0 {
+			delete(m.buckets, bucketID)
+		} else {
+			m.buckets[bucketID] = ss
+		}
+	}
+	return s.close()
+}
+
+func (m *Manager) queuePath(id influxdb.ID) string {
+	return filepath.Join(m.path, id.String())
+}
@@ -0,0 +1,170 @@
+package replication_test
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/replication"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// remote is a fake write endpoint that responds with the given status
+// codes, in order, and then 204.
+type remote struct {
+	mu       sync.Mutex
+	statuses []int
+	queries  []string
+	writes   []string
+	received chan struct{}
+}
+
+func newRemote(statuses ...int) *remote {
+	return &remote{statuses: statuses, received: make(chan struct{}, 100)}
+}
+
+func (r *remote) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	gr, err := gzip.NewReader(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	body, _ := ioutil.ReadAll(gr)
+
+	r.mu.Lock()
+	status := http.StatusNoContent
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	if status == http.StatusNoContent {
+		r.queries = append(r.queries, req.URL.Path+"?"+req.URL.RawQuery+" "+req.Header.Get("Authorization"))
+		r.writes = append(r.writes, string(body))
+	}
+	r.mu.Unlock()
+
+	w.WriteHeader(status)
+	r.received <- struct{}{}
+}
+
+func (r *remote) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.received:
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for write to remote")
+		}
+	}
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	svc := kv.NewService(inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "edge"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	bucket := &influxdb.Bucket{OrgID: org.ID, Name: "sensors"}
+	if err := svc.CreateBucket(ctx, bucket); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first write fails and is retried, the second is rejected.
+	rem := newRemote(http.StatusServiceUnavailable, http.StatusNoContent, http.StatusBadRequest)
+	srv := httptest.NewServer(rem)
+	defer srv.Close()
+
+	m := replication.NewManager(dir, svc)
+	m.MinBackoff = time.Millisecond
+	if err := m.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	r := &influxdb.Replication{
+		OrgID:          org.ID,
+		Name:           "cloud",
+		LocalBucketID:  bucket.ID,
+		RemoteURL:      srv.URL,
+		RemoteToken:    "secret",
+		RemoteOrgID:    0x1111,
+		RemoteBucketID: 0x2222,
+	}
+	if err := m.CreateReplication(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &replication.PointsWriter{Underlying: &mock.PointsWriter{}, Manager: m}
+	write := func(orgID, bucketID influxdb.ID, data string) {
+		t.Helper()
+		name := tsdb.EncodeName(orgID, bucketID)
+		points, err := models.ParsePointsWithPrecision([]byte(data), models.EscapeMeasurement(name[:]), time.Now(), "ns")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WritePoints(ctx, points); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(org.ID, bucket.ID, "cpu,host=a usage=1,idle=2i 10\nmem\\ used,host=b free=\"x y\" 20")
+	write(org.ID, 0x3333, "cpu,host=a usage=1 10") // not replicated
+	rem.wait(t, 2)
+
+	write(org.ID, bucket.ID, "cpu,host=a usage=3 20")
+	rem.wait(t, 1)
+
+	write(org.ID, bucket.ID, "cpu,host=a up=true 30")
+	rem.wait(t, 1)
+
+	rem.mu.Lock()
+	defer rem.mu.Unlock()
+	exp := []string{
+		"cpu,host=a usage=1 10\ncpu,host=a idle=2i 10\nmem\\ used,host=b free=\"x y\" 20\n",
+		"cpu,host=a up=true 30\n",
+	}
+	if got := strings.Join(rem.writes, "|"); got != strings.Join(exp, "|") {
+		t.Errorf("unexpected writes -got/+exp\n%s\n%s", got, strings.Join(exp, "|"))
+	}
+	if got, exp := rem.queries[0], "/api/v2/write?bucket=0000000000002222&org=0000000000001111&precision=ns Token secret"; got != exp {
+		t.Errorf("unexpected request -got/+exp\n%s\n%s", got, exp)
+	}
+}
+
+func TestPointsWriter_Error(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	m := replication.NewManager(dir, mock.NewReplicationService())
+	if err := m.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	pw := &mock.PointsWriter{}
+	pw.ForceError(errors.New("engine closed"))
+	w := &replication.PointsWriter{Underlying: pw, Manager: m}
+	if err := w.WritePoints(context.Background(), nil); err == nil || err.Error() != "engine closed" {
+		t.Errorf("expected error from underlying points writer, got %v", err)
+	}
+}
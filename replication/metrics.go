@@ -0,0 +1,74 @@
+package replication
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// replicationMetrics is a collection of metrics relating to replication
+// streams, split out by replication ID.
+type replicationMetrics struct {
+	queueBytes   *prometheus.GaugeVec
+	lagSeconds   *prometheus.GaugeVec
+	sentBytes    *prometheus.CounterVec
+	droppedBytes *prometheus.CounterVec
+	sendErrors   *prometheus.CounterVec
+}
+
+func newReplicationMetrics() *replicationMetrics {
+	const namespace = "replications"
+	const subsystem = "stream"
+
+	return &replicationMetrics{
+		queueBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "queue_bytes",
+			Help:      "Number of bytes of writes waiting to be sent to the remote bucket.",
+		}, []string{"replication_id"}),
+		lagSeconds: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "lag_seconds",
+			Help:      "Time since the oldest write waiting to be sent was queued.",
+		}, []string{"replication_id"}),
+		sentBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "sent_bytes_total",
+			Help:      "Number of bytes of line protocol accepted by the remote bucket.",
+		}, []string{"replication_id"}),
+		droppedBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "dropped_bytes_total",
+			Help:      "Number of bytes of line protocol dropped, split out by reason: the queue was full, or the remote rejected the write.",
+		}, []string{"replication_id", "reason"}),
+		sendErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "send_errors_total",
+			Help:      "Number of failed attempts to send writes to the remote bucket.",
+		}, []string{"replication_id"}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *replicationMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.queueBytes,
+		m.lagSeconds,
+		m.sentBytes,
+		m.droppedBytes,
+		m.sendErrors,
+	}
+}
+
+// delete removes the metrics of a replication that no longer exists.
+func (m *replicationMetrics) delete(id string) {
+	m.queueBytes.DeleteLabelValues(id)
+	m.lagSeconds.DeleteLabelValues(id)
+	m.sentBytes.DeleteLabelValues(id)
+	m.droppedBytes.DeleteLabelValues(id, dropReasonQueueFull)
+	m.droppedBytes.DeleteLabelValues(id, dropReasonRejected)
+	m.sendErrors.DeleteLabelValues(id)
+}
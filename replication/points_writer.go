@@ -0,0 +1,113 @@
+package replication
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/escape"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// PointsWriter queues points for replication once they have been written
+// to the underlying PointsWriter.
+type PointsWriter struct {
+	Underlying storage.PointsWriter
+	Manager    *Manager
+}
+
+// WritePoints writes points to the underlying PointsWriter and, if that
+// succeeds, queues the points of every replicated bucket.
+func (w *PointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	if err := w.Underlying.WritePoints(ctx, points); err != nil {
+		return err
+	}
+
+	replicated := make(map[influxdb.ID]bool)
+	buckets := make(map[influxdb.ID][]byte)
+	for _, p := range points {
+		// The name of a point is its encoded organization and bucket ID.
+		name := p.Name()
+		if len(name) != 16 {
+			continue
+		}
+		_, bucketID := tsdb.DecodeNameSlice(name)
+
+		ok, seen := replicated[bucketID]
+		if !seen {
+			ok = w.Manager.HasReplications(bucketID)
+			replicated[bucketID] = ok
+		}
+		if ok {
+			buckets[bucketID] = appendLineProtocol(buckets[bucketID], p)
+		}
+	}
+
+	for bucketID, buf := range buckets {
+		if len(buf) > 0 {
+			w.Manager.Enqueue(bucketID, buf)
+		}
+	}
+	return nil
+}
+
+// appendLineProtocol appends p as line protocol, restoring the measurement
+// and field keys that are stored as tags, to buf.
+func appendLineProtocol(buf []byte, p models.Point) []byte {
+	var measurement []byte
+	tags := p.Tags()
+	other := make(models.Tags, 0, len(tags))
+	for _, t := range tags {
+		switch string(t.Key) {
+		case models.MeasurementTagKey:
+			measurement = t.Value
+		case models.FieldKeyTagKey:
+		default:
+			other = append(other, t)
+		}
+	}
+	if measurement == nil {
+		return buf
+	}
+
+	buf = models.AppendMakeKey(buf, measurement, other)
+	buf = append(buf, ' ')
+
+	iter := p.FieldIterator()
+	for i := 0; iter.Next(); i++ {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, escape.Bytes(iter.FieldKey())...)
+		buf = append(buf, '=')
+		buf = appendFieldValue(buf, iter)
+	}
+
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, p.UnixNano(), 10)
+	return append(buf, '\n')
+}
+
+func appendFieldValue(buf []byte, iter models.FieldIterator) []byte {
+	switch iter.Type() {
+	case models.Float:
+		v, _ := iter.FloatValue()
+		return strconv.AppendFloat(buf, v, 'f', -1, 64)
+	case models.Integer:
+		v, _ := iter.IntegerValue()
+		return append(strconv.AppendInt(buf, v, 10), 'i')
+	case models.Unsigned:
+		v, _ := iter.UnsignedValue()
+		return append(strconv.AppendUint(buf, v, 10), 'u')
+	case models.String:
+		buf = append(buf, '"')
+		buf = append(buf, models.EscapeStringField(iter.StringValue())...)
+		return append(buf, '"')
+	case models.Boolean:
+		v, _ := iter.BooleanValue()
+		return strconv.AppendBool(buf, v)
+	}
+	return buf
+}
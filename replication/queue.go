@@ -0,0 +1,563 @@
+package replication
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/pkg/file"
+)
+
+const (
+	// DefaultSegmentSize is the size at which a new queue segment is started.
+	DefaultSegmentSize = 10 * 1024 * 1024
+
+	// recordHeaderSize is the size of the header of each record: the length
+	// of the data, its CRC-32 checksum and the time it was enqueued.
+	recordHeaderSize = 16
+
+	segmentFileExtension = "seg"
+	positionFileName     = "position"
+)
+
+var (
+	// ErrQueueClosed is returned when appending to a closed queue.
+	ErrQueueClosed = errors.New("replication queue is closed")
+
+	// errCorruptRecord is returned when a record cannot be read back.
+	errCorruptRecord = errors.New("corrupt replication queue record")
+)
+
+type segment struct {
+	id   int
+	size int64
+}
+
+// position is the location of a record in the queue.
+type position struct {
+	segment int
+	offset  int64
+}
+
+func (p position) less(o position) bool {
+	return p.segment < o.segment || (p.segment == o.segment && p.offset < o.offset)
+}
+
+// Batch is a set of consecutive records read from the head of a Queue.
+type Batch struct {
+	// Data holds the data of the records, each terminated by a newline.
+	Data []byte
+
+	// Records is the number of records in the batch.
+	Records int
+
+	// Oldest is the time the first record of the batch was enqueued.
+	Oldest time.Time
+
+	end position
+}
+
+// Queue is a durable FIFO queue of line protocol, stored in segment files
+// within a directory. Records are appended to the last segment, and a new
+// segment is started once it reaches SegmentSize. The position of the
+// oldest unsent record is stored in a separate file, and segments are
+// removed once all of their records have been sent.
+//
+// Each record consists of a 16 byte header followed by the data:
+//
+//	┌────────────┬────────────┬───────────────────┬──────────┐
+//	│ Length (4) │ CRC-32 (4) │ Enqueued at (8)   │ Data (N) │
+//	└────────────┴────────────┴───────────────────┴──────────┘
+//
+// The size of the unsent records is bounded by a maximum size. When an append
+// would exceed it, the drop policy decides whether the oldest records or
+// the new record are dropped.
+type Queue struct {
+	// SegmentSize is the size at which a new segment is started.
+	SegmentSize int64
+
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	policy  influxdb.ReplicationDropPolicy
+
+	// segments are sorted by id; the first segment holds the head.
+	segments []segment
+	head     int64 // offset of the head within the first segment
+	size     int64 // bytes of unsent records, including headers
+	oldest   time.Time
+	w        *os.File
+	closed   bool
+}
+
+// NewQueue returns a new queue stored in the directory at path.
+func NewQueue(path string, maxSize int64, policy influxdb.ReplicationDropPolicy) *Queue {
+	return &Queue{
+		SegmentSize: DefaultSegmentSize,
+		path:        path,
+		maxSize:     maxSize,
+		policy:      policy,
+	}
+}
+
+// Open opens the segments of the queue, creating the directory if needed.
+// A partially written record at the end of the last segment, e.g. after a
+// crash, is truncated.
+func (q *Queue) Open() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := os.MkdirAll(q.path, 0777); err != nil {
+		return err
+	}
+
+	fis, err := ioutil.ReadDir(q.path)
+	if err != nil {
+		return err
+	}
+	for _, fi := range fis {
+		id, ok := parseSegmentFileName(fi.Name())
+		if !ok {
+			continue
+		}
+		q.segments = append(q.segments, segment{id: id, size: fi.Size()})
+	}
+	sort.Slice(q.segments, func(i, j int) bool { return q.segments[i].id < q.segments[j].id })
+
+	if len(q.segments) == 0 {
+		q.segments = []segment{{id: 1}}
+	} else if err := q.repairLastSegment(); err != nil {
+		return err
+	}
+
+	last := q.segments[len(q.segments)-1]
+	w, err := os.OpenFile(q.segmentPath(last.id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	q.w = w
+
+	pos, err := q.readPosition()
+	if err != nil {
+		q.w.Close()
+		return err
+	}
+
+	// Segments before the head have been sent but were not yet removed.
+	for len(q.segments) > 1 && q.segments[0].id < pos.segment {
+		if err := os.Remove(q.segmentPath(q.segments[0].id)); err != nil {
+			q.w.Close()
+			return err
+		}
+		q.segments = q.segments[1:]
+	}
+	if q.segments[0].id == pos.segment && pos.offset <= q.segments[0].size {
+		q.head = pos.offset
+	}
+
+	q.updateSize()
+	return q.advanceSegments()
+}
+
+// Close closes the queue.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	if q.w != nil {
+		return q.w.Close()
+	}
+	return nil
+}
+
+// Size returns the number of bytes of unsent records.
+func (q *Queue) Size() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+// Lag returns how long the oldest unsent record has been in the queue.
+func (q *Queue) Lag(now time.Time) time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.oldest.IsZero() || now.Before(q.oldest) {
+		return 0
+	}
+	return now.Sub(q.oldest)
+}
+
+// Append adds data to the end of the queue. It returns the number of bytes
+// of data dropped to respect the maximum size of the queue.
+func (q *Queue) Append(data []byte, now time.Time) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return 0, ErrQueueClosed
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	n := int64(recordHeaderSize + len(data))
+	var dropped int64
+	if q.maxSize > 0 && q.size+n > q.maxSize {
+		if n > q.maxSize || q.policy == influxdb.DropNewestReplicationPolicy {
+			return int64(len(data)), nil
+		}
+		for q.size > 0 && q.size+n > q.maxSize {
+			d, err := q.dropHead()
+			if err != nil {
+				return dropped, err
+			}
+			dropped += d
+		}
+		if err := q.advanceSegments(); err != nil {
+			return dropped, err
+		}
+		if err := q.writePosition(); err != nil {
+			return dropped, err
+		}
+	}
+
+	last := &q.segments[len(q.segments)-1]
+	if last.size > 0 && last.size+n > q.SegmentSize {
+		if err := q.roll(); err != nil {
+			return dropped, err
+		}
+		last = &q.segments[len(q.segments)-1]
+	}
+
+	buf := make([]byte, n)
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(data))
+	binary.BigEndian.PutUint64(buf[8:16], uint64(now.UnixNano()))
+	copy(buf[recordHeaderSize:], data)
+
+	if _, err := q.w.Write(buf); err != nil {
+		// Remove any partial record so later records can be read.
+		q.w.Truncate(last.size)
+		return dropped, err
+	}
+	if err := q.w.Sync(); err != nil {
+		return dropped, err
+	}
+
+	last.size += n
+	if q.size == 0 {
+		q.oldest = now
+	}
+	q.size += n
+	return dropped, nil
+}
+
+// Peek returns the records at the head of the queue, up to about maxBytes
+// of data. At least one record is returned if the queue is not empty. It
+// returns nil if the queue is empty.
+func (q *Queue) Peek(maxBytes int) (*Batch, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.size == 0 {
+		return nil, nil
+	}
+	if maxBytes <= 0 {
+		maxBytes = 1
+	}
+
+	b := &Batch{}
+	pos := position{segment: q.segments[0].id, offset: q.head}
+	for i := 0; i < len(q.segments) && len(b.Data) < maxBytes; i++ {
+		seg := q.segments[i]
+		if i > 0 {
+			pos = position{segment: seg.id}
+		}
+
+		f, err := os.Open(q.segmentPath(seg.id))
+		if err != nil {
+			return nil, err
+		}
+		for pos.offset < seg.size && len(b.Data) < maxBytes {
+			data, enqueued, err := readRecord(f, pos.offset, seg.size)
+			if err == errCorruptRecord {
+				// Skip the rest of a corrupt segment.
+				pos.offset = seg.size
+				break
+			} else if err != nil {
+				f.Close()
+				return nil, err
+			}
+			if b.Records == 0 {
+				b.Oldest = enqueued
+			}
+			b.Data = append(b.Data, data...)
+			if len(data) > 0 && data[len(data)-1] != '\n' {
+				b.Data = append(b.Data, '\n')
+			}
+			b.Records++
+			pos.offset += int64(recordHeaderSize + len(data))
+		}
+		f.Close()
+		b.end = pos
+	}
+
+	if b.Records == 0 {
+		// Only corrupt records remain; drop them.
+		if err := q.advance(b.end); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return b, nil
+}
+
+// Advance removes the records of b, which must have been returned by Peek,
+// from the queue. Records already dropped from the queue are ignored.
+func (q *Queue) Advance(b *Batch) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+	return q.advance(b.end)
+}
+
+func (q *Queue) advance(end position) error {
+	for q.size > 0 && q.headPosition().less(end) {
+		if _, err := q.dropHead(); err != nil {
+			return err
+		}
+	}
+	if err := q.advanceSegments(); err != nil {
+		return err
+	}
+	return q.writePosition()
+}
+
+func (q *Queue) headPosition() position {
+	return position{segment: q.segments[0].id, offset: q.head}
+}
+
+// dropHead removes the record at the head of the queue and returns the
+// length of its data.
+func (q *Queue) dropHead() (int64, error) {
+	for q.head >= q.segments[0].size {
+		if len(q.segments) == 1 {
+			return 0, nil
+		}
+		if err := q.removeFirstSegment(); err != nil {
+			return 0, err
+		}
+	}
+
+	seg := q.segments[0]
+	f, err := os.Open(q.segmentPath(seg.id))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	data, _, err := readRecord(f, q.head, seg.size)
+	if err == errCorruptRecord {
+		q.head = seg.size
+		q.updateSize()
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	n := int64(recordHeaderSize + len(data))
+	q.head += n
+	q.size -= n
+	return int64(len(data)), nil
+}
+
+// advanceSegments removes fully sent segments and updates the enqueue time
+// of the oldest record.
+func (q *Queue) advanceSegments() error {
+	for len(q.segments) > 1 && q.head >= q.segments[0].size {
+		if err := q.removeFirstSegment(); err != nil {
+			return err
+		}
+	}
+
+	q.oldest = time.Time{}
+	if q.size == 0 {
+		return nil
+	}
+
+	f, err := os.Open(q.segmentPath(q.segments[0].id))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var hdr [recordHeaderSize]byte
+	if _, err := f.ReadAt(hdr[:], q.head); err != nil {
+		return nil
+	}
+	q.oldest = time.Unix(0, int64(binary.BigEndian.Uint64(hdr[8:16])))
+	return nil
+}
+
+func (q *Queue) removeFirstSegment() error {
+	if err := os.Remove(q.segmentPath(q.segments[0].id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	q.segments = q.segments[1:]
+	q.head = 0
+	q.updateSize()
+	return nil
+}
+
+func (q *Queue) updateSize() {
+	q.size = -q.head
+	for _, seg := range q.segments {
+		q.size += seg.size
+	}
+}
+
+// roll closes the last segment and starts a new one.
+func (q *Queue) roll() error {
+	if err := q.w.Close(); err != nil {
+		return err
+	}
+
+	id := q.segments[len(q.segments)-1].id + 1
+	w, err := os.OpenFile(q.segmentPath(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	if err := file.SyncDir(q.path); err != nil {
+		w.Close()
+		return err
+	}
+	q.w = w
+	q.segments = append(q.segments, segment{id: id})
+	return nil
+}
+
+// repairLastSegment truncates the last segment after its last valid record.
+func (q *Queue) repairLastSegment() error {
+	last := &q.segments[len(q.segments)-1]
+	f, err := os.OpenFile(q.segmentPath(last.id), os.O_RDWR, 0666)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var off int64
+	for off < last.size {
+		data, _, err := readRecord(f, off, last.size)
+		if err == errCorruptRecord {
+			break
+		} else if err != nil {
+			return err
+		}
+		off += int64(recordHeaderSize + len(data))
+	}
+
+	if off < last.size {
+		if err := f.Truncate(off); err != nil {
+			return err
+		}
+		last.size = off
+	}
+	return nil
+}
+
+func (q *Queue) readPosition() (position, error) {
+	pos := position{segment: q.segments[0].id}
+
+	buf, err := ioutil.ReadFile(filepath.Join(q.path, positionFileName))
+	if os.IsNotExist(err) {
+		return pos, nil
+	} else if err != nil {
+		return pos, err
+	}
+	if len(buf) != 16 {
+		return pos, fmt.Errorf("invalid replication queue position file in %s", q.path)
+	}
+
+	pos.segment = int(binary.BigEndian.Uint64(buf[0:8]))
+	pos.offset = int64(binary.BigEndian.Uint64(buf[8:16]))
+	return pos, nil
+}
+
+// writePosition atomically replaces the position file.
+func (q *Queue) writePosition() error {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[0:8], uint64(q.segments[0].id))
+	binary.BigEndian.PutUint64(buf[8:16], uint64(q.head))
+
+	path := filepath.Join(q.path, positionFileName)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf[:], 0666); err != nil {
+		return err
+	}
+	return file.RenameFile(tmp, path)
+}
+
+func (q *Queue) segmentPath(id int) string {
+	return filepath.Join(q.path, fmt.Sprintf("%08d.%s", id, segmentFileExtension))
+}
+
+func parseSegmentFileName(name string) (int, bool) {
+	if filepath.Ext(name) != "."+segmentFileExtension {
+		return 0, false
+	}
+	id, err := strconv.Atoi(strings.TrimSuffix(name, "."+segmentFileExtension))
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// readRecord reads the record at off in f, which has size bytes.
+func readRecord(f *os.File, off, size int64) ([]byte, time.Time, error) {
+	if size-off < recordHeaderSize {
+		return nil, time.Time{}, errCorruptRecord
+	}
+
+	var hdr [recordHeaderSize]byte
+	if _, err := f.ReadAt(hdr[:], off); err != nil {
+		if err == io.EOF {
+			return nil, time.Time{}, errCorruptRecord
+		}
+		return nil, time.Time{}, err
+	}
+
+	n := int64(binary.BigEndian.Uint32(hdr[0:4]))
+	if n > size-off-recordHeaderSize {
+		return nil, time.Time{}, errCorruptRecord
+	}
+
+	data := make([]byte, n)
+	if _, err := f.ReadAt(data, off+recordHeaderSize); err != nil {
+		if err == io.EOF {
+			return nil, time.Time{}, errCorruptRecord
+		}
+		return nil, time.Time{}, err
+	}
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(hdr[4:8]) {
+		return nil, time.Time{}, errCorruptRecord
+	}
+	return data, time.Unix(0, int64(binary.BigEndian.Uint64(hdr[8:16]))), nil
+}
@@ -0,0 +1,199 @@
+package replication_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/replication"
+)
+
+func TestQueue(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	q := replication.NewQueue(dir, 0, influxdb.DropOldestReplicationPolicy)
+	q.SegmentSize = 46 // a segment for every two records
+	if err := q.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(0, 0)
+	for i, line := range []string{"a v=1 1", "a v=2 2", "a v=3 3", "a v=4 4", "a v=5 5"} {
+		if _, err := q.Append([]byte(line), now.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, exp := q.Lag(now.Add(10*time.Second)), 10*time.Second; got != exp {
+		t.Errorf("unexpected lag -got/+exp\n%v\n%v", got, exp)
+	}
+
+	b := mustPeek(t, q, 10)
+	if got, exp := string(b.Data), "a v=1 1\na v=2 2\n"; got != exp {
+		t.Errorf("unexpected batch -got/+exp\n%s\n%s", got, exp)
+	}
+
+	// Records are kept until the batch is advanced.
+	if got := mustPeek(t, q, 10); string(got.Data) != string(b.Data) {
+		t.Errorf("unexpected batch %q", got.Data)
+	}
+	if err := q.Advance(b); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := q.Lag(now.Add(10*time.Second)), 8*time.Second; got != exp {
+		t.Errorf("unexpected lag -got/+exp\n%v\n%v", got, exp)
+	}
+	if got, exp := segmentFiles(t, dir), 2; got != exp {
+		t.Errorf("unexpected number of segments -got/+exp\n%d\n%d", got, exp)
+	}
+
+	// The unsent records are still queued after reopening the queue.
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	q = replication.NewQueue(dir, 0, influxdb.DropOldestReplicationPolicy)
+	if err := q.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	b = mustPeek(t, q, 1000)
+	if got, exp := string(b.Data), "a v=3 3\na v=4 4\na v=5 5\n"; got != exp {
+		t.Errorf("unexpected batch -got/+exp\n%s\n%s", got, exp)
+	}
+	if err := q.Advance(b); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := q.Peek(1000); err != nil || b != nil {
+		t.Errorf("expected empty queue, got %v, %v", b, err)
+	}
+	if q.Size() != 0 || q.Lag(now) != 0 {
+		t.Errorf("unexpected size %d and lag %v of empty queue", q.Size(), q.Lag(now))
+	}
+}
+
+func TestQueue_DropPolicy(t *testing.T) {
+	tests := []struct {
+		policy  influxdb.ReplicationDropPolicy
+		dropped []int64
+		exp     string
+	}{
+		{
+			policy:  influxdb.DropOldestReplicationPolicy,
+			dropped: []int64{0, 0, 7, 7},
+			exp:     "a v=3 3\na v=4 4\n",
+		},
+		{
+			policy:  influxdb.DropNewestReplicationPolicy,
+			dropped: []int64{0, 0, 7, 7},
+			exp:     "a v=1 1\na v=2 2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			dir := mustTempDir(t)
+			defer os.RemoveAll(dir)
+
+			// Room for two records of 16 byte headers and 7 bytes of data.
+			q := replication.NewQueue(dir, 46, tt.policy)
+			q.SegmentSize = 23
+			if err := q.Open(); err != nil {
+				t.Fatal(err)
+			}
+			defer q.Close()
+
+			for i, line := range []string{"a v=1 1", "a v=2 2", "a v=3 3", "a v=4 4"} {
+				dropped, err := q.Append([]byte(line), time.Now())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if dropped != tt.dropped[i] {
+					t.Errorf("unexpected bytes dropped for record %d -got/+exp\n%d\n%d", i, dropped, tt.dropped[i])
+				}
+			}
+
+			if got := string(mustPeek(t, q, 1000).Data); got != tt.exp {
+				t.Errorf("unexpected batch -got/+exp\n%s\n%s", got, tt.exp)
+			}
+		})
+	}
+}
+
+func TestQueue_TruncatesPartialRecord(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	q := replication.NewQueue(dir, 0, influxdb.DropOldestReplicationPolicy)
+	if err := q.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Append([]byte("a v=1 1"), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash while writing the second record.
+	f, err := os.OpenFile(filepath.Join(dir, "00000001.seg"), os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte{0, 0, 0, 7, 1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	q = replication.NewQueue(dir, 0, influxdb.DropOldestReplicationPolicy)
+	if err := q.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	if _, err := q.Append([]byte("a v=2 2"), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := string(mustPeek(t, q, 1000).Data), "a v=1 1\na v=2 2\n"; got != exp {
+		t.Errorf("unexpected batch -got/+exp\n%s\n%s", got, exp)
+	}
+}
+
+func mustTempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "replication_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func mustPeek(t *testing.T, q *replication.Queue, maxBytes int) *replication.Batch {
+	t.Helper()
+	b, err := q.Peek(maxBytes)
+	if err != nil {
+		t.Fatal(err)
+	} else if b == nil {
+		t.Fatal("expected records in queue")
+	}
+	return b
+}
+
+func segmentFiles(t *testing.T, dir string) int {
+	t.Helper()
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	for _, fi := range fis {
+		if strings.HasSuffix(fi.Name(), ".seg") {
+			n++
+		}
+	}
+	return n
+}
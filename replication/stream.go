@@ -0,0 +1,265 @@
+package replication
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+const (
+	dropReasonQueueFull = "queue_full"
+	dropReasonRejected  = "rejected"
+
+	// metricsInterval is how often the queue metrics of an idle or
+	// retrying stream are updated.
+	metricsInterval = 10 * time.Second
+)
+
+// permanentError is returned for writes the remote will never accept.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+// stream sends the writes queued for a single replication to the remote.
+type stream struct {
+	id         string
+	queue      *Queue
+	client     *http.Client
+	url        string
+	token      string
+	batchSize  int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	now        func() time.Time
+
+	logger  *zap.Logger
+	metrics *replicationMetrics
+
+	wake    chan struct{}
+	closing chan struct{}
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func newStream(r *influxdb.Replication, q *Queue, m *Manager) (*stream, error) {
+	u, err := writeURL(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stream{
+		id:    r.ID.String(),
+		queue: q,
+		client: &http.Client{
+			Timeout: m.Timeout,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout:   30 * time.Second,
+					KeepAlive: 30 * time.Second,
+				}).DialContext,
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: r.InsecureSkipVerify,
+				},
+			},
+		},
+		url:        u,
+		token:      r.RemoteToken,
+		batchSize:  m.BatchSize,
+		minBackoff: m.MinBackoff,
+		maxBackoff: m.MaxBackoff,
+		now:        m.now,
+		logger:     m.Logger.With(zap.String("replication_id", r.ID.String())),
+		metrics:    m.metrics,
+		wake:       make(chan struct{}, 1),
+		closing:    make(chan struct{}),
+	}, nil
+}
+
+// writeURL returns the URL of the write endpoint of the remote bucket.
+func writeURL(r *influxdb.Replication) (string, error) {
+	u, err := url.Parse(r.RemoteURL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/api/v2/write"
+
+	params := url.Values{}
+	params.Set("org", r.RemoteOrgID.String())
+	params.Set("bucket", r.RemoteBucketID.String())
+	params.Set("precision", "ns")
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
+func (s *stream) open() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx)
+	}()
+}
+
+// close stops sending and closes the queue.
+func (s *stream) close() error {
+	close(s.closing)
+	s.cancel()
+	s.wg.Wait()
+	return s.queue.Close()
+}
+
+// enqueue adds line protocol to the queue and wakes the sender.
+func (s *stream) enqueue(data []byte) {
+	dropped, err := s.queue.Append(data, s.now())
+	if err != nil {
+		s.logger.Error("Failed to queue write for replication", zap.Error(err))
+		dropped = int64(len(data))
+	}
+	if dropped > 0 {
+		s.metrics.droppedBytes.WithLabelValues(s.id, dropReasonQueueFull).Add(float64(dropped))
+	}
+	s.updateMetrics()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *stream) updateMetrics() {
+	s.metrics.queueBytes.WithLabelValues(s.id).Set(float64(s.queue.Size()))
+	s.metrics.lagSeconds.WithLabelValues(s.id).Set(s.queue.Lag(s.now()).Seconds())
+}
+
+// run sends batches from the queue until the stream is closed. Batches
+// that fail to send are retried with exponential backoff, except those
+// the remote rejects outright, which are dropped.
+func (s *stream) run(ctx context.Context) {
+	ticker := time.NewTicker(metricsInterval)
+	defer ticker.Stop()
+
+	backoff := s.minBackoff
+	for {
+		b, err := s.queue.Peek(s.batchSize)
+		if err != nil {
+			s.logger.Error("Failed to read replication queue", zap.Error(err))
+		} else if b == nil {
+			s.updateMetrics()
+			select {
+			case <-s.wake:
+				continue
+			case <-ticker.C:
+				continue
+			case <-s.closing:
+				return
+			}
+		} else {
+			err = s.send(ctx, b.Data)
+			if perr, ok := err.(*permanentError); ok {
+				s.logger.Error("Remote rejected replicated write; dropping it", zap.Int("records", b.Records), zap.Error(perr.err))
+				s.metrics.droppedBytes.WithLabelValues(s.id, dropReasonRejected).Add(float64(len(b.Data)))
+				err = nil
+			} else if err == nil {
+				s.metrics.sentBytes.WithLabelValues(s.id).Add(float64(len(b.Data)))
+			} else {
+				s.metrics.sendErrors.WithLabelValues(s.id).Inc()
+				s.logger.Info("Failed to send replicated write; retrying", zap.Duration("backoff", backoff), zap.Error(err))
+			}
+
+			if err == nil {
+				if err = s.queue.Advance(b); err != nil {
+					s.logger.Error("Failed to advance replication queue", zap.Error(err))
+				}
+			}
+		}
+
+		if err == nil {
+			backoff = s.minBackoff
+			s.updateMetrics()
+			continue
+		}
+
+		s.updateMetrics()
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-s.closing:
+			timer.Stop()
+			return
+		}
+		if backoff *= 2; backoff > s.maxBackoff {
+			backoff = s.maxBackoff
+		}
+	}
+}
+
+// send writes line protocol to the remote bucket.
+func (s *stream) send(ctx context.Context, data []byte) error {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(data); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", s.url, &buf)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("Content-Encoding", "gzip")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Token "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("remote write failed with status %s: %s", resp.Status, bytes.TrimSpace(body))
+
+	// Requests that were throttled, timed out or failed on the server may
+	// succeed later, as may those refused because of the configuration of
+	// the remote, e.g. an invalid token or a missing bucket. Other client
+	// errors, such as unparsable points, will not.
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusUnauthorized,
+		resp.StatusCode == http.StatusForbidden,
+		resp.StatusCode == http.StatusNotFound,
+		resp.StatusCode/100 == 5:
+		return err
+	default:
+		return &permanentError{err: err}
+	}
+}
@@ -0,0 +1,133 @@
+package influxdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Restriction restricts a permission on buckets to the series of some
+// measurements, or with some tag values. A series must satisfy both the
+// measurements and the tags of the restriction.
+type Restriction struct {
+	// Measurements, if set, are the measurements the series may be of.
+	Measurements []string `json:"measurements,omitempty"`
+	// Tags, if set, are the tag values the series must all have.
+	Tags []RestrictionTag `json:"tags,omitempty"`
+}
+
+// RestrictionTag is a tag value a series must have to satisfy a restriction.
+type RestrictionTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Valid returns an error if the restriction restricts nothing, or restricts
+// a tag key that may not be used.
+func (r *Restriction) Valid() error {
+	if len(r.Measurements) == 0 && len(r.Tags) == 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "restriction must have measurements or tags",
+		}
+	}
+	for _, m := range r.Measurements {
+		if m == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "restriction measurement cannot be empty",
+			}
+		}
+	}
+	for _, t := range r.Tags {
+		if t.Key == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "restriction tag key cannot be empty",
+			}
+		}
+		if t.Key == "_measurement" || t.Key == "_field" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("restriction tag key %q is reserved", t.Key),
+			}
+		}
+	}
+	return nil
+}
+
+// Covers returns true if every series satisfying o satisfies r.
+// A nil restriction is satisfied by every series.
+func (r *Restriction) Covers(o *Restriction) bool {
+	if r == nil {
+		return true
+	}
+	if o == nil {
+		return false
+	}
+
+	if len(r.Measurements) > 0 {
+		if len(o.Measurements) == 0 {
+			return false
+		}
+		for _, m := range o.Measurements {
+			if !r.hasMeasurement(m) {
+				return false
+			}
+		}
+	}
+
+	for _, t := range r.Tags {
+		var found bool
+		for _, ot := range o.Tags {
+			if ot == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Allows returns true if the series of the measurement with the tags
+// satisfies the restriction. tag returns the value of a tag key of the
+// series, and whether the series has it.
+func (r *Restriction) Allows(measurement string, tag func(key string) (string, bool)) bool {
+	if r == nil {
+		return true
+	}
+	if len(r.Measurements) > 0 && !r.hasMeasurement(measurement) {
+		return false
+	}
+	for _, t := range r.Tags {
+		if v, ok := tag(t.Key); !ok || v != t.Value {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Restriction) hasMeasurement(m string) bool {
+	for _, rm := range r.Measurements {
+		if rm == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Restriction) String() string {
+	var parts []string
+	if len(r.Measurements) > 0 {
+		ms := append([]string(nil), r.Measurements...)
+		sort.Strings(ms)
+		parts = append(parts, "_measurement in ("+strings.Join(ms, ",")+")")
+	}
+	for _, t := range r.Tags {
+		parts = append(parts, fmt.Sprintf("%s=%q", t.Key, t.Value))
+	}
+	return strings.Join(parts, " and ")
+}
@@ -0,0 +1,64 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestRestriction_Allows(t *testing.T) {
+	r := &influxdb.Restriction{
+		Measurements: []string{"cpu", "mem"},
+		Tags:         []influxdb.RestrictionTag{{Key: "team", Value: "payments"}},
+	}
+
+	tests := []struct {
+		name        string
+		measurement string
+		tags        map[string]string
+		allows      bool
+	}{
+		{
+			name:        "measurement and tag",
+			measurement: "mem",
+			tags:        map[string]string{"team": "payments", "host": "a"},
+			allows:      true,
+		},
+		{
+			name:        "other measurement",
+			measurement: "disk",
+			tags:        map[string]string{"team": "payments"},
+		},
+		{
+			name:        "other tag value",
+			measurement: "cpu",
+			tags:        map[string]string{"team": "billing"},
+		},
+		{
+			name:        "missing tag",
+			measurement: "cpu",
+			tags:        map[string]string{"host": "a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag := func(key string) (string, bool) {
+				v, ok := tt.tags[key]
+				return v, ok
+			}
+			if got := r.Allows(tt.measurement, tag); got != tt.allows {
+				t.Errorf("got allows = %v, expected %v", got, tt.allows)
+			}
+		})
+	}
+}
+
+func TestRestriction_String(t *testing.T) {
+	r := &influxdb.Restriction{
+		Measurements: []string{"mem", "cpu"},
+		Tags:         []influxdb.RestrictionTag{{Key: "team", Value: "payments"}},
+	}
+	if got, want := r.String(), `_measurement in (cpu,mem) and team="payments"`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,145 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// ErrRoleNotFound is the error msg for a missing role.
+const ErrRoleNotFound = "role not found"
+
+// ops for roles.
+const (
+	OpFindRoleByID = "FindRoleByID"
+	OpFindRoles    = "FindRoles"
+	OpCreateRole   = "CreateRole"
+	OpUpdateRole   = "UpdateRole"
+	OpDeleteRole   = "DeleteRole"
+)
+
+// Role is a named set of permissions on the resources of an organization.
+// Roles are assigned to users, as members of the role, and to authorizations.
+// The permissions of a role are resolved whenever its holders are
+// authorized, so that changes to a role apply to every holder at once.
+type Role struct {
+	ID          ID           `json:"id,omitempty"`
+	OrgID       ID           `json:"orgID,omitempty"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Permissions []Permission `json:"permissions"`
+	CRUDLog
+}
+
+// Valid returns an error if the role is missing required fields, or has
+// permissions outside of its organization.
+func (r *Role) Valid() error {
+	if r.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "role name is required",
+		}
+	}
+	if !r.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "role orgID is required",
+		}
+	}
+	return validRolePermissions(r.OrgID, r.Permissions)
+}
+
+func validRolePermissions(orgID ID, ps []Permission) error {
+	for _, p := range ps {
+		if err := p.Valid(); err != nil {
+			return err
+		}
+
+		inOrg := p.Resource.OrgID != nil && *p.Resource.OrgID == orgID
+		isOrg := p.Resource.Type == OrgsResourceType && p.Resource.ID != nil && *p.Resource.ID == orgID
+		if !inOrg && !isOrg {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("permission %s of role is not in the organization of the role", p),
+			}
+		}
+	}
+	return nil
+}
+
+// RoleFilter represents a set of filters that restrict the returned roles.
+type RoleFilter struct {
+	ID    *ID
+	OrgID *ID
+	Name  *string
+}
+
+// QueryParams converts RoleFilter fields to url query params.
+func (f RoleFilter) QueryParams() map[string][]string {
+	qp := url.Values{}
+	if f.ID != nil {
+		qp.Add("id", f.ID.String())
+	}
+	if f.OrgID != nil {
+		qp.Add("orgID", f.OrgID.String())
+	}
+	if f.Name != nil {
+		qp.Add("name", *f.Name)
+	}
+	return qp
+}
+
+// RoleUpdate represents updates to a role.
+// Only fields which are set are updated.
+type RoleUpdate struct {
+	Name        *string       `json:"name,omitempty"`
+	Description *string       `json:"description,omitempty"`
+	Permissions *[]Permission `json:"permissions,omitempty"`
+}
+
+// Valid returns an error if the update contains invalid values for a role of
+// the organization.
+func (u *RoleUpdate) Valid(orgID ID) error {
+	if u.Name != nil && *u.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "role name cannot be empty",
+		}
+	}
+	if u.Permissions != nil {
+		return validRolePermissions(orgID, *u.Permissions)
+	}
+	return nil
+}
+
+// Apply applies the update to a role.
+func (u *RoleUpdate) Apply(r *Role) {
+	if u.Name != nil {
+		r.Name = *u.Name
+	}
+	if u.Description != nil {
+		r.Description = *u.Description
+	}
+	if u.Permissions != nil {
+		r.Permissions = *u.Permissions
+	}
+}
+
+// RoleService is a service for managing roles.
+type RoleService interface {
+	// FindRoleByID returns a single role by ID.
+	FindRoleByID(ctx context.Context, id ID) (*Role, error)
+
+	// FindRoles returns a list of roles that match filter.
+	FindRoles(ctx context.Context, filter RoleFilter) ([]*Role, error)
+
+	// CreateRole creates a new role and sets r.ID with the new identifier.
+	CreateRole(ctx context.Context, r *Role) error
+
+	// UpdateRole updates a single role with changeset.
+	// Returns the new role state after update.
+	UpdateRole(ctx context.Context, id ID, upd RoleUpdate) (*Role, error)
+
+	// DeleteRole removes a role by ID, and unassigns it from its members.
+	DeleteRole(ctx context.Context, id ID) error
+}
@@ -64,6 +64,16 @@ func (s *Session) Allowed(p Permission) bool {
 	return PermissionAllowed(p, s.Permissions)
 }
 
+// Restrictions returns the restrictions on the data the session is allowed p on,
+// and whether it is allowed p at all.
+func (s *Session) Restrictions(p Permission) ([]*Restriction, bool) {
+	if err := s.Expired(); err != nil {
+		return nil, false
+	}
+
+	return PermissionRestrictions(p, s.Permissions)
+}
+
 // Kind returns session and is used for auditing.
 func (s *Session) Kind() string { return SessionAuthorizionKind }
 
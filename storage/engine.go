@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"os"
 	"sync"
 	"time"
 
@@ -104,6 +105,16 @@ func WithFileStoreObserver(obs tsm1.FileStoreObserver) Option {
 	}
 }
 
+// WithCompactionsDisabled prevents the engine from snapshotting the cache,
+// compacting TSM files or compacting index files, which is required by
+// offline tools that must not rewrite the files they read.
+func WithCompactionsDisabled() Option {
+	return func(e *Engine) {
+		e.engine.SetEnabled(false)
+		tsi1.DisableCompactions()(e.index)
+	}
+}
+
 // WithCompactionPlanner makes the engine have the provided compaction planner.
 func WithCompactionPlanner(planner tsm1.CompactionPlanner) Option {
 	return func(e *Engine) {
@@ -280,6 +291,85 @@ func (e *Engine) replayWAL() error {
 	return err
 }
 
+// LoadWAL reads the WAL segment files into the cache without writing their
+// series to the index and series file, and without truncating corrupt
+// segments. It allows offline tools to read data that has not yet been
+// snapshotted to TSM files while the engine is opened with the WAL disabled.
+//
+// The values of series that are missing from the index, which only happens
+// when influxd stopped between appending a write to the WAL and indexing it,
+// are loaded but cannot be found by cursors.
+func (e *Engine) LoadWAL(ctx context.Context) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	walPaths, err := wal.SegmentFileNames(e.config.GetWALPath(e.path))
+	if err != nil {
+		return err
+	}
+
+	// Disable the max size during loading
+	limit := e.engine.Cache.MaxSize()
+	defer func() { e.engine.Cache.SetMaxSize(limit) }()
+	e.engine.Cache.SetMaxSize(0)
+
+	for _, path := range walPaths {
+		if err := e.loadWALSegment(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadWALSegment reads the entries of a single WAL segment file into the
+// cache. Deletes were applied to the TSM files and the index when they were
+// made, so they are only applied to the values loaded before them.
+func (e *Engine) loadWALSegment(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	r := wal.NewWALSegmentReader(f)
+	defer r.Close()
+
+	for r.Next() {
+		entry, err := r.Read()
+		if err != nil {
+			e.logger.Info("File corrupt", zap.Error(err), zap.String("path", path), zap.Int64("pos", r.Count()))
+			break
+		}
+
+		switch en := entry.(type) {
+		case *wal.WriteWALEntry:
+			if err := e.engine.Cache.WriteMulti(en.Values); err != nil {
+				return err
+			}
+
+		case *wal.DeleteBucketRangeWALEntry:
+			var pred tsm1.Predicate
+			if len(en.Predicate) > 0 {
+				pred, err = tsm1.UnmarshalPredicate(en.Predicate)
+				if err != nil {
+					return err
+				}
+			}
+
+			encoded := tsdb.EncodeName(en.OrgID, en.BucketID)
+			name := models.EscapeMeasurement(encoded[:])
+			e.engine.Cache.DeleteBucketRange(name, en.Min, en.Max, pred)
+		}
+	}
+
+	return r.Close()
+}
+
 // runRetentionEnforcer runs the retention enforcer in a separate goroutine.
 //
 // Currently this just runs on an interval, but in the future we will add the
@@ -378,7 +468,36 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
-	collection, j := tsdb.NewSeriesCollection(points), 0
+	collection := tsdb.NewSeriesCollection(points)
+	ValidateSeriesCollection(collection)
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	// Convert the collection to values for adding to the WAL/Cache.
+	values, err := tsm1.CollectionToValues(collection)
+	if err != nil {
+		return err
+	}
+
+	// Add the write to the WAL to be replayed if there is a crash or shutdown.
+	if _, err := e.wal.WriteMulti(ctx, values); err != nil {
+		return err
+	}
+
+	return e.writePointsLocked(ctx, collection, values)
+}
+
+// ValidateSeriesCollection removes the points in collection that cannot be
+// stored by the engine, e.g. because they are missing the measurement or field
+// tags or contain invalid tag keys. The first reason for dropping a point is
+// recorded in the collection.
+func ValidateSeriesCollection(collection *tsdb.SeriesCollection) {
+	j := 0
 
 	// dropPoint should be called whenever there is reason to drop a point from
 	// the batch.
@@ -436,26 +555,6 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 		j++
 	}
 	collection.Truncate(j)
-
-	e.mu.RLock()
-	defer e.mu.RUnlock()
-
-	if e.closing == nil {
-		return ErrEngineClosed
-	}
-
-	// Convert the collection to values for adding to the WAL/Cache.
-	values, err := tsm1.CollectionToValues(collection)
-	if err != nil {
-		return err
-	}
-
-	// Add the write to the WAL to be replayed if there is a crash or shutdown.
-	if _, err := e.wal.WriteMulti(ctx, values); err != nil {
-		return err
-	}
-
-	return e.writePointsLocked(ctx, collection, values)
 }
 
 // writePointsLocked does the work of writing points and must be called under some sort of lock.
@@ -618,3 +717,35 @@ func (e *Engine) MeasurementCardinalityStats() tsi1.MeasurementCardinalityStats
 func (e *Engine) MeasurementStats() (tsm1.MeasurementStats, error) {
 	return e.engine.MeasurementStats()
 }
+
+var _ platform.StorageUsageService = (*Engine)(nil)
+
+// FindOrganizationStorageUsage returns the disk space used by the TSM blocks
+// and index entries of all buckets of an organization.
+func (e *Engine) FindOrganizationStorageUsage(ctx context.Context, orgID platform.ID) (*platform.StorageUsage, error) {
+	prefix := tsdb.EncodeOrgName(orgID)
+	return e.storageUsage(ctx, prefix[:])
+}
+
+// FindBucketStorageUsage returns the disk space used by the TSM blocks and
+// index entries of a bucket.
+func (e *Engine) FindBucketStorageUsage(ctx context.Context, orgID, bucketID platform.ID) (*platform.StorageUsage, error) {
+	name := tsdb.EncodeName(orgID, bucketID)
+	return e.storageUsage(ctx, name[:])
+}
+
+func (e *Engine) storageUsage(ctx context.Context, prefix []byte) (*platform.StorageUsage, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	return &platform.StorageUsage{
+		TSMBytes:   e.engine.DiskSizeBytesByPrefix(prefix),
+		IndexBytes: e.index.DiskSizeBytesByPrefix(prefix),
+	}, nil
+}
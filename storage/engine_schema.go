@@ -36,3 +36,15 @@ func (e *Engine) TagValues(ctx context.Context, orgID, bucketID influxdb.ID, tag
 
 	return e.engine.TagValues(ctx, orgID, bucketID, tagKey, start, end, predicate)
 }
+
+// CountSeries returns the number of series in the given bucket matching the
+// predicate, which have data within the time range (start, end].
+func (e *Engine) CountSeries(ctx context.Context, orgID, bucketID influxdb.ID, start, end int64, predicate influxql.Expr) (int64, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return 0, nil
+	}
+
+	return e.engine.CountSeries(ctx, orgID, bucketID, start, end, predicate)
+}
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"math"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/storage"
 	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/storage/wal"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/tsm1"
 	"github.com/prometheus/client_golang/prometheus"
@@ -455,6 +457,102 @@ func TestEngine_WALDisabled(t *testing.T) {
 	}
 }
 
+func TestEngine_LoadWAL(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+	tags := models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"})
+	for _, ts := range []int64{1, 2, 3} {
+		pt := models.MustNewPoint(name, tags, map[string]interface{}{"value": float64(ts)}, time.Unix(0, ts))
+		if err := engine.Engine.WritePoints(context.TODO(), []models.Point{pt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := engine.DeleteBucketRange(engine.org, engine.bucket, 2, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing the engine does not snapshot the cache, so the values are only
+	// stored in the WAL.
+	if err := engine.Engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	config := storage.NewConfig()
+	walPath := config.GetWALPath(engine.path)
+	walSize := func() int64 {
+		var size int64
+		segs, err := wal.SegmentFileNames(walPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, seg := range segs {
+			fi, err := os.Stat(seg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			size += fi.Size()
+		}
+		return size
+	}
+	before := walSize()
+
+	config.WAL.Enabled = false
+	engine.Engine = storage.NewEngine(engine.path, config, storage.WithCompactionsDisabled())
+	engine.MustOpen()
+
+	readValues := func() []int64 {
+		itr, err := engine.CreateCursorIterator(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		cur, err := itr.Next(context.Background(), &tsdb.CursorRequest{
+			Name:      []byte(name),
+			Tags:      tags,
+			Field:     "value",
+			Ascending: true,
+			StartTime: models.MinNanoTime,
+			EndTime:   models.MaxNanoTime,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cur == nil {
+			t.Fatal("series not found")
+		}
+		defer cur.Close()
+
+		var ts []int64
+		fc := cur.(tsdb.FloatArrayCursor)
+		for a := fc.Next(); a.Len() > 0; a = fc.Next() {
+			ts = append(ts, a.Timestamps...)
+		}
+		return ts
+	}
+
+	if got := readValues(); len(got) != 0 {
+		t.Fatalf("got values %v before loading the WAL, exp none", got)
+	}
+
+	if err := engine.LoadWAL(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := readValues(), []int64{1, 3}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got values %v, exp %v", got, exp)
+	}
+
+	if got, exp := engine.SeriesCardinality(), int64(1); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+
+	if got := walSize(); got != before {
+		t.Fatalf("got WAL size %d, exp %d", got, before)
+	}
+}
+
 func TestEngine_WriteConflictingBatch(t *testing.T) {
 	engine := NewDefaultEngine()
 	defer engine.Close()
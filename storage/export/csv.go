@@ -0,0 +1,155 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/tsdb/cursors"
+)
+
+// csvEncoder writes each series as a table in the annotated CSV format used by
+// Flux query results, so exported data can be read back with csv.from().
+type csvEncoder struct {
+	bw          *bufio.Writer
+	w           *csv.Writer
+	start, stop string
+	table       int
+	last        *series
+	row         []string
+}
+
+func newCSVEncoder(w io.Writer, start, end int64) *csvEncoder {
+	bw := bufio.NewWriter(w)
+	cw := csv.NewWriter(bw)
+	cw.UseCRLF = true
+	return &csvEncoder{
+		bw:    bw,
+		w:     cw,
+		start: formatTime(start),
+		stop:  formatTime(end),
+		table: -1,
+	}
+}
+
+func formatTime(ts int64) string {
+	return time.Unix(0, ts).UTC().Format(time.RFC3339Nano)
+}
+
+// writeHeader writes the annotations and column names of a new table.
+func (e *csvEncoder) writeHeader(s *series, datatype string) error {
+	if e.table >= 0 {
+		// Tables are separated by an empty line.
+		e.w.Flush()
+		if err := e.w.Error(); err != nil {
+			return err
+		}
+		if _, err := e.bw.WriteString("\r\n"); err != nil {
+			return err
+		}
+	}
+	e.table++
+
+	n := 9 + len(s.tags)
+	datatypes := make([]string, 0, n)
+	datatypes = append(datatypes, "#datatype", "string", "long", "dateTime:RFC3339", "dateTime:RFC3339", "dateTime:RFC3339", datatype, "string", "string")
+	groups := make([]string, 0, n)
+	groups = append(groups, "#group", "false", "false", "true", "true", "false", "false", "true", "true")
+	defaults := make([]string, n)
+	defaults[0], defaults[1] = "#default", "_result"
+	columns := make([]string, 0, n)
+	columns = append(columns, "", "result", "table", "_start", "_stop", "_time", "_value", "_field", "_measurement")
+	for _, t := range s.tags {
+		datatypes = append(datatypes, "string")
+		groups = append(groups, "true")
+		columns = append(columns, string(t.Key))
+	}
+
+	for _, record := range [][]string{datatypes, groups, defaults, columns} {
+		if err := e.w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	e.row = append(e.row[:0], "", "", strconv.Itoa(e.table), e.start, e.stop, "", "", string(s.field), string(s.measurement))
+	for _, t := range s.tags {
+		e.row = append(e.row, string(t.Value))
+	}
+	return nil
+}
+
+func (e *csvEncoder) Encode(s *series, values interface{}) error {
+	var datatype string
+	switch values.(type) {
+	case *cursors.FloatArray:
+		datatype = "double"
+	case *cursors.IntegerArray:
+		datatype = "long"
+	case *cursors.UnsignedArray:
+		datatype = "unsignedLong"
+	case *cursors.StringArray:
+		datatype = "string"
+	case *cursors.BooleanArray:
+		datatype = "boolean"
+	default:
+		return fmt.Errorf("unsupported array type %T", values)
+	}
+
+	if s != e.last {
+		if err := e.writeHeader(s, datatype); err != nil {
+			return err
+		}
+		e.last = s
+	}
+
+	switch a := values.(type) {
+	case *cursors.FloatArray:
+		for i, v := range a.Values {
+			if err := e.writeRow(a.Timestamps[i], strconv.FormatFloat(v, 'f', -1, 64)); err != nil {
+				return err
+			}
+		}
+	case *cursors.IntegerArray:
+		for i, v := range a.Values {
+			if err := e.writeRow(a.Timestamps[i], strconv.FormatInt(v, 10)); err != nil {
+				return err
+			}
+		}
+	case *cursors.UnsignedArray:
+		for i, v := range a.Values {
+			if err := e.writeRow(a.Timestamps[i], strconv.FormatUint(v, 10)); err != nil {
+				return err
+			}
+		}
+	case *cursors.StringArray:
+		for i, v := range a.Values {
+			if err := e.writeRow(a.Timestamps[i], v); err != nil {
+				return err
+			}
+		}
+	case *cursors.BooleanArray:
+		for i, v := range a.Values {
+			if err := e.writeRow(a.Timestamps[i], strconv.FormatBool(v)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *csvEncoder) writeRow(ts int64, v string) error {
+	e.row[5] = formatTime(ts)
+	e.row[6] = v
+	return e.w.Write(e.row)
+}
+
+func (e *csvEncoder) Close() error {
+	e.w.Flush()
+	if err := e.w.Error(); err != nil {
+		return err
+	}
+	return e.bw.Flush()
+}
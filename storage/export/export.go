@@ -0,0 +1,302 @@
+// Package export streams series data out of the storage engine as line
+// protocol, annotated CSV or Apache Parquet.
+//
+// The Exporter reads through a reads.Store, so the same code path is used by
+// the online /api/v2/export endpoint, which reads from the running engine,
+// and by the offline `influxd inspect export` command, which opens the
+// engine files (TSM, WAL and index) directly.
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+)
+
+// Format identifies the encoding of exported data.
+type Format string
+
+// Supported export formats.
+const (
+	LineProtocol Format = "lp"
+	CSV          Format = "csv"
+	Parquet      Format = "parquet"
+)
+
+// ParseFormat returns the Format identified by s.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "", "lp", "line-protocol", "line_protocol":
+		return LineProtocol, nil
+	case "csv":
+		return CSV, nil
+	case "parquet":
+		return Parquet, nil
+	default:
+		return "", &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unsupported export format %q; valid formats are lp, csv and parquet", s),
+		}
+	}
+}
+
+// ContentType returns the MIME type of the Format.
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case Parquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Filter selects the series and time range to export.
+type Filter struct {
+	OrgID    influxdb.ID
+	BucketID influxdb.ID
+
+	// Start and End define the half-open time range [Start, End), in
+	// nanoseconds since the epoch.
+	Start int64
+	End   int64
+
+	// Measurement, when set, restricts the export to a single measurement.
+	Measurement string
+
+	// Predicate, when set, restricts the export to series matching it. It is
+	// combined with Measurement using a logical AND.
+	Predicate *datatypes.Predicate
+}
+
+// Stats describes the data written by an export.
+type Stats struct {
+	Series int
+	Values int
+}
+
+// Exporter writes series data read from a reads.Store.
+type Exporter struct {
+	store reads.Store
+}
+
+// NewExporter returns an Exporter reading from store.
+func NewExporter(store reads.Store) *Exporter {
+	return &Exporter{store: store}
+}
+
+// Export writes all values matching f to w, encoded as format.
+func (e *Exporter) Export(ctx context.Context, w io.Writer, format Format, f Filter) (Stats, error) {
+	var stats Stats
+
+	src, err := types.MarshalAny(e.store.GetSource(uint64(f.OrgID), uint64(f.BucketID)))
+	if err != nil {
+		return stats, err
+	}
+	pred := f.predicate()
+
+	var enc encoder
+	switch format {
+	case LineProtocol:
+		enc = newLineProtocolEncoder(w)
+	case CSV:
+		enc = newCSVEncoder(w, f.Start, f.End)
+	case Parquet:
+		keys, err := e.tagKeys(ctx, src, pred, f)
+		if err != nil {
+			return stats, err
+		}
+		enc = newParquetEncoder(w, keys)
+	default:
+		_, err := ParseFormat(string(format))
+		return stats, err
+	}
+
+	req := &datatypes.ReadFilterRequest{
+		ReadSource: src,
+		Predicate:  pred,
+	}
+	req.Range.Start = f.Start
+	req.Range.End = f.End
+
+	rs, err := e.store.ReadFilter(ctx, req)
+	if err != nil {
+		return stats, err
+	}
+
+	if rs != nil {
+		defer rs.Close()
+
+		for rs.Next() {
+			cur := rs.Cursor()
+			if cur == nil {
+				continue
+			}
+
+			s := newSeries(rs.Tags())
+			n, err := exportCursor(enc, s, cur)
+			cur.Close()
+			if err != nil {
+				return stats, err
+			}
+			if n > 0 {
+				stats.Series++
+				stats.Values += n
+			}
+		}
+		if err := rs.Err(); err != nil {
+			return stats, err
+		}
+	}
+
+	return stats, enc.Close()
+}
+
+// tagKeys returns the tag keys of all series selected by the filter, excluding
+// the measurement and field keys.
+func (e *Exporter) tagKeys(ctx context.Context, src *types.Any, pred *datatypes.Predicate, f Filter) ([]string, error) {
+	req := &datatypes.TagKeysRequest{
+		TagsSource: src,
+		Predicate:  pred,
+	}
+	req.Range.Start = f.Start
+	req.Range.End = f.End
+
+	itr, err := e.store.TagKeys(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for itr != nil && itr.Next() {
+		switch v := itr.Value(); v {
+		case models.MeasurementTagKey, models.FieldKeyTagKey:
+		default:
+			keys = append(keys, v)
+		}
+	}
+	return keys, nil
+}
+
+// predicate returns the storage predicate combining the measurement and
+// predicate filters.
+func (f Filter) predicate() *datatypes.Predicate {
+	var root *datatypes.Node
+	if f.Measurement != "" {
+		root = &datatypes.Node{
+			NodeType: datatypes.NodeTypeComparisonExpression,
+			Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonEqual},
+			Children: []*datatypes.Node{
+				{NodeType: datatypes.NodeTypeTagRef, Value: &datatypes.Node_TagRefValue{TagRefValue: models.MeasurementTagKey}},
+				{NodeType: datatypes.NodeTypeLiteral, Value: &datatypes.Node_StringValue{StringValue: f.Measurement}},
+			},
+		}
+	}
+
+	if other := f.Predicate.GetRoot(); other != nil {
+		if root == nil {
+			root = other
+		} else {
+			root = &datatypes.Node{
+				NodeType: datatypes.NodeTypeLogicalExpression,
+				Value:    &datatypes.Node_Logical_{Logical: datatypes.LogicalAnd},
+				Children: []*datatypes.Node{root, other},
+			}
+		}
+	}
+
+	if root == nil {
+		return nil
+	}
+	return &datatypes.Predicate{Root: root}
+}
+
+var (
+	measurementKeyBytes = []byte("_measurement")
+	fieldKeyBytes       = []byte("_field")
+)
+
+// series identifies the series a cursor belongs to.
+type series struct {
+	measurement []byte
+	field       []byte
+	tags        models.Tags // excludes the measurement and field
+}
+
+func newSeries(tags models.Tags) *series {
+	s := &series{tags: make(models.Tags, 0, len(tags))}
+	for _, t := range tags {
+		switch {
+		case bytes.Equal(t.Key, measurementKeyBytes):
+			s.measurement = t.Value
+		case bytes.Equal(t.Key, fieldKeyBytes):
+			s.field = t.Value
+		default:
+			s.tags = append(s.tags, t)
+		}
+	}
+	return s
+}
+
+// encoder encodes blocks of values for a series. The values are one of the
+// cursors array types, such as *cursors.FloatArray.
+type encoder interface {
+	Encode(s *series, values interface{}) error
+	Close() error
+}
+
+// exportCursor encodes all values produced by cur and returns the number of
+// values written.
+func exportCursor(enc encoder, s *series, cur cursors.Cursor) (int, error) {
+	var n int
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			if err := enc.Encode(s, a); err != nil {
+				return n, err
+			}
+			n += a.Len()
+		}
+	case cursors.IntegerArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			if err := enc.Encode(s, a); err != nil {
+				return n, err
+			}
+			n += a.Len()
+		}
+	case cursors.UnsignedArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			if err := enc.Encode(s, a); err != nil {
+				return n, err
+			}
+			n += a.Len()
+		}
+	case cursors.StringArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			if err := enc.Encode(s, a); err != nil {
+				return n, err
+			}
+			n += a.Len()
+		}
+	case cursors.BooleanArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			if err := enc.Encode(s, a); err != nil {
+				return n, err
+			}
+			n += a.Len()
+		}
+	default:
+		return n, fmt.Errorf("unsupported cursor type %T", cur)
+	}
+	return n, cur.Err()
+}
@@ -0,0 +1,239 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/export"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/storage/readservice"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+const (
+	orgID    = influxdb.ID(0x3131313131313131)
+	bucketID = influxdb.ID(0x3232323232323232)
+)
+
+func TestExporter_Export(t *testing.T) {
+	engine := NewEngine(t, `
+cpu,host=a,region=west usage=1.5 10
+cpu,host=a,region=west usage=2.5 20
+cpu,host=b count=3i 10
+mem,host=a free=4i 10
+mem,host=a ok=true 10
+mem,host=a msg="hello \"world\"" 10
+`)
+	defer engine.Close()
+
+	tests := []struct {
+		name   string
+		format export.Format
+		filter export.Filter
+		exp    string
+		stats  export.Stats
+	}{
+		{
+			name:   "line protocol",
+			format: export.LineProtocol,
+			filter: export.Filter{Start: models.MinNanoTime, End: models.MaxNanoTime},
+			exp: `cpu,host=a,region=west usage=1.5 10
+cpu,host=a,region=west usage=2.5 20
+cpu,host=b count=3i 10
+mem,host=a free=4i 10
+mem,host=a msg="hello \"world\"" 10
+mem,host=a ok=true 10
+`,
+			stats: export.Stats{Series: 5, Values: 6},
+		},
+		{
+			name:   "time range",
+			format: export.LineProtocol,
+			filter: export.Filter{Start: 15, End: 25},
+			exp: `cpu,host=a,region=west usage=2.5 20
+`,
+			stats: export.Stats{Series: 1, Values: 1},
+		},
+		{
+			name:   "measurement",
+			format: export.LineProtocol,
+			filter: export.Filter{Start: models.MinNanoTime, End: models.MaxNanoTime, Measurement: "cpu"},
+			exp: `cpu,host=a,region=west usage=1.5 10
+cpu,host=a,region=west usage=2.5 20
+cpu,host=b count=3i 10
+`,
+			stats: export.Stats{Series: 2, Values: 3},
+		},
+		{
+			name:   "measurement and predicate",
+			format: export.LineProtocol,
+			filter: export.Filter{
+				Start:       models.MinNanoTime,
+				End:         models.MaxNanoTime,
+				Measurement: "cpu",
+				Predicate:   mustParsePredicate(t, `(r) => r.host == "b"`),
+			},
+			exp: `cpu,host=b count=3i 10
+`,
+			stats: export.Stats{Series: 1, Values: 1},
+		},
+		{
+			name:   "csv",
+			format: export.CSV,
+			filter: export.Filter{Start: 0, End: 100, Measurement: "cpu"},
+			exp: `#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,long,string,string,string` + "\r\n" +
+				`#group,false,false,true,true,false,false,true,true,true` + "\r\n" +
+				`#default,_result,,,,,,,,` + "\r\n" +
+				`,result,table,_start,_stop,_time,_value,_field,_measurement,host` + "\r\n" +
+				`,,0,1970-01-01T00:00:00Z,1970-01-01T00:00:00.0000001Z,1970-01-01T00:00:00.00000001Z,3,count,cpu,b` + "\r\n" +
+				"\r\n" +
+				`#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,double,string,string,string,string` + "\r\n" +
+				`#group,false,false,true,true,false,false,true,true,true,true` + "\r\n" +
+				`#default,_result,,,,,,,,,` + "\r\n" +
+				`,result,table,_start,_stop,_time,_value,_field,_measurement,host,region` + "\r\n" +
+				`,,1,1970-01-01T00:00:00Z,1970-01-01T00:00:00.0000001Z,1970-01-01T00:00:00.00000001Z,1.5,usage,cpu,a,west` + "\r\n" +
+				`,,1,1970-01-01T00:00:00Z,1970-01-01T00:00:00.0000001Z,1970-01-01T00:00:00.00000002Z,2.5,usage,cpu,a,west` + "\r\n",
+			stats: export.Stats{Series: 2, Values: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.OrgID, tt.filter.BucketID = orgID, bucketID
+
+			var buf bytes.Buffer
+			stats, err := export.NewExporter(engine.Store()).Export(context.Background(), &buf, tt.format, tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := buf.String()
+			if tt.format == export.LineProtocol {
+				// Series are emitted in index order; compare lines in a stable order.
+				got = sortLines(got)
+			}
+			if got != tt.exp {
+				t.Errorf("unexpected output -got/+exp\n%s\n%s", got, tt.exp)
+			}
+			if stats != tt.stats {
+				t.Errorf("unexpected stats -got/+exp\n%+v\n%+v", stats, tt.stats)
+			}
+		})
+	}
+}
+
+func TestExporter_Export_Parquet(t *testing.T) {
+	engine := NewEngine(t, `
+cpu,host=a usage=1.5 10
+mem,region=west free=4i 10
+`)
+	defer engine.Close()
+
+	var buf bytes.Buffer
+	stats, err := export.NewExporter(engine.Store()).Export(context.Background(), &buf, export.Parquet, export.Filter{
+		OrgID:    orgID,
+		BucketID: bucketID,
+		Start:    models.MinNanoTime,
+		End:      models.MaxNanoTime,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exp := (export.Stats{Series: 2, Values: 2}); stats != exp {
+		t.Errorf("unexpected stats -got/+exp\n%+v\n%+v", stats, exp)
+	}
+
+	b := buf.Bytes()
+	if !bytes.HasPrefix(b, []byte("PAR1")) || !bytes.HasSuffix(b, []byte("PAR1")) {
+		t.Fatal("output is not a parquet file")
+	}
+	for _, col := range []string{"_measurement", "_field", "host", "region", "_time", "_value_float", "_value_integer"} {
+		if !bytes.Contains(b, []byte(col)) {
+			t.Errorf("parquet schema is missing column %q", col)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for s, exp := range map[string]export.Format{
+		"":              export.LineProtocol,
+		"lp":            export.LineProtocol,
+		"line-protocol": export.LineProtocol,
+		"CSV":           export.CSV,
+		"parquet":       export.Parquet,
+	} {
+		if got, err := export.ParseFormat(s); err != nil || got != exp {
+			t.Errorf("ParseFormat(%q) = %q, %v; expected %q", s, got, err, exp)
+		}
+	}
+
+	if _, err := export.ParseFormat("json"); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected invalid error, got %v", err)
+	}
+}
+
+func sortLines(s string) string {
+	lines := strings.SplitAfter(s, "\n")
+	sort.Strings(lines)
+	return strings.Join(lines, "")
+}
+
+func mustParsePredicate(t *testing.T, src string) *datatypes.Predicate {
+	t.Helper()
+	p, err := reads.ParsePredicate(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// Engine is a storage engine populated with test data.
+type Engine struct {
+	*storage.Engine
+	path string
+}
+
+// NewEngine returns an open engine with data written to the test bucket.
+func NewEngine(t *testing.T, data string) *Engine {
+	t.Helper()
+
+	path, err := ioutil.TempDir("", "export_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := &Engine{Engine: storage.NewEngine(path, storage.NewConfig()), path: path}
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	name := tsdb.EncodeName(orgID, bucketID)
+	points, err := models.ParsePointsWithPrecision([]byte(strings.TrimSpace(data)), models.EscapeMeasurement(name[:]), time.Now(), "ns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.WritePoints(context.Background(), points); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// Store returns a reads.Store for the engine.
+func (e *Engine) Store() reads.Store {
+	return readservice.NewStore(e.Engine)
+}
+
+// Close closes the engine and removes its data.
+func (e *Engine) Close() error {
+	defer os.RemoveAll(e.path)
+	return e.Engine.Close()
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/escape"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+)
+
+// lineProtocolEncoder writes one line of line protocol per value.
+type lineProtocolEncoder struct {
+	w      *bufio.Writer
+	prefix []byte // escaped "measurement,tags field=" of the current series.
+	last   *series
+	buf    []byte
+}
+
+func newLineProtocolEncoder(w io.Writer) *lineProtocolEncoder {
+	return &lineProtocolEncoder{w: bufio.NewWriter(w)}
+}
+
+func (e *lineProtocolEncoder) Encode(s *series, values interface{}) error {
+	if s != e.last {
+		e.prefix = models.AppendMakeKey(e.prefix[:0], s.measurement, s.tags)
+		e.prefix = append(e.prefix, ' ')
+		e.prefix = append(e.prefix, escape.Bytes(s.field)...)
+		e.prefix = append(e.prefix, '=')
+		e.last = s
+	}
+
+	switch a := values.(type) {
+	case *cursors.FloatArray:
+		for i, v := range a.Values {
+			e.buf = strconv.AppendFloat(append(e.buf[:0], e.prefix...), v, 'f', -1, 64)
+			if err := e.writeLine(a.Timestamps[i]); err != nil {
+				return err
+			}
+		}
+	case *cursors.IntegerArray:
+		for i, v := range a.Values {
+			e.buf = strconv.AppendInt(append(e.buf[:0], e.prefix...), v, 10)
+			e.buf = append(e.buf, 'i')
+			if err := e.writeLine(a.Timestamps[i]); err != nil {
+				return err
+			}
+		}
+	case *cursors.UnsignedArray:
+		for i, v := range a.Values {
+			e.buf = strconv.AppendUint(append(e.buf[:0], e.prefix...), v, 10)
+			e.buf = append(e.buf, 'u')
+			if err := e.writeLine(a.Timestamps[i]); err != nil {
+				return err
+			}
+		}
+	case *cursors.StringArray:
+		for i, v := range a.Values {
+			e.buf = append(append(e.buf[:0], e.prefix...), '"')
+			e.buf = append(e.buf, models.EscapeStringField(v)...)
+			e.buf = append(e.buf, '"')
+			if err := e.writeLine(a.Timestamps[i]); err != nil {
+				return err
+			}
+		}
+	case *cursors.BooleanArray:
+		for i, v := range a.Values {
+			e.buf = strconv.AppendBool(append(e.buf[:0], e.prefix...), v)
+			if err := e.writeLine(a.Timestamps[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported array type %T", values)
+	}
+	return nil
+}
+
+// writeLine appends the timestamp to the current line and writes it.
+func (e *lineProtocolEncoder) writeLine(ts int64) error {
+	e.buf = append(e.buf, ' ')
+	e.buf = strconv.AppendInt(e.buf, ts, 10)
+	e.buf = append(e.buf, '\n')
+	_, err := e.w.Write(e.buf)
+	return err
+}
+
+func (e *lineProtocolEncoder) Close() error {
+	return e.w.Flush()
+}
@@ -0,0 +1,137 @@
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/influxdata/influxdb/pkg/parquet"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+)
+
+// Names of the typed value columns written to Parquet files. A row only has a
+// value in the column matching the type of its field.
+const (
+	parquetFloatColumn    = "_value_float"
+	parquetIntegerColumn  = "_value_integer"
+	parquetUnsignedColumn = "_value_unsigned"
+	parquetStringColumn   = "_value_string"
+	parquetBooleanColumn  = "_value_boolean"
+)
+
+// parquetEncoder writes values as rows of a single Parquet file with the
+// columns _measurement, _field, one optional column per tag key, _time and
+// one optional column per value type.
+type parquetEncoder struct {
+	w    *parquet.Writer
+	tags map[string]int // tag key to column index
+	row  []interface{}
+	last *series
+
+	timeCol  int // index of the _time column
+	valueCol int // index of the first value column
+}
+
+func newParquetEncoder(w io.Writer, tagKeys []string) *parquetEncoder {
+	cols := []parquet.Column{
+		{Name: "_measurement", Type: parquet.String},
+		{Name: "_field", Type: parquet.String},
+	}
+	tags := make(map[string]int, len(tagKeys))
+	for _, k := range tagKeys {
+		tags[k] = len(cols)
+		cols = append(cols, parquet.Column{Name: k, Type: parquet.String, Optional: true})
+	}
+	timeCol := len(cols)
+	cols = append(cols, parquet.Column{Name: "_time", Type: parquet.TimestampNanos})
+	cols = append(cols,
+		parquet.Column{Name: parquetFloatColumn, Type: parquet.Double, Optional: true},
+		parquet.Column{Name: parquetIntegerColumn, Type: parquet.Int64, Optional: true},
+		parquet.Column{Name: parquetUnsignedColumn, Type: parquet.Uint64, Optional: true},
+		parquet.Column{Name: parquetStringColumn, Type: parquet.String, Optional: true},
+		parquet.Column{Name: parquetBooleanColumn, Type: parquet.Boolean, Optional: true},
+	)
+
+	return &parquetEncoder{
+		w:        parquet.NewWriter(w, cols),
+		tags:     tags,
+		row:      make([]interface{}, len(cols)),
+		timeCol:  timeCol,
+		valueCol: timeCol + 1,
+	}
+}
+
+// resetRow prepares the row buffer for the values of s.
+func (e *parquetEncoder) resetRow(s *series) error {
+	for i := range e.row {
+		e.row[i] = nil
+	}
+	e.row[0] = string(s.measurement)
+	e.row[1] = string(s.field)
+	for _, t := range s.tags {
+		i, ok := e.tags[string(t.Key)]
+		if !ok {
+			return fmt.Errorf("tag key %q is missing from the parquet schema", t.Key)
+		}
+		e.row[i] = string(t.Value)
+	}
+	return nil
+}
+
+func (e *parquetEncoder) Encode(s *series, values interface{}) error {
+	if s != e.last {
+		if err := e.resetRow(s); err != nil {
+			return err
+		}
+		e.last = s
+	}
+
+	ts, col := e.timeCol, e.valueCol
+	switch a := values.(type) {
+	case *cursors.FloatArray:
+		for i, v := range a.Values {
+			e.row[ts], e.row[col] = a.Timestamps[i], v
+			if err := e.w.Write(e.row); err != nil {
+				return err
+			}
+		}
+	case *cursors.IntegerArray:
+		col++
+		for i, v := range a.Values {
+			e.row[ts], e.row[col] = a.Timestamps[i], v
+			if err := e.w.Write(e.row); err != nil {
+				return err
+			}
+		}
+	case *cursors.UnsignedArray:
+		col += 2
+		for i, v := range a.Values {
+			e.row[ts], e.row[col] = a.Timestamps[i], v
+			if err := e.w.Write(e.row); err != nil {
+				return err
+			}
+		}
+	case *cursors.StringArray:
+		col += 3
+		for i, v := range a.Values {
+			e.row[ts], e.row[col] = a.Timestamps[i], v
+			if err := e.w.Write(e.row); err != nil {
+				return err
+			}
+		}
+	case *cursors.BooleanArray:
+		col += 4
+		for i, v := range a.Values {
+			e.row[ts], e.row[col] = a.Timestamps[i], v
+			if err := e.w.Write(e.row); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported array type %T", values)
+	}
+	return nil
+}
+
+func (e *parquetEncoder) Close() error {
+	return e.w.Close()
+}
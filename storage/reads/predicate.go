@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
 	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/storage/reads/datatypes"
@@ -155,6 +156,34 @@ func (v *predicateExpressionPrinter) Visit(n *datatypes.Node) NodeVisitor {
 	}
 }
 
+// ParsePredicate parses the source of a Flux predicate function, such as
+// `(r) => r._measurement == "cpu" and r.host == "server01"`, and returns the
+// equivalent storage predicate.
+func ParsePredicate(src string) (*datatypes.Predicate, error) {
+	pkg := parser.ParseSource(src)
+	if ast.Check(pkg) > 0 {
+		return nil, ast.GetError(pkg)
+	}
+
+	sp, err := semantic.New(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sp.Files) != 1 || len(sp.Files[0].Body) != 1 {
+		return nil, errors.New("predicate must be a single function expression")
+	}
+	stmt, ok := sp.Files[0].Body[0].(*semantic.ExpressionStatement)
+	if !ok {
+		return nil, errors.New("predicate must be a single function expression")
+	}
+	f, ok := stmt.Expression.(*semantic.FunctionExpression)
+	if !ok {
+		return nil, fmt.Errorf("predicate must be a function expression, got %T", stmt.Expression)
+	}
+	return toStoragePredicate(f)
+}
+
 func toStoragePredicate(f *semantic.FunctionExpression) (*datatypes.Predicate, error) {
 	if f.Block.Parameters == nil || len(f.Block.Parameters.List) != 1 {
 		return nil, errors.New("storage predicate functions must have exactly one parameter")
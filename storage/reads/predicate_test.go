@@ -140,3 +140,51 @@ func TestHasFieldValueKey(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePredicate(t *testing.T) {
+	cases := []struct {
+		n   string
+		src string
+		e   string
+		err bool
+	}{
+		{
+			n:   "tag comparisons",
+			src: `(r) => r.host == "host1" and r.region =~ /^us-west/`,
+			e:   `'host' = "host1" AND 'region' =~ /^us-west/`,
+		},
+		{
+			n:   "measurement and field",
+			src: `(r) => r._measurement == "cpu" or r._field != "usage"`,
+			e:   "'\x00' = \"cpu\" OR '\xff' != \"usage\"",
+		},
+		{
+			n:   "not a function",
+			src: `r.host == "host1"`,
+			err: true,
+		},
+		{
+			n:   "syntax error",
+			src: `(r) => r.host ==`,
+			err: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.n, func(t *testing.T) {
+			p, err := reads.ParsePredicate(tc.src)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if got, wanted := reads.PredicateToExprString(p), tc.e; got != wanted {
+				t.Fatal("got:", got, "wanted:", wanted)
+			}
+		})
+	}
+}
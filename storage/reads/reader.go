@@ -50,13 +50,9 @@ func (r *storeReader) ReadGroup(ctx context.Context, spec influxdb.ReadGroupSpec
 }
 
 func (r *storeReader) ReadTagKeys(ctx context.Context, spec influxdb.ReadTagKeysSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
-	var predicate *datatypes.Predicate
-	if spec.Predicate != nil {
-		p, err := toStoragePredicate(spec.Predicate)
-		if err != nil {
-			return nil, err
-		}
-		predicate = p
+	predicate, err := readPredicate(ctx, spec.ReadFilterSpec)
+	if err != nil {
+		return nil, err
 	}
 
 	return &tagKeysIterator{
@@ -70,13 +66,9 @@ func (r *storeReader) ReadTagKeys(ctx context.Context, spec influxdb.ReadTagKeys
 }
 
 func (r *storeReader) ReadTagValues(ctx context.Context, spec influxdb.ReadTagValuesSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
-	var predicate *datatypes.Predicate
-	if spec.Predicate != nil {
-		p, err := toStoragePredicate(spec.Predicate)
-		if err != nil {
-			return nil, err
-		}
-		predicate = p
+	predicate, err := readPredicate(ctx, spec.ReadFilterSpec)
+	if err != nil {
+		return nil, err
 	}
 
 	return &tagValuesIterator{
@@ -89,8 +81,38 @@ func (r *storeReader) ReadTagValues(ctx context.Context, spec influxdb.ReadTagVa
 	}, nil
 }
 
+func (r *storeReader) ReadSeriesCardinality(ctx context.Context, spec influxdb.ReadSeriesCardinalitySpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	predicate, err := readPredicate(ctx, spec.ReadFilterSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &seriesCardinalityIterator{
+		ctx:       ctx,
+		bounds:    spec.Bounds,
+		s:         r.s,
+		readSpec:  spec,
+		predicate: predicate,
+		alloc:     alloc,
+	}, nil
+}
+
 func (r *storeReader) Close() {}
 
+// readPredicate returns the storage predicate of the read, restricted to the
+// data the authorizer of ctx may read.
+func readPredicate(ctx context.Context, spec influxdb.ReadFilterSpec) (*datatypes.Predicate, error) {
+	var predicate *datatypes.Predicate
+	if spec.Predicate != nil {
+		p, err := toStoragePredicate(spec.Predicate)
+		if err != nil {
+			return nil, err
+		}
+		predicate = p
+	}
+	return RestrictedPredicate(ctx, spec.OrganizationID, spec.BucketID, predicate)
+}
+
 type filterIterator struct {
 	ctx   context.Context
 	s     Store
@@ -113,13 +135,9 @@ func (fi *filterIterator) Do(f func(flux.Table) error) error {
 		return err
 	}
 
-	var predicate *datatypes.Predicate
-	if fi.spec.Predicate != nil {
-		p, err := toStoragePredicate(fi.spec.Predicate)
-		if err != nil {
-			return err
-		}
-		predicate = p
+	predicate, err := readPredicate(fi.ctx, fi.spec)
+	if err != nil {
+		return err
 	}
 
 	var req datatypes.ReadFilterRequest
@@ -235,13 +253,9 @@ func (gi *groupIterator) Do(f func(flux.Table) error) error {
 		return err
 	}
 
-	var predicate *datatypes.Predicate
-	if gi.spec.Predicate != nil {
-		p, err := toStoragePredicate(gi.spec.Predicate)
-		if err != nil {
-			return err
-		}
-		predicate = p
+	predicate, err := readPredicate(gi.ctx, gi.spec.ReadFilterSpec)
+	if err != nil {
+		return err
 	}
 
 	var req datatypes.ReadGroupRequest
@@ -648,3 +662,61 @@ func (ti *tagValuesIterator) handleRead(f func(flux.Table) error, rs cursors.Str
 func (ti *tagValuesIterator) Statistics() cursors.CursorStats {
 	return cursors.CursorStats{}
 }
+
+type seriesCardinalityIterator struct {
+	ctx       context.Context
+	bounds    execute.Bounds
+	s         Store
+	readSpec  influxdb.ReadSeriesCardinalitySpec
+	predicate *datatypes.Predicate
+	alloc     *memory.Allocator
+}
+
+func (ti *seriesCardinalityIterator) Do(f func(flux.Table) error) error {
+	src := ti.s.GetSource(
+		uint64(ti.readSpec.OrganizationID),
+		uint64(ti.readSpec.BucketID),
+	)
+
+	var req datatypes.TagKeysRequest
+	if any, err := types.MarshalAny(src); err != nil {
+		return err
+	} else {
+		req.TagsSource = any
+	}
+	req.Predicate = ti.predicate
+	req.Range.Start = int64(ti.bounds.Start)
+	req.Range.End = int64(ti.bounds.Stop)
+
+	n, err := ti.s.CountSeries(ti.ctx, &req)
+	if err != nil {
+		return err
+	}
+
+	key := execute.NewGroupKey(nil, nil)
+	builder := execute.NewColListTableBuilder(key, ti.alloc)
+	valueIdx, err := builder.AddCol(flux.ColMeta{
+		Label: execute.DefaultValueColLabel,
+		Type:  flux.TInt,
+	})
+	if err != nil {
+		return err
+	}
+	defer builder.ClearData()
+
+	if err := builder.AppendInt(valueIdx, n); err != nil {
+		return err
+	}
+
+	tbl, err := builder.Table()
+	if err != nil {
+		return err
+	}
+
+	builder.ClearData()
+	return f(tbl)
+}
+
+func (ti *seriesCardinalityIterator) Statistics() cursors.CursorStats {
+	return cursors.CursorStats{}
+}
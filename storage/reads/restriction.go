@@ -0,0 +1,92 @@
+package reads
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+)
+
+// RestrictedPredicate returns the predicate p AND-ed with the restrictions on
+// the data of the bucket the authorizer of ctx may read. It returns an
+// unauthorized error if ctx has no authorizer, so that reads are never
+// unrestricted by mistake. Checking that the authorizer may read the bucket at
+// all is left to the caller.
+func RestrictedPredicate(ctx context.Context, orgID, bucketID platform.ID, p *datatypes.Predicate) (*datatypes.Predicate, error) {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, &platform.Error{
+			Code: platform.EUnauthorized,
+			Msg:  "reads require an authorizer",
+			Err:  err,
+		}
+	}
+
+	perm, err := platform.NewPermissionAtID(bucketID, platform.ReadAction, platform.BucketsResourceType, orgID)
+	if err != nil {
+		return nil, err
+	}
+	rs, _ := platform.AuthorizerRestrictions(a, *perm)
+	return RestrictPredicate(p, rs), nil
+}
+
+// RestrictPredicate returns the predicate p AND-ed with a predicate matching
+// the series that satisfy any of the restrictions rs.
+func RestrictPredicate(p *datatypes.Predicate, rs []*platform.Restriction) *datatypes.Predicate {
+	if len(rs) == 0 {
+		return p
+	}
+
+	root := restrictionsNode(rs)
+	if p.GetRoot() != nil {
+		root = logicalNode(datatypes.LogicalAnd, p.Root, root)
+	}
+	return &datatypes.Predicate{Root: root}
+}
+
+func restrictionsNode(rs []*platform.Restriction) *datatypes.Node {
+	var root *datatypes.Node
+	for _, r := range rs {
+		var n *datatypes.Node
+		if len(r.Measurements) > 0 {
+			var ms *datatypes.Node
+			for _, m := range r.Measurements {
+				ms = logicalNode(datatypes.LogicalOr, ms, tagEqualNode(models.MeasurementTagKey, m))
+			}
+			n = ms
+		}
+		for _, t := range r.Tags {
+			n = logicalNode(datatypes.LogicalAnd, n, tagEqualNode(t.Key, t.Value))
+		}
+		root = logicalNode(datatypes.LogicalOr, root, n)
+	}
+	return root
+}
+
+// logicalNode combines the nodes left and right, either of which may be nil.
+func logicalNode(op datatypes.Node_Logical, left, right *datatypes.Node) *datatypes.Node {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeLogicalExpression,
+		Value:    &datatypes.Node_Logical_{Logical: op},
+		Children: []*datatypes.Node{left, right},
+	}
+}
+
+func tagEqualNode(key, value string) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeComparisonExpression,
+		Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonEqual},
+		Children: []*datatypes.Node{
+			{NodeType: datatypes.NodeTypeTagRef, Value: &datatypes.Node_TagRefValue{TagRefValue: key}},
+			{NodeType: datatypes.NodeTypeLiteral, Value: &datatypes.Node_StringValue{StringValue: value}},
+		},
+	}
+}
@@ -0,0 +1,131 @@
+package reads_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxql"
+)
+
+func TestRestrictedPredicate(t *testing.T) {
+	orgID, bucketID := influxdb.ID(1), influxdb.ID(2)
+	read := func(r *influxdb.Restriction) influxdb.Permission {
+		return influxdb.Permission{
+			Action:      influxdb.ReadAction,
+			Resource:    influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &orgID, ID: &bucketID},
+			Restriction: r,
+		}
+	}
+	host := &datatypes.Predicate{
+		Root: &datatypes.Node{
+			NodeType: datatypes.NodeTypeComparisonExpression,
+			Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonEqual},
+			Children: []*datatypes.Node{
+				{NodeType: datatypes.NodeTypeTagRef, Value: &datatypes.Node_TagRefValue{TagRefValue: "host"}},
+				{NodeType: datatypes.NodeTypeLiteral, Value: &datatypes.Node_StringValue{StringValue: "a"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		predicate   *datatypes.Predicate
+		want        string
+	}{
+		{
+			name:        "unrestricted",
+			permissions: []influxdb.Permission{read(nil)},
+			predicate:   host,
+			want:        `'host' = "a"`,
+		},
+		{
+			name: "tags",
+			permissions: []influxdb.Permission{read(&influxdb.Restriction{
+				Tags: []influxdb.RestrictionTag{{Key: "team", Value: "payments"}, {Key: "env", Value: "prod"}},
+			})},
+			want: `'team' = "payments" AND 'env' = "prod"`,
+		},
+		{
+			name: "measurements and predicate",
+			permissions: []influxdb.Permission{read(&influxdb.Restriction{
+				Measurements: []string{"cpu", "mem"},
+				Tags:         []influxdb.RestrictionTag{{Key: "team", Value: "payments"}},
+			})},
+			predicate: host,
+			want:      "'host' = \"a\" AND '\x00' = \"cpu\" OR '\x00' = \"mem\" AND 'team' = \"payments\"",
+		},
+		{
+			name: "any of several restrictions",
+			permissions: []influxdb.Permission{
+				read(&influxdb.Restriction{Measurements: []string{"cpu"}}),
+				read(&influxdb.Restriction{Tags: []influxdb.RestrictionTag{{Key: "team", Value: "payments"}}}),
+			},
+			want: "'\x00' = \"cpu\" OR 'team' = \"payments\"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+				Status:      influxdb.Active,
+				Permissions: tt.permissions,
+			})
+			p, err := reads.RestrictedPredicate(ctx, orgID, bucketID, tt.predicate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := reads.PredicateToExprString(p); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestrictedPredicate_Eval(t *testing.T) {
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{{
+			Action:   influxdb.ReadAction,
+			Resource: influxdb.Resource{Type: influxdb.BucketsResourceType},
+			Restriction: &influxdb.Restriction{
+				Measurements: []string{"cpu", "mem"},
+				Tags:         []influxdb.RestrictionTag{{Key: "team", Value: "payments"}},
+			},
+		}},
+	})
+	p, err := reads.RestrictedPredicate(ctx, 1, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expr, err := reads.NodeToExpr(p.Root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		series influxql.MapValuer
+		want   bool
+	}{
+		{series: influxql.MapValuer{models.MeasurementTagKey: "cpu", "team": "payments"}, want: true},
+		{series: influxql.MapValuer{models.MeasurementTagKey: "mem", "team": "payments"}, want: true},
+		{series: influxql.MapValuer{models.MeasurementTagKey: "mem", "team": "billing"}},
+		{series: influxql.MapValuer{models.MeasurementTagKey: "disk", "team": "payments"}},
+		{series: influxql.MapValuer{models.MeasurementTagKey: "cpu"}},
+	}
+	for _, tt := range tests {
+		if got := reads.EvalExprBool(expr, tt.series); got != tt.want {
+			t.Errorf("series %v: got %v, want %v", tt.series, got, tt.want)
+		}
+	}
+}
+
+func TestRestrictedPredicate_NoAuthorizer(t *testing.T) {
+	if _, err := reads.RestrictedPredicate(context.Background(), 1, 2, nil); influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+		t.Errorf("expected reads without an authorizer to be unauthorized, got %v", err)
+	}
+}
@@ -80,5 +80,9 @@ type Store interface {
 	TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cursors.StringIterator, error)
 	TagValues(ctx context.Context, req *datatypes.TagValuesRequest) (cursors.StringIterator, error)
 
+	// CountSeries returns the number of series of the source of req matching
+	// its predicate, which have data within its time range.
+	CountSeries(ctx context.Context, req *datatypes.TagKeysRequest) (int64, error)
+
 	GetSource(orgID, bucketID uint64) proto.Message
 }
@@ -0,0 +1,128 @@
+package readservice
+
+import (
+	"context"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/influxdata/influxdb"
+	kitgrpc "github.com/influxdata/influxdb/kit/grpc"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type remoteStore struct {
+	client datatypes.StorageClient
+	token  string
+}
+
+// NewRemoteStore returns a reads.Store that reads series data from the
+// Storage gRPC service of another influxd, authenticating with token.
+// Passing it to reads.NewReader allows a separate process to run Flux
+// queries directly against storage.
+func NewRemoteStore(conn *grpc.ClientConn, token string) reads.Store {
+	return &remoteStore{client: datatypes.NewStorageClient(conn), token: token}
+}
+
+func (s *remoteStore) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	stream, err := s.client.ReadFilter(s.withToken(ctx), req)
+	if err != nil {
+		return nil, fromStatusError(err)
+	}
+	return reads.NewResultSetStreamReader(reads.NewStorageReadClient(readResponseStream{stream})), nil
+}
+
+func (s *remoteStore) ReadGroup(ctx context.Context, req *datatypes.ReadGroupRequest) (reads.GroupResultSet, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	stream, err := s.client.ReadGroup(s.withToken(ctx), req)
+	if err != nil {
+		return nil, fromStatusError(err)
+	}
+	return reads.NewGroupResultSetStreamReader(reads.NewStorageReadClient(readResponseStream{stream})), nil
+}
+
+func (s *remoteStore) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cursors.StringIterator, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	stream, err := s.client.TagKeys(s.withToken(ctx), req)
+	if err != nil {
+		return nil, fromStatusError(err)
+	}
+	return reads.NewStringIteratorStreamReader(stringValuesStream{stream}), nil
+}
+
+func (s *remoteStore) TagValues(ctx context.Context, req *datatypes.TagValuesRequest) (cursors.StringIterator, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	stream, err := s.client.TagValues(s.withToken(ctx), req)
+	if err != nil {
+		return nil, fromStatusError(err)
+	}
+	return reads.NewStringIteratorStreamReader(stringValuesStream{stream}), nil
+}
+
+// CountSeries is not part of the Storage gRPC service, so the series of a
+// remote store cannot be counted.
+func (s *remoteStore) CountSeries(ctx context.Context, req *datatypes.TagKeysRequest) (int64, error) {
+	return 0, &influxdb.Error{
+		Code: influxdb.EMethodNotAllowed,
+		Msg:  "counting series is not supported by a remote store",
+	}
+}
+
+func (s *remoteStore) GetSource(orgID, bucketID uint64) proto.Message {
+	return &readSource{
+		BucketID:       bucketID,
+		OrganizationID: orgID,
+	}
+}
+
+func (s *remoteStore) withToken(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, AuthorizationMetadataKey, tokenScheme+s.token)
+}
+
+// readResponseStream converts the gRPC status errors of a stream of read
+// responses back to platform errors.
+type readResponseStream struct {
+	reads.StreamClient
+}
+
+func (s readResponseStream) Recv() (*datatypes.ReadResponse, error) {
+	res, err := s.StreamClient.Recv()
+	return res, fromStatusError(err)
+}
+
+// stringValuesStream converts the gRPC status errors of a stream of string
+// values back to platform errors.
+type stringValuesStream struct {
+	reads.StringValuesStreamReader
+}
+
+func (s stringValuesStream) Recv() (*datatypes.StringValuesResponse, error) {
+	res, err := s.StringValuesStreamReader.Recv()
+	return res, fromStatusError(err)
+}
+
+// fromStatusError converts a gRPC status error to a platform error using the
+// error mapping of kit/grpc. Other errors, such as io.EOF, are returned as is.
+func fromStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return kitgrpc.FromStatus(s)
+}
@@ -0,0 +1,230 @@
+package readservice
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	kitgrpc "github.com/influxdata/influxdb/kit/grpc"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"google.golang.org/grpc/metadata"
+)
+
+// AuthorizationMetadataKey is the gRPC metadata key holding the token of a
+// request, in the same "Token <token>" form as the HTTP Authorization header.
+const AuthorizationMetadataKey = "authorization"
+
+const tokenScheme = "Token "
+
+// Capabilities reported by the Storage service. The server supports all of
+// the read requests of storage_common.proto, including aggregates and the
+// hints of ReadGroup.
+var capabilities = map[string]string{
+	"ReadFilter": "",
+	"ReadGroup":  "",
+	"TagKeys":    "",
+	"TagValues":  "",
+}
+
+type server struct {
+	store reads.Store
+	auth  *authorizer.TokenAuthenticator
+}
+
+// NewStorageServer returns the Storage gRPC service of storage_common.proto
+// serving reads from store. Every request must carry a token in its
+// metadata, authenticated by auth as for the HTTP API, that is allowed to
+// read the requested bucket.
+func NewStorageServer(store reads.Store, auth *authorizer.TokenAuthenticator) datatypes.StorageServer {
+	return &server{store: store, auth: auth}
+}
+
+func (s *server) ReadFilter(req *datatypes.ReadFilterRequest, stream datatypes.Storage_ReadFilterServer) error {
+	span, ctx := tracing.StartSpanFromContext(stream.Context())
+	defer span.Finish()
+
+	ctx, predicate, err := s.authorizeRead(ctx, req.ReadSource, req.Predicate)
+	if err != nil {
+		return toStatusError(err)
+	}
+	req.Predicate = predicate
+
+	rs, err := s.store.ReadFilter(ctx, req)
+	if err != nil {
+		return toStatusError(err)
+	} else if rs == nil {
+		return nil
+	}
+	defer rs.Close()
+
+	w := reads.NewResponseWriter(stream, 0)
+	if err := w.WriteResultSet(rs); err != nil {
+		return toStatusError(err)
+	}
+	w.Flush()
+	return toStatusError(w.Err())
+}
+
+func (s *server) ReadGroup(req *datatypes.ReadGroupRequest, stream datatypes.Storage_ReadGroupServer) error {
+	span, ctx := tracing.StartSpanFromContext(stream.Context())
+	defer span.Finish()
+
+	ctx, predicate, err := s.authorizeRead(ctx, req.ReadSource, req.Predicate)
+	if err != nil {
+		return toStatusError(err)
+	}
+	req.Predicate = predicate
+
+	rs, err := s.store.ReadGroup(ctx, req)
+	if err != nil {
+		return toStatusError(err)
+	} else if rs == nil {
+		return nil
+	}
+	defer rs.Close()
+
+	w := reads.NewResponseWriter(stream, req.Hints)
+	if err := w.WriteGroupResultSet(rs); err != nil {
+		return toStatusError(err)
+	}
+	w.Flush()
+	return toStatusError(w.Err())
+}
+
+func (s *server) TagKeys(req *datatypes.TagKeysRequest, stream datatypes.Storage_TagKeysServer) error {
+	span, ctx := tracing.StartSpanFromContext(stream.Context())
+	defer span.Finish()
+
+	ctx, predicate, err := s.authorizeRead(ctx, req.TagsSource, req.Predicate)
+	if err != nil {
+		return toStatusError(err)
+	}
+	req.Predicate = predicate
+
+	iter, err := s.store.TagKeys(ctx, req)
+	if err != nil {
+		return toStatusError(err)
+	}
+
+	w := reads.NewStringIteratorWriter(stream)
+	if err := w.WriteStringIterator(iter); err != nil {
+		return toStatusError(err)
+	}
+	w.Flush()
+	return toStatusError(w.Err())
+}
+
+func (s *server) TagValues(req *datatypes.TagValuesRequest, stream datatypes.Storage_TagValuesServer) error {
+	span, ctx := tracing.StartSpanFromContext(stream.Context())
+	defer span.Finish()
+
+	ctx, predicate, err := s.authorizeRead(ctx, req.TagsSource, req.Predicate)
+	if err != nil {
+		return toStatusError(err)
+	}
+	req.Predicate = predicate
+
+	iter, err := s.store.TagValues(ctx, req)
+	if err != nil {
+		return toStatusError(err)
+	}
+
+	w := reads.NewStringIteratorWriter(stream)
+	if err := w.WriteStringIterator(iter); err != nil {
+		return toStatusError(err)
+	}
+	w.Flush()
+	return toStatusError(w.Err())
+}
+
+func (s *server) Capabilities(ctx context.Context, _ *types.Empty) (*datatypes.CapabilitiesResponse, error) {
+	if _, err := s.authenticate(ctx); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &datatypes.CapabilitiesResponse{Caps: capabilities}, nil
+}
+
+// authenticate places the authorization of the token in the metadata of the
+// request on the returned context.
+func (s *server) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(AuthorizationMetadataKey)
+	if len(values) == 0 || !strings.HasPrefix(values[0], tokenScheme) {
+		return ctx, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "token required",
+		}
+	}
+
+	a, err := s.auth.Authorizer(ctx, values[0][len(tokenScheme):])
+	if err != nil {
+		return ctx, err
+	}
+	return influxdbcontext.SetAuthorizer(ctx, a), nil
+}
+
+// authorizeRead authenticates the request and checks that it may read the
+// bucket of the read source. It returns the predicate of the request restricted
+// to the data of the bucket the request may read.
+func (s *server) authorizeRead(ctx context.Context, source *types.Any, predicate *datatypes.Predicate) (context.Context, *datatypes.Predicate, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	if source == nil {
+		return ctx, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "missing read source",
+		}
+	}
+	src, err := getReadSource(*source)
+	if err != nil {
+		return ctx, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid read source",
+			Err:  err,
+		}
+	}
+
+	p, err := influxdb.NewPermissionAtID(influxdb.ID(src.BucketID), influxdb.ReadAction, influxdb.BucketsResourceType, influxdb.ID(src.OrganizationID))
+	if err != nil {
+		return ctx, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid read source",
+			Err:  err,
+		}
+	}
+	rs, err := authorizer.IsAllowedRestricted(ctx, *p)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctx, reads.RestrictPredicate(predicate, rs), nil
+}
+
+// toStatusError converts err to a gRPC status error using the error mapping
+// of kit/grpc.
+func toStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	perr, ok := err.(*influxdb.Error)
+	if !ok {
+		perr = &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	s, serr := kitgrpc.ToStatus(perr)
+	if serr != nil {
+		return serr
+	}
+	return s.Err()
+}
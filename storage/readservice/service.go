@@ -9,19 +9,21 @@ import (
 	"github.com/influxdata/influxdb/storage/reads"
 )
 
-// NewProxyQueryService returns a proxy query service based on the given queryController
-// suitable for the storage read service.
-func NewProxyQueryService(queryController *control.Controller) query.ProxyQueryService {
+// NewProxyQueryService returns a proxy query service based on the given query
+// service, usually the query controller, suitable for the storage read service.
+func NewProxyQueryService(queryService query.AsyncQueryService) query.ProxyQueryService {
 	return query.ProxyQueryServiceAsyncBridge{
-		AsyncQueryService: queryController,
+		AsyncQueryService: queryService,
 	}
 }
 
 // AddControllerConfigDependencies sets up the dependencies on cc
 // such that "from" and "to" flux functions will work correctly.
+// The "to" function writes through pointsWriter.
 func AddControllerConfigDependencies(
 	cc *control.Config,
 	engine *storage.Engine,
+	pointsWriter storage.PointsWriter,
 	bucketSvc platform.BucketService,
 	orgSvc platform.OrganizationService,
 ) error {
@@ -43,6 +45,6 @@ func AddControllerConfigDependencies(
 	return influxdb.InjectToDependencies(cc.ExecutorDependencies, influxdb.ToDependencies{
 		BucketLookup:       bucketLookupSvc,
 		OrganizationLookup: orgLookupSvc,
-		PointsWriter:       engine,
+		PointsWriter:       pointsWriter,
 	})
 }
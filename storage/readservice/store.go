@@ -24,6 +24,11 @@ func newStore(engine *storage.Engine) *store {
 	return &store{engine: engine}
 }
 
+// NewStore returns a reads.Store that reads series data directly from engine.
+func NewStore(engine *storage.Engine) reads.Store {
+	return newStore(engine)
+}
+
 func (s *store) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
 	if req.ReadSource == nil {
 		return nil, errors.New("missing read source")
@@ -149,6 +154,45 @@ func (s *store) TagValues(ctx context.Context, req *datatypes.TagValuesRequest)
 	return s.engine.TagValues(ctx, influxdb.ID(readSource.OrganizationID), influxdb.ID(readSource.BucketID), req.TagKey, req.Range.Start, req.Range.End, expr)
 }
 
+func (s *store) CountSeries(ctx context.Context, req *datatypes.TagKeysRequest) (int64, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if req.TagsSource == nil {
+		return 0, errors.New("missing tags source")
+	}
+
+	if req.Range.Start == 0 {
+		req.Range.Start = models.MinNanoTime
+	}
+	if req.Range.End == 0 {
+		req.Range.End = models.MaxNanoTime
+	}
+
+	var expr influxql.Expr
+	var err error
+	if root := req.Predicate.GetRoot(); root != nil {
+		expr, err = reads.NodeToExpr(root, nil)
+		if err != nil {
+			return 0, err
+		}
+
+		if found := reads.HasFieldValueKey(expr); found {
+			return 0, errors.New("field values unsupported")
+		}
+		expr = influxql.Reduce(influxql.CloneExpr(expr), nil)
+		if reads.IsTrueBooleanLiteral(expr) {
+			expr = nil
+		}
+	}
+
+	readSource, err := getReadSource(*req.TagsSource)
+	if err != nil {
+		return 0, err
+	}
+	return s.engine.CountSeries(ctx, influxdb.ID(readSource.OrganizationID), influxdb.ID(readSource.BucketID), req.Range.Start, req.Range.End, expr)
+}
+
 // this is easier than fooling around with .proto files.
 
 type readSource struct {
@@ -0,0 +1,50 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// ops for storage usage errors.
+var (
+	OpFindOrganizationStorageUsage = "FindOrganizationStorageUsage"
+	OpFindBucketStorageUsage       = "FindBucketStorageUsage"
+)
+
+// StorageUsage is the disk space used by the data of an organization or a
+// bucket. Written data is counted once the storage engine snapshots it from
+// its cache to TSM files.
+type StorageUsage struct {
+	// TSMBytes is the size of the TSM blocks of the data.
+	TSMBytes int64 `json:"tsmBytes"`
+	// IndexBytes is the size of the index entries of the series of the data.
+	IndexBytes int64 `json:"indexBytes"`
+}
+
+// Bytes returns the total disk space used.
+func (u *StorageUsage) Bytes() int64 {
+	return u.TSMBytes + u.IndexBytes
+}
+
+// StorageUsageService reports the disk space used by organizations and buckets.
+type StorageUsageService interface {
+	// FindOrganizationStorageUsage returns the disk space used by the data
+	// of all buckets of an organization.
+	FindOrganizationStorageUsage(ctx context.Context, orgID ID) (*StorageUsage, error)
+
+	// FindBucketStorageUsage returns the disk space used by the data of a
+	// bucket of an organization.
+	FindBucketStorageUsage(ctx context.Context, orgID, bucketID ID) (*StorageUsage, error)
+}
+
+// ValidStorageQuota returns an error if a storage quota is negative.
+// A zero quota is no quota.
+func ValidStorageQuota(quotaBytes int64) error {
+	if quotaBytes < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("storage quota must not be negative, got %d bytes", quotaBytes),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestValidStorageQuota(t *testing.T) {
+	tests := []struct {
+		quota   int64
+		wantErr bool
+	}{
+		{quota: 0},
+		{quota: 1024},
+		{quota: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := influxdb.ValidStorageQuota(tt.quota)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidStorageQuota(%d) error = %v, wantErr %v", tt.quota, err, tt.wantErr)
+		}
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Errorf("ValidStorageQuota(%d) error code = %s, want %s", tt.quota, influxdb.ErrorCode(err), influxdb.EInvalid)
+		}
+	}
+}
+
+func TestStorageUsage_Bytes(t *testing.T) {
+	u := &influxdb.StorageUsage{TSMBytes: 100, IndexBytes: 20}
+	if got, want := u.Bytes(), int64(120); got != want {
+		t.Errorf("Bytes() = %d, want %d", got, want)
+	}
+}
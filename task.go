@@ -38,6 +38,9 @@ type Task struct {
 	LatestCompleted string `json:"latestCompleted,omitempty"`
 	CreatedAt       string `json:"createdAt,omitempty"`
 	UpdatedAt       string `json:"updatedAt,omitempty"`
+
+	// DependsOn are the tasks whose successful runs trigger a run of this task, for the same schedule time.
+	DependsOn []ID `json:"dependsOn,omitempty"`
 }
 
 // EffectiveCron returns the effective cron string of the options.
@@ -55,6 +58,16 @@ func (t *Task) EffectiveCron() string {
 	return ""
 }
 
+// DependsOnTask reports whether t depends on the task with the given id.
+func (t *Task) DependsOnTask(id ID) bool {
+	for _, dep := range t.DependsOn {
+		if dep == id {
+			return true
+		}
+	}
+	return false
+}
+
 // Run is a record created when a run of a task is scheduled.
 type Run struct {
 	ID           ID     `json:"id,omitempty"`
@@ -140,6 +153,7 @@ type TaskCreate struct {
 	OrganizationID ID     `json:"orgID,omitempty"`
 	Organization   string `json:"org,omitempty"`
 	Token          string `json:"token,omitempty"`
+	DependsOn      []ID   `json:"dependsOn,omitempty"`
 }
 
 func (t TaskCreate) Validate() error {
@@ -168,6 +182,9 @@ type TaskUpdate struct {
 
 	// Optional token override.
 	Token string `json:"token,omitempty"`
+
+	// DependsOn replaces the dependencies of the task; an empty list removes them.
+	DependsOn *[]ID `json:"dependsOn,omitempty"`
 }
 
 func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
@@ -193,7 +210,13 @@ func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
 
 		Retry *int64 `json:"retry,omitempty"`
 
+		// Timeout is how long a single attempt of a run may execute.
+		// It gets marshalled from a string duration, i.e.: "10s" is 10 seconds
+		Timeout *options.Duration `json:"timeout,omitempty"`
+
 		Token string `json:"token,omitempty"`
+
+		DependsOn *[]ID `json:"dependsOn,omitempty"`
 	}{}
 
 	if err := json.Unmarshal(data, &jo); err != nil {
@@ -209,9 +232,14 @@ func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
 	}
 	t.Options.Concurrency = jo.Concurrency
 	t.Options.Retry = jo.Retry
+	if jo.Timeout != nil {
+		timeout := *jo.Timeout
+		t.Options.Timeout = &timeout
+	}
 	t.Flux = jo.Flux
 	t.Status = jo.Status
 	t.Token = jo.Token
+	t.DependsOn = jo.DependsOn
 
 	return nil
 }
@@ -236,7 +264,12 @@ func (t TaskUpdate) MarshalJSON() ([]byte, error) {
 
 		Retry *int64 `json:"retry,omitempty"`
 
+		// Timeout is how long a single attempt of a run may execute.
+		Timeout *options.Duration `json:"timeout,omitempty"`
+
 		Token string `json:"token,omitempty"`
+
+		DependsOn *[]ID `json:"dependsOn,omitempty"`
 	}{}
 	jo.Name = t.Options.Name
 	jo.Cron = t.Options.Cron
@@ -248,9 +281,14 @@ func (t TaskUpdate) MarshalJSON() ([]byte, error) {
 	}
 	jo.Concurrency = t.Options.Concurrency
 	jo.Retry = t.Options.Retry
+	if t.Options.Timeout != nil {
+		timeout := *t.Options.Timeout
+		jo.Timeout = &timeout
+	}
 	jo.Flux = t.Flux
 	jo.Status = t.Status
 	jo.Token = t.Token
+	jo.DependsOn = t.DependsOn
 	return json.Marshal(jo)
 }
 
@@ -258,7 +296,7 @@ func (t TaskUpdate) Validate() error {
 	switch {
 	case !t.Options.Every.IsZero() && t.Options.Cron != "":
 		return errors.New("cannot specify both every and cron")
-	case t.Flux == nil && t.Status == nil && t.Options.IsZero() && t.Token == "":
+	case t.Flux == nil && t.Status == nil && t.Options.IsZero() && t.Token == "" && t.DependsOn == nil:
 		return errors.New("cannot update task without content")
 	case t.Status != nil && *t.Status != TaskStatusActive && *t.Status != TaskStatusInactive:
 		return fmt.Errorf("invalid task status: %q", *t.Status)
@@ -281,7 +319,7 @@ func (t *TaskUpdate) UpdateFlux(oldFlux string) error {
 	if !t.Options.Every.IsZero() && t.Options.Cron != "" {
 		return errors.New("cannot specify both cron and every")
 	}
-	op := make(map[string]ast.Expression, 4)
+	op := make(map[string]ast.Expression, 6)
 
 	if t.Options.Name != "" {
 		op["name"] = &ast.StringLiteral{Value: t.Options.Name}
@@ -299,6 +337,16 @@ func (t *TaskUpdate) UpdateFlux(oldFlux string) error {
 			toDelete["offset"] = struct{}{}
 		}
 	}
+	if t.Options.Retry != nil {
+		op["retry"] = &ast.IntegerLiteral{Value: *t.Options.Retry}
+	}
+	if t.Options.Timeout != nil {
+		if !t.Options.Timeout.IsZero() {
+			op["timeout"] = &t.Options.Timeout.Node
+		} else {
+			toDelete["timeout"] = struct{}{}
+		}
+	}
 	if len(op) > 0 || len(toDelete) > 0 {
 		editFunc := func(opt *ast.OptionStatement) (ast.Expression, error) {
 			a, ok := opt.Assignment.(*ast.VariableAssignment)
@@ -326,6 +374,16 @@ func (t *TaskUpdate) UpdateFlux(oldFlux string) error {
 						delete(op, "offset")
 						p.Value = offset.Copy().(*ast.DurationLiteral)
 					}
+				case "retry":
+					if retry, ok := op["retry"]; ok {
+						delete(op, "retry")
+						p.Value = retry
+					}
+				case "timeout":
+					if timeout, ok := op["timeout"]; ok && t.Options.Timeout != nil {
+						delete(op, "timeout")
+						p.Value = timeout.Copy().(*ast.DurationLiteral)
+					}
 				case "every":
 					if every, ok := op["every"]; ok && !t.Options.Every.IsZero() {
 						p.Value = every.Copy().(*ast.DurationLiteral)
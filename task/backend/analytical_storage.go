@@ -53,6 +53,11 @@ type AnalyticalStorage struct {
 	logger *zap.Logger
 }
 
+// ForceRun queues a manual run through the TaskService, which both embedded services provide.
+func (as *AnalyticalStorage) ForceRun(ctx context.Context, taskID influxdb.ID, scheduledFor int64) (*influxdb.Run, error) {
+	return as.TaskService.ForceRun(ctx, taskID, scheduledFor)
+}
+
 func (as *AnalyticalStorage) FinishRun(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error) {
 	run, err := as.TaskControlService.FinishRun(ctx, taskID, runID)
 	if run != nil && run.ID.String() != "" {
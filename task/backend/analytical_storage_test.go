@@ -118,7 +118,7 @@ func newAnalyticalBackend(t *testing.T, orgSvc influxdb.OrganizationService, buc
 	}
 
 	if err := readservice.AddControllerConfigDependencies(
-		&cc, engine, bucketSvc, orgSvc,
+		&cc, engine, engine, bucketSvc, orgSvc,
 	); err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,389 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/snowflake"
+	"github.com/influxdata/influxdb/task/options"
+	"go.uber.org/zap"
+	cron "gopkg.in/robfig/cron.v2"
+)
+
+// DefaultBackfillInterval is how often a Backfiller checks on the progress of its backfills by default.
+const DefaultBackfillInterval = time.Second
+
+// ErrBackfillInactiveTask is returned when creating a backfill for a task that is not active,
+// since the runs of an inactive task are never executed.
+var ErrBackfillInactiveTask = &influxdb.Error{
+	Code: influxdb.EInvalid,
+	Msg:  "cannot backfill an inactive task",
+}
+
+// BackfillStore persists the backfills of a Backfiller.
+type BackfillStore interface {
+	FindBackfillByID(ctx context.Context, id influxdb.ID) (*influxdb.Backfill, error)
+	FindBackfills(ctx context.Context, filter influxdb.BackfillFilter) ([]*influxdb.Backfill, error)
+	PutBackfill(ctx context.Context, b *influxdb.Backfill) error
+}
+
+var _ influxdb.BackfillService = (*Backfiller)(nil)
+
+// Backfiller implements influxdb.BackfillService.
+//
+// A Backfiller queues the runs of a backfill as manual runs through a TaskControlService,
+// at most the backfill's concurrency at a time, and notifies the Scheduler so that they are executed.
+// On every interval it checks which of the queued runs have finished and queues more,
+// until all of the runs of the backfill have finished or the backfill is canceled.
+type Backfiller struct {
+	logger *zap.Logger
+	store  BackfillStore
+	ts     influxdb.TaskService
+	tcs    TaskControlService
+	sch    Scheduler
+
+	// IDGenerator generates the IDs of backfills.
+	IDGenerator influxdb.IDGenerator
+	// Interval is how often the progress of backfills is checked.
+	Interval time.Duration
+	// Now returns the current time.
+	Now func() time.Time
+
+	// mu serializes changes to backfills.
+	mu      sync.Mutex
+	running map[influxdb.ID]struct{}
+
+	cancel func()
+	wg     sync.WaitGroup
+}
+
+// NewBackfiller returns a Backfiller that stores backfills in store and looks up tasks in ts.
+func NewBackfiller(logger *zap.Logger, store BackfillStore, ts influxdb.TaskService, tcs TaskControlService, sch Scheduler) *Backfiller {
+	return &Backfiller{
+		logger:      logger,
+		store:       store,
+		ts:          ts,
+		tcs:         tcs,
+		sch:         sch,
+		IDGenerator: snowflake.NewIDGenerator(),
+		Interval:    DefaultBackfillInterval,
+		Now:         time.Now,
+		running:     make(map[influxdb.ID]struct{}),
+	}
+}
+
+// Open resumes the backfills that were running and starts checking on their progress.
+func (b *Backfiller) Open(ctx context.Context) error {
+	bs, err := b.store.FindBackfills(ctx, influxdb.BackfillFilter{})
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	for _, bf := range bs {
+		if bf.Running() {
+			b.running[bf.ID] = struct{}{}
+		}
+	}
+	b.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+
+		ticker := time.NewTicker(b.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				b.Tick(ctx)
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops checking on the progress of backfills.
+// Backfills that are still running are resumed by the next call to Open.
+func (b *Backfiller) Close() error {
+	if b.cancel != nil {
+		b.cancel()
+		b.wg.Wait()
+	}
+	return nil
+}
+
+// Tick checks on the progress of every running backfill once, queueing more runs where possible.
+func (b *Backfiller) Tick(ctx context.Context) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for id := range b.running {
+		if err := b.advance(ctx, id); err != nil {
+			b.logger.Info("Failed to advance backfill", zap.Stringer("backfill_id", id), zap.Error(err))
+		}
+	}
+}
+
+// CreateBackfill starts queueing the runs of the task with taskID within the range of c.
+func (b *Backfiller) CreateBackfill(ctx context.Context, taskID influxdb.ID, c influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if c.Concurrency == 0 {
+		c.Concurrency = 1
+	}
+
+	task, err := b.ts.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != string(TaskActive) {
+		return nil, ErrBackfillInactiveTask
+	}
+
+	sch, err := backfillSchedule(task)
+	if err != nil {
+		return nil, err
+	}
+
+	start, stop := c.Start.UTC().Truncate(time.Second), c.Stop.UTC().Truncate(time.Second)
+	first := backfillFirst(task, sch, start)
+	total := 0
+	for t := first; !t.IsZero() && !t.After(stop); t = sch.Next(t) {
+		total++
+		if total > influxdb.BackfillMaxRuns {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("backfill cannot consist of more than %d runs", influxdb.BackfillMaxRuns),
+			}
+		}
+	}
+	if total == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "task is not scheduled to run between backfill start and stop",
+		}
+	}
+
+	now := b.Now().UTC().Format(time.RFC3339)
+	bf := &influxdb.Backfill{
+		ID:             b.IDGenerator.ID(),
+		TaskID:         task.ID,
+		OrganizationID: task.OrganizationID,
+		Start:          start.Format(time.RFC3339),
+		Stop:           stop.Format(time.RFC3339),
+		Concurrency:    c.Concurrency,
+		Status:         influxdb.BackfillStatusRunning,
+		Next:           first.Format(time.RFC3339),
+		Total:          total,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.store.PutBackfill(ctx, bf); err != nil {
+		return nil, err
+	}
+	b.running[bf.ID] = struct{}{}
+
+	// Queue the first runs right away rather than on the next tick.
+	if err := b.advance(ctx, bf.ID); err != nil {
+		return nil, err
+	}
+	return b.store.FindBackfillByID(ctx, bf.ID)
+}
+
+// FindBackfillByID returns a single backfill of a task.
+func (b *Backfiller) FindBackfillByID(ctx context.Context, taskID, id influxdb.ID) (*influxdb.Backfill, error) {
+	return b.findBackfillByID(ctx, taskID, id)
+}
+
+func (b *Backfiller) findBackfillByID(ctx context.Context, taskID, id influxdb.ID) (*influxdb.Backfill, error) {
+	bf, err := b.store.FindBackfillByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if bf.TaskID != taskID {
+		return nil, influxdb.ErrBackfillNotFound
+	}
+	return bf, nil
+}
+
+// FindBackfills returns the backfills that match filter, newest first.
+func (b *Backfiller) FindBackfills(ctx context.Context, filter influxdb.BackfillFilter) ([]*influxdb.Backfill, error) {
+	return b.store.FindBackfills(ctx, filter)
+}
+
+// CancelBackfill stops queueing runs for a backfill.
+// Runs of the backfill that were already queued are still executed.
+func (b *Backfiller) CancelBackfill(ctx context.Context, taskID, id influxdb.ID) (*influxdb.Backfill, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bf, err := b.findBackfillByID(ctx, taskID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !bf.Running() {
+		return nil, influxdb.ErrBackfillNotRunning
+	}
+
+	b.finish(bf, influxdb.BackfillStatusCanceled, "")
+	if err := b.store.PutBackfill(ctx, bf); err != nil {
+		return nil, err
+	}
+	delete(b.running, id)
+	return bf, nil
+}
+
+// advance records which of the queued runs of a backfill have finished and queues its next runs.
+// b.mu must be held.
+func (b *Backfiller) advance(ctx context.Context, id influxdb.ID) error {
+	bf, err := b.store.FindBackfillByID(ctx, id)
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			delete(b.running, id)
+		}
+		return err
+	}
+	if !bf.Running() {
+		delete(b.running, id)
+		return nil
+	}
+
+	task, err := b.ts.FindTaskByID(ctx, bf.TaskID)
+	if err != nil {
+		if influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+		b.finish(bf, influxdb.BackfillStatusFailed, "task was deleted")
+		delete(b.running, id)
+		return b.store.PutBackfill(ctx, bf)
+	}
+
+	// A queued run moves from the manual runs to the currently running runs before it finishes,
+	// so the manual runs must be listed first for no unfinished run to be missed.
+	manual, err := b.tcs.ManualRuns(ctx, bf.TaskID)
+	if err != nil {
+		return err
+	}
+	current, err := b.tcs.CurrentlyRunning(ctx, bf.TaskID)
+	if err != nil {
+		return err
+	}
+	unfinished := make(map[influxdb.ID]bool, len(manual)+len(current))
+	for _, r := range manual {
+		unfinished[r.ID] = true
+	}
+	for _, r := range current {
+		unfinished[r.ID] = true
+	}
+
+	runs := bf.Runs[:0]
+	for _, id := range bf.Runs {
+		if unfinished[id] {
+			runs = append(runs, id)
+		} else {
+			bf.Finished++
+		}
+	}
+	bf.Runs = runs
+
+	var queueErr error
+	queued := false
+	if bf.Next != "" {
+		sch, err := backfillSchedule(task)
+		if err != nil {
+			return err
+		}
+		next, err := time.Parse(time.RFC3339, bf.Next)
+		if err != nil {
+			return influxdb.ErrTaskTimeParse(err)
+		}
+		stop, err := time.Parse(time.RFC3339, bf.Stop)
+		if err != nil {
+			return influxdb.ErrTaskTimeParse(err)
+		}
+
+		for len(bf.Runs) < bf.Concurrency && !next.IsZero() && !next.After(stop) && bf.Queued < bf.Total {
+			r, err := b.tcs.ForceRun(ctx, bf.TaskID, next.Unix())
+			if err == nil {
+				bf.Runs = append(bf.Runs, r.ID)
+				queued = true
+			} else if err == influxdb.ErrTaskRunAlreadyQueued {
+				// A run for this time is already queued outside of the backfill, which does the work for it.
+				bf.Finished++
+			} else {
+				queueErr = err
+				break
+			}
+			bf.Queued++
+			next = sch.Next(next).UTC()
+		}
+
+		bf.Next = next.Format(time.RFC3339)
+		if next.IsZero() || next.After(stop) || bf.Queued >= bf.Total {
+			bf.Next = ""
+		}
+	}
+
+	bf.UpdatedAt = b.Now().UTC().Format(time.RFC3339)
+	if bf.Next == "" && len(bf.Runs) == 0 {
+		b.finish(bf, influxdb.BackfillStatusSuccess, "")
+		delete(b.running, id)
+	}
+	if err := b.store.PutBackfill(ctx, bf); err != nil {
+		return err
+	}
+
+	if queued {
+		if err := b.sch.UpdateTask(ctx, task); err != nil && err != influxdb.ErrTaskNotClaimed {
+			return err
+		}
+	}
+	return queueErr
+}
+
+func (b *Backfiller) finish(bf *influxdb.Backfill, status, msg string) {
+	now := b.Now().UTC().Format(time.RFC3339)
+	bf.Status = status
+	bf.Error = msg
+	bf.Next = ""
+	bf.UpdatedAt = now
+	bf.FinishedAt = now
+}
+
+// backfillSchedule returns the schedule of task.
+func backfillSchedule(task *influxdb.Task) (cron.Schedule, error) {
+	sch, err := cron.Parse(task.EffectiveCron())
+	if err != nil {
+		return nil, influxdb.ErrTaskTimeParse(err)
+	}
+	return sch, nil
+}
+
+// backfillFirst returns the first schedule time of task after start.
+// Like the schedule times of runs created by the TaskControlService,
+// the times of a task scheduled with every are aligned to its period.
+func backfillFirst(task *influxdb.Task, sch cron.Schedule, start time.Time) time.Time {
+	if strings.HasPrefix(task.EffectiveCron(), "@every ") {
+		var every options.Duration
+		if err := every.Parse(strings.TrimPrefix(task.EffectiveCron(), "@every ")); err == nil {
+			if d, err := every.DurationFrom(start); err == nil && d > 0 {
+				start = start.Truncate(d)
+			}
+		}
+	}
+	return sch.Next(start).UTC()
+}
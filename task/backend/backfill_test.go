@@ -0,0 +1,224 @@
+package backend_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	platformmock "github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func newBackfiller(t *testing.T, task *influxdb.Task) (*backend.Backfiller, *mock.TaskControlService) {
+	t.Helper()
+
+	store := kv.NewService(inmem.NewKVStore())
+	if err := store.Initialize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := &platformmock.TaskService{
+		FindTaskByIDFn: func(_ context.Context, id influxdb.ID) (*influxdb.Task, error) {
+			if id != task.ID {
+				return nil, influxdb.ErrTaskNotFound
+			}
+			return task, nil
+		},
+	}
+
+	tcs := mock.NewTaskControlService()
+	tcs.SetTask(task)
+
+	sch := mock.NewScheduler()
+	if err := sch.ClaimTask(context.Background(), task); err != nil {
+		t.Fatal(err)
+	}
+
+	return backend.NewBackfiller(zaptest.NewLogger(t), store, ts, tcs, sch), tcs
+}
+
+// executeRuns starts and finishes every queued run, returning their scheduled times.
+func executeRuns(t *testing.T, tcs *mock.TaskControlService, taskID influxdb.ID) []string {
+	t.Helper()
+
+	ctx := context.Background()
+	var scheduledFor []string
+	for {
+		runs, err := tcs.ManualRuns(ctx, taskID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(runs) == 0 {
+			return scheduledFor
+		}
+		rc, err := tcs.CreateNextRun(ctx, taskID, time.Now().Unix())
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := tcs.FinishRun(ctx, taskID, rc.Created.RunID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scheduledFor = append(scheduledFor, r.ScheduledFor)
+	}
+}
+
+func TestBackfiller(t *testing.T) {
+	task := &influxdb.Task{
+		ID:             1,
+		OrganizationID: 2,
+		Status:         string(backend.TaskActive),
+		Every:          "1h",
+	}
+	bf, tcs := newBackfiller(t, task)
+	ctx := context.Background()
+
+	b, err := bf.CreateBackfill(ctx, task.ID, influxdb.BackfillCreate{
+		Start:       time.Date(2019, 1, 1, 0, 30, 0, 0, time.UTC),
+		Stop:        time.Date(2019, 1, 1, 5, 0, 0, 0, time.UTC),
+		Concurrency: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Status != influxdb.BackfillStatusRunning || b.Total != 5 || b.Queued != 2 || len(b.Runs) != 2 {
+		t.Fatalf("unexpected backfill after create: %+v", b)
+	}
+	if b.Next != "2019-01-01T03:00:00Z" {
+		t.Fatalf("unexpected next schedule time %q", b.Next)
+	}
+
+	var scheduledFor []string
+	for i := 0; i < 3; i++ {
+		scheduledFor = append(scheduledFor, executeRuns(t, tcs, task.ID)...)
+		bf.Tick(ctx)
+	}
+
+	want := []string{
+		"2019-01-01T01:00:00Z",
+		"2019-01-01T02:00:00Z",
+		"2019-01-01T03:00:00Z",
+		"2019-01-01T04:00:00Z",
+		"2019-01-01T05:00:00Z",
+	}
+	if len(scheduledFor) != len(want) {
+		t.Fatalf("expected runs scheduled for %v, got %v", want, scheduledFor)
+	}
+	for i := range want {
+		if scheduledFor[i] != want[i] {
+			t.Fatalf("expected runs scheduled for %v, got %v", want, scheduledFor)
+		}
+	}
+
+	if _, err := bf.FindBackfillByID(ctx, 2, b.ID); err != influxdb.ErrBackfillNotFound {
+		t.Fatalf("expected ErrBackfillNotFound finding a backfill of another task, got %v", err)
+	}
+
+	b, err = bf.FindBackfillByID(ctx, task.ID, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Status != influxdb.BackfillStatusSuccess || b.Queued != 5 || b.Finished != 5 || len(b.Runs) != 0 || b.FinishedAt == "" {
+		t.Fatalf("unexpected backfill after finishing: %+v", b)
+	}
+
+	if _, err := bf.CancelBackfill(ctx, task.ID, b.ID); err != influxdb.ErrBackfillNotRunning {
+		t.Fatalf("expected ErrBackfillNotRunning canceling a finished backfill, got %v", err)
+	}
+}
+
+func TestBackfiller_Cancel(t *testing.T) {
+	task := &influxdb.Task{
+		ID:             1,
+		OrganizationID: 2,
+		Status:         string(backend.TaskActive),
+		Cron:           "0 * * * *",
+	}
+	bf, tcs := newBackfiller(t, task)
+	ctx := context.Background()
+
+	b, err := bf.CreateBackfill(ctx, task.ID, influxdb.BackfillCreate{
+		Start: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		Stop:  time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Total != 24 || b.Concurrency != 1 || b.Queued != 1 {
+		t.Fatalf("unexpected backfill after create: %+v", b)
+	}
+
+	b, err = bf.CancelBackfill(ctx, task.ID, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Status != influxdb.BackfillStatusCanceled || b.Next != "" {
+		t.Fatalf("unexpected backfill after cancel: %+v", b)
+	}
+
+	// The run queued before canceling is still executed, but no more are queued.
+	if runs := executeRuns(t, tcs, task.ID); len(runs) != 1 {
+		t.Fatalf("expected the one queued run to execute, got %v", runs)
+	}
+	bf.Tick(ctx)
+	if runs := executeRuns(t, tcs, task.ID); len(runs) != 0 {
+		t.Fatalf("expected no more runs after cancel, got %v", runs)
+	}
+
+	bs, err := bf.FindBackfills(ctx, influxdb.BackfillFilter{Task: &task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bs) != 1 || bs[0].Status != influxdb.BackfillStatusCanceled {
+		t.Fatalf("unexpected backfills %+v", bs)
+	}
+}
+
+func TestBackfiller_CreateErrors(t *testing.T) {
+	start := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name   string
+		task   influxdb.Task
+		create influxdb.BackfillCreate
+	}{
+		{
+			name:   "stop before start",
+			task:   influxdb.Task{Status: string(backend.TaskActive), Every: "1h"},
+			create: influxdb.BackfillCreate{Start: start, Stop: start.Add(-time.Hour)},
+		},
+		{
+			name:   "concurrency too large",
+			task:   influxdb.Task{Status: string(backend.TaskActive), Every: "1h"},
+			create: influxdb.BackfillCreate{Start: start, Stop: start.Add(time.Hour), Concurrency: influxdb.BackfillMaxConcurrency + 1},
+		},
+		{
+			name:   "inactive task",
+			task:   influxdb.Task{Status: string(backend.TaskInactive), Every: "1h"},
+			create: influxdb.BackfillCreate{Start: start, Stop: start.Add(time.Hour)},
+		},
+		{
+			name:   "no schedule times",
+			task:   influxdb.Task{Status: string(backend.TaskActive), Every: "1d"},
+			create: influxdb.BackfillCreate{Start: start.Add(time.Hour), Stop: start.Add(2 * time.Hour)},
+		},
+		{
+			name:   "too many runs",
+			task:   influxdb.Task{Status: string(backend.TaskActive), Every: "1s"},
+			create: influxdb.BackfillCreate{Start: start, Stop: start.Add(365 * 24 * time.Hour)},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.task.ID = 1
+			bf, _ := newBackfiller(t, &tt.task)
+			_, err := bf.CreateBackfill(context.Background(), tt.task.ID, tt.create)
+			if influxdb.ErrorCode(err) != influxdb.EInvalid {
+				t.Fatalf("expected invalid error, got %v", err)
+			}
+		})
+	}
+}
@@ -10,18 +10,20 @@ import (
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
 	icontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/options"
 	"go.uber.org/zap"
 )
 
 // queryServiceExecutor is an implementation of backend.Executor that depends on a QueryService.
 type queryServiceExecutor struct {
 	qs     query.QueryService
-	as     influxdb.AuthorizationService
+	tokens *authorizer.TokenAuthenticator
 	ts     influxdb.TaskService
 	logger *zap.Logger
 	wg     sync.WaitGroup
@@ -32,8 +34,9 @@ var _ backend.Executor = (*queryServiceExecutor)(nil)
 // NewQueryServiceExecutor returns a new executor based on the given QueryService.
 // In general, you should prefer NewAsyncQueryServiceExecutor, as that code is smaller and simpler,
 // because asynchronous queries are more in line with the Executor interface.
-func NewQueryServiceExecutor(logger *zap.Logger, qs query.QueryService, as influxdb.AuthorizationService, ts influxdb.TaskService) *queryServiceExecutor {
-	return &queryServiceExecutor{logger: logger, qs: qs, as: as, ts: ts}
+// The runs of a task act with the authorization of the task, found by tokens.
+func NewQueryServiceExecutor(logger *zap.Logger, qs query.QueryService, tokens *authorizer.TokenAuthenticator, ts influxdb.TaskService) *queryServiceExecutor {
+	return &queryServiceExecutor{logger: logger, qs: qs, tokens: tokens, ts: ts}
 }
 
 // AddTaskService is a temporary solution to a chicken and egg problem. It takes a executor and sets the task service.
@@ -56,13 +59,18 @@ func (e *queryServiceExecutor) Execute(ctx context.Context, run backend.QueuedRu
 		return nil, err
 	}
 
-	auth, err := e.as.FindAuthorizationByID(ctx, influxdb.ID(t.AuthorizationID))
+	auth, err := e.tokens.FindAuthorizationByID(ctx, t.AuthorizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := runTimeout(t)
 	if err != nil {
 		return nil, err
 	}
 
 	// TODO(goller): remove need for context authorization.
-	return newSyncRunPromise(icontext.SetAuthorizer(ctx, auth), auth, run, e, t), nil
+	return newSyncRunPromise(icontext.SetAuthorizer(ctx, auth), auth, run, e, t, timeout), nil
 }
 
 func (e *queryServiceExecutor) Wait() {
@@ -88,8 +96,13 @@ type syncRunPromise struct {
 
 var _ backend.RunPromise = (*syncRunPromise)(nil)
 
-func newSyncRunPromise(ctx context.Context, auth *influxdb.Authorization, qr backend.QueuedRun, e *queryServiceExecutor, t *influxdb.Task) *syncRunPromise {
-	ctx, cancel := context.WithCancel(ctx)
+func newSyncRunPromise(ctx context.Context, auth *influxdb.Authorization, qr backend.QueuedRun, e *queryServiceExecutor, t *influxdb.Task, timeout time.Duration) *syncRunPromise {
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
 	opLogger := e.logger.With(zap.Stringer("task_id", qr.TaskID), zap.Stringer("run_id", qr.RunID))
 	log, logEnd := logger.NewOperation(ctx, opLogger, "Executing task", "execute")
 	rp := &syncRunPromise{
@@ -138,6 +151,11 @@ func (p *syncRunPromise) finish(res *runResult, err error) {
 		// If afterwards, then p.cancel is just a resource cleanup.
 		defer p.cancel()
 
+		// Whatever error the query stopped with, it stopped because it ran out of time.
+		if err != influxdb.ErrRunCanceled && p.ctx.Err() == context.DeadlineExceeded {
+			res, err = nil, influxdb.ErrRunTimedOut
+		}
+
 		p.res, p.err = res, err
 		close(p.ready)
 
@@ -167,6 +185,7 @@ func (p *syncRunPromise) doQuery(wg *sync.WaitGroup) {
 			AST: pkg,
 			Now: time.Unix(p.qr.Now, 0),
 		},
+		Priority: query.PriorityTask,
 	}
 	it, err := p.qs.Query(p.ctx, req)
 	if err != nil {
@@ -194,7 +213,7 @@ func (p *syncRunPromise) doQuery(wg *sync.WaitGroup) {
 	}
 
 	// Is it okay to assume it.Err will be set if the query context is canceled?
-	p.finish(&runResult{err: err, statistics: it.Statistics()}, nil)
+	p.finish(&runResult{err: err, retryable: backend.IsRetryableError(err), statistics: it.Statistics()}, nil)
 }
 
 func (p *syncRunPromise) cancelOnContextDone(wg *sync.WaitGroup) {
@@ -214,7 +233,7 @@ func (p *syncRunPromise) cancelOnContextDone(wg *sync.WaitGroup) {
 // asyncQueryServiceExecutor is an implementation of backend.Executor that depends on an AsyncQueryService.
 type asyncQueryServiceExecutor struct {
 	qs     query.AsyncQueryService
-	as     influxdb.AuthorizationService
+	tokens *authorizer.TokenAuthenticator
 	ts     influxdb.TaskService
 	logger *zap.Logger
 	wg     sync.WaitGroup
@@ -223,8 +242,9 @@ type asyncQueryServiceExecutor struct {
 var _ backend.Executor = (*asyncQueryServiceExecutor)(nil)
 
 // NewAsyncQueryServiceExecutor returns a new executor based on the given AsyncQueryService.
-func NewAsyncQueryServiceExecutor(logger *zap.Logger, qs query.AsyncQueryService, as influxdb.AuthorizationService, ts influxdb.TaskService) backend.Executor {
-	return &asyncQueryServiceExecutor{logger: logger, qs: qs, as: as, ts: ts}
+// The runs of a task act with the authorization of the task, found by tokens.
+func NewAsyncQueryServiceExecutor(logger *zap.Logger, qs query.AsyncQueryService, tokens *authorizer.TokenAuthenticator, ts influxdb.TaskService) backend.Executor {
+	return &asyncQueryServiceExecutor{logger: logger, qs: qs, tokens: tokens, ts: ts}
 }
 
 func (e *asyncQueryServiceExecutor) Execute(ctx context.Context, run backend.QueuedRun) (backend.RunPromise, error) {
@@ -233,7 +253,7 @@ func (e *asyncQueryServiceExecutor) Execute(ctx context.Context, run backend.Que
 		return nil, err
 	}
 
-	auth, err := e.as.FindAuthorizationByID(ctx, influxdb.ID(t.AuthorizationID))
+	auth, err := e.tokens.FindAuthorizationByID(ctx, t.AuthorizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -243,6 +263,11 @@ func (e *asyncQueryServiceExecutor) Execute(ctx context.Context, run backend.Que
 		return nil, err
 	}
 
+	timeout, err := runTimeout(t)
+	if err != nil {
+		return nil, err
+	}
+
 	req := &query.Request{
 		Authorization:  auth,
 		OrganizationID: t.OrganizationID,
@@ -250,6 +275,7 @@ func (e *asyncQueryServiceExecutor) Execute(ctx context.Context, run backend.Que
 			AST: pkg,
 			Now: time.Unix(run.Now, 0),
 		},
+		Priority: query.PriorityTask,
 	}
 	// Only set the authorizer on the context where we need it here.
 	q, err := e.qs.Query(icontext.SetAuthorizer(ctx, auth), req)
@@ -257,7 +283,7 @@ func (e *asyncQueryServiceExecutor) Execute(ctx context.Context, run backend.Que
 		return nil, err
 	}
 
-	return newAsyncRunPromise(ctx, run, q, e), nil
+	return newAsyncRunPromise(ctx, run, q, e, timeout), nil
 }
 
 func (e *asyncQueryServiceExecutor) Wait() {
@@ -269,6 +295,9 @@ type asyncRunPromise struct {
 	qr backend.QueuedRun
 	q  flux.Query
 
+	// Time the query may execute before it is canceled, or 0 for no limit.
+	timeout time.Duration
+
 	logger *zap.Logger
 	logEnd func() // Called to log the end of the run operation.
 
@@ -280,7 +309,7 @@ type asyncRunPromise struct {
 
 var _ backend.RunPromise = (*asyncRunPromise)(nil)
 
-func newAsyncRunPromise(ctx context.Context, qr backend.QueuedRun, q flux.Query, e *asyncQueryServiceExecutor) *asyncRunPromise {
+func newAsyncRunPromise(ctx context.Context, qr backend.QueuedRun, q flux.Query, e *asyncQueryServiceExecutor, timeout time.Duration) *asyncRunPromise {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
@@ -288,9 +317,10 @@ func newAsyncRunPromise(ctx context.Context, qr backend.QueuedRun, q flux.Query,
 	log, logEnd := logger.NewOperation(ctx, opLogger, "Executing task", "execute")
 
 	p := &asyncRunPromise{
-		qr:    qr,
-		q:     q,
-		ready: make(chan struct{}),
+		qr:      qr,
+		q:       q,
+		timeout: timeout,
+		ready:   make(chan struct{}),
 
 		logger: log,
 		logEnd: logEnd,
@@ -327,6 +357,13 @@ func (p *asyncRunPromise) followQuery(wg *sync.WaitGroup) {
 	// Always need to call Done after query is finished.
 	defer p.q.Done()
 
+	var timeout <-chan time.Time
+	if p.timeout > 0 {
+		timer := time.NewTimer(p.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
 	var rwg sync.WaitGroup
 SelectLoop:
 	for {
@@ -336,6 +373,10 @@ SelectLoop:
 			// But we do need to cancel the flux. This could be a no-op.
 			p.q.Cancel()
 			return
+		case <-timeout:
+			p.q.Cancel()
+			p.finish(nil, influxdb.ErrRunTimedOut)
+			return
 		case r, ok := <-p.q.Results():
 			if !ok {
 				break SelectLoop
@@ -355,7 +396,7 @@ SelectLoop:
 
 	if p.q.Err() != nil {
 		// Something went wrong with the flux. Set the error in the run result.
-		rr := &runResult{err: p.q.Err()}
+		rr := &runResult{err: p.q.Err(), retryable: backend.IsRetryableError(p.q.Err())}
 		p.finish(rr, nil)
 		return
 	}
@@ -383,6 +424,18 @@ func (p *asyncRunPromise) finish(res *runResult, err error) {
 	})
 }
 
+// runTimeout returns the time a run of t may execute, from its timeout option, or 0 if t has no timeout.
+func runTimeout(t *influxdb.Task) (time.Duration, error) {
+	opt, err := options.FromScript(t.Flux)
+	if err != nil {
+		return 0, err
+	}
+	if opt.Timeout == nil {
+		return 0, nil
+	}
+	return opt.Timeout.DurationFrom(time.Now())
+}
+
 type runResult struct {
 	err        error
 	retryable  bool
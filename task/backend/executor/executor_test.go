@@ -16,6 +16,7 @@ import (
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/values"
 	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
 	icontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/inmem"
 	"github.com/influxdata/influxdb/kv"
@@ -277,7 +278,7 @@ func createAsyncSystem() *system {
 		name: "AsyncExecutor",
 		svc:  svc,
 		ts:   i,
-		ex:   NewAsyncQueryServiceExecutor(zap.NewNop(), svc, i, i),
+		ex:   NewAsyncQueryServiceExecutor(zap.NewNop(), svc, authorizer.NewTokenAuthenticator(i, authorizer.NewRoleResolver(i, i)), i),
 		i:    i,
 	}
 }
@@ -298,7 +299,7 @@ func createSyncSystem() *system {
 			query.QueryServiceBridge{
 				AsyncQueryService: svc,
 			},
-			i,
+			authorizer.NewTokenAuthenticator(i, authorizer.NewRoleResolver(i, i)),
 			i,
 		),
 		i: i,
@@ -310,7 +311,10 @@ func TestExecutor(t *testing.T) {
 		testExecutorQuerySuccess(t, fn)
 		testExecutorQueryFailure(t, fn)
 		testExecutorPromiseCancel(t, fn)
+		testExecutorPromiseTimeout(t, fn)
 		testExecutorServiceError(t, fn)
+		testExecutorExpiredToken(t, fn)
+		testExecutorRoles(t, fn)
 		testExecutorWait(t, fn)
 	}
 }
@@ -450,6 +454,43 @@ func testExecutorPromiseCancel(t *testing.T, fn createSysFn) {
 	})
 }
 
+func testExecutorPromiseTimeout(t *testing.T, fn createSysFn) {
+	sys := fn()
+	tc := createCreds(t, sys.i)
+	t.Run(sys.name+"/PromiseTimeout", func(t *testing.T) {
+		t.Parallel()
+		script := fmt.Sprintf(`
+import "http"
+
+option task = {
+			name: %q,
+			every: 1m,
+			timeout: 1s,
+}
+
+from(bucket: "one") |> http.to(url: "http://example.com")`, t.Name())
+		ctx := icontext.SetAuthorizer(context.Background(), tc.Auth)
+		task, err := sys.ts.CreateTask(ctx, platform.TaskCreate{OrganizationID: tc.OrgID, Token: tc.Auth.Token, Flux: script})
+		if err != nil {
+			t.Fatal(err)
+		}
+		qr := backend.QueuedRun{TaskID: task.ID, RunID: platform.ID(1), Now: 123}
+		rp, err := sys.ex.Execute(context.Background(), qr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The query never completes, so it is canceled by the timeout of the task.
+		res, err := rp.Wait()
+		if err != platform.ErrRunTimedOut {
+			t.Fatalf("expected ErrRunTimedOut, got %v", err)
+		}
+		if res != nil {
+			t.Fatalf("expected nil result after timeout, got %#v", res)
+		}
+	})
+}
+
 func testExecutorServiceError(t *testing.T, fn createSysFn) {
 	sys := fn()
 	tc := createCreds(t, sys.i)
@@ -487,6 +528,81 @@ func testExecutorServiceError(t *testing.T, fn createSysFn) {
 	})
 }
 
+func testExecutorExpiredToken(t *testing.T, fn createSysFn) {
+	sys := fn()
+	tc := createCreds(t, sys.i)
+	t.Run(sys.name+"/ExpiredToken", func(t *testing.T) {
+		t.Parallel()
+		script := fmt.Sprintf(fmtTestScript, t.Name())
+		ctx := icontext.SetAuthorizer(context.Background(), tc.Auth)
+		task, err := sys.ts.CreateTask(ctx, platform.TaskCreate{OrganizationID: tc.OrgID, Token: tc.Auth.Token, Flux: script})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expired := time.Now().Add(-time.Minute)
+		if _, err := sys.i.UpdateAuthorization(context.Background(), task.AuthorizationID, &platform.AuthorizationUpdate{ExpiresAt: &expired}); err != nil {
+			t.Fatal(err)
+		}
+
+		qr := backend.QueuedRun{TaskID: task.ID, RunID: platform.ID(1), Now: 123}
+		if _, err := sys.ex.Execute(context.Background(), qr); platform.ErrorCode(err) != platform.EUnauthorized {
+			t.Fatalf("expected unauthorized error executing with an expired token, got %v", err)
+		}
+	})
+}
+
+func testExecutorRoles(t *testing.T, fn createSysFn) {
+	sys := fn()
+	tc := createCreds(t, sys.i)
+	t.Run(sys.name+"/Roles", func(t *testing.T) {
+		t.Parallel()
+		readDashboards, err := platform.NewPermission(platform.ReadAction, platform.DashboardsResourceType, tc.OrgID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		role := &platform.Role{OrgID: tc.OrgID, Name: t.Name(), Permissions: []platform.Permission{*readDashboards}}
+		if err := sys.i.CreateRole(context.Background(), role); err != nil {
+			t.Fatal(err)
+		}
+		auth := &platform.Authorization{
+			OrgID:       tc.OrgID,
+			UserID:      tc.Auth.UserID,
+			Permissions: tc.Auth.Permissions,
+			RoleIDs:     []platform.ID{role.ID},
+		}
+		if err := sys.i.CreateAuthorization(context.Background(), auth); err != nil {
+			t.Fatal(err)
+		}
+
+		script := fmt.Sprintf(fmtTestScript, t.Name())
+		ctx := icontext.SetAuthorizer(context.Background(), auth)
+		task, err := sys.ts.CreateTask(ctx, platform.TaskCreate{OrganizationID: tc.OrgID, Token: auth.Token, Flux: script})
+		if err != nil {
+			t.Fatal(err)
+		}
+		qr := backend.QueuedRun{TaskID: task.ID, RunID: platform.ID(1), Now: 123}
+		rp, err := sys.ex.Execute(context.Background(), qr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sys.svc.WaitForQueryLive(t, script)
+		sys.svc.SucceedQuery(script)
+		if _, err := rp.Wait(); err != nil {
+			t.Fatal(err)
+		}
+
+		// The run is granted the permissions of the roles of its authorization.
+		qa, err := icontext.GetAuthorizer(sys.svc.mostRecentCtx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !qa.Allowed(*readDashboards) {
+			t.Fatalf("expected run to be allowed the permission of its role %v", readDashboards)
+		}
+	})
+}
+
 func testExecutorWait(t *testing.T, createSys createSysFn) {
 	// This is a longer delay than I'd prefer,
 	// but it needs to be large-ish for slow machines running with the race detector.
@@ -10,6 +10,7 @@ import (
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/task/backend"
@@ -38,13 +39,14 @@ type Metrics interface {
 }
 
 // NewExecutor creates a new task executor
-func NewExecutor(logger *zap.Logger, qs query.QueryService, as influxdb.AuthorizationService, ts influxdb.TaskService, tcs backend.TaskControlService, metrics Metrics) *TaskExecutor {
+// The runs of a task act with the authorization of the task, found by tokens.
+func NewExecutor(logger *zap.Logger, qs query.QueryService, tokens *authorizer.TokenAuthenticator, ts influxdb.TaskService, tcs backend.TaskControlService, metrics Metrics) *TaskExecutor {
 	te := &TaskExecutor{
 		logger: logger,
 		ts:     ts,
 		tcs:    tcs,
 		qs:     qs,
-		as:     as,
+		tokens: tokens,
 
 		metrics:         metrics,
 		currentPromises: sync.Map{},
@@ -61,14 +63,16 @@ func NewExecutor(logger *zap.Logger, qs query.QueryService, as influxdb.Authoriz
 	return te
 }
 
+var _ scheduler.Executor = (*TaskExecutor)(nil)
+
 // TaskExecutor it a task specific executor that works with the new scheduler system.
 type TaskExecutor struct {
 	logger *zap.Logger
 	ts     influxdb.TaskService
 	tcs    backend.TaskControlService
 
-	qs query.QueryService
-	as influxdb.AuthorizationService
+	qs     query.QueryService
+	tokens *authorizer.TokenAuthenticator
 
 	metrics Metrics
 
@@ -95,7 +99,7 @@ func (e *TaskExecutor) SetLimitFunc(l LimitFunc) {
 // We then want to add to the queue anything that was manually queued to run.
 // If the queue is full the call to execute should hang and apply back pressure to the caller
 // We then start a worker to work the newly queued jobs.
-func (e *TaskExecutor) Execute(ctx context.Context, id scheduler.ID, scheduledAt time.Time) (*Promise, error) {
+func (e *TaskExecutor) Execute(ctx context.Context, id scheduler.ID, scheduledAt time.Time) (scheduler.Promise, error) {
 	iid := influxdb.ID(id)
 	var p *Promise
 	var err error
@@ -215,7 +219,7 @@ func (e *TaskExecutor) createPromise(ctx context.Context, run *influxdb.Run) (*P
 		return nil, err
 	}
 
-	auth, err := e.as.FindAuthorizationByID(ctx, t.AuthorizationID)
+	auth, err := e.tokens.FindAuthorizationByID(ctx, t.AuthorizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -371,6 +375,7 @@ func (w *worker) executeQuery(p *Promise) {
 			AST: pkg,
 			Now: sf,
 		},
+		Priority: query.PriorityTask,
 	}
 
 	it, err := w.te.qs.Query(ctx, req)
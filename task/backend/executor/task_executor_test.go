@@ -11,6 +11,7 @@ import (
 	"github.com/influxdata/flux"
 	"github.com/influxdata/influxdb"
 	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
 	icontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/inmem"
 	"github.com/influxdata/influxdb/kit/prom"
@@ -42,7 +43,7 @@ func taskExecutorSystem(t *testing.T) tes {
 
 	i := kv.NewService(inmem.NewKVStore())
 
-	ex := NewExecutor(zaptest.NewLogger(t), qs, i, i, i, &noopMetrics{})
+	ex := NewExecutor(zaptest.NewLogger(t), qs, authorizer.NewTokenAuthenticator(i, authorizer.NewRoleResolver(i, i)), i, i, &noopMetrics{})
 	return tes{
 		svc: aqs,
 		ex:  ex,
@@ -60,6 +61,7 @@ func TestTaskExecutor(t *testing.T) {
 	t.Run("LimitFunc", testLimitFunc)
 	t.Run("Metrics", testMetrics)
 	t.Run("IteratorFailure", testIteratorFailure)
+	t.Run("ExpiredToken", testExpiredToken)
 }
 
 func testQuerySuccess(t *testing.T) {
@@ -98,6 +100,27 @@ func testQuerySuccess(t *testing.T) {
 	}
 }
 
+func testExpiredToken(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	script := fmt.Sprintf(fmtTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, platform.TaskCreate{OrganizationID: tes.tc.OrgID, Token: tes.tc.Auth.Token, Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expired := time.Now().Add(-time.Minute)
+	if _, err := tes.i.UpdateAuthorization(context.Background(), task.AuthorizationID, &influxdb.AuthorizationUpdate{ExpiresAt: &expired}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tes.ex.Execute(ctx, scheduler.ID(task.ID), time.Unix(123, 0)); influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+		t.Fatalf("expected unauthorized error executing with an expired token, got %v", err)
+	}
+}
+
 func testQueryFailure(t *testing.T) {
 	t.Parallel()
 	tes := taskExecutorSystem(t)
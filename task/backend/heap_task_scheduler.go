@@ -0,0 +1,771 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/task/backend/scheduler"
+	"github.com/influxdata/influxdb/task/options"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultRetryBackoff is the default time to wait before the first retry of a failed run.
+	DefaultRetryBackoff = time.Second
+
+	// MaxRetryBackoff is the longest time to wait between attempts of a failed run.
+	MaxRetryBackoff = time.Minute
+)
+
+// IsRetryableError reports whether a run that failed with err may succeed if attempted again.
+// Errors caused by the task itself, such as an invalid script or missing permissions, are not retryable;
+// errors from a temporarily unavailable or overloaded system, and timeouts, are.
+func IsRetryableError(err error) bool {
+	switch err {
+	case nil, platform.ErrRunCanceled, context.Canceled:
+		return false
+	case platform.ErrRunTimedOut, context.DeadlineExceeded:
+		return true
+	}
+
+	switch e := err.(type) {
+	case *platform.Error:
+		switch platform.ErrorCode(e) {
+		case platform.EInternal, platform.EUnavailable, platform.ETooManyRequests:
+			return true
+		}
+		return false
+	case *flux.Error:
+		switch flux.ErrorCode(e) {
+		case codes.Unknown, codes.Internal, codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+			return true
+		}
+		return false
+	}
+
+	// Errors without a code, like those from storage, are assumed to be transient.
+	return true
+}
+
+// HeapTaskSchedulerOption is a option you can use to modify the behavior of a HeapTaskScheduler.
+type HeapTaskSchedulerOption func(*HeapTaskScheduler)
+
+// WithRetryBackoff sets the time to wait before the first retry of a failed run.
+// The wait doubles with each following attempt, up to MaxRetryBackoff.
+func WithRetryBackoff(d time.Duration) HeapTaskSchedulerOption {
+	return func(s *HeapTaskScheduler) {
+		s.retryBackoff = d
+	}
+}
+
+// WithSchedulerOptions sets the options of the scheduler.HeapScheduler scheduling the tasks.
+func WithSchedulerOptions(opts ...scheduler.Option) HeapTaskSchedulerOption {
+	return func(s *HeapTaskScheduler) {
+		s.schedulerOpts = append(s.schedulerOpts, opts...)
+	}
+}
+
+// HeapTaskScheduler is a Scheduler that schedules the runs of tasks with a scheduler.HeapScheduler.
+//
+// Each claimed task is scheduled on its cron or every option. When a task is due, a run is created
+// for the schedule time and executed with the Executor; once it has finished, the schedule time is
+// checkpointed, so that scheduling resumes after it when the task is claimed again.
+// A task has at most one scheduled run in progress.
+//
+// Manual runs, queued by forcing or retrying a run, by a backfill, or by the success of a run of a task
+// the task depends on, are executed one at a time as soon as they are queued, alongside the scheduled runs.
+//
+// A run that fails with a retryable error is attempted again after a backoff, up to the retry option of its task.
+type HeapTaskScheduler struct {
+	tcs      TaskControlService
+	executor Executor
+	sch      *scheduler.HeapScheduler
+
+	logger  *zap.Logger
+	metrics *schedulerMetrics
+
+	retryBackoff  time.Duration
+	schedulerOpts []scheduler.Option
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex                   // Protects the claimed tasks and their fields.
+	tasks map[platform.ID]*claimedTask // task ID -> claimed task.
+}
+
+var _ Scheduler = (*HeapTaskScheduler)(nil)
+
+// NewHeapTaskScheduler returns a HeapTaskScheduler that creates runs with tcs, executes them with executor,
+// and checkpoints the schedule times of the tasks with checkpointer.
+// The scheduler does nothing until Start is called.
+func NewHeapTaskScheduler(logger *zap.Logger, tcs TaskControlService, executor Executor, checkpointer scheduler.Checkpointer, opts ...HeapTaskSchedulerOption) *HeapTaskScheduler {
+	s := &HeapTaskScheduler{
+		tcs:          tcs,
+		executor:     executor,
+		logger:       logger.With(zap.String("svc", "taskd/scheduler")),
+		metrics:      newSchedulerMetrics(),
+		retryBackoff: DefaultRetryBackoff,
+		tasks:        make(map[platform.ID]*claimedTask),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	opt := append(s.schedulerOpts, scheduler.WithOnErrorFn(s.onError))
+	s.sch = scheduler.NewScheduler(taskExecutor{s: s}, checkpointer, opt...)
+	return s
+}
+
+// claimedTask is a task claimed by a HeapTaskScheduler.
+type claimedTask struct {
+	id platform.ID
+
+	// task, authCtx, attempts, running, working and queued are protected by the mutex of the scheduler.
+	task *platform.Task
+
+	// Authorization context for using the TaskControlService.
+	authCtx context.Context
+
+	// Number of times a run is attempted before it fails, from the retry option.
+	attempts int64
+
+	logger *zap.Logger
+
+	// ctx is canceled when the task is released, canceling its runs.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// running maps the IDs of the runs in progress to the functions canceling them.
+	running map[platform.ID]context.CancelFunc
+
+	// working is whether the manual runs of the task are being executed,
+	// and queued whether more were queued meanwhile.
+	working bool
+	queued  bool
+}
+
+// schedulableTask is the scheduler.Schedulable of a task, scheduled on its cron or every option.
+type schedulableTask struct {
+	task *platform.Task
+}
+
+func (t schedulableTask) ID() scheduler.ID {
+	return scheduler.ID(t.task.ID)
+}
+
+func (t schedulableTask) Schedule() scheduler.Schedule {
+	return scheduler.Schedule{
+		Schedule: t.task.EffectiveCron(),
+		Offset:   taskOffset(t.task),
+	}
+}
+
+// taskOffset returns the offset option of t, or 0 if it has none or it is invalid.
+func taskOffset(t *platform.Task) time.Duration {
+	if t.Offset == "" {
+		return 0
+	}
+	var offset options.Duration
+	if err := offset.Parse(t.Offset); err != nil {
+		return 0
+	}
+	d, err := offset.DurationFrom(time.Now())
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// taskExecutor is the scheduler.Executor of a HeapTaskScheduler.
+// It creates a run of a task for each schedule time, and executes it.
+type taskExecutor struct {
+	s *HeapTaskScheduler
+}
+
+func (e taskExecutor) Execute(ctx context.Context, id scheduler.ID, scheduledAt time.Time) (scheduler.Promise, error) {
+	s := e.s
+
+	s.mu.Lock()
+	ct, ok := s.tasks[platform.ID(id)]
+	var offset time.Duration
+	if ok {
+		offset = taskOffset(ct.task)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil, platform.ErrTaskNotClaimed
+	}
+
+	span, ctx := tracing.StartSpanFromContext(ct.ctx)
+	defer span.Finish()
+
+	r, err := s.tcs.CreateRun(ctx, platform.ID(id), scheduledAt)
+	if err != nil {
+		ct.logger.Info("Failed to create run", zap.Error(err))
+		return nil, err
+	}
+
+	qr := QueuedRun{
+		TaskID: platform.ID(id),
+		RunID:  r.ID,
+		DueAt:  scheduledAt.Add(offset).Unix(),
+		Now:    scheduledAt.Unix(),
+	}
+	return s.startRun(ct, qr), nil
+}
+
+func (e taskExecutor) Cancel(ctx context.Context, promiseID scheduler.ID) error {
+	s := e.s
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ct := range s.tasks {
+		if cancel, ok := ct.running[platform.ID(promiseID)]; ok {
+			cancel()
+			return nil
+		}
+	}
+	return platform.ErrRunNotFound
+}
+
+// runPromise is the scheduler.Promise of a run executed by a HeapTaskScheduler.
+type runPromise struct {
+	id     platform.ID
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+func (p *runPromise) ID() scheduler.ID {
+	return scheduler.ID(p.id)
+}
+
+func (p *runPromise) Cancel(ctx context.Context) {
+	p.cancel()
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+	}
+}
+
+func (p *runPromise) Done() <-chan struct{} {
+	return p.done
+}
+
+func (p *runPromise) Error() error {
+	<-p.done
+	return p.err
+}
+
+// onError logs the errors of the scheduler.HeapScheduler.
+func (s *HeapTaskScheduler) onError(ctx context.Context, id scheduler.ID, scheduledAt time.Time, err error) {
+	s.logger.Info("Failed to execute scheduled run", zap.String("task_id", platform.ID(id).String()), zap.Time("scheduled_for", scheduledAt), zap.Error(err))
+}
+
+// Start begins scheduling the claimed tasks until ctx is done or Stop is called.
+func (s *HeapTaskScheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.mu.Unlock()
+
+	s.sch.Start(s.ctx)
+}
+
+// Stop stops scheduling, cancels the runs in progress and releases every task.
+func (s *HeapTaskScheduler) Stop() {
+	s.mu.Lock()
+	// if I was never started I cant stop
+	if s.cancel == nil {
+		s.mu.Unlock()
+		return
+	}
+	s.cancel()
+
+	// release tasks
+	for id, ct := range s.tasks {
+		ct.cancel()
+		delete(s.tasks, id)
+		s.metrics.ReleaseTask(id.String())
+	}
+	s.mu.Unlock()
+
+	s.sch.Stop()
+
+	// Wait for runs to clean up.
+	s.wg.Wait()
+
+	// Wait for outstanding executions to finish.
+	s.executor.Wait()
+}
+
+// Now returns the current time of the scheduler.
+func (s *HeapTaskScheduler) Now() time.Time {
+	return s.sch.Now()
+}
+
+// ClaimTask begins the scheduling of task, after its latest completed run.
+// Runs left in progress, e.g. by a restart, are executed again, and queued manual runs are executed.
+// Inactive tasks are not claimed.
+func (s *HeapTaskScheduler) ClaimTask(authCtx context.Context, task *platform.Task) (err error) {
+	if task.Status == string(TaskInactive) {
+		return nil
+	}
+
+	opt, err := options.FromScript(task.Flux)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.ctx == nil {
+		s.mu.Unlock()
+		return errors.New("can not claim tasks when i've not been started")
+	}
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return errors.New("can not claim a task if not started")
+	}
+
+	defer func() { s.metrics.ClaimTask(err == nil) }()
+
+	if _, ok := s.tasks[task.ID]; ok {
+		s.mu.Unlock()
+		return platform.ErrTaskAlreadyClaimed
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	ct := &claimedTask{
+		id:       task.ID,
+		task:     task,
+		authCtx:  authCtx,
+		attempts: runAttempts(opt),
+		logger:   s.logger.With(zap.String("task_id", task.ID.String())),
+		ctx:      ctx,
+		cancel:   cancel,
+		running:  make(map[platform.ID]context.CancelFunc),
+	}
+	s.tasks[task.ID] = ct
+	s.mu.Unlock()
+
+	// pickup any runs that are still "running from a previous failure"
+	runs, err := s.tcs.CurrentlyRunning(authCtx, task.ID)
+	if err != nil {
+		s.unclaim(ct)
+		return err
+	}
+
+	if err := s.sch.Schedule(schedulableTask{task: task}); err != nil {
+		s.unclaim(ct)
+		return err
+	}
+
+	for _, r := range runs {
+		t, err := r.ScheduledForTime()
+		if err != nil {
+			ct.logger.Info("Failed to resume run", zap.String("run_id", r.ID.String()), zap.Error(err))
+			continue
+		}
+		s.startRun(ct, QueuedRun{TaskID: task.ID, RunID: r.ID, DueAt: time.Now().UTC().Unix(), Now: t.Unix()})
+	}
+
+	s.workManualRuns(ct)
+	return nil
+}
+
+// unclaim removes ct after it failed to be claimed.
+func (s *HeapTaskScheduler) unclaim(ct *claimedTask) {
+	s.mu.Lock()
+	if s.tasks[ct.id] == ct {
+		delete(s.tasks, ct.id)
+	}
+	s.mu.Unlock()
+
+	ct.cancel()
+}
+
+// UpdateTask updates the schedule and retry option of a claimed task, and executes its queued manual runs.
+// An inactive task is released.
+func (s *HeapTaskScheduler) UpdateTask(authCtx context.Context, task *platform.Task) error {
+	if task.Status == string(TaskInactive) {
+		if err := s.ReleaseTask(task.ID); err != nil && err != platform.ErrTaskNotClaimed {
+			return err
+		}
+		return nil
+	}
+
+	opt, err := options.FromScript(task.Flux)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	ct, ok := s.tasks[task.ID]
+	if !ok {
+		s.mu.Unlock()
+		return platform.ErrTaskNotClaimed
+	}
+	ct.task = task
+	ct.authCtx = authCtx
+	ct.attempts = runAttempts(opt)
+	s.mu.Unlock()
+
+	if err := s.sch.Schedule(schedulableTask{task: task}); err != nil {
+		return err
+	}
+
+	s.workManualRuns(ct)
+	return nil
+}
+
+// ReleaseTask stops the scheduling of the task with ID taskID, and cancels its runs in progress.
+func (s *HeapTaskScheduler) ReleaseTask(taskID platform.ID) error {
+	s.mu.Lock()
+	ct, ok := s.tasks[taskID]
+	if !ok {
+		s.mu.Unlock()
+		return platform.ErrTaskNotClaimed
+	}
+	delete(s.tasks, taskID)
+	s.mu.Unlock()
+
+	ct.cancel()
+	if err := s.sch.Release(scheduler.ID(taskID)); err != nil && err != scheduler.ErrNotScheduled {
+		return err
+	}
+
+	s.metrics.ReleaseTask(taskID.String())
+	return nil
+}
+
+// CancelRun cancels a run, it has the unused Context argument so that it can implement a task.RunController
+func (s *HeapTaskScheduler) CancelRun(_ context.Context, taskID, runID platform.ID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ct, ok := s.tasks[taskID]
+	if !ok {
+		return platform.ErrTaskNotFound
+	}
+	cancel, ok := ct.running[runID]
+	if !ok {
+		return platform.ErrRunNotFound
+	}
+	cancel()
+	return nil
+}
+
+// WorkDependents executes the runs queued for the claimed tasks that depend on the task with ID taskID,
+// after a run of that task finished successfully.
+func (s *HeapTaskScheduler) WorkDependents(taskID platform.ID) {
+	s.mu.Lock()
+	var dependents []*claimedTask
+	for _, ct := range s.tasks {
+		if ct.task.DependsOnTask(taskID) {
+			dependents = append(dependents, ct)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, ct := range dependents {
+		s.workManualRuns(ct)
+	}
+}
+
+func (s *HeapTaskScheduler) PrometheusCollectors() []prometheus.Collector {
+	return s.metrics.PrometheusCollectors()
+}
+
+// workManualRuns executes the queued manual runs of ct one at a time, until there are none left.
+func (s *HeapTaskScheduler) workManualRuns(ct *claimedTask) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ct.working {
+		ct.queued = true
+		return
+	}
+	if ct.ctx.Err() != nil {
+		return
+	}
+	ct.working = true
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			s.mu.Lock()
+			ct.queued = false
+			s.mu.Unlock()
+
+			for s.executeManualRun(ct) {
+			}
+
+			s.mu.Lock()
+			if !ct.queued || ct.ctx.Err() != nil {
+				ct.working = false
+				s.mu.Unlock()
+				return
+			}
+			s.mu.Unlock()
+		}
+	}()
+}
+
+// executeManualRun starts the first queued manual run of ct and waits for it to finish.
+// It returns false if there was no run to start.
+func (s *HeapTaskScheduler) executeManualRun(ct *claimedTask) bool {
+	if ct.ctx.Err() != nil {
+		return false
+	}
+
+	s.mu.Lock()
+	authCtx := ct.authCtx
+	s.mu.Unlock()
+
+	runs, err := s.tcs.ManualRuns(authCtx, ct.id)
+	if err != nil {
+		ct.logger.Info("Failed to find manual runs", zap.Error(err))
+		return false
+	}
+	if len(runs) == 0 {
+		return false
+	}
+
+	r, err := s.tcs.StartManualRun(ct.ctx, ct.id, runs[0].ID)
+	if err != nil {
+		ct.logger.Info("Failed to start manual run", zap.String("run_id", runs[0].ID.String()), zap.Error(err))
+		return false
+	}
+	t, err := r.ScheduledForTime()
+	if err != nil {
+		ct.logger.Info("Failed to start manual run", zap.String("run_id", r.ID.String()), zap.Error(err))
+		return false
+	}
+
+	qr := QueuedRun{TaskID: ct.id, RunID: r.ID, DueAt: time.Now().UTC().Unix(), Now: t.Unix()}
+	if reqAt, err := r.RequestedAtTime(); err == nil && !reqAt.IsZero() {
+		qr.RequestedAt = reqAt.Unix()
+	}
+
+	<-s.startRun(ct, qr).Done()
+	return true
+}
+
+// startRun begins the execution of qr, a run of ct, on a separate goroutine.
+func (s *HeapTaskScheduler) startRun(ct *claimedTask, qr QueuedRun) *runPromise {
+	ctx, cancel := context.WithCancel(ct.ctx)
+	p := &runPromise{
+		id:     qr.RunID,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	ct.running[qr.RunID] = cancel
+	rt := runTask{task: ct.task, authCtx: ct.authCtx, attempts: ct.attempts}
+	s.mu.Unlock()
+
+	// Create a new child logger for the individual run.
+	runLogger := ct.logger.With(logger.TraceID(ctx), zap.String("run_id", qr.RunID.String()), zap.Int64("now", qr.Now))
+	runLogger.Info("Created run; beginning execution")
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer close(p.done)
+		defer func() {
+			s.mu.Lock()
+			delete(ct.running, qr.RunID)
+			s.mu.Unlock()
+			cancel() // cleanup
+		}()
+
+		p.err = s.executeAndWait(ctx, ct, rt, qr, runLogger)
+	}()
+	return p
+}
+
+// runTask is the state of a claimed task when one of its runs started.
+type runTask struct {
+	task     *platform.Task
+	authCtx  context.Context
+	attempts int64
+}
+
+// executeAndWait executes qr, attempting it again after retryable failures, up to the number of attempts of the task.
+// It returns the error the run failed with.
+func (s *HeapTaskScheduler) executeAndWait(ctx context.Context, ct *claimedTask, rt runTask, qr QueuedRun, runLogger *zap.Logger) (err error) {
+	authCtx, attempts := rt.authCtx, rt.attempts
+
+	s.updateRunState(ct, rt, qr, RunStarted, runLogger)
+
+	defer func() {
+		if _, ferr := s.tcs.FinishRun(ct.ctx, qr.TaskID, qr.RunID); ferr != nil {
+			// TODO(mr): Need to figure out how to reconcile this error, on the next run, if it happens.
+			runLogger.Error("Failed to finish run", zap.Error(ferr))
+			return
+		}
+		if err == nil {
+			// Finishing the run queued a run of each dependent task.
+			s.WorkDependents(qr.TaskID)
+		}
+	}()
+
+	for attempt := int64(1); ; attempt++ {
+		if attempt > 1 {
+			s.addRunLog(authCtx, qr, runLogger, fmt.Sprintf("Starting attempt %d of %d", attempt, attempts))
+		}
+
+		rr, stage, err := s.execute(ctx, qr, runLogger)
+		if err == platform.ErrRunCanceled {
+			s.updateRunState(ct, rt, qr, RunCanceled, runLogger)
+			return err
+		}
+
+		if err == nil {
+			stats := rr.Statistics()
+
+			b, err := json.Marshal(stats)
+			if err == nil {
+				s.tcs.AddRunLog(authCtx, qr.TaskID, qr.RunID, time.Now(), string(b))
+			}
+			s.updateRunState(ct, rt, qr, RunSuccess, runLogger)
+			runLogger.Info("Execution succeeded")
+			return nil
+		}
+
+		if err == platform.ErrRunTimedOut {
+			s.metrics.TimeoutRun(qr.TaskID.String())
+		}
+
+		retryable := IsRetryableError(err)
+		if rr != nil {
+			retryable = rr.IsRetryable()
+		}
+		if !retryable || attempt >= attempts {
+			s.addRunLog(authCtx, qr, runLogger, stage+": "+err.Error())
+			s.updateRunState(ct, rt, qr, RunFail, runLogger)
+			return err
+		}
+
+		backoff := s.backoff(attempt)
+		runLogger.Info("Run attempt failed; retrying", zap.Int64("attempt", attempt), zap.Duration("backoff", backoff), zap.Error(err))
+		s.addRunLog(authCtx, qr, runLogger, fmt.Sprintf("Attempt %d of %d failed: %s: %v; retrying in %s", attempt, attempts, stage, err, backoff))
+		s.metrics.RetryRun(qr.TaskID.String())
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			s.updateRunState(ct, rt, qr, RunCanceled, runLogger)
+			return platform.ErrRunCanceled
+		}
+	}
+}
+
+// execute makes a single attempt of qr.
+// If the attempt fails, the returned error is non-nil and stage describes where it failed;
+// the RunResult is only returned when the run executed.
+func (s *HeapTaskScheduler) execute(ctx context.Context, qr QueuedRun, runLogger *zap.Logger) (RunResult, string, error) {
+	sp, spCtx := tracing.StartSpanFromContext(ctx)
+	defer sp.Finish()
+
+	rp, err := s.executor.Execute(spCtx, qr)
+	if err != nil {
+		runLogger.Info("Failed to begin run execution", zap.Error(err))
+		return nil, "Run failed to begin execution", err
+	}
+
+	ready := make(chan struct{})
+	go func() {
+		// If the run's context is canceled, cancel the RunPromise.
+		select {
+		case <-ctx.Done():
+			rp.Cancel()
+		// Wait finished.
+		case <-ready:
+		}
+	}()
+
+	rr, err := rp.Wait()
+	close(ready)
+	if err != nil {
+		if err != platform.ErrRunCanceled {
+			runLogger.Info("Failed to wait for execution result", zap.Error(err))
+		}
+		return nil, "Waiting for execution result", err
+	}
+	if err := rr.Err(); err != nil {
+		runLogger.Info("Run failed to execute", zap.Error(err))
+		return rr, "Run failed to execute", err
+	}
+	return rr, "", nil
+}
+
+// runAttempts returns the number of times a run of a task with opt is attempted.
+func runAttempts(opt options.Options) int64 {
+	if opt.Retry == nil {
+		return 1
+	}
+	return *opt.Retry
+}
+
+// backoff returns the time to wait after the given failed attempt of a run, counting from 1.
+func (s *HeapTaskScheduler) backoff(attempt int64) time.Duration {
+	d := s.retryBackoff
+	for i := int64(1); i < attempt && d < MaxRetryBackoff; i++ {
+		d *= 2
+	}
+	if d > MaxRetryBackoff {
+		d = MaxRetryBackoff
+	}
+	return d
+}
+
+// addRunLog adds msg to the logs of qr.
+func (s *HeapTaskScheduler) addRunLog(authCtx context.Context, qr QueuedRun, runLogger *zap.Logger, msg string) {
+	if err := s.tcs.AddRunLog(authCtx, qr.TaskID, qr.RunID, time.Now(), msg); err != nil {
+		runLogger.Info("Failed to update run log", zap.Error(err))
+	}
+}
+
+func (s *HeapTaskScheduler) updateRunState(ct *claimedTask, rt runTask, qr QueuedRun, rs RunStatus, runLogger *zap.Logger) {
+	tid := qr.TaskID.String()
+	switch rs {
+	case RunStarted:
+		dueAt := time.Unix(qr.DueAt, 0)
+		s.metrics.StartRun(tid, time.Since(dueAt))
+		s.addRunLog(rt.authCtx, qr, runLogger, fmt.Sprintf("Started task from script: %q", rt.task.Flux))
+	case RunSuccess:
+		s.metrics.FinishRun(tid, true)
+		s.addRunLog(rt.authCtx, qr, runLogger, "Completed successfully")
+	case RunFail:
+		s.metrics.FinishRun(tid, false)
+		s.addRunLog(rt.authCtx, qr, runLogger, "Failed")
+	case RunCanceled:
+		s.metrics.FinishRun(tid, false)
+		s.addRunLog(rt.authCtx, qr, runLogger, "Canceled")
+	default:
+		runLogger.Warn("Unhandled run state", zap.Stringer("state", rs))
+	}
+
+	if err := s.tcs.UpdateRunState(ct.ctx, qr.TaskID, qr.RunID, time.Now(), rs); err != nil {
+		runLogger.Info("Error updating run state", zap.Stringer("state", rs), zap.Error(err))
+	}
+}
@@ -0,0 +1,393 @@
+package backend_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/prom"
+	"github.com/influxdata/influxdb/kit/prom/promtest"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/backend/scheduler"
+	"github.com/influxdata/influxdb/task/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+// checkpointer keeps the checkpoints of tasks in memory, starting from their LatestCompleted.
+type checkpointer struct {
+	mu   sync.Mutex
+	last map[scheduler.ID]time.Time
+}
+
+func newCheckpointer(t *testing.T, tasks ...*platform.Task) *checkpointer {
+	c := &checkpointer{last: make(map[scheduler.ID]time.Time)}
+	for _, task := range tasks {
+		last, err := time.Parse(time.RFC3339, task.LatestCompleted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.last[scheduler.ID(task.ID)] = last
+	}
+	return c
+}
+
+func (c *checkpointer) Checkpoint(ctx context.Context, id scheduler.ID, t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.last[id] = t
+	return nil
+}
+
+func (c *checkpointer) Last(ctx context.Context, id scheduler.ID) (time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last[id], nil
+}
+
+// pollForRunAt waits for the executor to run the task for the given schedule time.
+func pollForRunAt(t *testing.T, e *mock.Executor, taskID platform.ID, now int64) *mock.RunPromise {
+	t.Helper()
+
+	for i := 0; i < 50; i++ {
+		for _, rp := range e.RunningFor(taskID) {
+			if rp.Run().Now == now {
+				return rp
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("did not see a run at %d for task %s in time", now, taskID)
+	return nil
+}
+
+// pollForNextAttempt waits for the executor to run another attempt of the run of the given promise.
+func pollForNextAttempt(t *testing.T, e *mock.Executor, prev *mock.RunPromise) *mock.RunPromise {
+	t.Helper()
+
+	for i := 0; i < 50; i++ {
+		for _, rp := range e.RunningFor(prev.Run().TaskID) {
+			if rp != prev && rp.Run().RunID == prev.Run().RunID {
+				return rp
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("did not see another attempt of run %s in time", prev.Run().RunID)
+	return nil
+}
+
+func TestHeapTaskScheduler_Schedule(t *testing.T) {
+	t.Parallel()
+
+	task := &platform.Task{
+		ID:              platform.ID(1),
+		Cron:            "* * * * *",
+		LatestCompleted: "1970-01-01T00:50:00Z",
+		Flux:            `option task = {name:"x", every:1m} from(bucket:"a") |> to(bucket:"b", org: "o")`,
+	}
+
+	tcs := mock.NewTaskControlService()
+	tcs.SetTask(task)
+	e := mock.NewExecutor()
+	cp := newCheckpointer(t, task)
+	s := backend.NewHeapTaskScheduler(zaptest.NewLogger(t), tcs, e, cp)
+	s.Start(context.Background())
+	defer s.Stop()
+
+	if err := s.ClaimTask(context.Background(), task); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ClaimTask(context.Background(), task); err != platform.ErrTaskAlreadyClaimed {
+		t.Fatalf("expected error claiming task twice, got %v", err)
+	}
+
+	// The missed schedule times are executed one at a time, in order, and checkpointed.
+	for _, now := range []int64{3060, 3120} {
+		pollForRunAt(t, e, task.ID, now).Finish(mock.NewRunResult(nil, false), nil)
+	}
+
+	pollForRunAt(t, e, task.ID, 3180)
+	if last, _ := cp.Last(context.Background(), scheduler.ID(task.ID)); last.Unix() < 3120 {
+		t.Fatalf("expected checkpoint after 3120, got %d", last.Unix())
+	}
+
+	// Releasing the task cancels the run in progress.
+	if err := s.ReleaseTask(task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.PollForNumberRunning(task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ReleaseTask(task.ID); err != platform.ErrTaskNotClaimed {
+		t.Fatalf("expected error releasing task twice, got %v", err)
+	}
+}
+
+func TestHeapTaskScheduler_ManualRuns(t *testing.T) {
+	t.Parallel()
+
+	task := &platform.Task{
+		ID:              platform.ID(1),
+		Cron:            "0 0 1 1 *",
+		LatestCompleted: time.Now().UTC().Format(time.RFC3339),
+		Flux:            `option task = {name:"x", cron:"0 0 1 1 *"} from(bucket:"a") |> to(bucket:"b", org: "o")`,
+	}
+
+	tcs := mock.NewTaskControlService()
+	tcs.SetTask(task)
+	e := mock.NewExecutor()
+	s := backend.NewHeapTaskScheduler(zaptest.NewLogger(t), tcs, e, newCheckpointer(t, task))
+	s.Start(context.Background())
+	defer s.Stop()
+
+	if err := s.ClaimTask(context.Background(), task); err != nil {
+		t.Fatal(err)
+	}
+
+	// Queued manual runs are executed when the task is updated, although the task is not due.
+	for _, sf := range []int64{120, 180} {
+		if _, err := tcs.ForceRun(context.Background(), task.ID, sf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.UpdateTask(context.Background(), task); err != nil {
+		t.Fatal(err)
+	}
+
+	pollForRunAt(t, e, task.ID, 120).Finish(mock.NewRunResult(nil, false), nil)
+
+	// A run in progress can be canceled.
+	rp := pollForRunAt(t, e, task.ID, 180)
+	if err := s.CancelRun(context.Background(), task.ID, rp.Run().RunID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := e.PollForNumberRunning(task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CancelRun(context.Background(), task.ID, platform.ID(1)); err != platform.ErrRunNotFound {
+		t.Fatalf("expected error canceling unknown run, got %v", err)
+	}
+}
+
+// LogListener allows us to act as a middleware and see if specific logs have been written
+type logListener struct {
+	mu sync.Mutex
+
+	backend.TaskControlService
+
+	logs map[string][]string
+}
+
+func newLogListener(tcs backend.TaskControlService) *logListener {
+	return &logListener{
+		TaskControlService: tcs,
+		logs:               make(map[string][]string),
+	}
+}
+
+func (l *logListener) AddRunLog(ctx context.Context, taskID, runID platform.ID, when time.Time, log string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	logs := l.logs[taskID.String()+runID.String()]
+	logs = append(logs, log)
+	l.logs[taskID.String()+runID.String()] = logs
+
+	return l.TaskControlService.AddRunLog(ctx, taskID, runID, when, log)
+}
+
+func pollForRunLog(t *testing.T, ll *logListener, taskID, runID platform.ID, exp string) {
+	t.Helper()
+
+	var logs []string
+
+	const maxAttempts = 50
+	for i := 0; i < maxAttempts; i++ {
+		if i != 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		ll.mu.Lock()
+		logs = ll.logs[taskID.String()+runID.String()]
+		ll.mu.Unlock()
+
+		for _, log := range logs {
+			if log == exp {
+				return
+			}
+		}
+	}
+
+	t.Logf("Didn't find message %q in logs:", exp)
+	for _, log := range logs {
+		t.Logf("\t%s", log)
+	}
+	t.FailNow()
+}
+
+func TestHeapTaskScheduler_Retry(t *testing.T) {
+	t.Parallel()
+
+	task := &platform.Task{
+		ID:              platform.ID(1),
+		Every:           "1s",
+		LatestCompleted: "1970-01-01T00:00:05Z",
+		Flux:            `option task = {name:"x", every:1s, retry: 3} from(bucket:"a") |> to(bucket:"b", org: "o")`,
+	}
+
+	tcs := mock.NewTaskControlService()
+	tcs.SetTask(task)
+	e := mock.NewExecutor()
+	ll := newLogListener(tcs)
+	s := backend.NewHeapTaskScheduler(zaptest.NewLogger(t), ll, e, newCheckpointer(t, task), backend.WithRetryBackoff(20*time.Millisecond))
+	s.Start(context.Background())
+	defer s.Stop()
+
+	reg := prom.NewRegistry()
+	reg.MustRegister(s.PrometheusCollectors()...)
+
+	if err := s.ClaimTask(context.Background(), task); err != nil {
+		t.Fatal(err)
+	}
+
+	rp := pollForRunAt(t, e, task.ID, 6)
+	runID := rp.Run().RunID
+
+	// A retryable failure is attempted again, for the same run.
+	rp.Finish(mock.NewRunResult(errors.New("storage unavailable"), true), nil)
+	pollForRunLog(t, ll, task.ID, runID, "Attempt 1 of 3 failed: Run failed to execute: storage unavailable; retrying in 20ms")
+	pollForRunLog(t, ll, task.ID, runID, "Starting attempt 2 of 3")
+
+	rp = pollForNextAttempt(t, e, rp)
+	if rp.Run().RunID != runID {
+		t.Fatalf("expected retry of run %s, got run %s", runID, rp.Run().RunID)
+	}
+
+	// A timed out attempt is retried too, and counted separately.
+	rp.Finish(nil, platform.ErrRunTimedOut)
+	pollForRunLog(t, ll, task.ID, runID, "Starting attempt 3 of 3")
+
+	pollForNextAttempt(t, e, rp).Finish(mock.NewRunResult(nil, false), nil)
+	pollForRunLog(t, ll, task.ID, runID, "Completed successfully")
+
+	mfs := promtest.MustGather(t, reg)
+	m := promtest.MustFindMetric(t, mfs, "task_scheduler_run_retries", map[string]string{"task_id": task.ID.String()})
+	if got := *m.Counter.Value; got != 2 {
+		t.Fatalf("expected 2 retries, got %v", got)
+	}
+	m = promtest.MustFindMetric(t, mfs, "task_scheduler_run_timeouts", map[string]string{"task_id": task.ID.String()})
+	if got := *m.Counter.Value; got != 1 {
+		t.Fatalf("expected 1 timeout, got %v", got)
+	}
+
+	// A failure that is not retryable fails the next run on the first attempt.
+	next := pollForRunAt(t, e, task.ID, 7)
+	next.Finish(mock.NewRunResult(errors.New("invalid script"), false), nil)
+	pollForRunLog(t, ll, task.ID, next.Run().RunID, "Run failed to execute: invalid script")
+	pollForRunLog(t, ll, task.ID, next.Run().RunID, "Failed")
+}
+
+// dependentsQueuer queues a run of every task depending on a task, when a run of that task finishes successfully,
+// like the task store does.
+type dependentsQueuer struct {
+	*mock.TaskControlService
+
+	tasks []*platform.Task
+}
+
+func (q *dependentsQueuer) FinishRun(ctx context.Context, taskID, runID platform.ID) (*platform.Run, error) {
+	r, err := q.TaskControlService.FinishRun(ctx, taskID, runID)
+	if err != nil || r.Status != backend.RunSuccess.String() {
+		return r, err
+	}
+
+	scheduledFor, err := r.ScheduledForTime()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range q.tasks {
+		if t.DependsOnTask(taskID) {
+			if _, err := q.ForceRun(ctx, t.ID, scheduledFor.Unix()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+func TestHeapTaskScheduler_Dependents(t *testing.T) {
+	t.Parallel()
+
+	upstream := &platform.Task{
+		ID:              platform.ID(1),
+		Cron:            "* * * * *",
+		LatestCompleted: "1970-01-01T00:50:00Z",
+		Flux:            `option task = {name:"raw", every:1m} from(bucket:"a") |> to(bucket:"b", org: "o")`,
+	}
+	downstream := &platform.Task{
+		ID:              platform.ID(2),
+		Cron:            "0 * * * *",
+		LatestCompleted: time.Now().UTC().Format(time.RFC3339),
+		Flux:            `option task = {name:"1h", every:1h} from(bucket:"b") |> to(bucket:"c", org: "o")`,
+		DependsOn:       []platform.ID{upstream.ID},
+	}
+
+	tcs := &dependentsQueuer{TaskControlService: mock.NewTaskControlService(), tasks: []*platform.Task{downstream}}
+	e := mock.NewExecutor()
+	s := backend.NewHeapTaskScheduler(zaptest.NewLogger(t), tcs, e, newCheckpointer(t, upstream, downstream))
+	s.Start(context.Background())
+	defer s.Stop()
+
+	for _, task := range []*platform.Task{upstream, downstream} {
+		tcs.SetTask(task)
+		if err := s.ClaimTask(context.Background(), task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A successful upstream run starts a downstream run for the same schedule time,
+	// although the downstream task is not due yet.
+	pollForRunAt(t, e, upstream.ID, 3060).Finish(mock.NewRunResult(nil, false), nil)
+
+	cs, err := tcs.PollForNumberCreated(downstream.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs[0].Now != 3060 {
+		t.Fatalf("expected downstream run at 3060, got %d", cs[0].Now)
+	}
+	pollForRunAt(t, e, downstream.ID, 3060).Finish(mock.NewRunResult(nil, false), nil)
+
+	// A failed upstream run does not start a downstream run.
+	pollForRunAt(t, e, upstream.ID, 3120).Finish(mock.NewRunResult(errors.New("invalid script"), false), nil)
+	pollForRunAt(t, e, upstream.ID, 3180)
+	time.Sleep(10 * time.Millisecond)
+	if n := tcs.TotalRunsCreatedForTask(downstream.ID); n != 1 {
+		t.Fatalf("expected 1 downstream run, got %d", n)
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want bool
+	}{
+		{err: errors.New("connection reset"), want: true},
+		{err: platform.ErrRunTimedOut, want: true},
+		{err: &platform.Error{Code: platform.EUnavailable, Msg: "unavailable"}, want: true},
+		{err: &flux.Error{Code: codes.ResourceExhausted, Msg: "queue full"}, want: true},
+		{err: platform.ErrRunCanceled, want: false},
+		{err: &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"}, want: false},
+		{err: &flux.Error{Code: codes.Invalid, Msg: "type error"}, want: false},
+	} {
+		if got := backend.IsRetryableError(tt.err); got != tt.want {
+			t.Errorf("IsRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package scheduler
+
+import "time"
+
+// Clock tells the scheduler the time, and notifies it when a time is reached.
+// It allows tests to control the passing of time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTimer returns a Timer that fires once the time at is reached.
+	NewTimer(at time.Time) Timer
+}
+
+// Timer is a single event at a time in the future, like a time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is delivered when the timer fires.
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing.
+	// It returns false if the timer already fired or was stopped.
+	Stop() bool
+}
+
+// systemClock is the Clock of the system, backed by the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(at time.Time) Timer {
+	return systemTimer{time.NewTimer(time.Until(at))}
+}
+
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.t.Stop()
+}
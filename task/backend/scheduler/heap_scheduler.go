@@ -0,0 +1,443 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	cron "gopkg.in/robfig/cron.v2"
+)
+
+const (
+	// DefaultMaxWorkers is the default number of executions a HeapScheduler runs at once.
+	DefaultMaxWorkers = 100
+
+	// DefaultRetryInterval is the default time a HeapScheduler waits
+	// before retrying an execution the Executor failed to start.
+	DefaultRetryInterval = time.Second
+)
+
+// ErrNotScheduled is returned when releasing an id that is not scheduled.
+var ErrNotScheduled = &influxdb.Error{
+	Code: influxdb.ENotFound,
+	Msg:  "schedulable is not scheduled",
+}
+
+// ErrorFn is called with the errors of executing or checkpointing id at scheduledAt.
+type ErrorFn func(ctx context.Context, id ID, scheduledAt time.Time, err error)
+
+// Option configures a HeapScheduler.
+type Option func(*HeapScheduler)
+
+// WithClock sets the clock of the scheduler, used in tests to control time.
+func WithClock(c Clock) Option {
+	return func(s *HeapScheduler) {
+		s.clock = c
+	}
+}
+
+// WithMaxWorkers sets the number of executions the scheduler runs at once.
+func WithMaxWorkers(n int) Option {
+	return func(s *HeapScheduler) {
+		if n > 0 {
+			s.maxWorkers = n
+		}
+	}
+}
+
+// WithMaxJitter delays executions by a random duration up to d,
+// so that Schedulables with the same schedule do not all execute at the same instant.
+func WithMaxJitter(d time.Duration) Option {
+	return func(s *HeapScheduler) {
+		if d >= 0 {
+			s.maxJitter = d
+		}
+	}
+}
+
+// WithRetryInterval sets the time to wait before retrying an execution the Executor failed to start.
+func WithRetryInterval(d time.Duration) Option {
+	return func(s *HeapScheduler) {
+		if d > 0 {
+			s.retryInterval = d
+		}
+	}
+}
+
+// WithOnErrorFn sets the function called with execution and checkpoint errors.
+func WithOnErrorFn(fn ErrorFn) Option {
+	return func(s *HeapScheduler) {
+		s.onErr = fn
+	}
+}
+
+// item is a scheduled Schedulable.
+type item struct {
+	id       ID
+	schedule Schedule
+	cron     cron.Schedule
+
+	// last is the schedule time of the last completed execution.
+	last time.Time
+	// scheduledAt is the schedule time of the next execution.
+	scheduledAt time.Time
+	// due is when the next execution starts; scheduledAt plus offset and jitter.
+	due time.Time
+
+	// index is the position of the item in the heap, or -1 when it is not in the heap.
+	index    int
+	running  bool
+	released bool
+}
+
+// itemHeap orders items by due time, then by id.
+type itemHeap []*item
+
+func (h itemHeap) Len() int { return len(h) }
+
+func (h itemHeap) Less(i, j int) bool {
+	if h[i].due.Equal(h[j].due) {
+		return h[i].id < h[j].id
+	}
+	return h[i].due.Before(h[j].due)
+}
+
+func (h itemHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *itemHeap) Push(x interface{}) {
+	it := x.(*item)
+	it.index = len(*h)
+	*h = append(*h, it)
+}
+
+func (h *itemHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	it := old[n-1]
+	old[n-1] = nil
+	it.index = -1
+	*h = old[:n-1]
+	return it
+}
+
+// execution is a single execution handed to a worker.
+type execution struct {
+	item        *item
+	scheduledAt time.Time
+}
+
+// HeapScheduler is a Scheduler that keeps its Schedulables in a heap ordered by the time of their next execution.
+// A single goroutine dispatches due executions to a fixed pool of workers.
+//
+// Each Schedulable has at most one execution in progress.
+// After an execution completes, its schedule time is checkpointed and the next one is scheduled.
+// When the next schedule time has already passed, e.g. after downtime, every missed schedule time
+// is executed in order, without jitter, until the Schedulable has caught up.
+type HeapScheduler struct {
+	executor     Executor
+	checkpointer Checkpointer
+
+	clock         Clock
+	maxWorkers    int
+	maxJitter     time.Duration
+	retryInterval time.Duration
+	onErr         ErrorFn
+
+	mu    sync.Mutex
+	rand  *rand.Rand
+	items map[ID]*item
+	heap  itemHeap
+	idle  int
+
+	wake chan struct{}
+	work chan execution
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ Scheduler = (*HeapScheduler)(nil)
+
+// NewScheduler returns a HeapScheduler executing with executor and checkpointing with checkpointer.
+// The scheduler does nothing until Start is called.
+func NewScheduler(executor Executor, checkpointer Checkpointer, opts ...Option) *HeapScheduler {
+	s := &HeapScheduler{
+		executor:      executor,
+		checkpointer:  checkpointer,
+		clock:         systemClock{},
+		maxWorkers:    DefaultMaxWorkers,
+		retryInterval: DefaultRetryInterval,
+		onErr:         func(context.Context, ID, time.Time, error) {},
+		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		items:         make(map[ID]*item),
+		wake:          make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.idle = s.maxWorkers
+	s.work = make(chan execution, s.maxWorkers)
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	return s
+}
+
+// Start begins dispatching executions until ctx is done or Stop is called.
+func (s *HeapScheduler) Start(ctx context.Context) {
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.cancel()
+		case <-s.ctx.Done():
+		}
+	}()
+
+	s.wg.Add(s.maxWorkers + 1)
+	go s.dispatch()
+	for i := 0; i < s.maxWorkers; i++ {
+		go s.worker()
+	}
+}
+
+// Stop stops dispatching executions and waits for the workers to return.
+// Executions in progress are not canceled, but they are no longer waited on or checkpointed.
+func (s *HeapScheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Now returns the current time of the scheduler's clock.
+func (s *HeapScheduler) Now() time.Time {
+	return s.clock.Now()
+}
+
+// Schedule begins the scheduling of sch after its last checkpoint,
+// or updates its schedule if it is already scheduled.
+// A schedulable without a checkpoint is scheduled from now.
+func (s *HeapScheduler) Schedule(sch Schedulable) error {
+	schedule := sch.Schedule()
+	c, err := cron.Parse(schedule.Schedule)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid schedule",
+			Err:  err,
+		}
+	}
+
+	id := sch.ID()
+
+	s.mu.Lock()
+	it, ok := s.items[id]
+	s.mu.Unlock()
+
+	var last time.Time
+	if !ok {
+		last, err = s.checkpointer.Last(s.ctx, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The item may have been scheduled while looking up its checkpoint.
+	if it, ok = s.items[id]; !ok {
+		if last.IsZero() {
+			last = s.clock.Now()
+		}
+		it = &item{id: id, last: last, index: -1}
+		s.items[id] = it
+	}
+	it.schedule = schedule
+	it.cron = c
+
+	// An item with an execution in progress is rescheduled with its new schedule when the execution completes.
+	if !it.running {
+		s.next(it)
+	}
+	return nil
+}
+
+// Release stops scheduling the Schedulable with the given id.
+// An execution already in progress is left to complete, but it is not checkpointed.
+func (s *HeapScheduler) Release(id ID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.items[id]
+	if !ok {
+		return ErrNotScheduled
+	}
+	it.released = true
+	if it.index >= 0 {
+		heap.Remove(&s.heap, it.index)
+	}
+	delete(s.items, id)
+	return nil
+}
+
+// next places it in the heap at its next schedule time after it.last.
+// s.mu must be held.
+func (s *HeapScheduler) next(it *item) {
+	it.scheduledAt = it.cron.Next(it.last)
+	if it.scheduledAt.IsZero() {
+		// The schedule never fires again.
+		if it.index >= 0 {
+			heap.Remove(&s.heap, it.index)
+		}
+		return
+	}
+
+	it.due = it.scheduledAt.Add(it.schedule.Offset)
+	if s.maxJitter > 0 && it.due.After(s.clock.Now()) {
+		it.due = it.due.Add(time.Duration(s.rand.Int63n(int64(s.maxJitter))))
+	}
+	s.push(it)
+}
+
+// retry places it in the heap to execute its current schedule time again after the retry interval.
+// s.mu must be held.
+func (s *HeapScheduler) retry(it *item) {
+	it.due = s.clock.Now().Add(s.retryInterval)
+	s.push(it)
+}
+
+// push adds it to the heap, or fixes its position if it is already in the heap.
+// s.mu must be held.
+func (s *HeapScheduler) push(it *item) {
+	if it.index >= 0 {
+		heap.Fix(&s.heap, it.index)
+	} else {
+		heap.Push(&s.heap, it)
+	}
+	s.notify()
+}
+
+// notify wakes the dispatcher up to look at the heap again.
+func (s *HeapScheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch hands due executions to the workers, while there are idle workers.
+func (s *HeapScheduler) dispatch() {
+	defer s.wg.Done()
+
+	for {
+		s.mu.Lock()
+		now := s.clock.Now()
+		for s.idle > 0 && len(s.heap) > 0 && !s.heap[0].due.After(now) {
+			it := heap.Pop(&s.heap).(*item)
+			it.running = true
+			s.idle--
+			s.work <- execution{item: it, scheduledAt: it.scheduledAt}
+		}
+
+		var timer Timer
+		var timerC <-chan time.Time
+		if s.idle > 0 && len(s.heap) > 0 {
+			timer = s.clock.NewTimer(s.heap[0].due)
+			timerC = timer.C()
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-s.ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-timerC:
+		case <-s.wake:
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+	}
+}
+
+func (s *HeapScheduler) worker() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case e := <-s.work:
+			if !s.execute(e) {
+				return
+			}
+		}
+	}
+}
+
+// execute runs e, and schedules the next execution of its item.
+// It returns false if the scheduler stopped while waiting on the execution.
+func (s *HeapScheduler) execute(e execution) bool {
+	id := e.item.id
+
+	p, err := s.executor.Execute(s.ctx, id, e.scheduledAt)
+	if err != nil {
+		s.onErr(s.ctx, id, e.scheduledAt, err)
+		s.finish(e.item, false)
+		return true
+	}
+
+	select {
+	case <-p.Done():
+	case <-s.ctx.Done():
+		return false
+	}
+
+	if err := p.Error(); err != nil {
+		// The execution ran and failed; it is not retried.
+		s.onErr(s.ctx, id, e.scheduledAt, err)
+	}
+
+	s.mu.Lock()
+	released := e.item.released
+	s.mu.Unlock()
+
+	if !released {
+		if err := s.checkpointer.Checkpoint(s.ctx, id, e.scheduledAt); err != nil {
+			s.onErr(s.ctx, id, e.scheduledAt, err)
+		}
+	}
+
+	s.finish(e.item, true)
+	return true
+}
+
+// finish frees the worker of it, and schedules its next execution unless it was released.
+// If completed is false, the same schedule time is retried.
+func (s *HeapScheduler) finish(it *item, completed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.idle++
+	it.running = false
+	if it.released {
+		s.notify()
+		return
+	}
+
+	if completed {
+		it.last = it.scheduledAt
+		s.next(it)
+	} else {
+		s.retry(it)
+	}
+	s.notify()
+}
@@ -0,0 +1,419 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/task/backend/scheduler"
+)
+
+// mockClock is a Clock whose time only moves when set.
+type mockClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*mockTimer
+}
+
+func newMockClock(now time.Time) *mockClock {
+	return &mockClock{now: now}
+}
+
+func (c *mockClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *mockClock) NewTimer(at time.Time) scheduler.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &mockTimer{at: at, c: make(chan time.Time, 1)}
+	if !at.After(c.now) {
+		t.fire(c.now)
+		return t
+	}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Set moves the clock to now, firing the timers that are due.
+func (c *mockClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+	timers := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(now) {
+			timers = append(timers, t)
+			continue
+		}
+		t.fire(now)
+	}
+	c.timers = timers
+}
+
+type mockTimer struct {
+	mu      sync.Mutex
+	at      time.Time
+	c       chan time.Time
+	stopped bool
+}
+
+func (t *mockTimer) fire(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.stopped {
+		t.stopped = true
+		t.c <- now
+	}
+}
+
+func (t *mockTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *mockTimer) Stop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stopped := t.stopped
+	t.stopped = true
+	return !stopped
+}
+
+type mockSchedulable struct {
+	id       scheduler.ID
+	schedule scheduler.Schedule
+}
+
+func (s mockSchedulable) ID() scheduler.ID {
+	return s.id
+}
+
+func (s mockSchedulable) Schedule() scheduler.Schedule {
+	return s.schedule
+}
+
+type executed struct {
+	id          scheduler.ID
+	scheduledAt time.Time
+}
+
+// mockExecutor reports every execution, and completes them when told to.
+type mockExecutor struct {
+	executed chan executed
+
+	mu       sync.Mutex
+	hold     bool
+	promises []*mockPromise
+	errs     []error
+}
+
+func newMockExecutor() *mockExecutor {
+	return &mockExecutor{executed: make(chan executed, 100)}
+}
+
+func (e *mockExecutor) Execute(ctx context.Context, id scheduler.ID, scheduledAt time.Time) (scheduler.Promise, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.errs) > 0 {
+		err := e.errs[0]
+		e.errs = e.errs[1:]
+		return nil, err
+	}
+
+	p := &mockPromise{id: id, done: make(chan struct{})}
+	if e.hold {
+		e.promises = append(e.promises, p)
+	} else {
+		close(p.done)
+	}
+	e.executed <- executed{id: id, scheduledAt: scheduledAt}
+	return p, nil
+}
+
+func (e *mockExecutor) Cancel(ctx context.Context, promiseID scheduler.ID) error {
+	return nil
+}
+
+// release completes every held execution.
+func (e *mockExecutor) release() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, p := range e.promises {
+		close(p.done)
+	}
+	e.promises = nil
+}
+
+type mockPromise struct {
+	id   scheduler.ID
+	done chan struct{}
+}
+
+func (p *mockPromise) ID() scheduler.ID           { return p.id }
+func (p *mockPromise) Cancel(ctx context.Context) {}
+func (p *mockPromise) Done() <-chan struct{}      { return p.done }
+func (p *mockPromise) Error() error               { <-p.done; return nil }
+
+type mockCheckpointer struct {
+	mu          sync.Mutex
+	checkpoints map[scheduler.ID]time.Time
+}
+
+func newMockCheckpointer() *mockCheckpointer {
+	return &mockCheckpointer{checkpoints: make(map[scheduler.ID]time.Time)}
+}
+
+func (c *mockCheckpointer) Checkpoint(ctx context.Context, id scheduler.ID, t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checkpoints[id] = t
+	return nil
+}
+
+func (c *mockCheckpointer) Last(ctx context.Context, id scheduler.ID) (time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.checkpoints[id], nil
+}
+
+func expectExecuted(t *testing.T, e *mockExecutor, want ...executed) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case got := <-e.executed:
+			if got.id != w.id || !got.scheduledAt.Equal(w.scheduledAt) {
+				t.Fatalf("expected execution of %d at %v, got %d at %v", w.id, w.scheduledAt, got.id, got.scheduledAt)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for execution of %d at %v", w.id, w.scheduledAt)
+		}
+	}
+}
+
+func expectNotExecuted(t *testing.T, e *mockExecutor) {
+	t.Helper()
+	select {
+	case got := <-e.executed:
+		t.Fatalf("unexpected execution of %d at %v", got.id, got.scheduledAt)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+var start = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func everyMinute(id scheduler.ID) mockSchedulable {
+	return mockSchedulable{id: id, schedule: scheduler.Schedule{Schedule: "@every 1m"}}
+}
+
+func newScheduler(t *testing.T, e *mockExecutor, c *mockCheckpointer, clock *mockClock, opts ...scheduler.Option) *scheduler.HeapScheduler {
+	t.Helper()
+	s := scheduler.NewScheduler(e, c, append([]scheduler.Option{scheduler.WithClock(clock)}, opts...)...)
+	s.Start(context.Background())
+	return s
+}
+
+func TestHeapScheduler_Order(t *testing.T) {
+	clock := newMockClock(start)
+	e := newMockExecutor()
+	// A single worker executes in the order of the heap.
+	s := newScheduler(t, e, newMockCheckpointer(), clock, scheduler.WithMaxWorkers(1))
+	defer s.Stop()
+
+	for _, sch := range []mockSchedulable{
+		{id: 1, schedule: scheduler.Schedule{Schedule: "@every 3m"}},
+		{id: 2, schedule: scheduler.Schedule{Schedule: "@every 2m"}},
+		{id: 3, schedule: scheduler.Schedule{Schedule: "@every 2m", Offset: 30 * time.Second}},
+	} {
+		if err := s.Schedule(sch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expectNotExecuted(t, e)
+
+	clock.Set(start.Add(2 * time.Minute))
+	expectExecuted(t, e, executed{2, start.Add(2 * time.Minute)})
+	expectNotExecuted(t, e)
+
+	clock.Set(start.Add(3 * time.Minute))
+	expectExecuted(t, e,
+		executed{3, start.Add(2 * time.Minute)},
+		executed{1, start.Add(3 * time.Minute)},
+	)
+	expectNotExecuted(t, e)
+}
+
+func TestHeapScheduler_CatchUp(t *testing.T) {
+	clock := newMockClock(start.Add(3*time.Minute + 30*time.Second))
+	e := newMockExecutor()
+	c := newMockCheckpointer()
+	if err := c.Checkpoint(context.Background(), 1, start); err != nil {
+		t.Fatal(err)
+	}
+	s := newScheduler(t, e, c, clock, scheduler.WithMaxJitter(time.Minute))
+	defer s.Stop()
+
+	if err := s.Schedule(everyMinute(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every schedule time missed since the checkpoint runs once, in order, without jitter.
+	expectExecuted(t, e,
+		executed{1, start.Add(time.Minute)},
+		executed{1, start.Add(2 * time.Minute)},
+		executed{1, start.Add(3 * time.Minute)},
+	)
+	expectNotExecuted(t, e)
+
+	last, err := c.Last(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !last.Equal(start.Add(3 * time.Minute)) {
+		t.Fatalf("expected checkpoint at %v, got %v", start.Add(3*time.Minute), last)
+	}
+}
+
+func TestHeapScheduler_MaxWorkers(t *testing.T) {
+	clock := newMockClock(start)
+	e := newMockExecutor()
+	e.hold = true
+	s := newScheduler(t, e, newMockCheckpointer(), clock, scheduler.WithMaxWorkers(2))
+	defer s.Stop()
+
+	for id := scheduler.ID(1); id <= 3; id++ {
+		if err := s.Schedule(everyMinute(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Two workers execute concurrently, so either may report first.
+	clock.Set(start.Add(time.Minute))
+	seen := make(map[scheduler.ID]bool)
+	for i := 0; i < 2; i++ {
+		select {
+		case got := <-e.executed:
+			seen[got.id] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for executions")
+		}
+	}
+	if !seen[1] || !seen[2] {
+		t.Fatalf("expected executions of 1 and 2 first, got %v", seen)
+	}
+	expectNotExecuted(t, e)
+
+	e.release()
+	expectExecuted(t, e, executed{3, start.Add(time.Minute)})
+}
+
+func TestHeapScheduler_Release(t *testing.T) {
+	clock := newMockClock(start)
+	e := newMockExecutor()
+	s := newScheduler(t, e, newMockCheckpointer(), clock)
+	defer s.Stop()
+
+	if err := s.Schedule(everyMinute(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Schedule(everyMinute(2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Release(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Release(1); err != scheduler.ErrNotScheduled {
+		t.Fatalf("expected ErrNotScheduled releasing twice, got %v", err)
+	}
+
+	clock.Set(start.Add(time.Minute))
+	expectExecuted(t, e, executed{2, start.Add(time.Minute)})
+	expectNotExecuted(t, e)
+}
+
+func TestHeapScheduler_Update(t *testing.T) {
+	clock := newMockClock(start)
+	e := newMockExecutor()
+	s := newScheduler(t, e, newMockCheckpointer(), clock)
+	defer s.Stop()
+
+	if err := s.Schedule(everyMinute(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Schedule(mockSchedulable{id: 1, schedule: scheduler.Schedule{Schedule: "@every 5m"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Set(start.Add(time.Minute))
+	expectNotExecuted(t, e)
+
+	clock.Set(start.Add(5 * time.Minute))
+	expectExecuted(t, e, executed{1, start.Add(5 * time.Minute)})
+
+	if err := s.Schedule(mockSchedulable{id: 1, schedule: scheduler.Schedule{Schedule: "invalid"}}); err == nil {
+		t.Fatal("expected error scheduling an invalid schedule")
+	}
+}
+
+func TestHeapScheduler_Retry(t *testing.T) {
+	clock := newMockClock(start)
+	e := newMockExecutor()
+	e.errs = []error{errors.New("executor unavailable")}
+
+	var mu sync.Mutex
+	var errs []error
+	onErr := func(_ context.Context, _ scheduler.ID, _ time.Time, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}
+
+	s := newScheduler(t, e, newMockCheckpointer(), clock,
+		scheduler.WithRetryInterval(10*time.Second),
+		scheduler.WithOnErrorFn(onErr),
+	)
+	defer s.Stop()
+
+	if err := s.Schedule(everyMinute(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Set(start.Add(time.Minute))
+	expectNotExecuted(t, e)
+
+	mu.Lock()
+	if len(errs) != 1 {
+		t.Fatalf("expected one execution error, got %v", errs)
+	}
+	mu.Unlock()
+
+	// The failed schedule time is retried after the retry interval.
+	clock.Set(start.Add(time.Minute + 10*time.Second))
+	expectExecuted(t, e, executed{1, start.Add(time.Minute)})
+}
+
+func TestHeapScheduler_Jitter(t *testing.T) {
+	const maxJitter = 10 * time.Second
+
+	clock := newMockClock(start)
+	e := newMockExecutor()
+	s := newScheduler(t, e, newMockCheckpointer(), clock, scheduler.WithMaxJitter(maxJitter))
+	defer s.Stop()
+
+	if err := s.Schedule(everyMinute(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Set(start.Add(time.Minute - time.Nanosecond))
+	expectNotExecuted(t, e)
+
+	clock.Set(start.Add(time.Minute + maxJitter))
+	expectExecuted(t, e, executed{1, start.Add(time.Minute)})
+}
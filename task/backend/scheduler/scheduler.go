@@ -84,24 +84,13 @@ func (s Schedule) Next(checkpoint time.Time) (time.Time, error) {
 	return nextScheduled.Add(s.Offset), nil
 }
 
-// Scheduler is a example interface of a Scheduler.
-// // todo(lh): remove this once we start building the actual scheduler
-// type Scheduler interface {
-// 	// Start allows the scheduler to Tick. A scheduler without start will do nothing
-// 	Start(ctx context.Context)
-
-// 	// Stop a scheduler from ticking.
-// 	Stop()
-
-// 	Now() time.Time
-
-// 	// ClaimTask begins control of task execution in this scheduler.
-// 	ClaimTask(authCtx context.Context, task Schedulable) error
-
-// 	// UpdateTask will update the concurrency and the runners for a task
-// 	UpdateTask(authCtx context.Context, task Schedulable) error
-
-// 	// ReleaseTask immediately cancels any in-progress runs for the given task ID,
-// 	// and releases any resources related to management of that task.
-// 	ReleaseTask(taskID ID) error
-// }
+// Scheduler executes Schedulables on their schedule.
+type Scheduler interface {
+	// Schedule begins the scheduling of sch, or updates its schedule if it is already scheduled.
+	// Execution resumes after the last checkpoint of sch.
+	Schedule(sch Schedulable) error
+
+	// Release stops scheduling the Schedulable with the given id.
+	// An execution already in progress is left to complete.
+	Release(id ID) error
+}
@@ -16,6 +16,9 @@ type schedulerMetrics struct {
 	runsComplete *prometheus.CounterVec
 	runsActive   *prometheus.GaugeVec
 
+	runRetries  *prometheus.CounterVec
+	runTimeouts *prometheus.CounterVec
+
 	claimsComplete *prometheus.CounterVec
 	claimsActive   prometheus.Gauge
 
@@ -53,6 +56,19 @@ func newSchedulerMetrics() *schedulerMetrics {
 			Help:      "Total number of runs that have started but not yet completed, split out by task ID.",
 		}, []string{"task_id"}),
 
+		runRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "run_retries",
+			Help:      "Number of times a failed run was attempted again, split out by task ID.",
+		}, []string{"task_id"}),
+		runTimeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "run_timeouts",
+			Help:      "Number of run attempts canceled for exceeding the task timeout, split out by task ID.",
+		}, []string{"task_id"}),
+
 		claimsComplete: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace: namespace,
 			Subsystem: subsystem,
@@ -82,6 +98,8 @@ func (sm *schedulerMetrics) PrometheusCollectors() []prometheus.Collector {
 		sm.totalRunsActive,
 		sm.runsComplete,
 		sm.runsActive,
+		sm.runRetries,
+		sm.runTimeouts,
 		sm.claimsComplete,
 		sm.claimsActive,
 		sm.queueDelta,
@@ -107,6 +125,16 @@ func (sm *schedulerMetrics) FinishRun(tid string, succeeded bool) {
 	sm.runsComplete.WithLabelValues(tid, status).Inc()
 }
 
+// RetryRun records that a failed run of the given task ID is attempted again.
+func (sm *schedulerMetrics) RetryRun(tid string) {
+	sm.runRetries.WithLabelValues(tid).Inc()
+}
+
+// TimeoutRun records that an attempt of a run of the given task ID timed out.
+func (sm *schedulerMetrics) TimeoutRun(tid string) {
+	sm.runTimeouts.WithLabelValues(tid).Inc()
+}
+
 // ClaimTask adjusts the metrics to indicate the result of an attempted claim.
 func (sm *schedulerMetrics) ClaimTask(succeeded bool) {
 	status := statusString(succeeded)
@@ -124,6 +152,8 @@ func (sm *schedulerMetrics) ReleaseTask(tid string) {
 	sm.runsActive.DeleteLabelValues(tid)
 	sm.runsComplete.DeleteLabelValues(tid, statusString(true))
 	sm.runsComplete.DeleteLabelValues(tid, statusString(false))
+	sm.runRetries.DeleteLabelValues(tid)
+	sm.runTimeouts.DeleteLabelValues(tid)
 }
 
 func statusString(succeeded bool) string {
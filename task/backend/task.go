@@ -30,6 +30,10 @@ type TaskControlService interface {
 	CurrentlyRunning(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Run, error)
 	ManualRuns(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Run, error)
 
+	// ForceRun queues a manual run with unix timestamp scheduledFor, to be created by CreateNextRun
+	// ahead of the task's scheduled runs.
+	ForceRun(ctx context.Context, taskID influxdb.ID, scheduledFor int64) (*influxdb.Run, error)
+
 	// StartManualRun pulls a manual run from the list and moves it to currently running.
 	StartManualRun(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error)
 
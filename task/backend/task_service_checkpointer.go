@@ -3,11 +3,16 @@ package backend
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend/scheduler"
+	"github.com/influxdata/influxdb/task/options"
 )
 
+var _ scheduler.Checkpointer = (*TaskServiceCheckpointer)(nil)
+
 // Checkpointer allows us to restart a service from the last time we executed.
 type TaskServiceCheckpointer struct {
 	ts influxdb.TaskService
@@ -20,9 +25,9 @@ func NewTaskServiceCheckpointer(ts influxdb.TaskService) *TaskServiceCheckpointe
 }
 
 // Checkpoint updates a task's LatestCompleted value with the given time
-func (c *TaskServiceCheckpointer) Checkpoint(ctx context.Context, id influxdb.ID, t time.Time) error {
+func (c *TaskServiceCheckpointer) Checkpoint(ctx context.Context, id scheduler.ID, t time.Time) error {
 	s := t.Format(time.RFC3339Nano)
-	_, err := c.ts.UpdateTask(ctx, id, influxdb.TaskUpdate{
+	_, err := c.ts.UpdateTask(ctx, influxdb.ID(id), influxdb.TaskUpdate{
 		LatestCompleted: &s,
 	})
 
@@ -32,9 +37,11 @@ func (c *TaskServiceCheckpointer) Checkpoint(ctx context.Context, id influxdb.ID
 	return nil
 }
 
-// Last retrieves a task by its ID and returns its LatestCompleted value
-func (c *TaskServiceCheckpointer) Last(ctx context.Context, id influxdb.ID) (time.Time, error) {
-	task, err := c.ts.FindTaskByID(ctx, id)
+// Last retrieves a task by its ID and returns its LatestCompleted value.
+// For a task with an every option, it is truncated to a multiple of the every duration,
+// so that the runs are aligned to the hour or minute as they are by CreateNextRun.
+func (c *TaskServiceCheckpointer) Last(ctx context.Context, id scheduler.ID) (time.Time, error) {
+	task, err := c.ts.FindTaskByID(ctx, influxdb.ID(id))
 	if err != nil {
 		return time.Time{}, fmt.Errorf("could not fetch task: %v", err)
 	}
@@ -43,5 +50,18 @@ func (c *TaskServiceCheckpointer) Last(ctx context.Context, id influxdb.ID) (tim
 	if err != nil {
 		return time.Time{}, fmt.Errorf("internal server error: corrupt LastCompleted format: %v", err)
 	}
+
+	if strings.HasPrefix(task.EffectiveCron(), "@every ") {
+		var every options.Duration
+		if err := every.Parse(strings.TrimPrefix(task.EffectiveCron(), "@every ")); err != nil {
+			// We cannot align a invalid time
+			return last, nil
+		}
+		d, err := every.DurationFrom(last)
+		if err != nil {
+			return last, nil
+		}
+		last = last.Truncate(d).Truncate(time.Second)
+	}
 	return last, nil
 }
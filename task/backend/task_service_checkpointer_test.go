@@ -9,6 +9,7 @@ import (
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/mock"
 	"github.com/influxdata/influxdb/task/backend"
+	"github.com/influxdata/influxdb/task/backend/scheduler"
 )
 
 func TestCheckpoint(t *testing.T) {
@@ -17,7 +18,7 @@ func TestCheckpoint(t *testing.T) {
 	}
 
 	cp := backend.NewTaskServiceCheckpointer(mockService)
-	err := cp.Checkpoint(context.Background(), influxdb.ID(1), time.Now())
+	err := cp.Checkpoint(context.Background(), scheduler.ID(1), time.Now())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -31,7 +32,7 @@ func TestCheckpointError(t *testing.T) {
 	}
 
 	cp := backend.NewTaskServiceCheckpointer(mockService)
-	err := cp.Checkpoint(context.Background(), influxdb.ID(1), time.Now())
+	err := cp.Checkpoint(context.Background(), scheduler.ID(1), time.Now())
 	if err == nil {
 		t.Fatalf("Expected error")
 	}
@@ -47,7 +48,7 @@ func TestLast(t *testing.T) {
 	}
 
 	cp := backend.NewTaskServiceCheckpointer(mockService)
-	got, err := cp.Last(context.Background(), influxdb.ID(1))
+	got, err := cp.Last(context.Background(), scheduler.ID(1))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -56,6 +57,23 @@ func TestLast(t *testing.T) {
 	}
 }
 
+func TestLastEvery(t *testing.T) {
+	mockService := &mock.TaskService{
+		FindTaskByIDFn: func(context.Context, influxdb.ID) (*influxdb.Task, error) {
+			return &influxdb.Task{Every: "1h", LatestCompleted: "2019-06-01T10:17:23.5Z"}, nil
+		},
+	}
+
+	cp := backend.NewTaskServiceCheckpointer(mockService)
+	got, err := cp.Last(context.Background(), scheduler.ID(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("wrong time, wanted: %v, got: %v", want, got)
+	}
+}
+
 func TestLastFetchError(t *testing.T) {
 	mockService := &mock.TaskService{
 		FindTaskByIDFn: func(context.Context, influxdb.ID) (*influxdb.Task, error) {
@@ -64,7 +82,7 @@ func TestLastFetchError(t *testing.T) {
 	}
 
 	cp := backend.NewTaskServiceCheckpointer(mockService)
-	_, err := cp.Last(context.Background(), influxdb.ID(1))
+	_, err := cp.Last(context.Background(), scheduler.ID(1))
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -78,7 +96,7 @@ func TestLastParseError(t *testing.T) {
 	}
 
 	cp := backend.NewTaskServiceCheckpointer(mockService)
-	_, err := cp.Last(context.Background(), influxdb.ID(1))
+	_, err := cp.Last(context.Background(), scheduler.ID(1))
 	if err == nil {
 		t.Fatalf("expected error parsing invalid time: %v", err)
 	}
@@ -174,9 +174,12 @@ func (t *TaskControlService) CreateRun(_ context.Context, taskID influxdb.ID, sc
 	}
 	runs[runID] = &influxdb.Run{
 		ID:           runID,
+		TaskID:       taskID,
 		ScheduledFor: scheduledFor.Format(time.RFC3339),
 	}
 	t.runs[taskID] = runs
+	t.created[taskID.String()+runID.String()] = backend.QueuedRun{TaskID: taskID, RunID: runID, Now: scheduledFor.Unix()}
+	t.totalRunsCreated[taskID]++
 	return runs[runID], nil
 }
 
@@ -186,14 +189,26 @@ func (t *TaskControlService) StartManualRun(_ context.Context, taskID, runID inf
 
 	var run *influxdb.Run
 	for i, r := range t.manualRuns {
-		if r.ID == runID {
+		if r.ID == runID && r.TaskID == taskID {
 			run = r
 			t.manualRuns = append(t.manualRuns[:i], t.manualRuns[i+1:]...)
+			break
 		}
 	}
 	if run == nil {
 		return nil, influxdb.ErrRunNotFound
 	}
+
+	runs, ok := t.runs[taskID]
+	if !ok {
+		runs = make(map[influxdb.ID]*influxdb.Run)
+	}
+	runs[run.ID] = run
+	t.runs[taskID] = runs
+	if now, err := time.Parse(time.RFC3339, run.ScheduledFor); err == nil {
+		t.created[taskID.String()+run.ID.String()] = backend.QueuedRun{TaskID: taskID, RunID: run.ID, Now: now.Unix()}
+	}
+	t.totalRunsCreated[taskID]++
 	return run, nil
 }
 
@@ -239,10 +254,32 @@ func (t *TaskControlService) ManualRuns(ctx context.Context, taskID influxdb.ID)
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	if t.manualRuns != nil {
-		return t.manualRuns, nil
+	rtn := []*influxdb.Run{}
+	for _, r := range t.manualRuns {
+		if r.TaskID == taskID {
+			rtn = append(rtn, r)
+		}
 	}
-	return []*influxdb.Run{}, nil
+	return rtn, nil
+}
+
+func (t *TaskControlService) ForceRun(_ context.Context, taskID influxdb.ID, scheduledFor int64) (*influxdb.Run, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r := &influxdb.Run{
+		ID:           idgen.ID(),
+		TaskID:       taskID,
+		Status:       backend.RunScheduled.String(),
+		ScheduledFor: time.Unix(scheduledFor, 0).UTC().Format(time.RFC3339),
+	}
+	for _, run := range t.manualRuns {
+		if run.TaskID == taskID && run.ScheduledFor == r.ScheduledFor {
+			return nil, influxdb.ErrTaskRunAlreadyQueued
+		}
+	}
+	t.manualRuns = append(t.manualRuns, r)
+	return r, nil
 }
 
 // NextDueRun returns the Unix timestamp of when the next call to CreateNextRun will be ready.
@@ -38,7 +38,12 @@ type Options struct {
 
 	Concurrency *int64 `json:"concurrency,omitempty"`
 
+	// Retry is the number of times a run is attempted before it fails.
 	Retry *int64 `json:"retry,omitempty"`
+
+	// Timeout is how long a single attempt of a run may execute before it is canceled.
+	// this can be unmarshaled from json as a string i.e.: "1d" will unmarshal as 1 day
+	Timeout *Duration `json:"timeout,omitempty"`
 }
 
 // Duration is a time span that supports the same units as the flux parser's time duration, as well as negative length time spans.
@@ -128,6 +133,7 @@ func (o *Options) Clear() {
 	o.Offset = nil
 	o.Concurrency = nil
 	o.Retry = nil
+	o.Timeout = nil
 }
 
 // IsZero tells us if the options has been zeroed out.
@@ -137,7 +143,8 @@ func (o *Options) IsZero() bool {
 		o.Every.IsZero() &&
 		o.Offset == nil &&
 		o.Concurrency == nil &&
-		o.Retry == nil
+		o.Retry == nil &&
+		o.Timeout == nil
 }
 
 // All the task option names we accept.
@@ -148,6 +155,7 @@ const (
 	optOffset      = "offset"
 	optConcurrency = "concurrency"
 	optRetry       = "retry"
+	optTimeout     = "timeout"
 )
 
 // contains is a helper function to see if an array of strings contains a string
@@ -161,7 +169,7 @@ func contains(s []string, e string) bool {
 }
 
 func grabTaskOptionAST(p *ast.Package, keys ...string) map[string]ast.Expression {
-	res := make(map[string]ast.Expression, 2) // we preallocate two keys for the map, as that is how many we will use at maximum (offset and every)
+	res := make(map[string]ast.Expression, 3) // we preallocate three keys for the map, as that is how many we will use at maximum (every, offset and timeout)
 	for i := range p.Files {
 		for j := range p.Files[i].Body {
 			if p.Files[i].Body[j].Type() != "OptionStatement" {
@@ -202,7 +210,7 @@ func FromScript(script string) (Options, error) {
 	if err != nil {
 		return opt, err
 	}
-	durTypes := grabTaskOptionAST(fluxAST, optEvery, optOffset)
+	durTypes := grabTaskOptionAST(fluxAST, optEvery, optOffset, optTimeout)
 	_, scope, err := flux.EvalAST(fluxAST)
 	if err != nil {
 		return opt, err
@@ -301,6 +309,23 @@ func FromScript(script string) (Options, error) {
 		opt.Retry = pointer.Int64(retryVal.Int())
 	}
 
+	if timeoutVal, ok := optObject.Get(optTimeout); ok {
+		if err := checkNature(timeoutVal.PolyType().Nature(), semantic.Duration); err != nil {
+			return opt, err
+		}
+		dur, ok := durTypes["timeout"]
+		if !ok || dur == nil {
+			return opt, ErrParseTaskOptionField("timeout")
+		}
+		durNode, err := parseSignedDuration(dur.Location().Source)
+		if err != nil {
+			return opt, err
+		}
+		durNode.BaseNode = ast.BaseNode{}
+		opt.Timeout = &Duration{}
+		opt.Timeout.Node = *durNode
+	}
+
 	if err := opt.Validate(); err != nil {
 		return opt, err
 	}
@@ -361,6 +386,17 @@ func (o *Options) Validate() error {
 			errs = append(errs, fmt.Sprintf("retry exceeded max of %d", maxRetry))
 		}
 	}
+	if o.Timeout != nil {
+		timeout, err := o.Timeout.DurationFrom(now)
+		if err != nil {
+			return err
+		}
+		if timeout < time.Second {
+			errs = append(errs, "timeout option must be at least 1 second")
+		} else if timeout.Truncate(time.Second) != timeout {
+			errs = append(errs, "timeout option must be expressible as whole seconds")
+		}
+	}
 
 	if len(errs) == 0 {
 		return nil
@@ -402,7 +438,7 @@ func validateOptionNames(o values.Object) error {
 	var unexpected []string
 	o.Range(func(name string, _ values.Value) {
 		switch name {
-		case optName, optCron, optEvery, optOffset, optConcurrency, optRetry:
+		case optName, optCron, optEvery, optOffset, optConcurrency, optRetry, optTimeout:
 			// Known option. Nothing to do.
 		default:
 			unexpected = append(unexpected, name)
@@ -411,7 +447,7 @@ func validateOptionNames(o values.Object) error {
 
 	if len(unexpected) > 0 {
 		u := strings.Join(unexpected, ", ")
-		v := strings.Join([]string{optName, optCron, optEvery, optOffset, optConcurrency, optRetry}, ", ")
+		v := strings.Join([]string{optName, optCron, optEvery, optOffset, optConcurrency, optRetry, optTimeout}, ", ")
 		return fmt.Errorf("unknown task option(s): %s. valid options are %s", u, v)
 	}
 
@@ -83,6 +83,11 @@ func TestTaskService(t *testing.T, fn BackendComponentFactory, testCategory ...s
 					t.Parallel()
 					testManualRun(t, sys)
 				})
+
+				t.Run("Task Dependencies", func(t *testing.T) {
+					t.Parallel()
+					testTaskDependencies(t, sys)
+				})
 			})
 		case "analytical":
 			t.Run("AnalyticalTaskService", func(t *testing.T) {
@@ -1024,6 +1029,113 @@ func testManualRun(t *testing.T, s *System) {
 	}
 }
 
+func testTaskDependencies(t *testing.T, sys *System) {
+	cr := creds(t, sys)
+	authorizedCtx := icontext.SetAuthorizer(sys.Ctx, cr.Authorizer())
+
+	createTask := func(n int, dependsOn ...influxdb.ID) (*influxdb.Task, error) {
+		return sys.TaskService.CreateTask(authorizedCtx, influxdb.TaskCreate{
+			OrganizationID: cr.OrgID,
+			Flux:           fmt.Sprintf(scriptFmt, n),
+			Token:          cr.Token,
+			DependsOn:      dependsOn,
+		})
+	}
+
+	// Chain raw -> 1m -> 1h.
+	raw, err := createTask(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minute, err := createTask(1, raw.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hour, err := createTask(2, minute.ID, minute.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]influxdb.ID{minute.ID}, hour.DependsOn); diff != "" {
+		t.Fatalf("unexpected dependencies of created task: %s", diff)
+	}
+
+	found, err := sys.TaskService.FindTaskByID(authorizedCtx, minute.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]influxdb.ID{raw.ID}, found.DependsOn); diff != "" {
+		t.Fatalf("unexpected dependencies of found task: %s", diff)
+	}
+
+	if _, err := createTask(3, influxdb.ID(1)); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid error when depending on a missing task, got %v", err)
+	}
+
+	// Updates that would make a task depend on itself are rejected.
+	for _, deps := range [][]influxdb.ID{{hour.ID}, {raw.ID}} {
+		deps := deps
+		if _, err := sys.TaskService.UpdateTask(authorizedCtx, raw.ID, influxdb.TaskUpdate{DependsOn: &deps}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected invalid error for dependency cycle %v, got %v", deps, err)
+		}
+	}
+
+	// A successful run of raw queues a run of 1m for the same schedule time, but not of 1h.
+	scheduledFor := time.Now().UTC().Truncate(time.Minute)
+	if _, err := sys.TaskService.ForceRun(authorizedCtx, raw.ID, scheduledFor.Unix()); err != nil {
+		t.Fatal(err)
+	}
+	rc, err := sys.TaskControlService.CreateNextRun(sys.Ctx, raw.ID, scheduledFor.Unix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.TaskControlService.UpdateRunState(sys.Ctx, raw.ID, rc.Created.RunID, time.Now(), backend.RunSuccess); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sys.TaskControlService.FinishRun(sys.Ctx, raw.ID, rc.Created.RunID); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := sys.TaskControlService.ManualRuns(sys.Ctx, minute.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].ScheduledFor != scheduledFor.Format(time.RFC3339) {
+		t.Fatalf("expected 1 dependent run scheduled for %s, got %v", scheduledFor.Format(time.RFC3339), runs)
+	}
+	runs, err = sys.TaskControlService.ManualRuns(sys.Ctx, hour.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 0 {
+		t.Fatalf("expected no run of indirect dependent, got %v", runs)
+	}
+
+	// Deleting a task removes it from the dependencies of its dependents.
+	if err := sys.TaskService.DeleteTask(authorizedCtx, minute.ID); err != nil {
+		t.Fatal(err)
+	}
+	found, err = sys.TaskService.FindTaskByID(authorizedCtx, hour.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found.DependsOn) != 0 {
+		t.Fatalf("expected no dependencies after deleting dependency, got %v", found.DependsOn)
+	}
+
+	// Dependencies are removed with an empty list.
+	none := []influxdb.ID{}
+	if _, err := sys.TaskService.UpdateTask(authorizedCtx, hour.ID, influxdb.TaskUpdate{DependsOn: &[]influxdb.ID{raw.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := sys.TaskService.UpdateTask(authorizedCtx, hour.ID, influxdb.TaskUpdate{DependsOn: &none})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.DependsOn) != 0 {
+		t.Fatalf("expected no dependencies after update, got %v", updated.DependsOn)
+	}
+}
+
 func testRunStorage(t *testing.T, sys *System) {
 	cr := creds(t, sys)
 
@@ -0,0 +1,127 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// BackfillMaxRuns is the largest number of runs a single backfill may consist of.
+	BackfillMaxRuns = 100000
+
+	// BackfillMaxConcurrency is the largest number of runs of a backfill that may be queued at once.
+	BackfillMaxConcurrency = 100
+
+	BackfillStatusRunning  = "running"
+	BackfillStatusSuccess  = "success"
+	BackfillStatusCanceled = "canceled"
+	BackfillStatusFailed   = "failed"
+)
+
+var (
+	// ErrBackfillNotFound is returned when a backfill cannot be found.
+	ErrBackfillNotFound = &Error{
+		Code: ENotFound,
+		Msg:  "backfill not found",
+	}
+
+	// ErrBackfillNotRunning is returned when canceling a backfill that has already finished.
+	ErrBackfillNotRunning = &Error{
+		Code: EConflict,
+		Msg:  "backfill is not running",
+	}
+)
+
+// Backfill runs a task once for every time of its schedule within a time range,
+// and records the progress made doing so.
+//
+// A backfill covers the schedule times after Start up to and including Stop, so that
+// a task aggregating the previous period of its schedule processes the data between Start and Stop.
+// At most Concurrency runs of a backfill are queued or running at once.
+type Backfill struct {
+	ID             ID     `json:"id"`
+	TaskID         ID     `json:"taskID"`
+	OrganizationID ID     `json:"orgID"`
+	Start          string `json:"start"`
+	Stop           string `json:"stop"`
+	Concurrency    int    `json:"concurrency"`
+	Status         string `json:"status"`
+	Error          string `json:"error,omitempty"`
+
+	// Next is the schedule time of the next run to queue.
+	// It is empty once all of the runs have been queued.
+	Next string `json:"next,omitempty"`
+	// Total is the number of runs the backfill consists of.
+	Total int `json:"total"`
+	// Queued is the number of runs queued so far.
+	Queued int `json:"queued"`
+	// Finished is the number of queued runs that have finished.
+	Finished int `json:"finished"`
+	// Runs are the IDs of the queued runs that have not finished yet.
+	Runs []ID `json:"runs,omitempty"`
+
+	CreatedAt  string `json:"createdAt,omitempty"`
+	UpdatedAt  string `json:"updatedAt,omitempty"`
+	FinishedAt string `json:"finishedAt,omitempty"`
+}
+
+// Running reports whether the backfill still has runs to queue or wait for.
+func (b *Backfill) Running() bool {
+	return b.Status == BackfillStatusRunning
+}
+
+// BackfillCreate is the set of values to create a backfill.
+type BackfillCreate struct {
+	Start time.Time `json:"start"`
+	Stop  time.Time `json:"stop"`
+	// Concurrency is the number of runs of the backfill that may be queued at once.
+	// It defaults to 1.
+	Concurrency int `json:"concurrency,omitempty"`
+}
+
+// Validate returns an error if the backfill range or concurrency is invalid.
+func (c BackfillCreate) Validate() error {
+	if c.Start.IsZero() || c.Stop.IsZero() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "backfill requires a start and a stop time",
+		}
+	}
+	if !c.Start.Before(c.Stop) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "backfill start must be before stop",
+		}
+	}
+	if c.Concurrency < 0 || c.Concurrency > BackfillMaxConcurrency {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("backfill concurrency must be between 1 and %d", BackfillMaxConcurrency),
+		}
+	}
+	return nil
+}
+
+// BackfillFilter represents a set of filters that restrict the returned backfills.
+type BackfillFilter struct {
+	// Task restricts the backfills to those of a task.
+	// It is required for listing backfills over HTTP.
+	Task *ID
+}
+
+// BackfillService manages backfills of tasks.
+type BackfillService interface {
+	// CreateBackfill starts queueing the runs of the task with taskID within the range of c.
+	CreateBackfill(ctx context.Context, taskID ID, c BackfillCreate) (*Backfill, error)
+
+	// FindBackfillByID returns a single backfill of a task.
+	FindBackfillByID(ctx context.Context, taskID, id ID) (*Backfill, error)
+
+	// FindBackfills returns the backfills that match filter, newest first.
+	FindBackfills(ctx context.Context, filter BackfillFilter) ([]*Backfill, error)
+
+	// CancelBackfill stops queueing runs for a backfill.
+	// Runs of the backfill that were already queued are still executed.
+	CancelBackfill(ctx context.Context, taskID, id ID) (*Backfill, error)
+}
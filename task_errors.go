@@ -12,6 +12,12 @@ var (
 		Msg:  "run canceled",
 	}
 
+	// ErrRunTimedOut is returned from the RunResult when a Run exceeds the timeout of its task.
+	ErrRunTimedOut = &Error{
+		Code: EInternal,
+		Msg:  "run timed out",
+	}
+
 	// ErrTaskNotClaimed is returned when attempting to operate against a task that must be claimed but is not.
 	ErrTaskNotClaimed = &Error{
 		Code: EConflict,
@@ -68,6 +74,12 @@ var (
 		Code: EConflict,
 	}
 
+	// ErrTaskDependencyCycle is returned when the dependencies of a task would make it depend on itself.
+	ErrTaskDependencyCycle = &Error{
+		Code: EInvalid,
+		Msg:  "task dependencies cannot form a cycle",
+	}
+
 	// ErrOutOfBoundsLimit is returned with FindRuns is called with an invalid filter limit.
 	ErrOutOfBoundsLimit = &Error{
 		Code: EUnprocessableEntity,
@@ -75,6 +87,14 @@ var (
 	}
 )
 
+// ErrTaskDependencyNotFound is returned when a task depends on a task that does not exist in its organization.
+func ErrTaskDependencyNotFound(id ID) *Error {
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("task dependency %s not found", id),
+	}
+}
+
 func ErrInternalTaskServiceError(err error) *Error {
 	return &Error{
 		Code: EInternal,
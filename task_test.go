@@ -79,6 +79,25 @@ from(bucket: "x")
 			t.Fatalf("expected every to be 30s but was %s", op.Every)
 		}
 	})
+	t.Run("set retry and timeout", func(t *testing.T) {
+		tu := &platform.TaskUpdate{}
+		if err := json.Unmarshal([]byte(`{"retry": 3, "timeout": "5m"}`), tu); err != nil {
+			t.Fatal(err)
+		}
+		if err := tu.UpdateFlux(`option task = {every: 20s, name: "foo", retry: 2} from(bucket:"x") |> range(start:-1h)`); err != nil {
+			t.Fatal(err)
+		}
+		op, err := options.FromScript(*tu.Flux)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if op.Retry == nil || *op.Retry != 3 {
+			t.Fatalf("expected retry to be 3 but was %v", op.Retry)
+		}
+		if op.Timeout == nil || op.Timeout.String() != "5m" {
+			t.Fatalf("expected timeout to be 5m but was %v", op.Timeout)
+		}
+	})
 	t.Run("switching from every to cron", func(t *testing.T) {
 		tu := &platform.TaskUpdate{}
 		tu.Options.Cron = "* * * * *"
@@ -5,6 +5,7 @@ import (
 	"context"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	platform "github.com/influxdata/influxdb"
@@ -451,6 +452,10 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
 // UpdateAuthorization testing
 func UpdateAuthorization(
 	init func(AuthorizationFields, *testing.T) (platform.AuthorizationService, string, func()),
@@ -544,6 +549,92 @@ func UpdateAuthorization(
 				},
 			},
 		},
+		{
+			name: "update expiry",
+			fields: AuthorizationFields{
+				Users: []*platform.User{
+					{
+						Name: "cooluser",
+						ID:   MustIDBase16(userOneID),
+					},
+				},
+				Orgs: []*platform.Organization{
+					{
+						Name: "o1",
+						ID:   MustIDBase16(orgOneID),
+					},
+				},
+				Authorizations: []*platform.Authorization{
+					{
+						ID:          MustIDBase16(authOneID),
+						UserID:      MustIDBase16(userOneID),
+						OrgID:       MustIDBase16(orgOneID),
+						Token:       "rand1",
+						Permissions: allUsersPermission(MustIDBase16(orgOneID)),
+					},
+				},
+			},
+			args: args{
+				id: MustIDBase16(authOneID),
+				upd: &platform.AuthorizationUpdate{
+					ExpiresAt: timePtr(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+				},
+			},
+			wants: wants{
+				authorization: &platform.Authorization{
+					ID:          MustIDBase16(authOneID),
+					UserID:      MustIDBase16(userOneID),
+					OrgID:       MustIDBase16(orgOneID),
+					Token:       "rand1",
+					Permissions: allUsersPermission(MustIDBase16(orgOneID)),
+					Status:      platform.Active,
+					ExpiresAt:   timePtr(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+				},
+			},
+		},
+		{
+			name: "clear expiry",
+			fields: AuthorizationFields{
+				Users: []*platform.User{
+					{
+						Name: "cooluser",
+						ID:   MustIDBase16(userOneID),
+					},
+				},
+				Orgs: []*platform.Organization{
+					{
+						Name: "o1",
+						ID:   MustIDBase16(orgOneID),
+					},
+				},
+				Authorizations: []*platform.Authorization{
+					{
+						ID:          MustIDBase16(authOneID),
+						UserID:      MustIDBase16(userOneID),
+						OrgID:       MustIDBase16(orgOneID),
+						Token:       "rand1",
+						Permissions: allUsersPermission(MustIDBase16(orgOneID)),
+						ExpiresAt:   timePtr(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+					},
+				},
+			},
+			args: args{
+				id: MustIDBase16(authOneID),
+				upd: &platform.AuthorizationUpdate{
+					ClearExpiresAt: true,
+				},
+			},
+			wants: wants{
+				authorization: &platform.Authorization{
+					ID:          MustIDBase16(authOneID),
+					UserID:      MustIDBase16(userOneID),
+					OrgID:       MustIDBase16(orgOneID),
+					Token:       "rand1",
+					Permissions: allUsersPermission(MustIDBase16(orgOneID)),
+					Status:      platform.Active,
+				},
+			},
+		},
 		{
 			name: "update with id not found",
 			fields: AuthorizationFields{
@@ -807,6 +807,7 @@ func UpdateOrganization(
 		id          platform.ID
 		name        *string
 		description *string
+		queryLimits *platform.QueryLimits
 	}
 	type wants struct {
 		err          error
@@ -980,6 +981,58 @@ func UpdateOrganization(
 				},
 			},
 		},
+		{
+			name: "update query limits",
+			fields: OrganizationFields{
+				TimeGenerator: mock.TimeGenerator{FakeValue: time.Date(2006, 5, 4, 1, 2, 3, 0, time.UTC)},
+				Organizations: []*platform.Organization{
+					{
+						ID:   MustIDBase16(orgOneID),
+						Name: "organization1",
+					},
+				},
+			},
+			args: args{
+				id:          MustIDBase16(orgOneID),
+				queryLimits: &platform.QueryLimits{ConcurrencyQuota: 2, QueueSize: 10},
+			},
+			wants: wants{
+				organization: &platform.Organization{
+					ID:          MustIDBase16(orgOneID),
+					Name:        "organization1",
+					QueryLimits: &platform.QueryLimits{ConcurrencyQuota: 2, QueueSize: 10},
+					CRUDLog: platform.CRUDLog{
+						UpdatedAt: time.Date(2006, 5, 4, 1, 2, 3, 0, time.UTC),
+					},
+				},
+			},
+		},
+		{
+			name: "remove query limits",
+			fields: OrganizationFields{
+				TimeGenerator: mock.TimeGenerator{FakeValue: time.Date(2006, 5, 4, 1, 2, 3, 0, time.UTC)},
+				Organizations: []*platform.Organization{
+					{
+						ID:          MustIDBase16(orgOneID),
+						Name:        "organization1",
+						QueryLimits: &platform.QueryLimits{ConcurrencyQuota: 2},
+					},
+				},
+			},
+			args: args{
+				id:          MustIDBase16(orgOneID),
+				queryLimits: &platform.QueryLimits{},
+			},
+			wants: wants{
+				organization: &platform.Organization{
+					ID:   MustIDBase16(orgOneID),
+					Name: "organization1",
+					CRUDLog: platform.CRUDLog{
+						UpdatedAt: time.Date(2006, 5, 4, 1, 2, 3, 0, time.UTC),
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -991,6 +1044,7 @@ func UpdateOrganization(
 			upd := platform.OrganizationUpdate{}
 			upd.Name = tt.args.name
 			upd.Description = tt.args.description
+			upd.QueryLimits = tt.args.queryLimits
 
 			organization, err := s.UpdateOrganization(ctx, tt.args.id, upd)
 			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
@@ -0,0 +1,586 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+const (
+	replicationOneID   = "020f755c3c082010"
+	replicationTwoID   = "020f755c3c082011"
+	replicationOrgOne  = "020f755c3c082012"
+	replicationOrgTwo  = "020f755c3c082013"
+	replicationBucket1 = "020f755c3c082014"
+	replicationBucket2 = "020f755c3c082015"
+	replicationRemote  = "020f755c3c082016"
+)
+
+var replicationCmpOptions = cmp.Options{
+	cmp.Comparer(func(x, y []byte) bool {
+		return bytes.Equal(x, y)
+	}),
+	cmp.Transformer("Sort", func(in []*influxdb.Replication) []*influxdb.Replication {
+		out := append([]*influxdb.Replication(nil), in...)
+		sort.Slice(out, func(i, j int) bool {
+			return out[i].ID.String() > out[j].ID.String()
+		})
+		return out
+	}),
+}
+
+// ReplicationFields will include the IDGenerator, TimeGenerator, and the
+// organizations, buckets and replications to populate the service with.
+type ReplicationFields struct {
+	IDGenerator   influxdb.IDGenerator
+	TimeGenerator influxdb.TimeGenerator
+	Organizations []*influxdb.Organization
+	Buckets       []*influxdb.Bucket
+	Replications  []*influxdb.Replication
+}
+
+func replicationOrgsAndBuckets() ([]*influxdb.Organization, []*influxdb.Bucket) {
+	orgs := []*influxdb.Organization{
+		{ID: MustIDBase16(replicationOrgOne), Name: "org1"},
+		{ID: MustIDBase16(replicationOrgTwo), Name: "org2"},
+	}
+	buckets := []*influxdb.Bucket{
+		{ID: MustIDBase16(replicationBucket1), OrgID: MustIDBase16(replicationOrgOne), Name: "bucket1"},
+		{ID: MustIDBase16(replicationBucket2), OrgID: MustIDBase16(replicationOrgTwo), Name: "bucket2"},
+	}
+	return orgs, buckets
+}
+
+func newTestReplication(id, orgID, bucketID string) *influxdb.Replication {
+	return &influxdb.Replication{
+		ID:                MustIDBase16(id),
+		OrgID:             MustIDBase16(orgID),
+		Name:              "replication-" + id,
+		LocalBucketID:     MustIDBase16(bucketID),
+		RemoteURL:         "http://remote:9999",
+		RemoteToken:       "token",
+		RemoteOrgID:       MustIDBase16(replicationRemote),
+		RemoteBucketID:    MustIDBase16(replicationRemote),
+		MaxQueueSizeBytes: 1024,
+		DropPolicy:        influxdb.DropOldestReplicationPolicy,
+		CRUDLog: influxdb.CRUDLog{
+			CreatedAt: oldFakeDate,
+			UpdatedAt: oldFakeDate,
+		},
+	}
+}
+
+// ReplicationService tests all the service functions.
+func ReplicationService(
+	init func(ReplicationFields, *testing.T) (influxdb.ReplicationService, string, func()), t *testing.T,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(ReplicationFields, *testing.T) (influxdb.ReplicationService, string, func()),
+			t *testing.T)
+	}{
+		{
+			name: "CreateReplication",
+			fn:   CreateReplication,
+		},
+		{
+			name: "FindReplicationByID",
+			fn:   FindReplicationByID,
+		},
+		{
+			name: "FindReplications",
+			fn:   FindReplications,
+		},
+		{
+			name: "UpdateReplication",
+			fn:   UpdateReplication,
+		},
+		{
+			name: "DeleteReplication",
+			fn:   DeleteReplication,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t)
+		})
+	}
+}
+
+// CreateReplication testing
+func CreateReplication(init func(ReplicationFields, *testing.T) (influxdb.ReplicationService, string, func()), t *testing.T) {
+	orgs, buckets := replicationOrgsAndBuckets()
+
+	type args struct {
+		replication *influxdb.Replication
+	}
+	type wants struct {
+		err          error
+		replications []*influxdb.Replication
+	}
+
+	tests := []struct {
+		name   string
+		fields ReplicationFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "create replication with defaults",
+			fields: ReplicationFields{
+				IDGenerator:   mock.NewIDGenerator(replicationOneID, t),
+				TimeGenerator: fakeGenerator,
+				Organizations: orgs,
+				Buckets:       buckets,
+			},
+			args: args{
+				replication: &influxdb.Replication{
+					OrgID:          MustIDBase16(replicationOrgOne),
+					Name:           "edge",
+					LocalBucketID:  MustIDBase16(replicationBucket1),
+					RemoteURL:      "https://cloud:9999",
+					RemoteToken:    "secret",
+					RemoteOrgID:    MustIDBase16(replicationRemote),
+					RemoteBucketID: MustIDBase16(replicationRemote),
+				},
+			},
+			wants: wants{
+				replications: []*influxdb.Replication{
+					{
+						ID:                MustIDBase16(replicationOneID),
+						OrgID:             MustIDBase16(replicationOrgOne),
+						Name:              "edge",
+						LocalBucketID:     MustIDBase16(replicationBucket1),
+						RemoteURL:         "https://cloud:9999",
+						RemoteToken:       "secret",
+						RemoteOrgID:       MustIDBase16(replicationRemote),
+						RemoteBucketID:    MustIDBase16(replicationRemote),
+						MaxQueueSizeBytes: influxdb.DefaultReplicationMaxQueueSizeBytes,
+						DropPolicy:        influxdb.DropOldestReplicationPolicy,
+						CRUDLog: influxdb.CRUDLog{
+							CreatedAt: fakeDate,
+							UpdatedAt: fakeDate,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "local bucket of another organization",
+			fields: ReplicationFields{
+				IDGenerator:   mock.NewIDGenerator(replicationOneID, t),
+				TimeGenerator: fakeGenerator,
+				Organizations: orgs,
+				Buckets:       buckets,
+			},
+			args: args{
+				replication: &influxdb.Replication{
+					OrgID:          MustIDBase16(replicationOrgOne),
+					Name:           "edge",
+					LocalBucketID:  MustIDBase16(replicationBucket2),
+					RemoteURL:      "https://cloud:9999",
+					RemoteOrgID:    MustIDBase16(replicationRemote),
+					RemoteBucketID: MustIDBase16(replicationRemote),
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Op:   influxdb.OpCreateReplication,
+					Msg:  "local bucket does not belong to the organization of the replication",
+				},
+				replications: []*influxdb.Replication{},
+			},
+		},
+		{
+			name: "invalid remote url",
+			fields: ReplicationFields{
+				IDGenerator:   mock.NewIDGenerator(replicationOneID, t),
+				TimeGenerator: fakeGenerator,
+				Organizations: orgs,
+				Buckets:       buckets,
+			},
+			args: args{
+				replication: &influxdb.Replication{
+					OrgID:          MustIDBase16(replicationOrgOne),
+					Name:           "edge",
+					LocalBucketID:  MustIDBase16(replicationBucket1),
+					RemoteURL:      "cloud:9999",
+					RemoteOrgID:    MustIDBase16(replicationRemote),
+					RemoteBucketID: MustIDBase16(replicationRemote),
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Op:   influxdb.OpCreateReplication,
+					Msg:  "replication remoteURL must be an http or https URL",
+				},
+				replications: []*influxdb.Replication{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, opPrefix, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.CreateReplication(ctx, tt.args.replication)
+			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
+			if err == nil {
+				defer s.DeleteReplication(ctx, tt.args.replication.ID)
+			}
+
+			replications, err := s.FindReplications(ctx, influxdb.ReplicationFilter{})
+			if err != nil {
+				t.Fatalf("failed to retrieve replications: %v", err)
+			}
+			if diff := cmp.Diff(replications, tt.wants.replications, replicationCmpOptions...); diff != "" {
+				t.Errorf("replications are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindReplicationByID testing
+func FindReplicationByID(init func(ReplicationFields, *testing.T) (influxdb.ReplicationService, string, func()), t *testing.T) {
+	orgs, buckets := replicationOrgsAndBuckets()
+
+	type args struct {
+		id influxdb.ID
+	}
+	type wants struct {
+		err         error
+		replication *influxdb.Replication
+	}
+
+	tests := []struct {
+		name   string
+		fields ReplicationFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "find replication by id",
+			fields: ReplicationFields{
+				Organizations: orgs,
+				Buckets:       buckets,
+				Replications: []*influxdb.Replication{
+					newTestReplication(replicationOneID, replicationOrgOne, replicationBucket1),
+					newTestReplication(replicationTwoID, replicationOrgTwo, replicationBucket2),
+				},
+			},
+			args: args{
+				id: MustIDBase16(replicationTwoID),
+			},
+			wants: wants{
+				replication: newTestReplication(replicationTwoID, replicationOrgTwo, replicationBucket2),
+			},
+		},
+		{
+			name: "find replication that does not exist",
+			fields: ReplicationFields{
+				Organizations: orgs,
+				Buckets:       buckets,
+				Replications: []*influxdb.Replication{
+					newTestReplication(replicationOneID, replicationOrgOne, replicationBucket1),
+				},
+			},
+			args: args{
+				id: MustIDBase16(replicationTwoID),
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.ENotFound,
+					Op:   influxdb.OpFindReplicationByID,
+					Msg:  influxdb.ErrReplicationNotFound,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, opPrefix, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+
+			replication, err := s.FindReplicationByID(ctx, tt.args.id)
+			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
+
+			if diff := cmp.Diff(replication, tt.wants.replication, replicationCmpOptions...); diff != "" {
+				t.Errorf("replication is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindReplications testing
+func FindReplications(init func(ReplicationFields, *testing.T) (influxdb.ReplicationService, string, func()), t *testing.T) {
+	orgs, buckets := replicationOrgsAndBuckets()
+	fields := ReplicationFields{
+		Organizations: orgs,
+		Buckets:       buckets,
+		Replications: []*influxdb.Replication{
+			newTestReplication(replicationOneID, replicationOrgOne, replicationBucket1),
+			newTestReplication(replicationTwoID, replicationOrgTwo, replicationBucket2),
+		},
+	}
+
+	tests := []struct {
+		name   string
+		filter influxdb.ReplicationFilter
+		wants  []*influxdb.Replication
+	}{
+		{
+			name:   "find all replications",
+			filter: influxdb.ReplicationFilter{},
+			wants: []*influxdb.Replication{
+				newTestReplication(replicationOneID, replicationOrgOne, replicationBucket1),
+				newTestReplication(replicationTwoID, replicationOrgTwo, replicationBucket2),
+			},
+		},
+		{
+			name:   "find replications by organization",
+			filter: influxdb.ReplicationFilter{OrgID: idPtr(MustIDBase16(replicationOrgTwo))},
+			wants: []*influxdb.Replication{
+				newTestReplication(replicationTwoID, replicationOrgTwo, replicationBucket2),
+			},
+		},
+		{
+			name:   "find replications by local bucket",
+			filter: influxdb.ReplicationFilter{LocalBucketID: idPtr(MustIDBase16(replicationBucket1))},
+			wants: []*influxdb.Replication{
+				newTestReplication(replicationOneID, replicationOrgOne, replicationBucket1),
+			},
+		},
+		{
+			name: "find replication by id and mismatched organization",
+			filter: influxdb.ReplicationFilter{
+				ID:    idPtr(MustIDBase16(replicationOneID)),
+				OrgID: idPtr(MustIDBase16(replicationOrgTwo)),
+			},
+			wants: []*influxdb.Replication{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(fields, t)
+			defer done()
+			ctx := context.Background()
+
+			replications, err := s.FindReplications(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("failed to retrieve replications: %v", err)
+			}
+			if diff := cmp.Diff(replications, tt.wants, replicationCmpOptions...); diff != "" {
+				t.Errorf("replications are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// UpdateReplication testing
+func UpdateReplication(init func(ReplicationFields, *testing.T) (influxdb.ReplicationService, string, func()), t *testing.T) {
+	orgs, buckets := replicationOrgsAndBuckets()
+
+	name := "renamed"
+	size := int64(2048)
+	policy := influxdb.DropNewestReplicationPolicy
+	badPolicy := influxdb.ReplicationDropPolicy("drop-all")
+
+	updated := newTestReplication(replicationOneID, replicationOrgOne, replicationBucket1)
+	updated.Name = name
+	updated.MaxQueueSizeBytes = size
+	updated.DropPolicy = policy
+	updated.UpdatedAt = fakeDate
+
+	type args struct {
+		id  influxdb.ID
+		upd influxdb.ReplicationUpdate
+	}
+	type wants struct {
+		err         error
+		replication *influxdb.Replication
+	}
+
+	tests := []struct {
+		name   string
+		fields ReplicationFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "update replication",
+			fields: ReplicationFields{
+				TimeGenerator: fakeGenerator,
+				Organizations: orgs,
+				Buckets:       buckets,
+				Replications: []*influxdb.Replication{
+					newTestReplication(replicationOneID, replicationOrgOne, replicationBucket1),
+				},
+			},
+			args: args{
+				id: MustIDBase16(replicationOneID),
+				upd: influxdb.ReplicationUpdate{
+					Name:              &name,
+					MaxQueueSizeBytes: &size,
+					DropPolicy:        &policy,
+				},
+			},
+			wants: wants{
+				replication: updated,
+			},
+		},
+		{
+			name: "update with invalid drop policy",
+			fields: ReplicationFields{
+				TimeGenerator: fakeGenerator,
+				Organizations: orgs,
+				Buckets:       buckets,
+				Replications: []*influxdb.Replication{
+					newTestReplication(replicationOneID, replicationOrgOne, replicationBucket1),
+				},
+			},
+			args: args{
+				id: MustIDBase16(replicationOneID),
+				upd: influxdb.ReplicationUpdate{
+					DropPolicy: &badPolicy,
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Op:   influxdb.OpUpdateReplication,
+					Msg:  "drop policy must be drop-oldest or drop-newest",
+				},
+			},
+		},
+		{
+			name: "update replication that does not exist",
+			fields: ReplicationFields{
+				TimeGenerator: fakeGenerator,
+				Organizations: orgs,
+				Buckets:       buckets,
+			},
+			args: args{
+				id: MustIDBase16(replicationTwoID),
+				upd: influxdb.ReplicationUpdate{
+					Name: &name,
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.ENotFound,
+					Op:   influxdb.OpUpdateReplication,
+					Msg:  influxdb.ErrReplicationNotFound,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, opPrefix, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+
+			replication, err := s.UpdateReplication(ctx, tt.args.id, tt.args.upd)
+			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
+
+			if diff := cmp.Diff(replication, tt.wants.replication, replicationCmpOptions...); diff != "" {
+				t.Errorf("replication is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// DeleteReplication testing
+func DeleteReplication(init func(ReplicationFields, *testing.T) (influxdb.ReplicationService, string, func()), t *testing.T) {
+	orgs, buckets := replicationOrgsAndBuckets()
+
+	type args struct {
+		id influxdb.ID
+	}
+	type wants struct {
+		err          error
+		replications []*influxdb.Replication
+	}
+
+	tests := []struct {
+		name   string
+		fields ReplicationFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "delete replication",
+			fields: ReplicationFields{
+				Organizations: orgs,
+				Buckets:       buckets,
+				Replications: []*influxdb.Replication{
+					newTestReplication(replicationOneID, replicationOrgOne, replicationBucket1),
+					newTestReplication(replicationTwoID, replicationOrgTwo, replicationBucket2),
+				},
+			},
+			args: args{
+				id: MustIDBase16(replicationOneID),
+			},
+			wants: wants{
+				replications: []*influxdb.Replication{
+					newTestReplication(replicationTwoID, replicationOrgTwo, replicationBucket2),
+				},
+			},
+		},
+		{
+			name: "delete replication that does not exist",
+			fields: ReplicationFields{
+				Organizations: orgs,
+				Buckets:       buckets,
+				Replications: []*influxdb.Replication{
+					newTestReplication(replicationTwoID, replicationOrgTwo, replicationBucket2),
+				},
+			},
+			args: args{
+				id: MustIDBase16(replicationOneID),
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.ENotFound,
+					Op:   influxdb.OpDeleteReplication,
+					Msg:  influxdb.ErrReplicationNotFound,
+				},
+				replications: []*influxdb.Replication{
+					newTestReplication(replicationTwoID, replicationOrgTwo, replicationBucket2),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, opPrefix, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.DeleteReplication(ctx, tt.args.id)
+			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
+
+			replications, err := s.FindReplications(ctx, influxdb.ReplicationFilter{})
+			if err != nil {
+				t.Fatalf("failed to retrieve replications: %v", err)
+			}
+			if diff := cmp.Diff(replications, tt.wants.replications, replicationCmpOptions...); diff != "" {
+				t.Errorf("replications are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
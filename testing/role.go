@@ -0,0 +1,600 @@
+package testing
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+const (
+	roleOneID  = "020f755c3c082020"
+	roleTwoID  = "020f755c3c082021"
+	roleOrgOne = "020f755c3c082022"
+	roleOrgTwo = "020f755c3c082023"
+	roleUserID = "020f755c3c082024"
+)
+
+var roleCmpOptions = cmp.Options{
+	cmp.Transformer("Sort", func(in []*influxdb.Role) []*influxdb.Role {
+		out := append([]*influxdb.Role(nil), in...)
+		sort.Slice(out, func(i, j int) bool {
+			return out[i].ID.String() > out[j].ID.String()
+		})
+		return out
+	}),
+}
+
+// RoleFields will include the IDGenerator, TimeGenerator, and the
+// organizations, roles and user resource mappings to populate the service with.
+type RoleFields struct {
+	IDGenerator          influxdb.IDGenerator
+	TimeGenerator        influxdb.TimeGenerator
+	Organizations        []*influxdb.Organization
+	Roles                []*influxdb.Role
+	UserResourceMappings []*influxdb.UserResourceMapping
+}
+
+func roleOrgs() []*influxdb.Organization {
+	return []*influxdb.Organization{
+		{ID: MustIDBase16(roleOrgOne), Name: "org1"},
+		{ID: MustIDBase16(roleOrgTwo), Name: "org2"},
+	}
+}
+
+func roleBucketPermission(orgID string) influxdb.Permission {
+	return influxdb.Permission{
+		Action: influxdb.ReadAction,
+		Resource: influxdb.Resource{
+			Type:  influxdb.BucketsResourceType,
+			OrgID: idPtr(MustIDBase16(orgID)),
+		},
+	}
+}
+
+func newTestRole(id, orgID, name string) *influxdb.Role {
+	return &influxdb.Role{
+		ID:          MustIDBase16(id),
+		OrgID:       MustIDBase16(orgID),
+		Name:        name,
+		Permissions: []influxdb.Permission{roleBucketPermission(orgID)},
+		CRUDLog: influxdb.CRUDLog{
+			CreatedAt: oldFakeDate,
+			UpdatedAt: oldFakeDate,
+		},
+	}
+}
+
+// RoleService tests all the service functions.
+func RoleService(
+	init func(RoleFields, *testing.T) (influxdb.RoleService, string, func()), t *testing.T,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(RoleFields, *testing.T) (influxdb.RoleService, string, func()),
+			t *testing.T)
+	}{
+		{
+			name: "CreateRole",
+			fn:   CreateRole,
+		},
+		{
+			name: "FindRoleByID",
+			fn:   FindRoleByID,
+		},
+		{
+			name: "FindRoles",
+			fn:   FindRoles,
+		},
+		{
+			name: "UpdateRole",
+			fn:   UpdateRole,
+		},
+		{
+			name: "DeleteRole",
+			fn:   DeleteRole,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t)
+		})
+	}
+}
+
+// CreateRole testing
+func CreateRole(init func(RoleFields, *testing.T) (influxdb.RoleService, string, func()), t *testing.T) {
+	type args struct {
+		role *influxdb.Role
+	}
+	type wants struct {
+		err   error
+		roles []*influxdb.Role
+	}
+
+	tests := []struct {
+		name   string
+		fields RoleFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "create role",
+			fields: RoleFields{
+				IDGenerator:   mock.NewIDGenerator(roleOneID, t),
+				TimeGenerator: fakeGenerator,
+				Organizations: roleOrgs(),
+			},
+			args: args{
+				role: &influxdb.Role{
+					OrgID:       MustIDBase16(roleOrgOne),
+					Name:        "readers",
+					Permissions: []influxdb.Permission{roleBucketPermission(roleOrgOne)},
+				},
+			},
+			wants: wants{
+				roles: []*influxdb.Role{
+					{
+						ID:          MustIDBase16(roleOneID),
+						OrgID:       MustIDBase16(roleOrgOne),
+						Name:        "readers",
+						Permissions: []influxdb.Permission{roleBucketPermission(roleOrgOne)},
+						CRUDLog: influxdb.CRUDLog{
+							CreatedAt: fakeDate,
+							UpdatedAt: fakeDate,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "names are unique within an organization",
+			fields: RoleFields{
+				IDGenerator:   mock.NewIDGenerator(roleTwoID, t),
+				TimeGenerator: fakeGenerator,
+				Organizations: roleOrgs(),
+				Roles: []*influxdb.Role{
+					newTestRole(roleOneID, roleOrgOne, "readers"),
+				},
+			},
+			args: args{
+				role: &influxdb.Role{
+					OrgID: MustIDBase16(roleOrgOne),
+					Name:  "readers",
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.EConflict,
+					Op:   influxdb.OpCreateRole,
+					Msg:  "role name is not unique",
+				},
+				roles: []*influxdb.Role{
+					newTestRole(roleOneID, roleOrgOne, "readers"),
+				},
+			},
+		},
+		{
+			name: "permission outside of the organization",
+			fields: RoleFields{
+				IDGenerator:   mock.NewIDGenerator(roleOneID, t),
+				TimeGenerator: fakeGenerator,
+				Organizations: roleOrgs(),
+			},
+			args: args{
+				role: &influxdb.Role{
+					OrgID:       MustIDBase16(roleOrgOne),
+					Name:        "readers",
+					Permissions: []influxdb.Permission{roleBucketPermission(roleOrgTwo)},
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Op:   influxdb.OpCreateRole,
+					Msg:  "permission read:orgs/020f755c3c082023/buckets of role is not in the organization of the role",
+				},
+				roles: []*influxdb.Role{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, opPrefix, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.CreateRole(ctx, tt.args.role)
+			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
+			if err == nil {
+				defer s.DeleteRole(ctx, tt.args.role.ID)
+			}
+
+			roles, err := s.FindRoles(ctx, influxdb.RoleFilter{})
+			if err != nil {
+				t.Fatalf("failed to retrieve roles: %v", err)
+			}
+			if diff := cmp.Diff(roles, tt.wants.roles, roleCmpOptions...); diff != "" {
+				t.Errorf("roles are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindRoleByID testing
+func FindRoleByID(init func(RoleFields, *testing.T) (influxdb.RoleService, string, func()), t *testing.T) {
+	type args struct {
+		id influxdb.ID
+	}
+	type wants struct {
+		err  error
+		role *influxdb.Role
+	}
+
+	tests := []struct {
+		name   string
+		fields RoleFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "find role by id",
+			fields: RoleFields{
+				Organizations: roleOrgs(),
+				Roles: []*influxdb.Role{
+					newTestRole(roleOneID, roleOrgOne, "readers"),
+					newTestRole(roleTwoID, roleOrgTwo, "readers"),
+				},
+			},
+			args: args{
+				id: MustIDBase16(roleTwoID),
+			},
+			wants: wants{
+				role: newTestRole(roleTwoID, roleOrgTwo, "readers"),
+			},
+		},
+		{
+			name: "find role that does not exist",
+			fields: RoleFields{
+				Organizations: roleOrgs(),
+				Roles: []*influxdb.Role{
+					newTestRole(roleOneID, roleOrgOne, "readers"),
+				},
+			},
+			args: args{
+				id: MustIDBase16(roleTwoID),
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.ENotFound,
+					Op:   influxdb.OpFindRoleByID,
+					Msg:  influxdb.ErrRoleNotFound,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, opPrefix, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+
+			role, err := s.FindRoleByID(ctx, tt.args.id)
+			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
+
+			if diff := cmp.Diff(role, tt.wants.role, roleCmpOptions...); diff != "" {
+				t.Errorf("role is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindRoles testing
+func FindRoles(init func(RoleFields, *testing.T) (influxdb.RoleService, string, func()), t *testing.T) {
+	fields := RoleFields{
+		Organizations: roleOrgs(),
+		Roles: []*influxdb.Role{
+			newTestRole(roleOneID, roleOrgOne, "readers"),
+			newTestRole(roleTwoID, roleOrgTwo, "writers"),
+		},
+	}
+	writers := "writers"
+
+	tests := []struct {
+		name   string
+		filter influxdb.RoleFilter
+		wants  []*influxdb.Role
+	}{
+		{
+			name:   "find all roles",
+			filter: influxdb.RoleFilter{},
+			wants: []*influxdb.Role{
+				newTestRole(roleOneID, roleOrgOne, "readers"),
+				newTestRole(roleTwoID, roleOrgTwo, "writers"),
+			},
+		},
+		{
+			name:   "find roles by organization",
+			filter: influxdb.RoleFilter{OrgID: idPtr(MustIDBase16(roleOrgTwo))},
+			wants: []*influxdb.Role{
+				newTestRole(roleTwoID, roleOrgTwo, "writers"),
+			},
+		},
+		{
+			name:   "find roles by name",
+			filter: influxdb.RoleFilter{Name: &writers},
+			wants: []*influxdb.Role{
+				newTestRole(roleTwoID, roleOrgTwo, "writers"),
+			},
+		},
+		{
+			name: "find role by id and mismatched organization",
+			filter: influxdb.RoleFilter{
+				ID:    idPtr(MustIDBase16(roleOneID)),
+				OrgID: idPtr(MustIDBase16(roleOrgTwo)),
+			},
+			wants: []*influxdb.Role{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(fields, t)
+			defer done()
+			ctx := context.Background()
+
+			roles, err := s.FindRoles(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("failed to retrieve roles: %v", err)
+			}
+			if diff := cmp.Diff(roles, tt.wants, roleCmpOptions...); diff != "" {
+				t.Errorf("roles are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// UpdateRole testing
+func UpdateRole(init func(RoleFields, *testing.T) (influxdb.RoleService, string, func()), t *testing.T) {
+	name := "writers"
+	taken := "taken"
+	permissions := []influxdb.Permission{
+		{
+			Action: influxdb.WriteAction,
+			Resource: influxdb.Resource{
+				Type:  influxdb.BucketsResourceType,
+				OrgID: idPtr(MustIDBase16(roleOrgOne)),
+			},
+		},
+	}
+	otherOrg := []influxdb.Permission{roleBucketPermission(roleOrgTwo)}
+
+	updated := newTestRole(roleOneID, roleOrgOne, name)
+	updated.Permissions = permissions
+	updated.UpdatedAt = fakeDate
+
+	type args struct {
+		id  influxdb.ID
+		upd influxdb.RoleUpdate
+	}
+	type wants struct {
+		err  error
+		role *influxdb.Role
+	}
+
+	tests := []struct {
+		name   string
+		fields RoleFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "update role",
+			fields: RoleFields{
+				TimeGenerator: fakeGenerator,
+				Organizations: roleOrgs(),
+				Roles: []*influxdb.Role{
+					newTestRole(roleOneID, roleOrgOne, "readers"),
+				},
+			},
+			args: args{
+				id: MustIDBase16(roleOneID),
+				upd: influxdb.RoleUpdate{
+					Name:        &name,
+					Permissions: &permissions,
+				},
+			},
+			wants: wants{
+				role: updated,
+			},
+		},
+		{
+			name: "update to the name of another role",
+			fields: RoleFields{
+				TimeGenerator: fakeGenerator,
+				Organizations: roleOrgs(),
+				Roles: []*influxdb.Role{
+					newTestRole(roleOneID, roleOrgOne, "readers"),
+					newTestRole(roleTwoID, roleOrgOne, taken),
+				},
+			},
+			args: args{
+				id: MustIDBase16(roleOneID),
+				upd: influxdb.RoleUpdate{
+					Name: &taken,
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.EConflict,
+					Op:   influxdb.OpUpdateRole,
+					Msg:  "role name is not unique",
+				},
+			},
+		},
+		{
+			name: "update to permissions outside of the organization",
+			fields: RoleFields{
+				TimeGenerator: fakeGenerator,
+				Organizations: roleOrgs(),
+				Roles: []*influxdb.Role{
+					newTestRole(roleOneID, roleOrgOne, "readers"),
+				},
+			},
+			args: args{
+				id: MustIDBase16(roleOneID),
+				upd: influxdb.RoleUpdate{
+					Permissions: &otherOrg,
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Op:   influxdb.OpUpdateRole,
+					Msg:  "permission read:orgs/020f755c3c082023/buckets of role is not in the organization of the role",
+				},
+			},
+		},
+		{
+			name: "update role that does not exist",
+			fields: RoleFields{
+				TimeGenerator: fakeGenerator,
+				Organizations: roleOrgs(),
+			},
+			args: args{
+				id: MustIDBase16(roleTwoID),
+				upd: influxdb.RoleUpdate{
+					Name: &name,
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.ENotFound,
+					Op:   influxdb.OpUpdateRole,
+					Msg:  influxdb.ErrRoleNotFound,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, opPrefix, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+
+			role, err := s.UpdateRole(ctx, tt.args.id, tt.args.upd)
+			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
+
+			if diff := cmp.Diff(role, tt.wants.role, roleCmpOptions...); diff != "" {
+				t.Errorf("role is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// DeleteRole testing
+func DeleteRole(init func(RoleFields, *testing.T) (influxdb.RoleService, string, func()), t *testing.T) {
+	type args struct {
+		id influxdb.ID
+	}
+	type wants struct {
+		err   error
+		roles []*influxdb.Role
+	}
+
+	tests := []struct {
+		name   string
+		fields RoleFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "delete role and its members",
+			fields: RoleFields{
+				Organizations: roleOrgs(),
+				Roles: []*influxdb.Role{
+					newTestRole(roleOneID, roleOrgOne, "readers"),
+					newTestRole(roleTwoID, roleOrgTwo, "readers"),
+				},
+				UserResourceMappings: []*influxdb.UserResourceMapping{
+					{
+						UserID:       MustIDBase16(roleUserID),
+						UserType:     influxdb.Member,
+						ResourceType: influxdb.RolesResourceType,
+						ResourceID:   MustIDBase16(roleOneID),
+					},
+				},
+			},
+			args: args{
+				id: MustIDBase16(roleOneID),
+			},
+			wants: wants{
+				roles: []*influxdb.Role{
+					newTestRole(roleTwoID, roleOrgTwo, "readers"),
+				},
+			},
+		},
+		{
+			name: "delete role that does not exist",
+			fields: RoleFields{
+				Organizations: roleOrgs(),
+				Roles: []*influxdb.Role{
+					newTestRole(roleTwoID, roleOrgTwo, "readers"),
+				},
+			},
+			args: args{
+				id: MustIDBase16(roleOneID),
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.ENotFound,
+					Op:   influxdb.OpDeleteRole,
+					Msg:  influxdb.ErrRoleNotFound,
+				},
+				roles: []*influxdb.Role{
+					newTestRole(roleTwoID, roleOrgTwo, "readers"),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, opPrefix, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.DeleteRole(ctx, tt.args.id)
+			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
+
+			roles, err := s.FindRoles(ctx, influxdb.RoleFilter{})
+			if err != nil {
+				t.Fatalf("failed to retrieve roles: %v", err)
+			}
+			if diff := cmp.Diff(roles, tt.wants.roles, roleCmpOptions...); diff != "" {
+				t.Errorf("roles are different -got/+want\ndiff %s", diff)
+			}
+
+			urms, ok := s.(influxdb.UserResourceMappingService)
+			if !ok || err != nil {
+				return
+			}
+			ms, _, err := urms.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+				ResourceID:   tt.args.id,
+				ResourceType: influxdb.RolesResourceType,
+			})
+			if err != nil {
+				t.Fatalf("failed to retrieve user resource mappings: %v", err)
+			}
+			if len(ms) != 0 {
+				t.Errorf("expected the members of the deleted role to be removed, got %d", len(ms))
+			}
+		})
+	}
+}
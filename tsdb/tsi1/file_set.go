@@ -89,6 +89,16 @@ func (fs *FileSet) Size() int64 {
 	return total + int64(fs.manifestSize)
 }
 
+// DiskSizeBytesByPrefix returns the on-disk size of the measurements of the
+// FileSet whose name starts with prefix.
+func (fs *FileSet) DiskSizeBytesByPrefix(prefix []byte) int64 {
+	var total int64
+	for _, f := range fs.files {
+		total += f.diskSizeBytesByPrefix(prefix)
+	}
+	return total
+}
+
 // MustReplace swaps a list of files for a single file and returns a new file set.
 // The caller should always guarantee that the files exist and are contiguous.
 func (fs *FileSet) MustReplace(oldFiles []File, newFile File) (*FileSet, error) {
@@ -469,6 +479,9 @@ type File interface {
 	// Size of file on disk
 	Size() int64
 
+	// Size on disk of the measurements starting with prefix
+	diskSizeBytesByPrefix(prefix []byte) int64
+
 	// Estimated memory footprint
 	bytes() int
 }
@@ -245,6 +245,9 @@ func (i *Index) Open(ctx context.Context) error {
 		p.MaxLogFileSize = i.maxLogFileSize
 		p.nosync = i.disableFsync
 		p.logbufferSize = i.logfileBufferSize
+		if i.disableCompactions {
+			p.compactionsDisabled++
+		}
 		p.logger = i.logger.With(zap.String("tsi1_partition", fmt.Sprint(j+1)))
 
 		// Each of the trackers needs to be given slightly different default
@@ -1131,6 +1134,21 @@ func (i *Index) MeasurementTagKeysByExpr(name []byte, expr influxql.Expr) (map[s
 	return result, nil
 }
 
+// DiskSizeBytesByPrefix returns the size on disk of the measurements of the
+// index whose name starts with prefix. In storage, measurement names are the
+// org and bucket of the series, so the prefix of an org ID sums all of its
+// buckets.
+func (i *Index) DiskSizeBytesByPrefix(prefix []byte) int64 {
+	fs, err := i.FileSet()
+	if err != nil {
+		i.logger.Warn("Index is closing down")
+		return 0
+	}
+	defer fs.Release()
+
+	return fs.DiskSizeBytesByPrefix(prefix)
+}
+
 // DiskSizeBytes returns the size of the index on disk.
 func (i *Index) DiskSizeBytes() int64 {
 	fs, err := i.FileSet()
@@ -262,6 +262,19 @@ func (f *IndexFile) Measurement(name []byte) MeasurementElem {
 	return &e
 }
 
+// diskSizeBytesByPrefix returns the size of the measurement block elements and
+// tag blocks of the measurements whose name starts with prefix.
+func (f *IndexFile) diskSizeBytesByPrefix(prefix []byte) int64 {
+	var n int64
+	itr := f.mblk.Iterator()
+	for e := itr.Next(); e != nil; e = itr.Next() {
+		if elem := e.(*MeasurementBlockElem); bytes.HasPrefix(elem.Name(), prefix) {
+			n += int64(elem.Size()) + elem.TagBlockSize()
+		}
+	}
+	return n
+}
+
 // MeasurementN returns the number of measurements in the file.
 func (f *IndexFile) MeasurementN() (n uint64) {
 	mitr := f.mblk.Iterator()
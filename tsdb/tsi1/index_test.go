@@ -329,6 +329,55 @@ func TestIndex_DiskSizeBytes(t *testing.T) {
 	})
 }
 
+func TestIndex_DiskSizeBytesByPrefix(t *testing.T) {
+	idx := MustOpenIndex(tsi1.DefaultPartitionN, tsi1.NewConfig())
+	defer idx.Close()
+
+	// Add series to index.
+	if err := idx.CreateSeriesSliceIfNotExists([]Series{
+		{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"region": "east"})},
+		{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"region": "west"})},
+		{Name: []byte("disk"), Tags: models.NewTags(map[string]string{"region": "north"})},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each series entry is 9 bytes in the log file.
+	idx.Run(t, func(t *testing.T) {
+		if got, exp := idx.DiskSizeBytesByPrefix([]byte("cpu")), int64(2*9); got != exp {
+			t.Fatalf("got %d bytes, expected %d", got, exp)
+		}
+		if got, exp := idx.DiskSizeBytesByPrefix([]byte("disk")), int64(9); got != exp {
+			t.Fatalf("got %d bytes, expected %d", got, exp)
+		}
+		if got, exp := idx.DiskSizeBytesByPrefix([]byte("mem")), int64(0); got != exp {
+			t.Fatalf("got %d bytes, expected %d", got, exp)
+		}
+	})
+
+	// Compacted index files count the measurement and tag blocks.
+	c := tsi1.NewConfig()
+	c.MaxIndexLogFileSize = 1
+	cidx := MustOpenIndex(1, c)
+	defer cidx.Close()
+
+	if err := cidx.CreateSeriesSliceIfNotExists([]Series{
+		{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"region": "east"})},
+		{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"region": "west"})},
+		{Name: []byte("disk"), Tags: models.NewTags(map[string]string{"region": "north"})},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	cidx.Wait()
+
+	if got := cidx.DiskSizeBytesByPrefix([]byte("cpu")); got <= 0 {
+		t.Fatalf("got %d bytes, expected compacted size", got)
+	}
+	if got := cidx.DiskSizeBytesByPrefix([]byte("mem")); got != 0 {
+		t.Fatalf("got %d bytes, expected 0", got)
+	}
+}
+
 // Ensure index can returns measurement cardinality stats.
 func TestIndex_MeasurementCardinalityStats(t *testing.T) {
 	t.Parallel()
@@ -10,6 +10,7 @@ import (
 	"io"
 	"os"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 	"unsafe"
@@ -697,6 +698,7 @@ func (f *LogFile) execEntry(e *LogEntry) {
 
 func (f *LogFile) execDeleteMeasurementEntry(e *LogEntry) {
 	mm := f.createMeasurementIfNotExists(e.Name)
+	mm.diskBytes += int64(e.Size)
 	mm.deleted = true
 	mm.tagSet = make(map[string]logTagKey)
 	mm.series = make(map[tsdb.SeriesID]struct{})
@@ -705,6 +707,7 @@ func (f *LogFile) execDeleteMeasurementEntry(e *LogEntry) {
 
 func (f *LogFile) execDeleteTagKeyEntry(e *LogEntry) {
 	mm := f.createMeasurementIfNotExists(e.Name)
+	mm.diskBytes += int64(e.Size)
 	ts := mm.createTagSetIfNotExists(e.Key)
 
 	ts.deleted = true
@@ -714,6 +717,7 @@ func (f *LogFile) execDeleteTagKeyEntry(e *LogEntry) {
 
 func (f *LogFile) execDeleteTagValueEntry(e *LogEntry) {
 	mm := f.createMeasurementIfNotExists(e.Name)
+	mm.diskBytes += int64(e.Size)
 	ts := mm.createTagSetIfNotExists(e.Key)
 	tv := ts.createTagValueIfNotExists(e.Value)
 
@@ -754,6 +758,7 @@ func (f *LogFile) execSeriesEntry(e *LogEntry) {
 	name, remainder := tsdb.ReadSeriesKeyMeasurement(remainder)
 	mm := f.createMeasurementIfNotExists(name)
 	mm.deleted = false
+	mm.diskBytes += int64(e.Size)
 	if !deleted {
 		mm.addSeriesID(e.SeriesID)
 	} else {
@@ -835,6 +840,21 @@ func (f *LogFile) createMeasurementIfNotExists(name []byte) *logMeasurement {
 	return mm
 }
 
+// diskSizeBytesByPrefix returns the size of the log entries of the
+// measurements whose name starts with prefix.
+func (f *LogFile) diskSizeBytesByPrefix(prefix []byte) int64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var n int64
+	for name, mm := range f.mms {
+		if strings.HasPrefix(name, string(prefix)) {
+			n += mm.diskBytes
+		}
+	}
+	return n
+}
+
 // MeasurementIterator returns an iterator over all the measurements in the file.
 func (f *LogFile) MeasurementIterator() MeasurementIterator {
 	f.mu.RLock()
@@ -1209,6 +1229,9 @@ type logMeasurement struct {
 	deleted   bool
 	series    map[tsdb.SeriesID]struct{}
 	seriesSet *tsdb.SeriesIDSet
+
+	// diskBytes is the size of the log entries of the measurement.
+	diskBytes int64
 }
 
 // bytes estimates the memory footprint of this logMeasurement, in bytes.
@@ -285,7 +285,9 @@ func (p *Partition) Open() (err error) {
 	p.res.Open()
 
 	// Send a compaction request on start up.
-	p.compact()
+	if p.compactionsDisabled == 0 {
+		p.compact()
+	}
 
 	return nil
 }
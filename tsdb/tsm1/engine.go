@@ -482,6 +482,12 @@ func (e *Engine) MeasurementStats() (MeasurementStats, error) {
 	return e.FileStore.MeasurementStats()
 }
 
+// DiskSizeBytesByPrefix returns the number of bytes consumed by the TSM blocks
+// of the keys whose measurement name starts with prefix.
+func (e *Engine) DiskSizeBytesByPrefix(prefix []byte) int64 {
+	return e.FileStore.DiskSizeBytesByPrefix(prefix)
+}
+
 func (e *Engine) initTrackers() {
 	mmu.Lock()
 	defer mmu.Unlock()
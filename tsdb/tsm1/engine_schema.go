@@ -386,6 +386,77 @@ func (e *Engine) tagKeysPredicate(ctx context.Context, orgBucket []byte, start,
 	return cursors.NewStringSliceIteratorWithStats(keyset.Keys(), stats), nil
 }
 
+// CountSeries returns the number of series in the given bucket matching the
+// predicate, which have data within the time range (start, end].
+func (e *Engine) CountSeries(ctx context.Context, orgID, bucketID influxdb.ID, start, end int64, predicate influxql.Expr) (int64, error) {
+	if predicate != nil {
+		if err := ValidateTagPredicate(predicate); err != nil {
+			return 0, err
+		}
+	}
+
+	encoded := tsdb.EncodeName(orgID, bucketID)
+	keys, err := e.findCandidateKeys(ctx, encoded[:], predicate)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	var files []TSMFile
+	defer func() {
+		for _, f := range files {
+			f.Unref()
+		}
+	}()
+	var iters []*TimeRangeIterator
+
+	prefix := models.EscapeMeasurement(encoded[:])
+
+	e.FileStore.ForEachFile(func(f TSMFile) bool {
+		if f.OverlapsTimeRange(start, end) && f.OverlapsKeyPrefixRange(prefix, prefix) {
+			f.Ref()
+			files = append(files, f)
+			iters = append(iters, f.TimeRangeIterator(prefix, start, end))
+		}
+		return true
+	})
+
+	// reusable buffers
+	var (
+		tags   models.Tags
+		keybuf []byte
+		sfkey  []byte
+		n      int64
+	)
+
+	for i := range keys {
+		_, tags = tsdb.ParseSeriesKeyInto(keys[i], tags[:0])
+		keybuf = models.AppendMakeKey(keybuf[:0], prefix, tags)
+		sfkey = AppendSeriesFieldKeyBytes(sfkey[:0], keybuf, tags.Get(models.FieldKeyTagKeyBytes))
+
+		if e.Cache.Values(sfkey).Contains(start, end) {
+			n++
+			continue
+		}
+
+		for _, iter := range iters {
+			if exact, _ := iter.Seek(sfkey); !exact {
+				continue
+			}
+
+			if iter.HasData() {
+				n++
+				break
+			}
+		}
+	}
+
+	return n, nil
+}
+
 var errUnexpectedTagComparisonOperator = errors.New("unexpected tag comparison operator")
 
 func ValidateTagPredicate(expr influxql.Expr) (err error) {
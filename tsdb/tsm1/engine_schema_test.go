@@ -539,6 +539,114 @@ mem,mem1=v,mem2=v        f=1 201`)
 	}
 }
 
+func TestEngine_CountSeries(t *testing.T) {
+	e, err := NewEngine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	org, bucket := influxdb.ID(0x5020), influxdb.ID(0x5100)
+
+	e.MustWritePointsString(org, bucket, `
+cpu,host=A,os=linux value=1.1 101
+cpu,host=B,os=linux value=1.2 102
+cpu,host=C,os=macOS value=1.3 103
+mem,host=A,os=linux value=1.4,free=2.1 104`)
+
+	// send some points to TSM data
+	e.MustWriteSnapshot()
+
+	// delete all the data of two series
+	e.MustDeleteBucketRange(org, bucket, 0, 102)
+
+	// leave some points in the cache
+	e.MustWritePointsString(org, bucket, `
+cpu,host=A,os=linux value=1.1 201
+cpu,host=D,os=macOS value=1.2 202`)
+
+	var tests = []struct {
+		name     string
+		min, max int64
+		expr     string
+		exp      int64
+	}{
+		{
+			name: "TSM and cache",
+			min:  0,
+			max:  1000,
+			exp:  5,
+		},
+		{
+			name: "only TSM",
+			min:  0,
+			max:  199,
+			exp:  3,
+		},
+		{
+			name: "only cache",
+			min:  200,
+			max:  299,
+			exp:  2,
+		},
+		{
+			name: "predicate/tag",
+			min:  0,
+			max:  1000,
+			expr: `os = 'macOS'`,
+			exp:  2,
+		},
+		{
+			name: "predicate/measurement",
+			min:  0,
+			max:  1000,
+			expr: `_m = 'mem'`,
+			exp:  2,
+		},
+		{
+			name: "predicate/time range",
+			min:  0,
+			max:  199,
+			expr: `host = 'A'`,
+			exp:  2,
+		},
+		{
+			name: "predicate/no candidate series",
+			min:  0,
+			max:  1000,
+			expr: `foo = 'bar'`,
+			exp:  0,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var expr influxql.Expr
+			if len(tc.expr) > 0 {
+				expr = influxql.MustParseExpr(tc.expr)
+				expr = influxql.RewriteExpr(expr, func(expr influxql.Expr) influxql.Expr {
+					if n, ok := expr.(*influxql.BinaryExpr); ok {
+						if r, ok := n.LHS.(*influxql.VarRef); ok && r.Val == "_m" {
+							r.Val = models.MeasurementTagKey
+						}
+					}
+					return expr
+				})
+			}
+
+			got, err := e.CountSeries(context.Background(), org, bucket, tc.min, tc.max, expr)
+			if err != nil {
+				t.Fatalf("CountSeries: error %v", err)
+			}
+			if got != tc.exp {
+				t.Errorf("unexpected CountSeries: got %d, exp %d", got, tc.exp)
+			}
+		})
+	}
+}
+
 func TestValidateTagPredicate(t *testing.T) {
 	tests := []struct {
 		name    string
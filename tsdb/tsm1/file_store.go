@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/file"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/pkg/metrics"
@@ -212,6 +213,10 @@ type FileStore struct {
 	tracker *fileTracker
 	purger  *purger
 
+	// blockSizes are the sizes of the blocks of each file by the measurement
+	// name of their keys, which is the org and bucket of the data in storage.
+	blockSizes map[TSMFile]MeasurementStats
+
 	currentTempDirID int
 
 	parseFileName ParseFileNameFunc
@@ -259,6 +264,7 @@ func NewFileStore(dir string) *FileStore {
 		obs:           noFileStoreObserver{},
 		parseFileName: DefaultParseFileName,
 		tracker:       newFileTracker(newFileMetrics(nil), nil),
+		blockSizes:    make(map[TSMFile]MeasurementStats),
 	}
 	fs.purger.fileStore = fs
 	return fs
@@ -622,8 +628,9 @@ func (f *FileStore) Open(ctx context.Context) error {
 
 	// struct to hold the result of opening each reader in a goroutine
 	type res struct {
-		r   *TSMReader
-		err error
+		r     *TSMReader
+		sizes MeasurementStats
+		err   error
 	}
 
 	readerC := make(chan *res)
@@ -671,7 +678,7 @@ func (f *FileStore) Open(ctx context.Context) error {
 			}
 
 			df.WithObserver(f.obs)
-			readerC <- &res{r: df}
+			readerC <- &res{r: df, sizes: blockSizesByName(df)}
 		}(i, file)
 	}
 
@@ -690,6 +697,7 @@ func (f *FileStore) Open(ctx context.Context) error {
 			continue
 		}
 		f.files = append(f.files, res.r)
+		f.blockSizes[res.r] = res.sizes
 		name := filepath.Base(res.r.Stats().Path)
 		_, seq, err := f.parseFileName(name)
 		if err != nil {
@@ -728,6 +736,7 @@ func (f *FileStore) Close() error {
 
 	f.lastFileStats = nil
 	f.files = nil
+	f.blockSizes = make(map[TSMFile]MeasurementStats)
 	f.tracker.ClearFileCounts()
 
 	// Let other methods access this closed object while we do the actual closing.
@@ -746,6 +755,50 @@ func (f *FileStore) Close() error {
 // DiskSizeBytes returns the total number of bytes consumed by the files in the FileStore.
 func (f *FileStore) DiskSizeBytes() int64 { return int64(f.tracker.Bytes()) }
 
+// DiskSizeBytesByPrefix returns the number of bytes consumed by the blocks of
+// the keys whose measurement name starts with prefix. In storage, measurement
+// names are the org and bucket of the data, so the prefix of an org ID sums
+// all of its buckets.
+func (f *FileStore) DiskSizeBytesByPrefix(prefix []byte) int64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var n int64
+	for _, file := range f.files {
+		for name, size := range f.blockSizes[file] {
+			if strings.HasPrefix(name, string(prefix)) {
+				n += int64(size)
+			}
+		}
+	}
+	return n
+}
+
+// blockSizesByName returns the sizes of the blocks of the file by the
+// measurement name of their keys. They are read from the stats file the TSM
+// writer records them in as it writes the file. The index of the file is only
+// walked for files written without a stats file.
+func blockSizesByName(r TSMFile) MeasurementStats {
+	if stats, err := r.MeasurementStats(); err == nil && (len(stats) > 0 || r.KeyCount() == 0) {
+		return stats
+	}
+	return indexBlockSizesByName(r)
+}
+
+// indexBlockSizesByName returns the sizes of the blocks of the file by the
+// measurement name of their keys, from the entries of the file's index.
+func indexBlockSizesByName(r TSMFile) MeasurementStats {
+	sizes := NewMeasurementStats()
+	itr := r.Iterator(nil)
+	for itr.Next() {
+		name := models.ParseName(itr.Key())
+		for _, e := range itr.Entries() {
+			sizes[string(name)] += int(e.Size)
+		}
+	}
+	return sizes
+}
+
 // Read returns the slice of values for the given key and the given timestamp,
 // if any file matches those constraints.
 func (f *FileStore) Read(key []byte, t int64) ([]Value, error) {
@@ -849,6 +902,7 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 	f.mu.RUnlock()
 
 	updated := make([]TSMFile, 0, len(newFiles))
+	blockSizes := make(map[TSMFile]MeasurementStats, len(newFiles))
 	tsmTmpExt := fmt.Sprintf("%s.%s", TSMFileExtension, TmpTSMFileExtension)
 
 	// Rename all the new files to make them live on restart
@@ -901,6 +955,7 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 		tsm.WithObserver(f.obs)
 
 		updated = append(updated, tsm)
+		blockSizes[tsm] = blockSizesByName(tsm)
 	}
 
 	if updatedFn != nil {
@@ -1014,6 +1069,15 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 	f.lastFileStats = nil
 	f.files = active
 	sort.Sort(tsmReaders(f.files))
+
+	// Keep the block sizes of the active files only.
+	for file, s := range f.blockSizes {
+		blockSizes[file] = s
+	}
+	f.blockSizes = make(map[TSMFile]MeasurementStats, len(f.files))
+	for _, file := range f.files {
+		f.blockSizes[file] = blockSizes[file]
+	}
 	f.tracker.ClearFileCounts()
 
 	// Recalculate the disk size stat
@@ -2699,6 +2699,140 @@ func TestFileStore_Stats(t *testing.T) {
 	}
 }
 
+func TestFileStore_DiskSizeBytesByPrefix(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	// Create 3 TSM files...
+	data := []keyValues{
+		keyValues{"cpu,host=a#!~#value", []tsm1.Value{tsm1.NewValue(0, 1.0)}},
+		keyValues{"cpu,host=b#!~#value", []tsm1.Value{tsm1.NewValue(1, 2.0), tsm1.NewValue(2, 3.0)}},
+		keyValues{"mem#!~#value", []tsm1.Value{tsm1.NewValue(0, 1.0)}},
+	}
+
+	files, err := newFileDir(dir, data...)
+	if err != nil {
+		fatal(t, "creating test files", err)
+	}
+
+	fs := tsm1.NewFileStore(dir)
+	if err := fs.Open(context.Background()); err != nil {
+		fatal(t, "opening file store", err)
+	}
+	defer fs.Close()
+
+	cpu, mem := fs.DiskSizeBytesByPrefix([]byte("cpu")), fs.DiskSizeBytesByPrefix([]byte("mem"))
+	if cpu == 0 || mem == 0 {
+		t.Fatalf("expected sizes of blocks, got cpu=%d mem=%d", cpu, mem)
+	}
+	if got, exp := fs.DiskSizeBytesByPrefix(nil), cpu+mem; got != exp {
+		t.Fatalf("total size mismatch: got %v, exp %v", got, exp)
+	}
+	if got := fs.DiskSizeBytesByPrefix([]byte("disk")); got != 0 {
+		t.Fatalf("expected no size for missing prefix, got %v", got)
+	}
+
+	// Removing the cpu files should remove their sizes.
+	if err := fs.Replace(files[0:2], nil); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if got := fs.DiskSizeBytesByPrefix([]byte("cpu")); got != 0 {
+		t.Fatalf("expected no size for removed files, got %v", got)
+	}
+	if got, exp := fs.DiskSizeBytesByPrefix([]byte("mem")), mem; got != exp {
+		t.Fatalf("size mismatch: got %v, exp %v", got, exp)
+	}
+
+	// Adding a file should add its sizes.
+	newFile := MustWriteTSM(dir, 4, map[string][]tsm1.Value{
+		"cpu,host=c#!~#value": []tsm1.Value{tsm1.NewValue(0, 1.0)},
+	})
+	if err := fs.Replace(nil, []string{newFile}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if got := fs.DiskSizeBytesByPrefix([]byte("cpu")); got == 0 {
+		t.Fatalf("expected size of added file, got %v", got)
+	}
+}
+
+func TestFileStore_DiskSizeBytesByPrefix_StatsFile(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	// The files are written without stats files, so their sizes are read from their index.
+	data := []keyValues{
+		keyValues{"cpu,host=a#!~#value", []tsm1.Value{tsm1.NewValue(0, 1.0)}},
+		keyValues{"mem#!~#value", []tsm1.Value{tsm1.NewValue(0, 1.0), tsm1.NewValue(1, 2.0)}},
+	}
+	files, err := newFileDir(dir, data...)
+	if err != nil {
+		fatal(t, "creating test files", err)
+	}
+
+	fs := tsm1.NewFileStore(dir)
+	if err := fs.Open(context.Background()); err != nil {
+		fatal(t, "opening file store", err)
+	}
+	cpu, mem := fs.DiskSizeBytesByPrefix([]byte("cpu")), fs.DiskSizeBytesByPrefix([]byte("mem"))
+	fs.Close()
+	if cpu == 0 || mem == 0 {
+		t.Fatalf("expected sizes of blocks, got cpu=%d mem=%d", cpu, mem)
+	}
+
+	// The sizes in stats files are used instead of the index.
+	for i, stats := range []tsm1.MeasurementStats{{"cpu": 2 * int(cpu)}, {"mem": 2 * int(mem)}} {
+		f, err := os.Create(tsm1.StatsFilename(files[i]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := stats.WriteTo(f); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	fs = tsm1.NewFileStore(dir)
+	if err := fs.Open(context.Background()); err != nil {
+		fatal(t, "opening file store", err)
+	}
+	defer fs.Close()
+	if got, exp := fs.DiskSizeBytesByPrefix([]byte("cpu")), 2*cpu; got != exp {
+		t.Fatalf("cpu size mismatch: got %v, exp %v", got, exp)
+	}
+	if got, exp := fs.DiskSizeBytesByPrefix([]byte("mem")), 2*mem; got != exp {
+		t.Fatalf("mem size mismatch: got %v, exp %v", got, exp)
+	}
+
+	// The writer records the sizes of the blocks it writes, which are those of
+	// the index of the file: the same values as mem have blocks of the same size.
+	name := filepath.Join(dir, tsm1.DefaultFormatFileName(3, 1)+"."+tsm1.TSMFileExtension)
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write([]byte("disk#!~#value"), []tsm1.Value{tsm1.NewValue(0, 1.0), tsm1.NewValue(1, 2.0)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Replace(nil, []string{name}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if _, err := os.Stat(tsm1.StatsFilename(name)); err != nil {
+		t.Fatalf("expected the writer to write a stats file: %v", err)
+	}
+	if got := fs.DiskSizeBytesByPrefix([]byte("disk")); got != mem {
+		t.Fatalf("disk size mismatch: got %v, exp %v", got, mem)
+	}
+}
+
 func TestFileStore_CreateSnapshot(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
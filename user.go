@@ -2,6 +2,7 @@ package influxdb
 
 import (
 	"context"
+	"fmt"
 )
 
 // User is a user. 🎉
@@ -9,8 +10,14 @@ type User struct {
 	ID      ID     `json:"id,omitempty"`
 	Name    string `json:"name"`
 	OAuthID string `json:"oauthID,omitempty"`
+	// Origin is where the user is managed; it is empty for local users.
+	Origin string `json:"origin,omitempty"`
 }
 
+// UserOriginLDAP is the origin of users provisioned by, or linked to, a LDAP server.
+// Their passwords are checked, and their organization memberships managed, by the LDAP server.
+const UserOriginLDAP = "ldap"
+
 // Ops for user errors and op log.
 const (
 	OpFindUserByID = "FindUserByID"
@@ -49,6 +56,19 @@ type UserService interface {
 // Only fields which are set are updated.
 type UserUpdate struct {
 	Name *string `json:"name"`
+	// Origin links a local user to, or unlinks it from, the server it is managed by.
+	Origin *string `json:"origin,omitempty"`
+}
+
+// Valid returns an error if the update sets an unknown origin.
+func (u UserUpdate) Valid() error {
+	if u.Origin != nil && *u.Origin != "" && *u.Origin != UserOriginLDAP {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("unknown user origin %q", *u.Origin),
+		}
+	}
+	return nil
 }
 
 // UserFilter represents a set of filter that restrict the returned results.